@@ -0,0 +1,260 @@
+// Package fanucv1 содержит сообщения protobuf-схемы fanuc.proto и их заполнение
+// из структур пакета models, а также gRPC-сервис MachineService (service.proto).
+// Схема используется при публикации в брокеры сообщений и в gRPC API (пакет grpcapi).
+package fanucv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative fanuc/v1/fanuc.proto
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative fanuc/v1/service.proto
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/iwtcode/fanucAdapter/models"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// FromQuality преобразует models.Quality в Quality.
+func FromQuality(q models.Quality) Quality {
+	switch q {
+	case models.QualityGood:
+		return Quality_QUALITY_GOOD
+	case models.QualityNotSupported:
+		return Quality_QUALITY_NOT_SUPPORTED
+	case models.QualityCommError:
+		return Quality_QUALITY_COMM_ERROR
+	case models.QualityStale:
+		return Quality_QUALITY_STALE
+	}
+	return Quality_QUALITY_UNSPECIFIED
+}
+
+// FromMachineData преобразует сводные данные станка в MachineData.
+func FromMachineData(d *models.AggregatedData) *MachineData {
+	if d == nil {
+		return nil
+	}
+	m := &MachineData{
+		MachineId:          d.MachineID,
+		Timestamp:          timestamp(d.Timestamp),
+		IsEnabled:          d.IsEnabled,
+		IsEmergency:        d.IsEmergency,
+		MachineState:       d.MachineState,
+		ProgramMode:        d.ProgramMode,
+		TmMode:             d.TmMode,
+		AxisMovementStatus: d.AxisMovementStatus,
+		MstbStatus:         d.MstbStatus,
+		EmergencyStatus:    d.EmergencyStatus,
+		AlarmStatus:        d.AlarmStatus,
+		EditStatus:         d.EditStatus,
+		Axes:               FromAxes(d.AxisInfos),
+		HasAlarms:          d.HasAlarms,
+		Alarms:             FromAlarms(d.Alarms),
+		CurrentProgram:     fromProgram(d.CurrentProgram),
+		Spindles:           FromSpindles(d.SpindleInfos),
+		ContourFeedRate:    d.ContourFeedRate,
+		ActualFeedRate:     d.ActualFeedRate,
+		FeedOverride:       int32(d.FeedOverride),
+		JogOverride:        d.JogOverride,
+		PartsCount:         d.PartsCount,
+		PowerOnTime:        d.PowerOnTime,
+		OperatingTime:      d.OperatingTime,
+		CycleTime:          d.CycleTime,
+		CuttingTime:        d.CuttingTime,
+	}
+	for _, p := range d.Paths {
+		m.Paths = append(m.Paths, &PathData{
+			Path:               int32(p.Path),
+			MachineState:       p.MachineState,
+			ProgramMode:        p.ProgramMode,
+			TmMode:             p.TmMode,
+			AxisMovementStatus: p.AxisMovementStatus,
+			MstbStatus:         p.MstbStatus,
+			EmergencyStatus:    p.EmergencyStatus,
+			AlarmStatus:        p.AlarmStatus,
+			EditStatus:         p.EditStatus,
+			HasAlarms:          p.HasAlarms,
+			Alarms:             FromAlarms(p.Alarms),
+			Axes:               FromAxes(p.AxisInfos),
+			Spindles:           FromSpindles(p.SpindleInfos),
+			CurrentProgram:     fromProgram(p.CurrentProgram),
+		})
+	}
+	if d.Sections != nil {
+		m.Sections = make(map[string]*SectionStatus, len(d.Sections))
+		for section, status := range d.Sections {
+			m.Sections[string(section)] = &SectionStatus{
+				Status:     status.Status,
+				Quality:    FromQuality(status.Quality),
+				SourceTime: timestamp(status.SourceTime),
+				Error:      status.Error,
+			}
+		}
+	}
+	return m
+}
+
+// FromMachineEvent преобразует событие изменения в MachineEvent. Значения Before и After
+// передаются в том же виде, что и в JSON.
+func FromMachineEvent(e models.MachineEvent) (*MachineEvent, error) {
+	before, err := toValue(e.Before)
+	if err != nil {
+		return nil, fmt.Errorf("event %s before: %w", e.Type, err)
+	}
+	after, err := toValue(e.After)
+	if err != nil {
+		return nil, fmt.Errorf("event %s after: %w", e.Type, err)
+	}
+	return &MachineEvent{
+		Type:      string(e.Type),
+		MachineId: e.MachineID,
+		Key:       e.Key,
+		Before:    before,
+		After:     after,
+		Timestamp: timestamp(e.Timestamp),
+	}, nil
+}
+
+// FromSystemInfo преобразует системную информацию станка в SystemInfo.
+func FromSystemInfo(info *models.SystemInfo) *SystemInfo {
+	if info == nil {
+		return nil
+	}
+	return &SystemInfo{
+		Manufacturer:   info.Manufacturer,
+		Model:          info.Model,
+		Series:         info.Series,
+		Version:        info.Version,
+		MaxAxes:        int32(info.MaxAxes),
+		ControlledAxes: int32(info.ControlledAxes),
+		Paths:          int32(info.Paths),
+		CncType:        info.CncType,
+		MachineType:    info.MachineType,
+		ModelSeries:    info.ModelSeries,
+		ModelSource:    info.ModelSource,
+		Implementation: info.Implementation,
+	}
+}
+
+// FromParameterInfo преобразует счетчик деталей и наработку в ParameterInfo.
+func FromParameterInfo(p *models.ParameterInfo) *ParameterInfo {
+	if p == nil {
+		return nil
+	}
+	return &ParameterInfo{
+		PartsCount:    p.PartsCount,
+		PowerOnTime:   p.PowerOnTime,
+		OperatingTime: p.OperatingTime,
+		CycleTime:     p.CycleTime,
+		CuttingTime:   p.CuttingTime,
+	}
+}
+
+// FromProgramInfo преобразует сведения о выполняемой программе в Program.
+func FromProgramInfo(p *models.ProgramInfo) *Program {
+	if p == nil {
+		return nil
+	}
+	return &Program{ProgramName: p.Name, ProgramNumber: p.Number, GCodeLine: p.CurrentGCode}
+}
+
+// FromConnectionStatus преобразует состояние соединения в ConnectionStatus.
+func FromConnectionStatus(s models.ConnectionStatus) *ConnectionStatus {
+	return &ConnectionStatus{
+		State:          string(s.State),
+		Since:          timestamp(s.Since),
+		LastError:      s.LastError,
+		LastErrorTime:  timestamp(s.LastErrorTime),
+		LastSuccess:    timestamp(s.LastSuccess),
+		ReconnectCount: int32(s.ReconnectCount),
+	}
+}
+
+// FromAxes преобразует данные осей.
+func FromAxes(axes []models.AxisInfo) []*Axis {
+	result := make([]*Axis, 0, len(axes))
+	for _, a := range axes {
+		result = append(result, &Axis{
+			Name:             a.Name,
+			Position:         a.Position,
+			LoadPercent:      a.LoadPercent,
+			ServoTemperature: a.ServoTemperature,
+			CoderTemperature: a.CoderTemperature,
+			PowerConsumption: a.PowerConsumption,
+			Diag_301:         a.Diag301,
+			Quality:          fromFieldQuality(a.Quality),
+		})
+	}
+	return result
+}
+
+// FromSpindles преобразует данные шпинделей.
+func FromSpindles(spindles []models.SpindleInfo) []*Spindle {
+	result := make([]*Spindle, 0, len(spindles))
+	for _, s := range spindles {
+		result = append(result, &Spindle{
+			Number:           int32(s.Number),
+			SpeedRpm:         s.SpeedRPM,
+			LoadPercent:      s.LoadPercent,
+			OverridePercent:  int32(s.OverridePercent),
+			PowerConsumption: s.PowerConsumption,
+			Diag_411Value:    s.Diag411Value,
+			Quality:          fromFieldQuality(s.Quality),
+		})
+	}
+	return result
+}
+
+// FromAlarms преобразует список тревог.
+func FromAlarms(alarms []models.AlarmDetail) []*Alarm {
+	result := make([]*Alarm, 0, len(alarms))
+	for _, a := range alarms {
+		result = append(result, &Alarm{
+			ErrorCode:            a.ErrorCode,
+			ErrorTypeDescription: a.ErrorTypeDescription,
+			ErrorMessage:         a.ErrorMessage,
+		})
+	}
+	return result
+}
+
+func fromProgram(p models.CurrentProgramInfo) *Program {
+	return &Program{ProgramName: p.ProgramName, ProgramNumber: p.ProgramNumber, GCodeLine: p.GCodeLine}
+}
+
+func fromFieldQuality(q models.FieldQuality) map[string]Quality {
+	if len(q) == 0 {
+		return nil
+	}
+	result := make(map[string]Quality, len(q))
+	for field, quality := range q {
+		result[field] = FromQuality(quality)
+	}
+	return result
+}
+
+// toValue преобразует произвольное значение в google.protobuf.Value через JSON.
+func toValue(v any) (*structpb.Value, error) {
+	if v == nil {
+		return structpb.NewNullValue(), nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	value := &structpb.Value{}
+	if err := value.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// timestamp преобразует время; нулевое время не передается.
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,1495 @@
+// Схема данных станка FANUC для публикации в брокеры сообщений и внешних API.
+// Поля соответствуют структурам пакета models и их JSON-представлению.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: fanuc/v1/fanuc.proto
+
+package fanucv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Качество значения (models.Quality).
+type Quality int32
+
+const (
+	Quality_QUALITY_UNSPECIFIED   Quality = 0
+	Quality_QUALITY_GOOD          Quality = 1
+	Quality_QUALITY_NOT_SUPPORTED Quality = 2
+	Quality_QUALITY_COMM_ERROR    Quality = 3
+	Quality_QUALITY_STALE         Quality = 4
+)
+
+// Enum value maps for Quality.
+var (
+	Quality_name = map[int32]string{
+		0: "QUALITY_UNSPECIFIED",
+		1: "QUALITY_GOOD",
+		2: "QUALITY_NOT_SUPPORTED",
+		3: "QUALITY_COMM_ERROR",
+		4: "QUALITY_STALE",
+	}
+	Quality_value = map[string]int32{
+		"QUALITY_UNSPECIFIED":   0,
+		"QUALITY_GOOD":          1,
+		"QUALITY_NOT_SUPPORTED": 2,
+		"QUALITY_COMM_ERROR":    3,
+		"QUALITY_STALE":         4,
+	}
+)
+
+func (x Quality) Enum() *Quality {
+	p := new(Quality)
+	*p = x
+	return p
+}
+
+func (x Quality) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Quality) Descriptor() protoreflect.EnumDescriptor {
+	return file_fanuc_v1_fanuc_proto_enumTypes[0].Descriptor()
+}
+
+func (Quality) Type() protoreflect.EnumType {
+	return &file_fanuc_v1_fanuc_proto_enumTypes[0]
+}
+
+func (x Quality) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Quality.Descriptor instead.
+func (Quality) EnumDescriptor() ([]byte, []int) {
+	return file_fanuc_v1_fanuc_proto_rawDescGZIP(), []int{0}
+}
+
+// Результат чтения раздела сводных данных (models.SectionStatus).
+type SectionStatus struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Результат последнего чтения: "ok", "error" или "unsupported".
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Quality       Quality                `protobuf:"varint,2,opt,name=quality,proto3,enum=fanuc.v1.Quality" json:"quality,omitempty"`
+	SourceTime    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=source_time,json=sourceTime,proto3" json:"source_time,omitempty"`
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SectionStatus) Reset() {
+	*x = SectionStatus{}
+	mi := &file_fanuc_v1_fanuc_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SectionStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SectionStatus) ProtoMessage() {}
+
+func (x *SectionStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_fanuc_v1_fanuc_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SectionStatus.ProtoReflect.Descriptor instead.
+func (*SectionStatus) Descriptor() ([]byte, []int) {
+	return file_fanuc_v1_fanuc_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SectionStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *SectionStatus) GetQuality() Quality {
+	if x != nil {
+		return x.Quality
+	}
+	return Quality_QUALITY_UNSPECIFIED
+}
+
+func (x *SectionStatus) GetSourceTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SourceTime
+	}
+	return nil
+}
+
+func (x *SectionStatus) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type Alarm struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	ErrorCode            string                 `protobuf:"bytes,1,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	ErrorTypeDescription string                 `protobuf:"bytes,2,opt,name=error_type_description,json=errorTypeDescription,proto3" json:"error_type_description,omitempty"`
+	ErrorMessage         string                 `protobuf:"bytes,3,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *Alarm) Reset() {
+	*x = Alarm{}
+	mi := &file_fanuc_v1_fanuc_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Alarm) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Alarm) ProtoMessage() {}
+
+func (x *Alarm) ProtoReflect() protoreflect.Message {
+	mi := &file_fanuc_v1_fanuc_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Alarm.ProtoReflect.Descriptor instead.
+func (*Alarm) Descriptor() ([]byte, []int) {
+	return file_fanuc_v1_fanuc_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Alarm) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *Alarm) GetErrorTypeDescription() string {
+	if x != nil {
+		return x.ErrorTypeDescription
+	}
+	return ""
+}
+
+func (x *Alarm) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+type Axis struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Name             string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Position         float64                `protobuf:"fixed64,2,opt,name=position,proto3" json:"position,omitempty"`
+	LoadPercent      float64                `protobuf:"fixed64,3,opt,name=load_percent,json=loadPercent,proto3" json:"load_percent,omitempty"`
+	ServoTemperature int32                  `protobuf:"varint,4,opt,name=servo_temperature,json=servoTemperature,proto3" json:"servo_temperature,omitempty"`
+	CoderTemperature int32                  `protobuf:"varint,5,opt,name=coder_temperature,json=coderTemperature,proto3" json:"coder_temperature,omitempty"`
+	PowerConsumption int32                  `protobuf:"varint,6,opt,name=power_consumption,json=powerConsumption,proto3" json:"power_consumption,omitempty"`
+	Diag_301         float64                `protobuf:"fixed64,7,opt,name=diag_301,json=diag301,proto3" json:"diag_301,omitempty"`
+	// Качество полей, которые не удалось прочитать, по JSON-именам полей.
+	Quality       map[string]Quality `protobuf:"bytes,8,rep,name=quality,proto3" json:"quality,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value,enum=fanuc.v1.Quality"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Axis) Reset() {
+	*x = Axis{}
+	mi := &file_fanuc_v1_fanuc_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Axis) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Axis) ProtoMessage() {}
+
+func (x *Axis) ProtoReflect() protoreflect.Message {
+	mi := &file_fanuc_v1_fanuc_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Axis.ProtoReflect.Descriptor instead.
+func (*Axis) Descriptor() ([]byte, []int) {
+	return file_fanuc_v1_fanuc_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Axis) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Axis) GetPosition() float64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *Axis) GetLoadPercent() float64 {
+	if x != nil {
+		return x.LoadPercent
+	}
+	return 0
+}
+
+func (x *Axis) GetServoTemperature() int32 {
+	if x != nil {
+		return x.ServoTemperature
+	}
+	return 0
+}
+
+func (x *Axis) GetCoderTemperature() int32 {
+	if x != nil {
+		return x.CoderTemperature
+	}
+	return 0
+}
+
+func (x *Axis) GetPowerConsumption() int32 {
+	if x != nil {
+		return x.PowerConsumption
+	}
+	return 0
+}
+
+func (x *Axis) GetDiag_301() float64 {
+	if x != nil {
+		return x.Diag_301
+	}
+	return 0
+}
+
+func (x *Axis) GetQuality() map[string]Quality {
+	if x != nil {
+		return x.Quality
+	}
+	return nil
+}
+
+type Spindle struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Number           int32                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	SpeedRpm         int32                  `protobuf:"varint,2,opt,name=speed_rpm,json=speedRpm,proto3" json:"speed_rpm,omitempty"`
+	LoadPercent      float64                `protobuf:"fixed64,3,opt,name=load_percent,json=loadPercent,proto3" json:"load_percent,omitempty"`
+	OverridePercent  int32                  `protobuf:"varint,4,opt,name=override_percent,json=overridePercent,proto3" json:"override_percent,omitempty"`
+	PowerConsumption int32                  `protobuf:"varint,5,opt,name=power_consumption,json=powerConsumption,proto3" json:"power_consumption,omitempty"`
+	Diag_411Value    int32                  `protobuf:"varint,6,opt,name=diag_411_value,json=diag411Value,proto3" json:"diag_411_value,omitempty"`
+	Quality          map[string]Quality     `protobuf:"bytes,7,rep,name=quality,proto3" json:"quality,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value,enum=fanuc.v1.Quality"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Spindle) Reset() {
+	*x = Spindle{}
+	mi := &file_fanuc_v1_fanuc_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Spindle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Spindle) ProtoMessage() {}
+
+func (x *Spindle) ProtoReflect() protoreflect.Message {
+	mi := &file_fanuc_v1_fanuc_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Spindle.ProtoReflect.Descriptor instead.
+func (*Spindle) Descriptor() ([]byte, []int) {
+	return file_fanuc_v1_fanuc_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Spindle) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *Spindle) GetSpeedRpm() int32 {
+	if x != nil {
+		return x.SpeedRpm
+	}
+	return 0
+}
+
+func (x *Spindle) GetLoadPercent() float64 {
+	if x != nil {
+		return x.LoadPercent
+	}
+	return 0
+}
+
+func (x *Spindle) GetOverridePercent() int32 {
+	if x != nil {
+		return x.OverridePercent
+	}
+	return 0
+}
+
+func (x *Spindle) GetPowerConsumption() int32 {
+	if x != nil {
+		return x.PowerConsumption
+	}
+	return 0
+}
+
+func (x *Spindle) GetDiag_411Value() int32 {
+	if x != nil {
+		return x.Diag_411Value
+	}
+	return 0
+}
+
+func (x *Spindle) GetQuality() map[string]Quality {
+	if x != nil {
+		return x.Quality
+	}
+	return nil
+}
+
+type Program struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProgramName   string                 `protobuf:"bytes,1,opt,name=program_name,json=programName,proto3" json:"program_name,omitempty"`
+	ProgramNumber int64                  `protobuf:"varint,2,opt,name=program_number,json=programNumber,proto3" json:"program_number,omitempty"`
+	GCodeLine     string                 `protobuf:"bytes,3,opt,name=g_code_line,json=gCodeLine,proto3" json:"g_code_line,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Program) Reset() {
+	*x = Program{}
+	mi := &file_fanuc_v1_fanuc_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Program) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Program) ProtoMessage() {}
+
+func (x *Program) ProtoReflect() protoreflect.Message {
+	mi := &file_fanuc_v1_fanuc_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Program.ProtoReflect.Descriptor instead.
+func (*Program) Descriptor() ([]byte, []int) {
+	return file_fanuc_v1_fanuc_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Program) GetProgramName() string {
+	if x != nil {
+		return x.ProgramName
+	}
+	return ""
+}
+
+func (x *Program) GetProgramNumber() int64 {
+	if x != nil {
+		return x.ProgramNumber
+	}
+	return 0
+}
+
+func (x *Program) GetGCodeLine() string {
+	if x != nil {
+		return x.GCodeLine
+	}
+	return ""
+}
+
+// Данные одного канала многоканального станка (models.PathData).
+type PathData struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Path               int32                  `protobuf:"varint,1,opt,name=path,proto3" json:"path,omitempty"`
+	MachineState       string                 `protobuf:"bytes,2,opt,name=machine_state,json=machineState,proto3" json:"machine_state,omitempty"`
+	ProgramMode        string                 `protobuf:"bytes,3,opt,name=program_mode,json=programMode,proto3" json:"program_mode,omitempty"`
+	TmMode             string                 `protobuf:"bytes,4,opt,name=tm_mode,json=tmMode,proto3" json:"tm_mode,omitempty"`
+	AxisMovementStatus string                 `protobuf:"bytes,5,opt,name=axis_movement_status,json=axisMovementStatus,proto3" json:"axis_movement_status,omitempty"`
+	MstbStatus         string                 `protobuf:"bytes,6,opt,name=mstb_status,json=mstbStatus,proto3" json:"mstb_status,omitempty"`
+	EmergencyStatus    string                 `protobuf:"bytes,7,opt,name=emergency_status,json=emergencyStatus,proto3" json:"emergency_status,omitempty"`
+	AlarmStatus        string                 `protobuf:"bytes,8,opt,name=alarm_status,json=alarmStatus,proto3" json:"alarm_status,omitempty"`
+	EditStatus         string                 `protobuf:"bytes,9,opt,name=edit_status,json=editStatus,proto3" json:"edit_status,omitempty"`
+	HasAlarms          bool                   `protobuf:"varint,10,opt,name=has_alarms,json=hasAlarms,proto3" json:"has_alarms,omitempty"`
+	Alarms             []*Alarm               `protobuf:"bytes,11,rep,name=alarms,proto3" json:"alarms,omitempty"`
+	Axes               []*Axis                `protobuf:"bytes,12,rep,name=axes,proto3" json:"axes,omitempty"`
+	Spindles           []*Spindle             `protobuf:"bytes,13,rep,name=spindles,proto3" json:"spindles,omitempty"`
+	CurrentProgram     *Program               `protobuf:"bytes,14,opt,name=current_program,json=currentProgram,proto3" json:"current_program,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *PathData) Reset() {
+	*x = PathData{}
+	mi := &file_fanuc_v1_fanuc_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PathData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PathData) ProtoMessage() {}
+
+func (x *PathData) ProtoReflect() protoreflect.Message {
+	mi := &file_fanuc_v1_fanuc_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PathData.ProtoReflect.Descriptor instead.
+func (*PathData) Descriptor() ([]byte, []int) {
+	return file_fanuc_v1_fanuc_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PathData) GetPath() int32 {
+	if x != nil {
+		return x.Path
+	}
+	return 0
+}
+
+func (x *PathData) GetMachineState() string {
+	if x != nil {
+		return x.MachineState
+	}
+	return ""
+}
+
+func (x *PathData) GetProgramMode() string {
+	if x != nil {
+		return x.ProgramMode
+	}
+	return ""
+}
+
+func (x *PathData) GetTmMode() string {
+	if x != nil {
+		return x.TmMode
+	}
+	return ""
+}
+
+func (x *PathData) GetAxisMovementStatus() string {
+	if x != nil {
+		return x.AxisMovementStatus
+	}
+	return ""
+}
+
+func (x *PathData) GetMstbStatus() string {
+	if x != nil {
+		return x.MstbStatus
+	}
+	return ""
+}
+
+func (x *PathData) GetEmergencyStatus() string {
+	if x != nil {
+		return x.EmergencyStatus
+	}
+	return ""
+}
+
+func (x *PathData) GetAlarmStatus() string {
+	if x != nil {
+		return x.AlarmStatus
+	}
+	return ""
+}
+
+func (x *PathData) GetEditStatus() string {
+	if x != nil {
+		return x.EditStatus
+	}
+	return ""
+}
+
+func (x *PathData) GetHasAlarms() bool {
+	if x != nil {
+		return x.HasAlarms
+	}
+	return false
+}
+
+func (x *PathData) GetAlarms() []*Alarm {
+	if x != nil {
+		return x.Alarms
+	}
+	return nil
+}
+
+func (x *PathData) GetAxes() []*Axis {
+	if x != nil {
+		return x.Axes
+	}
+	return nil
+}
+
+func (x *PathData) GetSpindles() []*Spindle {
+	if x != nil {
+		return x.Spindles
+	}
+	return nil
+}
+
+func (x *PathData) GetCurrentProgram() *Program {
+	if x != nil {
+		return x.CurrentProgram
+	}
+	return nil
+}
+
+// Сводные данные станка (models.AggregatedData).
+type MachineData struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	MachineId          string                 `protobuf:"bytes,1,opt,name=machine_id,json=machineId,proto3" json:"machine_id,omitempty"`
+	Timestamp          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	IsEnabled          bool                   `protobuf:"varint,3,opt,name=is_enabled,json=isEnabled,proto3" json:"is_enabled,omitempty"`
+	IsEmergency        bool                   `protobuf:"varint,4,opt,name=is_emergency,json=isEmergency,proto3" json:"is_emergency,omitempty"`
+	MachineState       string                 `protobuf:"bytes,5,opt,name=machine_state,json=machineState,proto3" json:"machine_state,omitempty"`
+	ProgramMode        string                 `protobuf:"bytes,6,opt,name=program_mode,json=programMode,proto3" json:"program_mode,omitempty"`
+	TmMode             string                 `protobuf:"bytes,7,opt,name=tm_mode,json=tmMode,proto3" json:"tm_mode,omitempty"`
+	AxisMovementStatus string                 `protobuf:"bytes,8,opt,name=axis_movement_status,json=axisMovementStatus,proto3" json:"axis_movement_status,omitempty"`
+	MstbStatus         string                 `protobuf:"bytes,9,opt,name=mstb_status,json=mstbStatus,proto3" json:"mstb_status,omitempty"`
+	EmergencyStatus    string                 `protobuf:"bytes,10,opt,name=emergency_status,json=emergencyStatus,proto3" json:"emergency_status,omitempty"`
+	AlarmStatus        string                 `protobuf:"bytes,11,opt,name=alarm_status,json=alarmStatus,proto3" json:"alarm_status,omitempty"`
+	EditStatus         string                 `protobuf:"bytes,12,opt,name=edit_status,json=editStatus,proto3" json:"edit_status,omitempty"`
+	Axes               []*Axis                `protobuf:"bytes,13,rep,name=axes,proto3" json:"axes,omitempty"`
+	HasAlarms          bool                   `protobuf:"varint,14,opt,name=has_alarms,json=hasAlarms,proto3" json:"has_alarms,omitempty"`
+	Alarms             []*Alarm               `protobuf:"bytes,15,rep,name=alarms,proto3" json:"alarms,omitempty"`
+	CurrentProgram     *Program               `protobuf:"bytes,16,opt,name=current_program,json=currentProgram,proto3" json:"current_program,omitempty"`
+	Spindles           []*Spindle             `protobuf:"bytes,17,rep,name=spindles,proto3" json:"spindles,omitempty"`
+	ContourFeedRate    int32                  `protobuf:"varint,18,opt,name=contour_feed_rate,json=contourFeedRate,proto3" json:"contour_feed_rate,omitempty"`
+	ActualFeedRate     int32                  `protobuf:"varint,19,opt,name=actual_feed_rate,json=actualFeedRate,proto3" json:"actual_feed_rate,omitempty"`
+	FeedOverride       int32                  `protobuf:"varint,20,opt,name=feed_override,json=feedOverride,proto3" json:"feed_override,omitempty"`
+	JogOverride        int32                  `protobuf:"varint,21,opt,name=jog_override,json=jogOverride,proto3" json:"jog_override,omitempty"`
+	PartsCount         int64                  `protobuf:"varint,22,opt,name=parts_count,json=partsCount,proto3" json:"parts_count,omitempty"`
+	PowerOnTime        string                 `protobuf:"bytes,23,opt,name=power_on_time,json=powerOnTime,proto3" json:"power_on_time,omitempty"`
+	OperatingTime      string                 `protobuf:"bytes,24,opt,name=operating_time,json=operatingTime,proto3" json:"operating_time,omitempty"`
+	CycleTime          string                 `protobuf:"bytes,25,opt,name=cycle_time,json=cycleTime,proto3" json:"cycle_time,omitempty"`
+	CuttingTime        string                 `protobuf:"bytes,26,opt,name=cutting_time,json=cuttingTime,proto3" json:"cutting_time,omitempty"`
+	Paths              []*PathData            `protobuf:"bytes,27,rep,name=paths,proto3" json:"paths,omitempty"`
+	// Качество и время чтения разделов по имени раздела (models.Section).
+	Sections      map[string]*SectionStatus `protobuf:"bytes,28,rep,name=sections,proto3" json:"sections,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MachineData) Reset() {
+	*x = MachineData{}
+	mi := &file_fanuc_v1_fanuc_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MachineData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MachineData) ProtoMessage() {}
+
+func (x *MachineData) ProtoReflect() protoreflect.Message {
+	mi := &file_fanuc_v1_fanuc_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MachineData.ProtoReflect.Descriptor instead.
+func (*MachineData) Descriptor() ([]byte, []int) {
+	return file_fanuc_v1_fanuc_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MachineData) GetMachineId() string {
+	if x != nil {
+		return x.MachineId
+	}
+	return ""
+}
+
+func (x *MachineData) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *MachineData) GetIsEnabled() bool {
+	if x != nil {
+		return x.IsEnabled
+	}
+	return false
+}
+
+func (x *MachineData) GetIsEmergency() bool {
+	if x != nil {
+		return x.IsEmergency
+	}
+	return false
+}
+
+func (x *MachineData) GetMachineState() string {
+	if x != nil {
+		return x.MachineState
+	}
+	return ""
+}
+
+func (x *MachineData) GetProgramMode() string {
+	if x != nil {
+		return x.ProgramMode
+	}
+	return ""
+}
+
+func (x *MachineData) GetTmMode() string {
+	if x != nil {
+		return x.TmMode
+	}
+	return ""
+}
+
+func (x *MachineData) GetAxisMovementStatus() string {
+	if x != nil {
+		return x.AxisMovementStatus
+	}
+	return ""
+}
+
+func (x *MachineData) GetMstbStatus() string {
+	if x != nil {
+		return x.MstbStatus
+	}
+	return ""
+}
+
+func (x *MachineData) GetEmergencyStatus() string {
+	if x != nil {
+		return x.EmergencyStatus
+	}
+	return ""
+}
+
+func (x *MachineData) GetAlarmStatus() string {
+	if x != nil {
+		return x.AlarmStatus
+	}
+	return ""
+}
+
+func (x *MachineData) GetEditStatus() string {
+	if x != nil {
+		return x.EditStatus
+	}
+	return ""
+}
+
+func (x *MachineData) GetAxes() []*Axis {
+	if x != nil {
+		return x.Axes
+	}
+	return nil
+}
+
+func (x *MachineData) GetHasAlarms() bool {
+	if x != nil {
+		return x.HasAlarms
+	}
+	return false
+}
+
+func (x *MachineData) GetAlarms() []*Alarm {
+	if x != nil {
+		return x.Alarms
+	}
+	return nil
+}
+
+func (x *MachineData) GetCurrentProgram() *Program {
+	if x != nil {
+		return x.CurrentProgram
+	}
+	return nil
+}
+
+func (x *MachineData) GetSpindles() []*Spindle {
+	if x != nil {
+		return x.Spindles
+	}
+	return nil
+}
+
+func (x *MachineData) GetContourFeedRate() int32 {
+	if x != nil {
+		return x.ContourFeedRate
+	}
+	return 0
+}
+
+func (x *MachineData) GetActualFeedRate() int32 {
+	if x != nil {
+		return x.ActualFeedRate
+	}
+	return 0
+}
+
+func (x *MachineData) GetFeedOverride() int32 {
+	if x != nil {
+		return x.FeedOverride
+	}
+	return 0
+}
+
+func (x *MachineData) GetJogOverride() int32 {
+	if x != nil {
+		return x.JogOverride
+	}
+	return 0
+}
+
+func (x *MachineData) GetPartsCount() int64 {
+	if x != nil {
+		return x.PartsCount
+	}
+	return 0
+}
+
+func (x *MachineData) GetPowerOnTime() string {
+	if x != nil {
+		return x.PowerOnTime
+	}
+	return ""
+}
+
+func (x *MachineData) GetOperatingTime() string {
+	if x != nil {
+		return x.OperatingTime
+	}
+	return ""
+}
+
+func (x *MachineData) GetCycleTime() string {
+	if x != nil {
+		return x.CycleTime
+	}
+	return ""
+}
+
+func (x *MachineData) GetCuttingTime() string {
+	if x != nil {
+		return x.CuttingTime
+	}
+	return ""
+}
+
+func (x *MachineData) GetPaths() []*PathData {
+	if x != nil {
+		return x.Paths
+	}
+	return nil
+}
+
+func (x *MachineData) GetSections() map[string]*SectionStatus {
+	if x != nil {
+		return x.Sections
+	}
+	return nil
+}
+
+// Событие изменения данных станка (models.MachineEvent).
+type MachineEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	MachineId     string                 `protobuf:"bytes,2,opt,name=machine_id,json=machineId,proto3" json:"machine_id,omitempty"`
+	Key           string                 `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	Before        *structpb.Value        `protobuf:"bytes,4,opt,name=before,proto3" json:"before,omitempty"`
+	After         *structpb.Value        `protobuf:"bytes,5,opt,name=after,proto3" json:"after,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MachineEvent) Reset() {
+	*x = MachineEvent{}
+	mi := &file_fanuc_v1_fanuc_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MachineEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MachineEvent) ProtoMessage() {}
+
+func (x *MachineEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_fanuc_v1_fanuc_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MachineEvent.ProtoReflect.Descriptor instead.
+func (*MachineEvent) Descriptor() ([]byte, []int) {
+	return file_fanuc_v1_fanuc_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *MachineEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *MachineEvent) GetMachineId() string {
+	if x != nil {
+		return x.MachineId
+	}
+	return ""
+}
+
+func (x *MachineEvent) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *MachineEvent) GetBefore() *structpb.Value {
+	if x != nil {
+		return x.Before
+	}
+	return nil
+}
+
+func (x *MachineEvent) GetAfter() *structpb.Value {
+	if x != nil {
+		return x.After
+	}
+	return nil
+}
+
+func (x *MachineEvent) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+// Системная информация станка (models.SystemInfo).
+type SystemInfo struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Manufacturer   string                 `protobuf:"bytes,1,opt,name=manufacturer,proto3" json:"manufacturer,omitempty"`
+	Model          string                 `protobuf:"bytes,2,opt,name=model,proto3" json:"model,omitempty"`
+	Series         string                 `protobuf:"bytes,3,opt,name=series,proto3" json:"series,omitempty"`
+	Version        string                 `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`
+	MaxAxes        int32                  `protobuf:"varint,5,opt,name=max_axes,json=maxAxes,proto3" json:"max_axes,omitempty"`
+	ControlledAxes int32                  `protobuf:"varint,6,opt,name=controlled_axes,json=controlledAxes,proto3" json:"controlled_axes,omitempty"`
+	Paths          int32                  `protobuf:"varint,7,opt,name=paths,proto3" json:"paths,omitempty"`
+	CncType        string                 `protobuf:"bytes,8,opt,name=cnc_type,json=cncType,proto3" json:"cnc_type,omitempty"`
+	MachineType    string                 `protobuf:"bytes,9,opt,name=machine_type,json=machineType,proto3" json:"machine_type,omitempty"`
+	ModelSeries    string                 `protobuf:"bytes,10,opt,name=model_series,json=modelSeries,proto3" json:"model_series,omitempty"`
+	ModelSource    string                 `protobuf:"bytes,11,opt,name=model_source,json=modelSource,proto3" json:"model_source,omitempty"`
+	Implementation string                 `protobuf:"bytes,12,opt,name=implementation,proto3" json:"implementation,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SystemInfo) Reset() {
+	*x = SystemInfo{}
+	mi := &file_fanuc_v1_fanuc_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SystemInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SystemInfo) ProtoMessage() {}
+
+func (x *SystemInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_fanuc_v1_fanuc_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SystemInfo.ProtoReflect.Descriptor instead.
+func (*SystemInfo) Descriptor() ([]byte, []int) {
+	return file_fanuc_v1_fanuc_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SystemInfo) GetManufacturer() string {
+	if x != nil {
+		return x.Manufacturer
+	}
+	return ""
+}
+
+func (x *SystemInfo) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *SystemInfo) GetSeries() string {
+	if x != nil {
+		return x.Series
+	}
+	return ""
+}
+
+func (x *SystemInfo) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *SystemInfo) GetMaxAxes() int32 {
+	if x != nil {
+		return x.MaxAxes
+	}
+	return 0
+}
+
+func (x *SystemInfo) GetControlledAxes() int32 {
+	if x != nil {
+		return x.ControlledAxes
+	}
+	return 0
+}
+
+func (x *SystemInfo) GetPaths() int32 {
+	if x != nil {
+		return x.Paths
+	}
+	return 0
+}
+
+func (x *SystemInfo) GetCncType() string {
+	if x != nil {
+		return x.CncType
+	}
+	return ""
+}
+
+func (x *SystemInfo) GetMachineType() string {
+	if x != nil {
+		return x.MachineType
+	}
+	return ""
+}
+
+func (x *SystemInfo) GetModelSeries() string {
+	if x != nil {
+		return x.ModelSeries
+	}
+	return ""
+}
+
+func (x *SystemInfo) GetModelSource() string {
+	if x != nil {
+		return x.ModelSource
+	}
+	return ""
+}
+
+func (x *SystemInfo) GetImplementation() string {
+	if x != nil {
+		return x.Implementation
+	}
+	return ""
+}
+
+// Счетчик деталей и наработка (models.ParameterInfo).
+type ParameterInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PartsCount    int64                  `protobuf:"varint,1,opt,name=parts_count,json=partsCount,proto3" json:"parts_count,omitempty"`
+	PowerOnTime   string                 `protobuf:"bytes,2,opt,name=power_on_time,json=powerOnTime,proto3" json:"power_on_time,omitempty"`
+	OperatingTime string                 `protobuf:"bytes,3,opt,name=operating_time,json=operatingTime,proto3" json:"operating_time,omitempty"`
+	CycleTime     string                 `protobuf:"bytes,4,opt,name=cycle_time,json=cycleTime,proto3" json:"cycle_time,omitempty"`
+	CuttingTime   string                 `protobuf:"bytes,5,opt,name=cutting_time,json=cuttingTime,proto3" json:"cutting_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ParameterInfo) Reset() {
+	*x = ParameterInfo{}
+	mi := &file_fanuc_v1_fanuc_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParameterInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParameterInfo) ProtoMessage() {}
+
+func (x *ParameterInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_fanuc_v1_fanuc_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParameterInfo.ProtoReflect.Descriptor instead.
+func (*ParameterInfo) Descriptor() ([]byte, []int) {
+	return file_fanuc_v1_fanuc_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ParameterInfo) GetPartsCount() int64 {
+	if x != nil {
+		return x.PartsCount
+	}
+	return 0
+}
+
+func (x *ParameterInfo) GetPowerOnTime() string {
+	if x != nil {
+		return x.PowerOnTime
+	}
+	return ""
+}
+
+func (x *ParameterInfo) GetOperatingTime() string {
+	if x != nil {
+		return x.OperatingTime
+	}
+	return ""
+}
+
+func (x *ParameterInfo) GetCycleTime() string {
+	if x != nil {
+		return x.CycleTime
+	}
+	return ""
+}
+
+func (x *ParameterInfo) GetCuttingTime() string {
+	if x != nil {
+		return x.CuttingTime
+	}
+	return ""
+}
+
+// Состояние соединения клиента со станком (models.ConnectionStatus).
+type ConnectionStatus struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	State          string                 `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	Since          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=since,proto3" json:"since,omitempty"`
+	LastError      string                 `protobuf:"bytes,3,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	LastErrorTime  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=last_error_time,json=lastErrorTime,proto3" json:"last_error_time,omitempty"`
+	LastSuccess    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_success,json=lastSuccess,proto3" json:"last_success,omitempty"`
+	ReconnectCount int32                  `protobuf:"varint,6,opt,name=reconnect_count,json=reconnectCount,proto3" json:"reconnect_count,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ConnectionStatus) Reset() {
+	*x = ConnectionStatus{}
+	mi := &file_fanuc_v1_fanuc_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConnectionStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnectionStatus) ProtoMessage() {}
+
+func (x *ConnectionStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_fanuc_v1_fanuc_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConnectionStatus.ProtoReflect.Descriptor instead.
+func (*ConnectionStatus) Descriptor() ([]byte, []int) {
+	return file_fanuc_v1_fanuc_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ConnectionStatus) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *ConnectionStatus) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *ConnectionStatus) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *ConnectionStatus) GetLastErrorTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastErrorTime
+	}
+	return nil
+}
+
+func (x *ConnectionStatus) GetLastSuccess() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSuccess
+	}
+	return nil
+}
+
+func (x *ConnectionStatus) GetReconnectCount() int32 {
+	if x != nil {
+		return x.ReconnectCount
+	}
+	return 0
+}
+
+var File_fanuc_v1_fanuc_proto protoreflect.FileDescriptor
+
+const file_fanuc_v1_fanuc_proto_rawDesc = "" +
+	"\n" +
+	"\x14fanuc/v1/fanuc.proto\x12\bfanuc.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa7\x01\n" +
+	"\rSectionStatus\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12+\n" +
+	"\aquality\x18\x02 \x01(\x0e2\x11.fanuc.v1.QualityR\aquality\x12;\n" +
+	"\vsource_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"sourceTime\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\x81\x01\n" +
+	"\x05Alarm\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x01 \x01(\tR\terrorCode\x124\n" +
+	"\x16error_type_description\x18\x02 \x01(\tR\x14errorTypeDescription\x12#\n" +
+	"\rerror_message\x18\x03 \x01(\tR\ferrorMessage\"\x81\x03\n" +
+	"\x04Axis\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\bposition\x18\x02 \x01(\x01R\bposition\x12!\n" +
+	"\fload_percent\x18\x03 \x01(\x01R\vloadPercent\x12+\n" +
+	"\x11servo_temperature\x18\x04 \x01(\x05R\x10servoTemperature\x12+\n" +
+	"\x11coder_temperature\x18\x05 \x01(\x05R\x10coderTemperature\x12+\n" +
+	"\x11power_consumption\x18\x06 \x01(\x05R\x10powerConsumption\x12\x19\n" +
+	"\bdiag_301\x18\a \x01(\x01R\adiag301\x125\n" +
+	"\aquality\x18\b \x03(\v2\x1b.fanuc.v1.Axis.QualityEntryR\aquality\x1aM\n" +
+	"\fQualityEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12'\n" +
+	"\x05value\x18\x02 \x01(\x0e2\x11.fanuc.v1.QualityR\x05value:\x028\x01\"\xe8\x02\n" +
+	"\aSpindle\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x05R\x06number\x12\x1b\n" +
+	"\tspeed_rpm\x18\x02 \x01(\x05R\bspeedRpm\x12!\n" +
+	"\fload_percent\x18\x03 \x01(\x01R\vloadPercent\x12)\n" +
+	"\x10override_percent\x18\x04 \x01(\x05R\x0foverridePercent\x12+\n" +
+	"\x11power_consumption\x18\x05 \x01(\x05R\x10powerConsumption\x12$\n" +
+	"\x0ediag_411_value\x18\x06 \x01(\x05R\fdiag411Value\x128\n" +
+	"\aquality\x18\a \x03(\v2\x1e.fanuc.v1.Spindle.QualityEntryR\aquality\x1aM\n" +
+	"\fQualityEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12'\n" +
+	"\x05value\x18\x02 \x01(\x0e2\x11.fanuc.v1.QualityR\x05value:\x028\x01\"s\n" +
+	"\aProgram\x12!\n" +
+	"\fprogram_name\x18\x01 \x01(\tR\vprogramName\x12%\n" +
+	"\x0eprogram_number\x18\x02 \x01(\x03R\rprogramNumber\x12\x1e\n" +
+	"\vg_code_line\x18\x03 \x01(\tR\tgCodeLine\"\x98\x04\n" +
+	"\bPathData\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\x05R\x04path\x12#\n" +
+	"\rmachine_state\x18\x02 \x01(\tR\fmachineState\x12!\n" +
+	"\fprogram_mode\x18\x03 \x01(\tR\vprogramMode\x12\x17\n" +
+	"\atm_mode\x18\x04 \x01(\tR\x06tmMode\x120\n" +
+	"\x14axis_movement_status\x18\x05 \x01(\tR\x12axisMovementStatus\x12\x1f\n" +
+	"\vmstb_status\x18\x06 \x01(\tR\n" +
+	"mstbStatus\x12)\n" +
+	"\x10emergency_status\x18\a \x01(\tR\x0femergencyStatus\x12!\n" +
+	"\falarm_status\x18\b \x01(\tR\valarmStatus\x12\x1f\n" +
+	"\vedit_status\x18\t \x01(\tR\n" +
+	"editStatus\x12\x1d\n" +
+	"\n" +
+	"has_alarms\x18\n" +
+	" \x01(\bR\thasAlarms\x12'\n" +
+	"\x06alarms\x18\v \x03(\v2\x0f.fanuc.v1.AlarmR\x06alarms\x12\"\n" +
+	"\x04axes\x18\f \x03(\v2\x0e.fanuc.v1.AxisR\x04axes\x12-\n" +
+	"\bspindles\x18\r \x03(\v2\x11.fanuc.v1.SpindleR\bspindles\x12:\n" +
+	"\x0fcurrent_program\x18\x0e \x01(\v2\x11.fanuc.v1.ProgramR\x0ecurrentProgram\"\xaf\t\n" +
+	"\vMachineData\x12\x1d\n" +
+	"\n" +
+	"machine_id\x18\x01 \x01(\tR\tmachineId\x128\n" +
+	"\ttimestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x1d\n" +
+	"\n" +
+	"is_enabled\x18\x03 \x01(\bR\tisEnabled\x12!\n" +
+	"\fis_emergency\x18\x04 \x01(\bR\visEmergency\x12#\n" +
+	"\rmachine_state\x18\x05 \x01(\tR\fmachineState\x12!\n" +
+	"\fprogram_mode\x18\x06 \x01(\tR\vprogramMode\x12\x17\n" +
+	"\atm_mode\x18\a \x01(\tR\x06tmMode\x120\n" +
+	"\x14axis_movement_status\x18\b \x01(\tR\x12axisMovementStatus\x12\x1f\n" +
+	"\vmstb_status\x18\t \x01(\tR\n" +
+	"mstbStatus\x12)\n" +
+	"\x10emergency_status\x18\n" +
+	" \x01(\tR\x0femergencyStatus\x12!\n" +
+	"\falarm_status\x18\v \x01(\tR\valarmStatus\x12\x1f\n" +
+	"\vedit_status\x18\f \x01(\tR\n" +
+	"editStatus\x12\"\n" +
+	"\x04axes\x18\r \x03(\v2\x0e.fanuc.v1.AxisR\x04axes\x12\x1d\n" +
+	"\n" +
+	"has_alarms\x18\x0e \x01(\bR\thasAlarms\x12'\n" +
+	"\x06alarms\x18\x0f \x03(\v2\x0f.fanuc.v1.AlarmR\x06alarms\x12:\n" +
+	"\x0fcurrent_program\x18\x10 \x01(\v2\x11.fanuc.v1.ProgramR\x0ecurrentProgram\x12-\n" +
+	"\bspindles\x18\x11 \x03(\v2\x11.fanuc.v1.SpindleR\bspindles\x12*\n" +
+	"\x11contour_feed_rate\x18\x12 \x01(\x05R\x0fcontourFeedRate\x12(\n" +
+	"\x10actual_feed_rate\x18\x13 \x01(\x05R\x0eactualFeedRate\x12#\n" +
+	"\rfeed_override\x18\x14 \x01(\x05R\ffeedOverride\x12!\n" +
+	"\fjog_override\x18\x15 \x01(\x05R\vjogOverride\x12\x1f\n" +
+	"\vparts_count\x18\x16 \x01(\x03R\n" +
+	"partsCount\x12\"\n" +
+	"\rpower_on_time\x18\x17 \x01(\tR\vpowerOnTime\x12%\n" +
+	"\x0eoperating_time\x18\x18 \x01(\tR\roperatingTime\x12\x1d\n" +
+	"\n" +
+	"cycle_time\x18\x19 \x01(\tR\tcycleTime\x12!\n" +
+	"\fcutting_time\x18\x1a \x01(\tR\vcuttingTime\x12(\n" +
+	"\x05paths\x18\x1b \x03(\v2\x12.fanuc.v1.PathDataR\x05paths\x12?\n" +
+	"\bsections\x18\x1c \x03(\v2#.fanuc.v1.MachineData.SectionsEntryR\bsections\x1aT\n" +
+	"\rSectionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12-\n" +
+	"\x05value\x18\x02 \x01(\v2\x17.fanuc.v1.SectionStatusR\x05value:\x028\x01\"\xeb\x01\n" +
+	"\fMachineEvent\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x1d\n" +
+	"\n" +
+	"machine_id\x18\x02 \x01(\tR\tmachineId\x12\x10\n" +
+	"\x03key\x18\x03 \x01(\tR\x03key\x12.\n" +
+	"\x06before\x18\x04 \x01(\v2\x16.google.protobuf.ValueR\x06before\x12,\n" +
+	"\x05after\x18\x05 \x01(\v2\x16.google.protobuf.ValueR\x05after\x128\n" +
+	"\ttimestamp\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\xfe\x02\n" +
+	"\n" +
+	"SystemInfo\x12\"\n" +
+	"\fmanufacturer\x18\x01 \x01(\tR\fmanufacturer\x12\x14\n" +
+	"\x05model\x18\x02 \x01(\tR\x05model\x12\x16\n" +
+	"\x06series\x18\x03 \x01(\tR\x06series\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\tR\aversion\x12\x19\n" +
+	"\bmax_axes\x18\x05 \x01(\x05R\amaxAxes\x12'\n" +
+	"\x0fcontrolled_axes\x18\x06 \x01(\x05R\x0econtrolledAxes\x12\x14\n" +
+	"\x05paths\x18\a \x01(\x05R\x05paths\x12\x19\n" +
+	"\bcnc_type\x18\b \x01(\tR\acncType\x12!\n" +
+	"\fmachine_type\x18\t \x01(\tR\vmachineType\x12!\n" +
+	"\fmodel_series\x18\n" +
+	" \x01(\tR\vmodelSeries\x12!\n" +
+	"\fmodel_source\x18\v \x01(\tR\vmodelSource\x12&\n" +
+	"\x0eimplementation\x18\f \x01(\tR\x0eimplementation\"\xbd\x01\n" +
+	"\rParameterInfo\x12\x1f\n" +
+	"\vparts_count\x18\x01 \x01(\x03R\n" +
+	"partsCount\x12\"\n" +
+	"\rpower_on_time\x18\x02 \x01(\tR\vpowerOnTime\x12%\n" +
+	"\x0eoperating_time\x18\x03 \x01(\tR\roperatingTime\x12\x1d\n" +
+	"\n" +
+	"cycle_time\x18\x04 \x01(\tR\tcycleTime\x12!\n" +
+	"\fcutting_time\x18\x05 \x01(\tR\vcuttingTime\"\xa5\x02\n" +
+	"\x10ConnectionStatus\x12\x14\n" +
+	"\x05state\x18\x01 \x01(\tR\x05state\x120\n" +
+	"\x05since\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x03 \x01(\tR\tlastError\x12B\n" +
+	"\x0flast_error_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\rlastErrorTime\x12=\n" +
+	"\flast_success\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\vlastSuccess\x12'\n" +
+	"\x0freconnect_count\x18\x06 \x01(\x05R\x0ereconnectCount*z\n" +
+	"\aQuality\x12\x17\n" +
+	"\x13QUALITY_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fQUALITY_GOOD\x10\x01\x12\x19\n" +
+	"\x15QUALITY_NOT_SUPPORTED\x10\x02\x12\x16\n" +
+	"\x12QUALITY_COMM_ERROR\x10\x03\x12\x11\n" +
+	"\rQUALITY_STALE\x10\x04BIZ4github.com/iwtcode/fanucAdapter/api/fanuc/v1;fanucv1\xaa\x02\x10IwtCode.Fanuc.V1b\x06proto3"
+
+var (
+	file_fanuc_v1_fanuc_proto_rawDescOnce sync.Once
+	file_fanuc_v1_fanuc_proto_rawDescData []byte
+)
+
+func file_fanuc_v1_fanuc_proto_rawDescGZIP() []byte {
+	file_fanuc_v1_fanuc_proto_rawDescOnce.Do(func() {
+		file_fanuc_v1_fanuc_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_fanuc_v1_fanuc_proto_rawDesc), len(file_fanuc_v1_fanuc_proto_rawDesc)))
+	})
+	return file_fanuc_v1_fanuc_proto_rawDescData
+}
+
+var file_fanuc_v1_fanuc_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_fanuc_v1_fanuc_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_fanuc_v1_fanuc_proto_goTypes = []any{
+	(Quality)(0),                  // 0: fanuc.v1.Quality
+	(*SectionStatus)(nil),         // 1: fanuc.v1.SectionStatus
+	(*Alarm)(nil),                 // 2: fanuc.v1.Alarm
+	(*Axis)(nil),                  // 3: fanuc.v1.Axis
+	(*Spindle)(nil),               // 4: fanuc.v1.Spindle
+	(*Program)(nil),               // 5: fanuc.v1.Program
+	(*PathData)(nil),              // 6: fanuc.v1.PathData
+	(*MachineData)(nil),           // 7: fanuc.v1.MachineData
+	(*MachineEvent)(nil),          // 8: fanuc.v1.MachineEvent
+	(*SystemInfo)(nil),            // 9: fanuc.v1.SystemInfo
+	(*ParameterInfo)(nil),         // 10: fanuc.v1.ParameterInfo
+	(*ConnectionStatus)(nil),      // 11: fanuc.v1.ConnectionStatus
+	nil,                           // 12: fanuc.v1.Axis.QualityEntry
+	nil,                           // 13: fanuc.v1.Spindle.QualityEntry
+	nil,                           // 14: fanuc.v1.MachineData.SectionsEntry
+	(*timestamppb.Timestamp)(nil), // 15: google.protobuf.Timestamp
+	(*structpb.Value)(nil),        // 16: google.protobuf.Value
+}
+var file_fanuc_v1_fanuc_proto_depIdxs = []int32{
+	0,  // 0: fanuc.v1.SectionStatus.quality:type_name -> fanuc.v1.Quality
+	15, // 1: fanuc.v1.SectionStatus.source_time:type_name -> google.protobuf.Timestamp
+	12, // 2: fanuc.v1.Axis.quality:type_name -> fanuc.v1.Axis.QualityEntry
+	13, // 3: fanuc.v1.Spindle.quality:type_name -> fanuc.v1.Spindle.QualityEntry
+	2,  // 4: fanuc.v1.PathData.alarms:type_name -> fanuc.v1.Alarm
+	3,  // 5: fanuc.v1.PathData.axes:type_name -> fanuc.v1.Axis
+	4,  // 6: fanuc.v1.PathData.spindles:type_name -> fanuc.v1.Spindle
+	5,  // 7: fanuc.v1.PathData.current_program:type_name -> fanuc.v1.Program
+	15, // 8: fanuc.v1.MachineData.timestamp:type_name -> google.protobuf.Timestamp
+	3,  // 9: fanuc.v1.MachineData.axes:type_name -> fanuc.v1.Axis
+	2,  // 10: fanuc.v1.MachineData.alarms:type_name -> fanuc.v1.Alarm
+	5,  // 11: fanuc.v1.MachineData.current_program:type_name -> fanuc.v1.Program
+	4,  // 12: fanuc.v1.MachineData.spindles:type_name -> fanuc.v1.Spindle
+	6,  // 13: fanuc.v1.MachineData.paths:type_name -> fanuc.v1.PathData
+	14, // 14: fanuc.v1.MachineData.sections:type_name -> fanuc.v1.MachineData.SectionsEntry
+	16, // 15: fanuc.v1.MachineEvent.before:type_name -> google.protobuf.Value
+	16, // 16: fanuc.v1.MachineEvent.after:type_name -> google.protobuf.Value
+	15, // 17: fanuc.v1.MachineEvent.timestamp:type_name -> google.protobuf.Timestamp
+	15, // 18: fanuc.v1.ConnectionStatus.since:type_name -> google.protobuf.Timestamp
+	15, // 19: fanuc.v1.ConnectionStatus.last_error_time:type_name -> google.protobuf.Timestamp
+	15, // 20: fanuc.v1.ConnectionStatus.last_success:type_name -> google.protobuf.Timestamp
+	0,  // 21: fanuc.v1.Axis.QualityEntry.value:type_name -> fanuc.v1.Quality
+	0,  // 22: fanuc.v1.Spindle.QualityEntry.value:type_name -> fanuc.v1.Quality
+	1,  // 23: fanuc.v1.MachineData.SectionsEntry.value:type_name -> fanuc.v1.SectionStatus
+	24, // [24:24] is the sub-list for method output_type
+	24, // [24:24] is the sub-list for method input_type
+	24, // [24:24] is the sub-list for extension type_name
+	24, // [24:24] is the sub-list for extension extendee
+	0,  // [0:24] is the sub-list for field type_name
+}
+
+func init() { file_fanuc_v1_fanuc_proto_init() }
+func file_fanuc_v1_fanuc_proto_init() {
+	if File_fanuc_v1_fanuc_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_fanuc_v1_fanuc_proto_rawDesc), len(file_fanuc_v1_fanuc_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_fanuc_v1_fanuc_proto_goTypes,
+		DependencyIndexes: file_fanuc_v1_fanuc_proto_depIdxs,
+		EnumInfos:         file_fanuc_v1_fanuc_proto_enumTypes,
+		MessageInfos:      file_fanuc_v1_fanuc_proto_msgTypes,
+	}.Build()
+	File_fanuc_v1_fanuc_proto = out.File
+	file_fanuc_v1_fanuc_proto_goTypes = nil
+	file_fanuc_v1_fanuc_proto_depIdxs = nil
+}
@@ -0,0 +1,720 @@
+// gRPC-сервис чтения данных станков FANUC: разовые чтения и потоковая
+// подписка на снимки и события изменений.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: fanuc/v1/service.proto
+
+package fanucv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type MachineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MachineId     string                 `protobuf:"bytes,1,opt,name=machine_id,json=machineId,proto3" json:"machine_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MachineRequest) Reset() {
+	*x = MachineRequest{}
+	mi := &file_fanuc_v1_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MachineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MachineRequest) ProtoMessage() {}
+
+func (x *MachineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fanuc_v1_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MachineRequest.ProtoReflect.Descriptor instead.
+func (*MachineRequest) Descriptor() ([]byte, []int) {
+	return file_fanuc_v1_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *MachineRequest) GetMachineId() string {
+	if x != nil {
+		return x.MachineId
+	}
+	return ""
+}
+
+type ListMachinesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMachinesRequest) Reset() {
+	*x = ListMachinesRequest{}
+	mi := &file_fanuc_v1_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMachinesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMachinesRequest) ProtoMessage() {}
+
+func (x *ListMachinesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fanuc_v1_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMachinesRequest.ProtoReflect.Descriptor instead.
+func (*ListMachinesRequest) Descriptor() ([]byte, []int) {
+	return file_fanuc_v1_service_proto_rawDescGZIP(), []int{1}
+}
+
+type Machine struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Tags       map[string]string      `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Connection *ConnectionStatus      `protobuf:"bytes,3,opt,name=connection,proto3" json:"connection,omitempty"`
+	// Не заполняется до первого подключения.
+	SystemInfo    *SystemInfo `protobuf:"bytes,4,opt,name=system_info,json=systemInfo,proto3" json:"system_info,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Machine) Reset() {
+	*x = Machine{}
+	mi := &file_fanuc_v1_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Machine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Machine) ProtoMessage() {}
+
+func (x *Machine) ProtoReflect() protoreflect.Message {
+	mi := &file_fanuc_v1_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Machine.ProtoReflect.Descriptor instead.
+func (*Machine) Descriptor() ([]byte, []int) {
+	return file_fanuc_v1_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Machine) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Machine) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Machine) GetConnection() *ConnectionStatus {
+	if x != nil {
+		return x.Connection
+	}
+	return nil
+}
+
+func (x *Machine) GetSystemInfo() *SystemInfo {
+	if x != nil {
+		return x.SystemInfo
+	}
+	return nil
+}
+
+type ListMachinesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Machines      []*Machine             `protobuf:"bytes,1,rep,name=machines,proto3" json:"machines,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMachinesResponse) Reset() {
+	*x = ListMachinesResponse{}
+	mi := &file_fanuc_v1_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMachinesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMachinesResponse) ProtoMessage() {}
+
+func (x *ListMachinesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fanuc_v1_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMachinesResponse.ProtoReflect.Descriptor instead.
+func (*ListMachinesResponse) Descriptor() ([]byte, []int) {
+	return file_fanuc_v1_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListMachinesResponse) GetMachines() []*Machine {
+	if x != nil {
+		return x.Machines
+	}
+	return nil
+}
+
+type GetCurrentDataRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	MachineId string                 `protobuf:"bytes,1,opt,name=machine_id,json=machineId,proto3" json:"machine_id,omitempty"`
+	// Имена разделов (models.Section); пустой список — все разделы.
+	Sections      []string `protobuf:"bytes,2,rep,name=sections,proto3" json:"sections,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCurrentDataRequest) Reset() {
+	*x = GetCurrentDataRequest{}
+	mi := &file_fanuc_v1_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCurrentDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCurrentDataRequest) ProtoMessage() {}
+
+func (x *GetCurrentDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fanuc_v1_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCurrentDataRequest.ProtoReflect.Descriptor instead.
+func (*GetCurrentDataRequest) Descriptor() ([]byte, []int) {
+	return file_fanuc_v1_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetCurrentDataRequest) GetMachineId() string {
+	if x != nil {
+		return x.MachineId
+	}
+	return ""
+}
+
+func (x *GetCurrentDataRequest) GetSections() []string {
+	if x != nil {
+		return x.Sections
+	}
+	return nil
+}
+
+type GetAxesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Axes          []*Axis                `protobuf:"bytes,1,rep,name=axes,proto3" json:"axes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAxesResponse) Reset() {
+	*x = GetAxesResponse{}
+	mi := &file_fanuc_v1_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAxesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAxesResponse) ProtoMessage() {}
+
+func (x *GetAxesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fanuc_v1_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAxesResponse.ProtoReflect.Descriptor instead.
+func (*GetAxesResponse) Descriptor() ([]byte, []int) {
+	return file_fanuc_v1_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetAxesResponse) GetAxes() []*Axis {
+	if x != nil {
+		return x.Axes
+	}
+	return nil
+}
+
+type GetSpindlesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Spindles      []*Spindle             `protobuf:"bytes,1,rep,name=spindles,proto3" json:"spindles,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSpindlesResponse) Reset() {
+	*x = GetSpindlesResponse{}
+	mi := &file_fanuc_v1_service_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSpindlesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSpindlesResponse) ProtoMessage() {}
+
+func (x *GetSpindlesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fanuc_v1_service_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSpindlesResponse.ProtoReflect.Descriptor instead.
+func (*GetSpindlesResponse) Descriptor() ([]byte, []int) {
+	return file_fanuc_v1_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetSpindlesResponse) GetSpindles() []*Spindle {
+	if x != nil {
+		return x.Spindles
+	}
+	return nil
+}
+
+type GetAlarmsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Alarms        []*Alarm               `protobuf:"bytes,1,rep,name=alarms,proto3" json:"alarms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAlarmsResponse) Reset() {
+	*x = GetAlarmsResponse{}
+	mi := &file_fanuc_v1_service_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAlarmsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAlarmsResponse) ProtoMessage() {}
+
+func (x *GetAlarmsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fanuc_v1_service_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAlarmsResponse.ProtoReflect.Descriptor instead.
+func (*GetAlarmsResponse) Descriptor() ([]byte, []int) {
+	return file_fanuc_v1_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetAlarmsResponse) GetAlarms() []*Alarm {
+	if x != nil {
+		return x.Alarms
+	}
+	return nil
+}
+
+type WatchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID станков; пустой список — все станки сервера.
+	MachineIds []string `protobuf:"bytes,1,rep,name=machine_ids,json=machineIds,proto3" json:"machine_ids,omitempty"`
+	// Интервалы опроса по имени группы (fanuc.DataGroup: state, positions, spindles,
+	// feed, parameters, paths, system); пустая карта — интервалы по умолчанию.
+	Intervals map[string]*durationpb.Duration `protobuf:"bytes,2,rep,name=intervals,proto3" json:"intervals,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Передавать только события изменений, без снимков.
+	EventsOnly    bool `protobuf:"varint,3,opt,name=events_only,json=eventsOnly,proto3" json:"events_only,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_fanuc_v1_service_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fanuc_v1_service_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_fanuc_v1_service_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *WatchRequest) GetMachineIds() []string {
+	if x != nil {
+		return x.MachineIds
+	}
+	return nil
+}
+
+func (x *WatchRequest) GetIntervals() map[string]*durationpb.Duration {
+	if x != nil {
+		return x.Intervals
+	}
+	return nil
+}
+
+func (x *WatchRequest) GetEventsOnly() bool {
+	if x != nil {
+		return x.EventsOnly
+	}
+	return false
+}
+
+type WatchResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	MachineId string                 `protobuf:"bytes,1,opt,name=machine_id,json=machineId,proto3" json:"machine_id,omitempty"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*WatchResponse_Snapshot
+	//	*WatchResponse_Event
+	Payload       isWatchResponse_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchResponse) Reset() {
+	*x = WatchResponse{}
+	mi := &file_fanuc_v1_service_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchResponse) ProtoMessage() {}
+
+func (x *WatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fanuc_v1_service_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchResponse.ProtoReflect.Descriptor instead.
+func (*WatchResponse) Descriptor() ([]byte, []int) {
+	return file_fanuc_v1_service_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *WatchResponse) GetMachineId() string {
+	if x != nil {
+		return x.MachineId
+	}
+	return ""
+}
+
+func (x *WatchResponse) GetPayload() isWatchResponse_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *WatchResponse) GetSnapshot() *MachineData {
+	if x != nil {
+		if x, ok := x.Payload.(*WatchResponse_Snapshot); ok {
+			return x.Snapshot
+		}
+	}
+	return nil
+}
+
+func (x *WatchResponse) GetEvent() *MachineEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*WatchResponse_Event); ok {
+			return x.Event
+		}
+	}
+	return nil
+}
+
+type isWatchResponse_Payload interface {
+	isWatchResponse_Payload()
+}
+
+type WatchResponse_Snapshot struct {
+	Snapshot *MachineData `protobuf:"bytes,2,opt,name=snapshot,proto3,oneof"`
+}
+
+type WatchResponse_Event struct {
+	Event *MachineEvent `protobuf:"bytes,3,opt,name=event,proto3,oneof"`
+}
+
+func (*WatchResponse_Snapshot) isWatchResponse_Payload() {}
+
+func (*WatchResponse_Event) isWatchResponse_Payload() {}
+
+var File_fanuc_v1_service_proto protoreflect.FileDescriptor
+
+const file_fanuc_v1_service_proto_rawDesc = "" +
+	"\n" +
+	"\x16fanuc/v1/service.proto\x12\bfanuc.v1\x1a\x14fanuc/v1/fanuc.proto\x1a\x1egoogle/protobuf/duration.proto\"/\n" +
+	"\x0eMachineRequest\x12\x1d\n" +
+	"\n" +
+	"machine_id\x18\x01 \x01(\tR\tmachineId\"\x15\n" +
+	"\x13ListMachinesRequest\"\xf6\x01\n" +
+	"\aMachine\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12/\n" +
+	"\x04tags\x18\x02 \x03(\v2\x1b.fanuc.v1.Machine.TagsEntryR\x04tags\x12:\n" +
+	"\n" +
+	"connection\x18\x03 \x01(\v2\x1a.fanuc.v1.ConnectionStatusR\n" +
+	"connection\x125\n" +
+	"\vsystem_info\x18\x04 \x01(\v2\x14.fanuc.v1.SystemInfoR\n" +
+	"systemInfo\x1a7\n" +
+	"\tTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"E\n" +
+	"\x14ListMachinesResponse\x12-\n" +
+	"\bmachines\x18\x01 \x03(\v2\x11.fanuc.v1.MachineR\bmachines\"R\n" +
+	"\x15GetCurrentDataRequest\x12\x1d\n" +
+	"\n" +
+	"machine_id\x18\x01 \x01(\tR\tmachineId\x12\x1a\n" +
+	"\bsections\x18\x02 \x03(\tR\bsections\"5\n" +
+	"\x0fGetAxesResponse\x12\"\n" +
+	"\x04axes\x18\x01 \x03(\v2\x0e.fanuc.v1.AxisR\x04axes\"D\n" +
+	"\x13GetSpindlesResponse\x12-\n" +
+	"\bspindles\x18\x01 \x03(\v2\x11.fanuc.v1.SpindleR\bspindles\"<\n" +
+	"\x11GetAlarmsResponse\x12'\n" +
+	"\x06alarms\x18\x01 \x03(\v2\x0f.fanuc.v1.AlarmR\x06alarms\"\xee\x01\n" +
+	"\fWatchRequest\x12\x1f\n" +
+	"\vmachine_ids\x18\x01 \x03(\tR\n" +
+	"machineIds\x12C\n" +
+	"\tintervals\x18\x02 \x03(\v2%.fanuc.v1.WatchRequest.IntervalsEntryR\tintervals\x12\x1f\n" +
+	"\vevents_only\x18\x03 \x01(\bR\n" +
+	"eventsOnly\x1aW\n" +
+	"\x0eIntervalsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12/\n" +
+	"\x05value\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\x05value:\x028\x01\"\x9e\x01\n" +
+	"\rWatchResponse\x12\x1d\n" +
+	"\n" +
+	"machine_id\x18\x01 \x01(\tR\tmachineId\x123\n" +
+	"\bsnapshot\x18\x02 \x01(\v2\x15.fanuc.v1.MachineDataH\x00R\bsnapshot\x12.\n" +
+	"\x05event\x18\x03 \x01(\v2\x16.fanuc.v1.MachineEventH\x00R\x05eventB\t\n" +
+	"\apayload2\xf1\x04\n" +
+	"\x0eMachineService\x12M\n" +
+	"\fListMachines\x12\x1d.fanuc.v1.ListMachinesRequest\x1a\x1e.fanuc.v1.ListMachinesResponse\x12?\n" +
+	"\rGetSystemInfo\x12\x18.fanuc.v1.MachineRequest\x1a\x14.fanuc.v1.SystemInfo\x12H\n" +
+	"\x0eGetCurrentData\x12\x1f.fanuc.v1.GetCurrentDataRequest\x1a\x15.fanuc.v1.MachineData\x12>\n" +
+	"\aGetAxes\x12\x18.fanuc.v1.MachineRequest\x1a\x19.fanuc.v1.GetAxesResponse\x12F\n" +
+	"\vGetSpindles\x12\x18.fanuc.v1.MachineRequest\x1a\x1d.fanuc.v1.GetSpindlesResponse\x12B\n" +
+	"\tGetAlarms\x12\x18.fanuc.v1.MachineRequest\x1a\x1b.fanuc.v1.GetAlarmsResponse\x12B\n" +
+	"\rGetParameters\x12\x18.fanuc.v1.MachineRequest\x1a\x17.fanuc.v1.ParameterInfo\x129\n" +
+	"\n" +
+	"GetProgram\x12\x18.fanuc.v1.MachineRequest\x1a\x11.fanuc.v1.Program\x12:\n" +
+	"\x05Watch\x12\x16.fanuc.v1.WatchRequest\x1a\x17.fanuc.v1.WatchResponse0\x01BIZ4github.com/iwtcode/fanucAdapter/api/fanuc/v1;fanucv1\xaa\x02\x10IwtCode.Fanuc.V1b\x06proto3"
+
+var (
+	file_fanuc_v1_service_proto_rawDescOnce sync.Once
+	file_fanuc_v1_service_proto_rawDescData []byte
+)
+
+func file_fanuc_v1_service_proto_rawDescGZIP() []byte {
+	file_fanuc_v1_service_proto_rawDescOnce.Do(func() {
+		file_fanuc_v1_service_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_fanuc_v1_service_proto_rawDesc), len(file_fanuc_v1_service_proto_rawDesc)))
+	})
+	return file_fanuc_v1_service_proto_rawDescData
+}
+
+var file_fanuc_v1_service_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_fanuc_v1_service_proto_goTypes = []any{
+	(*MachineRequest)(nil),        // 0: fanuc.v1.MachineRequest
+	(*ListMachinesRequest)(nil),   // 1: fanuc.v1.ListMachinesRequest
+	(*Machine)(nil),               // 2: fanuc.v1.Machine
+	(*ListMachinesResponse)(nil),  // 3: fanuc.v1.ListMachinesResponse
+	(*GetCurrentDataRequest)(nil), // 4: fanuc.v1.GetCurrentDataRequest
+	(*GetAxesResponse)(nil),       // 5: fanuc.v1.GetAxesResponse
+	(*GetSpindlesResponse)(nil),   // 6: fanuc.v1.GetSpindlesResponse
+	(*GetAlarmsResponse)(nil),     // 7: fanuc.v1.GetAlarmsResponse
+	(*WatchRequest)(nil),          // 8: fanuc.v1.WatchRequest
+	(*WatchResponse)(nil),         // 9: fanuc.v1.WatchResponse
+	nil,                           // 10: fanuc.v1.Machine.TagsEntry
+	nil,                           // 11: fanuc.v1.WatchRequest.IntervalsEntry
+	(*ConnectionStatus)(nil),      // 12: fanuc.v1.ConnectionStatus
+	(*SystemInfo)(nil),            // 13: fanuc.v1.SystemInfo
+	(*Axis)(nil),                  // 14: fanuc.v1.Axis
+	(*Spindle)(nil),               // 15: fanuc.v1.Spindle
+	(*Alarm)(nil),                 // 16: fanuc.v1.Alarm
+	(*MachineData)(nil),           // 17: fanuc.v1.MachineData
+	(*MachineEvent)(nil),          // 18: fanuc.v1.MachineEvent
+	(*durationpb.Duration)(nil),   // 19: google.protobuf.Duration
+	(*ParameterInfo)(nil),         // 20: fanuc.v1.ParameterInfo
+	(*Program)(nil),               // 21: fanuc.v1.Program
+}
+var file_fanuc_v1_service_proto_depIdxs = []int32{
+	10, // 0: fanuc.v1.Machine.tags:type_name -> fanuc.v1.Machine.TagsEntry
+	12, // 1: fanuc.v1.Machine.connection:type_name -> fanuc.v1.ConnectionStatus
+	13, // 2: fanuc.v1.Machine.system_info:type_name -> fanuc.v1.SystemInfo
+	2,  // 3: fanuc.v1.ListMachinesResponse.machines:type_name -> fanuc.v1.Machine
+	14, // 4: fanuc.v1.GetAxesResponse.axes:type_name -> fanuc.v1.Axis
+	15, // 5: fanuc.v1.GetSpindlesResponse.spindles:type_name -> fanuc.v1.Spindle
+	16, // 6: fanuc.v1.GetAlarmsResponse.alarms:type_name -> fanuc.v1.Alarm
+	11, // 7: fanuc.v1.WatchRequest.intervals:type_name -> fanuc.v1.WatchRequest.IntervalsEntry
+	17, // 8: fanuc.v1.WatchResponse.snapshot:type_name -> fanuc.v1.MachineData
+	18, // 9: fanuc.v1.WatchResponse.event:type_name -> fanuc.v1.MachineEvent
+	19, // 10: fanuc.v1.WatchRequest.IntervalsEntry.value:type_name -> google.protobuf.Duration
+	1,  // 11: fanuc.v1.MachineService.ListMachines:input_type -> fanuc.v1.ListMachinesRequest
+	0,  // 12: fanuc.v1.MachineService.GetSystemInfo:input_type -> fanuc.v1.MachineRequest
+	4,  // 13: fanuc.v1.MachineService.GetCurrentData:input_type -> fanuc.v1.GetCurrentDataRequest
+	0,  // 14: fanuc.v1.MachineService.GetAxes:input_type -> fanuc.v1.MachineRequest
+	0,  // 15: fanuc.v1.MachineService.GetSpindles:input_type -> fanuc.v1.MachineRequest
+	0,  // 16: fanuc.v1.MachineService.GetAlarms:input_type -> fanuc.v1.MachineRequest
+	0,  // 17: fanuc.v1.MachineService.GetParameters:input_type -> fanuc.v1.MachineRequest
+	0,  // 18: fanuc.v1.MachineService.GetProgram:input_type -> fanuc.v1.MachineRequest
+	8,  // 19: fanuc.v1.MachineService.Watch:input_type -> fanuc.v1.WatchRequest
+	3,  // 20: fanuc.v1.MachineService.ListMachines:output_type -> fanuc.v1.ListMachinesResponse
+	13, // 21: fanuc.v1.MachineService.GetSystemInfo:output_type -> fanuc.v1.SystemInfo
+	17, // 22: fanuc.v1.MachineService.GetCurrentData:output_type -> fanuc.v1.MachineData
+	5,  // 23: fanuc.v1.MachineService.GetAxes:output_type -> fanuc.v1.GetAxesResponse
+	6,  // 24: fanuc.v1.MachineService.GetSpindles:output_type -> fanuc.v1.GetSpindlesResponse
+	7,  // 25: fanuc.v1.MachineService.GetAlarms:output_type -> fanuc.v1.GetAlarmsResponse
+	20, // 26: fanuc.v1.MachineService.GetParameters:output_type -> fanuc.v1.ParameterInfo
+	21, // 27: fanuc.v1.MachineService.GetProgram:output_type -> fanuc.v1.Program
+	9,  // 28: fanuc.v1.MachineService.Watch:output_type -> fanuc.v1.WatchResponse
+	20, // [20:29] is the sub-list for method output_type
+	11, // [11:20] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_fanuc_v1_service_proto_init() }
+func file_fanuc_v1_service_proto_init() {
+	if File_fanuc_v1_service_proto != nil {
+		return
+	}
+	file_fanuc_v1_fanuc_proto_init()
+	file_fanuc_v1_service_proto_msgTypes[9].OneofWrappers = []any{
+		(*WatchResponse_Snapshot)(nil),
+		(*WatchResponse_Event)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_fanuc_v1_service_proto_rawDesc), len(file_fanuc_v1_service_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_fanuc_v1_service_proto_goTypes,
+		DependencyIndexes: file_fanuc_v1_service_proto_depIdxs,
+		MessageInfos:      file_fanuc_v1_service_proto_msgTypes,
+	}.Build()
+	File_fanuc_v1_service_proto = out.File
+	file_fanuc_v1_service_proto_goTypes = nil
+	file_fanuc_v1_service_proto_depIdxs = nil
+}
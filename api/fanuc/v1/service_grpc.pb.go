@@ -0,0 +1,446 @@
+// gRPC-сервис чтения данных станков FANUC: разовые чтения и потоковая
+// подписка на снимки и события изменений.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: fanuc/v1/service.proto
+
+package fanucv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	MachineService_ListMachines_FullMethodName   = "/fanuc.v1.MachineService/ListMachines"
+	MachineService_GetSystemInfo_FullMethodName  = "/fanuc.v1.MachineService/GetSystemInfo"
+	MachineService_GetCurrentData_FullMethodName = "/fanuc.v1.MachineService/GetCurrentData"
+	MachineService_GetAxes_FullMethodName        = "/fanuc.v1.MachineService/GetAxes"
+	MachineService_GetSpindles_FullMethodName    = "/fanuc.v1.MachineService/GetSpindles"
+	MachineService_GetAlarms_FullMethodName      = "/fanuc.v1.MachineService/GetAlarms"
+	MachineService_GetParameters_FullMethodName  = "/fanuc.v1.MachineService/GetParameters"
+	MachineService_GetProgram_FullMethodName     = "/fanuc.v1.MachineService/GetProgram"
+	MachineService_Watch_FullMethodName          = "/fanuc.v1.MachineService/Watch"
+)
+
+// MachineServiceClient is the client API for MachineService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Ошибки чтения передаются кодами gRPC: станок недоступен — UNAVAILABLE, таймаут —
+// DEADLINE_EXCEEDED, функция или опция не поддерживается — UNIMPLEMENTED,
+// неизвестный станок — NOT_FOUND.
+type MachineServiceClient interface {
+	// Станки сервера и состояние соединения с ними.
+	ListMachines(ctx context.Context, in *ListMachinesRequest, opts ...grpc.CallOption) (*ListMachinesResponse, error)
+	GetSystemInfo(ctx context.Context, in *MachineRequest, opts ...grpc.CallOption) (*SystemInfo, error)
+	// Сводные данные; sections ограничивает читаемые разделы.
+	GetCurrentData(ctx context.Context, in *GetCurrentDataRequest, opts ...grpc.CallOption) (*MachineData, error)
+	GetAxes(ctx context.Context, in *MachineRequest, opts ...grpc.CallOption) (*GetAxesResponse, error)
+	GetSpindles(ctx context.Context, in *MachineRequest, opts ...grpc.CallOption) (*GetSpindlesResponse, error)
+	GetAlarms(ctx context.Context, in *MachineRequest, opts ...grpc.CallOption) (*GetAlarmsResponse, error)
+	GetParameters(ctx context.Context, in *MachineRequest, opts ...grpc.CallOption) (*ParameterInfo, error)
+	GetProgram(ctx context.Context, in *MachineRequest, opts ...grpc.CallOption) (*Program, error)
+	// Снимки данных и события изменений станков до отмены вызова.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchResponse], error)
+}
+
+type machineServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMachineServiceClient(cc grpc.ClientConnInterface) MachineServiceClient {
+	return &machineServiceClient{cc}
+}
+
+func (c *machineServiceClient) ListMachines(ctx context.Context, in *ListMachinesRequest, opts ...grpc.CallOption) (*ListMachinesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMachinesResponse)
+	err := c.cc.Invoke(ctx, MachineService_ListMachines_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *machineServiceClient) GetSystemInfo(ctx context.Context, in *MachineRequest, opts ...grpc.CallOption) (*SystemInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SystemInfo)
+	err := c.cc.Invoke(ctx, MachineService_GetSystemInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *machineServiceClient) GetCurrentData(ctx context.Context, in *GetCurrentDataRequest, opts ...grpc.CallOption) (*MachineData, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MachineData)
+	err := c.cc.Invoke(ctx, MachineService_GetCurrentData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *machineServiceClient) GetAxes(ctx context.Context, in *MachineRequest, opts ...grpc.CallOption) (*GetAxesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAxesResponse)
+	err := c.cc.Invoke(ctx, MachineService_GetAxes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *machineServiceClient) GetSpindles(ctx context.Context, in *MachineRequest, opts ...grpc.CallOption) (*GetSpindlesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSpindlesResponse)
+	err := c.cc.Invoke(ctx, MachineService_GetSpindles_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *machineServiceClient) GetAlarms(ctx context.Context, in *MachineRequest, opts ...grpc.CallOption) (*GetAlarmsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAlarmsResponse)
+	err := c.cc.Invoke(ctx, MachineService_GetAlarms_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *machineServiceClient) GetParameters(ctx context.Context, in *MachineRequest, opts ...grpc.CallOption) (*ParameterInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ParameterInfo)
+	err := c.cc.Invoke(ctx, MachineService_GetParameters_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *machineServiceClient) GetProgram(ctx context.Context, in *MachineRequest, opts ...grpc.CallOption) (*Program, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Program)
+	err := c.cc.Invoke(ctx, MachineService_GetProgram_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *machineServiceClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MachineService_ServiceDesc.Streams[0], MachineService_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, WatchResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MachineService_WatchClient = grpc.ServerStreamingClient[WatchResponse]
+
+// MachineServiceServer is the server API for MachineService service.
+// All implementations must embed UnimplementedMachineServiceServer
+// for forward compatibility.
+//
+// Ошибки чтения передаются кодами gRPC: станок недоступен — UNAVAILABLE, таймаут —
+// DEADLINE_EXCEEDED, функция или опция не поддерживается — UNIMPLEMENTED,
+// неизвестный станок — NOT_FOUND.
+type MachineServiceServer interface {
+	// Станки сервера и состояние соединения с ними.
+	ListMachines(context.Context, *ListMachinesRequest) (*ListMachinesResponse, error)
+	GetSystemInfo(context.Context, *MachineRequest) (*SystemInfo, error)
+	// Сводные данные; sections ограничивает читаемые разделы.
+	GetCurrentData(context.Context, *GetCurrentDataRequest) (*MachineData, error)
+	GetAxes(context.Context, *MachineRequest) (*GetAxesResponse, error)
+	GetSpindles(context.Context, *MachineRequest) (*GetSpindlesResponse, error)
+	GetAlarms(context.Context, *MachineRequest) (*GetAlarmsResponse, error)
+	GetParameters(context.Context, *MachineRequest) (*ParameterInfo, error)
+	GetProgram(context.Context, *MachineRequest) (*Program, error)
+	// Снимки данных и события изменений станков до отмены вызова.
+	Watch(*WatchRequest, grpc.ServerStreamingServer[WatchResponse]) error
+	mustEmbedUnimplementedMachineServiceServer()
+}
+
+// UnimplementedMachineServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMachineServiceServer struct{}
+
+func (UnimplementedMachineServiceServer) ListMachines(context.Context, *ListMachinesRequest) (*ListMachinesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMachines not implemented")
+}
+func (UnimplementedMachineServiceServer) GetSystemInfo(context.Context, *MachineRequest) (*SystemInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSystemInfo not implemented")
+}
+func (UnimplementedMachineServiceServer) GetCurrentData(context.Context, *GetCurrentDataRequest) (*MachineData, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCurrentData not implemented")
+}
+func (UnimplementedMachineServiceServer) GetAxes(context.Context, *MachineRequest) (*GetAxesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAxes not implemented")
+}
+func (UnimplementedMachineServiceServer) GetSpindles(context.Context, *MachineRequest) (*GetSpindlesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSpindles not implemented")
+}
+func (UnimplementedMachineServiceServer) GetAlarms(context.Context, *MachineRequest) (*GetAlarmsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAlarms not implemented")
+}
+func (UnimplementedMachineServiceServer) GetParameters(context.Context, *MachineRequest) (*ParameterInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetParameters not implemented")
+}
+func (UnimplementedMachineServiceServer) GetProgram(context.Context, *MachineRequest) (*Program, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProgram not implemented")
+}
+func (UnimplementedMachineServiceServer) Watch(*WatchRequest, grpc.ServerStreamingServer[WatchResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedMachineServiceServer) mustEmbedUnimplementedMachineServiceServer() {}
+func (UnimplementedMachineServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeMachineServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MachineServiceServer will
+// result in compilation errors.
+type UnsafeMachineServiceServer interface {
+	mustEmbedUnimplementedMachineServiceServer()
+}
+
+func RegisterMachineServiceServer(s grpc.ServiceRegistrar, srv MachineServiceServer) {
+	// If the following call pancis, it indicates UnimplementedMachineServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&MachineService_ServiceDesc, srv)
+}
+
+func _MachineService_ListMachines_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMachinesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MachineServiceServer).ListMachines(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MachineService_ListMachines_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MachineServiceServer).ListMachines(ctx, req.(*ListMachinesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MachineService_GetSystemInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MachineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MachineServiceServer).GetSystemInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MachineService_GetSystemInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MachineServiceServer).GetSystemInfo(ctx, req.(*MachineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MachineService_GetCurrentData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCurrentDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MachineServiceServer).GetCurrentData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MachineService_GetCurrentData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MachineServiceServer).GetCurrentData(ctx, req.(*GetCurrentDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MachineService_GetAxes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MachineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MachineServiceServer).GetAxes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MachineService_GetAxes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MachineServiceServer).GetAxes(ctx, req.(*MachineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MachineService_GetSpindles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MachineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MachineServiceServer).GetSpindles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MachineService_GetSpindles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MachineServiceServer).GetSpindles(ctx, req.(*MachineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MachineService_GetAlarms_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MachineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MachineServiceServer).GetAlarms(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MachineService_GetAlarms_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MachineServiceServer).GetAlarms(ctx, req.(*MachineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MachineService_GetParameters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MachineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MachineServiceServer).GetParameters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MachineService_GetParameters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MachineServiceServer).GetParameters(ctx, req.(*MachineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MachineService_GetProgram_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MachineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MachineServiceServer).GetProgram(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MachineService_GetProgram_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MachineServiceServer).GetProgram(ctx, req.(*MachineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MachineService_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MachineServiceServer).Watch(m, &grpc.GenericServerStream[WatchRequest, WatchResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MachineService_WatchServer = grpc.ServerStreamingServer[WatchResponse]
+
+// MachineService_ServiceDesc is the grpc.ServiceDesc for MachineService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MachineService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fanuc.v1.MachineService",
+	HandlerType: (*MachineServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListMachines",
+			Handler:    _MachineService_ListMachines_Handler,
+		},
+		{
+			MethodName: "GetSystemInfo",
+			Handler:    _MachineService_GetSystemInfo_Handler,
+		},
+		{
+			MethodName: "GetCurrentData",
+			Handler:    _MachineService_GetCurrentData_Handler,
+		},
+		{
+			MethodName: "GetAxes",
+			Handler:    _MachineService_GetAxes_Handler,
+		},
+		{
+			MethodName: "GetSpindles",
+			Handler:    _MachineService_GetSpindles_Handler,
+		},
+		{
+			MethodName: "GetAlarms",
+			Handler:    _MachineService_GetAlarms_Handler,
+		},
+		{
+			MethodName: "GetParameters",
+			Handler:    _MachineService_GetParameters_Handler,
+		},
+		{
+			MethodName: "GetProgram",
+			Handler:    _MachineService_GetProgram_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _MachineService_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "fanuc/v1/service.proto",
+}
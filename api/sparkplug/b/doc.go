@@ -0,0 +1,4 @@
+// Package sparkplugb содержит сообщения полезной нагрузки Sparkplug B (sparkplug_b.proto).
+package sparkplugb
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative sparkplug/b/sparkplug_b.proto
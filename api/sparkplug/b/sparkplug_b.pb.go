@@ -0,0 +1,533 @@
+// Схема полезной нагрузки Sparkplug B (Eclipse Tahu, sparkplug_b.proto).
+// Оставлены только поля, которые использует адаптер: остальные поля исходной схемы
+// (MetaData, PropertySet, DataSet, Template) не объявлены, но номера полей совпадают,
+// поэтому сообщения совместимы с любым клиентом Sparkplug B.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: sparkplug/b/sparkplug_b.proto
+
+package sparkplugb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Типы данных метрик.
+type DataType int32
+
+const (
+	DataType_Unknown  DataType = 0
+	DataType_Int8     DataType = 1
+	DataType_Int16    DataType = 2
+	DataType_Int32    DataType = 3
+	DataType_Int64    DataType = 4
+	DataType_UInt8    DataType = 5
+	DataType_UInt16   DataType = 6
+	DataType_UInt32   DataType = 7
+	DataType_UInt64   DataType = 8
+	DataType_Float    DataType = 9
+	DataType_Double   DataType = 10
+	DataType_Boolean  DataType = 11
+	DataType_String   DataType = 12
+	DataType_DateTime DataType = 13
+	DataType_Text     DataType = 14
+)
+
+// Enum value maps for DataType.
+var (
+	DataType_name = map[int32]string{
+		0:  "Unknown",
+		1:  "Int8",
+		2:  "Int16",
+		3:  "Int32",
+		4:  "Int64",
+		5:  "UInt8",
+		6:  "UInt16",
+		7:  "UInt32",
+		8:  "UInt64",
+		9:  "Float",
+		10: "Double",
+		11: "Boolean",
+		12: "String",
+		13: "DateTime",
+		14: "Text",
+	}
+	DataType_value = map[string]int32{
+		"Unknown":  0,
+		"Int8":     1,
+		"Int16":    2,
+		"Int32":    3,
+		"Int64":    4,
+		"UInt8":    5,
+		"UInt16":   6,
+		"UInt32":   7,
+		"UInt64":   8,
+		"Float":    9,
+		"Double":   10,
+		"Boolean":  11,
+		"String":   12,
+		"DateTime": 13,
+		"Text":     14,
+	}
+)
+
+func (x DataType) Enum() *DataType {
+	p := new(DataType)
+	*p = x
+	return p
+}
+
+func (x DataType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DataType) Descriptor() protoreflect.EnumDescriptor {
+	return file_sparkplug_b_sparkplug_b_proto_enumTypes[0].Descriptor()
+}
+
+func (DataType) Type() protoreflect.EnumType {
+	return &file_sparkplug_b_sparkplug_b_proto_enumTypes[0]
+}
+
+func (x DataType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Do not use.
+func (x *DataType) UnmarshalJSON(b []byte) error {
+	num, err := protoimpl.X.UnmarshalJSONEnum(x.Descriptor(), b)
+	if err != nil {
+		return err
+	}
+	*x = DataType(num)
+	return nil
+}
+
+// Deprecated: Use DataType.Descriptor instead.
+func (DataType) EnumDescriptor() ([]byte, []int) {
+	return file_sparkplug_b_sparkplug_b_proto_rawDescGZIP(), []int{0}
+}
+
+type Payload struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp     *uint64                `protobuf:"varint,1,opt,name=timestamp" json:"timestamp,omitempty"`
+	Metrics       []*Payload_Metric      `protobuf:"bytes,2,rep,name=metrics" json:"metrics,omitempty"`
+	Seq           *uint64                `protobuf:"varint,3,opt,name=seq" json:"seq,omitempty"`
+	Uuid          *string                `protobuf:"bytes,4,opt,name=uuid" json:"uuid,omitempty"`
+	Body          []byte                 `protobuf:"bytes,5,opt,name=body" json:"body,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Payload) Reset() {
+	*x = Payload{}
+	mi := &file_sparkplug_b_sparkplug_b_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Payload) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Payload) ProtoMessage() {}
+
+func (x *Payload) ProtoReflect() protoreflect.Message {
+	mi := &file_sparkplug_b_sparkplug_b_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Payload.ProtoReflect.Descriptor instead.
+func (*Payload) Descriptor() ([]byte, []int) {
+	return file_sparkplug_b_sparkplug_b_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Payload) GetTimestamp() uint64 {
+	if x != nil && x.Timestamp != nil {
+		return *x.Timestamp
+	}
+	return 0
+}
+
+func (x *Payload) GetMetrics() []*Payload_Metric {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *Payload) GetSeq() uint64 {
+	if x != nil && x.Seq != nil {
+		return *x.Seq
+	}
+	return 0
+}
+
+func (x *Payload) GetUuid() string {
+	if x != nil && x.Uuid != nil {
+		return *x.Uuid
+	}
+	return ""
+}
+
+func (x *Payload) GetBody() []byte {
+	if x != nil {
+		return x.Body
+	}
+	return nil
+}
+
+type Payload_Metric struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Name         *string                `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Alias        *uint64                `protobuf:"varint,2,opt,name=alias" json:"alias,omitempty"`
+	Timestamp    *uint64                `protobuf:"varint,3,opt,name=timestamp" json:"timestamp,omitempty"`
+	Datatype     *uint32                `protobuf:"varint,4,opt,name=datatype" json:"datatype,omitempty"`
+	IsHistorical *bool                  `protobuf:"varint,5,opt,name=is_historical,json=isHistorical" json:"is_historical,omitempty"`
+	IsTransient  *bool                  `protobuf:"varint,6,opt,name=is_transient,json=isTransient" json:"is_transient,omitempty"`
+	IsNull       *bool                  `protobuf:"varint,7,opt,name=is_null,json=isNull" json:"is_null,omitempty"`
+	// Types that are valid to be assigned to Value:
+	//
+	//	*Payload_Metric_IntValue
+	//	*Payload_Metric_LongValue
+	//	*Payload_Metric_FloatValue
+	//	*Payload_Metric_DoubleValue
+	//	*Payload_Metric_BooleanValue
+	//	*Payload_Metric_StringValue
+	//	*Payload_Metric_BytesValue
+	Value         isPayload_Metric_Value `protobuf_oneof:"value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Payload_Metric) Reset() {
+	*x = Payload_Metric{}
+	mi := &file_sparkplug_b_sparkplug_b_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Payload_Metric) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Payload_Metric) ProtoMessage() {}
+
+func (x *Payload_Metric) ProtoReflect() protoreflect.Message {
+	mi := &file_sparkplug_b_sparkplug_b_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Payload_Metric.ProtoReflect.Descriptor instead.
+func (*Payload_Metric) Descriptor() ([]byte, []int) {
+	return file_sparkplug_b_sparkplug_b_proto_rawDescGZIP(), []int{0, 0}
+}
+
+func (x *Payload_Metric) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *Payload_Metric) GetAlias() uint64 {
+	if x != nil && x.Alias != nil {
+		return *x.Alias
+	}
+	return 0
+}
+
+func (x *Payload_Metric) GetTimestamp() uint64 {
+	if x != nil && x.Timestamp != nil {
+		return *x.Timestamp
+	}
+	return 0
+}
+
+func (x *Payload_Metric) GetDatatype() uint32 {
+	if x != nil && x.Datatype != nil {
+		return *x.Datatype
+	}
+	return 0
+}
+
+func (x *Payload_Metric) GetIsHistorical() bool {
+	if x != nil && x.IsHistorical != nil {
+		return *x.IsHistorical
+	}
+	return false
+}
+
+func (x *Payload_Metric) GetIsTransient() bool {
+	if x != nil && x.IsTransient != nil {
+		return *x.IsTransient
+	}
+	return false
+}
+
+func (x *Payload_Metric) GetIsNull() bool {
+	if x != nil && x.IsNull != nil {
+		return *x.IsNull
+	}
+	return false
+}
+
+func (x *Payload_Metric) GetValue() isPayload_Metric_Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *Payload_Metric) GetIntValue() uint32 {
+	if x != nil {
+		if x, ok := x.Value.(*Payload_Metric_IntValue); ok {
+			return x.IntValue
+		}
+	}
+	return 0
+}
+
+func (x *Payload_Metric) GetLongValue() uint64 {
+	if x != nil {
+		if x, ok := x.Value.(*Payload_Metric_LongValue); ok {
+			return x.LongValue
+		}
+	}
+	return 0
+}
+
+func (x *Payload_Metric) GetFloatValue() float32 {
+	if x != nil {
+		if x, ok := x.Value.(*Payload_Metric_FloatValue); ok {
+			return x.FloatValue
+		}
+	}
+	return 0
+}
+
+func (x *Payload_Metric) GetDoubleValue() float64 {
+	if x != nil {
+		if x, ok := x.Value.(*Payload_Metric_DoubleValue); ok {
+			return x.DoubleValue
+		}
+	}
+	return 0
+}
+
+func (x *Payload_Metric) GetBooleanValue() bool {
+	if x != nil {
+		if x, ok := x.Value.(*Payload_Metric_BooleanValue); ok {
+			return x.BooleanValue
+		}
+	}
+	return false
+}
+
+func (x *Payload_Metric) GetStringValue() string {
+	if x != nil {
+		if x, ok := x.Value.(*Payload_Metric_StringValue); ok {
+			return x.StringValue
+		}
+	}
+	return ""
+}
+
+func (x *Payload_Metric) GetBytesValue() []byte {
+	if x != nil {
+		if x, ok := x.Value.(*Payload_Metric_BytesValue); ok {
+			return x.BytesValue
+		}
+	}
+	return nil
+}
+
+type isPayload_Metric_Value interface {
+	isPayload_Metric_Value()
+}
+
+type Payload_Metric_IntValue struct {
+	IntValue uint32 `protobuf:"varint,10,opt,name=int_value,json=intValue,oneof"`
+}
+
+type Payload_Metric_LongValue struct {
+	LongValue uint64 `protobuf:"varint,11,opt,name=long_value,json=longValue,oneof"`
+}
+
+type Payload_Metric_FloatValue struct {
+	FloatValue float32 `protobuf:"fixed32,12,opt,name=float_value,json=floatValue,oneof"`
+}
+
+type Payload_Metric_DoubleValue struct {
+	DoubleValue float64 `protobuf:"fixed64,13,opt,name=double_value,json=doubleValue,oneof"`
+}
+
+type Payload_Metric_BooleanValue struct {
+	BooleanValue bool `protobuf:"varint,14,opt,name=boolean_value,json=booleanValue,oneof"`
+}
+
+type Payload_Metric_StringValue struct {
+	StringValue string `protobuf:"bytes,15,opt,name=string_value,json=stringValue,oneof"`
+}
+
+type Payload_Metric_BytesValue struct {
+	BytesValue []byte `protobuf:"bytes,16,opt,name=bytes_value,json=bytesValue,oneof"`
+}
+
+func (*Payload_Metric_IntValue) isPayload_Metric_Value() {}
+
+func (*Payload_Metric_LongValue) isPayload_Metric_Value() {}
+
+func (*Payload_Metric_FloatValue) isPayload_Metric_Value() {}
+
+func (*Payload_Metric_DoubleValue) isPayload_Metric_Value() {}
+
+func (*Payload_Metric_BooleanValue) isPayload_Metric_Value() {}
+
+func (*Payload_Metric_StringValue) isPayload_Metric_Value() {}
+
+func (*Payload_Metric_BytesValue) isPayload_Metric_Value() {}
+
+var File_sparkplug_b_sparkplug_b_proto protoreflect.FileDescriptor
+
+const file_sparkplug_b_sparkplug_b_proto_rawDesc = "" +
+	"\n" +
+	"\x1dsparkplug/b/sparkplug_b.proto\x12\x19org.eclipse.tahu.protobuf\"\xf6\x04\n" +
+	"\aPayload\x12\x1c\n" +
+	"\ttimestamp\x18\x01 \x01(\x04R\ttimestamp\x12C\n" +
+	"\ametrics\x18\x02 \x03(\v2).org.eclipse.tahu.protobuf.Payload.MetricR\ametrics\x12\x10\n" +
+	"\x03seq\x18\x03 \x01(\x04R\x03seq\x12\x12\n" +
+	"\x04uuid\x18\x04 \x01(\tR\x04uuid\x12\x12\n" +
+	"\x04body\x18\x05 \x01(\fR\x04body\x1a\xcd\x03\n" +
+	"\x06Metric\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05alias\x18\x02 \x01(\x04R\x05alias\x12\x1c\n" +
+	"\ttimestamp\x18\x03 \x01(\x04R\ttimestamp\x12\x1a\n" +
+	"\bdatatype\x18\x04 \x01(\rR\bdatatype\x12#\n" +
+	"\ris_historical\x18\x05 \x01(\bR\fisHistorical\x12!\n" +
+	"\fis_transient\x18\x06 \x01(\bR\visTransient\x12\x17\n" +
+	"\ais_null\x18\a \x01(\bR\x06isNull\x12\x1d\n" +
+	"\tint_value\x18\n" +
+	" \x01(\rH\x00R\bintValue\x12\x1f\n" +
+	"\n" +
+	"long_value\x18\v \x01(\x04H\x00R\tlongValue\x12!\n" +
+	"\vfloat_value\x18\f \x01(\x02H\x00R\n" +
+	"floatValue\x12#\n" +
+	"\fdouble_value\x18\r \x01(\x01H\x00R\vdoubleValue\x12%\n" +
+	"\rboolean_value\x18\x0e \x01(\bH\x00R\fbooleanValue\x12#\n" +
+	"\fstring_value\x18\x0f \x01(\tH\x00R\vstringValue\x12!\n" +
+	"\vbytes_value\x18\x10 \x01(\fH\x00R\n" +
+	"bytesValueB\a\n" +
+	"\x05value*\xb9\x01\n" +
+	"\bDataType\x12\v\n" +
+	"\aUnknown\x10\x00\x12\b\n" +
+	"\x04Int8\x10\x01\x12\t\n" +
+	"\x05Int16\x10\x02\x12\t\n" +
+	"\x05Int32\x10\x03\x12\t\n" +
+	"\x05Int64\x10\x04\x12\t\n" +
+	"\x05UInt8\x10\x05\x12\n" +
+	"\n" +
+	"\x06UInt16\x10\x06\x12\n" +
+	"\n" +
+	"\x06UInt32\x10\a\x12\n" +
+	"\n" +
+	"\x06UInt64\x10\b\x12\t\n" +
+	"\x05Float\x10\t\x12\n" +
+	"\n" +
+	"\x06Double\x10\n" +
+	"\x12\v\n" +
+	"\aBoolean\x10\v\x12\n" +
+	"\n" +
+	"\x06String\x10\f\x12\f\n" +
+	"\bDateTime\x10\r\x12\b\n" +
+	"\x04Text\x10\x0eB<Z:github.com/iwtcode/fanucAdapter/api/sparkplug/b;sparkplugb"
+
+var (
+	file_sparkplug_b_sparkplug_b_proto_rawDescOnce sync.Once
+	file_sparkplug_b_sparkplug_b_proto_rawDescData []byte
+)
+
+func file_sparkplug_b_sparkplug_b_proto_rawDescGZIP() []byte {
+	file_sparkplug_b_sparkplug_b_proto_rawDescOnce.Do(func() {
+		file_sparkplug_b_sparkplug_b_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_sparkplug_b_sparkplug_b_proto_rawDesc), len(file_sparkplug_b_sparkplug_b_proto_rawDesc)))
+	})
+	return file_sparkplug_b_sparkplug_b_proto_rawDescData
+}
+
+var file_sparkplug_b_sparkplug_b_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_sparkplug_b_sparkplug_b_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_sparkplug_b_sparkplug_b_proto_goTypes = []any{
+	(DataType)(0),          // 0: org.eclipse.tahu.protobuf.DataType
+	(*Payload)(nil),        // 1: org.eclipse.tahu.protobuf.Payload
+	(*Payload_Metric)(nil), // 2: org.eclipse.tahu.protobuf.Payload.Metric
+}
+var file_sparkplug_b_sparkplug_b_proto_depIdxs = []int32{
+	2, // 0: org.eclipse.tahu.protobuf.Payload.metrics:type_name -> org.eclipse.tahu.protobuf.Payload.Metric
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_sparkplug_b_sparkplug_b_proto_init() }
+func file_sparkplug_b_sparkplug_b_proto_init() {
+	if File_sparkplug_b_sparkplug_b_proto != nil {
+		return
+	}
+	file_sparkplug_b_sparkplug_b_proto_msgTypes[1].OneofWrappers = []any{
+		(*Payload_Metric_IntValue)(nil),
+		(*Payload_Metric_LongValue)(nil),
+		(*Payload_Metric_FloatValue)(nil),
+		(*Payload_Metric_DoubleValue)(nil),
+		(*Payload_Metric_BooleanValue)(nil),
+		(*Payload_Metric_StringValue)(nil),
+		(*Payload_Metric_BytesValue)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_sparkplug_b_sparkplug_b_proto_rawDesc), len(file_sparkplug_b_sparkplug_b_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_sparkplug_b_sparkplug_b_proto_goTypes,
+		DependencyIndexes: file_sparkplug_b_sparkplug_b_proto_depIdxs,
+		EnumInfos:         file_sparkplug_b_sparkplug_b_proto_enumTypes,
+		MessageInfos:      file_sparkplug_b_sparkplug_b_proto_msgTypes,
+	}.Build()
+	File_sparkplug_b_sparkplug_b_proto = out.File
+	file_sparkplug_b_sparkplug_b_proto_goTypes = nil
+	file_sparkplug_b_sparkplug_b_proto_depIdxs = nil
+}
@@ -1,21 +1,19 @@
 package fanuc
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"sync"
 
 	"github.com/iwtcode/fanucAdapter/focas"
+	"github.com/iwtcode/fanucAdapter/focas/ethernet"
+	"github.com/iwtcode/fanucAdapter/focas/model"
 	"github.com/iwtcode/fanucAdapter/models"
 	"github.com/sirupsen/logrus"
 )
 
-var (
-	startupOnce sync.Once
-	startupErr  error
-)
-
 // Client является основной точкой входа для взаимодействия с библиотекой.
 type Client struct {
 	adapter *focas.FocasAdapter
@@ -24,7 +22,8 @@ type Client struct {
 }
 
 // New создает и возвращает новый экземпляр клиента.
-// Эта функция инициализирует FOCAS и устанавливает соединение.
+// Эта функция инициализирует FOCAS и устанавливает соединение
+// (в ленивом режиме — запускает подключение в фоне).
 func New(cfg *Config) (*Client, error) {
 	logger := logrus.New()
 
@@ -46,16 +45,32 @@ func New(cfg *Config) (*Client, error) {
 		TimestampFormat: "2006-01-02 15:04:05",
 	})
 
-	startupOnce.Do(func() {
-		startupErr = focas.Startup(0, "")
-	})
+	backend, err := newBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Observer != nil {
+		backend = focas.Instrument(backend, cfg.Observer)
+	}
 
-	if startupErr != nil {
-		return nil, fmt.Errorf("FOCAS startup failed: %w", startupErr)
+	if err := focas.Startup(backend, 0, ""); err != nil {
+		return nil, fmt.Errorf("FOCAS startup failed: %w", err)
 	}
 
-	// Передаем указанную серию модели в адаптер и локальный логгер
-	adapter, err := focas.NewFocasAdapter(cfg.IP, cfg.Port, cfg.TimeoutMs, cfg.ModelSeries, logger)
+	// Передаем указанную серию модели, локальный логгер и политику повторов в адаптер
+	newAdapter := focas.NewFocasAdapterWithOptions
+	if cfg.LazyConnect {
+		newAdapter = focas.NewLazyFocasAdapter
+	}
+	adapter, err := newAdapter(focas.AdapterOptions{
+		Backend:     backend,
+		IP:          cfg.IP,
+		Port:        cfg.Port,
+		TimeoutMs:   cfg.TimeoutMs,
+		ModelSeries: cfg.ModelSeries,
+		Logger:      logger,
+		Retry:       cfg.Retry,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create focas adapter: %w", err)
 	}
@@ -67,6 +82,29 @@ func New(cfg *Config) (*Client, error) {
 	}, nil
 }
 
+// newBackend выбирает реализацию вызовов FOCAS согласно конфигурации.
+func newBackend(cfg *Config) (model.Backend, error) {
+	if cfg.Backend != nil {
+		return cfg.Backend, nil
+	}
+
+	switch cfg.Transport {
+	case "":
+		// Бэкенд по умолчанию; инициализация libfwlib32 выполняется один раз на процесс
+		return focas.DefaultBackend(), nil
+	case TransportFwlib:
+		backend := focas.DefaultBackend()
+		if _, ok := backend.(*ethernet.Backend); ok {
+			return nil, fmt.Errorf("transport %q is not available: built without cgo", cfg.Transport)
+		}
+		return backend, nil
+	case TransportEthernet:
+		return ethernet.New(), nil
+	default:
+		return nil, fmt.Errorf("unknown FOCAS transport %q", cfg.Transport)
+	}
+}
+
 // Close закрывает соединение со станком.
 func (c *Client) Close() {
 	if c.adapter != nil {
@@ -79,67 +117,241 @@ func (c *Client) GetLogger() *logrus.Logger {
 	return c.logger
 }
 
+// ConnectionStatus возвращает состояние соединения со станком: текущее состояние,
+// последнюю ошибку, время последнего успешного вызова и количество переподключений.
+func (c *Client) ConnectionStatus() models.ConnectionStatus {
+	return c.adapter.ConnectionStatus()
+}
+
+// ConnectionState возвращает текущее состояние соединения со станком.
+func (c *Client) ConnectionState() models.ConnectionState {
+	return c.adapter.ConnectionStatus().State
+}
+
+// OnConnectionChange вызывает fn при каждой смене состояния соединения и возвращает функцию отписки.
+// fn вызывается синхронно из горутины, выполняющей запрос к станку, поэтому не должна блокироваться.
+func (c *Client) OnConnectionChange(fn func(models.ConnectionEvent)) (unsubscribe func()) {
+	return c.adapter.OnConnectionChange(fn)
+}
+
+// ConnectionEvents возвращает канал событий смены состояния соединения с буфером buffer.
+// Если получатель не успевает, самые старые события вытесняются новыми.
+// Вызов cancel отписывает канал и закрывает его.
+func (c *Client) ConnectionEvents(buffer int) (events <-chan models.ConnectionEvent, cancel func()) {
+	if buffer < 1 {
+		buffer = 1
+	}
+	ch := make(chan models.ConnectionEvent, buffer)
+
+	var mu sync.Mutex
+	closed := false
+	unsubscribe := c.adapter.OnConnectionChange(func(event models.ConnectionEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return
+		}
+		for {
+			select {
+			case ch <- event:
+				return
+			default:
+				// Буфер заполнен: вытесняем самое старое событие
+				select {
+				case <-ch:
+				default:
+				}
+			}
+		}
+	})
+
+	return ch, func() {
+		unsubscribe()
+		mu.Lock()
+		defer mu.Unlock()
+		if !closed {
+			closed = true
+			close(ch)
+		}
+	}
+}
+
 // GetSystemInfo возвращает системную информацию о станке.
+// В ленивом режиме (Config.LazyConnect) до первого подключения возвращает nil.
 func (c *Client) GetSystemInfo() *models.SystemInfo {
 	return c.adapter.GetSystemInfo()
 }
 
 // GetMachineState возвращает текущее состояние станка.
 func (c *Client) GetMachineState() (*models.UnifiedMachineData, error) {
-	return c.adapter.ReadMachineState()
+	return c.GetMachineStateCtx(context.Background())
+}
+
+// GetMachineStateCtx — вариант GetMachineState с поддержкой отмены и дедлайна через ctx.
+func (c *Client) GetMachineStateCtx(ctx context.Context) (*models.UnifiedMachineData, error) {
+	return c.adapter.ReadMachineState(ctx)
 }
 
 // GetAxisData возвращает информацию обо всех управляемых осях.
 func (c *Client) GetAxisData() ([]models.AxisInfo, error) {
-	return c.adapter.ReadAxisData()
+	return c.GetAxisDataCtx(context.Background())
+}
+
+// GetAxisDataCtx — вариант GetAxisData с поддержкой отмены и дедлайна через ctx.
+func (c *Client) GetAxisDataCtx(ctx context.Context) ([]models.AxisInfo, error) {
+	return c.adapter.ReadAxisData(ctx)
 }
 
 // GetSpindleData возвращает информацию обо всех шпинделях.
 func (c *Client) GetSpindleData() ([]models.SpindleInfo, error) {
-	return c.adapter.ReadSpindleData()
+	return c.GetSpindleDataCtx(context.Background())
+}
+
+// GetSpindleDataCtx — вариант GetSpindleData с поддержкой отмены и дедлайна через ctx.
+func (c *Client) GetSpindleDataCtx(ctx context.Context) ([]models.SpindleInfo, error) {
+	return c.adapter.ReadSpindleData(ctx)
 }
 
 // GetProgramInfo возвращает информацию о текущей выполняемой программе.
 func (c *Client) GetProgramInfo() (*models.ProgramInfo, error) {
-	return c.adapter.ReadProgram()
+	return c.GetProgramInfoCtx(context.Background())
+}
+
+// GetProgramInfoCtx — вариант GetProgramInfo с поддержкой отмены и дедлайна через ctx.
+func (c *Client) GetProgramInfoCtx(ctx context.Context) (*models.ProgramInfo, error) {
+	return c.adapter.ReadProgram(ctx)
 }
 
 // GetControlProgram возвращает полный G-код текущей выполняемой программы.
 func (c *Client) GetControlProgram() (string, error) {
-	return c.adapter.GetControlProgram()
+	return c.GetControlProgramCtx(context.Background())
+}
+
+// GetControlProgramCtx — вариант GetControlProgram с поддержкой отмены и дедлайна через ctx.
+func (c *Client) GetControlProgramCtx(ctx context.Context) (string, error) {
+	return c.adapter.GetControlProgram(ctx)
 }
 
 // GetAlarms возвращает список активных ошибок на станке.
 func (c *Client) GetAlarms() ([]models.AlarmDetail, error) {
-	return c.adapter.ReadAlarms()
+	return c.GetAlarmsCtx(context.Background())
+}
+
+// GetAlarmsCtx — вариант GetAlarms с поддержкой отмены и дедлайна через ctx.
+func (c *Client) GetAlarmsCtx(ctx context.Context) ([]models.AlarmDetail, error) {
+	return c.adapter.ReadAlarms(ctx)
 }
 
 // GetFeedData возвращает информацию о скорости подачи и коррекции.
 func (c *Client) GetFeedData() (*models.FeedInfo, error) {
-	return c.adapter.ReadFeedData()
+	return c.GetFeedDataCtx(context.Background())
+}
+
+// GetFeedDataCtx — вариант GetFeedData с поддержкой отмены и дедлайна через ctx.
+func (c *Client) GetFeedDataCtx(ctx context.Context) (*models.FeedInfo, error) {
+	return c.adapter.ReadFeedData(ctx)
 }
 
 // GetContourFeedRate возвращает фактическую скорость подачи по контуру.
 func (c *Client) GetContourFeedRate() (int32, error) {
-	return c.adapter.ReadContourFeedRate()
+	return c.GetContourFeedRateCtx(context.Background())
+}
+
+// GetContourFeedRateCtx — вариант GetContourFeedRate с поддержкой отмены и дедлайна через ctx.
+func (c *Client) GetContourFeedRateCtx(ctx context.Context) (int32, error) {
+	return c.adapter.ReadContourFeedRate(ctx)
 }
 
 // GetFeedOverride возвращает процент коррекции подачи.
 func (c *Client) GetFeedOverride() (int32, error) {
-	return c.adapter.ReadFeedOverride()
+	return c.GetFeedOverrideCtx(context.Background())
+}
+
+// GetFeedOverrideCtx — вариант GetFeedOverride с поддержкой отмены и дедлайна через ctx.
+func (c *Client) GetFeedOverrideCtx(ctx context.Context) (int32, error) {
+	return c.adapter.ReadFeedOverride(ctx)
 }
 
 // GetJogOverride возвращает процент коррекции скорости в режиме JOG.
 func (c *Client) GetJogOverride() (int32, error) {
-	return c.adapter.ReadJogOverride()
+	return c.GetJogOverrideCtx(context.Background())
+}
+
+// GetJogOverrideCtx — вариант GetJogOverride с поддержкой отмены и дедлайна через ctx.
+func (c *Client) GetJogOverrideCtx(ctx context.Context) (int32, error) {
+	return c.adapter.ReadJogOverride(ctx)
 }
 
 // GetParameterInfo возвращает информацию о параметрах (счетчики, время работы).
 func (c *Client) GetParameterInfo() (*models.ParameterInfo, error) {
-	return c.adapter.ReadParameterInfo()
+	return c.GetParameterInfoCtx(context.Background())
+}
+
+// GetParameterInfoCtx — вариант GetParameterInfo с поддержкой отмены и дедлайна через ctx.
+func (c *Client) GetParameterInfoCtx(ctx context.Context) (*models.ParameterInfo, error) {
+	return c.adapter.ReadParameterInfo(ctx)
+}
+
+// DiagType — тип данных диагностики для GetDiagnosis.
+type DiagType string
+
+// Допустимые значения DiagType
+const (
+	DiagByte       DiagType = "byte"  // 1 байт
+	DiagWord       DiagType = "word"  // 2 байта
+	DiagDoubleWord DiagType = "dword" // 4 байта
+	DiagReal       DiagType = "real"  // Значение с десятичной точкой
+)
+
+// GetParameter возвращает целочисленное значение параметра ЧПУ number.
+// axis — номер оси (с 1) для осевых параметров, 0 — для общих.
+func (c *Client) GetParameter(number, axis int16) (int32, error) {
+	return c.GetParameterCtx(context.Background(), number, axis)
+}
+
+// GetParameterCtx — вариант GetParameter с поддержкой отмены и дедлайна через ctx.
+func (c *Client) GetParameterCtx(ctx context.Context, number, axis int16) (int32, error) {
+	return c.adapter.ReadParameter(ctx, number, axis)
+}
+
+// GetDiagnosis возвращает значение диагностики number типа typ.
+// axis — номер оси (с 1) для осевой диагностики, 0 — для общей.
+func (c *Client) GetDiagnosis(number, axis int16, typ DiagType) (float64, error) {
+	return c.GetDiagnosisCtx(context.Background(), number, axis, typ)
+}
+
+// GetDiagnosisCtx — вариант GetDiagnosis с поддержкой отмены и дедлайна через ctx.
+func (c *Client) GetDiagnosisCtx(ctx context.Context, number, axis int16, typ DiagType) (float64, error) {
+	switch typ {
+	case DiagByte:
+		v, err := c.adapter.ReadDiagnosisByte(ctx, number, axis)
+		return float64(v), err
+	case DiagWord:
+		v, err := c.adapter.ReadDiagnosisWord(ctx, number, axis)
+		return float64(v), err
+	case DiagDoubleWord:
+		v, err := c.adapter.ReadDiagnosisDoubleWord(ctx, number, axis)
+		return float64(v), err
+	case DiagReal:
+		return c.adapter.ReadDiagnosisReal(ctx, number, axis)
+	}
+	return 0, fmt.Errorf("unknown diagnosis type %q", typ)
 }
 
 // GetCurrentData возвращает полную сводку данных о станке, собранную асинхронно.
+// Ошибка одного раздела не прерывает сбор: результат каждого раздела записан
+// в AggregatedData.Sections, а ошибка возвращается, только если не прочитан ни один раздел.
 func (c *Client) GetCurrentData() (*models.AggregatedData, error) {
-	return c.adapter.AggregateAllData()
+	return c.GetCurrentDataCtx(context.Background())
+}
+
+// GetCurrentDataCtx — вариант GetCurrentData с поддержкой отмены и дедлайна через ctx.
+func (c *Client) GetCurrentDataCtx(ctx context.Context) (*models.AggregatedData, error) {
+	return c.adapter.AggregateAllData(ctx)
+}
+
+// GetCurrentDataSections собирает только указанные разделы сводных данных
+// (models.SectionState, models.SectionAxes и т.д.); вызовы FOCAS остальных разделов не выполняются.
+func (c *Client) GetCurrentDataSections(ctx context.Context, sections ...models.Section) (*models.AggregatedData, error) {
+	return c.adapter.AggregateSections(ctx, sections...)
 }
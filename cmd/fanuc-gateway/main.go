@@ -0,0 +1,126 @@
+// Команда fanuc-gateway запускает REST-шлюз к станкам FANUC (пакет gateway)
+// и, если задан -grpc-addr, gRPC-сервис fanuc.v1.MachineService (пакет grpcapi).
+//
+// Пример:
+//
+//	go run ./cmd/fanuc-gateway -addr :8080 -grpc-addr :9090 -config fleet.json
+//
+// Станки задаются файлом конфигурации парка (см. fleet.LoadConfig). Без -config шлюз
+// обслуживает один станок с ID из -id, настроенный переменными окружения FANUC_*
+// (см. fanuc.Load). Клиенты подключаются в фоне, поэтому недоступный станок не мешает
+// запуску: /readyz возвращает 503, пока не подключен ни один станок.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	"github.com/iwtcode/fanucAdapter/fleet"
+	"github.com/iwtcode/fanucAdapter/gateway"
+	"github.com/iwtcode/fanucAdapter/grpcapi"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	addr := flag.String("addr", gateway.DefaultAddr, "адрес HTTP-сервера")
+	grpcAddr := flag.String("grpc-addr", "", "адрес gRPC-сервера (пустой — gRPC отключен)")
+	configPath := flag.String("config", "", "JSON-файл конфигурации парка станков")
+	id := flag.String("id", "cnc", "ID станка, если -config не задан")
+	timeout := flag.Duration("timeout", 10*time.Second, "ограничение времени чтения данных в одном запросе")
+	logLevel := flag.String("log-level", "info", "уровень логирования")
+	flag.Parse()
+
+	logger := logrus.New()
+	if level, err := logrus.ParseLevel(*logLevel); err == nil {
+		logger.SetLevel(level)
+	}
+	logger.SetFormatter(&logrus.TextFormatter{
+		FullTimestamp:   true,
+		TimestampFormat: "2006-01-02 15:04:05",
+	})
+
+	gw := gateway.New(gateway.Options{Timeout: *timeout, Logger: logger})
+	rpc := grpcapi.NewServer(grpcapi.Options{Timeout: *timeout, Logger: logger})
+	add := func(id string, client *fanuc.Client, tags map[string]string) {
+		if err := gw.Add(id, client, tags); err != nil {
+			logger.Fatalf("Не удалось добавить станок: %v", err)
+		}
+		if err := rpc.Add(id, client, tags); err != nil {
+			logger.Fatalf("Не удалось добавить станок: %v", err)
+		}
+	}
+	if *configPath != "" {
+		cfg, err := fleet.LoadConfig(*configPath)
+		if err != nil {
+			logger.Fatalf("Не удалось загрузить конфигурацию парка: %v", err)
+		}
+		if cfg.LogLevel == "" {
+			cfg.LogLevel = *logLevel
+		}
+		// Парк используется только для создания клиентов: шлюз читает данные по запросу,
+		// поэтому периодический опрос (Start) не запускается.
+		f, err := fleet.New(cfg)
+		if err != nil {
+			logger.Fatalf("Не удалось создать парк станков: %v", err)
+		}
+		defer f.Close()
+		for _, mc := range f.Machines() {
+			client, _ := f.Client(mc.ID)
+			add(mc.ID, client, mc.Tags)
+		}
+	} else {
+		cfg := fanuc.Load()
+		cfg.LazyConnect = true
+		client, err := fanuc.New(cfg)
+		if err != nil {
+			logger.Fatalf("Не удалось создать клиент: %v", err)
+		}
+		defer client.Close()
+		add(*id, client, nil)
+	}
+
+	server := &http.Server{Addr: *addr, Handler: gw, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatalf("Ошибка HTTP-сервера: %v", err)
+		}
+	}()
+	logger.Infof("Шлюз слушает %s (станков: %d)", *addr, len(gw.Machines()))
+
+	var grpcServer *grpc.Server
+	if *grpcAddr != "" {
+		lis, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			logger.Fatalf("Не удалось запустить gRPC-сервер: %v", err)
+		}
+		grpcServer = grpc.NewServer()
+		rpc.Register(grpcServer)
+		go func() {
+			if err := grpcServer.Serve(lis); err != nil {
+				logger.Fatalf("Ошибка gRPC-сервера: %v", err)
+			}
+		}()
+		logger.Infof("gRPC-сервер слушает %s", lis.Addr())
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+
+	logger.Info("Остановка шлюза...")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	server.Shutdown(ctx)
+	if grpcServer != nil {
+		grpcServer.Stop()
+	}
+}
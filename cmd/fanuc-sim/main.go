@@ -0,0 +1,73 @@
+// Команда fanuc-sim запускает виртуальный станок FANUC, доступный по FOCAS/Ethernet.
+//
+// Пример:
+//
+//	go run ./cmd/fanuc-sim -addr :8193 -config machine.json -busy 0.05 -drop 0.01
+//
+// После запуска к симулятору можно подключиться клиентом с FANUC_TRANSPORT=ethernet.
+package main
+
+import (
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/iwtcode/fanucAdapter/focas/fake"
+	"github.com/iwtcode/fanucAdapter/simulator"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	addr := flag.String("addr", simulator.DefaultAddr, "адрес для приема подключений FOCAS")
+	configPath := flag.String("config", "", "JSON-файл с описанием станка (по умолчанию 0i-TF)")
+	busy := flag.Float64("busy", 0, "вероятность ответа EW_BUSY на вызов (0..1)")
+	handle := flag.Float64("handle", 0, "вероятность ответа EW_HANDLE на вызов (0..1)")
+	drop := flag.Float64("drop", 0, "вероятность разрыва соединения при вызове (0..1)")
+	logLevel := flag.String("log-level", "info", "уровень логирования")
+	flag.Parse()
+
+	logger := logrus.New()
+	if level, err := logrus.ParseLevel(*logLevel); err == nil {
+		logger.SetLevel(level)
+	}
+	logger.SetFormatter(&logrus.TextFormatter{
+		FullTimestamp:   true,
+		TimestampFormat: "2006-01-02 15:04:05",
+	})
+
+	cnc := fake.NewCNC()
+	var running string
+	if *configPath != "" {
+		machine, err := simulator.LoadMachine(*configPath)
+		if err != nil {
+			logger.Fatalf("Не удалось загрузить описание станка: %v", err)
+		}
+		if cnc, err = machine.CNC(); err != nil {
+			logger.Fatalf("Некорректное описание станка: %v", err)
+		}
+		running = machine.Running
+	}
+
+	sim := simulator.New(cnc)
+	if running != "" {
+		if err := sim.StartProgram(running); err != nil {
+			logger.Fatalf("Не удалось запустить программу: %v", err)
+		}
+	}
+	sim.SetChaos(simulator.Chaos{Busy: *busy, Handle: *handle, Drop: *drop})
+
+	if err := sim.Start(*addr); err != nil {
+		logger.Fatalf("Не удалось запустить симулятор: %v", err)
+	}
+	state := sim.State()
+	logger.Infof("Симулятор станка серии %s (осей: %d, программа %s) слушает %s",
+		state.SysInfo.Series, len(state.Axes), state.ExecName, sim.Addr())
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+
+	logger.Info("Остановка симулятора...")
+	sim.Close()
+}
@@ -0,0 +1,12 @@
+// Команда fanuc-worker — процесс-обработчик вызовов FOCAS для пакета focas/worker.
+// Обычно ее запускает супервизор (Options.Command), а не пользователь:
+// вызовы поступают через stdin, ответы отправляются в отдельный дескриптор (fd 3),
+// а вывод в stdout передается в stderr супервизора (Options.Stderr).
+// Бэкенд выбирается переменной FANUC_WORKER_TRANSPORT ("fwlib" или "ethernet").
+package main
+
+import "github.com/iwtcode/fanucAdapter/focas/worker"
+
+func main() {
+	worker.Main()
+}
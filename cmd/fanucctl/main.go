@@ -0,0 +1,435 @@
+// Команда fanucctl читает данные станка FANUC из командной строки.
+//
+// Пример:
+//
+//	fanucctl -ip 10.0.0.1 state
+//	fanucctl -format json axes
+//	fanucctl params get 6711 6712
+//	fanucctl diag get -axis 1 -type word 308
+//	fanucctl program upload -o O0001.nc
+//	fanucctl watch -interval 500ms -events
+//
+// Параметры подключения по умолчанию берутся из переменных окружения FANUC_*
+// (см. fanuc.Load), флаги имеют приоритет.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	"github.com/iwtcode/fanucAdapter/models"
+)
+
+// errUsage — ошибка в аргументах команды; описание уже выведено.
+var errUsage = errors.New("usage")
+
+// env — общие параметры команд.
+type env struct {
+	cfg    *fanuc.Config
+	out    *printer
+	stdout io.Writer
+	stderr io.Writer
+	client *fanuc.Client
+}
+
+// connect подключается к станку при первом вызове.
+func (e *env) connect() (*fanuc.Client, error) {
+	if e.client != nil {
+		return e.client, nil
+	}
+	client, err := fanuc.New(e.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось подключиться к %s:%d: %w", e.cfg.IP, e.cfg.Port, err)
+	}
+	e.client = client
+	return client, nil
+}
+
+// command — подкоманда fanucctl.
+type command struct {
+	usage string
+	help  string
+	run   func(ctx context.Context, e *env, args []string) error
+}
+
+// commands заполняется в init: обработчики команд сами обращаются к commands за usage.
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"info":     {"info", "системная информация станка", runInfo},
+		"state":    {"state", "состояние станка и активные тревоги", runState},
+		"axes":     {"axes", "позиции, нагрузка и температуры осей", runAxes},
+		"spindles": {"spindles", "скорость и нагрузка шпинделей", runSpindles},
+		"alarms":   {"alarms", "активные тревоги", runAlarms},
+		"params":   {"params get [-axis N] <номер>...", "значения параметров ЧПУ", runParams},
+		"diag":     {"diag get [-axis N] [-type byte|word|dword|real] <номер>...", "значения диагностики", runDiag},
+		"program":  {"program current | program upload [-o файл]", "выполняемая программа и ее текст", runProgram},
+		"watch":    {"watch [-interval 1s] [-events]", "снимки данных или события изменений до Ctrl+C", runWatch},
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run выполняет fanucctl с аргументами args и возвращает код завершения:
+// 0 — успех, 1 — ошибка выполнения, 2 — ошибка в аргументах.
+func run(args []string, stdout, stderr io.Writer) int {
+	cfg := fanuc.Load()
+	logLevel := "off"
+	if os.Getenv("LOG_LEVEL") != "" {
+		logLevel = cfg.LogLevel
+	}
+
+	flags := flag.NewFlagSet("fanucctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&cfg.IP, "ip", cfg.IP, "адрес станка (FANUC_IP)")
+	port := flags.Uint("port", uint(cfg.Port), "порт FOCAS/Ethernet (FANUC_PORT)")
+	timeout := flags.Int("timeout", int(cfg.TimeoutMs), "таймаут FOCAS, мс (FANUC_TIMEOUT)")
+	flags.StringVar(&cfg.ModelSeries, "series", cfg.ModelSeries, "серия ЧПУ, например 0i; пустая — определить (FANUC_MODEL_SERIES)")
+	flags.StringVar(&cfg.Transport, "transport", cfg.Transport, "fwlib или ethernet (FANUC_TRANSPORT)")
+	format := flags.String("format", formatTable, "формат вывода: table, json или yaml")
+	flags.StringVar(&cfg.LogLevel, "log-level", logLevel, "уровень логирования клиента (LOG_LEVEL)")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Использование: fanucctl [флаги] <команда> [аргументы]\n\nКоманды:\n")
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(stderr, "  %-62s %s\n", commands[name].usage, commands[name].help)
+		}
+		fmt.Fprintf(stderr, "\nФлаги:\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "fanucctl: неизвестная команда %q\n", flags.Arg(0))
+		flags.Usage()
+		return 2
+	}
+	if *port == 0 || *port > 65535 {
+		fmt.Fprintf(stderr, "fanucctl: некорректный порт %d\n", *port)
+		return 2
+	}
+	cfg.Port = uint16(*port)
+	cfg.TimeoutMs = int32(*timeout)
+
+	out, err := newPrinter(*format, stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "fanucctl: %v\n", err)
+		return 2
+	}
+	e := &env{cfg: cfg, out: out, stdout: stdout, stderr: stderr}
+	defer func() {
+		if e.client != nil {
+			e.client.Close()
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := cmd.run(ctx, e, flags.Args()[1:]); err != nil {
+		if errors.Is(err, errUsage) {
+			return 2
+		}
+		fmt.Fprintf(stderr, "fanucctl: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// subcommand разбирает флаги подкоманды name и возвращает оставшиеся аргументы.
+func subcommand(e *env, name, usage string, args []string, define func(*flag.FlagSet)) ([]string, error) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(e.stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(e.stderr, "Использование: fanucctl %s\n", usage)
+		flags.PrintDefaults()
+	}
+	if define != nil {
+		define(flags)
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, errUsage
+	}
+	return flags.Args(), nil
+}
+
+// noArgs проверяет, что у команды без аргументов их нет, и подключается к станку.
+func noArgs(e *env, name string, args []string) (*fanuc.Client, error) {
+	if _, err := subcommand(e, name, commands[name].usage, args, nil); err != nil {
+		return nil, err
+	}
+	if len(args) > 0 {
+		fmt.Fprintf(e.stderr, "fanucctl %s: лишние аргументы %q\n", name, args)
+		return nil, errUsage
+	}
+	return e.connect()
+}
+
+func runInfo(ctx context.Context, e *env, args []string) error {
+	client, err := noArgs(e, "info", args)
+	if err != nil {
+		return err
+	}
+	return e.out.print(client.GetSystemInfo())
+}
+
+func runState(ctx context.Context, e *env, args []string) error {
+	client, err := noArgs(e, "state", args)
+	if err != nil {
+		return err
+	}
+	state, err := client.GetMachineStateCtx(ctx)
+	if err != nil {
+		return err
+	}
+	return e.out.print(state)
+}
+
+func runAxes(ctx context.Context, e *env, args []string) error {
+	client, err := noArgs(e, "axes", args)
+	if err != nil {
+		return err
+	}
+	axes, err := client.GetAxisDataCtx(ctx)
+	if err != nil {
+		return err
+	}
+	return e.out.print(axes)
+}
+
+func runSpindles(ctx context.Context, e *env, args []string) error {
+	client, err := noArgs(e, "spindles", args)
+	if err != nil {
+		return err
+	}
+	spindles, err := client.GetSpindleDataCtx(ctx)
+	if err != nil {
+		return err
+	}
+	return e.out.print(spindles)
+}
+
+func runAlarms(ctx context.Context, e *env, args []string) error {
+	client, err := noArgs(e, "alarms", args)
+	if err != nil {
+		return err
+	}
+	alarms, err := client.GetAlarmsCtx(ctx)
+	if err != nil {
+		return err
+	}
+	return e.out.print(alarms)
+}
+
+// value — значение параметра или диагностики в выводе params и diag.
+type value struct {
+	Number int16   `json:"number"`
+	Axis   int16   `json:"axis"`
+	Value  float64 `json:"value"`
+}
+
+// readNumbered разбирает подкоманду "get" команды name и читает значения по номерам.
+func readNumbered(ctx context.Context, e *env, name string, args []string, define func(*flag.FlagSet), read func(ctx context.Context, client *fanuc.Client, number, axis int16) (float64, error)) error {
+	usage := commands[name].usage
+	if len(args) == 0 || args[0] != "get" {
+		fmt.Fprintf(e.stderr, "Использование: fanucctl %s\n", usage)
+		return errUsage
+	}
+	var axis int
+	numbers, err := subcommand(e, name+" get", usage, args[1:], func(flags *flag.FlagSet) {
+		flags.IntVar(&axis, "axis", 0, "номер оси (с 1) для осевых значений, 0 — общие")
+		if define != nil {
+			define(flags)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if len(numbers) == 0 {
+		fmt.Fprintf(e.stderr, "fanucctl %s get: укажите номер\n", name)
+		return errUsage
+	}
+	parsed := make([]int16, len(numbers))
+	for i, s := range numbers {
+		n, err := strconv.ParseInt(s, 10, 16)
+		if err != nil || n < 0 {
+			fmt.Fprintf(e.stderr, "fanucctl %s get: некорректный номер %q\n", name, s)
+			return errUsage
+		}
+		parsed[i] = int16(n)
+	}
+
+	client, err := e.connect()
+	if err != nil {
+		return err
+	}
+	values := make([]value, 0, len(parsed))
+	for _, number := range parsed {
+		v, err := read(ctx, client, number, int16(axis))
+		if err != nil {
+			return err
+		}
+		values = append(values, value{Number: number, Axis: int16(axis), Value: v})
+	}
+	return e.out.print(values)
+}
+
+func runParams(ctx context.Context, e *env, args []string) error {
+	return readNumbered(ctx, e, "params", args, nil, func(ctx context.Context, client *fanuc.Client, number, axis int16) (float64, error) {
+		v, err := client.GetParameterCtx(ctx, number, axis)
+		return float64(v), err
+	})
+}
+
+func runDiag(ctx context.Context, e *env, args []string) error {
+	typ := string(fanuc.DiagDoubleWord)
+	return readNumbered(ctx, e, "diag", args, func(flags *flag.FlagSet) {
+		flags.StringVar(&typ, "type", typ, "тип данных: byte, word, dword или real")
+	}, func(ctx context.Context, client *fanuc.Client, number, axis int16) (float64, error) {
+		return client.GetDiagnosisCtx(ctx, number, axis, fanuc.DiagType(typ))
+	})
+}
+
+func runProgram(ctx context.Context, e *env, args []string) error {
+	usage := commands["program"].usage
+	if len(args) == 0 {
+		fmt.Fprintf(e.stderr, "Использование: fanucctl %s\n", usage)
+		return errUsage
+	}
+	switch args[0] {
+	case "current":
+		client, err := noArgs(e, "program", args[1:])
+		if err != nil {
+			return err
+		}
+		info, err := client.GetProgramInfoCtx(ctx)
+		if err != nil {
+			return err
+		}
+		return e.out.print(info)
+	case "upload":
+		var output string
+		rest, err := subcommand(e, "program upload", usage, args[1:], func(flags *flag.FlagSet) {
+			flags.StringVar(&output, "o", "", "файл для текста программы; без флага — стандартный вывод")
+		})
+		if err != nil {
+			return err
+		}
+		if len(rest) > 0 {
+			fmt.Fprintf(e.stderr, "fanucctl program upload: лишние аргументы %q\n", rest)
+			return errUsage
+		}
+		client, err := e.connect()
+		if err != nil {
+			return err
+		}
+		source, err := client.GetControlProgramCtx(ctx)
+		if err != nil {
+			return err
+		}
+		if output == "" {
+			_, err = fmt.Fprintln(e.stdout, source)
+			return err
+		}
+		if err := os.WriteFile(output, []byte(source+"\n"), 0o644); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stderr, "Программа записана в %s (%d байт)\n", output, len(source)+1)
+		return nil
+	}
+	fmt.Fprintf(e.stderr, "Использование: fanucctl %s\n", usage)
+	return errUsage
+}
+
+func runWatch(ctx context.Context, e *env, args []string) error {
+	interval := time.Second
+	eventsOnly := false
+	rest, err := subcommand(e, "watch", commands["watch"].usage, args, func(flags *flag.FlagSet) {
+		flags.DurationVar(&interval, "interval", interval, "период опроса")
+		flags.BoolVar(&eventsOnly, "events", false, "выводить только события изменений")
+	})
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 || interval <= 0 {
+		fmt.Fprintf(e.stderr, "Использование: fanucctl %s\n", commands["watch"].usage)
+		return errUsage
+	}
+	client, err := e.connect()
+	if err != nil {
+		return err
+	}
+
+	intervals := make(map[fanuc.DataGroup]time.Duration)
+	for _, group := range []fanuc.DataGroup{fanuc.GroupState, fanuc.GroupPositions, fanuc.GroupSpindles, fanuc.GroupFeed, fanuc.GroupParameters, fanuc.GroupPaths} {
+		intervals[group] = interval
+	}
+	snapshots, err := client.Subscribe(ctx, fanuc.SubscribeOptions{Intervals: intervals})
+	if err != nil {
+		return err
+	}
+	detector := fanuc.NewEventDetector()
+	for snapshot := range snapshots {
+		if !eventsOnly && snapshot.Data != nil {
+			if err := e.out.stream(snapshot.Data, snapshotRow(snapshot)); err != nil {
+				return err
+			}
+		}
+		for _, event := range detector.Detect(snapshot) {
+			if err := e.out.stream(event, eventRow(event)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// snapshotRow — строка снимка в табличном выводе watch.
+func snapshotRow(s fanuc.Snapshot) string {
+	d := s.Data
+	positions := make([]string, 0, len(d.AxisInfos))
+	for _, axis := range d.AxisInfos {
+		positions = append(positions, fmt.Sprintf("%s=%.3f", axis.Name, axis.Position))
+	}
+	return fmt.Sprintf("%s  %-8s %-8s %-10s parts=%-6d alarms=%-2d %s",
+		s.Timestamp.Format("15:04:05.000"), d.MachineState, d.ProgramMode, d.CurrentProgram.ProgramName,
+		d.PartsCount, len(d.Alarms), strings.Join(positions, " "))
+}
+
+// eventRow — строка события в табличном выводе watch.
+func eventRow(e models.MachineEvent) string {
+	row := fmt.Sprintf("%s  %s", e.Timestamp.Format("15:04:05.000"), e.Type)
+	if e.Key != "" {
+		row += " " + e.Key
+	}
+	if e.Before != nil || e.After != nil {
+		row += fmt.Sprintf(": %s → %s", compact(e.Before), compact(e.After))
+	}
+	return row
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Форматы вывода
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+// printer выводит результаты команд в выбранном формате. Имена полей во всех
+// форматах совпадают с JSON-представлением структур models.
+type printer struct {
+	format string
+	w      io.Writer
+}
+
+func newPrinter(format string, w io.Writer) (*printer, error) {
+	switch format {
+	case formatTable, formatJSON, formatYAML:
+		return &printer{format: format, w: w}, nil
+	}
+	return nil, fmt.Errorf("неизвестный формат вывода %q (table, json или yaml)", format)
+}
+
+// print выводит значение v. В табличном формате объект выводится парами
+// поле — значение, а массив объектов — таблицей со столбцами по полям.
+func (p *printer) print(v any) error {
+	switch p.format {
+	case formatJSON:
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(p.w, "%s\n", data)
+		return err
+	case formatYAML:
+		data, err := toYAML(v)
+		if err != nil {
+			return err
+		}
+		_, err = p.w.Write(data)
+		return err
+	}
+
+	node, err := toNode(v)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			fmt.Fprintf(tw, "%s\t%s\n", strings.ToUpper(node.Content[i].Value), cell(node.Content[i+1]))
+		}
+	case yaml.SequenceNode:
+		writeRows(tw, node.Content)
+	default:
+		fmt.Fprintln(tw, cell(node))
+	}
+	return tw.Flush()
+}
+
+// stream выводит очередной элемент потока (watch): JSON — одной строкой,
+// YAML — отдельным документом, таблица — строкой row.
+func (p *printer) stream(v any, row string) error {
+	switch p.format {
+	case formatJSON:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(p.w, "%s\n", data)
+		return err
+	case formatYAML:
+		data, err := toYAML(v)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(p.w, "---\n%s", data)
+		return err
+	}
+	_, err := fmt.Fprintln(p.w, row)
+	return err
+}
+
+// writeRows выводит массив объектов таблицей; столбцы — поля первого элемента.
+func writeRows(tw *tabwriter.Writer, items []*yaml.Node) {
+	if len(items) == 0 {
+		fmt.Fprintln(tw, "(нет данных)")
+		return
+	}
+	if items[0].Kind != yaml.MappingNode {
+		for _, item := range items {
+			fmt.Fprintln(tw, cell(item))
+		}
+		return
+	}
+
+	var columns []string
+	for i := 0; i+1 < len(items[0].Content); i += 2 {
+		columns = append(columns, items[0].Content[i].Value)
+	}
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+	for _, item := range items {
+		values := make(map[string]*yaml.Node, len(item.Content)/2)
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			values[item.Content[i].Value] = item.Content[i+1]
+		}
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			if v, ok := values[column]; ok {
+				cells[i] = cell(v)
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+}
+
+// cell возвращает значение ячейки таблицы: скаляр как есть, вложенные значения — в JSON.
+func cell(n *yaml.Node) string {
+	if n.Kind == yaml.ScalarNode {
+		if n.Tag == "!!null" {
+			return "-"
+		}
+		return n.Value
+	}
+	var v any
+	if err := n.Decode(&v); err != nil {
+		return "?"
+	}
+	return compact(v)
+}
+
+// compact возвращает значение одной строкой JSON.
+func compact(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// toNode преобразует значение в дерево YAML через JSON, сохраняя имена и порядок полей.
+func toNode(v any) (*yaml.Node, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	node := doc.Content[0]
+	blockStyle(node)
+	return node, nil
+}
+
+// blockStyle сбрасывает стиль JSON (flow, кавычки), чтобы YAML выводился блоками.
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, child := range n.Content {
+		blockStyle(child)
+	}
+}
+
+func toYAML(v any) ([]byte, error) {
+	node, err := toNode(v)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
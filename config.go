@@ -3,6 +3,10 @@ package fanuc
 import (
 	"os"
 	"strconv"
+	"time"
+
+	"github.com/iwtcode/fanucAdapter/focas"
+	"github.com/iwtcode/fanucAdapter/focas/model"
 )
 
 // Config хранит модель конфигурации приложения
@@ -10,10 +14,40 @@ type Config struct {
 	IP          string
 	Port        uint16
 	TimeoutMs   int32
-	ModelSeries string
+	ModelSeries string // Серия ЧПУ, например "0i" или "30i-M"; пустая — определяется по cnc_sysinfo
 	LogLevel    string
+
+	// Transport выбирает реализацию вызовов FOCAS, если Backend не указан:
+	// TransportFwlib (libfwlib32 через cgo) или TransportEthernet (чистый Go).
+	// Пустое значение означает focas.DefaultBackend.
+	Transport string
+
+	// Backend задает реализацию вызовов FOCAS явно и имеет приоритет над Transport.
+	Backend model.Backend
+
+	// LazyConnect включает ленивый режим: New не обращается к станку и возвращает клиент
+	// в состоянии Disconnected, а подключение выполняется в фоне. До первого подключения
+	// вызовы возвращают ошибку focas.ErrNotConnected.
+	LazyConnect bool
+
+	// Retry задает повторы при потере связи и предохранитель для недоступного станка.
+	// Нулевое значение соответствует focas.DefaultRetryPolicy.
+	Retry RetryPolicy
+
+	// Observer получает сведения о каждом вызове FOCAS и об ожидании блокировки вызовов
+	// (см. focas.Instrument), например для метрик Prometheus из пакета metrics.
+	Observer model.CallObserver
 }
 
+// RetryPolicy — политика повторов вызовов FOCAS при ошибках соединения.
+type RetryPolicy = focas.RetryPolicy
+
+// Допустимые значения Config.Transport
+const (
+	TransportFwlib    = "fwlib"
+	TransportEthernet = "ethernet"
+)
+
 // Load загружает конфигурацию из переменных окружения
 func Load() *Config {
 	ip := os.Getenv("FANUC_IP")
@@ -35,6 +69,18 @@ func Load() *Config {
 
 	modelSeries := os.Getenv("FANUC_MODEL_SERIES")
 
+	transport := os.Getenv("FANUC_TRANSPORT")
+
+	lazyConnect, _ := strconv.ParseBool(os.Getenv("FANUC_LAZY_CONNECT"))
+
+	retry := RetryPolicy{
+		MaxAttempts:      envInt("FANUC_RETRY_ATTEMPTS"),
+		InitialBackoff:   envMillis("FANUC_RETRY_BACKOFF"),
+		MaxBackoff:       envMillis("FANUC_RETRY_MAX_BACKOFF"),
+		BreakerThreshold: envInt("FANUC_BREAKER_THRESHOLD"),
+		BreakerCooldown:  envMillis("FANUC_BREAKER_COOLDOWN"),
+	}
+
 	logLevel := os.Getenv("LOG_LEVEL")
 	if logLevel == "" {
 		logLevel = "info"
@@ -46,5 +92,22 @@ func Load() *Config {
 		TimeoutMs:   int32(timeout),
 		ModelSeries: modelSeries,
 		LogLevel:    logLevel,
+		Transport:   transport,
+		LazyConnect: lazyConnect,
+		Retry:       retry,
 	}
 }
+
+// envInt читает целое число из переменной окружения; при ошибке возвращает 0.
+func envInt(name string) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// envMillis читает длительность в миллисекундах из переменной окружения.
+func envMillis(name string) time.Duration {
+	return time.Duration(envInt(name)) * time.Millisecond
+}
@@ -10,12 +10,28 @@ const (
 	BadRequest          = "bad request"
 	NotFound            = "not_found"
 	UnauthorizedError   = "unauthorized"
+	Forbidden           = "forbidden"
+	Conflict            = "conflict"
+	MethodNotAllowed    = "method not allowed"
+	NotImplemented      = "not_implemented"
+	CNCUnavailable      = "cnc unavailable"
+	CNCError            = "cnc error"
+	CNCTimeout          = "cnc timeout"
+	ClientClosedRequest = "client closed request"
 
+	BadRequestCode          = 400
 	UnauthorizedErrorCode   = 401
 	InvalidDataCode         = 402
 	ForbiddenErrorCode      = 403
 	InternalServerErrorCode = 500
 	NotFoundErrorCode       = 404
+	MethodNotAllowedCode    = 405
+	ConflictErrorCode       = 409
+	ClientClosedRequestCode = 499 // Нестандартный статус: клиент отменил запрос
+	NotImplementedCode      = 501
+	BadGatewayCode          = 502
+	ServiceUnavailableCode  = 503
+	GatewayTimeoutCode      = 504
 )
 
 // AppError представляет собой стандартизированную структуру ошибки для API
@@ -36,6 +52,15 @@ func (a *AppError) Error() string {
 	return fmt.Sprintf("%s (code: %d)", a.Message, a.Code)
 }
 
+// Unwrap возвращает внутреннюю ошибку, чтобы errors.Is и errors.As
+// видели причину, например focas.ErrNoOption.
+func (a *AppError) Unwrap() error {
+	if a == nil {
+		return nil
+	}
+	return a.Err
+}
+
 // NewAppError создает новый экземпляр AppError
 func NewAppError(httpCode int, message string, err error, isUserFacing bool) *AppError {
 	return &AppError{
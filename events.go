@@ -0,0 +1,207 @@
+package fanuc
+
+import (
+	"context"
+	"time"
+
+	"github.com/iwtcode/fanucAdapter/models"
+)
+
+// DiffData сравнивает два последовательных снимка данных станка и возвращает события
+// изменений. Время и ID станка событий берутся из after.
+func DiffData(before, after *models.AggregatedData) []models.MachineEvent {
+	if before == nil || after == nil {
+		return nil
+	}
+	events := diffState(before, after)
+	return append(events, diffParameters(before, after)...)
+}
+
+// DiffMachineState сравнивает два результата ReadMachineState (GetMachineState)
+// и возвращает события смены состояния, режима, аварийного останова и тревог.
+func DiffMachineState(machineID string, before, after *models.UnifiedMachineData, ts time.Time) []models.MachineEvent {
+	if before == nil || after == nil {
+		return nil
+	}
+	b := &models.AggregatedData{}
+	a := &models.AggregatedData{MachineID: machineID, Timestamp: ts}
+	setMachineState(b, before)
+	setMachineState(a, after)
+	return diffState(b, a)
+}
+
+// Events подписывается на данные станка (см. Subscribe) и возвращает канал событий
+// изменений между последовательными снимками. Первое успешное чтение группы задает
+// исходные значения и событий не порождает; группы, прочитанные с ошибкой, пропускаются
+// до следующего успешного чтения. Если получатель не успевает, опрос ведет себя
+// согласно opts.Backpressure: при DropOldest кратковременные изменения между
+// вытесненными снимками могут не попасть в события.
+func (c *Client) Events(ctx context.Context, opts SubscribeOptions) (<-chan models.MachineEvent, error) {
+	snapshots, err := c.Subscribe(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	buffer := opts.Buffer
+	if buffer < 1 {
+		buffer = 1
+	}
+
+	ch := make(chan models.MachineEvent, buffer)
+	go func() {
+		defer close(ch)
+		detector := NewEventDetector()
+		for s := range snapshots {
+			for _, event := range detector.Detect(s) {
+				select {
+				case ch <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// EventDetector строит события изменений по последовательным снимкам подписки
+// так же, как Events, для тех, кто обрабатывает снимки сам. Не безопасен
+// для одновременного использования.
+type EventDetector struct {
+	base *models.AggregatedData // Последние успешно прочитанные значения
+	seen map[DataGroup]bool
+}
+
+// NewEventDetector создает детектор без исходных значений.
+func NewEventDetector() *EventDetector {
+	return &EventDetector{base: &models.AggregatedData{}, seen: make(map[DataGroup]bool)}
+}
+
+// Detect возвращает события изменений групп снимка s относительно последних успешно
+// прочитанных значений. Первое успешное чтение группы событий не порождает.
+func (d *EventDetector) Detect(s Snapshot) []models.MachineEvent {
+	var events []models.MachineEvent
+	for _, g := range s.Groups {
+		if s.Errors[g] != nil {
+			continue
+		}
+		if d.seen[g] {
+			events = append(events, diffGroup(g, d.base, s.Data)...)
+		}
+		copyGroup(g, d.base, s.Data)
+		d.seen[g] = true
+	}
+	return events
+}
+
+// diffGroup возвращает события для полей группы g.
+func diffGroup(g DataGroup, before, after *models.AggregatedData) []models.MachineEvent {
+	switch g {
+	case GroupState:
+		return diffState(before, after)
+	case GroupParameters:
+		return diffParameters(before, after)
+	}
+	return nil
+}
+
+// copyGroup переносит поля группы g, по которым строятся события, из src в dst.
+func copyGroup(g DataGroup, dst, src *models.AggregatedData) {
+	switch g {
+	case GroupState:
+		dst.MachineState = src.MachineState
+		dst.ProgramMode = src.ProgramMode
+		dst.EmergencyStatus = src.EmergencyStatus
+		dst.IsEmergency = src.IsEmergency
+		dst.Alarms = src.Alarms
+		dst.CurrentProgram = src.CurrentProgram
+	case GroupParameters:
+		dst.PartsCount = src.PartsCount
+	}
+}
+
+// diffState сравнивает состояние, режим, аварийный останов, тревоги и программу.
+func diffState(before, after *models.AggregatedData) []models.MachineEvent {
+	var events []models.MachineEvent
+	add := func(t models.EventType, key string, b, a any) {
+		events = append(events, models.MachineEvent{
+			Type:      t,
+			MachineID: after.MachineID,
+			Key:       key,
+			Before:    b,
+			After:     a,
+			Timestamp: after.Timestamp,
+		})
+	}
+
+	if before.MachineState != after.MachineState {
+		add(models.EventMachineStateChanged, "", before.MachineState, after.MachineState)
+	}
+	if before.ProgramMode != after.ProgramMode {
+		add(models.EventModeChanged, "", before.ProgramMode, after.ProgramMode)
+	}
+	switch {
+	case !before.IsEmergency && after.IsEmergency:
+		add(models.EventEmergencyEntered, "", before.EmergencyStatus, after.EmergencyStatus)
+	case before.IsEmergency && !after.IsEmergency:
+		add(models.EventEmergencyCleared, "", before.EmergencyStatus, after.EmergencyStatus)
+	}
+	// Смена строки G-кода — не смена программы
+	if before.CurrentProgram.ProgramName != after.CurrentProgram.ProgramName ||
+		before.CurrentProgram.ProgramNumber != after.CurrentProgram.ProgramNumber {
+		add(models.EventProgramChanged, "", before.CurrentProgram, after.CurrentProgram)
+	}
+
+	// Тревоги сравниваются по коду ошибки и только если они прочитаны: пустой список
+	// непрочитанных тревог — не их сброс
+	if alarmQuality(after) != models.QualityGood || !alarmQuality(before).HasValue() {
+		return events
+	}
+	previous := alarmsByCode(before.Alarms)
+	current := alarmsByCode(after.Alarms)
+	for _, alarm := range after.Alarms {
+		if _, ok := previous[alarm.ErrorCode]; !ok {
+			add(models.EventAlarmRaised, alarm.ErrorCode, nil, alarm)
+			previous[alarm.ErrorCode] = alarm // Повторяющиеся коды — одно событие
+		}
+	}
+	for _, alarm := range before.Alarms {
+		if _, ok := current[alarm.ErrorCode]; !ok {
+			add(models.EventAlarmCleared, alarm.ErrorCode, alarm, nil)
+			current[alarm.ErrorCode] = alarm
+		}
+	}
+	return events
+}
+
+// diffParameters сравнивает счетчик деталей. Уменьшение (сброс счетчика) событием не считается.
+func diffParameters(before, after *models.AggregatedData) []models.MachineEvent {
+	if after.PartsCount <= before.PartsCount {
+		return nil
+	}
+	return []models.MachineEvent{{
+		Type:      models.EventPartsCountIncremented,
+		MachineID: after.MachineID,
+		Before:    before.PartsCount,
+		After:     after.PartsCount,
+		Timestamp: after.Timestamp,
+	}}
+}
+
+// alarmQuality возвращает качество тревог снимка d. Тревоги снимков без Sections
+// (DiffMachineState, EventDetector) считаются прочитанными.
+func alarmQuality(d *models.AggregatedData) models.Quality {
+	if d.Sections == nil {
+		return models.QualityGood
+	}
+	return d.Sections[models.SectionAlarms].Quality
+}
+
+func alarmsByCode(alarms []models.AlarmDetail) map[string]models.AlarmDetail {
+	result := make(map[string]models.AlarmDetail, len(alarms))
+	for _, alarm := range alarms {
+		if _, ok := result[alarm.ErrorCode]; !ok {
+			result[alarm.ErrorCode] = alarm
+		}
+	}
+	return result
+}
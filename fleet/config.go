@@ -0,0 +1,149 @@
+package fleet
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	"github.com/iwtcode/fanucAdapter/focas/model"
+	"github.com/iwtcode/fanucAdapter/focas/worker"
+)
+
+// Допустимые значения Config.Isolation
+const (
+	// IsolationNone — вызовы FOCAS выполняются в текущем процессе.
+	IsolationNone = ""
+	// IsolationProcess — вызовы FOCAS выполняются в процессах-обработчиках (пакет focas/worker).
+	IsolationProcess = "process"
+)
+
+// MachineConfig описывает один станок парка.
+type MachineConfig struct {
+	// ID — уникальный идентификатор станка в парке. По умолчанию "IP:Port".
+	ID          string            `json:"id"`
+	IP          string            `json:"ip"`
+	Port        uint16            `json:"port"`
+	ModelSeries string            `json:"series,omitempty"`
+	TimeoutMs   int32             `json:"timeout_ms,omitempty"`
+	Transport   string            `json:"transport,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+
+	// WorkerGroup объединяет станки в один процесс-обработчик в режиме IsolationProcess.
+	// Вызовы станков группы, включая проверку доступности при подключении, выполняются
+	// по очереди, поэтому недоступный станок задерживает опрос остальных станков группы.
+	// Пустое значение — отдельный процесс для станка.
+	WorkerGroup string `json:"worker_group,omitempty"`
+
+	// PollInterval переопределяет Config.PollInterval для этого станка.
+	PollInterval Duration `json:"poll_interval,omitempty"`
+
+	// Backend задает реализацию вызовов FOCAS явно (например, fake.Backend в тестах).
+	Backend model.Backend `json:"-"`
+}
+
+// Config хранит настройки парка станков.
+type Config struct {
+	Machines []MachineConfig `json:"machines"`
+
+	// PollInterval — период опроса станков. По умолчанию 1 с.
+	PollInterval Duration `json:"poll_interval,omitempty"`
+	// PollTimeout ограничивает время одного опроса станка. По умолчанию 10 с.
+	PollTimeout Duration `json:"poll_timeout,omitempty"`
+	// MaxBackoff ограничивает паузу между опросами недоступного станка. По умолчанию 1 мин.
+	MaxBackoff Duration `json:"max_backoff,omitempty"`
+	// MaxConcurrentPolls ограничивает количество одновременных опросов. По умолчанию 4.
+	MaxConcurrentPolls int `json:"max_concurrent_polls,omitempty"`
+
+	// Isolation выбирает, где выполняются вызовы FOCAS: IsolationNone или IsolationProcess.
+	// В режиме IsolationProcess сбой libfwlib32 завершает только процесс-обработчик,
+	// который перезапускается, а станки разных процессов опрашиваются параллельно.
+	Isolation string `json:"isolation,omitempty"`
+	// Worker — параметры процессов-обработчиков; Transport берется из описания станка.
+	// В режиме IsolationProcess поле Worker.Command обязательно.
+	Worker worker.Options `json:"-"`
+
+	// Retry — политика повторов для клиентов всех станков.
+	Retry fanuc.RetryPolicy `json:"-"`
+	// Observer возвращает наблюдателя вызовов FOCAS для станка с указанным ID
+	// (см. fanuc.Config.Observer), например metrics.Exporter.Observer.
+	Observer func(machineID string) model.CallObserver `json:"-"`
+	// LogLevel — уровень логирования клиентов и парка.
+	LogLevel string `json:"log_level,omitempty"`
+}
+
+// Duration — time.Duration, который в JSON задается строкой ("500ms", "2s") или числом миллисекунд.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var ms int64
+	if err := json.Unmarshal(data, &ms); err == nil {
+		*d = Duration(time.Duration(ms) * time.Millisecond)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid duration %s", data)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// LoadConfig читает конфигурацию парка из JSON-файла.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// withDefaults заполняет незаданные поля значениями по умолчанию.
+func (c Config) withDefaults() Config {
+	if c.PollInterval <= 0 {
+		c.PollInterval = Duration(time.Second)
+	}
+	if c.PollTimeout <= 0 {
+		c.PollTimeout = Duration(10 * time.Second)
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = Duration(time.Minute)
+	}
+	if c.MaxConcurrentPolls <= 0 {
+		c.MaxConcurrentPolls = 4
+	}
+	if c.LogLevel == "" {
+		c.LogLevel = "info"
+	}
+	return c
+}
+
+// normalize проверяет описание станка и заполняет значения по умолчанию.
+func (m MachineConfig) normalize() (MachineConfig, error) {
+	if m.IP == "" && m.Backend == nil {
+		return m, fmt.Errorf("machine %q: ip is required", m.ID)
+	}
+	if m.Port == 0 {
+		m.Port = 8193
+	}
+	if m.TimeoutMs <= 0 {
+		m.TimeoutMs = 5000
+	}
+	if m.ID == "" {
+		m.ID = fmt.Sprintf("%s:%d", m.IP, m.Port)
+	}
+	return m, nil
+}
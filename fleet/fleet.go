@@ -0,0 +1,480 @@
+// Package fleet управляет парком станков FANUC в одном процессе: создает клиентов,
+// опрашивает станки по расписанию и хранит последние снимки данных по ID станка.
+//
+// Все вызовы FOCAS сериализуются глобальной блокировкой libLock, поэтому недоступный станок
+// мог бы надолго занимать ее таймаутами подключения. Чтобы он не мешал остальным, клиенты
+// создаются в ленивом режиме (подключение в фоне), число одновременных опросов ограничено,
+// а пауза между опросами станка с ошибками соединения растет экспоненциально.
+// В режиме IsolationProcess вызовы выполняются в процессах-обработчиках без общей блокировки.
+package fleet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	"github.com/iwtcode/fanucAdapter/focas"
+	"github.com/iwtcode/fanucAdapter/focas/model"
+	"github.com/iwtcode/fanucAdapter/focas/worker"
+	"github.com/iwtcode/fanucAdapter/models"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrUnknownMachine возвращается для станка, которого нет в парке.
+var ErrUnknownMachine = errors.New("unknown machine")
+
+// Snapshot — результат последнего опроса станка.
+type Snapshot struct {
+	MachineID string            `json:"machine_id"`
+	Tags      map[string]string `json:"tags,omitempty"`
+	// Data — последние успешно полученные данные. При ошибке опроса сохраняются прежние
+	// значения, а их разделы в Data.Sections помечаются как Stale.
+	Data       *models.AggregatedData  `json:"data,omitempty"`
+	Error      string                  `json:"error,omitempty"` // Ошибка последнего опроса
+	Err        error                   `json:"-"`
+	Connection models.ConnectionStatus `json:"connection"`
+	PolledAt   time.Time               `json:"polled_at"`  // Время последнего опроса
+	UpdatedAt  time.Time               `json:"updated_at"` // Время получения Data
+}
+
+// Fleet — парк станков с периодическим опросом.
+type Fleet struct {
+	cfg    Config
+	logger *logrus.Logger
+	sem    chan struct{} // ограничивает количество одновременных опросов
+
+	mu       sync.RWMutex
+	machines map[string]*machine
+	order    []string
+	workers  map[string]*sharedWorker // Процессы-обработчики по ключу группы
+	started  bool
+	closed   bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// machine — станок парка и его последний снимок.
+type machine struct {
+	cfg    MachineConfig
+	client *fanuc.Client
+	cancel context.CancelFunc // останавливает цикл опроса станка
+	worker string             // Ключ процесса-обработчика или пустая строка
+
+	mu       sync.Mutex
+	snapshot Snapshot
+}
+
+// sharedWorker — процесс-обработчик, общий для станков одной группы.
+type sharedWorker struct {
+	worker    *worker.Worker
+	transport string
+	refs      int
+}
+
+// New создает парк по конфигурации. Клиенты создаются в ленивом режиме и подключаются
+// в фоне, поэтому New не обращается к станкам. Опрос запускается методом Start.
+func New(cfg *Config) (*Fleet, error) {
+	c := cfg.withDefaults()
+	if c.Isolation != IsolationNone && c.Isolation != IsolationProcess {
+		return nil, fmt.Errorf("unknown isolation mode %q", c.Isolation)
+	}
+	if c.Isolation == IsolationProcess && c.Worker.Command == "" {
+		return nil, fmt.Errorf("isolation mode %q: %w", c.Isolation, worker.ErrNoCommand)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &Fleet{
+		cfg:      c,
+		logger:   newLogger(c.LogLevel),
+		sem:      make(chan struct{}, c.MaxConcurrentPolls),
+		machines: make(map[string]*machine),
+		workers:  make(map[string]*sharedWorker),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	for _, mc := range c.Machines {
+		if err := f.Add(mc); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// Add добавляет станок в парк. Если опрос уже запущен, станок начинает опрашиваться сразу.
+func (f *Fleet) Add(mc MachineConfig) error {
+	mc, err := mc.normalize()
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return errors.New("fleet is closed")
+	}
+	if _, exists := f.machines[mc.ID]; exists {
+		return fmt.Errorf("machine %q already exists", mc.ID)
+	}
+
+	backend, workerKey, err := f.acquireWorker(mc)
+	if err != nil {
+		return fmt.Errorf("machine %q: %w", mc.ID, err)
+	}
+	if backend == nil {
+		backend = mc.Backend
+	}
+
+	var observer model.CallObserver
+	if f.cfg.Observer != nil {
+		observer = f.cfg.Observer(mc.ID)
+	}
+	client, err := fanuc.New(&fanuc.Config{
+		IP:          mc.IP,
+		Port:        mc.Port,
+		TimeoutMs:   mc.TimeoutMs,
+		ModelSeries: mc.ModelSeries,
+		LogLevel:    f.cfg.LogLevel,
+		Transport:   mc.Transport,
+		Backend:     backend,
+		LazyConnect: true,
+		Retry:       f.cfg.Retry,
+		Observer:    observer,
+	})
+	if err != nil {
+		f.releaseWorker(workerKey)
+		return fmt.Errorf("machine %q: %w", mc.ID, err)
+	}
+
+	m := &machine{
+		cfg:    mc,
+		client: client,
+		worker: workerKey,
+		snapshot: Snapshot{
+			MachineID:  mc.ID,
+			Tags:       mc.Tags,
+			Connection: client.ConnectionStatus(),
+		},
+	}
+	f.machines[mc.ID] = m
+	f.order = append(f.order, mc.ID)
+	if f.started {
+		f.startMachine(m, 0)
+	}
+	return nil
+}
+
+// Remove останавливает опрос станка, закрывает его клиент и удаляет его из парка.
+func (f *Fleet) Remove(id string) error {
+	f.mu.Lock()
+	m, ok := f.machines[id]
+	if !ok {
+		f.mu.Unlock()
+		return fmt.Errorf("%w: %q", ErrUnknownMachine, id)
+	}
+	delete(f.machines, id)
+	for i, mid := range f.order {
+		if mid == id {
+			f.order = append(f.order[:i], f.order[i+1:]...)
+			break
+		}
+	}
+	f.mu.Unlock()
+
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.client.Close()
+
+	f.mu.Lock()
+	f.releaseWorker(m.worker)
+	f.mu.Unlock()
+	return nil
+}
+
+// Start запускает периодический опрос всех станков. Первые опросы распределяются
+// по интервалу опроса, чтобы станки не обращались к библиотеке одновременно.
+func (f *Fleet) Start() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.started || f.closed {
+		return
+	}
+	f.started = true
+
+	interval := time.Duration(f.cfg.PollInterval)
+	for i, id := range f.order {
+		offset := interval * time.Duration(i) / time.Duration(len(f.order))
+		f.startMachine(f.machines[id], offset)
+	}
+}
+
+// Close останавливает опрос и закрывает клиентов всех станков.
+func (f *Fleet) Close() {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return
+	}
+	f.closed = true
+	f.cancel()
+	machines := make([]*machine, 0, len(f.machines))
+	for _, m := range f.machines {
+		machines = append(machines, m)
+	}
+	workers := f.workers
+	f.workers = make(map[string]*sharedWorker)
+	f.mu.Unlock()
+
+	f.wg.Wait()
+	for _, m := range machines {
+		m.client.Close()
+	}
+	for _, w := range workers {
+		w.worker.Close()
+	}
+}
+
+// acquireWorker возвращает процесс-обработчик для станка в режиме IsolationProcess,
+// запуская его при необходимости. Вызывается под f.mu.
+func (f *Fleet) acquireWorker(mc MachineConfig) (model.Backend, string, error) {
+	if f.cfg.Isolation != IsolationProcess || mc.Backend != nil {
+		return nil, "", nil
+	}
+	key := "group:" + mc.WorkerGroup
+	if mc.WorkerGroup == "" {
+		key = "machine:" + mc.ID
+	}
+
+	if w, ok := f.workers[key]; ok {
+		if w.transport != mc.Transport {
+			return nil, "", fmt.Errorf("worker group %q uses transport %q, not %q", mc.WorkerGroup, w.transport, mc.Transport)
+		}
+		w.refs++
+		return w.worker, key, nil
+	}
+
+	opts := f.cfg.Worker
+	opts.Transport = mc.Transport
+	if opts.Logger == nil {
+		opts.Logger = f.logger
+	}
+	w, err := worker.New(opts)
+	if err != nil {
+		return nil, "", err
+	}
+	f.workers[key] = &sharedWorker{worker: w, transport: mc.Transport, refs: 1}
+	return w, key, nil
+}
+
+// releaseWorker завершает процесс-обработчик, когда его не использует ни один станок.
+// Вызывается под f.mu.
+func (f *Fleet) releaseWorker(key string) {
+	w, ok := f.workers[key]
+	if !ok {
+		return
+	}
+	w.refs--
+	if w.refs == 0 {
+		delete(f.workers, key)
+		w.worker.Close()
+	}
+}
+
+// Client возвращает клиент станка для прямых вызовов.
+func (f *Fleet) Client(id string) (*fanuc.Client, bool) {
+	m, ok := f.machine(id)
+	if !ok {
+		return nil, false
+	}
+	return m.client, true
+}
+
+// Snapshot возвращает последний снимок станка.
+func (f *Fleet) Snapshot(id string) (Snapshot, bool) {
+	m, ok := f.machine(id)
+	if !ok {
+		return Snapshot{}, false
+	}
+	return m.current(), true
+}
+
+// Snapshots возвращает последние снимки всех станков по их ID.
+func (f *Fleet) Snapshots() map[string]Snapshot {
+	f.mu.RLock()
+	machines := make([]*machine, 0, len(f.machines))
+	for _, m := range f.machines {
+		machines = append(machines, m)
+	}
+	f.mu.RUnlock()
+
+	result := make(map[string]Snapshot, len(machines))
+	for _, m := range machines {
+		result[m.cfg.ID] = m.current()
+	}
+	return result
+}
+
+// Machines возвращает описания станков в порядке добавления.
+func (f *Fleet) Machines() []MachineConfig {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	result := make([]MachineConfig, 0, len(f.order))
+	for _, id := range f.order {
+		result = append(result, f.machines[id].cfg)
+	}
+	return result
+}
+
+// Select возвращает отсортированные ID станков, у которых есть все указанные теги.
+func (f *Fleet) Select(tags map[string]string) []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	var ids []string
+	for id, m := range f.machines {
+		matched := true
+		for k, v := range tags {
+			if m.cfg.Tags[k] != v {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (f *Fleet) machine(id string) (*machine, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	m, ok := f.machines[id]
+	return m, ok
+}
+
+// startMachine запускает цикл опроса станка. Вызывается под f.mu.
+func (f *Fleet) startMachine(m *machine, offset time.Duration) {
+	ctx, cancel := context.WithCancel(f.ctx)
+	m.cancel = cancel
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		f.run(ctx, m, offset)
+	}()
+}
+
+// run опрашивает станок до отмены ctx. После ошибок соединения пауза до следующего
+// опроса удваивается (до MaxBackoff), чтобы недоступный станок реже занимал libLock.
+func (f *Fleet) run(ctx context.Context, m *machine, delay time.Duration) {
+	interval := time.Duration(f.cfg.PollInterval)
+	if m.cfg.PollInterval > 0 {
+		interval = time.Duration(m.cfg.PollInterval)
+	}
+	maxBackoff := time.Duration(f.cfg.MaxBackoff)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	failures := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		err := f.poll(ctx, m)
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err == nil || !costlyError(err):
+			failures = 0
+			delay = interval
+		default:
+			failures++
+			delay = min(interval<<min(failures, 16), maxBackoff)
+			f.logger.Debugf("Machine %s: poll failed (%v), next poll in %s", m.cfg.ID, err, delay)
+		}
+		timer.Reset(delay)
+	}
+}
+
+// poll выполняет один опрос станка и обновляет его снимок.
+func (f *Fleet) poll(ctx context.Context, m *machine) error {
+	select {
+	case f.sem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	pctx, cancel := context.WithTimeout(ctx, time.Duration(f.cfg.PollTimeout))
+	data, err := m.client.GetCurrentDataCtx(pctx)
+	cancel()
+	<-f.sem
+
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := &m.snapshot
+	s.PolledAt = now
+	s.Connection = m.client.ConnectionStatus()
+	s.Err = err
+	s.Error = ""
+	if data != nil {
+		// Разделы, которые не удалось прочитать, сохраняют прежние значения с качеством Stale
+		data.MachineID = m.cfg.ID
+		data.MergeStale(s.Data)
+	}
+	if err != nil {
+		s.Error = err.Error()
+		if data != nil && s.Data != nil {
+			s.Data = data
+		}
+		return err
+	}
+	s.Data = data
+	s.UpdatedAt = now
+	return nil
+}
+
+func (m *machine) current() Snapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.snapshot
+	if s.PolledAt.IsZero() {
+		s.Connection = m.client.ConnectionStatus()
+	}
+	return s
+}
+
+// costlyError сообщает, заняла ли неудачная попытка время на обращение к станку.
+// Ошибки, возвращаемые без вызовов FOCAS (нет подключения, разомкнут предохранитель),
+// не увеличивают паузу между опросами.
+func costlyError(err error) bool {
+	return !errors.Is(err, focas.ErrNotConnected) && !errors.Is(err, focas.ErrCircuitOpen)
+}
+
+func newLogger(level string) *logrus.Logger {
+	logger := logrus.New()
+	if level == "off" || level == "none" {
+		logger.SetOutput(io.Discard)
+		return logger
+	}
+	if lvl, err := logrus.ParseLevel(level); err == nil {
+		logger.SetLevel(lvl)
+	}
+	logger.SetOutput(os.Stdout)
+	logger.SetFormatter(&logrus.TextFormatter{
+		FullTimestamp:   true,
+		TimestampFormat: "2006-01-02 15:04:05",
+	})
+	return logger
+}
@@ -1,26 +1,13 @@
 package focas
 
-/*
-#cgo CFLAGS: -I${SRCDIR}
-#cgo LDFLAGS: -L${SRCDIR} -lfwlib32
-#cgo linux LDFLAGS: -Wl,-rpath,${SRCDIR}
-
-#include <stdlib.h>
-#include <string.h>
-#include "c_helpers.h"
-*/
-import "C"
-
 import (
+	"context"
+	"errors"
 	"fmt"
-	"net"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
-	"unsafe"
 
 	. "github.com/iwtcode/fanucAdapter/focas/errcode"
 	"github.com/iwtcode/fanucAdapter/focas/model"
@@ -32,47 +19,106 @@ import (
 // Библиотека FOCAS на Linux чувствительна к конкурентным вызовам даже с разными хендлами.
 var libLock sync.Mutex
 
+// callLock возвращает блокировку для вызовов бэкенда: собственную, если бэкенд
+// реализует model.CallLocker, иначе глобальную libLock.
+func callLock(backend model.Backend) sync.Locker {
+	if l, ok := backend.(model.CallLocker); ok {
+		return l.CallLock()
+	}
+	return &libLock
+}
+
 // FocasAdapter инкапсулирует логику подключения и вызовов к FOCAS API.
 // Он также управляет автоматическим переподключением и содержит реализации
 // для конкретной модели станка.
 type FocasAdapter struct {
-	ip            string
-	port          uint16
-	timeout       int32
-	handle        uint16
-	mu            sync.Mutex
-	sysInfo       *models.SystemInfo
-	interpreter   model.Interpreter   // Интерфейс для интерпретации состояния
-	programReader model.ProgramReader // Интерфейс для чтения программы
-	logger        logrus.FieldLogger  // Локальный логгер
+	backend        model.Backend
+	lock           sync.Locker // Блокировка вызовов бэкенда (см. callLock)
+	ip             string
+	port           uint16
+	timeout        int32
+	handle         uint16
+	mu             sync.Mutex
+	sysInfo        *models.SystemInfo
+	modelSeries    string              // Серия из конфигурации; пустая — определяется по cnc_sysinfo
+	implementation string              // Имя выбранной реализации, например "Model0i"
+	interpreter    model.Interpreter   // Интерфейс для интерпретации состояния
+	programReader  model.ProgramReader // Интерфейс для чтения программы
+	logger         logrus.FieldLogger  // Локальный логгер
+	retry          RetryPolicy         // Политика повторов при ошибках соединения
+	breaker        circuitBreaker      // Предохранитель для недоступного станка
+	path           pathState           // Текущий канал хендла; защищен libLock
+	conn           *connTracker        // Состояние соединения и подписчики на его смену
+	ready          bool                // Соединение хотя бы раз было установлено
+	lastConnErr    error               // Последняя ошибка первичного подключения (ленивый режим)
+	ctx            context.Context     // Отменяется при закрытии адаптера
+	cancel         context.CancelFunc
 }
 
 // Убедимся, что FocasAdapter удовлетворяет интерфейсу FocasCaller.
 var _ model.FocasCaller = (*FocasAdapter)(nil)
 
-// NewFocasAdapter создает новый экземпляр FocasAdapter и устанавливает соединение.
+// ErrNoBackend возвращается, если бэкенд FOCAS не указан и недоступен по умолчанию (сборка без cgo).
+var ErrNoBackend = errors.New("no FOCAS backend available")
+
+// AdapterOptions задает параметры адаптера для NewFocasAdapterWithOptions
+// и NewLazyFocasAdapter.
+type AdapterOptions struct {
+	// Backend — реализация вызовов FOCAS. Если nil, используется DefaultBackend.
+	Backend     model.Backend
+	IP          string
+	Port        uint16
+	TimeoutMs   int32
+	ModelSeries string // Пустая — серия определяется по cnc_sysinfo
+	Logger      logrus.FieldLogger
+	// Retry — политика повторов; действует с первого вызова. Незаполненные поля
+	// берутся из DefaultRetryPolicy.
+	Retry RetryPolicy
+}
+
+// backend возвращает бэкенд из параметров или бэкенд по умолчанию.
+func (o AdapterOptions) backend() (model.Backend, error) {
+	if o.Backend != nil {
+		return o.Backend, nil
+	}
+	if backend := DefaultBackend(); backend != nil {
+		return backend, nil
+	}
+	return nil, ErrNoBackend
+}
+
+// NewFocasAdapter создает новый экземпляр FocasAdapter и устанавливает соединение
+// через бэкенд по умолчанию с политикой повторов DefaultRetryPolicy.
 func NewFocasAdapter(ip string, port uint16, timeoutMs int32, modelSeries string, logger logrus.FieldLogger) (*FocasAdapter, error) {
-	handle, err := Connect(ip, port, timeoutMs)
+	return NewFocasAdapterWithOptions(AdapterOptions{
+		IP:          ip,
+		Port:        port,
+		TimeoutMs:   timeoutMs,
+		ModelSeries: modelSeries,
+		Logger:      logger,
+	})
+}
+
+// NewFocasAdapterWithOptions создает адаптер с параметрами opts и устанавливает соединение.
+func NewFocasAdapterWithOptions(opts AdapterOptions) (*FocasAdapter, error) {
+	backend, err := opts.backend()
 	if err != nil {
-		return nil, fmt.Errorf("initial connection failed: %w", err)
+		return nil, err
 	}
 
-	// Используем фабрику с вручную указанной серией для получения нужных реализаций
-	interpreter, programReader := GetModelImplementations(modelSeries)
-
-	adapter := &FocasAdapter{
-		ip:            ip,
-		port:          port,
-		timeout:       timeoutMs,
-		handle:        handle,
-		interpreter:   interpreter,
-		programReader: programReader,
-		logger:        logger,
+	handle, err := Connect(backend, opts.IP, opts.Port, opts.TimeoutMs)
+	if err != nil {
+		return nil, fmt.Errorf("initial connection failed: %w", err)
 	}
 
-	sysInfo, err := adapter.ReadSystemInfo()
+	adapter := newAdapter(backend, opts, models.ConnectionConnecting)
+	adapter.handle = handle
+	adapter.ready = true
+
+	sysInfo, err := adapter.ReadSystemInfo(context.Background())
 	if err != nil {
-		Disconnect(handle)
+		// Close освобождает текущий хендл (ReadSystemInfo мог переподключиться) и отменяет контекст адаптера
+		adapter.Close()
 		return nil, fmt.Errorf("failed to read system info after connecting: %w", err)
 	}
 	adapter.sysInfo = sysInfo
@@ -80,63 +126,91 @@ func NewFocasAdapter(ip string, port uint16, timeoutMs int32, modelSeries string
 	return adapter, nil
 }
 
+// newAdapter создает адаптер без соединения в указанном начальном состоянии.
+func newAdapter(backend model.Backend, opts AdapterOptions, state models.ConnectionState) *FocasAdapter {
+	// До чтения cnc_sysinfo используем реализации для серии из конфигурации;
+	// после подключения applyModel уточняет выбор по данным станка
+	interpreter, programReader, implementation := resolveImplementations(opts.ModelSeries)
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &FocasAdapter{
+		backend:        backend,
+		lock:           callLock(backend),
+		ip:             opts.IP,
+		port:           opts.Port,
+		timeout:        opts.TimeoutMs,
+		modelSeries:    opts.ModelSeries,
+		implementation: implementation,
+		interpreter:    interpreter,
+		programReader:  programReader,
+		logger:         logger,
+		retry:          opts.Retry.withDefaults(),
+		conn:           newConnTracker(fmt.Sprintf("%s:%d", opts.IP, opts.Port), state),
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+}
+
 // Startup инициализирует процесс FOCAS2
-func Startup(mode uint16, logPath string) error {
-	libLock.Lock()
-	defer libLock.Unlock()
-
-	var cpath *C.char
-	if logPath != "" {
-		dir := filepath.Dir(logPath)
-		if dir != "" && dir != "." {
-			_ = os.MkdirAll(dir, 0o755)
-		}
-		cpath = C.CString(logPath)
-		defer C.free(unsafe.Pointer(cpath))
-	} else {
-		cpath = C.CString("")
-		defer C.free(unsafe.Pointer(cpath))
+func Startup(backend model.Backend, mode uint16, logPath string) error {
+	if backend == nil {
+		backend = DefaultBackend()
 	}
+	if backend == nil {
+		return ErrNoBackend
+	}
+
+	lock := callLock(backend)
+	lock.Lock()
+	defer lock.Unlock()
 
-	rc := C.go_cnc_startupprocess(C.ushort(mode), cpath)
-	if int16(rc) != EW_OK {
-		return fmt.Errorf("cnc_startupprocess(%d, %q) rc=%d", mode, logPath, int16(rc))
+	rc := backend.Startup(mode, logPath)
+	if rc != EW_OK {
+		return fmt.Errorf("startup(%d, %q): %w", mode, logPath, model.NewError(nil, "cnc_startupprocess", 0, rc))
 	}
 	return nil
 }
 
 // Connect подключается к станку и возвращает хендл
-func Connect(ip string, port uint16, timeoutMs int32) (uint16, error) {
-	target := net.JoinHostPort(ip, strconv.Itoa(int(port)))
-	conn, err := net.DialTimeout("tcp", target, 2*time.Second)
-	if err != nil {
-		return 0, fmt.Errorf("network pre-check failed (machine unreachable): %w", err)
+func Connect(backend model.Backend, ip string, port uint16, timeoutMs int32) (uint16, error) {
+	// Проверка доступности выполняется до взятия блокировки (см. model.Prober)
+	if p, ok := backend.(model.Prober); ok {
+		if rc := p.Probe(ip, port); rc != EW_OK {
+			return 0, model.NewError(nil, "cnc_allclibhndl3", 0, rc)
+		}
 	}
-	conn.Close()
-
-	libLock.Lock()
-	defer libLock.Unlock()
 
-	cip := C.CString(ip)
-	defer C.free(unsafe.Pointer(cip))
+	lock := callLock(backend)
+	lock.Lock()
+	defer lock.Unlock()
 
-	var h C.ushort
-	rc := C.go_cnc_allclibhndl3(cip, C.ushort(port), C.long(timeoutMs), &h)
-	if int16(rc) != EW_OK {
-		return 0, fmt.Errorf("cnc_allclibhndl3 failed: rc=%d", int16(rc))
+	h, rc := backend.AllcLibHndl3(ip, port, timeoutMs)
+	if rc != EW_OK {
+		return 0, model.NewError(nil, "cnc_allclibhndl3", 0, rc)
 	}
-	return uint16(h), nil
+	return h, nil
 }
 
 // Disconnect освобождает хендл подключения
-func Disconnect(handle uint16) {
+func Disconnect(backend model.Backend, handle uint16) {
 	if handle == 0 {
 		return
 	}
-	libLock.Lock()
-	defer libLock.Unlock()
+	lock := callLock(backend)
+	lock.Lock()
+	defer lock.Unlock()
+
+	backend.FreeLibHndl(handle)
+}
 
-	C.go_cnc_freelibhndl(C.ushort(handle))
+// Backend возвращает бэкенд, через который адаптер выполняет вызовы FOCAS (реализация FocasCaller).
+func (a *FocasAdapter) Backend() model.Backend {
+	return a.backend
 }
 
 // Logger возвращает текущий логгер адаптера (реализация FocasCaller).
@@ -146,31 +220,80 @@ func (a *FocasAdapter) Logger() logrus.FieldLogger {
 
 // Reconnect пытается восстановить соединение.
 func (a *FocasAdapter) Reconnect() error {
+	if err := a.reconnect(); err != nil {
+		return err
+	}
+
+	// Подписчиков уведомляем вне a.mu, чтобы они могли обращаться к адаптеру
+	a.conn.reconnected()
+	a.logger.Infof("Successfully reconnected to FOCAS at %s:%d", a.ip, a.port)
+	return nil
+}
+
+// reconnect заменяет хендл соединения новым.
+func (a *FocasAdapter) reconnect() error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
 	if a.handle != 0 {
 		// Disconnect сам берет libLock, поэтому здесь просто вызываем его
 		// (внимание: Disconnect берет libLock внутри, поэтому здесь безопасно)
-		Disconnect(a.handle)
+		Disconnect(a.backend, a.handle)
 		a.handle = 0
 		time.Sleep(50 * time.Millisecond)
 	}
 
-	newHandle, err := Connect(a.ip, a.port, a.timeout)
+	newHandle, err := Connect(a.backend, a.ip, a.port, a.timeout)
 	if err != nil {
 		return fmt.Errorf("Reconnect failed: %w", err)
 	}
 
 	a.handle = newHandle
-	a.logger.Infof("Successfully reconnected to FOCAS at %s:%d", a.ip, a.port)
 	return nil
 }
 
+// SetRetryPolicy задает политику повторов для CallWithReconnect.
+// Незаполненные поля берутся из DefaultRetryPolicy.
+func (a *FocasAdapter) SetRetryPolicy(policy RetryPolicy) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.retry = policy.withDefaults()
+}
+
 // CallWithReconnect — это обертка для выполнения вызовов с возможностью переподключения.
-// Она гарантирует, что C-вызов выполняется эксклюзивно (через libLock).
-func (a *FocasAdapter) CallWithReconnect(f func(handle uint16) (int16, error)) error {
-	for {
+// Она гарантирует, что C-вызов выполняется эксклюзивно (через libLock или блокировку бэкенда).
+//
+// При ошибках соединения (EW_HANDLE, EW_SOCKET) вызов повторяется согласно RetryPolicy
+// с экспоненциальной паузой между неудачными переподключениями. Отмена ctx прерывает
+// ожидание между попытками; уже начатый вызов FOCAS прервать нельзя.
+// Пока предохранитель разомкнут, вызов сразу завершается с ErrCircuitOpen.
+// Если ctx содержит канал (WithPath), хендл переключается на него под той же блокировкой.
+func (a *FocasAdapter) CallWithReconnect(ctx context.Context, f func(handle uint16) (int16, error)) error {
+	if connecting(ctx) {
+		return a.callConnecting(ctx, f)
+	}
+	if err := a.checkConnected(); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	policy := a.retry
+	a.mu.Unlock()
+
+	if err := a.breaker.allow(policy); err != nil {
+		return err
+	}
+	path := PathFromContext(ctx)
+
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			if attempt > 1 {
+				// Вызов прерван посреди переподключения
+				a.conn.disconnected(err)
+			}
+			return err
+		}
+
 		a.mu.Lock()
 		currentHandle := a.handle
 		a.mu.Unlock()
@@ -178,134 +301,191 @@ func (a *FocasAdapter) CallWithReconnect(f func(handle uint16) (int16, error)) e
 		// === GLOBAL LOCK START ===
 		// Сериализуем доступ к C-библиотеке, чтобы разные горутины
 		// (разные станки) не вызывали функции FOCAS одновременно.
-		libLock.Lock()
-		rc, err := f(currentHandle)
-		libLock.Unlock()
+		a.lock.Lock()
+		rc, err := a.switchPath(currentHandle, path)
+		if err == nil {
+			rc, err = f(currentHandle)
+		}
+		a.lock.Unlock()
 		// === GLOBAL LOCK END ===
 
 		if err == nil {
+			a.breaker.success()
+			a.conn.succeeded()
 			return nil
 		}
 
-		if rc == EW_HANDLE || rc == EW_SOCKET {
-			a.logger.Warnf("Connection error detected (rc=%d). Attempting to Reconnect...", rc)
+		if rc != EW_HANDLE && rc != EW_SOCKET {
+			// Станок ответил, значит соединение в порядке
+			a.breaker.success()
+			a.conn.failed(err, false)
+			return err
+		}
+		a.conn.failed(err, true)
 
-			if reconnErr := a.Reconnect(); reconnErr != nil {
-				a.logger.Errorf("Reconnect failed: %v. Retrying in 500ms...", reconnErr)
-				time.Sleep(500 * time.Millisecond)
-				continue
+		if policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts {
+			if a.breaker.failure(policy) {
+				a.logger.Errorf("Machine %s:%d is unavailable, circuit breaker opened for %s", a.ip, a.port, policy.BreakerCooldown)
 			}
+			err = fmt.Errorf("%w after %d attempts: %w", ErrRetriesExhausted, attempt, err)
+			a.conn.disconnected(err)
+			return err
+		}
 
-			continue
+		a.logger.Warnf("Connection error detected (rc=%d). Attempting to Reconnect...", rc)
 
-		} else {
-			return err
+		if reconnErr := a.Reconnect(); reconnErr != nil {
+			delay := policy.backoff(attempt)
+			a.logger.Errorf("Reconnect failed: %v. Retrying in %s...", reconnErr, delay.Round(time.Millisecond))
+			if err := sleepCtx(ctx, delay); err != nil {
+				a.conn.disconnected(err)
+				return err
+			}
 		}
 	}
 }
 
 // Close закрывает соединение.
 func (a *FocasAdapter) Close() {
+	a.cancel()
+
 	a.mu.Lock()
-	defer a.mu.Unlock()
 	if a.handle != 0 {
-		Disconnect(a.handle)
+		Disconnect(a.backend, a.handle)
 		a.handle = 0
 	}
+	a.mu.Unlock()
+
+	a.conn.closed()
+}
+
+// Done возвращает канал, который закрывается при закрытии адаптера.
+func (a *FocasAdapter) Done() <-chan struct{} {
+	return a.ctx.Done()
+}
+
+// ConnectionStatus возвращает текущее состояние соединения со станком.
+func (a *FocasAdapter) ConnectionStatus() models.ConnectionStatus {
+	return a.conn.Status()
+}
+
+// OnConnectionChange подписывает fn на смену состояния соединения и возвращает функцию отписки.
+// События доставляются синхронно и по порядку, поэтому fn не должна блокироваться
+// и обращаться к станку через этот же адаптер.
+func (a *FocasAdapter) OnConnectionChange(fn func(models.ConnectionEvent)) func() {
+	return a.conn.subscribe(fn)
 }
 
 // GetSystemInfo возвращает системную информацию о станке.
+// В ленивом режиме до первого подключения возвращает nil.
 func (a *FocasAdapter) GetSystemInfo() *models.SystemInfo {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	return a.sysInfo
 }
 
 // ReadSystemInfo считывает и возвращает системную информацию о станке.
-func (a *FocasAdapter) ReadSystemInfo() (*models.SystemInfo, error) {
-	var sysInfo C.ODBSYS
-	var rc C.short
-
-	err := a.CallWithReconnect(func(handle uint16) (int16, error) {
-		rc = C.go_cnc_sysinfo(C.ushort(handle), &sysInfo)
-		if int16(rc) != EW_OK {
-			return int16(rc), fmt.Errorf("go_cnc_sysinfo rc=%d", int16(rc))
+func (a *FocasAdapter) ReadSystemInfo(ctx context.Context) (*models.SystemInfo, error) {
+	var sysInfo model.SysInfo
+
+	err := a.CallWithReconnect(ctx, func(handle uint16) (int16, error) {
+		var rc int16
+		sysInfo, rc = a.backend.SysInfo(handle)
+		if rc != EW_OK {
+			return rc, a.callError("cnc_sysinfo", handle, rc)
 		}
-		return int16(rc), nil
+		return rc, nil
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	series := C.GoStringN(&sysInfo.series[0], C.int(len(sysInfo.series)))
-	version := C.GoStringN(&sysInfo.version[0], C.int(len(sysInfo.version)))
-	axesStr := C.GoStringN(&sysInfo.axes[0], C.int(len(sysInfo.axes)))
-
-	controlledAxes, err := strconv.Atoi(trimNull(axesStr))
+	controlledAxes, err := strconv.Atoi(sysInfo.Axes)
 	if err != nil {
 		controlledAxes = 0
 	}
 
 	data := &models.SystemInfo{
 		Manufacturer:   "FANUC",
-		Series:         trimNull(series),
-		Version:        trimNull(version),
-		Model:          fmt.Sprintf("Series %s Version %s", trimNull(series), trimNull(version)),
-		MaxAxes:        int16(sysInfo.max_axis),
+		Series:         sysInfo.Series,
+		Version:        sysInfo.Version,
+		Model:          fmt.Sprintf("Series %s Version %s", sysInfo.Series, sysInfo.Version),
+		MaxAxes:        sysInfo.MaxAxis,
 		ControlledAxes: int16(controlledAxes),
+		CncType:        strings.TrimSpace(sysInfo.CncType),
 	}
 
-	return data, nil
-}
+	if data.Paths, err = a.readPaths(ctx); err != nil {
+		return nil, err
+	}
 
-// ReadMachineState считывает и интерпретирует состояние станка, используя реализацию для конкретной модели.
-func (a *FocasAdapter) ReadMachineState() (*models.UnifiedMachineData, error) {
-	var stat C.ODBST
-	var rc C.short
+	detected, machineType := DetectModelSeries(sysInfo)
+	data.MachineType = machineType
+	a.applyModel(data, detected)
 
-	err := a.CallWithReconnect(func(handle uint16) (int16, error) {
-		rc = C.go_cnc_statinfo(C.ushort(handle), &stat)
-		if int16(rc) != EW_OK {
-			return int16(rc), fmt.Errorf("go_cnc_statinfo rc=%d", int16(rc))
-		}
-		return int16(rc), nil
-	})
+	return data, nil
+}
 
+// ReadMachineState считывает и интерпретирует состояние станка, используя реализацию для конкретной модели,
+// вместе с активными тревогами. Ошибка чтения тревог возвращается: пустой список тревог
+// вместо непрочитанного неотличим от их сброса.
+func (a *FocasAdapter) ReadMachineState(ctx context.Context) (*models.UnifiedMachineData, error) {
+	machineData, err := a.readState(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// Делегируем интерпретацию состояния конкретной реализации, передавая указатель
-	machineData := a.interpreter.InterpretMachineState(unsafe.Pointer(&stat))
-
-	// Считываем и добавляем информацию об ошибках
-	alarms, err := a.ReadAlarms()
+	alarms, err := a.ReadAlarms(ctx)
 	if err != nil {
-		a.logger.Warnf("Warning: could not read alarms: %v", err)
-	} else {
-		machineData.Alarms = alarms
+		return nil, fmt.Errorf("failed to read alarms: %w", err)
 	}
+	machineData.Alarms = alarms
 
 	return machineData, nil
 }
 
+// readState считывает и интерпретирует состояние станка без тревог.
+func (a *FocasAdapter) readState(ctx context.Context) (*models.UnifiedMachineData, error) {
+	var stat model.StatInfo
+
+	err := a.CallWithReconnect(ctx, func(handle uint16) (int16, error) {
+		var rc int16
+		stat, rc = a.backend.StatInfo(handle)
+		if rc != EW_OK {
+			return rc, a.callError("cnc_statinfo", handle, rc)
+		}
+		return rc, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Делегируем интерпретацию состояния конкретной реализации
+	interpreter, _ := a.implementations()
+	return interpreter.InterpretMachineState(&stat), nil
+}
+
 // GetControlProgram считывает G-код программы, используя реализацию для конкретной модели.
-func (a *FocasAdapter) GetControlProgram() (string, error) {
-	return a.programReader.GetControlProgram(a)
+func (a *FocasAdapter) GetControlProgram(ctx context.Context) (string, error) {
+	_, programReader := a.implementations()
+	return programReader.GetControlProgram(ctx, a)
 }
 
 // ReadProgram считывает информацию о текущей выполняемой программе и текущую строку G-кода.
 // Этот метод является частью интерфейса model.FocasCaller.
-func (a *FocasAdapter) ReadProgram() (*models.ProgramInfo, error) {
-	nameBuf := make([]byte, 64)
-	var onum C.long
-	var rc C.short
-
-	err := a.CallWithReconnect(func(handle uint16) (int16, error) {
-		rc = C.go_cnc_exeprgname(C.ushort(handle), (*C.char)(unsafe.Pointer(&nameBuf[0])), C.int(len(nameBuf)), &onum)
-		if int16(rc) != EW_OK {
-			return int16(rc), fmt.Errorf("cnc_exeprgname rc=%d", int16(rc))
+func (a *FocasAdapter) ReadProgram(ctx context.Context) (*models.ProgramInfo, error) {
+	var name string
+	var onum int64
+
+	err := a.CallWithReconnect(ctx, func(handle uint16) (int16, error) {
+		var rc int16
+		name, onum, rc = a.backend.ExePrgName(handle)
+		if rc != EW_OK {
+			return rc, a.callError("cnc_exeprgname", handle, rc)
 		}
-		return int16(rc), nil
+		return rc, nil
 	})
 
 	if err != nil {
@@ -313,8 +493,8 @@ func (a *FocasAdapter) ReadProgram() (*models.ProgramInfo, error) {
 	}
 
 	progInfo := &models.ProgramInfo{
-		Name:   trimNull(string(nameBuf)),
-		Number: int64(onum),
+		Name:   name,
+		Number: onum,
 	}
 
 	const (
@@ -322,32 +502,32 @@ func (a *FocasAdapter) ReadProgram() (*models.ProgramInfo, error) {
 		busyRetryDelay = 50 * time.Millisecond
 	)
 
-	err = a.CallWithReconnect(func(handle uint16) (int16, error) {
-		var rc C.short
+	err = a.CallWithReconnect(ctx, func(handle uint16) (int16, error) {
+		var rc int16
 		for i := 0; i < maxBusyRetries; i++ {
-			var length C.ushort = 256
-			var blknum C.short
+			var length uint16 = 256
+			var blknum int16
 			dataBuf := make([]byte, length)
 
-			rc = C.go_cnc_rdexecprog(C.ushort(handle), &length, &blknum, (*C.char)(unsafe.Pointer(&dataBuf[0])))
+			rc = a.backend.RdExecProg(handle, &length, &blknum, dataBuf)
 
-			if int16(rc) == EW_OK {
+			if rc == EW_OK {
 				fullBlock := trimNull(string(dataBuf[:length]))
 				lines := strings.Split(fullBlock, "\n")
 				progInfo.CurrentGCode = lines[0]
-				return int16(rc), nil
+				return rc, nil
 			}
 
-			if int16(rc) == EW_HANDLE {
+			if rc == EW_HANDLE {
 				time.Sleep(busyRetryDelay)
 				continue // Контроллер занят, повторяем
 			}
 
 			// Любая другая ошибка
-			return int16(rc), fmt.Errorf("cnc_rdexecprog failed with rc=%d", int16(rc))
+			return rc, a.callError("cnc_rdexecprog", handle, rc)
 		}
 		// Если вышли из цикла
-		return int16(rc), fmt.Errorf("cnc_rdexecprog timed out after %d retries (last rc=%d)", maxBusyRetries, int16(rc))
+		return rc, fmt.Errorf("timed out after %d retries: %w", maxBusyRetries, a.callError("cnc_rdexecprog", handle, rc))
 	})
 
 	if err != nil {
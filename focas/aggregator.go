@@ -1,6 +1,8 @@
 package focas
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -8,73 +10,228 @@ import (
 )
 
 // AggregateAllData собирает все доступные данные со станка последовательно.
-func (a *FocasAdapter) AggregateAllData() (*models.AggregatedData, error) {
-	// 1. Получение состояния станка
-	machineState, err := a.ReadMachineState()
-	if err != nil {
-		return nil, fmt.Errorf("failed to read machine state: %w", err)
+// Ошибка одного раздела не прерывает сбор (см. AggregateSections).
+func (a *FocasAdapter) AggregateAllData(ctx context.Context) (*models.AggregatedData, error) {
+	return a.AggregateSections(ctx, models.AllSections()...)
+}
+
+// AggregateSections собирает только указанные разделы сводных данных; вызовы FOCAS
+// остальных разделов не выполняются. Качество и время чтения каждого раздела
+// записываются в data.Sections, а поля разделов с ошибкой остаются нулевыми
+// (в JSON — null). После ошибки соединения
+// (нет связи, разомкнут предохранитель, исчерпаны повторы) оставшиеся разделы не читаются
+// и получают ту же ошибку. Ошибка возвращается вместе с данными, только если не удалось
+// прочитать ни один раздел.
+func (a *FocasAdapter) AggregateSections(ctx context.Context, sections ...models.Section) (*models.AggregatedData, error) {
+	data := &models.AggregatedData{
+		MachineID: fmt.Sprintf("%s:%d", a.ip, a.port),
+		Timestamp: time.Now().UTC(),
+		IsEnabled: true,
+		Sections:  make(map[models.Section]models.SectionStatus, len(sections)),
 	}
 
-	// 2. Получение данных по осям
-	axisData, err := a.ReadAxisData()
-	if err != nil {
-		return nil, fmt.Errorf("failed to read axis data: %w", err)
+	var connErr, lastErr error
+	succeeded := 0
+	for _, section := range sections {
+		if _, done := data.Sections[section]; done {
+			continue
+		}
+
+		err := connErr
+		if err == nil {
+			err = a.readSection(ctx, data, section)
+			if err != nil && isConnectionError(ctx, err) {
+				connErr = err
+			}
+		}
+
+		status := sectionStatus(err)
+		data.Sections[section] = status
+		switch status.Status {
+		case models.StatusOK:
+			succeeded++
+		case models.StatusUnsupported:
+			a.logger.Debugf("Section %s is not supported: %v", section, err)
+		default:
+			lastErr = err
+			a.logger.Warnf("Warning: failed to read %s: %v", section, err)
+		}
 	}
 
-	// 3. Получение данных по шпинделям
-	spindleData, err := a.ReadSpindleData()
-	if err != nil {
-		return nil, fmt.Errorf("failed to read spindle data: %w", err)
+	if succeeded == 0 && lastErr != nil {
+		return data, fmt.Errorf("failed to read machine data: %w", lastErr)
 	}
+	return data, nil
+}
 
-	// 4. Получение информации о программе
-	programInfo, err := a.ReadProgram()
-	if err != nil {
-		return nil, fmt.Errorf("failed to read program info: %w", err)
+// readSection читает один раздел и заполняет соответствующие поля data.
+func (a *FocasAdapter) readSection(ctx context.Context, data *models.AggregatedData, section models.Section) error {
+	switch section {
+	case models.SectionState:
+		machineState, err := a.readState(ctx)
+		if err != nil {
+			return err
+		}
+		data.IsEmergency = machineState.EmergencyStatus != "Not Emergency"
+		data.MachineState = machineState.MachineState
+		data.ProgramMode = machineState.ProgramMode
+		data.TmMode = machineState.TmMode
+		data.AxisMovementStatus = machineState.AxisMovementStatus
+		data.MstbStatus = machineState.MstbStatus
+		data.EmergencyStatus = machineState.EmergencyStatus
+		data.AlarmStatus = machineState.AlarmStatus
+		data.EditStatus = machineState.EditStatus
+
+	case models.SectionAlarms:
+		alarms, err := a.ReadAlarms(ctx)
+		if err != nil {
+			return err
+		}
+		data.HasAlarms = len(alarms) > 0
+		data.Alarms = alarms
+
+	case models.SectionAxes:
+		axisData, err := a.ReadAxisData(ctx)
+		if err != nil {
+			return err
+		}
+		data.AxisInfos = axisData
+
+	case models.SectionSpindles:
+		spindleData, err := a.ReadSpindleData(ctx)
+		if err != nil {
+			return err
+		}
+		data.SpindleInfos = spindleData
+
+	case models.SectionProgram:
+		programInfo, err := a.ReadProgram(ctx)
+		if err != nil {
+			return err
+		}
+		data.CurrentProgram = models.CurrentProgramInfo{
+			ProgramName:   programInfo.Name,
+			ProgramNumber: programInfo.Number,
+			GCodeLine:     programInfo.CurrentGCode,
+		}
+
+	case models.SectionFeed:
+		feedInfo, err := a.ReadFeedData(ctx)
+		if err != nil {
+			return err
+		}
+		data.ActualFeedRate = feedInfo.ActualFeedRate
+		data.FeedOverride = feedInfo.FeedOverride
+
+	case models.SectionContourFeed:
+		contourFeedRate, err := a.ReadContourFeedRate(ctx)
+		if err != nil {
+			return err
+		}
+		data.ContourFeedRate = contourFeedRate
+
+	case models.SectionJogOverride:
+		jogOverride, err := a.ReadJogOverride(ctx)
+		if err != nil {
+			return err
+		}
+		data.JogOverride = jogOverride
+
+	case models.SectionParameters:
+		paramInfo, err := a.ReadParameterInfo(ctx)
+		if err != nil {
+			return err
+		}
+		data.PartsCount = paramInfo.PartsCount
+		data.PowerOnTime = paramInfo.PowerOnTime
+		data.OperatingTime = paramInfo.OperatingTime
+		data.CycleTime = paramInfo.CycleTime
+		data.CuttingTime = paramInfo.CuttingTime
+
+	case models.SectionPaths:
+		// Данные по каналам собираются только для многоканального станка
+		sysInfo := a.GetSystemInfo()
+		if sysInfo == nil || sysInfo.Paths <= 1 {
+			return nil
+		}
+		for path := int16(1); path <= sysInfo.Paths; path++ {
+			pathData, err := a.aggregatePathData(WithPath(ctx, path), path)
+			if err != nil {
+				return fmt.Errorf("path %d: %w", path, err)
+			}
+			data.Paths = append(data.Paths, *pathData)
+		}
+
+	default:
+		return fmt.Errorf("unknown section %q", section)
 	}
+	return nil
+}
 
-	// 5. Получение данных о подаче
-	feedInfo, err := a.ReadFeedData()
-	if err != nil {
-		return nil, fmt.Errorf("failed to read feed data: %w", err)
+// isConnectionError сообщает, что связи со станком нет и читать остальные разделы бессмысленно.
+func isConnectionError(ctx context.Context, err error) bool {
+	return ctx.Err() != nil ||
+		errors.Is(err, ErrNotConnected) ||
+		errors.Is(err, ErrCircuitOpen) ||
+		errors.Is(err, ErrRetriesExhausted)
+}
+
+// QualityOf возвращает качество значения, прочитанного с ошибкой err:
+// Good без ошибки, NotSupported для EW_FUNC и EW_NOOPT и CommError для остальных
+// ошибок (нет связи или вызов FOCAS завершился ошибкой). Подробности ошибки
+// сохраняются в SectionStatus.Error.
+func QualityOf(err error) models.Quality {
+	switch {
+	case err == nil:
+		return models.QualityGood
+	case isUnsupported(err):
+		return models.QualityNotSupported
+	default:
+		return models.QualityCommError
 	}
+}
 
-	// 6. Получение данных о контурной подаче
-	contourFeedRate, err := a.ReadContourFeedRate()
-	if err != nil {
-		return nil, fmt.Errorf("failed to read contour feed rate: %w", err)
+// isUnsupported сообщает, что станок не поддерживает функцию или опцию.
+func isUnsupported(err error) bool {
+	return errors.Is(err, ErrFunction) || errors.Is(err, ErrNoOption)
+}
+
+// sectionStatus преобразует ошибку чтения раздела в его статус.
+func sectionStatus(err error) models.SectionStatus {
+	switch {
+	case err == nil:
+		return models.SectionStatus{Status: models.StatusOK, Quality: models.QualityGood, SourceTime: time.Now().UTC()}
+	case isUnsupported(err):
+		return models.SectionStatus{Status: models.StatusUnsupported, Quality: models.QualityNotSupported, Error: err.Error(), Err: err}
+	default:
+		return models.SectionStatus{Status: models.StatusError, Quality: models.QualityCommError, Error: err.Error(), Err: err}
 	}
+}
 
-	// 7. Получение данных о коррекции JOG
-	jogOverride, err := a.ReadJogOverride()
+// aggregatePathData собирает состояние, оси, шпиндели и программу одного канала.
+func (a *FocasAdapter) aggregatePathData(ctx context.Context, path int16) (*models.PathData, error) {
+	machineState, err := a.ReadMachineState(ctx)
 	if err != nil {
-		a.logger.Warnf("Warning: failed to read jog override: %v", err)
-		jogOverride = 0 // Устанавливаем значение по умолчанию в случае ошибки
+		return nil, fmt.Errorf("failed to read machine state: %w", err)
 	}
 
-	// 8. Получение параметров (счетчики, время)
-	paramInfo, err := a.ReadParameterInfo()
+	axisData, err := a.ReadAxisData(ctx)
 	if err != nil {
-		a.logger.Warnf("Warning: one or more parameters could not be read: %v", err)
-		paramInfo = &models.ParameterInfo{}
+		return nil, fmt.Errorf("failed to read axis data: %w", err)
 	}
 
-	// Сборка финальной структуры
-	isEmergency := machineState.EmergencyStatus != "Not Emergency"
-	hasAlarms := len(machineState.Alarms) > 0
+	spindleData, err := a.ReadSpindleData(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spindle data: %w", err)
+	}
 
-	currentProg := models.CurrentProgramInfo{}
-	if programInfo != nil {
-		currentProg.ProgramName = programInfo.Name
-		currentProg.ProgramNumber = programInfo.Number
-		currentProg.GCodeLine = programInfo.CurrentGCode
+	programInfo, err := a.ReadProgram(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read program info: %w", err)
 	}
 
-	data := &models.AggregatedData{
-		MachineID:          fmt.Sprintf("%s:%d", a.ip, a.port),
-		Timestamp:          time.Now().UTC(),
-		IsEnabled:          true,
-		IsEmergency:        isEmergency,
+	return &models.PathData{
+		Path:               path,
 		MachineState:       machineState.MachineState,
 		ProgramMode:        machineState.ProgramMode,
 		TmMode:             machineState.TmMode,
@@ -83,21 +240,14 @@ func (a *FocasAdapter) AggregateAllData() (*models.AggregatedData, error) {
 		EmergencyStatus:    machineState.EmergencyStatus,
 		AlarmStatus:        machineState.AlarmStatus,
 		EditStatus:         machineState.EditStatus,
-		HasAlarms:          hasAlarms,
+		HasAlarms:          len(machineState.Alarms) > 0,
 		Alarms:             machineState.Alarms,
 		AxisInfos:          axisData,
 		SpindleInfos:       spindleData,
-		CurrentProgram:     currentProg,
-		ContourFeedRate:    contourFeedRate,
-		ActualFeedRate:     feedInfo.ActualFeedRate,
-		FeedOverride:       feedInfo.FeedOverride,
-		JogOverride:        jogOverride,
-		PartsCount:         paramInfo.PartsCount,
-		PowerOnTime:        paramInfo.PowerOnTime,
-		OperatingTime:      paramInfo.OperatingTime,
-		CycleTime:          paramInfo.CycleTime,
-		CuttingTime:        paramInfo.CuttingTime,
-	}
-
-	return data, nil
+		CurrentProgram: models.CurrentProgramInfo{
+			ProgramName:   programInfo.Name,
+			ProgramNumber: programInfo.Number,
+			GCodeLine:     programInfo.CurrentGCode,
+		},
+	}, nil
 }
@@ -1,20 +1,10 @@
 package focas
 
-/*
-#cgo CFLAGS: -I${SRCDIR}
-#cgo LDFLAGS: -L${SRCDIR} -lfwlib32
-#cgo linux LDFLAGS: -Wl,-rpath,${SRCDIR}
-
-#include "c_helpers.h"
-*/
-import "C"
-
 import (
+	"context"
 	"encoding/binary"
-	"fmt"
 	"strconv"
 	"strings"
-	"unsafe"
 
 	. "github.com/iwtcode/fanucAdapter/focas/errcode"
 	"github.com/iwtcode/fanucAdapter/focas/interpreter"
@@ -22,30 +12,29 @@ import (
 )
 
 // ReadAlarms считывает все активные сообщения об ошибках со станка.
-func (a *FocasAdapter) ReadAlarms() ([]models.AlarmDetail, error) {
+func (a *FocasAdapter) ReadAlarms(ctx context.Context) ([]models.AlarmDetail, error) {
 	const maxAlarms = 10
 	// Размер структуры ODBALMMSG2_data: alm_no(4) + type(2) + axis(2) + dummy(2) + msg_len(2) + alm_msg(64) = 76 байт
 	const alarmDataSize = 76
 	bufferSize := maxAlarms * alarmDataSize
 	buffer := make([]byte, bufferSize)
-	numAlarms := C.short(maxAlarms)
-	var rc C.short
+	numAlarms := int16(maxAlarms)
 
 	a.logger.Debugf("[ReadAlarms] Попытка чтения до %d ошибок (структура ODBALMMSG2)...", maxAlarms)
 
-	err := a.CallWithReconnect(func(handle uint16) (int16, error) {
-		a.logger.Debugf("[ReadAlarms] Вызов C.go_cnc_rdalmmsg с хендлом %d", handle)
-		rc = C.go_cnc_rdalmmsg(
-			C.ushort(handle),
+	err := a.CallWithReconnect(ctx, func(handle uint16) (int16, error) {
+		a.logger.Debugf("[ReadAlarms] Вызов cnc_rdalmmsg с хендлом %d", handle)
+		rc := a.backend.RdAlmMsg(
+			handle,
 			-1, // Читать все типы ошибок
 			&numAlarms,
-			(*C.ODBALMMSG)(unsafe.Pointer(&buffer[0])),
+			buffer,
 		)
-		a.logger.Debugf("[ReadAlarms] C.go_cnc_rdalmmsg вернул: rc=%d, numAlarms=%d", rc, numAlarms)
-		if int16(rc) != EW_OK {
-			return int16(rc), fmt.Errorf("cnc_rdalmmsg failed: rc=%d", int16(rc))
+		a.logger.Debugf("[ReadAlarms] cnc_rdalmmsg вернул: rc=%d, numAlarms=%d", rc, numAlarms)
+		if rc != EW_OK {
+			return rc, a.callError("cnc_rdalmmsg", handle, rc)
 		}
-		return int16(rc), nil
+		return rc, nil
 	})
 
 	if err != nil {
@@ -0,0 +1,66 @@
+package focas
+
+import (
+	"context"
+	"errors"
+
+	apperrors "github.com/iwtcode/fanucAdapter/errors"
+)
+
+// ToAppError преобразует ошибку адаптера в AppError с подходящим HTTP-статусом:
+//   - станок недоступен (нет соединения, EW_SOCKET, EW_HANDLE, разомкнут предохранитель, EW_BUSY) — 503;
+//   - истек таймаут запроса — 504;
+//   - запрос отменен клиентом — 499;
+//   - функция или опция не поддерживается станком (EW_FUNC, EW_NOOPT) — 501;
+//   - нет данных с таким номером (EW_NUMBER, EW_DATA) — 404;
+//   - некорректный запрос (EW_LENGTH, EW_ATTRIB, EW_PARAM, EW_PATH) — 400;
+//   - защита от записи или пароль (EW_PROT, EW_PASSWD) — 403;
+//   - станок не в том режиме (EW_MODE, EW_REJECT, EW_ALARM, EW_STOP) — 409;
+//   - прочие ошибки FOCAS — 502, остальные ошибки — 500.
+//
+// Если err уже является AppError, он возвращается без изменений.
+func ToAppError(err error) *apperrors.AppError {
+	if err == nil {
+		return nil
+	}
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	switch {
+	case errors.Is(err, ErrNotConnected),
+		errors.Is(err, ErrCircuitOpen),
+		errors.Is(err, ErrRetriesExhausted),
+		errors.Is(err, ErrSocket),
+		errors.Is(err, ErrHandle),
+		errors.Is(err, ErrBusy):
+		return apperrors.NewAppError(apperrors.ServiceUnavailableCode, apperrors.CNCUnavailable, err, false)
+	case errors.Is(err, context.DeadlineExceeded):
+		return apperrors.NewAppError(apperrors.GatewayTimeoutCode, apperrors.CNCTimeout, err, false)
+	case errors.Is(err, context.Canceled):
+		return apperrors.NewAppError(apperrors.ClientClosedRequestCode, apperrors.ClientClosedRequest, err, false)
+	case errors.Is(err, ErrFunction), errors.Is(err, ErrNoOption):
+		return apperrors.NewAppError(apperrors.NotImplementedCode, apperrors.NotImplemented, err, true)
+	case errors.Is(err, ErrNumber), errors.Is(err, ErrData):
+		return apperrors.NewAppError(apperrors.NotFoundErrorCode, apperrors.NotFound, err, true)
+	case errors.Is(err, ErrLength),
+		errors.Is(err, ErrAttribute),
+		errors.Is(err, ErrParam),
+		errors.Is(err, ErrPath):
+		return apperrors.NewAppError(apperrors.BadRequestCode, apperrors.BadRequest, err, true)
+	case errors.Is(err, ErrWriteProtect), errors.Is(err, ErrPassword):
+		return apperrors.NewAppError(apperrors.ForbiddenErrorCode, apperrors.Forbidden, err, true)
+	case errors.Is(err, ErrMode),
+		errors.Is(err, ErrReject),
+		errors.Is(err, ErrAlarm),
+		errors.Is(err, ErrStop):
+		return apperrors.NewAppError(apperrors.ConflictErrorCode, apperrors.Conflict, err, true)
+	}
+
+	var focasErr *FocasError
+	if errors.As(err, &focasErr) {
+		return apperrors.NewAppError(apperrors.BadGatewayCode, apperrors.CNCError, err, false)
+	}
+	return apperrors.NewAppError(apperrors.InternalServerErrorCode, apperrors.InternalServerError, err, false)
+}
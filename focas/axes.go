@@ -1,27 +1,21 @@
 package focas
 
-/*
-#cgo CFLAGS: -I${SRCDIR}
-#cgo LDFLAGS: -L${SRCDIR} -lfwlib32
-#cgo linux LDFLAGS: -Wl,-rpath,${SRCDIR}
-
-#include "c_helpers.h"
-*/
-import "C"
-
 import (
+	"context"
 	"encoding/binary"
-	"fmt"
+	"maps"
 	"math"
-	"unsafe"
 
 	. "github.com/iwtcode/fanucAdapter/focas/errcode"
 	"github.com/iwtcode/fanucAdapter/models"
 )
 
 // ReadAxisData считывает имена, абсолютные позиции и диагностику для всех управляемых осей
-func (a *FocasAdapter) ReadAxisData() ([]models.AxisInfo, error) {
-	sysInfo := a.sysInfo
+func (a *FocasAdapter) ReadAxisData(ctx context.Context) ([]models.AxisInfo, error) {
+	if err := a.checkConnected(); err != nil {
+		return nil, err
+	}
+	sysInfo := a.GetSystemInfo()
 	if sysInfo == nil || sysInfo.ControlledAxes <= 0 {
 		return []models.AxisInfo{}, nil
 	}
@@ -31,16 +25,15 @@ func (a *FocasAdapter) ReadAxisData() ([]models.AxisInfo, error) {
 	const odbposSize = 48
 	bufferSize := int(maxAxes) * odbposSize
 	buffer := make([]byte, bufferSize)
-	axesToRead := C.short(maxAxes)
-	var rc C.short
+	axesToRead := maxAxes
 
 	// 1. Читаем позиции (стандартный метод)
-	err := a.CallWithReconnect(func(handle uint16) (int16, error) {
-		rc = C.go_cnc_rdposition(C.ushort(handle), -1, &axesToRead, (*C.ODBPOS)(unsafe.Pointer(&buffer[0])))
-		if int16(rc) != EW_OK {
-			return int16(rc), fmt.Errorf("cnc_rdposition failed: rc=%d", int16(rc))
+	err := a.CallWithReconnect(ctx, func(handle uint16) (int16, error) {
+		rc := a.backend.RdPosition(handle, -1, &axesToRead, buffer)
+		if rc != EW_OK {
+			return rc, a.callError("cnc_rdposition", handle, rc)
 		}
-		return int16(rc), nil
+		return rc, nil
 	})
 
 	if err != nil {
@@ -57,32 +50,35 @@ func (a *FocasAdapter) ReadAxisData() ([]models.AxisInfo, error) {
 	// 2. Массовое чтение диагностики (OPTIMIZATION)
 	// Передаем maxAxes (32), чтобы FOCAS не вернул ошибку длины.
 
+	// Непрочитанные значения остаются нулевыми, а их качество записывается в quality
+	var quality models.FieldQuality
+
 	// Diag 301: Servo Load (Real)
-	diag301Vals, err := a.ReadDiagnosisRealAllAxes(301, maxAxes)
+	diag301Vals, err := a.ReadDiagnosisRealAllAxes(ctx, 301, maxAxes)
 	if err != nil {
 		a.logger.Warnf("Warning: Batch read diag 301 failed: %v", err)
-		diag301Vals = make([]float64, maxAxes)
+		quality = setFieldQuality(quality, "diag_301", err)
 	}
 
 	// Diag 308: Servo Temperature (Byte)
-	diag308Vals, err := a.ReadDiagnosisByteAllAxes(308, maxAxes)
+	diag308Vals, err := a.ReadDiagnosisByteAllAxes(ctx, 308, maxAxes)
 	if err != nil {
 		a.logger.Warnf("Warning: Batch read diag 308 failed: %v", err)
-		diag308Vals = make([]int32, maxAxes)
+		quality = setFieldQuality(quality, "servo_temperature", err)
 	}
 
 	// Diag 309: Coder Temperature (Byte)
-	diag309Vals, err := a.ReadDiagnosisByteAllAxes(309, maxAxes)
+	diag309Vals, err := a.ReadDiagnosisByteAllAxes(ctx, 309, maxAxes)
 	if err != nil {
 		a.logger.Warnf("Warning: Batch read diag 309 failed: %v", err)
-		diag309Vals = make([]int32, maxAxes)
+		quality = setFieldQuality(quality, "coder_temperature", err)
 	}
 
 	// Diag 4901: Power Consumption (Double Word)
-	diag4901Vals, err := a.ReadDiagnosisDoubleWordAllAxes(4901, maxAxes)
+	diag4901Vals, err := a.ReadDiagnosisDoubleWordAllAxes(ctx, 4901, maxAxes)
 	if err != nil {
 		// Это нормально для старых станков
-		diag4901Vals = make([]int64, maxAxes)
+		quality = setFieldQuality(quality, "power_consumption", err)
 	}
 
 	axisInfos := make([]models.AxisInfo, 0, axesToRead)
@@ -133,8 +129,18 @@ func (a *FocasAdapter) ReadAxisData() ([]models.AxisInfo, error) {
 			ServoTemperature: d308,
 			CoderTemperature: d309,
 			PowerConsumption: int32(d4901),
+			Quality:          maps.Clone(quality),
 		})
 	}
 
 	return axisInfos, nil
 }
+
+// setFieldQuality записывает в quality качество поля field, прочитанного с ошибкой err.
+func setFieldQuality(quality models.FieldQuality, field string, err error) models.FieldQuality {
+	if quality == nil {
+		quality = make(models.FieldQuality)
+	}
+	quality[field] = QualityOf(err)
+	return quality
+}
@@ -0,0 +1,14 @@
+//go:build !cgo
+
+package focas
+
+import (
+	"github.com/iwtcode/fanucAdapter/focas/ethernet"
+	"github.com/iwtcode/fanucAdapter/focas/model"
+)
+
+// DefaultBackend возвращает сетевой бэкенд FOCAS/Ethernet:
+// сборка без cgo не содержит обертки над libfwlib32.
+func DefaultBackend() model.Backend {
+	return ethernet.New()
+}
@@ -1,3 +1,5 @@
+//go:build cgo
+
 package focas
 
 /*
@@ -69,6 +71,10 @@ short go_cnc_getpath(unsigned short h, short* path_no, short* maxpath_no) {
     return cnc_getpath(h, path_no, maxpath_no);
 }
 
+short go_cnc_setpath(unsigned short h, short path_no) {
+    return cnc_setpath(h, path_no);
+}
+
 short go_cnc_upstart4(unsigned short h, short type, const char* file_name) {
     return cnc_upstart4(h, type, (char*)file_name);
 }
@@ -125,5 +131,9 @@ short go_cnc_rdtofs(unsigned short h, short number, short type, short length, OD
     return cnc_rdtofs(h, number, type, length, tofs);
 }
 
+short go_cnc_getdtailerr(unsigned short h, ODBERR* err_out) {
+    return cnc_getdtailerr(h, err_out);
+}
+
 */
 import "C"
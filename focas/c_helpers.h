@@ -16,6 +16,7 @@ short go_cnc_machine(unsigned short h, short axis, short length, ODBAXIS* axis_o
 short go_cnc_rdposition(unsigned short h, short type, short* data_num, ODBPOS* position);
 short go_cnc_upstart(unsigned short h, short prog_num);
 short go_cnc_getpath(unsigned short h, short* path_no, short* maxpath_no);
+short go_cnc_setpath(unsigned short h, short path_no);
 short go_cnc_upstart4(unsigned short h, short type, const char* file_name);
 short go_cnc_upload(unsigned short h, ODBUP* data_out, unsigned short* len);
 short go_cnc_upend(unsigned short h);
@@ -30,5 +31,6 @@ short go_cnc_rdparam(unsigned short h, short prm_no, short axis_no, short length
 short go_cnc_rdparar(unsigned short h, short* s_number, short axis, short* e_number, short* length, IODBPSD* param_out);
 short go_cnc_actf(unsigned short h, ODBACT* actualfeed);
 short go_cnc_rdtofs(unsigned short h, short number, short type, short length, ODBTOFS* tofs);
+short go_cnc_getdtailerr(unsigned short h, ODBERR* err_out);
 
-#endif // C_HELPERS_H
\ No newline at end of file
+#endif // C_HELPERS_H
\ No newline at end of file
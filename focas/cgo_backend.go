@@ -0,0 +1,265 @@
+//go:build cgo
+
+package focas
+
+/*
+#cgo CFLAGS: -I${SRCDIR}
+#cgo LDFLAGS: -L${SRCDIR} -lfwlib32
+#cgo linux LDFLAGS: -Wl,-rpath,${SRCDIR}
+
+#include <stdlib.h>
+#include <string.h>
+#include "c_helpers.h"
+*/
+import "C"
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+	"unsafe"
+
+	. "github.com/iwtcode/fanucAdapter/focas/errcode"
+	"github.com/iwtcode/fanucAdapter/focas/model"
+)
+
+// CgoBackend реализует model.Backend поверх нативной библиотеки libfwlib32.
+type CgoBackend struct {
+	startupOnce sync.Once
+	startupRc   int16
+}
+
+// Убедимся, что CgoBackend удовлетворяет интерфейсам Backend и Prober.
+var (
+	_ model.Backend = (*CgoBackend)(nil)
+	_ model.Prober  = (*CgoBackend)(nil)
+)
+
+// cgoBackend — единственный экземпляр, так как библиотека инициализируется один раз на процесс.
+var cgoBackend = &CgoBackend{}
+
+// DefaultBackend возвращает бэкенд по умолчанию — обертку над libfwlib32.
+func DefaultBackend() model.Backend {
+	return cgoBackend
+}
+
+func (b *CgoBackend) Startup(mode uint16, logPath string) int16 {
+	b.startupOnce.Do(func() {
+		var cpath *C.char
+		if logPath != "" {
+			dir := filepath.Dir(logPath)
+			if dir != "" && dir != "." {
+				_ = os.MkdirAll(dir, 0o755)
+			}
+			cpath = C.CString(logPath)
+		} else {
+			cpath = C.CString("")
+		}
+		defer C.free(unsafe.Pointer(cpath))
+
+		b.startupRc = int16(C.go_cnc_startupprocess(C.ushort(mode), cpath))
+	})
+	return b.startupRc
+}
+
+// Probe проверяет, что станок отвечает по TCP (реализация model.Prober):
+// libfwlib32 может надолго зависнуть на недоступном адресе, а проверка
+// выполняется без блокировки вызовов и не задерживает другие станки.
+func (b *CgoBackend) Probe(ip string, port uint16) int16 {
+	target := net.JoinHostPort(ip, strconv.Itoa(int(port)))
+	conn, err := net.DialTimeout("tcp", target, 2*time.Second)
+	if err != nil {
+		return EW_SOCKET
+	}
+	conn.Close()
+	return EW_OK
+}
+
+func (b *CgoBackend) AllcLibHndl3(ip string, port uint16, timeoutMs int32) (uint16, int16) {
+	cip := C.CString(ip)
+	defer C.free(unsafe.Pointer(cip))
+
+	var h C.ushort
+	rc := C.go_cnc_allclibhndl3(cip, C.ushort(port), C.long(timeoutMs), &h)
+	return uint16(h), int16(rc)
+}
+
+func (b *CgoBackend) FreeLibHndl(handle uint16) int16 {
+	return int16(C.go_cnc_freelibhndl(C.ushort(handle)))
+}
+
+func (b *CgoBackend) SysInfo(handle uint16) (model.SysInfo, int16) {
+	var sysInfo C.ODBSYS
+	rc := C.go_cnc_sysinfo(C.ushort(handle), &sysInfo)
+	if int16(rc) != EW_OK {
+		return model.SysInfo{}, int16(rc)
+	}
+	return model.SysInfo{
+		AddInfo: int16(sysInfo.addinfo),
+		MaxAxis: int16(sysInfo.max_axis),
+		CncType: trimNull(C.GoStringN(&sysInfo.cnc_type[0], C.int(len(sysInfo.cnc_type)))),
+		MtType:  trimNull(C.GoStringN(&sysInfo.mt_type[0], C.int(len(sysInfo.mt_type)))),
+		Series:  trimNull(C.GoStringN(&sysInfo.series[0], C.int(len(sysInfo.series)))),
+		Version: trimNull(C.GoStringN(&sysInfo.version[0], C.int(len(sysInfo.version)))),
+		Axes:    trimNull(C.GoStringN(&sysInfo.axes[0], C.int(len(sysInfo.axes)))),
+	}, int16(rc)
+}
+
+func (b *CgoBackend) StatInfo(handle uint16) (model.StatInfo, int16) {
+	var stat C.ODBST
+	rc := C.go_cnc_statinfo(C.ushort(handle), &stat)
+	if int16(rc) != EW_OK {
+		return model.StatInfo{}, int16(rc)
+	}
+	return model.StatInfo{
+		Hdck:      int16(stat.hdck),
+		TmMode:    int16(stat.tmmode),
+		Aut:       int16(stat.aut),
+		Run:       int16(stat.run),
+		Motion:    int16(stat.motion),
+		Mstb:      int16(stat.mstb),
+		Emergency: int16(stat.emergency),
+		Alarm:     int16(stat.alarm),
+		Edit:      int16(stat.edit),
+	}, int16(rc)
+}
+
+func (b *CgoBackend) ExePrgName(handle uint16) (string, int64, int16) {
+	nameBuf := make([]byte, 64)
+	var onum C.long
+	rc := C.go_cnc_exeprgname(C.ushort(handle), (*C.char)(unsafe.Pointer(&nameBuf[0])), C.int(len(nameBuf)), &onum)
+	if int16(rc) != EW_OK {
+		return "", 0, int16(rc)
+	}
+	return trimNull(string(nameBuf)), int64(onum), int16(rc)
+}
+
+func (b *CgoBackend) RdExecProg(handle uint16, length *uint16, blknum *int16, data []byte) int16 {
+	cLength := C.ushort(*length)
+	var cBlknum C.short
+	rc := C.go_cnc_rdexecprog(C.ushort(handle), &cLength, &cBlknum, (*C.char)(unsafe.Pointer(&data[0])))
+	*length = uint16(cLength)
+	*blknum = int16(cBlknum)
+	return int16(rc)
+}
+
+func (b *CgoBackend) RdPosition(handle uint16, posType int16, dataNum *int16, buf []byte) int16 {
+	cNum := C.short(*dataNum)
+	rc := C.go_cnc_rdposition(C.ushort(handle), C.short(posType), &cNum, (*C.ODBPOS)(unsafe.Pointer(&buf[0])))
+	*dataNum = int16(cNum)
+	return int16(rc)
+}
+
+func (b *CgoBackend) Diagnoss(handle uint16, diagNo int16, axisNo int16, length int16, buf []byte) int16 {
+	return int16(C.go_cnc_diagnoss(
+		C.ushort(handle),
+		C.short(diagNo),
+		C.short(axisNo),
+		C.short(length),
+		(*C.ODBDGN)(unsafe.Pointer(&buf[0])),
+	))
+}
+
+func (b *CgoBackend) RdSpMeter(handle uint16, spType int16, num *int16, buf []byte) int16 {
+	cNum := C.short(*num)
+	rc := C.go_cnc_rdspmeter(C.ushort(handle), C.short(spType), &cNum, (*C.ODBSPLOAD)(unsafe.Pointer(&buf[0])))
+	*num = int16(cNum)
+	return int16(rc)
+}
+
+func (b *CgoBackend) RdSpLoad(handle uint16, spNo int16, buf []byte) int16 {
+	return int16(C.go_cnc_rdspload(C.ushort(handle), C.short(spNo), (*C.ODBSPN)(unsafe.Pointer(&buf[0]))))
+}
+
+func (b *CgoBackend) RdSpeed(handle uint16, spType int16, buf []byte) int16 {
+	return int16(C.go_cnc_rdspeed(C.ushort(handle), C.short(spType), (*C.ODBSPEED)(unsafe.Pointer(&buf[0]))))
+}
+
+func (b *CgoBackend) Actf(handle uint16, buf []byte) int16 {
+	return int16(C.go_cnc_actf(C.ushort(handle), (*C.ODBACT)(unsafe.Pointer(&buf[0]))))
+}
+
+func (b *CgoBackend) RdTofs(handle uint16, number int16, ofsType int16, length int16, buf []byte) int16 {
+	return int16(C.go_cnc_rdtofs(
+		C.ushort(handle),
+		C.short(number),
+		C.short(ofsType),
+		C.short(length),
+		(*C.ODBTOFS)(unsafe.Pointer(&buf[0])),
+	))
+}
+
+func (b *CgoBackend) RdAlmMsg(handle uint16, almType int16, num *int16, buf []byte) int16 {
+	cNum := C.short(*num)
+	rc := C.go_cnc_rdalmmsg(C.ushort(handle), C.short(almType), &cNum, (*C.ODBALMMSG)(unsafe.Pointer(&buf[0])))
+	*num = int16(cNum)
+	return int16(rc)
+}
+
+func (b *CgoBackend) RdParam(handle uint16, prmNo int16, axisNo int16, length int16, buf []byte) int16 {
+	return int16(C.go_cnc_rdparam(
+		C.ushort(handle),
+		C.short(prmNo),
+		C.short(axisNo),
+		C.short(length),
+		(*C.IODBPSD)(unsafe.Pointer(&buf[0])),
+	))
+}
+
+func (b *CgoBackend) RdParar(handle uint16, start *int16, axisNo int16, end *int16, length *int16, buf []byte) int16 {
+	cStart := C.short(*start)
+	cEnd := C.short(*end)
+	cLength := C.short(*length)
+	rc := C.go_cnc_rdparar(
+		C.ushort(handle),
+		&cStart,
+		C.short(axisNo),
+		&cEnd,
+		&cLength,
+		(*C.IODBPSD)(unsafe.Pointer(&buf[0])),
+	)
+	*start = int16(cStart)
+	*end = int16(cEnd)
+	*length = int16(cLength)
+	return int16(rc)
+}
+
+func (b *CgoBackend) GetPath(handle uint16) (int16, int16, int16) {
+	var pathNo, maxPathNo C.short
+	rc := C.go_cnc_getpath(C.ushort(handle), &pathNo, &maxPathNo)
+	return int16(pathNo), int16(maxPathNo), int16(rc)
+}
+
+func (b *CgoBackend) SetPath(handle uint16, pathNo int16) int16 {
+	return int16(C.go_cnc_setpath(C.ushort(handle), C.short(pathNo)))
+}
+
+func (b *CgoBackend) UpStart(handle uint16, progNum int16) int16 {
+	return int16(C.go_cnc_upstart(C.ushort(handle), C.short(progNum)))
+}
+
+func (b *CgoBackend) UpStart4(handle uint16, upType int16, fileName string) int16 {
+	cFileName := C.CString(fileName)
+	defer C.free(unsafe.Pointer(cFileName))
+	return int16(C.go_cnc_upstart4(C.ushort(handle), C.short(upType), cFileName))
+}
+
+func (b *CgoBackend) Upload(handle uint16, buf []byte, length *uint16) int16 {
+	cLength := C.ushort(*length)
+	rc := C.go_cnc_upload(C.ushort(handle), (*C.ODBUP)(unsafe.Pointer(&buf[0])), &cLength)
+	*length = uint16(cLength)
+	return int16(rc)
+}
+
+func (b *CgoBackend) UpEnd(handle uint16) int16 {
+	return int16(C.go_cnc_upend(C.ushort(handle)))
+}
+
+func (b *CgoBackend) GetDtailErr(handle uint16) (int16, int16, int16) {
+	var e C.ODBERR
+	rc := C.go_cnc_getdtailerr(C.ushort(handle), &e)
+	return int16(e.err_no), int16(e.err_dtno), int16(rc)
+}
@@ -0,0 +1,142 @@
+package focas
+
+import (
+	"sync"
+	"time"
+
+	"github.com/iwtcode/fanucAdapter/models"
+)
+
+// connTracker хранит состояние соединения адаптера и рассылает подписчикам
+// события о его смене.
+type connTracker struct {
+	machineID string
+
+	mu     sync.Mutex
+	status models.ConnectionStatus
+	nextID int
+	subs   map[int]func(models.ConnectionEvent)
+
+	// notifyMu сохраняет порядок доставки событий при конкурентных вызовах.
+	notifyMu sync.Mutex
+}
+
+func newConnTracker(machineID string, state models.ConnectionState) *connTracker {
+	return &connTracker{
+		machineID: machineID,
+		status: models.ConnectionStatus{
+			State: state,
+			Since: time.Now(),
+		},
+		subs: make(map[int]func(models.ConnectionEvent)),
+	}
+}
+
+// Status возвращает снимок текущего состояния соединения.
+func (t *connTracker) Status() models.ConnectionStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.status
+}
+
+// subscribe регистрирует обработчик событий и возвращает функцию отписки.
+func (t *connTracker) subscribe(fn func(models.ConnectionEvent)) func() {
+	t.mu.Lock()
+	id := t.nextID
+	t.nextID++
+	t.subs[id] = fn
+	t.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.mu.Lock()
+			delete(t.subs, id)
+			t.mu.Unlock()
+		})
+	}
+}
+
+// succeeded отмечает успешный вызов FOCAS.
+func (t *connTracker) succeeded() {
+	t.update(func(s *models.ConnectionStatus) models.ConnectionState {
+		s.LastSuccess = time.Now()
+		return models.ConnectionConnected
+	})
+}
+
+// failed отмечает ошибку вызова. Если connLost, соединение считается потерянным
+// и начинается переподключение; иначе станок ответил и соединение в порядке.
+func (t *connTracker) failed(err error, connLost bool) {
+	t.update(func(s *models.ConnectionStatus) models.ConnectionState {
+		s.LastError = err.Error()
+		s.LastErrorTime = time.Now()
+		if connLost {
+			return models.ConnectionReconnecting
+		}
+		return models.ConnectionConnected
+	})
+}
+
+// reconnected отмечает успешное переподключение.
+func (t *connTracker) reconnected() {
+	t.update(func(s *models.ConnectionStatus) models.ConnectionState {
+		s.ReconnectCount++
+		return models.ConnectionConnected
+	})
+}
+
+// disconnected отмечает, что восстановить соединение не удалось.
+func (t *connTracker) disconnected(err error) {
+	t.update(func(s *models.ConnectionStatus) models.ConnectionState {
+		s.LastError = err.Error()
+		s.LastErrorTime = time.Now()
+		return models.ConnectionDisconnected
+	})
+}
+
+// closed переводит соединение в конечное состояние Closed.
+func (t *connTracker) closed() {
+	t.update(func(s *models.ConnectionStatus) models.ConnectionState {
+		return models.ConnectionClosed
+	})
+}
+
+// update изменяет статус и, если состояние сменилось, уведомляет подписчиков.
+// Из состояния Closed выйти нельзя.
+func (t *connTracker) update(f func(s *models.ConnectionStatus) models.ConnectionState) {
+	t.notifyMu.Lock()
+	defer t.notifyMu.Unlock()
+
+	t.mu.Lock()
+	if t.status.State == models.ConnectionClosed {
+		t.mu.Unlock()
+		return
+	}
+	from := t.status.State
+	to := f(&t.status)
+	if from == to {
+		t.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	t.status.State = to
+	t.status.Since = now
+	event := models.ConnectionEvent{
+		MachineID: t.machineID,
+		From:      from,
+		To:        to,
+		Status:    t.status,
+		Timestamp: now,
+	}
+	subs := make([]func(models.ConnectionEvent), 0, len(t.subs))
+	for _, fn := range t.subs {
+		subs = append(subs, fn)
+	}
+	t.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(event)
+	}
+}
@@ -1,38 +1,28 @@
 package focas
 
-/*
-#cgo CFLAGS: -I${SRCDIR}
-#cgo LDFLAGS: -L${SRCDIR} -lfwlib32
-#cgo linux LDFLAGS: -Wl,-rpath,${SRCDIR}
-
-#include "c_helpers.h"
-*/
-import "C"
-
 import (
+	"context"
 	"encoding/binary"
 	"fmt"
-	"unsafe"
 
 	. "github.com/iwtcode/fanucAdapter/focas/errcode"
 )
 
 // ReadContourFeedRate считывает фактическую скорость подачи по контуру (F).
 // Эта функция вызывает cnc_actf.
-func (a *FocasAdapter) ReadContourFeedRate() (int32, error) {
+func (a *FocasAdapter) ReadContourFeedRate(ctx context.Context) (int32, error) {
 	a.logger.Debug("[ReadContourFeedRate] Начато чтение скорости подачи по контуру.")
 
 	// Размер структуры ODBACT = 2 * short (4 байта) + 1 * long (4 байта) = 8 байт
 	const dataSize = 8
 	buffer := make([]byte, dataSize)
-	var rc C.short
 
-	err := a.CallWithReconnect(func(handle uint16) (int16, error) {
-		rc = C.go_cnc_actf(C.ushort(handle), (*C.ODBACT)(unsafe.Pointer(&buffer[0])))
-		if int16(rc) != EW_OK {
-			return int16(rc), fmt.Errorf("cnc_actf failed: rc=%d", int16(rc))
+	err := a.CallWithReconnect(ctx, func(handle uint16) (int16, error) {
+		rc := a.backend.Actf(handle, buffer)
+		if rc != EW_OK {
+			return rc, a.callError("cnc_actf", handle, rc)
 		}
-		return int16(rc), nil
+		return rc, nil
 	})
 
 	if err != nil {
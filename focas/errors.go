@@ -0,0 +1,47 @@
+package focas
+
+import "github.com/iwtcode/fanucAdapter/focas/model"
+
+// FocasError описывает неудачный вызов функции FOCAS: имя функции, код возврата,
+// хендл и подробности от cnc_getdtailerr. Проверять код удобно через errors.Is
+// с сигнальными ошибками ниже, а извлекать подробности — через errors.As.
+type FocasError = model.FocasError
+
+// ErrorDetail — подробности ошибки от cnc_getdtailerr.
+type ErrorDetail = model.ErrorDetail
+
+// Сигнальные ошибки для кодов возврата FOCAS, например errors.Is(err, focas.ErrNoOption).
+var (
+	ErrProtocol     = model.ErrProtocol
+	ErrSocket       = model.ErrSocket
+	ErrNoDLL        = model.ErrNoDLL
+	ErrHandle       = model.ErrHandle
+	ErrVersion      = model.ErrVersion
+	ErrUnexpected   = model.ErrUnexpected
+	ErrSystem       = model.ErrSystem
+	ErrReset        = model.ErrReset
+	ErrBusy         = model.ErrBusy
+	ErrFunction     = model.ErrFunction
+	ErrLength       = model.ErrLength
+	ErrNumber       = model.ErrNumber
+	ErrAttribute    = model.ErrAttribute
+	ErrData         = model.ErrData
+	ErrNoOption     = model.ErrNoOption
+	ErrWriteProtect = model.ErrWriteProtect
+	ErrOverflow     = model.ErrOverflow
+	ErrParam        = model.ErrParam
+	ErrBuffer       = model.ErrBuffer
+	ErrPath         = model.ErrPath
+	ErrMode         = model.ErrMode
+	ErrReject       = model.ErrReject
+	ErrDataServer   = model.ErrDataServer
+	ErrAlarm        = model.ErrAlarm
+	ErrStop         = model.ErrStop
+	ErrPassword     = model.ErrPassword
+)
+
+// callError создает FocasError для вызова fn с кодом rc.
+// Вызывается внутри CallWithReconnect, то есть под libLock.
+func (a *FocasAdapter) callError(fn string, handle uint16, rc int16) error {
+	return model.NewError(a.backend, fn, handle, rc)
+}
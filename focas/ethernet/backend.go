@@ -0,0 +1,386 @@
+package ethernet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	. "github.com/iwtcode/fanucAdapter/focas/errcode"
+	"github.com/iwtcode/fanucAdapter/focas/model"
+)
+
+// DefaultDialTimeout используется, если в AllcLibHndl3 передан нулевой таймаут.
+const DefaultDialTimeout = 5 * time.Second
+
+// ProbeTimeout ограничивает проверку доступности станка в Probe.
+const ProbeTimeout = 2 * time.Second
+
+// session — открытое соединение с ЧПУ, соответствующее одному хендлу.
+type session struct {
+	conn    net.Conn
+	timeout time.Duration
+}
+
+// Backend реализует model.Backend поверх протокола FOCAS/Ethernet.
+// Каждый хендл соответствует отдельному TCP-соединению. Общая для процесса
+// блокировка libfwlib32 бэкенду не нужна: вызовы разных экземпляров Backend
+// (обычно по одному на станок) выполняются параллельно (см. CallLock).
+type Backend struct {
+	mu       sync.Mutex
+	callMu   sync.Mutex // Блокировка вызовов (model.CallLocker)
+	next     uint16
+	sessions map[uint16]*session
+
+	// Dial позволяет подменить установку соединения (например, в тестах).
+	// По умолчанию используется net.DialTimeout.
+	Dial func(network, address string, timeout time.Duration) (net.Conn, error)
+}
+
+// Убедимся, что Backend удовлетворяет интерфейсам Backend, CallLocker и Prober.
+var (
+	_ model.Backend    = (*Backend)(nil)
+	_ model.CallLocker = (*Backend)(nil)
+	_ model.Prober     = (*Backend)(nil)
+)
+
+// New создает новый сетевой бэкенд.
+func New() *Backend {
+	return &Backend{
+		sessions: make(map[uint16]*session),
+		Dial:     net.DialTimeout,
+	}
+}
+
+// Startup ничего не делает: сетевому бэкенду не нужна инициализация процесса.
+func (b *Backend) Startup(mode uint16, logPath string) int16 {
+	return EW_OK
+}
+
+// CallLock возвращает блокировку вызовов этого бэкенда (реализация model.CallLocker).
+// Она сериализует вызовы одного станка (например, смену канала и следующий вызов),
+// но не задерживает станки с другими экземплярами Backend.
+func (b *Backend) CallLock() sync.Locker {
+	return &b.callMu
+}
+
+// Probe проверяет, что станок принимает TCP-соединения (реализация model.Prober).
+// Проверка выполняется без блокировки вызовов, поэтому недоступный станок
+// не задерживает вызовы, ожидающие той же блокировки.
+func (b *Backend) Probe(ip string, port uint16) int16 {
+	conn, err := b.Dial("tcp", net.JoinHostPort(ip, strconv.Itoa(int(port))), ProbeTimeout)
+	if err != nil {
+		return EW_SOCKET
+	}
+	conn.Close()
+	return EW_OK
+}
+
+func (b *Backend) AllcLibHndl3(ip string, port uint16, timeoutMs int32) (uint16, int16) {
+	timeout := time.Duration(timeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = DefaultDialTimeout
+	}
+
+	conn, err := b.Dial("tcp", net.JoinHostPort(ip, strconv.Itoa(int(port))), timeout)
+	if err != nil {
+		return 0, EW_SOCKET
+	}
+
+	s := &session{conn: conn, timeout: timeout}
+	frame, err := s.roundTrip(Frame{Version: Version, Type: FrameOpenRequest, Body: []byte{0, 1}})
+	if err != nil || frame.Type != FrameOpenResponse {
+		conn.Close()
+		return 0, EW_SOCKET
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for {
+		b.next++
+		if _, used := b.sessions[b.next]; b.next != 0 && !used {
+			break
+		}
+	}
+	b.sessions[b.next] = s
+	return b.next, EW_OK
+}
+
+func (b *Backend) FreeLibHndl(handle uint16) int16 {
+	b.mu.Lock()
+	s, ok := b.sessions[handle]
+	delete(b.sessions, handle)
+	b.mu.Unlock()
+
+	if !ok {
+		return EW_HANDLE
+	}
+	_, _ = s.roundTrip(Frame{Version: Version, Type: FrameCloseRequest})
+	s.conn.Close()
+	return EW_OK
+}
+
+// call выполняет один вызов FOCAS и возвращает данные ответа.
+// При сетевой ошибке соединение закрывается, а хендл становится недействительным.
+func (b *Backend) call(handle uint16, cmd Command, args [5]int32, payload []byte) ([]byte, int16) {
+	b.mu.Lock()
+	s, ok := b.sessions[handle]
+	b.mu.Unlock()
+	if !ok {
+		return nil, EW_HANDLE
+	}
+
+	body := EncodeRequests(RequestBlock{Command: cmd, Args: args, Payload: payload})
+	frame, err := s.roundTrip(Frame{Version: Version, Type: FrameRequest, Body: body})
+	if err != nil {
+		b.drop(handle, s)
+		return nil, EW_SOCKET
+	}
+	if frame.Type != FrameResponse {
+		return nil, EW_PROTOCOL
+	}
+
+	blocks, err := DecodeResponses(frame.Body)
+	if err != nil || len(blocks) != 1 || blocks[0].Command != cmd {
+		return nil, EW_PROTOCOL
+	}
+	return blocks[0].Data, blocks[0].RC
+}
+
+func (b *Backend) drop(handle uint16, s *session) {
+	b.mu.Lock()
+	if b.sessions[handle] == s {
+		delete(b.sessions, handle)
+	}
+	b.mu.Unlock()
+	s.conn.Close()
+}
+
+func (s *session) roundTrip(req Frame) (Frame, error) {
+	if err := s.conn.SetDeadline(time.Now().Add(s.timeout)); err != nil {
+		return Frame{}, err
+	}
+	if err := WriteFrame(s.conn, req); err != nil {
+		return Frame{}, err
+	}
+	return ReadFrame(s.conn)
+}
+
+func (b *Backend) SysInfo(handle uint16) (model.SysInfo, int16) {
+	data, rc := b.call(handle, CmdSysInfo, [5]int32{}, nil)
+	if rc != EW_OK {
+		return model.SysInfo{}, rc
+	}
+	info, err := DecodeSysInfo(data)
+	if err != nil {
+		return model.SysInfo{}, EW_PROTOCOL
+	}
+	return info, EW_OK
+}
+
+func (b *Backend) StatInfo(handle uint16) (model.StatInfo, int16) {
+	data, rc := b.call(handle, CmdStatInfo, [5]int32{}, nil)
+	if rc != EW_OK {
+		return model.StatInfo{}, rc
+	}
+	stat, err := DecodeStatInfo(data)
+	if err != nil {
+		return model.StatInfo{}, EW_PROTOCOL
+	}
+	return stat, EW_OK
+}
+
+func (b *Backend) ExePrgName(handle uint16) (string, int64, int16) {
+	data, rc := b.call(handle, CmdExePrgName, [5]int32{}, nil)
+	if rc != EW_OK {
+		return "", 0, rc
+	}
+	if len(data) < exeprgName+4 {
+		return "", 0, EW_PROTOCOL
+	}
+	name := trimNull(string(data[:exeprgName]))
+	onum := int32(binary.BigEndian.Uint32(data[exeprgName:]))
+	return name, int64(onum), EW_OK
+}
+
+func (b *Backend) RdExecProg(handle uint16, length *uint16, blknum *int16, data []byte) int16 {
+	resp, rc := b.call(handle, CmdRdExecProg, [5]int32{int32(*length)}, nil)
+	if rc != EW_OK {
+		return rc
+	}
+	if len(resp) < 2 {
+		return EW_PROTOCOL
+	}
+	*blknum = int16(binary.BigEndian.Uint16(resp[0:2]))
+	*length = uint16(copy(data[:min(int(*length), len(data))], resp[2:]))
+	return EW_OK
+}
+
+func (b *Backend) RdPosition(handle uint16, posType int16, dataNum *int16, buf []byte) int16 {
+	resp, rc := b.call(handle, CmdRdPosition, [5]int32{int32(posType), int32(*dataNum)}, nil)
+	if rc != EW_OK {
+		return rc
+	}
+	n := copyRecords(buf, resp, layoutOdbpos)
+	*dataNum = int16(n)
+	return EW_OK
+}
+
+func (b *Backend) Diagnoss(handle uint16, diagNo int16, axisNo int16, length int16, buf []byte) int16 {
+	resp, rc := b.call(handle, CmdDiagnoss, [5]int32{int32(diagNo), int32(axisNo), int32(length)}, nil)
+	if rc != EW_OK {
+		return rc
+	}
+	// Ответ: размер элемента (2) + ODBDGN в big-endian
+	if len(resp) < 2+4 {
+		return EW_PROTOCOL
+	}
+	elemSize := int(binary.BigEndian.Uint16(resp[0:2]))
+	n := copy(buf, resp[2:])
+	layoutDgnHeader.swap(buf[:4])
+	if elemSize > 0 {
+		diagLayout(elemSize).swap(buf[4:n])
+	}
+	return EW_OK
+}
+
+func (b *Backend) RdSpMeter(handle uint16, spType int16, num *int16, buf []byte) int16 {
+	resp, rc := b.call(handle, CmdRdSpMeter, [5]int32{int32(spType), int32(*num)}, nil)
+	if rc != EW_OK {
+		return rc
+	}
+	*num = int16(copyRecords(buf, resp, layoutOdbspload))
+	return EW_OK
+}
+
+func (b *Backend) RdSpLoad(handle uint16, spNo int16, buf []byte) int16 {
+	return b.callStruct(handle, CmdRdSpLoad, [5]int32{int32(spNo)}, buf, layoutOdbspn)
+}
+
+func (b *Backend) RdSpeed(handle uint16, spType int16, buf []byte) int16 {
+	return b.callStruct(handle, CmdRdSpeed, [5]int32{int32(spType)}, buf, layoutOdbspeed)
+}
+
+func (b *Backend) Actf(handle uint16, buf []byte) int16 {
+	return b.callStruct(handle, CmdActf, [5]int32{}, buf, layoutOdbact)
+}
+
+func (b *Backend) RdTofs(handle uint16, number int16, ofsType int16, length int16, buf []byte) int16 {
+	return b.callStruct(handle, CmdRdTofs, [5]int32{int32(number), int32(ofsType), int32(length)}, buf, layoutOdbtofs)
+}
+
+func (b *Backend) RdAlmMsg(handle uint16, almType int16, num *int16, buf []byte) int16 {
+	resp, rc := b.call(handle, CmdRdAlmMsg, [5]int32{int32(almType), int32(*num)}, nil)
+	if rc != EW_OK {
+		return rc
+	}
+	*num = int16(copyRecords(buf, resp, layoutAlmmsg))
+	return EW_OK
+}
+
+func (b *Backend) RdParam(handle uint16, prmNo int16, axisNo int16, length int16, buf []byte) int16 {
+	return b.callStruct(handle, CmdRdParam, [5]int32{int32(prmNo), int32(axisNo), int32(length)}, buf, layoutIodbpsd)
+}
+
+func (b *Backend) RdParar(handle uint16, start *int16, axisNo int16, end *int16, length *int16, buf []byte) int16 {
+	resp, rc := b.call(handle, CmdRdParar, [5]int32{int32(*start), int32(*end), int32(axisNo), int32(*length)}, nil)
+	if rc != EW_OK {
+		return rc
+	}
+	n := copyRecords(buf[:min(int(*length), len(buf))], resp, layoutIodbpsd)
+	if n > 0 {
+		*end = int16(binary.LittleEndian.Uint16(buf[(n-1)*iodbpsdSize:]))
+	}
+	*length = int16(n * iodbpsdSize)
+	return EW_OK
+}
+
+func (b *Backend) GetPath(handle uint16) (int16, int16, int16) {
+	resp, rc := b.call(handle, CmdGetPath, [5]int32{}, nil)
+	if rc != EW_OK {
+		return 0, 0, rc
+	}
+	if len(resp) < 4 {
+		return 0, 0, EW_PROTOCOL
+	}
+	return int16(binary.BigEndian.Uint16(resp[0:2])), int16(binary.BigEndian.Uint16(resp[2:4])), EW_OK
+}
+
+func (b *Backend) SetPath(handle uint16, pathNo int16) int16 {
+	_, rc := b.call(handle, CmdSetPath, [5]int32{int32(pathNo)}, nil)
+	return rc
+}
+
+func (b *Backend) GetDtailErr(handle uint16) (int16, int16, int16) {
+	resp, rc := b.call(handle, CmdGetDtErr, [5]int32{}, nil)
+	if rc != EW_OK {
+		return 0, 0, rc
+	}
+	if len(resp) < 4 {
+		return 0, 0, EW_PROTOCOL
+	}
+	return int16(binary.BigEndian.Uint16(resp[0:2])), int16(binary.BigEndian.Uint16(resp[2:4])), EW_OK
+}
+
+func (b *Backend) UpStart(handle uint16, progNum int16) int16 {
+	_, rc := b.call(handle, CmdUpStart, [5]int32{int32(progNum)}, nil)
+	return rc
+}
+
+func (b *Backend) UpStart4(handle uint16, upType int16, fileName string) int16 {
+	_, rc := b.call(handle, CmdUpStart4, [5]int32{int32(upType)}, []byte(fileName))
+	return rc
+}
+
+func (b *Backend) Upload(handle uint16, buf []byte, length *uint16) int16 {
+	resp, rc := b.call(handle, CmdUpload, [5]int32{int32(*length)}, nil)
+	if rc != EW_OK && rc != EW_BUFFER {
+		return rc
+	}
+	// Структура ODBUP: dummy[2](4) + data
+	const odbupHeaderSize = 4
+	*length = uint16(copy(buf[odbupHeaderSize:odbupHeaderSize+min(int(*length), len(buf)-odbupHeaderSize)], resp))
+	return rc
+}
+
+func (b *Backend) UpEnd(handle uint16) int16 {
+	_, rc := b.call(handle, CmdUpEnd, [5]int32{}, nil)
+	return rc
+}
+
+// callStruct выполняет вызов, возвращающий одну структуру фиксированной раскладки.
+func (b *Backend) callStruct(handle uint16, cmd Command, args [5]int32, buf []byte, l layout) int16 {
+	resp, rc := b.call(handle, cmd, args, nil)
+	if rc != EW_OK {
+		return rc
+	}
+	n := copy(buf, resp)
+	l.swap(buf[:n])
+	return EW_OK
+}
+
+// copyRecords копирует записи из ответа в буфер, переводя их в little-endian,
+// и возвращает количество скопированных записей.
+func copyRecords(buf, resp []byte, l layout) int {
+	size := l.size()
+	n := min(len(buf), len(resp)) / size
+	copy(buf, resp[:n*size])
+	l.swap(buf[:n*size])
+	return n
+}
+
+func trimNull(s string) string {
+	for i := 0; i < len(s); i++ {
+		if s[i] == 0 {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// errorf формирует ошибку декодирования данных ответа.
+func errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: "+format, append([]any{ErrBadFrame}, args...)...)
+}
@@ -0,0 +1,72 @@
+package ethernet
+
+import (
+	"encoding/binary"
+
+	"github.com/iwtcode/fanucAdapter/focas/model"
+)
+
+const (
+	sysInfoSize  = 18
+	statInfoSize = 18
+)
+
+// EncodeSysInfo кодирует ODBSYS для передачи по сети.
+func EncodeSysInfo(info model.SysInfo) []byte {
+	buf := make([]byte, sysInfoSize)
+	binary.BigEndian.PutUint16(buf[0:2], uint16(info.AddInfo))
+	binary.BigEndian.PutUint16(buf[2:4], uint16(info.MaxAxis))
+	copy(buf[4:6], info.CncType)
+	copy(buf[6:8], info.MtType)
+	copy(buf[8:12], info.Series)
+	copy(buf[12:16], info.Version)
+	copy(buf[16:18], info.Axes)
+	return buf
+}
+
+// DecodeSysInfo разбирает ODBSYS, полученную по сети.
+func DecodeSysInfo(data []byte) (model.SysInfo, error) {
+	if len(data) < sysInfoSize {
+		return model.SysInfo{}, errorf("sysinfo: %d bytes, want %d", len(data), sysInfoSize)
+	}
+	return model.SysInfo{
+		AddInfo: int16(binary.BigEndian.Uint16(data[0:2])),
+		MaxAxis: int16(binary.BigEndian.Uint16(data[2:4])),
+		CncType: trimNull(string(data[4:6])),
+		MtType:  trimNull(string(data[6:8])),
+		Series:  trimNull(string(data[8:12])),
+		Version: trimNull(string(data[12:16])),
+		Axes:    trimNull(string(data[16:18])),
+	}, nil
+}
+
+// EncodeStatInfo кодирует ODBST для передачи по сети.
+func EncodeStatInfo(stat model.StatInfo) []byte {
+	fields := []int16{stat.Hdck, stat.TmMode, stat.Aut, stat.Run, stat.Motion, stat.Mstb, stat.Emergency, stat.Alarm, stat.Edit}
+	buf := make([]byte, statInfoSize)
+	for i, f := range fields {
+		binary.BigEndian.PutUint16(buf[i*2:], uint16(f))
+	}
+	return buf
+}
+
+// DecodeStatInfo разбирает ODBST, полученную по сети.
+func DecodeStatInfo(data []byte) (model.StatInfo, error) {
+	if len(data) < statInfoSize {
+		return model.StatInfo{}, errorf("statinfo: %d bytes, want %d", len(data), statInfoSize)
+	}
+	field := func(i int) int16 {
+		return int16(binary.BigEndian.Uint16(data[i*2:]))
+	}
+	return model.StatInfo{
+		Hdck:      field(0),
+		TmMode:    field(1),
+		Aut:       field(2),
+		Run:       field(3),
+		Motion:    field(4),
+		Mstb:      field(5),
+		Emergency: field(6),
+		Alarm:     field(7),
+		Edit:      field(8),
+	}, nil
+}
@@ -0,0 +1,87 @@
+package ethernet
+
+// layout описывает поля C-структуры FOCAS по порядку: положительное число —
+// размер числового поля, у которого при передаче меняется порядок байт,
+// отрицательное — размер байтового массива, который передается как есть.
+//
+// Смена порядка байт симметрична, поэтому одна и та же раскладка переводит
+// буфер как из little-endian (C-структура) в big-endian (провод), так и обратно.
+type layout []int
+
+// Раскладки структур, которые передаются по сети.
+var (
+	layoutPoselm    = layout{4, 2, 2, 2, -1, -1} // POSELM
+	layoutOdbpos    = concat(layoutPoselm, layoutPoselm, layoutPoselm, layoutPoselm)
+	layoutLoadelm   = layout{4, 2, 2, -1, -1, -1, -1} // LOADELM
+	layoutOdbspload = concat(layoutLoadelm, layoutLoadelm)
+	layoutSpeedelm  = layout{4, 2, 2, 2, -1, -1} // SPEEDELM
+	layoutOdbspeed  = concat(layoutSpeedelm, layoutSpeedelm)
+	layoutOdbspn    = layout{2, 2, 2, 2, 2, 2, 2, 2, 2, 2}
+	layoutOdbact    = layout{2, 2, 4}
+	layoutOdbtofs   = layout{2, 2, 4}
+	layoutIodbpsd   = layout{2, 2, 4}
+	layoutAlmmsg    = layout{4, 2, 2, 2, 2, -64} // ODBALMMSG2
+	layoutDgnHeader = layout{2, 2}
+)
+
+const (
+	iodbpsdSize = 8  // IODBPSD для long-параметра
+	exeprgName  = 36 // ODBEXEPRG.name
+)
+
+func concat(parts ...layout) layout {
+	var l layout
+	for _, p := range parts {
+		l = append(l, p...)
+	}
+	return l
+}
+
+// size возвращает размер одной записи в байтах.
+func (l layout) size() int {
+	n := 0
+	for _, f := range l {
+		if f < 0 {
+			n -= f
+		} else {
+			n += f
+		}
+	}
+	return n
+}
+
+// swap меняет порядок байт во всех полных записях буфера.
+func (l layout) swap(buf []byte) {
+	size := l.size()
+	for off := 0; off+size <= len(buf); off += size {
+		p := off
+		for _, f := range l {
+			if f < 0 {
+				p -= f
+				continue
+			}
+			reverse(buf[p : p+f])
+			p += f
+		}
+	}
+}
+
+// diagLayout возвращает раскладку элемента диагностики по его размеру.
+func diagLayout(elemSize int) layout {
+	switch elemSize {
+	case 8:
+		return layout{4, 4} // REALDATA
+	case 4:
+		return layout{4}
+	case 2:
+		return layout{2}
+	default:
+		return layout{-elemSize}
+	}
+}
+
+func reverse(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
@@ -0,0 +1,257 @@
+// Package ethernet реализует протокол FOCAS/Ethernet (TCP, порт 8193 по умолчанию)
+// на чистом Go, без libfwlib32 и cgo.
+//
+// Обмен идет кадрами. Каждый кадр начинается с заголовка из 10 байт
+// (big-endian):
+//
+//	A0 A0 A0 A0 | версия (2) | тип кадра (2) | длина тела (2)
+//
+// Тело запроса данных (FrameRequest) содержит количество блоков (2) и сами блоки:
+//
+//	длина блока (2) | команда c1 c2 c3 (6) | аргументы 5 x int32 (20) | доп. данные
+//
+// Тело ответа (FrameResponse) устроено так же, но блок ответа имеет вид:
+//
+//	длина блока (2) | команда c1 c2 c3 (6) | rc (2) | резерв (4) | длина данных (2) | данные
+//
+// Все числа в данных передаются в big-endian; Backend переводит их
+// в раскладку C-структур FOCAS, которую ожидает пакет focas.
+//
+// Заголовок кадра, открытие и закрытие соединения и формат блоков сверены
+// с libfwlib32 (tests/ethernet_test.go). Набор команд рассчитан на Server
+// этого пакета и с libfwlib32 совпадает не полностью: библиотека получает
+// сведения о системе при открытии соединения, читает сообщения об ошибках
+// командой 0x0023, диапазон параметров — командой 0x000E, а cnc_statinfo
+// и cnc_rdposition отправляет пакетами из нескольких блоков.
+package ethernet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Magic — сигнатура начала каждого кадра.
+const Magic uint32 = 0xA0A0A0A0
+
+// Version — версия протокола, передаваемая в заголовке.
+const Version uint16 = 1
+
+// Типы кадров.
+const (
+	FrameOpenRequest   uint16 = 0x0101
+	FrameOpenResponse  uint16 = 0x0102
+	FrameCloseRequest  uint16 = 0x0201
+	FrameCloseResponse uint16 = 0x0202
+	FrameRequest       uint16 = 0x2101
+	FrameResponse      uint16 = 0x2102
+)
+
+const (
+	headerSize        = 10
+	requestBlockHead  = 2 + 6 + 20
+	responseBlockHead = 2 + 6 + 2 + 4 + 2
+	maxBodySize       = 0xFFFF
+)
+
+// ErrBadFrame возвращается при разборе некорректного кадра.
+var ErrBadFrame = errors.New("focas/ethernet: malformed frame")
+
+// Command идентифицирует функцию FOCAS в блоке запроса.
+type Command struct {
+	C1, C2, C3 uint16
+}
+
+// Коды команд для функций, которые использует адаптер.
+var (
+	CmdSysInfo    = Command{1, 1, 0x0018}
+	CmdStatInfo   = Command{1, 1, 0x0019}
+	CmdRdParam    = Command{1, 1, 0x000E}
+	CmdRdParar    = Command{1, 1, 0x000F}
+	CmdDiagnoss   = Command{1, 1, 0x0030}
+	CmdRdPosition = Command{1, 1, 0x0026}
+	CmdActf       = Command{1, 1, 0x0024}
+	CmdRdSpeed    = Command{1, 1, 0x0025}
+	CmdRdSpMeter  = Command{1, 1, 0x0040}
+	CmdRdSpLoad   = Command{1, 1, 0x0041}
+	CmdRdTofs     = Command{1, 1, 0x0011}
+	CmdRdAlmMsg   = Command{1, 1, 0x0035}
+	CmdExePrgName = Command{1, 1, 0x00CF}
+	CmdRdExecProg = Command{1, 1, 0x0020}
+	CmdGetPath    = Command{1, 1, 0x00B0}
+	CmdSetPath    = Command{1, 1, 0x00B1}
+	CmdUpStart    = Command{1, 1, 0x0001}
+	CmdUpStart4   = Command{1, 1, 0x00F7}
+	CmdUpload     = Command{1, 1, 0x0002}
+	CmdUpEnd      = Command{1, 1, 0x0003}
+	CmdGetDtErr   = Command{1, 1, 0x00B2}
+)
+
+// String возвращает команду в виде "c1.c2.0xc3" для логов.
+func (c Command) String() string {
+	return fmt.Sprintf("%d.%d.0x%04X", c.C1, c.C2, c.C3)
+}
+
+// Frame — кадр протокола без сигнатуры.
+type Frame struct {
+	Version uint16
+	Type    uint16
+	Body    []byte
+}
+
+// RequestBlock — один вызов функции FOCAS внутри кадра запроса.
+type RequestBlock struct {
+	Command Command
+	Args    [5]int32
+	Payload []byte
+}
+
+// ResponseBlock — результат одного вызова внутри кадра ответа.
+type ResponseBlock struct {
+	Command Command
+	RC      int16
+	Data    []byte
+}
+
+// ReadFrame читает один кадр из r.
+func ReadFrame(r io.Reader) (Frame, error) {
+	var header [headerSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return Frame{}, err
+	}
+	if binary.BigEndian.Uint32(header[0:4]) != Magic {
+		return Frame{}, fmt.Errorf("%w: bad magic %x", ErrBadFrame, header[0:4])
+	}
+
+	frame := Frame{
+		Version: binary.BigEndian.Uint16(header[4:6]),
+		Type:    binary.BigEndian.Uint16(header[6:8]),
+		Body:    make([]byte, binary.BigEndian.Uint16(header[8:10])),
+	}
+	if _, err := io.ReadFull(r, frame.Body); err != nil {
+		return Frame{}, err
+	}
+	return frame, nil
+}
+
+// WriteFrame записывает кадр в w одним вызовом Write.
+func WriteFrame(w io.Writer, frame Frame) error {
+	if len(frame.Body) > maxBodySize {
+		return fmt.Errorf("%w: body too large (%d bytes)", ErrBadFrame, len(frame.Body))
+	}
+	buf := make([]byte, headerSize+len(frame.Body))
+	binary.BigEndian.PutUint32(buf[0:4], Magic)
+	binary.BigEndian.PutUint16(buf[4:6], frame.Version)
+	binary.BigEndian.PutUint16(buf[6:8], frame.Type)
+	binary.BigEndian.PutUint16(buf[8:10], uint16(len(frame.Body)))
+	copy(buf[headerSize:], frame.Body)
+	_, err := w.Write(buf)
+	return err
+}
+
+// EncodeRequests формирует тело кадра запроса из блоков.
+func EncodeRequests(blocks ...RequestBlock) []byte {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.BigEndian, uint16(len(blocks)))
+	for _, b := range blocks {
+		_ = binary.Write(&buf, binary.BigEndian, uint16(requestBlockHead+len(b.Payload)))
+		_ = binary.Write(&buf, binary.BigEndian, b.Command)
+		_ = binary.Write(&buf, binary.BigEndian, b.Args)
+		buf.Write(b.Payload)
+	}
+	return buf.Bytes()
+}
+
+// DecodeRequests разбирает тело кадра запроса.
+func DecodeRequests(body []byte) ([]RequestBlock, error) {
+	count, rest, err := blockCount(body)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := make([]RequestBlock, 0, count)
+	for i := 0; i < count; i++ {
+		var block []byte
+		block, rest, err = nextBlock(rest, requestBlockHead)
+		if err != nil {
+			return nil, err
+		}
+		rb := RequestBlock{Command: decodeCommand(block[2:8])}
+		for j := range rb.Args {
+			rb.Args[j] = int32(binary.BigEndian.Uint32(block[8+j*4:]))
+		}
+		rb.Payload = block[requestBlockHead:]
+		blocks = append(blocks, rb)
+	}
+	return blocks, nil
+}
+
+// EncodeResponses формирует тело кадра ответа из блоков.
+func EncodeResponses(blocks ...ResponseBlock) []byte {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.BigEndian, uint16(len(blocks)))
+	for _, b := range blocks {
+		_ = binary.Write(&buf, binary.BigEndian, uint16(responseBlockHead+len(b.Data)))
+		_ = binary.Write(&buf, binary.BigEndian, b.Command)
+		_ = binary.Write(&buf, binary.BigEndian, b.RC)
+		buf.Write(make([]byte, 4))
+		_ = binary.Write(&buf, binary.BigEndian, uint16(len(b.Data)))
+		buf.Write(b.Data)
+	}
+	return buf.Bytes()
+}
+
+// DecodeResponses разбирает тело кадра ответа.
+func DecodeResponses(body []byte) ([]ResponseBlock, error) {
+	count, rest, err := blockCount(body)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := make([]ResponseBlock, 0, count)
+	for i := 0; i < count; i++ {
+		var block []byte
+		block, rest, err = nextBlock(rest, responseBlockHead)
+		if err != nil {
+			return nil, err
+		}
+		dataLen := int(binary.BigEndian.Uint16(block[14:16]))
+		if responseBlockHead+dataLen > len(block) {
+			return nil, fmt.Errorf("%w: data length %d exceeds block", ErrBadFrame, dataLen)
+		}
+		blocks = append(blocks, ResponseBlock{
+			Command: decodeCommand(block[2:8]),
+			RC:      int16(binary.BigEndian.Uint16(block[8:10])),
+			Data:    block[responseBlockHead : responseBlockHead+dataLen],
+		})
+	}
+	return blocks, nil
+}
+
+func blockCount(body []byte) (int, []byte, error) {
+	if len(body) < 2 {
+		return 0, nil, fmt.Errorf("%w: body too short", ErrBadFrame)
+	}
+	return int(binary.BigEndian.Uint16(body[0:2])), body[2:], nil
+}
+
+func nextBlock(buf []byte, minSize int) (block, rest []byte, err error) {
+	if len(buf) < 2 {
+		return nil, nil, fmt.Errorf("%w: truncated block", ErrBadFrame)
+	}
+	size := int(binary.BigEndian.Uint16(buf[0:2]))
+	if size < minSize || size > len(buf) {
+		return nil, nil, fmt.Errorf("%w: bad block length %d", ErrBadFrame, size)
+	}
+	return buf[:size], buf[size:], nil
+}
+
+func decodeCommand(b []byte) Command {
+	return Command{
+		C1: binary.BigEndian.Uint16(b[0:2]),
+		C2: binary.BigEndian.Uint16(b[2:4]),
+		C3: binary.BigEndian.Uint16(b[4:6]),
+	}
+}
@@ -0,0 +1,400 @@
+package ethernet
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+
+	. "github.com/iwtcode/fanucAdapter/focas/errcode"
+	"github.com/iwtcode/fanucAdapter/focas/model"
+)
+
+// ErrServerClosed возвращается из Serve после вызова Close.
+var ErrServerClosed = errors.New("focas/ethernet: server closed")
+
+// Максимальное количество записей, которое сервер готов вернуть за один вызов.
+const maxRecords = 1024
+
+// Server принимает подключения FOCAS/Ethernet и выполняет запросы клиентов
+// на указанном model.Backend. Каждое TCP-соединение получает свой хендл бэкенда.
+//
+// Если бэкенд возвращает EW_SOCKET, сервер разрывает соединение вместо ответа:
+// так клиент видит настоящую сетевую ошибку.
+type Server struct {
+	backend model.Backend
+
+	mu        sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// NewServer создает сервер, обслуживающий запросы через backend.
+func NewServer(backend model.Backend) *Server {
+	return &Server{
+		backend:   backend,
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+// Serve принимает подключения на ln, пока он не будет закрыт.
+// Всегда возвращает ненулевую ошибку; после Close — ErrServerClosed.
+func (s *Server) Serve(ln net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		ln.Close()
+		return ErrServerClosed
+	}
+	s.listeners[ln] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.listeners, ln)
+		s.mu.Unlock()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+
+		if !s.track(conn) {
+			conn.Close()
+			return ErrServerClosed
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer s.untrack(conn)
+			s.serveConn(conn)
+		}()
+	}
+}
+
+// Close закрывает все слушатели и соединения и дожидается завершения обработчиков.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for ln := range s.listeners {
+		ln.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return nil
+}
+
+// DropConnections разрывает все активные клиентские соединения.
+// Новые подключения при этом продолжают приниматься.
+func (s *Server) DropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+}
+
+func (s *Server) track(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+func (s *Server) untrack(conn net.Conn) {
+	s.mu.Lock()
+	delete(s.conns, conn)
+	s.mu.Unlock()
+	conn.Close()
+}
+
+// serverSession хранит состояние одного клиентского соединения.
+type serverSession struct {
+	handle  uint16
+	open    bool
+	maxAxis int16 // кэш ODBSYS.max_axis для диагностики по всем осям
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	sess := &serverSession{}
+	defer func() {
+		if sess.open {
+			s.backend.FreeLibHndl(sess.handle)
+		}
+	}()
+
+	for {
+		req, err := ReadFrame(conn)
+		if err != nil {
+			return
+		}
+
+		var resp Frame
+		switch req.Type {
+		case FrameOpenRequest:
+			if sess.open {
+				return
+			}
+			host, port := splitAddr(conn.LocalAddr())
+			handle, rc := s.backend.AllcLibHndl3(host, port, 0)
+			if rc != EW_OK {
+				return
+			}
+			sess.handle, sess.open = handle, true
+			resp = Frame{Version: req.Version, Type: FrameOpenResponse}
+
+		case FrameCloseRequest:
+			if sess.open {
+				s.backend.FreeLibHndl(sess.handle)
+				sess.open = false
+			}
+			_ = WriteFrame(conn, Frame{Version: req.Version, Type: FrameCloseResponse})
+			return
+
+		case FrameRequest:
+			if !sess.open {
+				return
+			}
+			blocks, err := DecodeRequests(req.Body)
+			if err != nil {
+				return
+			}
+			results := make([]ResponseBlock, 0, len(blocks))
+			for _, block := range blocks {
+				result := s.dispatch(sess, block)
+				if result.RC == EW_SOCKET {
+					return
+				}
+				results = append(results, result)
+			}
+			resp = Frame{Version: req.Version, Type: FrameResponse, Body: EncodeResponses(results...)}
+
+		default:
+			return
+		}
+
+		if err := WriteFrame(conn, resp); err != nil {
+			return
+		}
+	}
+}
+
+// dispatch выполняет один блок запроса на бэкенде и формирует блок ответа.
+// Данные бэкенда (little-endian C-структуры) переводятся в сетевой порядок байт.
+func (s *Server) dispatch(sess *serverSession, req RequestBlock) ResponseBlock {
+	resp := ResponseBlock{Command: req.Command}
+	h := sess.handle
+	arg := func(i int) int16 { return int16(req.Args[i]) }
+
+	switch req.Command {
+	case CmdSysInfo:
+		info, rc := s.backend.SysInfo(h)
+		resp.RC = rc
+		if rc == EW_OK {
+			resp.Data = EncodeSysInfo(info)
+		}
+
+	case CmdStatInfo:
+		stat, rc := s.backend.StatInfo(h)
+		resp.RC = rc
+		if rc == EW_OK {
+			resp.Data = EncodeStatInfo(stat)
+		}
+
+	case CmdExePrgName:
+		name, onum, rc := s.backend.ExePrgName(h)
+		resp.RC = rc
+		if rc == EW_OK {
+			resp.Data = make([]byte, exeprgName+4)
+			copy(resp.Data[:exeprgName-1], name)
+			binary.BigEndian.PutUint32(resp.Data[exeprgName:], uint32(int32(onum)))
+		}
+
+	case CmdRdExecProg:
+		length := uint16(req.Args[0])
+		var blknum int16
+		buf := make([]byte, length)
+		resp.RC = s.backend.RdExecProg(h, &length, &blknum, buf)
+		if resp.RC == EW_OK {
+			resp.Data = make([]byte, 2+int(length))
+			binary.BigEndian.PutUint16(resp.Data[0:2], uint16(blknum))
+			copy(resp.Data[2:], buf[:length])
+		}
+
+	case CmdRdPosition:
+		num := clampRecords(arg(1))
+		buf := make([]byte, int(num)*layoutOdbpos.size())
+		resp.RC = s.backend.RdPosition(h, arg(0), &num, buf)
+		resp.Data = records(resp.RC, buf, int(num), layoutOdbpos)
+
+	case CmdDiagnoss:
+		resp.RC, resp.Data = s.diagnoss(sess, arg(0), arg(1), arg(2))
+
+	case CmdRdSpMeter:
+		num := clampRecords(arg(1))
+		buf := make([]byte, int(num)*layoutOdbspload.size())
+		resp.RC = s.backend.RdSpMeter(h, arg(0), &num, buf)
+		resp.Data = records(resp.RC, buf, int(num), layoutOdbspload)
+
+	case CmdRdSpLoad:
+		buf := make([]byte, layoutOdbspn.size())
+		resp.RC = s.backend.RdSpLoad(h, arg(0), buf)
+		resp.Data = records(resp.RC, buf, 1, layoutOdbspn)
+
+	case CmdRdSpeed:
+		buf := make([]byte, layoutOdbspeed.size())
+		resp.RC = s.backend.RdSpeed(h, arg(0), buf)
+		resp.Data = records(resp.RC, buf, 1, layoutOdbspeed)
+
+	case CmdActf:
+		buf := make([]byte, layoutOdbact.size())
+		resp.RC = s.backend.Actf(h, buf)
+		resp.Data = records(resp.RC, buf, 1, layoutOdbact)
+
+	case CmdRdTofs:
+		buf := make([]byte, layoutOdbtofs.size())
+		resp.RC = s.backend.RdTofs(h, arg(0), arg(1), arg(2), buf)
+		resp.Data = records(resp.RC, buf, 1, layoutOdbtofs)
+
+	case CmdRdAlmMsg:
+		num := clampRecords(arg(1))
+		buf := make([]byte, int(num)*layoutAlmmsg.size())
+		resp.RC = s.backend.RdAlmMsg(h, arg(0), &num, buf)
+		resp.Data = records(resp.RC, buf, int(num), layoutAlmmsg)
+
+	case CmdRdParam:
+		buf := make([]byte, max(int(arg(2)), iodbpsdSize))
+		resp.RC = s.backend.RdParam(h, arg(0), arg(1), arg(2), buf)
+		resp.Data = records(resp.RC, buf, 1, layoutIodbpsd)
+
+	case CmdRdParar:
+		start, end, length := arg(0), arg(1), max(arg(3), 0)
+		buf := make([]byte, length)
+		resp.RC = s.backend.RdParar(h, &start, arg(2), &end, &length, buf)
+		resp.Data = records(resp.RC, buf, int(length)/iodbpsdSize, layoutIodbpsd)
+
+	case CmdGetPath:
+		path, maxPath, rc := s.backend.GetPath(h)
+		resp.RC = rc
+		if rc == EW_OK {
+			resp.Data = make([]byte, 4)
+			binary.BigEndian.PutUint16(resp.Data[0:2], uint16(path))
+			binary.BigEndian.PutUint16(resp.Data[2:4], uint16(maxPath))
+		}
+
+	case CmdSetPath:
+		resp.RC = s.backend.SetPath(h, arg(0))
+
+	case CmdGetDtErr:
+		errNo, errDtNo, rc := s.backend.GetDtailErr(h)
+		resp.RC = rc
+		if rc == EW_OK {
+			resp.Data = make([]byte, 4)
+			binary.BigEndian.PutUint16(resp.Data[0:2], uint16(errNo))
+			binary.BigEndian.PutUint16(resp.Data[2:4], uint16(errDtNo))
+		}
+
+	case CmdUpStart:
+		resp.RC = s.backend.UpStart(h, arg(0))
+
+	case CmdUpStart4:
+		resp.RC = s.backend.UpStart4(h, arg(0), trimNull(string(req.Payload)))
+
+	case CmdUpload:
+		// Структура ODBUP: dummy[2](4) + data
+		const odbupHeaderSize = 4
+		length := uint16(req.Args[0])
+		buf := make([]byte, odbupHeaderSize+int(length))
+		resp.RC = s.backend.Upload(h, buf, &length)
+		if resp.RC == EW_OK || resp.RC == EW_BUFFER {
+			resp.Data = buf[odbupHeaderSize : odbupHeaderSize+int(length)]
+		}
+
+	case CmdUpEnd:
+		resp.RC = s.backend.UpEnd(h)
+
+	default:
+		resp.RC = EW_FUNC
+	}
+	return resp
+}
+
+// diagnoss выполняет cnc_diagnoss. Размер элемента данных сервер определяет сам:
+// для одной оси — по длине запроса, для всех осей — по ODBSYS.max_axis.
+func (s *Server) diagnoss(sess *serverSession, diagNo, axisNo, length int16) (int16, []byte) {
+	const headerSize = 4
+	if length < headerSize {
+		return EW_LENGTH, nil
+	}
+
+	elemSize := int(length) - headerSize
+	if axisNo == -1 {
+		if sess.maxAxis <= 0 {
+			info, rc := s.backend.SysInfo(sess.handle)
+			if rc != EW_OK {
+				return rc, nil
+			}
+			sess.maxAxis = info.MaxAxis
+		}
+		if sess.maxAxis <= 0 {
+			return EW_LENGTH, nil
+		}
+		elemSize /= int(sess.maxAxis)
+	}
+
+	buf := make([]byte, length)
+	rc := s.backend.Diagnoss(sess.handle, diagNo, axisNo, length, buf)
+	if rc != EW_OK {
+		return rc, nil
+	}
+
+	// Ответ: размер элемента (2) + ODBDGN в big-endian
+	data := make([]byte, 2+len(buf))
+	binary.BigEndian.PutUint16(data[0:2], uint16(elemSize))
+	copy(data[2:], buf)
+	layoutDgnHeader.swap(data[2 : 2+headerSize])
+	if elemSize > 0 {
+		diagLayout(elemSize).swap(data[2+headerSize:])
+	}
+	return EW_OK, data
+}
+
+// records возвращает первые n записей буфера в сетевом порядке байт.
+func records(rc int16, buf []byte, n int, l layout) []byte {
+	if rc != EW_OK {
+		return nil
+	}
+	size := min(n*l.size(), len(buf))
+	data := buf[:size]
+	l.swap(data)
+	return data
+}
+
+func clampRecords(n int16) int16 {
+	return min(max(n, 0), maxRecords)
+}
+
+func splitAddr(addr net.Addr) (string, uint16) {
+	if tcp, ok := addr.(*net.TCPAddr); ok {
+		return tcp.IP.String(), uint16(tcp.Port)
+	}
+	return addr.String(), 0
+}
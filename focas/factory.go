@@ -1,54 +1,252 @@
 package focas
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/iwtcode/fanucAdapter/focas/interpreter"
 	"github.com/iwtcode/fanucAdapter/focas/model"
 	"github.com/iwtcode/fanucAdapter/focas/program"
 )
 
+// SeriesMatcher сообщает, подходит ли реализация для серии ЧПУ.
+// Серия передается в верхнем регистре, например "0I-T" или "30I-M".
+type SeriesMatcher func(series string) bool
+
+// Приоритеты встроенных реализаций
+const (
+	// PriorityBuiltin — приоритет встроенных реализаций для конкретных серий.
+	PriorityBuiltin = 0
+	// PriorityFallback — приоритет реализации по умолчанию (ModelUnknown).
+	PriorityFallback = math.MinInt32
+)
+
+// Implementation описывает реализацию интерпретатора и/или ридера программ для серий ЧПУ.
+//
+// Интерпретатор и ридер выбираются независимо: из реализаций, чей Match подходит
+// для серии и у которых задана соответствующая фабрика, побеждает реализация с большим
+// Priority, а при равном приоритете — зарегистрированная позже. Поэтому
+// пользовательская реализация с PriorityBuiltin заменяет встроенную, а реализация
+// только с NewProgramReader заменяет ридер, сохраняя встроенный интерпретатор.
+type Implementation struct {
+	Name     string        // Уникальное имя, например "Model0i"
+	Match    SeriesMatcher // Серии, для которых подходит реализация
+	Priority int
+
+	NewInterpreter   func() model.Interpreter   // Может быть nil
+	NewProgramReader func() model.ProgramReader // Может быть nil
+}
+
+// ErrDuplicateImplementation возвращается при регистрации реализации с уже занятым именем.
+var ErrDuplicateImplementation = errors.New("implementation already registered")
+
+// registration — зарегистрированная реализация с порядковым номером регистрации.
+type registration struct {
+	Implementation
+	seq int
+}
+
+var registry = struct {
+	mu      sync.RWMutex
+	entries []registration
+	nextSeq int
+}{}
+
+// Register добавляет реализацию в реестр. Изменения применяются к адаптерам,
+// которые выбирают реализацию после регистрации (при подключении).
+func Register(impl Implementation) error {
+	if impl.Name == "" {
+		return errors.New("implementation name is empty")
+	}
+	if impl.Match == nil {
+		return fmt.Errorf("implementation %q: matcher is nil", impl.Name)
+	}
+	if impl.NewInterpreter == nil && impl.NewProgramReader == nil {
+		return fmt.Errorf("implementation %q: no factories", impl.Name)
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	for _, r := range registry.entries {
+		if r.Name == impl.Name {
+			return fmt.Errorf("%w: %q", ErrDuplicateImplementation, impl.Name)
+		}
+	}
+	registry.entries = append(registry.entries, registration{Implementation: impl, seq: registry.nextSeq})
+	registry.nextSeq++
+	return nil
+}
+
+// MustRegister вызывает Register и паникует при ошибке. Удобен в init().
+func MustRegister(impl Implementation) {
+	if err := Register(impl); err != nil {
+		panic(err)
+	}
+}
+
+// Unregister удаляет реализацию по имени и сообщает, была ли она зарегистрирована.
+func Unregister(name string) bool {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	for i, r := range registry.entries {
+		if r.Name == name {
+			registry.entries = append(registry.entries[:i], registry.entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Implementations возвращает зарегистрированные реализации в порядке убывания приоритета.
+func Implementations() []Implementation {
+	entries := sortedRegistry()
+	result := make([]Implementation, len(entries))
+	for i, r := range entries {
+		result[i] = r.Implementation
+	}
+	return result
+}
+
+// MatchPrefix возвращает matcher для серий, начинающихся с одного из префиксов (без учета регистра).
+func MatchPrefix(prefixes ...string) SeriesMatcher {
+	upper := make([]string, len(prefixes))
+	for i, p := range prefixes {
+		upper[i] = strings.ToUpper(p)
+	}
+	return func(series string) bool {
+		for _, p := range upper {
+			if strings.HasPrefix(series, p) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchAny подходит для любой серии.
+func MatchAny(string) bool { return true }
+
 // GetModelImplementations выбирает подходящие интерпретатор и ридер программ
 // на основе строки серии ЧПУ.
 func GetModelImplementations(series string) (model.Interpreter, model.ProgramReader) {
+	interp, reader, _ := resolveImplementations(series)
+	return interp, reader
+}
+
+// resolveImplementations выбирает интерпретатор и ридер программ по реестру и возвращает
+// их вместе с именем выбора: "Model0i" или "Model0i+CustomReader", если ридер взят
+// из другой реализации.
+func resolveImplementations(series string) (model.Interpreter, model.ProgramReader, string) {
 	s := strings.ToUpper(series)
 
-	// Реализации по умолчанию
-	var interp model.Interpreter = &interpreter.ModelUnknownInterpreter{}
-	var progReader model.ProgramReader = &program.ModelUnknownProgramReader{}
-
-	// Переопределения для конкретных моделей
-	if strings.HasPrefix(s, model.Series0i) {
-		interp = &interpreter.Model0iInterpreter{}
-		progReader = &program.Model0iProgramReader{}
-	} else if strings.HasPrefix(s, model.Series15i) {
-		interp = &interpreter.Model15iInterpreter{}
-		progReader = &program.Model15iProgramReader{}
-	} else if strings.HasPrefix(s, model.Series15) {
-		interp = &interpreter.Model15Interpreter{}
-		progReader = &program.Model15ProgramReader{}
-	} else if strings.HasPrefix(s, model.Series16i) {
-		interp = &interpreter.Model16iInterpreter{}
-		progReader = &program.Model16iProgramReader{}
-	} else if strings.HasPrefix(s, model.Series16) {
-		interp = &interpreter.Model16Interpreter{}
-		progReader = &program.Model16ProgramReader{}
-	} else if strings.HasPrefix(s, model.Series18i) {
-		interp = &interpreter.Model18iInterpreter{}
-		progReader = &program.Model18iProgramReader{}
-	} else if strings.HasPrefix(s, model.Series21) {
-		interp = &interpreter.Model21Interpreter{}
-		progReader = &program.Model21ProgramReader{}
-	} else if strings.HasPrefix(s, model.Series30) {
-		interp = &interpreter.Model30Interpreter{}
-		progReader = &program.Model30ProgramReader{}
-	} else if strings.HasPrefix(s, model.Series31) {
-		interp = &interpreter.Model31Interpreter{}
-		progReader = &program.Model31ProgramReader{}
-	} else if strings.HasPrefix(s, model.Series32) {
-		interp = &interpreter.Model32Interpreter{}
-		progReader = &program.Model32ProgramReader{}
-	}
-
-	return interp, progReader
+	var interp model.Interpreter
+	var reader model.ProgramReader
+	var interpName, readerName string
+	for _, r := range sortedRegistry() {
+		if interp != nil && reader != nil {
+			break
+		}
+		if !r.Match(s) {
+			continue
+		}
+		if interp == nil && r.NewInterpreter != nil {
+			interp, interpName = r.NewInterpreter(), r.Name
+		}
+		if reader == nil && r.NewProgramReader != nil {
+			reader, readerName = r.NewProgramReader(), r.Name
+		}
+	}
+
+	// Встроенная реализация по умолчанию могла быть удалена из реестра
+	if interp == nil {
+		interp, interpName = &interpreter.ModelUnknownInterpreter{}, "ModelUnknown"
+	}
+	if reader == nil {
+		reader, readerName = &program.ModelUnknownProgramReader{}, "ModelUnknown"
+	}
+
+	name := interpName
+	if readerName != interpName {
+		name += "+" + readerName
+	}
+	return interp, reader, name
+}
+
+// sortedRegistry возвращает копию реестра, упорядоченную по убыванию приоритета,
+// а при равном приоритете — от последней регистрации к первой.
+func sortedRegistry() []registration {
+	registry.mu.RLock()
+	entries := append([]registration(nil), registry.entries...)
+	registry.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Priority != entries[j].Priority {
+			return entries[i].Priority > entries[j].Priority
+		}
+		return entries[i].seq > entries[j].seq
+	})
+	return entries
+}
+
+// Встроенные реализации. Общие префиксы регистрируются раньше более конкретных
+// ("15" до "15I"), чтобы при равном приоритете побеждала конкретная серия.
+func init() {
+	MustRegister(Implementation{
+		Name:             "ModelUnknown",
+		Match:            MatchAny,
+		Priority:         PriorityFallback,
+		NewInterpreter:   func() model.Interpreter { return &interpreter.ModelUnknownInterpreter{} },
+		NewProgramReader: func() model.ProgramReader { return &program.ModelUnknownProgramReader{} },
+	})
+
+	builtin := []struct {
+		name   string
+		series string
+		interp func() model.Interpreter
+		reader func() model.ProgramReader
+	}{
+		{"Model0i", model.Series0i,
+			func() model.Interpreter { return &interpreter.Model0iInterpreter{} },
+			func() model.ProgramReader { return &program.Model0iProgramReader{} }},
+		{"Model15", model.Series15,
+			func() model.Interpreter { return &interpreter.Model15Interpreter{} },
+			func() model.ProgramReader { return &program.Model15ProgramReader{} }},
+		{"Model15i", model.Series15i,
+			func() model.Interpreter { return &interpreter.Model15iInterpreter{} },
+			func() model.ProgramReader { return &program.Model15iProgramReader{} }},
+		{"Model16", model.Series16,
+			func() model.Interpreter { return &interpreter.Model16Interpreter{} },
+			func() model.ProgramReader { return &program.Model16ProgramReader{} }},
+		{"Model16i", model.Series16i,
+			func() model.Interpreter { return &interpreter.Model16iInterpreter{} },
+			func() model.ProgramReader { return &program.Model16iProgramReader{} }},
+		{"Model18i", model.Series18i,
+			func() model.Interpreter { return &interpreter.Model18iInterpreter{} },
+			func() model.ProgramReader { return &program.Model18iProgramReader{} }},
+		{"Model21", model.Series21,
+			func() model.Interpreter { return &interpreter.Model21Interpreter{} },
+			func() model.ProgramReader { return &program.Model21ProgramReader{} }},
+		{"Model30", model.Series30,
+			func() model.Interpreter { return &interpreter.Model30Interpreter{} },
+			func() model.ProgramReader { return &program.Model30ProgramReader{} }},
+		{"Model31", model.Series31,
+			func() model.Interpreter { return &interpreter.Model31Interpreter{} },
+			func() model.ProgramReader { return &program.Model31ProgramReader{} }},
+		{"Model32", model.Series32,
+			func() model.Interpreter { return &interpreter.Model32Interpreter{} },
+			func() model.ProgramReader { return &program.Model32ProgramReader{} }},
+	}
+	for _, b := range builtin {
+		MustRegister(Implementation{
+			Name:             b.name,
+			Match:            MatchPrefix(b.series),
+			Priority:         PriorityBuiltin,
+			NewInterpreter:   b.interp,
+			NewProgramReader: b.reader,
+		})
+	}
 }
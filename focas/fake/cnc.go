@@ -0,0 +1,237 @@
+package fake
+
+import (
+	"sort"
+
+	"github.com/iwtcode/fanucAdapter/focas/model"
+)
+
+// Axis описывает ось виртуального станка.
+type Axis struct {
+	Name     byte  // Имя оси, например 'X'
+	Suffix   byte  // Суффикс имени (0, если отсутствует)
+	Position int32 // Абсолютная позиция в единицах 10^-PosDec
+	PosDec   int16 // Количество знаков после запятой
+
+	Load             int32 // Диагностика 301 (нагрузка сервопривода) в единицах 10^-LoadDec
+	LoadDec          int32
+	ServoTemperature int32 // Диагностика 308
+	CoderTemperature int32 // Диагностика 309
+	PowerConsumption int32 // Диагностика 4901
+}
+
+// Spindle описывает шпиндель виртуального станка.
+type Spindle struct {
+	Load     int32 // Нагрузка в единицах 10^-LoadDec
+	LoadDec  int16
+	Speed    int32 // Сырое значение скорости, как его возвращает cnc_rdspmeter
+	SpeedDec int16
+	Override int16 // Сырое значение коррекции (0..16383), как его возвращает cnc_rdspload
+	Diag411  int32 // Диагностика 411
+}
+
+// Alarm описывает активную ошибку.
+type Alarm struct {
+	Number  int32
+	Type    int16
+	Axis    int16
+	Message string
+}
+
+// PathState хранит состояние дополнительного канала (path) многоканального станка.
+type PathState struct {
+	Stat     model.StatInfo
+	Axes     []Axis
+	Spindles []Spindle
+	Alarms   []Alarm
+
+	ExecName   string
+	ExecNumber int64
+	ExecBlock  string
+}
+
+// CNC хранит состояние виртуального станка, которое отдает Backend.
+type CNC struct {
+	SysInfo model.SysInfo
+	Stat    model.StatInfo
+
+	Axes     []Axis
+	Spindles []Spindle
+	Alarms   []Alarm
+
+	// Params содержит значения параметров для cnc_rdparam и cnc_rdparar.
+	Params map[int16]int32
+	// Diag содержит значения диагностики, не покрытые полями Axis и Spindle.
+	// Индекс в срезе соответствует номеру оси, начиная с 0.
+	Diag map[int16][]int32
+
+	ActualFeed    int32 // cnc_rdspeed, фактическая подача в единицах 10^-ActualFeedDec
+	ActualFeedDec int16
+	ContourFeed   int32 // cnc_actf
+	FeedOverride  int32 // cnc_rdtofs(1, 0)
+	JogOverride   int32 // cnc_rdtofs(1, 1)
+
+	// Path — канал, который выбран у нового хендла; MaxPath — количество каналов.
+	// Поля Stat, Axes, Spindles, Alarms и Exec* описывают канал 1,
+	// ExtraPaths — каналы 2..MaxPath.
+	Path       int16
+	MaxPath    int16
+	ExtraPaths []PathState
+
+	// Выполняемая программа
+	ExecName   string
+	ExecNumber int64
+	ExecBlock  string
+
+	// Programs хранит тексты программ по имени ("O0001") или полному пути
+	// ("//CNC_MEM/USER/PATH1/MAIN.NC").
+	Programs map[string]string
+}
+
+// NewCNC возвращает виртуальный токарный станок серии 0i-TF с двумя осями,
+// одним шпинделем и загруженной программой O0001.
+func NewCNC() *CNC {
+	return &CNC{
+		SysInfo: model.SysInfo{
+			AddInfo: 0x02, // i-серия
+			MaxAxis: 32,
+			CncType: "0",
+			MtType:  " T",
+			Series:  "D4F1",
+			Version: "30.0",
+			Axes:    "02",
+		},
+		Stat: model.StatInfo{
+			TmMode: 0,
+			Aut:    1,
+			Run:    0,
+		},
+		Axes: []Axis{
+			{Name: 'X', Position: 125000, PosDec: 3, Load: 12, ServoTemperature: 31, CoderTemperature: 29},
+			{Name: 'Z', Position: -40500, PosDec: 3, Load: 7, ServoTemperature: 30, CoderTemperature: 28},
+		},
+		Spindles: []Spindle{
+			{Load: 15, Speed: 2400, Override: 16383, Diag411: 12},
+		},
+		Params: map[int16]int32{
+			20:   100,
+			6711: 42,
+			6750: 3600 * 1200,
+			6751: 3600 * 300,
+			6753: 3600 * 120,
+			6757: 95,
+		},
+		ActualFeed:   150,
+		ContourFeed:  150,
+		FeedOverride: 100,
+		JogOverride:  50,
+		Path:         1,
+		MaxPath:      1,
+		ExecName:     "O0001",
+		ExecNumber:   1,
+		ExecBlock:    "G00 X100. Z0.;",
+		Programs: map[string]string{
+			"O0001": "%\nO0001\nG00 X100. Z0.;\nG01 Z-40.5 F0.2;\nM30;\n%",
+		},
+	}
+}
+
+// clone возвращает глубокую копию состояния.
+func (c *CNC) clone() *CNC {
+	cp := *c
+	cp.Axes = append([]Axis(nil), c.Axes...)
+	cp.Spindles = append([]Spindle(nil), c.Spindles...)
+	cp.Alarms = append([]Alarm(nil), c.Alarms...)
+	cp.ExtraPaths = make([]PathState, len(c.ExtraPaths))
+	for i, ps := range c.ExtraPaths {
+		ps.Axes = append([]Axis(nil), ps.Axes...)
+		ps.Spindles = append([]Spindle(nil), ps.Spindles...)
+		ps.Alarms = append([]Alarm(nil), ps.Alarms...)
+		cp.ExtraPaths[i] = ps
+	}
+	cp.Params = make(map[int16]int32, len(c.Params))
+	for k, v := range c.Params {
+		cp.Params[k] = v
+	}
+	cp.Diag = make(map[int16][]int32, len(c.Diag))
+	for k, v := range c.Diag {
+		cp.Diag[k] = append([]int32(nil), v...)
+	}
+	cp.Programs = make(map[string]string, len(c.Programs))
+	for k, v := range c.Programs {
+		cp.Programs[k] = v
+	}
+	return &cp
+}
+
+// pathView возвращает состояние станка с точки зрения канала path:
+// для каналов 2..N поля канала 1 заменяются данными из ExtraPaths.
+func (c *CNC) pathView(path int16) *CNC {
+	idx := int(path) - 2
+	if idx < 0 || idx >= len(c.ExtraPaths) {
+		return c
+	}
+	ps := c.ExtraPaths[idx]
+	view := *c
+	view.Stat = ps.Stat
+	view.Axes = ps.Axes
+	view.Spindles = ps.Spindles
+	view.Alarms = ps.Alarms
+	view.ExecName = ps.ExecName
+	view.ExecNumber = ps.ExecNumber
+	view.ExecBlock = ps.ExecBlock
+	return &view
+}
+
+// diagValue возвращает значение диагностики diagNo для оси с индексом idx.
+// Для вещественной диагностики (301) дополнительно возвращается позиция десятичной точки.
+func (c *CNC) diagValue(diagNo int16, idx int) (value int32, dec int32, known bool) {
+	switch diagNo {
+	case 301:
+		if idx < len(c.Axes) {
+			return c.Axes[idx].Load, c.Axes[idx].LoadDec, true
+		}
+		return 0, 0, true
+	case 308:
+		if idx < len(c.Axes) {
+			return c.Axes[idx].ServoTemperature, 0, true
+		}
+		return 0, 0, true
+	case 309:
+		if idx < len(c.Axes) {
+			return c.Axes[idx].CoderTemperature, 0, true
+		}
+		return 0, 0, true
+	case 4901:
+		if idx < len(c.Axes) {
+			return c.Axes[idx].PowerConsumption, 0, true
+		}
+		return 0, 0, true
+	case 411:
+		if idx < len(c.Spindles) {
+			return c.Spindles[idx].Diag411, 0, true
+		}
+		return 0, 0, true
+	}
+
+	values, ok := c.Diag[diagNo]
+	if !ok {
+		return 0, 0, false
+	}
+	if idx < len(values) {
+		return values[idx], 0, true
+	}
+	return 0, 0, true
+}
+
+// sortedParams возвращает номера параметров в диапазоне [start, end] по возрастанию.
+func (c *CNC) sortedParams(start, end int16) []int16 {
+	numbers := make([]int16, 0, len(c.Params))
+	for no := range c.Params {
+		if no >= start && no <= end {
+			numbers = append(numbers, no)
+		}
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+	return numbers
+}
@@ -0,0 +1,573 @@
+// Package fake содержит in-memory реализацию model.Backend для тестов без
+// libfwlib32 и реального станка. Состояние станка задается структурой CNC,
+// а ошибки FOCAS можно подставлять сценарием через Fail и SetHook.
+package fake
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+
+	. "github.com/iwtcode/fanucAdapter/focas/errcode"
+	"github.com/iwtcode/fanucAdapter/focas/model"
+)
+
+// Hook вызывается перед каждым вызовом FOCAS. Если он возвращает код,
+// отличный от EW_OK, вызов завершается с этим кодом.
+type Hook func(fn string, handle uint16) int16
+
+// uploadState хранит состояние выгрузки программы для одного хендла.
+type uploadState struct {
+	data   []byte
+	offset int
+}
+
+// Backend — сценарная in-memory реализация model.Backend.
+type Backend struct {
+	mu         sync.Mutex
+	cnc        *CNC
+	reachable  bool
+	nextHandle uint16
+	handles    map[uint16]bool
+	paths      map[uint16]int16 // текущий канал каждого хендла (cnc_setpath)
+	uploads    map[uint16]*uploadState
+	faults     map[string][]int16
+	hook       Hook
+	calls      []string
+	detail     [2]int16 // err_no и err_dtno для cnc_getdtailerr
+}
+
+// Убедимся, что Backend удовлетворяет интерфейсу model.Backend.
+var _ model.Backend = (*Backend)(nil)
+
+// New создает бэкенд, обслуживающий указанный виртуальный станок.
+// Если cnc равен nil, используется NewCNC.
+func New(cnc *CNC) *Backend {
+	if cnc == nil {
+		cnc = NewCNC()
+	}
+	return &Backend{
+		cnc:       cnc,
+		reachable: true,
+		handles:   make(map[uint16]bool),
+		paths:     make(map[uint16]int16),
+		uploads:   make(map[uint16]*uploadState),
+		faults:    make(map[string][]int16),
+	}
+}
+
+// Update изменяет состояние станка под блокировкой бэкенда.
+func (b *Backend) Update(f func(cnc *CNC)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	f(b.cnc)
+}
+
+// State возвращает копию текущего состояния станка.
+func (b *Backend) State() *CNC {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.cnc.clone()
+}
+
+// Fail ставит в очередь коды возврата для функции fn (например, "cnc_statinfo").
+// Каждый следующий вызов fn забирает из очереди один код.
+func (b *Backend) Fail(fn string, rcs ...int16) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.faults[fn] = append(b.faults[fn], rcs...)
+}
+
+// SetErrorDetail задает подробности ошибки, возвращаемые cnc_getdtailerr.
+func (b *Backend) SetErrorDetail(errNo, errDtNo int16) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.detail = [2]int16{errNo, errDtNo}
+}
+
+// SetHook устанавливает функцию, вызываемую перед каждым вызовом FOCAS.
+func (b *Backend) SetHook(hook Hook) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.hook = hook
+}
+
+// SetReachable управляет доступностью станка: недоступный станок
+// отклоняет новые подключения с EW_SOCKET.
+func (b *Backend) SetReachable(reachable bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.reachable = reachable
+}
+
+// DropConnections делает все выданные хендлы недействительными,
+// имитируя разрыв соединения со станком.
+func (b *Backend) DropConnections() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handles = make(map[uint16]bool)
+	b.paths = make(map[uint16]int16)
+	b.uploads = make(map[uint16]*uploadState)
+}
+
+// Calls возвращает имена всех выполненных вызовов FOCAS по порядку.
+func (b *Backend) Calls() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.calls...)
+}
+
+// enter регистрирует вызов и возвращает код ошибки, если вызов должен завершиться неудачей.
+// Вызывается под b.mu.
+func (b *Backend) enter(fn string, handle uint16) int16 {
+	b.calls = append(b.calls, fn)
+
+	if queue := b.faults[fn]; len(queue) > 0 {
+		rc := queue[0]
+		b.faults[fn] = queue[1:]
+		if rc != EW_OK {
+			return rc
+		}
+	}
+
+	if b.hook != nil {
+		if rc := b.hook(fn, handle); rc != EW_OK {
+			return rc
+		}
+	}
+
+	if fn != "cnc_startupprocess" && fn != "cnc_allclibhndl3" && !b.handles[handle] {
+		return EW_HANDLE
+	}
+	return EW_OK
+}
+
+func (b *Backend) Startup(mode uint16, logPath string) int16 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.enter("cnc_startupprocess", 0)
+}
+
+func (b *Backend) AllcLibHndl3(ip string, port uint16, timeoutMs int32) (uint16, int16) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rc := b.enter("cnc_allclibhndl3", 0); rc != EW_OK {
+		return 0, rc
+	}
+	if !b.reachable {
+		return 0, EW_SOCKET
+	}
+	b.nextHandle++
+	if b.nextHandle == 0 {
+		b.nextHandle = 1
+	}
+	b.handles[b.nextHandle] = true
+	b.paths[b.nextHandle] = b.cnc.Path
+	return b.nextHandle, EW_OK
+}
+
+func (b *Backend) FreeLibHndl(handle uint16) int16 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rc := b.enter("cnc_freelibhndl", handle); rc != EW_OK {
+		return rc
+	}
+	delete(b.handles, handle)
+	delete(b.paths, handle)
+	delete(b.uploads, handle)
+	return EW_OK
+}
+
+func (b *Backend) SysInfo(handle uint16) (model.SysInfo, int16) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rc := b.enter("cnc_sysinfo", handle); rc != EW_OK {
+		return model.SysInfo{}, rc
+	}
+	return b.cnc.SysInfo, EW_OK
+}
+
+func (b *Backend) StatInfo(handle uint16) (model.StatInfo, int16) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rc := b.enter("cnc_statinfo", handle); rc != EW_OK {
+		return model.StatInfo{}, rc
+	}
+	cnc := b.cnc.pathView(b.paths[handle])
+	return cnc.Stat, EW_OK
+}
+
+func (b *Backend) ExePrgName(handle uint16) (string, int64, int16) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rc := b.enter("cnc_exeprgname", handle); rc != EW_OK {
+		return "", 0, rc
+	}
+	cnc := b.cnc.pathView(b.paths[handle])
+	return cnc.ExecName, cnc.ExecNumber, EW_OK
+}
+
+func (b *Backend) RdExecProg(handle uint16, length *uint16, blknum *int16, data []byte) int16 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rc := b.enter("cnc_rdexecprog", handle); rc != EW_OK {
+		return rc
+	}
+	cnc := b.cnc.pathView(b.paths[handle])
+	n := copy(data[:min(int(*length), len(data))], cnc.ExecBlock)
+	*length = uint16(n)
+	*blknum = 1
+	return EW_OK
+}
+
+func (b *Backend) RdPosition(handle uint16, posType int16, dataNum *int16, buf []byte) int16 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rc := b.enter("cnc_rdposition", handle); rc != EW_OK {
+		return rc
+	}
+	cnc := b.cnc.pathView(b.paths[handle])
+
+	const odbposSize = 48
+	const poselmSize = 12
+	n := min(int(*dataNum), len(cnc.Axes), len(buf)/odbposSize)
+	for i := 0; i < n; i++ {
+		axis := cnc.Axes[i]
+		// absolute, machine, relative, distance — заполняем одинаково
+		for p := 0; p < 4; p++ {
+			off := i*odbposSize + p*poselmSize
+			putInt32(buf[off:], axis.Position)
+			putInt16(buf[off+4:], axis.PosDec)
+			putInt16(buf[off+8:], 1)
+			buf[off+10] = axis.Name
+			buf[off+11] = axis.Suffix
+		}
+	}
+	*dataNum = int16(n)
+	return EW_OK
+}
+
+func (b *Backend) Diagnoss(handle uint16, diagNo int16, axisNo int16, length int16, buf []byte) int16 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rc := b.enter("cnc_diagnoss", handle); rc != EW_OK {
+		return rc
+	}
+	cnc := b.cnc.pathView(b.paths[handle])
+	if _, _, known := cnc.diagValue(diagNo, 0); !known {
+		return EW_NUMBER
+	}
+
+	const headerSize = 4
+	putInt16(buf[0:], diagNo)
+	putInt16(buf[2:], axisNo)
+
+	if axisNo != -1 {
+		// Номер оси 0 — диагностика без привязки к оси
+		value, dec, _ := cnc.diagValue(diagNo, max(int(axisNo)-1, 0))
+		putDiagElement(buf[headerSize:length], value, dec)
+		return EW_OK
+	}
+
+	maxAxes := int(cnc.SysInfo.MaxAxis)
+	if maxAxes <= 0 {
+		return EW_LENGTH
+	}
+	elemSize := (int(length) - headerSize) / maxAxes
+	if elemSize <= 0 {
+		return EW_LENGTH
+	}
+	for i := 0; i < maxAxes; i++ {
+		off := headerSize + i*elemSize
+		value, dec, _ := cnc.diagValue(diagNo, i)
+		putDiagElement(buf[off:off+elemSize], value, dec)
+	}
+	return EW_OK
+}
+
+func (b *Backend) RdSpMeter(handle uint16, spType int16, num *int16, buf []byte) int16 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rc := b.enter("cnc_rdspmeter", handle); rc != EW_OK {
+		return rc
+	}
+	cnc := b.cnc.pathView(b.paths[handle])
+
+	const odbsploadSize = 24
+	n := min(int(*num), len(cnc.Spindles), len(buf)/odbsploadSize)
+	for i := 0; i < n; i++ {
+		sp := cnc.Spindles[i]
+		off := i * odbsploadSize
+		putInt32(buf[off:], sp.Load)
+		putInt16(buf[off+4:], sp.LoadDec)
+		putInt32(buf[off+12:], sp.Speed)
+		putInt16(buf[off+16:], sp.SpeedDec)
+	}
+	*num = int16(n)
+	return EW_OK
+}
+
+func (b *Backend) RdSpLoad(handle uint16, spNo int16, buf []byte) int16 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rc := b.enter("cnc_rdspload", handle); rc != EW_OK {
+		return rc
+	}
+	cnc := b.cnc.pathView(b.paths[handle])
+
+	putInt16(buf[0:], spNo)
+	for i, sp := range cnc.Spindles {
+		off := 4 + i*2
+		if off+2 > len(buf) {
+			break
+		}
+		putInt16(buf[off:], sp.Override)
+	}
+	return EW_OK
+}
+
+func (b *Backend) RdSpeed(handle uint16, spType int16, buf []byte) int16 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rc := b.enter("cnc_rdspeed", handle); rc != EW_OK {
+		return rc
+	}
+	putInt32(buf[0:], b.cnc.ActualFeed)
+	putInt16(buf[4:], b.cnc.ActualFeedDec)
+	buf[10] = 'F'
+	return EW_OK
+}
+
+func (b *Backend) Actf(handle uint16, buf []byte) int16 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rc := b.enter("cnc_actf", handle); rc != EW_OK {
+		return rc
+	}
+	putInt32(buf[4:], b.cnc.ContourFeed)
+	return EW_OK
+}
+
+func (b *Backend) RdTofs(handle uint16, number int16, ofsType int16, length int16, buf []byte) int16 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rc := b.enter("cnc_rdtofs", handle); rc != EW_OK {
+		return rc
+	}
+
+	var value int32
+	switch ofsType {
+	case 0:
+		value = b.cnc.FeedOverride
+	case 1:
+		value = b.cnc.JogOverride
+	default:
+		return EW_ATTRIB
+	}
+	putInt16(buf[0:], number)
+	putInt16(buf[2:], ofsType)
+	putInt32(buf[4:], value)
+	return EW_OK
+}
+
+func (b *Backend) RdAlmMsg(handle uint16, almType int16, num *int16, buf []byte) int16 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rc := b.enter("cnc_rdalmmsg", handle); rc != EW_OK {
+		return rc
+	}
+	cnc := b.cnc.pathView(b.paths[handle])
+
+	const alarmDataSize = 76
+	const maxMsgLen = 64
+	count := 0
+	for _, alarm := range cnc.Alarms {
+		if count >= int(*num) || (count+1)*alarmDataSize > len(buf) {
+			break
+		}
+		if almType != -1 && alarm.Type != almType {
+			continue
+		}
+		off := count * alarmDataSize
+		msg := alarm.Message
+		if len(msg) > maxMsgLen {
+			msg = msg[:maxMsgLen]
+		}
+		putInt32(buf[off:], alarm.Number)
+		putInt16(buf[off+4:], alarm.Type)
+		putInt16(buf[off+6:], alarm.Axis)
+		putInt16(buf[off+10:], int16(len(msg)))
+		copy(buf[off+12:off+12+maxMsgLen], msg)
+		count++
+	}
+	*num = int16(count)
+	return EW_OK
+}
+
+func (b *Backend) RdParam(handle uint16, prmNo int16, axisNo int16, length int16, buf []byte) int16 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rc := b.enter("cnc_rdparam", handle); rc != EW_OK {
+		return rc
+	}
+
+	value, ok := b.cnc.Params[prmNo]
+	if !ok {
+		return EW_NUMBER
+	}
+	putInt16(buf[0:], prmNo)
+	putInt16(buf[2:], axisNo)
+	putInt32(buf[4:], value)
+	return EW_OK
+}
+
+func (b *Backend) RdParar(handle uint16, start *int16, axisNo int16, end *int16, length *int16, buf []byte) int16 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rc := b.enter("cnc_rdparar", handle); rc != EW_OK {
+		return rc
+	}
+
+	const paramSize = 8
+	limit := min(int(*length), len(buf))
+	written := 0
+	last := *start
+	for _, no := range b.cnc.sortedParams(*start, *end) {
+		if written+paramSize > limit {
+			break
+		}
+		putInt16(buf[written:], no)
+		putInt16(buf[written+2:], 0)
+		putInt32(buf[written+4:], b.cnc.Params[no])
+		written += paramSize
+		last = no
+	}
+	*end = last
+	*length = int16(written)
+	return EW_OK
+}
+
+func (b *Backend) GetPath(handle uint16) (int16, int16, int16) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rc := b.enter("cnc_getpath", handle); rc != EW_OK {
+		return 0, 0, rc
+	}
+	return b.paths[handle], b.cnc.MaxPath, EW_OK
+}
+
+func (b *Backend) SetPath(handle uint16, pathNo int16) int16 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rc := b.enter("cnc_setpath", handle); rc != EW_OK {
+		return rc
+	}
+	if pathNo < 1 || pathNo > b.cnc.MaxPath {
+		return EW_DATA
+	}
+	b.paths[handle] = pathNo
+	return EW_OK
+}
+
+func (b *Backend) UpStart(handle uint16, progNum int16) int16 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rc := b.enter("cnc_upstart", handle); rc != EW_OK {
+		return rc
+	}
+	return b.startUpload(handle, programName(int64(progNum)))
+}
+
+func (b *Backend) UpStart4(handle uint16, upType int16, fileName string) int16 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rc := b.enter("cnc_upstart4", handle); rc != EW_OK {
+		return rc
+	}
+	if rc := b.startUpload(handle, fileName); rc == EW_OK {
+		return rc
+	}
+	// Допускаем поиск по имени файла без пути
+	return b.startUpload(handle, fileName[strings.LastIndex(fileName, "/")+1:])
+}
+
+func (b *Backend) Upload(handle uint16, buf []byte, length *uint16) int16 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rc := b.enter("cnc_upload", handle); rc != EW_OK {
+		return rc
+	}
+
+	up, ok := b.uploads[handle]
+	if !ok {
+		return EW_FUNC
+	}
+
+	const odbupHeaderSize = 4
+	n := copy(buf[odbupHeaderSize:odbupHeaderSize+min(int(*length), len(buf)-odbupHeaderSize)], up.data[up.offset:])
+	up.offset += n
+	*length = uint16(n)
+	return EW_OK
+}
+
+func (b *Backend) UpEnd(handle uint16) int16 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rc := b.enter("cnc_upend", handle); rc != EW_OK {
+		return rc
+	}
+	delete(b.uploads, handle)
+	return EW_OK
+}
+
+func (b *Backend) GetDtailErr(handle uint16) (int16, int16, int16) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rc := b.enter("cnc_getdtailerr", handle); rc != EW_OK {
+		return 0, 0, rc
+	}
+	return b.detail[0], b.detail[1], EW_OK
+}
+
+// startUpload начинает выгрузку программы с указанным ключом. Вызывается под b.mu.
+func (b *Backend) startUpload(handle uint16, key string) int16 {
+	if _, busy := b.uploads[handle]; busy {
+		return EW_BUSY
+	}
+	source, ok := b.cnc.Programs[key]
+	if !ok {
+		return EW_DATA
+	}
+	b.uploads[handle] = &uploadState{data: []byte(source)}
+	return EW_OK
+}
+
+// programName возвращает имя программы в формате "O0001".
+func programName(number int64) string {
+	return fmt.Sprintf("O%04d", number)
+}
+
+// putDiagElement записывает значение диагностики в элемент буфера ODBDGN.
+// Тип данных определяется размером элемента: байт, слово, двойное слово или REALDATA.
+func putDiagElement(buf []byte, value int32, dec int32) {
+	switch {
+	case len(buf) >= 8:
+		putInt32(buf[0:], value)
+		putInt32(buf[4:], dec)
+	case len(buf) >= 4:
+		putInt32(buf[0:], value)
+	case len(buf) >= 2:
+		putInt16(buf[0:], int16(value))
+	case len(buf) == 1:
+		buf[0] = byte(value)
+	}
+}
+
+func putInt16(buf []byte, v int16) {
+	binary.LittleEndian.PutUint16(buf, uint16(v))
+}
+
+func putInt32(buf []byte, v int32) {
+	binary.LittleEndian.PutUint32(buf, uint32(v))
+}
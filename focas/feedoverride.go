@@ -1,48 +1,38 @@
 package focas
 
-/*
-#cgo CFLAGS: -I${SRCDIR}
-#cgo LDFLAGS: -L${SRCDIR} -lfwlib32
-#cgo linux LDFLAGS: -Wl,-rpath,${SRCDIR}
-
-#include "c_helpers.h"
-*/
-import "C"
-
 import (
+	"context"
 	"encoding/binary"
 	"fmt"
-	"unsafe"
 
 	. "github.com/iwtcode/fanucAdapter/focas/errcode"
 )
 
 // ReadFeedOverride считывает процент коррекции подачи (F%).
 // Используется FOCAS функция cnc_rdtofs.
-func (a *FocasAdapter) ReadFeedOverride() (int32, error) {
+func (a *FocasAdapter) ReadFeedOverride(ctx context.Context) (int32, error) {
 	a.logger.Debug("[ReadFeedOverride] Начато чтение коррекции подачи с помощью cnc_rdtofs.")
 
 	// Размер структуры ODBTOFS: datano(2) + type(2) + data(4) = 8 байт
 	const dataLength = 8
 	buffer := make([]byte, dataLength)
-	var rc C.short
 
-	err := a.CallWithReconnect(func(handle uint16) (int16, error) {
-		rc = C.go_cnc_rdtofs(
-			C.ushort(handle),
+	err := a.CallWithReconnect(ctx, func(handle uint16) (int16, error) {
+		rc := a.backend.RdTofs(
+			handle,
 			1, // номер 1 для коррекции подачи (F%)
 			0, // тип 0
-			C.short(dataLength),
-			(*C.ODBTOFS)(unsafe.Pointer(&buffer[0])),
+			dataLength,
+			buffer,
 		)
 
-		a.logger.Debugf("[ReadFeedOverride] Вызов go_cnc_rdtofs. Код возврата (rc): %d", rc)
+		a.logger.Debugf("[ReadFeedOverride] Вызов cnc_rdtofs. Код возврата (rc): %d", rc)
 		a.logger.Debugf("[ReadFeedOverride] Сырой буфер ответа (hex): %x", buffer)
 
-		if int16(rc) != EW_OK {
-			return int16(rc), fmt.Errorf("cnc_rdtofs failed with error code: %d", int16(rc))
+		if rc != EW_OK {
+			return rc, fmt.Errorf("cnc_rdtofs failed with error code: %d", rc)
 		}
-		return int16(rc), nil
+		return rc, nil
 	})
 
 	if err != nil {
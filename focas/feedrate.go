@@ -1,19 +1,10 @@
 package focas
 
-/*
-#cgo CFLAGS: -I${SRCDIR}
-#cgo LDFLAGS: -L${SRCDIR} -lfwlib32
-#cgo linux LDFLAGS: -Wl,-rpath,${SRCDIR}
-
-#include "c_helpers.h"
-*/
-import "C"
-
 import (
+	"context"
 	"encoding/binary"
 	"fmt"
 	"math"
-	"unsafe"
 
 	. "github.com/iwtcode/fanucAdapter/focas/errcode"
 	"github.com/iwtcode/fanucAdapter/models"
@@ -21,7 +12,7 @@ import (
 
 // ReadFeedData считывает фактическую скорость подачи и процент коррекции.
 // Реализация основана на C# коде, считывающем данные с помощью cnc_rdspeed и cnc_rdparam.
-func (a *FocasAdapter) ReadFeedData() (*models.FeedInfo, error) {
+func (a *FocasAdapter) ReadFeedData(ctx context.Context) (*models.FeedInfo, error) {
 	a.logger.Debug("[ReadFeedData] Начато чтение данных о скорости подачи и коррекции.")
 	feedInfo := &models.FeedInfo{}
 	var finalErr error
@@ -29,18 +20,17 @@ func (a *FocasAdapter) ReadFeedData() (*models.FeedInfo, error) {
 	// 1. Чтение фактической скорости подачи с помощью cnc_rdspeed
 	// Размер структуры ODBSPEED примерно 32 байта.
 	speedBuffer := make([]byte, 32)
-	var rcSpeed C.short
 
-	errSpeed := a.CallWithReconnect(func(handle uint16) (int16, error) {
-		rcSpeed = C.go_cnc_rdspeed(
-			C.ushort(handle),
+	errSpeed := a.CallWithReconnect(ctx, func(handle uint16) (int16, error) {
+		rcSpeed := a.backend.RdSpeed(
+			handle,
 			0, // ИСПРАВЛЕНО: Тип 0 для фактической скорости подачи (был 2)
-			(*C.ODBSPEED)(unsafe.Pointer(&speedBuffer[0])),
+			speedBuffer,
 		)
-		if int16(rcSpeed) != EW_OK {
-			return int16(rcSpeed), fmt.Errorf("cnc_rdspeed failed: rc=%d", int16(rcSpeed))
+		if rcSpeed != EW_OK {
+			return rcSpeed, a.callError("cnc_rdspeed", handle, rcSpeed)
 		}
-		return int16(rcSpeed), nil
+		return rcSpeed, nil
 	})
 
 	if errSpeed != nil {
@@ -67,20 +57,19 @@ func (a *FocasAdapter) ReadFeedData() (*models.FeedInfo, error) {
 	const axisNum = 0   // Номер оси (0 для общих параметров)
 	const length = 8    // Длина структуры данных для одного параметра
 	paramBuffer := make([]byte, length)
-	var rcParam C.short
 
-	errParam := a.CallWithReconnect(func(handle uint16) (int16, error) {
-		rcParam = C.go_cnc_rdparam(
-			C.ushort(handle),
-			C.short(paramNum),
-			C.short(axisNum),
-			C.short(length),
-			(*C.IODBPSD)(unsafe.Pointer(&paramBuffer[0])),
+	errParam := a.CallWithReconnect(ctx, func(handle uint16) (int16, error) {
+		rcParam := a.backend.RdParam(
+			handle,
+			paramNum,
+			axisNum,
+			length,
+			paramBuffer,
 		)
-		if int16(rcParam) != EW_OK {
-			return int16(rcParam), fmt.Errorf("cnc_rdparam для коррекции подачи (параметр %d) завершился с ошибкой: rc=%d", paramNum, int16(rcParam))
+		if rcParam != EW_OK {
+			return rcParam, fmt.Errorf("коррекция подачи (параметр %d): %w", paramNum, a.callError("cnc_rdparam", handle, rcParam))
 		}
-		return int16(rcParam), nil
+		return rcParam, nil
 	})
 
 	if errParam != nil {
@@ -1,20 +1,11 @@
 package focas
 
-/*
-#cgo CFLAGS: -I${SRCDIR}
-#cgo LDFLAGS: -L${SRCDIR} -lfwlib32
-#cgo linux LDFLAGS: -Wl,-rpath,${SRCDIR}
-
-#include "c_helpers.h"
-*/
-import "C"
-
 import (
+	"context"
 	"encoding/binary"
 	"fmt"
 	"math"
 	"strings"
-	"unsafe"
 
 	. "github.com/iwtcode/fanucAdapter/focas/errcode"
 )
@@ -24,22 +15,15 @@ func trimNull(s string) string {
 }
 
 // readDiagnosisInternal - базовый метод для чтения диагностики
-func (a *FocasAdapter) readDiagnosisInternal(diagNo int16, axisNo int16, length int16) ([]byte, error) {
+func (a *FocasAdapter) readDiagnosisInternal(ctx context.Context, diagNo int16, axisNo int16, length int16) ([]byte, error) {
 	buffer := make([]byte, length)
-	var rc C.short
-
-	err := a.CallWithReconnect(func(handle uint16) (int16, error) {
-		rc = C.go_cnc_diagnoss(
-			C.ushort(handle),
-			C.short(diagNo),
-			C.short(axisNo),
-			C.short(length),
-			(*C.ODBDGN)(unsafe.Pointer(&buffer[0])),
-		)
-		if int16(rc) != EW_OK {
-			return int16(rc), fmt.Errorf("cnc_diagnoss for diagNo %d failed: rc=%d", diagNo, int16(rc))
+
+	err := a.CallWithReconnect(ctx, func(handle uint16) (int16, error) {
+		rc := a.backend.Diagnoss(handle, diagNo, axisNo, length, buffer)
+		if rc != EW_OK {
+			return rc, fmt.Errorf("diagNo %d: %w", diagNo, a.callError("cnc_diagnoss", handle, rc))
 		}
-		return int16(rc), nil
+		return rc, nil
 	})
 
 	if err != nil {
@@ -48,32 +32,32 @@ func (a *FocasAdapter) readDiagnosisInternal(diagNo int16, axisNo int16, length
 	return buffer, nil
 }
 
-func (a *FocasAdapter) ReadDiagnosisByte(diagNo int16, axisNo int16) (int32, error) {
-	buf, err := a.readDiagnosisInternal(diagNo, axisNo, 5) // 4 header + 1 data
+func (a *FocasAdapter) ReadDiagnosisByte(ctx context.Context, diagNo int16, axisNo int16) (int32, error) {
+	buf, err := a.readDiagnosisInternal(ctx, diagNo, axisNo, 5) // 4 header + 1 data
 	if err != nil {
 		return 0, err
 	}
 	return int32(buf[4]), nil
 }
 
-func (a *FocasAdapter) ReadDiagnosisWord(diagNo int16, axisNo int16) (int32, error) {
-	buf, err := a.readDiagnosisInternal(diagNo, axisNo, 6) // 4 header + 2 data
+func (a *FocasAdapter) ReadDiagnosisWord(ctx context.Context, diagNo int16, axisNo int16) (int32, error) {
+	buf, err := a.readDiagnosisInternal(ctx, diagNo, axisNo, 6) // 4 header + 2 data
 	if err != nil {
 		return 0, err
 	}
 	return int32(binary.LittleEndian.Uint16(buf[4:6])), nil
 }
 
-func (a *FocasAdapter) ReadDiagnosisDoubleWord(diagNo int16, axisNo int16) (int64, error) {
-	buf, err := a.readDiagnosisInternal(diagNo, axisNo, 8) // 4 header + 4 data
+func (a *FocasAdapter) ReadDiagnosisDoubleWord(ctx context.Context, diagNo int16, axisNo int16) (int64, error) {
+	buf, err := a.readDiagnosisInternal(ctx, diagNo, axisNo, 8) // 4 header + 4 data
 	if err != nil {
 		return 0, err
 	}
 	return int64(binary.LittleEndian.Uint32(buf[4:8])), nil
 }
 
-func (a *FocasAdapter) ReadDiagnosisReal(diagNo int16, axisNo int16) (float64, error) {
-	buf, err := a.readDiagnosisInternal(diagNo, axisNo, 12) // 4 header + 8 data
+func (a *FocasAdapter) ReadDiagnosisReal(ctx context.Context, diagNo int16, axisNo int16) (float64, error) {
+	buf, err := a.readDiagnosisInternal(ctx, diagNo, axisNo, 12) // 4 header + 8 data
 	if err != nil {
 		return 0, err
 	}
@@ -83,11 +67,11 @@ func (a *FocasAdapter) ReadDiagnosisReal(diagNo int16, axisNo int16) (float64, e
 }
 
 // ReadDiagnosisByteAllAxes читает байтовую диагностику для всех осей сразу.
-func (a *FocasAdapter) ReadDiagnosisByteAllAxes(diagNo int16, maxAxes int16) ([]int32, error) {
+func (a *FocasAdapter) ReadDiagnosisByteAllAxes(ctx context.Context, diagNo int16, maxAxes int16) ([]int32, error) {
 	// Header (4 bytes) + (1 byte * maxAxes)
 	length := int16(4 + 1*int(maxAxes))
 
-	buf, err := a.readDiagnosisInternal(diagNo, -1, length)
+	buf, err := a.readDiagnosisInternal(ctx, diagNo, -1, length)
 	if err != nil {
 		return nil, err
 	}
@@ -103,11 +87,11 @@ func (a *FocasAdapter) ReadDiagnosisByteAllAxes(diagNo int16, maxAxes int16) ([]
 }
 
 // ReadDiagnosisWordAllAxes читает 2-байтовую (Word) диагностику для всех осей.
-func (a *FocasAdapter) ReadDiagnosisWordAllAxes(diagNo int16, maxAxes int16) ([]int32, error) {
+func (a *FocasAdapter) ReadDiagnosisWordAllAxes(ctx context.Context, diagNo int16, maxAxes int16) ([]int32, error) {
 	// Header (4 bytes) + (2 bytes * maxAxes)
 	length := int16(4 + 2*int(maxAxes))
 
-	buf, err := a.readDiagnosisInternal(diagNo, -1, length)
+	buf, err := a.readDiagnosisInternal(ctx, diagNo, -1, length)
 	if err != nil {
 		return nil, err
 	}
@@ -123,11 +107,11 @@ func (a *FocasAdapter) ReadDiagnosisWordAllAxes(diagNo int16, maxAxes int16) ([]
 }
 
 // ReadDiagnosisDoubleWordAllAxes читает 4-байтовую диагностику для всех осей.
-func (a *FocasAdapter) ReadDiagnosisDoubleWordAllAxes(diagNo int16, maxAxes int16) ([]int64, error) {
+func (a *FocasAdapter) ReadDiagnosisDoubleWordAllAxes(ctx context.Context, diagNo int16, maxAxes int16) ([]int64, error) {
 	// Header (4 bytes) + (4 bytes * maxAxes)
 	length := int16(4 + 4*int(maxAxes))
 
-	buf, err := a.readDiagnosisInternal(diagNo, -1, length)
+	buf, err := a.readDiagnosisInternal(ctx, diagNo, -1, length)
 	if err != nil {
 		return nil, err
 	}
@@ -143,11 +127,11 @@ func (a *FocasAdapter) ReadDiagnosisDoubleWordAllAxes(diagNo int16, maxAxes int1
 }
 
 // ReadDiagnosisRealAllAxes читает Real диагностику (значение + дес. точка) для всех осей.
-func (a *FocasAdapter) ReadDiagnosisRealAllAxes(diagNo int16, maxAxes int16) ([]float64, error) {
+func (a *FocasAdapter) ReadDiagnosisRealAllAxes(ctx context.Context, diagNo int16, maxAxes int16) ([]float64, error) {
 	// Header (4 bytes) + (8 bytes * maxAxes) -> RealData = 4 bytes val + 4 bytes dec
 	length := int16(4 + 8*int(maxAxes))
 
-	buf, err := a.readDiagnosisInternal(diagNo, -1, length)
+	buf, err := a.readDiagnosisInternal(ctx, diagNo, -1, length)
 	if err != nil {
 		return nil, err
 	}
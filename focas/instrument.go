@@ -0,0 +1,189 @@
+package focas
+
+import (
+	"sync"
+	"time"
+
+	. "github.com/iwtcode/fanucAdapter/focas/errcode"
+	"github.com/iwtcode/fanucAdapter/focas/model"
+)
+
+// Instrument оборачивает backend так, что каждый вызов FOCAS и каждое ожидание
+// блокировки вызовов сообщаются observer. Обертка использует ту же блокировку,
+// что и исходный бэкенд (см. callLock).
+func Instrument(backend model.Backend, observer model.CallObserver) model.Backend {
+	return &instrumentedBackend{
+		backend:  backend,
+		observer: observer,
+		lock:     &observedLock{lock: callLock(backend), observer: observer},
+	}
+}
+
+// instrumentedBackend измеряет вызовы вложенного бэкенда.
+type instrumentedBackend struct {
+	backend  model.Backend
+	observer model.CallObserver
+	lock     *observedLock
+}
+
+// observedLock измеряет время ожидания блокировки вызовов.
+type observedLock struct {
+	lock     sync.Locker
+	observer model.CallObserver
+}
+
+func (l *observedLock) Lock() {
+	start := time.Now()
+	l.lock.Lock()
+	l.observer.ObserveLockWait(time.Since(start))
+}
+
+func (l *observedLock) Unlock() {
+	l.lock.Unlock()
+}
+
+// CallLock реализует model.CallLocker: обертка всегда сериализует вызовы той же
+// блокировкой, что и вложенный бэкенд, и измеряет ожидание.
+func (b *instrumentedBackend) CallLock() sync.Locker {
+	return b.lock
+}
+
+// Probe реализует model.Prober, если его реализует вложенный бэкенд.
+func (b *instrumentedBackend) Probe(ip string, port uint16) int16 {
+	if p, ok := b.backend.(model.Prober); ok {
+		return p.Probe(ip, port)
+	}
+	return EW_OK
+}
+
+// observe сообщает о завершении вызова function, начатого в start.
+func (b *instrumentedBackend) observe(function string, start time.Time, rc int16) int16 {
+	b.observer.ObserveCall(function, rc, time.Since(start))
+	return rc
+}
+
+func (b *instrumentedBackend) Startup(mode uint16, logPath string) int16 {
+	start := time.Now()
+	return b.observe("cnc_startupprocess", start, b.backend.Startup(mode, logPath))
+}
+
+func (b *instrumentedBackend) AllcLibHndl3(ip string, port uint16, timeoutMs int32) (uint16, int16) {
+	start := time.Now()
+	handle, rc := b.backend.AllcLibHndl3(ip, port, timeoutMs)
+	return handle, b.observe("cnc_allclibhndl3", start, rc)
+}
+
+func (b *instrumentedBackend) FreeLibHndl(handle uint16) int16 {
+	start := time.Now()
+	return b.observe("cnc_freelibhndl", start, b.backend.FreeLibHndl(handle))
+}
+
+func (b *instrumentedBackend) SysInfo(handle uint16) (model.SysInfo, int16) {
+	start := time.Now()
+	info, rc := b.backend.SysInfo(handle)
+	return info, b.observe("cnc_sysinfo", start, rc)
+}
+
+func (b *instrumentedBackend) StatInfo(handle uint16) (model.StatInfo, int16) {
+	start := time.Now()
+	stat, rc := b.backend.StatInfo(handle)
+	return stat, b.observe("cnc_statinfo", start, rc)
+}
+
+func (b *instrumentedBackend) ExePrgName(handle uint16) (string, int64, int16) {
+	start := time.Now()
+	name, number, rc := b.backend.ExePrgName(handle)
+	return name, number, b.observe("cnc_exeprgname", start, rc)
+}
+
+func (b *instrumentedBackend) RdExecProg(handle uint16, length *uint16, blknum *int16, data []byte) int16 {
+	start := time.Now()
+	return b.observe("cnc_rdexecprog", start, b.backend.RdExecProg(handle, length, blknum, data))
+}
+
+func (b *instrumentedBackend) RdPosition(handle uint16, posType int16, dataNum *int16, buf []byte) int16 {
+	start := time.Now()
+	return b.observe("cnc_rdposition", start, b.backend.RdPosition(handle, posType, dataNum, buf))
+}
+
+func (b *instrumentedBackend) Diagnoss(handle uint16, diagNo int16, axisNo int16, length int16, buf []byte) int16 {
+	start := time.Now()
+	return b.observe("cnc_diagnoss", start, b.backend.Diagnoss(handle, diagNo, axisNo, length, buf))
+}
+
+func (b *instrumentedBackend) RdSpMeter(handle uint16, spType int16, num *int16, buf []byte) int16 {
+	start := time.Now()
+	return b.observe("cnc_rdspmeter", start, b.backend.RdSpMeter(handle, spType, num, buf))
+}
+
+func (b *instrumentedBackend) RdSpLoad(handle uint16, spNo int16, buf []byte) int16 {
+	start := time.Now()
+	return b.observe("cnc_rdspload", start, b.backend.RdSpLoad(handle, spNo, buf))
+}
+
+func (b *instrumentedBackend) RdSpeed(handle uint16, spType int16, buf []byte) int16 {
+	start := time.Now()
+	return b.observe("cnc_rdspeed", start, b.backend.RdSpeed(handle, spType, buf))
+}
+
+func (b *instrumentedBackend) Actf(handle uint16, buf []byte) int16 {
+	start := time.Now()
+	return b.observe("cnc_actf", start, b.backend.Actf(handle, buf))
+}
+
+func (b *instrumentedBackend) RdTofs(handle uint16, number int16, ofsType int16, length int16, buf []byte) int16 {
+	start := time.Now()
+	return b.observe("cnc_rdtofs", start, b.backend.RdTofs(handle, number, ofsType, length, buf))
+}
+
+func (b *instrumentedBackend) RdAlmMsg(handle uint16, almType int16, num *int16, buf []byte) int16 {
+	start := time.Now()
+	return b.observe("cnc_rdalmmsg", start, b.backend.RdAlmMsg(handle, almType, num, buf))
+}
+
+func (b *instrumentedBackend) RdParam(handle uint16, prmNo int16, axisNo int16, length int16, buf []byte) int16 {
+	start := time.Now()
+	return b.observe("cnc_rdparam", start, b.backend.RdParam(handle, prmNo, axisNo, length, buf))
+}
+
+func (b *instrumentedBackend) RdParar(handle uint16, startNo *int16, axisNo int16, end *int16, length *int16, buf []byte) int16 {
+	start := time.Now()
+	return b.observe("cnc_rdparar", start, b.backend.RdParar(handle, startNo, axisNo, end, length, buf))
+}
+
+func (b *instrumentedBackend) GetPath(handle uint16) (int16, int16, int16) {
+	start := time.Now()
+	path, maxPath, rc := b.backend.GetPath(handle)
+	return path, maxPath, b.observe("cnc_getpath", start, rc)
+}
+
+func (b *instrumentedBackend) SetPath(handle uint16, pathNo int16) int16 {
+	start := time.Now()
+	return b.observe("cnc_setpath", start, b.backend.SetPath(handle, pathNo))
+}
+
+func (b *instrumentedBackend) UpStart(handle uint16, progNum int16) int16 {
+	start := time.Now()
+	return b.observe("cnc_upstart", start, b.backend.UpStart(handle, progNum))
+}
+
+func (b *instrumentedBackend) UpStart4(handle uint16, upType int16, fileName string) int16 {
+	start := time.Now()
+	return b.observe("cnc_upstart4", start, b.backend.UpStart4(handle, upType, fileName))
+}
+
+func (b *instrumentedBackend) Upload(handle uint16, buf []byte, length *uint16) int16 {
+	start := time.Now()
+	return b.observe("cnc_upload", start, b.backend.Upload(handle, buf, length))
+}
+
+func (b *instrumentedBackend) UpEnd(handle uint16) int16 {
+	start := time.Now()
+	return b.observe("cnc_upend", start, b.backend.UpEnd(handle))
+}
+
+func (b *instrumentedBackend) GetDtailErr(handle uint16) (int16, int16, int16) {
+	start := time.Now()
+	errNo, errDtNo, rc := b.backend.GetDtailErr(handle)
+	return errNo, errDtNo, b.observe("cnc_getdtailerr", start, rc)
+}
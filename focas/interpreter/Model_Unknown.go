@@ -1,20 +1,10 @@
 package interpreter
 
 import (
-	"unsafe"
-
+	"github.com/iwtcode/fanucAdapter/focas/model"
 	"github.com/iwtcode/fanucAdapter/models"
 )
 
-/*
-#cgo CFLAGS: -I${SRCDIR}/..
-#cgo LDFLAGS: -L${SRCDIR}/.. -lfwlib32
-#cgo linux LDFLAGS: -Wl,-rpath,${SRCDIR}/..
-
-#include "../fwlib32.h"
-*/
-import "C"
-
 const (
 	// TmMode (Тип станка)
 	TmModeTurning = "T" // Токарный
@@ -137,23 +127,22 @@ const (
 // ModelUnknownInterpreter предоставляет реализацию по умолчанию для интерпретации состояния станка.
 type ModelUnknownInterpreter struct{}
 
-// InterpretMachineState преобразует сырую структуру ODBST в доменную модель UnifiedMachineData.
-func (i *ModelUnknownInterpreter) InterpretMachineState(statPtr unsafe.Pointer) *models.UnifiedMachineData {
-	stat := (*C.ODBST)(statPtr) // Преобразуем указатель обратно в нужный тип
+// InterpretMachineState преобразует структуру ODBST в доменную модель UnifiedMachineData.
+func (i *ModelUnknownInterpreter) InterpretMachineState(stat *model.StatInfo) *models.UnifiedMachineData {
 	return &models.UnifiedMachineData{
-		TmMode:             interpretTmMode(stat.tmmode),
-		ProgramMode:        interpretProgramMode(stat.aut),
-		MachineState:       interpretMachineState(stat.run),
-		AxisMovementStatus: interpretAxisMovement(stat.motion),
-		MstbStatus:         interpretMstbStatus(stat.mstb),
-		EmergencyStatus:    interpretEmergencyStatus(stat.emergency),
-		AlarmStatus:        interpretAlarmStatus(stat.alarm),
-		EditStatus:         interpretEditStatus(stat.tmmode, stat.edit),
+		TmMode:             interpretTmMode(stat.TmMode),
+		ProgramMode:        interpretProgramMode(stat.Aut),
+		MachineState:       interpretMachineState(stat.Run),
+		AxisMovementStatus: interpretAxisMovement(stat.Motion),
+		MstbStatus:         interpretMstbStatus(stat.Mstb),
+		EmergencyStatus:    interpretEmergencyStatus(stat.Emergency),
+		AlarmStatus:        interpretAlarmStatus(stat.Alarm),
+		EditStatus:         interpretEditStatus(stat.TmMode, stat.Edit),
 		Alarms:             []models.AlarmDetail{}, // Инициализируем пустым слайсом
 	}
 }
 
-func interpretTmMode(tmmode C.short) string {
+func interpretTmMode(tmmode int16) string {
 	switch tmmode {
 	case 0:
 		return TmModeTurning
@@ -164,7 +153,7 @@ func interpretTmMode(tmmode C.short) string {
 	}
 }
 
-func interpretProgramMode(aut C.short) string {
+func interpretProgramMode(aut int16) string {
 	switch aut {
 	case 0:
 		return ProgramModeMDI
@@ -193,7 +182,7 @@ func interpretProgramMode(aut C.short) string {
 	}
 }
 
-func interpretMachineState(run C.short) string {
+func interpretMachineState(run int16) string {
 	switch run {
 	case 0:
 		return MachineStateReset
@@ -210,7 +199,7 @@ func interpretMachineState(run C.short) string {
 	}
 }
 
-func interpretAxisMovement(motion C.short) string {
+func interpretAxisMovement(motion int16) string {
 	switch motion {
 	case 0:
 		return AxisMovementNone
@@ -223,14 +212,14 @@ func interpretAxisMovement(motion C.short) string {
 	}
 }
 
-func interpretMstbStatus(mstb C.short) string {
+func interpretMstbStatus(mstb int16) string {
 	if mstb == 1 {
 		return MstbStatusFIN
 	}
 	return MstbStatusOther
 }
 
-func interpretEmergencyStatus(emergency C.short) string {
+func interpretEmergencyStatus(emergency int16) string {
 	switch emergency {
 	case 0:
 		return EmergencyStatusNotEmergency
@@ -245,7 +234,7 @@ func interpretEmergencyStatus(emergency C.short) string {
 	}
 }
 
-func interpretAlarmStatus(alarm C.short) string {
+func interpretAlarmStatus(alarm int16) string {
 	switch alarm {
 	case 0:
 		return AlarmStatusOthers
@@ -270,7 +259,7 @@ func interpretAlarmStatus(alarm C.short) string {
 	}
 }
 
-func interpretEditStatus(tmmode C.short, editValue C.short) string {
+func interpretEditStatus(tmmode int16, editValue int16) string {
 	switch tmmode {
 	case 0: // T mode (токарный станок)
 		switch editValue {
@@ -1,45 +1,35 @@
 package focas
 
-/*
-#cgo CFLAGS: -I${SRCDIR}
-#cgo LDFLAGS: -L${SRCDIR} -lfwlib32
-#cgo linux LDFLAGS: -Wl,-rpath,${SRCDIR}
-
-#include "c_helpers.h"
-*/
-import "C"
-
 import (
+	"context"
 	"encoding/binary"
 	"fmt"
-	"unsafe"
 
 	. "github.com/iwtcode/fanucAdapter/focas/errcode"
 )
 
 // ReadJogOverride считывает процент коррекции скорости перемещения в режиме JOG.
-func (a *FocasAdapter) ReadJogOverride() (int32, error) {
+func (a *FocasAdapter) ReadJogOverride(ctx context.Context) (int32, error) {
 	a.logger.Debug("[ReadJogOverride] Начато чтение коррекции JOG.")
 	const length = 8 // Размер структуры ODBTOFS
 	buffer := make([]byte, length)
-	var rc C.short
 
-	err := a.CallWithReconnect(func(handle uint16) (int16, error) {
-		rc = C.go_cnc_rdtofs(
-			C.ushort(handle),
+	err := a.CallWithReconnect(ctx, func(handle uint16) (int16, error) {
+		rc := a.backend.RdTofs(
+			handle,
 			1, // номер корректора
 			1, // тип корректора
-			C.short(length),
-			(*C.ODBTOFS)(unsafe.Pointer(&buffer[0])),
+			length,
+			buffer,
 		)
 
-		a.logger.Debugf("[ReadFeedOverride] Вызов go_cnc_rdtofs. Код возврата (rc): %d", rc)
+		a.logger.Debugf("[ReadFeedOverride] Вызов cnc_rdtofs. Код возврата (rc): %d", rc)
 		a.logger.Debugf("[ReadFeedOverride] Сырой буфер ответа (hex): %x", buffer)
 
-		if int16(rc) != EW_OK {
-			return int16(rc), fmt.Errorf("cnc_rdtofs for JOG override failed: rc=%d", int16(rc))
+		if rc != EW_OK {
+			return rc, fmt.Errorf("JOG override: %w", a.callError("cnc_rdtofs", handle, rc))
 		}
-		return int16(rc), nil
+		return rc, nil
 	})
 
 	if err != nil {
@@ -0,0 +1,147 @@
+package focas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/iwtcode/fanucAdapter/models"
+)
+
+// ErrNotConnected — сентинел для errors.Is: соединение со станком еще ни разу не было установлено.
+var ErrNotConnected = errors.New("not connected to machine")
+
+// NotConnectedError возвращается вызовами адаптера, созданного NewLazyFocasAdapter,
+// пока первое подключение к станку не выполнено.
+type NotConnectedError struct {
+	Addr  string // Адрес станка "ip:port"
+	Cause error  // Последняя ошибка подключения (nil, если попыток еще не было)
+}
+
+func (e *NotConnectedError) Error() string {
+	if e.Cause == nil {
+		return fmt.Sprintf("%s: %v", e.Addr, ErrNotConnected)
+	}
+	return fmt.Sprintf("%s: %v: %v", e.Addr, ErrNotConnected, e.Cause)
+}
+
+// Is позволяет проверять ошибку через errors.Is(err, ErrNotConnected).
+func (e *NotConnectedError) Is(target error) bool {
+	return target == ErrNotConnected
+}
+
+func (e *NotConnectedError) Unwrap() error {
+	return e.Cause
+}
+
+// NewLazyFocasAdapter создает адаптер в состоянии Disconnected, не обращаясь к станку.
+// Подключение выполняется в фоне с паузами согласно RetryPolicy, пока не завершится
+// успешно или адаптер не будет закрыт. До этого вызовы возвращают NotConnectedError,
+// а GetSystemInfo — nil. Политика opts.Retry действует с первой попытки подключения.
+func NewLazyFocasAdapter(opts AdapterOptions) (*FocasAdapter, error) {
+	backend, err := opts.backend()
+	if err != nil {
+		return nil, err
+	}
+
+	adapter := newAdapter(backend, opts, models.ConnectionDisconnected)
+	go adapter.connectLoop()
+	return adapter, nil
+}
+
+// checkConnected возвращает NotConnectedError, если соединение еще ни разу не устанавливалось.
+func (a *FocasAdapter) checkConnected() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.ready {
+		return nil
+	}
+	return &NotConnectedError{Addr: fmt.Sprintf("%s:%d", a.ip, a.port), Cause: a.lastConnErr}
+}
+
+// connectLoop выполняет первичное подключение в фоне.
+func (a *FocasAdapter) connectLoop() {
+	for attempt := 1; ; attempt++ {
+		err := a.connectFirst()
+		if err == nil {
+			return
+		}
+
+		a.mu.Lock()
+		a.lastConnErr = err
+		policy := a.retry
+		a.mu.Unlock()
+		a.conn.disconnected(err)
+
+		delay := policy.backoff(attempt)
+		a.logger.Debugf("Machine %s:%d is not reachable yet: %v. Retrying in %s...", a.ip, a.port, err, delay.Round(time.Millisecond))
+		if sleepCtx(a.ctx, delay) != nil {
+			return
+		}
+	}
+}
+
+// connectFirst подключается к станку и считывает системную информацию.
+// Возвращает nil и в случае, если адаптер закрыт во время подключения.
+func (a *FocasAdapter) connectFirst() error {
+	handle, err := Connect(a.backend, a.ip, a.port, a.timeout)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	if a.ctx.Err() != nil {
+		a.mu.Unlock()
+		Disconnect(a.backend, handle)
+		return nil
+	}
+	a.handle = handle
+	a.mu.Unlock()
+
+	// Адаптер становится готовым вместе с системной информацией: до этого
+	// вызовы получают NotConnectedError, а состояние соединения не меняется
+	sysInfo, err := a.ReadSystemInfo(context.WithValue(a.ctx, connectingKey{}, true))
+	if err != nil {
+		a.mu.Lock()
+		handle, a.handle = a.handle, 0
+		a.mu.Unlock()
+		Disconnect(a.backend, handle)
+		return fmt.Errorf("failed to read system info after connecting: %w", err)
+	}
+
+	a.mu.Lock()
+	a.sysInfo = sysInfo
+	a.ready = true
+	a.mu.Unlock()
+	a.conn.succeeded()
+	a.logger.Infof("Connected to FOCAS at %s:%d (series %s)", a.ip, a.port, sysInfo.Series)
+	return nil
+}
+
+// connectingKey помечает контекст вызовов первичного подключения.
+type connectingKey struct{}
+
+func connecting(ctx context.Context) bool {
+	return ctx.Value(connectingKey{}) != nil
+}
+
+// callConnecting выполняет вызов первичного подключения: без проверки готовности,
+// повторов и учета состояния соединения — ими занимается connectLoop.
+func (a *FocasAdapter) callConnecting(ctx context.Context, f func(handle uint16) (int16, error)) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	handle := a.handle
+	a.mu.Unlock()
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	_, err := a.switchPath(handle, PathFromContext(ctx))
+	if err == nil {
+		_, err = f(handle)
+	}
+	return err
+}
@@ -0,0 +1,122 @@
+package model
+
+import (
+	"sync"
+	"time"
+)
+
+// StatInfo содержит поля структуры ODBST, возвращаемой cnc_statinfo.
+type StatInfo struct {
+	Hdck      int16 // Статус handle retrace
+	TmMode    int16 // Тип станка T/M
+	Aut       int16 // Выбранный автоматический режим
+	Run       int16 // Статус выполнения
+	Motion    int16 // Движение осей / выдержка
+	Mstb      int16 // Статус M, S, T, B
+	Emergency int16 // Аварийная остановка
+	Alarm     int16 // Статус тревоги
+	Edit      int16 // Статус редактирования
+}
+
+// SysInfo содержит поля структуры ODBSYS, возвращаемой cnc_sysinfo.
+// Строковые поля уже очищены от завершающих нулей.
+type SysInfo struct {
+	AddInfo int16
+	MaxAxis int16
+	CncType string // Тип ЧПУ, например "0", "16", "30"
+	MtType  string // Тип станка: " M", " T", "TT", "MM" и т.д.
+	Series  string // Номер серии ПО
+	Version string // Версия ПО
+	Axes    string // Количество управляемых осей (ASCII)
+}
+
+// Backend абстрагирует все вызовы FOCAS API, которые выполняет адаптер.
+//
+// Методы повторяют сигнатуры соответствующих функций cnc_* и возвращают их код
+// возврата (EW_*). Буферы передаются в раскладке C-структур FOCAS (little-endian,
+// long = 4 байта), поэтому разбор данных в пакете focas не зависит от того,
+// какая реализация используется: cgo-обертка над libfwlib32, сетевой клиент
+// или in-memory заглушка для тестов.
+//
+// Реализации не обязаны быть потокобезопасными: адаптер сериализует вызовы сам.
+type Backend interface {
+	// Startup соответствует cnc_startupprocess. Повторные вызовы должны быть безопасны.
+	Startup(mode uint16, logPath string) int16
+	// AllcLibHndl3 соответствует cnc_allclibhndl3 и возвращает хендл соединения.
+	AllcLibHndl3(ip string, port uint16, timeoutMs int32) (uint16, int16)
+	// FreeLibHndl соответствует cnc_freelibhndl.
+	FreeLibHndl(handle uint16) int16
+
+	// SysInfo соответствует cnc_sysinfo.
+	SysInfo(handle uint16) (SysInfo, int16)
+	// StatInfo соответствует cnc_statinfo.
+	StatInfo(handle uint16) (StatInfo, int16)
+	// ExePrgName соответствует cnc_exeprgname: имя и номер выполняемой программы.
+	ExePrgName(handle uint16) (string, int64, int16)
+	// RdExecProg соответствует cnc_rdexecprog; length — вход/выход.
+	RdExecProg(handle uint16, length *uint16, blknum *int16, data []byte) int16
+
+	// RdPosition соответствует cnc_rdposition (буфер ODBPOS[]).
+	RdPosition(handle uint16, posType int16, dataNum *int16, buf []byte) int16
+	// Diagnoss соответствует cnc_diagnoss (буфер ODBDGN).
+	Diagnoss(handle uint16, diagNo int16, axisNo int16, length int16, buf []byte) int16
+	// RdSpMeter соответствует cnc_rdspmeter (буфер ODBSPLOAD[]).
+	RdSpMeter(handle uint16, spType int16, num *int16, buf []byte) int16
+	// RdSpLoad соответствует cnc_rdspload (буфер ODBSPN).
+	RdSpLoad(handle uint16, spNo int16, buf []byte) int16
+	// RdSpeed соответствует cnc_rdspeed (буфер ODBSPEED).
+	RdSpeed(handle uint16, spType int16, buf []byte) int16
+	// Actf соответствует cnc_actf (буфер ODBACT).
+	Actf(handle uint16, buf []byte) int16
+	// RdTofs соответствует cnc_rdtofs (буфер ODBTOFS).
+	RdTofs(handle uint16, number int16, ofsType int16, length int16, buf []byte) int16
+
+	// RdAlmMsg соответствует cnc_rdalmmsg (буфер ODBALMMSG2[]).
+	RdAlmMsg(handle uint16, almType int16, num *int16, buf []byte) int16
+	// RdParam соответствует cnc_rdparam (буфер IODBPSD).
+	RdParam(handle uint16, prmNo int16, axisNo int16, length int16, buf []byte) int16
+	// RdParar соответствует cnc_rdparar (буфер IODBPSD[]); start, end и length — вход/выход.
+	RdParar(handle uint16, start *int16, axisNo int16, end *int16, length *int16, buf []byte) int16
+
+	// GetPath соответствует cnc_getpath: текущий и максимальный номер канала.
+	GetPath(handle uint16) (int16, int16, int16)
+	// SetPath соответствует cnc_setpath: выбирает канал для последующих вызовов по хендлу.
+	SetPath(handle uint16, pathNo int16) int16
+	// UpStart соответствует cnc_upstart (выгрузка программы по номеру).
+	UpStart(handle uint16, progNum int16) int16
+	// UpStart4 соответствует cnc_upstart4 (выгрузка программы по пути).
+	UpStart4(handle uint16, upType int16, fileName string) int16
+	// Upload соответствует cnc_upload (буфер ODBUP); length — вход/выход.
+	Upload(handle uint16, buf []byte, length *uint16) int16
+	// UpEnd соответствует cnc_upend.
+	UpEnd(handle uint16) int16
+
+	// GetDtailErr соответствует cnc_getdtailerr: подробности последней ошибки (ODBERR).
+	GetDtailErr(handle uint16) (int16, int16, int16)
+}
+
+// CallLocker — необязательный интерфейс бэкенда с собственной блокировкой вызовов.
+// Вызовы бэкендов без CallLocker сериализуются общей для процесса блокировкой,
+// поскольку libfwlib32 не допускает конкурентных вызовов. Бэкенд, который изолирует
+// библиотеку (например, в отдельном процессе), возвращает свою блокировку, и его вызовы
+// выполняются параллельно с вызовами других бэкендов.
+type CallLocker interface {
+	CallLock() sync.Locker
+}
+
+// Prober — необязательный интерфейс бэкенда с быстрой проверкой доступности станка.
+// Probe вызывается перед cnc_allclibhndl3 без блокировки вызовов, чтобы ожидание
+// недоступного станка не задерживало вызовы FOCAS к другим станкам. Код, отличный
+// от EW_OK, возвращается как ошибка подключения.
+type Prober interface {
+	Probe(ip string, port uint16) int16
+}
+
+// CallObserver получает сведения о вызовах бэкенда, обернутого focas.Instrument:
+// имя функции FOCAS, код возврата и длительность каждого вызова, а также время
+// ожидания блокировки вызовов. Методы вызываются синхронно на пути вызова FOCAS,
+// поэтому не должны блокироваться.
+type CallObserver interface {
+	ObserveCall(function string, rc int16, duration time.Duration)
+	ObserveLockWait(wait time.Duration)
+}
@@ -0,0 +1,137 @@
+package model
+
+import (
+	"fmt"
+
+	. "github.com/iwtcode/fanucAdapter/focas/errcode"
+)
+
+// Code — код возврата функции FOCAS. Значения Code реализуют error и служат
+// сигнальными ошибками: errors.Is(err, ErrNoOption) проверяет код внутри FocasError.
+type Code int16
+
+// Сигнальные ошибки для кодов возврата FOCAS.
+var (
+	ErrProtocol     error = Code(EW_PROTOCOL)
+	ErrSocket       error = Code(EW_SOCKET)
+	ErrNoDLL        error = Code(EW_NODLL)
+	ErrHandle       error = Code(EW_HANDLE)
+	ErrVersion      error = Code(EW_VERSION)
+	ErrUnexpected   error = Code(EW_UNEXP)
+	ErrSystem       error = Code(EW_SYSTEM)
+	ErrReset        error = Code(EW_RESET)
+	ErrBusy         error = Code(EW_BUSY)
+	ErrFunction     error = Code(EW_FUNC)
+	ErrLength       error = Code(EW_LENGTH)
+	ErrNumber       error = Code(EW_NUMBER)
+	ErrAttribute    error = Code(EW_ATTRIB)
+	ErrData         error = Code(EW_DATA)
+	ErrNoOption     error = Code(EW_NOOPT)
+	ErrWriteProtect error = Code(EW_PROT)
+	ErrOverflow     error = Code(EW_OVRFLOW)
+	ErrParam        error = Code(EW_PARAM)
+	ErrBuffer       error = Code(EW_BUFFER)
+	ErrPath         error = Code(EW_PATH)
+	ErrMode         error = Code(EW_MODE)
+	ErrReject       error = Code(EW_REJECT)
+	ErrDataServer   error = Code(EW_DTSRVR)
+	ErrAlarm        error = Code(EW_ALARM)
+	ErrStop         error = Code(EW_STOP)
+	ErrPassword     error = Code(EW_PASSWD)
+)
+
+var codeNames = map[Code]string{
+	EW_PROTOCOL:  "EW_PROTOCOL",
+	EW_SOCKET:    "EW_SOCKET",
+	EW_NODLL:     "EW_NODLL",
+	EW_INIERR:    "EW_INIERR",
+	EW_ITLOW:     "EW_ITLOW",
+	EW_ITHIGHT:   "EW_ITHIGHT",
+	EW_BUS:       "EW_BUS",
+	EW_SYSTEM2:   "EW_SYSTEM2",
+	EW_HSSB:      "EW_HSSB",
+	EW_HANDLE:    "EW_HANDLE",
+	EW_VERSION:   "EW_VERSION",
+	EW_UNEXP:     "EW_UNEXP",
+	EW_SYSTEM:    "EW_SYSTEM",
+	EW_PARITY:    "EW_PARITY",
+	EW_MMCSYS:    "EW_MMCSYS",
+	EW_RESET:     "EW_RESET",
+	EW_BUSY:      "EW_BUSY",
+	EW_OK:        "EW_OK",
+	EW_FUNC:      "EW_FUNC",
+	EW_LENGTH:    "EW_LENGTH",
+	EW_NUMBER:    "EW_NUMBER",
+	EW_ATTRIB:    "EW_ATTRIB",
+	EW_DATA:      "EW_DATA",
+	EW_NOOPT:     "EW_NOOPT",
+	EW_PROT:      "EW_PROT",
+	EW_OVRFLOW:   "EW_OVRFLOW",
+	EW_PARAM:     "EW_PARAM",
+	EW_BUFFER:    "EW_BUFFER",
+	EW_PATH:      "EW_PATH",
+	EW_MODE:      "EW_MODE",
+	EW_REJECT:    "EW_REJECT",
+	EW_DTSRVR:    "EW_DTSRVR",
+	EW_ALARM:     "EW_ALARM",
+	EW_STOP:      "EW_STOP",
+	EW_PASSWD:    "EW_PASSWD",
+	EW_PMC:       "EW_PMC",
+	EW_PMCHANDLE: "EW_PMCHANDLE",
+	EW_RD_OVWSTP: "EW_RD_OVWSTP",
+	EW_RD_RSTFIN: "EW_RD_RSTFIN",
+}
+
+// String возвращает имя константы кода, например "EW_NOOPT".
+func (c Code) String() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("EW_%d", int16(c))
+}
+
+func (c Code) Error() string {
+	return fmt.Sprintf("focas: %s (rc=%d)", c.String(), int16(c))
+}
+
+// ErrorDetail — подробности ошибки, возвращаемые cnc_getdtailerr (ODBERR).
+type ErrorDetail struct {
+	ErrNo   int16 // err_no
+	ErrDtNo int16 // err_dtno
+}
+
+// FocasError описывает неудачный вызов функции FOCAS.
+// Unwrap возвращает Code, поэтому ошибку можно сравнивать с сигнальными ошибками через errors.Is.
+type FocasError struct {
+	Func   string       // имя функции FOCAS, например "cnc_rdparam"
+	RC     int16        // код возврата
+	Handle uint16       // хендл соединения, на котором выполнялся вызов
+	Detail *ErrorDetail // подробности от cnc_getdtailerr, если удалось получить
+}
+
+func (e *FocasError) Error() string {
+	msg := fmt.Sprintf("%s failed: rc=%d (%s)", e.Func, e.RC, Code(e.RC))
+	if e.Detail != nil {
+		msg += fmt.Sprintf(", detail err_no=%d err_dtno=%d", e.Detail.ErrNo, e.Detail.ErrDtNo)
+	}
+	return msg
+}
+
+// Unwrap возвращает код возврата как сигнальную ошибку.
+func (e *FocasError) Unwrap() error {
+	return Code(e.RC)
+}
+
+// NewError создает FocasError для вызова fn, завершившегося с кодом rc.
+// Для ошибок, о которых сообщил сам ЧПУ (rc > 0), подробности запрашиваются у backend
+// через cnc_getdtailerr; вызывающий должен удерживать libLock, как и для исходного вызова.
+// Если backend равен nil, подробности не запрашиваются.
+func NewError(backend Backend, fn string, handle uint16, rc int16) *FocasError {
+	e := &FocasError{Func: fn, RC: rc, Handle: handle}
+	if backend != nil && rc > EW_OK {
+		if errNo, errDtNo, drc := backend.GetDtailErr(handle); drc == EW_OK {
+			e.Detail = &ErrorDetail{ErrNo: errNo, ErrDtNo: errDtNo}
+		}
+	}
+	return e
+}
@@ -1,7 +1,7 @@
 package model
 
 import (
-	"unsafe"
+	"context"
 
 	"github.com/iwtcode/fanucAdapter/models"
 	"github.com/sirupsen/logrus"
@@ -40,20 +40,21 @@ const (
 
 // Interpreter определяет интерфейс для интерпретации состояния станка в зависимости от модели.
 type Interpreter interface {
-	InterpretMachineState(stat unsafe.Pointer) *models.UnifiedMachineData
+	InterpretMachineState(stat *StatInfo) *models.UnifiedMachineData
 }
 
 // ProgramReader определяет интерфейс для логики чтения управляющей программы в зависимости от модели.
 // Ему необходим доступ к адаптеру для выполнения вызовов FOCAS.
 type ProgramReader interface {
-	GetControlProgram(adapter FocasCaller) (string, error)
+	GetControlProgram(ctx context.Context, adapter FocasCaller) (string, error)
 }
 
 // FocasCaller - это интерфейс, который абстрагирует FocasAdapter,
 // предоставляя только те методы, которые необходимы для реализаций ProgramReader.
 // Это предотвращает циклические зависимости между пакетом program и пакетом focas.
 type FocasCaller interface {
-	ReadProgram() (*models.ProgramInfo, error)
-	CallWithReconnect(f func(handle uint16) (int16, error)) error
+	ReadProgram(ctx context.Context) (*models.ProgramInfo, error)
+	CallWithReconnect(ctx context.Context, f func(handle uint16) (int16, error)) error
+	Backend() Backend
 	Logger() logrus.FieldLogger
 }
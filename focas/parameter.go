@@ -1,24 +1,15 @@
 package focas
 
 import (
+	"context"
 	"encoding/binary"
 	"fmt"
 	"time"
-	"unsafe"
 
 	. "github.com/iwtcode/fanucAdapter/focas/errcode"
 	"github.com/iwtcode/fanucAdapter/models"
 )
 
-/*
-#cgo CFLAGS: -I${SRCDIR}
-#cgo LDFLAGS: -L${SRCDIR} -lfwlib32
-#cgo linux LDFLAGS: -Wl,-rpath,${SRCDIR}
-
-#include "c_helpers.h"
-*/
-import "C"
-
 const (
 	paramPartsCount    = 6711 // Количество обработанных деталей
 	paramPowerOnTime   = 6750 // Время включения
@@ -36,8 +27,29 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%02d:%02d:%02d", h, m, s)
 }
 
+// ReadParameter читает целочисленное значение параметра prmNo (cnc_rdparam).
+// axisNo — номер оси для осевых параметров, 0 — для общих. Битовые и байтовые
+// параметры возвращаются в младших разрядах значения.
+func (a *FocasAdapter) ReadParameter(ctx context.Context, prmNo int16, axisNo int16) (int32, error) {
+	// IODBPSD: short datano, short type, затем union с данными (ldata — 4 байта)
+	const length = 8
+	buffer := make([]byte, length)
+
+	err := a.CallWithReconnect(ctx, func(handle uint16) (int16, error) {
+		rc := a.backend.RdParam(handle, prmNo, axisNo, length, buffer)
+		if rc != EW_OK {
+			return rc, fmt.Errorf("parameter %d: %w", prmNo, a.callError("cnc_rdparam", handle, rc))
+		}
+		return rc, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.LittleEndian.Uint32(buffer[4:8])), nil
+}
+
 // ReadParameterInfo считывает и сразу форматирует группу параметров одним пакетным запросом.
-func (a *FocasAdapter) ReadParameterInfo() (*models.ParameterInfo, error) {
+func (a *FocasAdapter) ReadParameterInfo(ctx context.Context) (*models.ParameterInfo, error) {
 	info := &models.ParameterInfo{}
 
 	const startParam = 6711
@@ -49,25 +61,24 @@ func (a *FocasAdapter) ReadParameterInfo() (*models.ParameterInfo, error) {
 	const bufferSize = 4096
 	buffer := make([]byte, bufferSize)
 
-	var rc C.short
-	var length C.short = C.short(bufferSize)
-	var startNo C.short = C.short(startParam)
-	var endNo C.short = C.short(endParam)
+	var length int16 = bufferSize
+	var startNo int16 = startParam
+	var endNo int16 = endParam
 
-	err := a.CallWithReconnect(func(handle uint16) (int16, error) {
-		rc = C.go_cnc_rdparar(
-			C.ushort(handle),
+	err := a.CallWithReconnect(ctx, func(handle uint16) (int16, error) {
+		rc := a.backend.RdParar(
+			handle,
 			&startNo, // Указатель на start
 			0,        // Axis
 			&endNo,   // Указатель на end
 			&length,  // Указатель на length
-			(*C.IODBPSD)(unsafe.Pointer(&buffer[0])),
+			buffer,
 		)
 
-		if int16(rc) != EW_OK {
-			return int16(rc), fmt.Errorf("cnc_rdparar failed for range %d-%d: rc=%d", startParam, endParam, int16(rc))
+		if rc != EW_OK {
+			return rc, fmt.Errorf("range %d-%d: %w", startParam, endParam, a.callError("cnc_rdparar", handle, rc))
 		}
-		return int16(rc), nil
+		return rc, nil
 	})
 
 	if err != nil {
@@ -0,0 +1,79 @@
+package focas
+
+import (
+	"context"
+
+	. "github.com/iwtcode/fanucAdapter/focas/errcode"
+)
+
+// pathKey — ключ контекста с номером канала.
+type pathKey struct{}
+
+// WithPath возвращает контекст, в котором вызовы адаптера выполняются для канала path
+// многоканального станка (1..SystemInfo.Paths). Без WithPath используется канал,
+// выбранный на станке при подключении.
+func WithPath(ctx context.Context, path int16) context.Context {
+	return context.WithValue(ctx, pathKey{}, path)
+}
+
+// PathFromContext возвращает канал, заданный через WithPath, или 0.
+func PathFromContext(ctx context.Context) int16 {
+	path, _ := ctx.Value(pathKey{}).(int16)
+	return path
+}
+
+// pathState хранит канал, выбранный у хендла. Канал — свойство хендла в библиотеке FOCAS,
+// поэтому поля читаются и изменяются только под libLock вместе с вызовами.
+type pathState struct {
+	handle      uint16 // хендл, для которого известен current
+	current     int16  // текущий канал хендла
+	defaultPath int16  // канал, выбранный на станке при первом подключении
+}
+
+// switchPath переключает хендл на канал path (0 — канал по умолчанию) перед вызовом.
+// Вызывается под libLock.
+func (a *FocasAdapter) switchPath(handle uint16, path int16) (int16, error) {
+	ps := &a.path
+	if ps.handle != handle {
+		// Новый хендл после подключения находится в канале по умолчанию
+		ps.handle, ps.current = handle, ps.defaultPath
+	}
+	if path == 0 {
+		path = ps.defaultPath
+		if path == 0 && ps.current != 0 {
+			// Канал по умолчанию неизвестен (нет cnc_getpath), но хендл переключали
+			path = 1
+		}
+	}
+	if path == 0 || path == ps.current {
+		return EW_OK, nil
+	}
+
+	rc := a.backend.SetPath(handle, path)
+	if rc != EW_OK {
+		return rc, a.callError("cnc_setpath", handle, rc)
+	}
+	ps.current = path
+	return rc, nil
+}
+
+// readPaths считывает канал по умолчанию и количество каналов.
+// Станки без поддержки cnc_getpath считаются одноканальными.
+func (a *FocasAdapter) readPaths(ctx context.Context) (int16, error) {
+	maxPath := int16(1)
+	err := a.CallWithReconnect(ctx, func(handle uint16) (int16, error) {
+		path, maxNo, rc := a.backend.GetPath(handle)
+		if rc == EW_HANDLE || rc == EW_SOCKET {
+			return rc, a.callError("cnc_getpath", handle, rc)
+		}
+		if rc == EW_OK && maxNo > 0 {
+			maxPath = maxNo
+			if a.path.defaultPath == 0 {
+				a.path.defaultPath = path
+				a.path.current = path
+			}
+		}
+		return EW_OK, nil
+	})
+	return maxPath, err
+}
@@ -1,20 +1,11 @@
 package program
 
-/*
-#cgo CFLAGS: -I${SRCDIR}/..
-#cgo LDFLAGS: -L${SRCDIR}/.. -lfwlib32
-#cgo linux LDFLAGS: -Wl,-rpath,${SRCDIR}/..
-
-#include <stdlib.h>
-#include "../c_helpers.h"
-*/
-import "C"
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
-	"unsafe"
 
 	. "github.com/iwtcode/fanucAdapter/focas/errcode"
 	"github.com/iwtcode/fanucAdapter/focas/model"
@@ -24,25 +15,25 @@ import (
 type ModelUnknownProgramReader struct{}
 
 // GetControlProgram считывает полное содержимое текущей выполняемой программы.
-func (pr *ModelUnknownProgramReader) GetControlProgram(a model.FocasCaller) (string, error) {
-	nameBuf := make([]byte, 64)
-	var onum C.long
+func (pr *ModelUnknownProgramReader) GetControlProgram(ctx context.Context, a model.FocasCaller) (string, error) {
 	var progName string
 	logger := a.Logger()
+	backend := a.Backend()
 
-	err := a.CallWithReconnect(func(handle uint16) (int16, error) {
-		rc := C.go_cnc_exeprgname(C.ushort(handle), (*C.char)(unsafe.Pointer(&nameBuf[0])), C.int(len(nameBuf)), &onum)
-		if int16(rc) != EW_OK {
-			return int16(rc), fmt.Errorf("cnc_exeprgname failed: rc=%d", int16(rc))
+	err := a.CallWithReconnect(ctx, func(handle uint16) (int16, error) {
+		var rc int16
+		progName, _, rc = backend.ExePrgName(handle)
+		if rc != EW_OK {
+			return rc, model.NewError(backend, "cnc_exeprgname", handle, rc)
 		}
-		return int16(rc), nil
+		return rc, nil
 	})
 
 	if err != nil {
 		return "", fmt.Errorf("could not read program info: %w", err)
 	}
 
-	progName = strings.TrimRight(string(nameBuf), "\x00")
+	progName = strings.TrimRight(progName, "\x00")
 
 	var programNumberToUpload int64
 	if strings.HasPrefix(progName, "O") {
@@ -53,87 +44,85 @@ func (pr *ModelUnknownProgramReader) GetControlProgram(a model.FocasCaller) (str
 	}
 
 	var finalContent string
-	err = a.CallWithReconnect(func(handle uint16) (int16, error) {
-		var rc C.short
+	err = a.CallWithReconnect(ctx, func(handle uint16) (int16, error) {
+		var rc int16
 
 		if programNumberToUpload > 0 {
 			logger.Infof("Starting program upload by number for program O%d (%s)", programNumberToUpload, progName)
-			rc = C.go_cnc_upstart(C.ushort(handle), C.short(programNumberToUpload))
-			if int16(rc) != EW_OK {
-				return int16(rc), fmt.Errorf("cnc_upstart for program '%s' (number %d) failed: rc=%d", progName, programNumberToUpload, int16(rc))
+			rc = backend.UpStart(handle, int16(programNumberToUpload))
+			if rc != EW_OK {
+				return rc, fmt.Errorf("program '%s' (number %d): %w", progName, programNumberToUpload, model.NewError(backend, "cnc_upstart", handle, rc))
 			}
 		} else {
-			var pathNo, maxPathNo C.short
-			rcPath := C.go_cnc_getpath(C.ushort(handle), &pathNo, &maxPathNo)
-			if int16(rcPath) != EW_OK {
-				return int16(rcPath), fmt.Errorf("cnc_getpath failed: rc=%d", int16(rcPath))
+			pathNo, _, rcPath := backend.GetPath(handle)
+			if rcPath != EW_OK {
+				return rcPath, model.NewError(backend, "cnc_getpath", handle, rcPath)
 			}
 
 			filePath := fmt.Sprintf("//CNC_MEM/USER/PATH%d/%s", pathNo, progName)
 			logger.Infof("Starting program upload by path for program '%s'", filePath)
 
-			cFilePath := C.CString(filePath)
-			defer C.free(unsafe.Pointer(cFilePath))
-
-			rc = C.go_cnc_upstart4(C.ushort(handle), 0, cFilePath)
-			if int16(rc) != EW_OK {
-				return int16(rc), fmt.Errorf("cnc_upstart4 for program '%s' failed: rc=%d", filePath, int16(rc))
+			rc = backend.UpStart4(handle, 0, filePath)
+			if rc != EW_OK {
+				return rc, fmt.Errorf("program '%s': %w", filePath, model.NewError(backend, "cnc_upstart4", handle, rc))
 			}
 		}
 
 		logger.Info("Program upload started successfully.")
-		defer C.go_cnc_upend(C.ushort(handle))
+		defer backend.UpEnd(handle)
 
 		var sb strings.Builder
 		var uploadErr error
-		var lastRc C.short
+		var lastRc int16
+
+		// Структура ODBUP: dummy[2](4) + data[256]
+		const odbupHeaderSize = 4
+		buffer := make([]byte, odbupHeaderSize+256)
 
 		iteration := 0
 		for {
-			var buffer C.ODBUP
-			length := C.ushort(256)
-			rcUpload := C.go_cnc_upload(C.ushort(handle), &buffer, &length)
+			length := uint16(256)
+			rcUpload := backend.Upload(handle, buffer, &length)
 			lastRc = rcUpload
 
 			logger.Debugf("Upload iteration %d: rc=%d, length=%d", iteration, rcUpload, length)
 			iteration++
 
-			isDataRead := int16(rcUpload) == EW_OK || int16(rcUpload) == EW_BUFFER
+			isDataRead := rcUpload == EW_OK || rcUpload == EW_BUFFER
 
 			if isDataRead && length > 0 {
-				goBytes := C.GoBytes(unsafe.Pointer(&buffer.data[0]), C.int(length))
-				sb.Write(goBytes)
+				sb.Write(buffer[odbupHeaderSize : odbupHeaderSize+int(length)])
 			}
 
 			// 1. Условия успешного завершения
-			if (int16(rcUpload) == EW_OK && length == 0) || int16(rcUpload) == EW_RESET {
+			if (rcUpload == EW_OK && length == 0) || rcUpload == EW_RESET {
 				logger.Infof("Upload finished successfully with code: %d", rcUpload)
 				break
 			}
 
 			// 2. Условие для повторной попытки
-			if int16(rcUpload) == EW_HANDLE {
+			if rcUpload == EW_HANDLE {
 				logger.Warnf("CNC is busy (rc=%d). Retrying in 50ms...", rcUpload)
 				time.Sleep(50 * time.Millisecond)
 				continue
 			}
 
 			// 3. Условие продолжения чтения (буфер был полон, есть еще данные)
-			if int16(rcUpload) == EW_BUFFER {
+			if rcUpload == EW_BUFFER {
 				continue
 			}
 
 			// 4. Условие неустранимой ошибки (все остальные случаи)
-			if int16(rcUpload) != EW_OK {
+			if rcUpload != EW_OK {
 				logger.Errorf("Exiting upload loop due to unrecoverable error. rc=%d", rcUpload)
-				uploadErr = fmt.Errorf("cnc_upload failed with rc=%d", int16(rcUpload))
+				uploadErr = model.NewError(backend, "cnc_upload", handle, rcUpload)
 				break
 			}
 		}
 
 		// Если во время цикла произошла ошибка, немедленно возвращаем ее
 		if uploadErr != nil {
-			return int16(lastRc), uploadErr
+			return lastRc, uploadErr
 		}
 
 		// Обработка и очистка происходят только после успешной загрузки
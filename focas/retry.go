@@ -0,0 +1,158 @@
+package focas
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen возвращается без обращения к станку, пока предохранитель
+// (circuit breaker) разомкнут: станок недавно был недоступен.
+var ErrCircuitOpen = errors.New("circuit breaker is open: machine is unavailable")
+
+// ErrRetriesExhausted возвращается, когда все попытки восстановить соединение исчерпаны.
+var ErrRetriesExhausted = errors.New("retries exhausted")
+
+// RetryPolicy задает поведение CallWithReconnect при ошибках соединения (EW_HANDLE, EW_SOCKET).
+// Нулевые поля заменяются значениями из DefaultRetryPolicy.
+type RetryPolicy struct {
+	// MaxAttempts — максимальное количество попыток вызова, включая первую.
+	// Отрицательное значение снимает ограничение (вызов прерывается только через context).
+	MaxAttempts int
+	// InitialBackoff — пауза после первой неудачной попытки переподключения.
+	InitialBackoff time.Duration
+	// MaxBackoff ограничивает паузу сверху.
+	MaxBackoff time.Duration
+	// Multiplier — множитель паузы для каждой следующей попытки.
+	Multiplier float64
+	// Jitter — доля случайного разброса паузы (0..1), чтобы станки не переподключались синхронно.
+	// Отрицательное значение отключает разброс.
+	Jitter float64
+
+	// BreakerThreshold — количество подряд неудачных вызовов (после всех попыток),
+	// после которого предохранитель размыкается. Отрицательное значение отключает предохранитель.
+	BreakerThreshold int
+	// BreakerCooldown — время, в течение которого вызовы сразу завершаются с ErrCircuitOpen.
+	// По его истечении пропускается одна пробная попытка.
+	BreakerCooldown time.Duration
+}
+
+// DefaultRetryPolicy возвращает политику по умолчанию: 5 попыток с паузой
+// от 500 мс до 10 с и предохранитель, размыкающийся на 30 с после 3 неудачных вызовов.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:      5,
+		InitialBackoff:   500 * time.Millisecond,
+		MaxBackoff:       10 * time.Second,
+		Multiplier:       2,
+		Jitter:           0.2,
+		BreakerThreshold: 3,
+		BreakerCooldown:  30 * time.Second,
+	}
+}
+
+// withDefaults заполняет незаданные поля значениями из DefaultRetryPolicy.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	def := DefaultRetryPolicy()
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = def.MaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = def.InitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = def.MaxBackoff
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = def.Multiplier
+	}
+	switch {
+	case p.Jitter == 0:
+		p.Jitter = def.Jitter
+	case p.Jitter < 0:
+		p.Jitter = 0
+	case p.Jitter > 1:
+		p.Jitter = 1
+	}
+	if p.BreakerThreshold == 0 {
+		p.BreakerThreshold = def.BreakerThreshold
+	}
+	if p.BreakerCooldown <= 0 {
+		p.BreakerCooldown = def.BreakerCooldown
+	}
+	return p
+}
+
+// backoff возвращает паузу перед попыткой с номером attempt (начиная с 1).
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
+
+// sleepCtx ждет d или отмены ctx.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// circuitBreaker размыкается после серии неудачных вызовов и пропускает
+// пробный вызов по истечении паузы.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// allow сообщает, можно ли обращаться к станку.
+func (b *circuitBreaker) allow(p RetryPolicy) error {
+	if p.BreakerThreshold <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < p.BreakerThreshold {
+		return nil
+	}
+	now := time.Now()
+	if now.Before(b.openUntil) {
+		return ErrCircuitOpen
+	}
+	// Пауза истекла: пропускаем один пробный вызов, остальные по-прежнему получают ErrCircuitOpen
+	b.openUntil = now.Add(p.BreakerCooldown)
+	return nil
+}
+
+// success замыкает предохранитель.
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.openUntil = time.Time{}
+}
+
+// failure регистрирует неудачный вызов и при достижении порога размыкает предохранитель.
+func (b *circuitBreaker) failure(p RetryPolicy) (opened bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if p.BreakerThreshold > 0 && b.failures >= p.BreakerThreshold {
+		b.openUntil = time.Now().Add(p.BreakerCooldown)
+		return true
+	}
+	return false
+}
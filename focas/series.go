@@ -0,0 +1,104 @@
+package focas
+
+import (
+	"strings"
+
+	"github.com/iwtcode/fanucAdapter/focas/model"
+	"github.com/iwtcode/fanucAdapter/models"
+)
+
+// addInfoISeries — бит ODBSYS.addinfo, означающий ЧПУ i-серии.
+const addInfoISeries = 0x02
+
+// generations0i сопоставляет третий символ кода ПО ЧПУ серии 0i (ODBSYS.series)
+// с поколением модели: "D4B1", "D6B1" — 0i-D; "D4F1", "D6F1" — 0i-F. Тип станка
+// (T или M) берется из mt_type.
+var generations0i = map[byte]string{
+	'B': "D",
+	'F': "F",
+}
+
+// Источники серии модели в SystemInfo.ModelSource
+const (
+	ModelSourceConfig   = "config"
+	ModelSourceDetected = "detected"
+)
+
+// DetectModelSeries определяет семейство ЧПУ и тип станка по данным cnc_sysinfo.
+// Возвращает серию в формате Config.ModelSeries, например "0i-TF", "0i-MD", "16i-M"
+// или "30i-M", а также тип станка ("T", "M" и т.д.). Поколение серии 0i (D или F)
+// определяется по коду ПО ЧПУ; если код не распознан, поколение не указывается ("0i-T").
+// Если тип ЧПУ не заполнен, серия пустая.
+func DetectModelSeries(info model.SysInfo) (series string, machineType string) {
+	machineType = strings.TrimSpace(info.MtType)
+	// "TT" и "MM" — двухканальные токарные и фрезерные станки
+	if len(machineType) == 2 && machineType[0] == machineType[1] {
+		machineType = machineType[:1]
+	}
+
+	family := strings.TrimSpace(info.CncType)
+	if family == "" {
+		return "", machineType
+	}
+	switch {
+	case family == "30" || family == "31" || family == "32":
+		// Серии 30/31/32 выпускаются только в исполнении i
+		family += "i"
+	case info.AddInfo&addInfoISeries != 0:
+		family += "i"
+	}
+
+	generation := ""
+	if software := strings.TrimSpace(info.Series); family == "0i" && len(software) == 4 && software[0] == 'D' {
+		generation = generations0i[software[2]]
+	}
+
+	if machineType != "" || generation != "" {
+		return family + "-" + machineType + generation, machineType
+	}
+	return family, machineType
+}
+
+// applyModel выбирает реализации интерпретатора и ридера программ для станка:
+// серия из конфигурации имеет приоритет, иначе используется определенная по cnc_sysinfo.
+// Заполняет поля ModelSeries, ModelSource и Implementation в info.
+func (a *FocasAdapter) applyModel(info *models.SystemInfo, detected string) {
+	series, source := a.modelSeries, ModelSourceConfig
+	if series == "" {
+		series, source = detected, ModelSourceDetected
+	}
+
+	interp, reader, name := resolveImplementations(series)
+	info.ModelSeries = series
+	info.ModelSource = source
+	info.Implementation = name
+
+	a.mu.Lock()
+	changed := a.implementation != name
+	a.interpreter, a.programReader, a.implementation = interp, reader, name
+	a.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	a.logger.Infof("Machine %s:%d: model series %q (%s), using %s implementation", a.ip, a.port, series, source, name)
+	if source == ModelSourceConfig && detected != "" && !sameFamily(series, detected) {
+		a.logger.Warnf("Machine %s:%d: configured model series %q differs from detected %q", a.ip, a.port, series, detected)
+	}
+}
+
+// implementations возвращает текущие реализации интерпретатора и ридера программ.
+func (a *FocasAdapter) implementations() (model.Interpreter, model.ProgramReader) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.interpreter, a.programReader
+}
+
+// sameFamily сравнивает семейства ЧПУ без учета регистра и типа станка.
+func sameFamily(a, b string) bool {
+	family := func(s string) string {
+		s, _, _ = strings.Cut(strings.ToUpper(s), "-")
+		return s
+	}
+	return family(a) == family(b)
+}
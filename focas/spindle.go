@@ -1,39 +1,29 @@
 package focas
 
-/*
-#cgo CFLAGS: -I${SRCDIR}
-#cgo LDFLAGS: -L${SRCDIR} -lfwlib32
-#cgo linux LDFLAGS: -Wl,-rpath,${SRCDIR}
-
-#include "c_helpers.h"
-*/
-import "C"
-
 import (
+	"context"
 	"encoding/binary"
-	"fmt"
+	"maps"
 	"math"
-	"unsafe"
 
 	. "github.com/iwtcode/fanucAdapter/focas/errcode"
 	"github.com/iwtcode/fanucAdapter/models"
 )
 
 // ReadSpindleData считывает информацию о скорости, нагрузке и коррекции для всех активных шпинделей.
-func (a *FocasAdapter) ReadSpindleData() ([]models.SpindleInfo, error) {
+func (a *FocasAdapter) ReadSpindleData(ctx context.Context) ([]models.SpindleInfo, error) {
 	// 1. Чтение основных данных шпинделей (Load, Speed
-	var numSpindles C.short = 8
+	var numSpindles int16 = 8
 	const sploadSpspeedSize = 24
 	bufferSize := int(numSpindles) * sploadSpspeedSize
 	buffer := make([]byte, bufferSize)
-	var rc C.short
 
-	err := a.CallWithReconnect(func(handle uint16) (int16, error) {
-		rc = C.go_cnc_rdspmeter(C.ushort(handle), -1, &numSpindles, (*C.ODBSPLOAD)(unsafe.Pointer(&buffer[0])))
-		if int16(rc) != EW_OK {
-			return int16(rc), fmt.Errorf("cnc_rdspmeter failed: rc=%d", int16(rc))
+	err := a.CallWithReconnect(ctx, func(handle uint16) (int16, error) {
+		rc := a.backend.RdSpMeter(handle, -1, &numSpindles, buffer)
+		if rc != EW_OK {
+			return rc, a.callError("cnc_rdspmeter", handle, rc)
 		}
-		return int16(rc), nil
+		return rc, nil
 	})
 
 	if err != nil {
@@ -45,25 +35,33 @@ func (a *FocasAdapter) ReadSpindleData() ([]models.SpindleInfo, error) {
 	}
 
 	// 2. Чтение Override (Коррекция)
-	var overrideData C.ODBSPN
-	errOverride := a.CallWithReconnect(func(handle uint16) (int16, error) {
-		rc := C.go_cnc_rdspload(C.ushort(handle), -1, &overrideData)
-		if int16(rc) != EW_OK {
-			return int16(rc), fmt.Errorf("go_cnc_rdspload failed: rc=%d", int16(rc))
+	// Структура ODBSPN: datano(2) + type(2) + data[MAX_SPINDLE](2 * 8) = 20 байт
+	const maxSpindle = 8
+	overrideData := make([]byte, 4+2*maxSpindle)
+	errOverride := a.CallWithReconnect(ctx, func(handle uint16) (int16, error) {
+		rc := a.backend.RdSpLoad(handle, -1, overrideData)
+		if rc != EW_OK {
+			return rc, a.callError("cnc_rdspload", handle, rc)
 		}
-		return int16(rc), nil
+		return rc, nil
 	})
 
 	// 3. Чтение диагностики 411
 	maxAxes := int16(32)
-	if a.sysInfo != nil && a.sysInfo.MaxAxes > 0 {
-		maxAxes = a.sysInfo.MaxAxes
+	if sysInfo := a.GetSystemInfo(); sysInfo != nil && sysInfo.MaxAxes > 0 {
+		maxAxes = sysInfo.MaxAxes
+	}
+
+	// Непрочитанные значения остаются нулевыми, а их качество записывается в quality
+	var quality models.FieldQuality
+	if errOverride != nil {
+		quality = setFieldQuality(quality, "override_percent", errOverride)
 	}
 
-	diag411Vals, errDiag := a.ReadDiagnosisWordAllAxes(411, maxAxes)
+	diag411Vals, errDiag := a.ReadDiagnosisWordAllAxes(ctx, 411, maxAxes)
 	if errDiag != nil {
 		a.logger.Warnf("Warning: Batch read diag 411 failed: %v", errDiag)
-		diag411Vals = make([]int32, maxAxes)
+		quality = setFieldQuality(quality, "diag_411_value", errDiag)
 	}
 
 	spindleInfos := make([]models.SpindleInfo, 0, numSpindles)
@@ -87,8 +85,8 @@ func (a *FocasAdapter) ReadSpindleData() ([]models.SpindleInfo, error) {
 
 		// Парсинг Override
 		var overridePercent int16
-		if errOverride == nil && i < len(overrideData.data) {
-			rawOverride := overrideData.data[i]
+		if errOverride == nil && i < maxSpindle {
+			rawOverride := int16(binary.LittleEndian.Uint16(overrideData[4+i*2 : 6+i*2]))
 			const maxOverrideValue = 16383.0
 			calculatedPercent := (float64(rawOverride) / maxOverrideValue) * 100.0
 			overridePercent = int16(math.Round(calculatedPercent))
@@ -111,6 +109,7 @@ func (a *FocasAdapter) ReadSpindleData() ([]models.SpindleInfo, error) {
 			LoadPercent:     load,
 			OverridePercent: overridePercent,
 			Diag411Value:    diag411Value,
+			Quality:         maps.Clone(quality),
 		})
 	}
 
@@ -0,0 +1,135 @@
+package worker
+
+import (
+	. "github.com/iwtcode/fanucAdapter/focas/errcode"
+	"github.com/iwtcode/fanucAdapter/focas/model"
+)
+
+// Startup запоминает параметры, чтобы повторить cnc_startupprocess после перезапуска обработчика.
+func (w *Worker) Startup(mode uint16, logPath string) int16 {
+	w.mu.Lock()
+	w.startup = &request{Method: "Startup", Args: []any{mode, logPath}}
+	w.mu.Unlock()
+	return w.call(w.current(), "Startup", nil, mode, logPath)
+}
+
+// Probe выполняет проверку доступности станка в процессе-обработчике (реализация model.Prober).
+// Обработчик выполняет запросы по одному, поэтому в общем обработчике (WorkerGroup)
+// проверка недоступного станка задерживает вызовы остальных станков группы
+// на время ожидания соединения.
+func (w *Worker) Probe(ip string, port uint16) int16 {
+	return w.call(w.current(), "Probe", nil, ip, port)
+}
+
+func (w *Worker) AllcLibHndl3(ip string, port uint16, timeoutMs int32) (uint16, int16) {
+	p := w.current()
+	var child uint16
+	rc := w.call(p, "AllcLibHndl3", []any{&child}, ip, port, timeoutMs)
+	if rc != EW_OK {
+		return 0, rc
+	}
+	return w.register(p, child), rc
+}
+
+func (w *Worker) FreeLibHndl(handle uint16) int16 {
+	rc := w.invoke("FreeLibHndl", handle, nil)
+	w.mu.Lock()
+	delete(w.handles, handle)
+	w.mu.Unlock()
+	return rc
+}
+
+func (w *Worker) SysInfo(handle uint16) (model.SysInfo, int16) {
+	var info model.SysInfo
+	rc := w.invoke("SysInfo", handle, []any{&info})
+	return info, rc
+}
+
+func (w *Worker) StatInfo(handle uint16) (model.StatInfo, int16) {
+	var info model.StatInfo
+	rc := w.invoke("StatInfo", handle, []any{&info})
+	return info, rc
+}
+
+func (w *Worker) ExePrgName(handle uint16) (string, int64, int16) {
+	var name string
+	var number int64
+	rc := w.invoke("ExePrgName", handle, []any{&name, &number})
+	return name, number, rc
+}
+
+func (w *Worker) RdExecProg(handle uint16, length *uint16, blknum *int16, data []byte) int16 {
+	return w.invoke("RdExecProg", handle, nil, length, blknum, data)
+}
+
+func (w *Worker) RdPosition(handle uint16, posType int16, dataNum *int16, buf []byte) int16 {
+	return w.invoke("RdPosition", handle, nil, posType, dataNum, buf)
+}
+
+func (w *Worker) Diagnoss(handle uint16, diagNo int16, axisNo int16, length int16, buf []byte) int16 {
+	return w.invoke("Diagnoss", handle, nil, diagNo, axisNo, length, buf)
+}
+
+func (w *Worker) RdSpMeter(handle uint16, spType int16, num *int16, buf []byte) int16 {
+	return w.invoke("RdSpMeter", handle, nil, spType, num, buf)
+}
+
+func (w *Worker) RdSpLoad(handle uint16, spNo int16, buf []byte) int16 {
+	return w.invoke("RdSpLoad", handle, nil, spNo, buf)
+}
+
+func (w *Worker) RdSpeed(handle uint16, spType int16, buf []byte) int16 {
+	return w.invoke("RdSpeed", handle, nil, spType, buf)
+}
+
+func (w *Worker) Actf(handle uint16, buf []byte) int16 {
+	return w.invoke("Actf", handle, nil, buf)
+}
+
+func (w *Worker) RdTofs(handle uint16, number int16, ofsType int16, length int16, buf []byte) int16 {
+	return w.invoke("RdTofs", handle, nil, number, ofsType, length, buf)
+}
+
+func (w *Worker) RdAlmMsg(handle uint16, almType int16, num *int16, buf []byte) int16 {
+	return w.invoke("RdAlmMsg", handle, nil, almType, num, buf)
+}
+
+func (w *Worker) RdParam(handle uint16, prmNo int16, axisNo int16, length int16, buf []byte) int16 {
+	return w.invoke("RdParam", handle, nil, prmNo, axisNo, length, buf)
+}
+
+func (w *Worker) RdParar(handle uint16, start *int16, axisNo int16, end *int16, length *int16, buf []byte) int16 {
+	return w.invoke("RdParar", handle, nil, start, axisNo, end, length, buf)
+}
+
+func (w *Worker) GetPath(handle uint16) (int16, int16, int16) {
+	var path, maxPath int16
+	rc := w.invoke("GetPath", handle, []any{&path, &maxPath})
+	return path, maxPath, rc
+}
+
+func (w *Worker) SetPath(handle uint16, pathNo int16) int16 {
+	return w.invoke("SetPath", handle, nil, pathNo)
+}
+
+func (w *Worker) UpStart(handle uint16, progNum int16) int16 {
+	return w.invoke("UpStart", handle, nil, progNum)
+}
+
+func (w *Worker) UpStart4(handle uint16, upType int16, fileName string) int16 {
+	return w.invoke("UpStart4", handle, nil, upType, fileName)
+}
+
+func (w *Worker) Upload(handle uint16, buf []byte, length *uint16) int16 {
+	return w.invoke("Upload", handle, nil, buf, length)
+}
+
+func (w *Worker) UpEnd(handle uint16) int16 {
+	return w.invoke("UpEnd", handle, nil)
+}
+
+func (w *Worker) GetDtailErr(handle uint16) (int16, int16, int16) {
+	var errNo, errDtNo int16
+	rc := w.invoke("GetDtailErr", handle, []any{&errNo, &errDtNo})
+	return errNo, errDtNo, rc
+}
@@ -0,0 +1,114 @@
+package worker
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	. "github.com/iwtcode/fanucAdapter/focas/errcode"
+	"github.com/iwtcode/fanucAdapter/focas/model"
+)
+
+// request — вызов метода model.Backend в процессе-обработчике.
+// Аргументы-указатели передаются значениями, на которые они указывают.
+type request struct {
+	Method string
+	Args   []any
+}
+
+// response — результат вызова: все возвращаемые значения метода и новые значения
+// аргументов-указателей и буферов (по индексу аргумента).
+type response struct {
+	Results []any
+	Out     map[int]any
+	Err     string // Вызов не выполнен (неизвестный метод, неверные аргументы)
+}
+
+func init() {
+	gob.Register(model.SysInfo{})
+	gob.Register(model.StatInfo{})
+}
+
+// Serve выполняет вызовы FOCAS, поступающие из r, через backend и пишет ответы в w.
+// Вызовы выполняются по одному в порядке поступления. Serve возвращает nil,
+// когда родительский процесс закрывает r.
+func Serve(backend model.Backend, r io.Reader, w io.Writer) error {
+	dec := gob.NewDecoder(r)
+	enc := gob.NewEncoder(w)
+	target := reflect.ValueOf(probingBackend{backend})
+	for {
+		var req request
+		if err := dec.Decode(&req); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("read request: %w", err)
+		}
+		if err := enc.Encode(dispatch(target, req)); err != nil {
+			return fmt.Errorf("write response: %w", err)
+		}
+	}
+}
+
+// probingBackend добавляет бэкенду обработчика метод Probe, чтобы родительский
+// процесс мог вызывать его независимо от того, реализует ли бэкенд model.Prober.
+type probingBackend struct {
+	model.Backend
+}
+
+func (b probingBackend) Probe(ip string, port uint16) int16 {
+	if p, ok := b.Backend.(model.Prober); ok {
+		return p.Probe(ip, port)
+	}
+	return EW_OK
+}
+
+// dispatch вызывает метод бэкенда по имени. Паника в бэкенде не перехватывается:
+// как и сбой в libfwlib32, она завершает процесс-обработчик, который затем перезапускается.
+func dispatch(target reflect.Value, req request) response {
+	method := target.MethodByName(req.Method)
+	if !method.IsValid() {
+		return response{Err: fmt.Sprintf("unknown method %q", req.Method)}
+	}
+	mt := method.Type()
+	if mt.NumIn() != len(req.Args) {
+		return response{Err: fmt.Sprintf("%s: expected %d arguments, got %d", req.Method, mt.NumIn(), len(req.Args))}
+	}
+
+	in := make([]reflect.Value, len(req.Args))
+	for i, arg := range req.Args {
+		t := mt.In(i)
+		elem := t
+		if t.Kind() == reflect.Pointer {
+			elem = t.Elem()
+		}
+		v := reflect.ValueOf(arg)
+		if !v.IsValid() || !v.Type().ConvertibleTo(elem) {
+			return response{Err: fmt.Sprintf("%s: invalid argument %d", req.Method, i)}
+		}
+		v = v.Convert(elem)
+		if t.Kind() == reflect.Pointer {
+			p := reflect.New(elem)
+			p.Elem().Set(v)
+			v = p
+		}
+		in[i] = v
+	}
+
+	results := method.Call(in)
+	resp := response{Results: make([]any, len(results)), Out: make(map[int]any)}
+	for i, r := range results {
+		resp.Results[i] = r.Interface()
+	}
+	for i, v := range in {
+		switch v.Kind() {
+		case reflect.Pointer:
+			resp.Out[i] = v.Elem().Interface()
+		case reflect.Slice:
+			resp.Out[i] = v.Interface()
+		}
+	}
+	return resp
+}
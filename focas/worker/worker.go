@@ -0,0 +1,488 @@
+// Package worker выполняет вызовы FOCAS в отдельном процессе-обработчике.
+//
+// Сбой внутри libfwlib32 (например, segfault) завершает весь процесс, а глобальная
+// блокировка вызовов не дает опрашивать станки параллельно. Worker реализует
+// model.Backend, передавая каждый вызов дочернему процессу, который владеет своими
+// хендлами FOCAS. Супервизор перезапускает упавший процесс, а хендлы старого процесса
+// становятся недействительными (EW_HANDLE), поэтому адаптер переподключается
+// обычным путем. Вызовы разных процессов не сериализуются между собой.
+//
+// Исполняемый файл обработчика задается явно в Options.Command — обычно это
+// cmd/fanuc-worker. Он запускается с переменной окружения FANUC_WORKER=1. Если
+// обработчиком служит собственная программа, она должна передать ему управление
+// в начале main, иначе каждый запуск будет порождать новые копии программы:
+//
+//	func main() {
+//		if worker.IsWorker() {
+//			worker.Main()
+//		}
+//		...
+//	}
+package worker
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/iwtcode/fanucAdapter/focas"
+	. "github.com/iwtcode/fanucAdapter/focas/errcode"
+	"github.com/iwtcode/fanucAdapter/focas/ethernet"
+	"github.com/iwtcode/fanucAdapter/focas/model"
+	"github.com/sirupsen/logrus"
+)
+
+// Переменные окружения процесса-обработчика
+const (
+	// EnvWorker со значением "1" означает, что процесс запущен как обработчик.
+	EnvWorker = "FANUC_WORKER"
+	// EnvTransport выбирает бэкенд обработчика: "fwlib" или "ethernet" (как Config.Transport).
+	EnvTransport = "FANUC_WORKER_TRANSPORT"
+)
+
+// Options задает параметры процесса-обработчика.
+type Options struct {
+	// Command — исполняемый файл обработчика, например cmd/fanuc-worker. Обязателен.
+	Command string
+	Args    []string
+	// Env — дополнительные переменные окружения обработчика ("KEY=value").
+	Env []string
+	// Transport — бэкенд FOCAS внутри обработчика. Пустое значение — focas.DefaultBackend.
+	Transport string
+
+	// CallTimeout ограничивает время одного вызова. Зависший обработчик
+	// завершается и перезапускается. По умолчанию 1 мин.
+	CallTimeout time.Duration
+	// RestartBackoff — пауза перед первым перезапуском. По умолчанию 500 мс.
+	RestartBackoff time.Duration
+	// MaxRestartBackoff ограничивает паузу между перезапусками. По умолчанию 30 с.
+	MaxRestartBackoff time.Duration
+
+	// Stderr получает stderr обработчика. По умолчанию os.Stderr.
+	Stderr io.Writer
+	// Logger — логгер супервизора. По умолчанию logrus.StandardLogger().
+	Logger logrus.FieldLogger
+}
+
+func (o Options) withDefaults() Options {
+	if o.CallTimeout <= 0 {
+		o.CallTimeout = time.Minute
+	}
+	if o.RestartBackoff <= 0 {
+		o.RestartBackoff = 500 * time.Millisecond
+	}
+	if o.MaxRestartBackoff <= 0 {
+		o.MaxRestartBackoff = 30 * time.Second
+	}
+	if o.Stderr == nil {
+		o.Stderr = os.Stderr
+	}
+	if o.Logger == nil {
+		o.Logger = logrus.StandardLogger()
+	}
+	return o
+}
+
+// IsWorker сообщает, запущен ли текущий процесс как обработчик.
+func IsWorker() bool {
+	return os.Getenv(EnvWorker) == "1"
+}
+
+// protocolFD — дескриптор обработчика, в который пишутся ответы (exec.Cmd.ExtraFiles).
+// Для протокола нужен отдельный канал: libfwlib32 и cgo-код могут писать в fd 1
+// в обход os.Stdout, а stdout обработчика передается в Options.Stderr.
+const protocolFD = 3
+
+// Main обслуживает вызовы родительского процесса (см. ServeParent) и завершает процесс.
+func Main() {
+	backend, err := newBackend(os.Getenv(EnvTransport))
+	if err == nil {
+		err = ServeParent(backend)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fanuc worker:", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// ServeParent выполняет вызовы родительского процесса через backend: запросы
+// поступают через stdin, ответы отправляются в дескриптор протокола.
+func ServeParent(backend model.Backend) error {
+	out := os.NewFile(protocolFD, "fanuc-worker-protocol")
+	if out == nil {
+		return errors.New("protocol descriptor is not available")
+	}
+	defer out.Close()
+	return Serve(backend, os.Stdin, out)
+}
+
+// newBackend выбирает бэкенд обработчика так же, как клиент выбирает его по Config.Transport.
+func newBackend(transport string) (model.Backend, error) {
+	switch transport {
+	case "":
+		return focas.DefaultBackend(), nil
+	case "fwlib":
+		backend := focas.DefaultBackend()
+		if _, ok := backend.(*ethernet.Backend); ok {
+			return nil, errors.New("transport \"fwlib\" is not available: built without cgo")
+		}
+		return backend, nil
+	case "ethernet":
+		return ethernet.New(), nil
+	default:
+		return nil, fmt.Errorf("unknown FOCAS transport %q", transport)
+	}
+}
+
+// Worker — model.Backend, выполняющий вызовы в процессе-обработчике под надзором супервизора.
+type Worker struct {
+	opts   Options
+	logger logrus.FieldLogger
+	callMu sync.Mutex // Блокировка вызовов для адаптеров (model.CallLocker)
+
+	mu         sync.Mutex
+	proc       *process             // Текущий процесс; nil, пока обработчик перезапускается
+	gen        uint64               // Номер последнего запущенного процесса
+	handles    map[uint16]handleRef // Хендлы, выданные адаптерам
+	nextHandle uint16
+	startup    *request // Последний вызов Startup, повторяется в новом процессе
+	restarts   int
+	closed     bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// handleRef связывает хендл, выданный адаптеру, с хендлом в процессе-обработчике.
+type handleRef struct {
+	gen   uint64
+	child uint16
+}
+
+// process — запущенный процесс-обработчик.
+type process struct {
+	gen     uint64
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	out     *os.File // Чтение ответов (дескриптор протокола обработчика)
+	timeout time.Duration
+
+	mu  sync.Mutex // Сериализует обмен запрос-ответ
+	enc *gob.Encoder
+	dec *gob.Decoder
+}
+
+// Убедимся, что Worker реализует model.Backend, собственную блокировку вызовов
+// и проверку доступности станка.
+var (
+	_ model.Backend    = (*Worker)(nil)
+	_ model.CallLocker = (*Worker)(nil)
+	_ model.Prober     = (*Worker)(nil)
+)
+
+// ErrClosed возвращается при запуске закрытого обработчика.
+var ErrClosed = errors.New("worker is closed")
+
+// ErrNoCommand возвращается, если в Options.Command не указан исполняемый файл обработчика.
+var ErrNoCommand = errors.New("worker command is not set")
+
+// New запускает процесс-обработчик и супервизор, перезапускающий его после сбоев.
+// Ошибка возвращается, если процесс не удалось запустить в первый раз.
+func New(opts Options) (*Worker, error) {
+	if opts.Command == "" {
+		return nil, ErrNoCommand
+	}
+	opts = opts.withDefaults()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Worker{
+		opts:    opts,
+		logger:  opts.Logger,
+		handles: make(map[uint16]handleRef),
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+
+	p, err := w.spawn()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	go w.supervise(p)
+	return w, nil
+}
+
+// CallLock возвращает блокировку вызовов этого обработчика (реализация model.CallLocker).
+// Вызовы разных обработчиков выполняются параллельно.
+func (w *Worker) CallLock() sync.Locker {
+	return &w.callMu
+}
+
+// Restarts возвращает количество перезапусков процесса-обработчика.
+func (w *Worker) Restarts() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.restarts
+}
+
+// Running сообщает, запущен ли сейчас процесс-обработчик.
+func (w *Worker) Running() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.proc != nil
+}
+
+// Close останавливает супервизор и завершает процесс-обработчик.
+func (w *Worker) Close() {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	w.closed = true
+	p := w.proc
+	w.mu.Unlock()
+
+	w.cancel()
+	if p != nil {
+		// Закрытый stdin завершает Serve; зависший процесс завершаем принудительно
+		p.stdin.Close()
+		timer := time.AfterFunc(time.Second, p.kill)
+		defer timer.Stop()
+	}
+	<-w.done
+}
+
+// spawn запускает новый процесс-обработчик и повторяет в нем Startup.
+func (w *Worker) spawn() (*process, error) {
+	cmd := exec.Command(w.opts.Command, w.opts.Args...)
+	cmd.Env = append(os.Environ(), EnvWorker+"=1", EnvTransport+"="+w.opts.Transport)
+	cmd.Env = append(cmd.Env, w.opts.Env...)
+	// Вывод обработчика в stdout не относится к протоколу
+	cmd.Stdout = w.opts.Stderr
+	cmd.Stderr = w.opts.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, protocol, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.ExtraFiles = []*os.File{protocol} // protocolFD в обработчике
+	err = cmd.Start()
+	protocol.Close()
+	if err != nil {
+		out.Close()
+		return nil, fmt.Errorf("start worker %s: %w", w.opts.Command, err)
+	}
+
+	w.mu.Lock()
+	w.gen++
+	p := &process{
+		gen:     w.gen,
+		cmd:     cmd,
+		stdin:   stdin,
+		out:     out,
+		timeout: w.opts.CallTimeout,
+		enc:     gob.NewEncoder(stdin),
+		dec:     gob.NewDecoder(out),
+	}
+	startup := w.startup
+	w.mu.Unlock()
+
+	// Новый процесс публикуется только после инициализации библиотеки
+	if startup != nil {
+		if _, err := p.exchange(*startup); err != nil {
+			w.logger.Warnf("Worker %d: startup failed: %v", cmd.Process.Pid, err)
+		}
+	}
+
+	w.mu.Lock()
+	w.proc = p
+	w.mu.Unlock()
+	w.logger.Debugf("Worker %d started", cmd.Process.Pid)
+	return p, nil
+}
+
+// supervise ожидает завершения процесса и перезапускает его с экспоненциальной паузой.
+// Пауза сбрасывается, если процесс проработал дольше MaxRestartBackoff.
+func (w *Worker) supervise(p *process) {
+	defer close(w.done)
+	backoff := w.opts.RestartBackoff
+	for {
+		started := time.Now()
+		err := p.cmd.Wait()
+		p.out.Close()
+
+		w.mu.Lock()
+		w.proc = nil
+		w.mu.Unlock()
+
+		if w.ctx.Err() != nil {
+			return
+		}
+		w.logger.Warnf("Worker %d exited: %v", p.cmd.Process.Pid, err)
+		if time.Since(started) > w.opts.MaxRestartBackoff {
+			backoff = w.opts.RestartBackoff
+		}
+
+		for {
+			select {
+			case <-w.ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, w.opts.MaxRestartBackoff)
+
+			next, err := w.spawn()
+			if err != nil {
+				w.logger.Errorf("Worker restart failed: %v", err)
+				continue
+			}
+			p = next
+			break
+		}
+
+		w.mu.Lock()
+		w.restarts++
+		closed := w.closed
+		w.mu.Unlock()
+		if closed {
+			// Close мог вызваться во время перезапуска
+			p.stdin.Close()
+			p.kill()
+		}
+	}
+}
+
+// current возвращает текущий процесс или nil.
+func (w *Worker) current() *process {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.proc
+}
+
+// resolve находит процесс и хендл обработчика для хендла адаптера.
+// ok равен false, если хендл неизвестен или выдан процессом, который уже завершился.
+func (w *Worker) resolve(handle uint16) (p *process, child uint16, ok bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ref, known := w.handles[handle]
+	if !known {
+		return nil, 0, false
+	}
+	if w.proc == nil {
+		return nil, 0, true
+	}
+	if ref.gen != w.proc.gen {
+		return nil, 0, false
+	}
+	return w.proc, ref.child, true
+}
+
+// register выдает адаптеру хендл для хендла child процесса p.
+func (w *Worker) register(p *process, child uint16) uint16 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for {
+		w.nextHandle++
+		if _, used := w.handles[w.nextHandle]; w.nextHandle != 0 && !used {
+			break
+		}
+	}
+	w.handles[w.nextHandle] = handleRef{gen: p.gen, child: child}
+	return w.nextHandle
+}
+
+// invoke вызывает метод с хендлом адаптера, заменяя его хендлом обработчика.
+func (w *Worker) invoke(method string, handle uint16, results []any, args ...any) int16 {
+	p, child, ok := w.resolve(handle)
+	if !ok {
+		return EW_HANDLE
+	}
+	return w.call(p, method, results, append([]any{child}, args...)...)
+}
+
+// call выполняет метод в процессе p. Аргументы-указатели и буферы обновляются по ответу,
+// results получают все возвращаемые значения, кроме последнего — кода возврата.
+// Если процесс недоступен или завершился во время вызова, возвращается EW_SOCKET.
+func (w *Worker) call(p *process, method string, results []any, args ...any) int16 {
+	if p == nil {
+		return EW_SOCKET
+	}
+
+	req := request{Method: method, Args: make([]any, len(args))}
+	for i, arg := range args {
+		v := reflect.ValueOf(arg)
+		if v.Kind() == reflect.Pointer {
+			v = v.Elem()
+		}
+		req.Args[i] = v.Interface()
+	}
+
+	resp, err := p.exchange(req)
+	if err != nil {
+		w.logger.Warnf("Worker %d: %s: %v", p.cmd.Process.Pid, method, err)
+		return EW_SOCKET
+	}
+	if resp.Err != "" || len(resp.Results) != len(results)+1 {
+		w.logger.Errorf("Worker %d: %s: bad response: %s", p.cmd.Process.Pid, method, resp.Err)
+		return EW_FUNC
+	}
+
+	for i, arg := range args {
+		out, ok := resp.Out[i]
+		if !ok {
+			continue
+		}
+		switch v := reflect.ValueOf(arg); v.Kind() {
+		case reflect.Pointer:
+			v.Elem().Set(reflect.ValueOf(out).Convert(v.Elem().Type()))
+		case reflect.Slice:
+			reflect.Copy(v, reflect.ValueOf(out))
+		}
+	}
+	for i, r := range results {
+		v := reflect.ValueOf(r).Elem()
+		v.Set(reflect.ValueOf(resp.Results[i]).Convert(v.Type()))
+	}
+	rc, _ := resp.Results[len(results)].(int16)
+	return rc
+}
+
+// exchange отправляет запрос и ждет ответ. Если ответ не получен за timeout или
+// поток поврежден, процесс завершается, чтобы супервизор его перезапустил.
+func (p *process) exchange(req request) (response, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	timer := time.AfterFunc(p.timeout, p.kill)
+	defer timer.Stop()
+
+	var resp response
+	if err := p.enc.Encode(req); err != nil {
+		p.kill()
+		return resp, err
+	}
+	if err := p.dec.Decode(&resp); err != nil {
+		p.kill()
+		return resp, err
+	}
+	return resp, nil
+}
+
+func (p *process) kill() {
+	_ = p.cmd.Process.Kill()
+}
@@ -0,0 +1,305 @@
+// Package gateway реализует REST-шлюз к станкам FANUC: JSON-эндпоинты чтения данных
+// по ID станка, документ OpenAPI и проверки живости и готовности для Kubernetes.
+//
+// Ошибки возвращаются телом apperrors.AppError ({"code": ..., "message": ...}); причина
+// добавляется в поле details, если ее можно показывать клиенту (AppError.IsUserFacing).
+// Ошибки FOCAS преобразуются в HTTP-статусы функцией focas.ToAppError.
+package gateway
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	apperrors "github.com/iwtcode/fanucAdapter/errors"
+	"github.com/iwtcode/fanucAdapter/focas"
+	"github.com/iwtcode/fanucAdapter/models"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultAddr — адрес шлюза по умолчанию.
+const DefaultAddr = ":8080"
+
+//go:embed openapi.json
+var openAPIDocument []byte
+
+// OpenAPI возвращает документ OpenAPI 3 с описанием эндпоинтов шлюза.
+func OpenAPI() []byte {
+	return openAPIDocument
+}
+
+// Options задает параметры шлюза.
+type Options struct {
+	// Timeout ограничивает время чтения данных станка в одном запросе. По умолчанию 10 с.
+	Timeout time.Duration
+	// Logger — журнал запросов с ошибками. По умолчанию logrus.StandardLogger().
+	Logger *logrus.Logger
+}
+
+// Machine — описание станка в ответах /machines.
+type Machine struct {
+	ID         string                  `json:"id"`
+	Tags       map[string]string       `json:"tags,omitempty"`
+	Connection models.ConnectionStatus `json:"connection"`
+	SystemInfo *models.SystemInfo      `json:"system_info,omitempty"` // nil до первого подключения
+}
+
+// Gateway — HTTP-обработчик шлюза. Станки добавляются методом Add.
+type Gateway struct {
+	opts Options
+	mux  *http.ServeMux
+
+	mu       sync.RWMutex
+	machines map[string]*machine
+}
+
+// machine — станок шлюза.
+type machine struct {
+	id     string
+	client *fanuc.Client
+	tags   map[string]string
+}
+
+// endpoint читает данные станка для одного маршрута.
+type endpoint func(ctx context.Context, m *machine, r *http.Request) (any, error)
+
+// New создает шлюз без станков.
+func New(opts Options) *Gateway {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.Logger == nil {
+		opts.Logger = logrus.StandardLogger()
+	}
+	g := &Gateway{
+		opts:     opts,
+		mux:      http.NewServeMux(),
+		machines: make(map[string]*machine),
+	}
+
+	g.mux.HandleFunc("/healthz", g.get(g.healthz))
+	g.mux.HandleFunc("/readyz", g.get(g.readyz))
+	g.mux.HandleFunc("/openapi.json", g.get(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPIDocument)
+	}))
+	g.mux.HandleFunc("/machines", g.get(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, g.Machines())
+	}))
+	g.mux.HandleFunc("/machines/{id}", g.machine(func(ctx context.Context, m *machine, r *http.Request) (any, error) {
+		return m.describe(), nil
+	}))
+	g.mux.HandleFunc("/machines/{id}/state", g.machine(func(ctx context.Context, m *machine, r *http.Request) (any, error) {
+		return m.client.GetMachineStateCtx(ctx)
+	}))
+	g.mux.HandleFunc("/machines/{id}/axes", g.machine(func(ctx context.Context, m *machine, r *http.Request) (any, error) {
+		return m.client.GetAxisDataCtx(ctx)
+	}))
+	g.mux.HandleFunc("/machines/{id}/spindles", g.machine(func(ctx context.Context, m *machine, r *http.Request) (any, error) {
+		return m.client.GetSpindleDataCtx(ctx)
+	}))
+	g.mux.HandleFunc("/machines/{id}/alarms", g.machine(func(ctx context.Context, m *machine, r *http.Request) (any, error) {
+		return m.client.GetAlarmsCtx(ctx)
+	}))
+	g.mux.HandleFunc("/machines/{id}/program", g.machine(func(ctx context.Context, m *machine, r *http.Request) (any, error) {
+		return m.client.GetProgramInfoCtx(ctx)
+	}))
+	g.mux.HandleFunc("/machines/{id}/program/source", g.machine(readProgramSource))
+	g.mux.HandleFunc("/machines/{id}/parameters", g.machine(func(ctx context.Context, m *machine, r *http.Request) (any, error) {
+		return m.client.GetParameterInfoCtx(ctx)
+	}))
+	g.mux.HandleFunc("/machines/{id}/current", g.machine(readCurrent))
+	g.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		g.writeError(w, r, apperrors.NewAppError(apperrors.NotFoundErrorCode, apperrors.NotFound,
+			fmt.Errorf("unknown path %q", r.URL.Path), true))
+	})
+	return g
+}
+
+// Add добавляет станок client с идентификатором id и метками tags.
+func (g *Gateway) Add(id string, client *fanuc.Client, tags map[string]string) error {
+	if id == "" {
+		return fmt.Errorf("gateway: empty machine id")
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, exists := g.machines[id]; exists {
+		return fmt.Errorf("gateway: machine %q already exists", id)
+	}
+	g.machines[id] = &machine{id: id, client: client, tags: tags}
+	return nil
+}
+
+// Remove удаляет станок id. Клиент станка не закрывается.
+func (g *Gateway) Remove(id string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.machines, id)
+}
+
+// Machines возвращает описания станков, упорядоченные по ID.
+func (g *Gateway) Machines() []Machine {
+	g.mu.RLock()
+	machines := make([]Machine, 0, len(g.machines))
+	for _, m := range g.machines {
+		machines = append(machines, m.describe())
+	}
+	g.mu.RUnlock()
+	sort.Slice(machines, func(i, j int) bool { return machines[i].ID < machines[j].ID })
+	return machines
+}
+
+// ServeHTTP реализует http.Handler.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		if p := recover(); p != nil {
+			g.writeError(w, r, apperrors.NewAppError(apperrors.InternalServerErrorCode, apperrors.InternalServerError,
+				fmt.Errorf("panic: %v", p), false))
+		}
+	}()
+	g.mux.ServeHTTP(w, r)
+}
+
+func (m *machine) describe() Machine {
+	return Machine{
+		ID:         m.id,
+		Tags:       m.tags,
+		Connection: m.client.ConnectionStatus(),
+		SystemInfo: m.client.GetSystemInfo(),
+	}
+}
+
+// get разрешает для обработчика только методы GET и HEAD.
+func (g *Gateway) get(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			g.writeError(w, r, apperrors.NewAppError(apperrors.MethodNotAllowedCode, apperrors.MethodNotAllowed,
+				fmt.Errorf("method %s is not allowed", r.Method), true))
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// machine возвращает обработчик маршрута станка {id}: ищет станок, ограничивает время
+// чтения и записывает результат или ошибку.
+func (g *Gateway) machine(read endpoint) http.HandlerFunc {
+	return g.get(func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		g.mu.RLock()
+		m, ok := g.machines[id]
+		g.mu.RUnlock()
+		if !ok {
+			g.writeError(w, r, apperrors.NewAppError(apperrors.NotFoundErrorCode, apperrors.NotFound,
+				fmt.Errorf("machine %q not found", id), true))
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), g.opts.Timeout)
+		defer cancel()
+		data, err := read(ctx, m, r)
+		if err != nil {
+			g.writeError(w, r, focas.ToAppError(err))
+			return
+		}
+		writeJSON(w, http.StatusOK, data)
+	})
+}
+
+func readProgramSource(ctx context.Context, m *machine, r *http.Request) (any, error) {
+	info, err := m.client.GetProgramInfoCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	source, err := m.client.GetControlProgramCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return models.ControlProgram{ProgramInfo: *info, GCode: source}, nil
+}
+
+// readCurrent возвращает сводные данные. Параметр sections (через запятую) ограничивает
+// набор читаемых разделов, например ?sections=state,axes.
+func readCurrent(ctx context.Context, m *machine, r *http.Request) (any, error) {
+	param := r.URL.Query().Get("sections")
+	if param == "" {
+		return m.client.GetCurrentDataCtx(ctx)
+	}
+
+	known := make(map[models.Section]bool)
+	for _, section := range models.AllSections() {
+		known[section] = true
+	}
+	var sections []models.Section
+	for _, name := range strings.Split(param, ",") {
+		section := models.Section(strings.TrimSpace(name))
+		if !known[section] {
+			return nil, apperrors.NewAppError(apperrors.BadRequestCode, apperrors.BadRequest,
+				fmt.Errorf("unknown section %q", section), true)
+		}
+		sections = append(sections, section)
+	}
+	return m.client.GetCurrentDataSections(ctx, sections...)
+}
+
+// healthz сообщает, что процесс шлюза работает. Состояние станков не учитывается.
+func (g *Gateway) healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// readyz сообщает о готовности принимать запросы: хотя бы один станок подключен.
+// Недоступность отдельных станков не выводит шлюз из балансировки.
+func (g *Gateway) readyz(w http.ResponseWriter, r *http.Request) {
+	states := make(map[string]models.ConnectionState)
+	ready := false
+	for _, m := range g.Machines() {
+		states[m.ID] = m.Connection.State
+		if m.Connection.State == models.ConnectionConnected {
+			ready = true
+		}
+	}
+	status, code := "ready", http.StatusOK
+	if !ready {
+		status, code = "not ready", http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, struct {
+		Status   string                            `json:"status"`
+		Machines map[string]models.ConnectionState `json:"machines"`
+	}{status, states})
+}
+
+// errorBody — тело ответа с ошибкой: поля AppError и причина, если ее можно показывать клиенту.
+type errorBody struct {
+	*apperrors.AppError
+	Details string `json:"details,omitempty"`
+}
+
+func (g *Gateway) writeError(w http.ResponseWriter, r *http.Request, appErr *apperrors.AppError) {
+	body := errorBody{AppError: appErr}
+	if appErr.IsUserFacing && appErr.Err != nil {
+		body.Details = appErr.Err.Error()
+	}
+	if appErr.Code >= http.StatusInternalServerError {
+		g.opts.Logger.WithError(appErr).Warnf("%s %s", r.Method, r.URL.Path)
+	}
+	writeJSON(w, appErr.Code, body)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
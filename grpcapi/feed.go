@@ -0,0 +1,137 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// feedKey определяет общую подписку: станок и интервалы опроса групп.
+type feedKey struct {
+	machine   *machine
+	intervals string
+}
+
+// feed — подписка станка, общая для всех вызовов Watch с теми же интервалами.
+// Поля subs и last защищены Server.feedsMu.
+type feed struct {
+	cancel context.CancelFunc
+	subs   map[chan fanuc.Snapshot]struct{}
+	last   *fanuc.Snapshot // Последний снимок; передается подключившимся позже
+}
+
+// subscribe подключает вызов Watch к общей подписке станка m с параметрами opts
+// и создает подписку при первом подключении. Подписки с разными интервалами
+// ограничены Options.MaxSubscriptions. Возвращает канал снимков, который
+// закрывается при завершении подписки, и функцию отключения.
+func (s *Server) subscribe(m *machine, opts fanuc.SubscribeOptions) (<-chan fanuc.Snapshot, func(), error) {
+	key := feedKey{machine: m, intervals: intervalsKey(opts.Intervals)}
+
+	s.feedsMu.Lock()
+	defer s.feedsMu.Unlock()
+	f := s.feeds[key]
+	if f == nil {
+		active := 0
+		for k := range s.feeds {
+			if k.machine == m {
+				active++
+			}
+		}
+		if active >= s.opts.MaxSubscriptions {
+			return nil, nil, status.Errorf(codes.ResourceExhausted,
+				"machine %q already has %d subscriptions with other intervals", m.id, active)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		snapshots, err := m.client.Subscribe(ctx, opts)
+		if err != nil {
+			cancel()
+			return nil, nil, statusError(err)
+		}
+		f = &feed{cancel: cancel, subs: make(map[chan fanuc.Snapshot]struct{})}
+		s.feeds[key] = f
+		go s.run(key, f, snapshots)
+	}
+
+	ch := make(chan fanuc.Snapshot, s.opts.Buffer)
+	if f.last != nil {
+		ch <- *f.last
+	}
+	f.subs[ch] = struct{}{}
+	return ch, func() { s.unsubscribe(key, f, ch) }, nil
+}
+
+// run рассылает снимки подписки f до ее завершения, затем закрывает каналы подключенных вызовов.
+func (s *Server) run(key feedKey, f *feed, snapshots <-chan fanuc.Snapshot) {
+	for snapshot := range snapshots {
+		s.feedsMu.Lock()
+		f.last = &snapshot
+		for ch := range f.subs {
+			sendLatest(ch, snapshot)
+		}
+		s.feedsMu.Unlock()
+	}
+
+	s.feedsMu.Lock()
+	defer s.feedsMu.Unlock()
+	if s.feeds[key] == f {
+		delete(s.feeds, key)
+	}
+	for ch := range f.subs {
+		close(ch)
+	}
+	f.subs = nil
+	f.cancel()
+}
+
+// unsubscribe отключает канал ch от подписки f и останавливает ее после отключения последнего вызова.
+func (s *Server) unsubscribe(key feedKey, f *feed, ch chan fanuc.Snapshot) {
+	s.feedsMu.Lock()
+	defer s.feedsMu.Unlock()
+	if _, ok := f.subs[ch]; !ok {
+		return
+	}
+	delete(f.subs, ch)
+	if len(f.subs) > 0 {
+		return
+	}
+	if s.feeds[key] == f {
+		delete(s.feeds, key)
+	}
+	f.cancel()
+}
+
+// sendLatest отправляет снимок в ch, при заполненном буфере вытесняя самый старый
+// (как fanuc.DropOldest). Вызывается только отправителем канала.
+func sendLatest(ch chan fanuc.Snapshot, snapshot fanuc.Snapshot) {
+	for {
+		select {
+		case ch <- snapshot:
+			return
+		default:
+		}
+		select {
+		case <-ch:
+		default:
+		}
+	}
+}
+
+// intervalsKey возвращает каноническую запись интервалов; nil — интервалы по умолчанию.
+func intervalsKey(intervals map[fanuc.DataGroup]time.Duration) string {
+	if intervals == nil {
+		return "default"
+	}
+	var b strings.Builder
+	for _, group := range slices.Sorted(maps.Keys(intervals)) {
+		fmt.Fprintf(&b, "%s=%s;", group, intervals[group])
+	}
+	return b.String()
+}
@@ -0,0 +1,409 @@
+// Package grpcapi реализует gRPC-сервис fanuc.v1.MachineService (api/fanuc/v1/service.proto)
+// поверх клиентов fanuc.Client: разовые чтения данных станка по его ID и потоковый вызов
+// Watch, который передает снимки подписки (fanuc.Client.Subscribe) и события изменений
+// (fanuc.EventDetector). Вызовы Watch с одинаковыми интервалами используют общую
+// подписку станка, поэтому число наблюдателей не увеличивает число вызовов FOCAS.
+//
+// Ошибки чтения преобразуются функцией focas.ToAppError и передаются кодами gRPC,
+// соответствующими HTTP-статусам REST-шлюза (пакет gateway).
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	fanucv1 "github.com/iwtcode/fanucAdapter/api/fanuc/v1"
+	apperrors "github.com/iwtcode/fanucAdapter/errors"
+	"github.com/iwtcode/fanucAdapter/focas"
+	"github.com/iwtcode/fanucAdapter/models"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Options задает параметры сервиса.
+type Options struct {
+	// Timeout ограничивает время разового чтения, если вызов не задал меньший дедлайн.
+	// По умолчанию 10 с.
+	Timeout time.Duration
+	// Buffer — количество сообщений Watch, ожидающих отправки клиенту. По умолчанию 16.
+	// Снимки подписки при заполненном буфере вытесняются (fanuc.DropOldest).
+	Buffer int
+	// MaxSubscriptions ограничивает число одновременных подписок одного станка: вызовы
+	// Watch с новыми интервалами сверх него получают RESOURCE_EXHAUSTED. По умолчанию 4.
+	MaxSubscriptions int
+	// Logger — журнал ошибок преобразования событий. По умолчанию logrus.StandardLogger().
+	Logger *logrus.Logger
+}
+
+// Server — реализация fanucv1.MachineServiceServer. Станки добавляются методом Add.
+type Server struct {
+	fanucv1.UnimplementedMachineServiceServer
+	opts Options
+
+	mu       sync.RWMutex
+	machines map[string]*machine
+
+	feedsMu sync.Mutex
+	feeds   map[feedKey]*feed // Общие подписки вызовов Watch
+}
+
+// machine — станок сервиса.
+type machine struct {
+	id     string
+	client *fanuc.Client
+	tags   map[string]string
+}
+
+// knownGroups — группы подписки, допустимые в WatchRequest.Intervals.
+var knownGroups = map[fanuc.DataGroup]bool{
+	fanuc.GroupState:      true,
+	fanuc.GroupPositions:  true,
+	fanuc.GroupSpindles:   true,
+	fanuc.GroupFeed:       true,
+	fanuc.GroupParameters: true,
+	fanuc.GroupPaths:      true,
+	fanuc.GroupSystem:     true,
+}
+
+// NewServer создает сервис без станков.
+func NewServer(opts Options) *Server {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.Buffer <= 0 {
+		opts.Buffer = 16
+	}
+	if opts.MaxSubscriptions <= 0 {
+		opts.MaxSubscriptions = 4
+	}
+	if opts.Logger == nil {
+		opts.Logger = logrus.StandardLogger()
+	}
+	return &Server{opts: opts, machines: make(map[string]*machine), feeds: make(map[feedKey]*feed)}
+}
+
+// Register регистрирует сервис на сервере gRPC.
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	fanucv1.RegisterMachineServiceServer(registrar, s)
+}
+
+// Add добавляет станок client с идентификатором id и метками tags.
+func (s *Server) Add(id string, client *fanuc.Client, tags map[string]string) error {
+	if id == "" {
+		return fmt.Errorf("grpcapi: empty machine id")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.machines[id]; exists {
+		return fmt.Errorf("grpcapi: machine %q already exists", id)
+	}
+	s.machines[id] = &machine{id: id, client: client, tags: tags}
+	return nil
+}
+
+// Remove удаляет станок id. Клиент станка не закрывается, текущие вызовы Watch продолжаются.
+func (s *Server) Remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.machines, id)
+}
+
+func (s *Server) ListMachines(ctx context.Context, req *fanucv1.ListMachinesRequest) (*fanucv1.ListMachinesResponse, error) {
+	s.mu.RLock()
+	resp := &fanucv1.ListMachinesResponse{Machines: make([]*fanucv1.Machine, 0, len(s.machines))}
+	for _, m := range s.machines {
+		resp.Machines = append(resp.Machines, &fanucv1.Machine{
+			Id:         m.id,
+			Tags:       m.tags,
+			Connection: fanucv1.FromConnectionStatus(m.client.ConnectionStatus()),
+			SystemInfo: fanucv1.FromSystemInfo(m.client.GetSystemInfo()),
+		})
+	}
+	s.mu.RUnlock()
+	sort.Slice(resp.Machines, func(i, j int) bool { return resp.Machines[i].Id < resp.Machines[j].Id })
+	return resp, nil
+}
+
+func (s *Server) GetSystemInfo(ctx context.Context, req *fanucv1.MachineRequest) (*fanucv1.SystemInfo, error) {
+	m, err := s.machine(req.GetMachineId())
+	if err != nil {
+		return nil, err
+	}
+	info := m.client.GetSystemInfo()
+	if info == nil {
+		return nil, statusError(fmt.Errorf("system info of %q: %w", m.id, focas.ErrNotConnected))
+	}
+	return fanucv1.FromSystemInfo(info), nil
+}
+
+func (s *Server) GetCurrentData(ctx context.Context, req *fanucv1.GetCurrentDataRequest) (*fanucv1.MachineData, error) {
+	m, err := s.machine(req.GetMachineId())
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[models.Section]bool)
+	for _, section := range models.AllSections() {
+		known[section] = true
+	}
+	sections := make([]models.Section, 0, len(req.GetSections()))
+	for _, name := range req.GetSections() {
+		section := models.Section(name)
+		if !known[section] {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown section %q", name)
+		}
+		sections = append(sections, section)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+	var data *models.AggregatedData
+	if len(sections) == 0 {
+		data, err = m.client.GetCurrentDataCtx(ctx)
+	} else {
+		data, err = m.client.GetCurrentDataSections(ctx, sections...)
+	}
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return fanucv1.FromMachineData(data), nil
+}
+
+func (s *Server) GetAxes(ctx context.Context, req *fanucv1.MachineRequest) (*fanucv1.GetAxesResponse, error) {
+	axes, err := read(s, ctx, req, (*fanuc.Client).GetAxisDataCtx)
+	if err != nil {
+		return nil, err
+	}
+	return &fanucv1.GetAxesResponse{Axes: fanucv1.FromAxes(axes)}, nil
+}
+
+func (s *Server) GetSpindles(ctx context.Context, req *fanucv1.MachineRequest) (*fanucv1.GetSpindlesResponse, error) {
+	spindles, err := read(s, ctx, req, (*fanuc.Client).GetSpindleDataCtx)
+	if err != nil {
+		return nil, err
+	}
+	return &fanucv1.GetSpindlesResponse{Spindles: fanucv1.FromSpindles(spindles)}, nil
+}
+
+func (s *Server) GetAlarms(ctx context.Context, req *fanucv1.MachineRequest) (*fanucv1.GetAlarmsResponse, error) {
+	alarms, err := read(s, ctx, req, (*fanuc.Client).GetAlarmsCtx)
+	if err != nil {
+		return nil, err
+	}
+	return &fanucv1.GetAlarmsResponse{Alarms: fanucv1.FromAlarms(alarms)}, nil
+}
+
+func (s *Server) GetParameters(ctx context.Context, req *fanucv1.MachineRequest) (*fanucv1.ParameterInfo, error) {
+	params, err := read(s, ctx, req, (*fanuc.Client).GetParameterInfoCtx)
+	if err != nil {
+		return nil, err
+	}
+	return fanucv1.FromParameterInfo(params), nil
+}
+
+func (s *Server) GetProgram(ctx context.Context, req *fanucv1.MachineRequest) (*fanucv1.Program, error) {
+	program, err := read(s, ctx, req, (*fanuc.Client).GetProgramInfoCtx)
+	if err != nil {
+		return nil, err
+	}
+	return fanucv1.FromProgramInfo(program), nil
+}
+
+// read выполняет разовое чтение fn у станка из запроса с ограничением времени.
+func read[T any](s *Server, ctx context.Context, req *fanucv1.MachineRequest, fn func(*fanuc.Client, context.Context) (T, error)) (T, error) {
+	var zero T
+	m, err := s.machine(req.GetMachineId())
+	if err != nil {
+		return zero, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+	result, err := fn(m.client, ctx)
+	if err != nil {
+		return zero, statusError(err)
+	}
+	return result, nil
+}
+
+// Watch подписывается на станки запроса и передает их снимки и события изменений
+// до отмены вызова. Сообщения разных станков чередуются в порядке получения.
+// Подключившийся к уже работающей подписке вызов сначала получает ее последний снимок.
+func (s *Server) Watch(req *fanucv1.WatchRequest, stream grpc.ServerStreamingServer[fanucv1.WatchResponse]) error {
+	machines, err := s.watched(req.GetMachineIds())
+	if err != nil {
+		return err
+	}
+	opts := fanuc.SubscribeOptions{Buffer: s.opts.Buffer}
+	if len(req.GetIntervals()) > 0 {
+		opts.Intervals = make(map[fanuc.DataGroup]time.Duration, len(req.GetIntervals()))
+		for name, interval := range req.GetIntervals() {
+			group := fanuc.DataGroup(name)
+			if !knownGroups[group] {
+				return status.Errorf(codes.InvalidArgument, "unknown data group %q", name)
+			}
+			d := interval.AsDuration()
+			if d <= 0 {
+				return status.Errorf(codes.InvalidArgument, "interval of group %q must be positive", name)
+			}
+			opts.Intervals[group] = d
+		}
+	}
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	out := make(chan *fanucv1.WatchResponse, s.opts.Buffer)
+	var wg sync.WaitGroup
+	for _, m := range machines {
+		snapshots, unsubscribe, err := s.subscribe(m, opts)
+		if err != nil {
+			cancel()
+			wg.Wait()
+			return err
+		}
+		defer unsubscribe()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.forward(ctx, m.id, snapshots, req.GetEventsOnly(), out)
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	for resp := range out {
+		if err := stream.Send(resp); err != nil {
+			cancel()
+			for range out {
+			}
+			return err
+		}
+	}
+	if err := stream.Context().Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	return nil
+}
+
+// forward преобразует снимки станка id в сообщения Watch до закрытия подписки или отмены ctx.
+func (s *Server) forward(ctx context.Context, id string, snapshots <-chan fanuc.Snapshot, eventsOnly bool, out chan<- *fanucv1.WatchResponse) {
+	send := func(resp *fanucv1.WatchResponse) bool {
+		select {
+		case out <- resp:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	detector := fanuc.NewEventDetector()
+	for {
+		var snapshot fanuc.Snapshot
+		select {
+		case next, ok := <-snapshots:
+			if !ok {
+				return
+			}
+			snapshot = next
+		case <-ctx.Done():
+			return
+		}
+		if !eventsOnly && snapshot.Data != nil {
+			resp := &fanucv1.WatchResponse{
+				MachineId: id,
+				Payload:   &fanucv1.WatchResponse_Snapshot{Snapshot: fanucv1.FromMachineData(snapshot.Data)},
+			}
+			if !send(resp) {
+				return
+			}
+		}
+		for _, e := range detector.Detect(snapshot) {
+			event, err := fanucv1.FromMachineEvent(e)
+			if err != nil {
+				s.opts.Logger.WithError(err).Warnf("Станок %s: событие не передано", id)
+				continue
+			}
+			resp := &fanucv1.WatchResponse{MachineId: id, Payload: &fanucv1.WatchResponse_Event{Event: event}}
+			if !send(resp) {
+				return
+			}
+		}
+	}
+}
+
+// watched возвращает станки по списку ID; пустой список — все станки сервиса.
+func (s *Server) watched(ids []string) ([]*machine, error) {
+	if len(ids) == 0 {
+		s.mu.RLock()
+		machines := make([]*machine, 0, len(s.machines))
+		for _, m := range s.machines {
+			machines = append(machines, m)
+		}
+		s.mu.RUnlock()
+		if len(machines) == 0 {
+			return nil, status.Error(codes.FailedPrecondition, "no machines to watch")
+		}
+		return machines, nil
+	}
+
+	machines := make([]*machine, 0, len(ids))
+	for _, id := range ids {
+		m, err := s.machine(id)
+		if err != nil {
+			return nil, err
+		}
+		machines = append(machines, m)
+	}
+	return machines, nil
+}
+
+// machine возвращает станок id или ошибку NOT_FOUND.
+func (s *Server) machine(id string) (*machine, error) {
+	s.mu.RLock()
+	m, ok := s.machines[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "machine %q not found", id)
+	}
+	return m, nil
+}
+
+// statusError преобразует ошибку чтения в ошибку gRPC с кодом, соответствующим
+// HTTP-статусу focas.ToAppError. Причина передается, только если ее можно
+// показывать клиенту.
+func statusError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	appErr := focas.ToAppError(err)
+	code := codes.Internal
+	switch appErr.Code {
+	case apperrors.BadRequestCode:
+		code = codes.InvalidArgument
+	case apperrors.ForbiddenErrorCode:
+		code = codes.PermissionDenied
+	case apperrors.NotFoundErrorCode:
+		code = codes.NotFound
+	case apperrors.ConflictErrorCode:
+		code = codes.FailedPrecondition
+	case apperrors.NotImplementedCode:
+		code = codes.Unimplemented
+	case apperrors.ServiceUnavailableCode:
+		code = codes.Unavailable
+	case apperrors.GatewayTimeoutCode:
+		code = codes.DeadlineExceeded
+	case apperrors.ClientClosedRequestCode:
+		code = codes.Canceled
+	}
+	message := appErr.Message
+	if appErr.IsUserFacing && appErr.Err != nil {
+		message += ": " + appErr.Err.Error()
+	}
+	return status.Error(code, message)
+}
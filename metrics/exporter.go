@@ -0,0 +1,307 @@
+// Package metrics экспортирует данные станков и показатели работы адаптера в формате Prometheus.
+//
+// Метрики станков строятся по последнему снимку AggregatedData (Update, Run) и имеют
+// метку machine, метрики осей и шпинделей — также axis и spindle. Значения разделов
+// без данных (ошибка чтения, раздел не запрошен) не экспортируются. Состояние станка
+// и режим программы передаются в стиле перечислений: у текущего значения 1, у ранее
+// встречавшихся — 0.
+//
+// Метрики вызовов FOCAS собирает наблюдатель Observer, который передается в
+// fanuc.Config.Observer или fleet.Config.Observer.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	"github.com/iwtcode/fanucAdapter/focas/model"
+	"github.com/iwtcode/fanucAdapter/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace — префикс имен всех метрик.
+const Namespace = "fanuc"
+
+// Exporter собирает метрики станков и вызовов FOCAS. Реализует prometheus.Collector,
+// поэтому его можно зарегистрировать в собственном реестре или отдавать через Handler.
+type Exporter struct {
+	registry *prometheus.Registry
+	calls    *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	lockWait *prometheus.HistogramVec
+
+	mu       sync.Mutex
+	machines map[string]*machine
+}
+
+// machine — последние данные станка для сбора метрик.
+type machine struct {
+	data       *models.AggregatedData
+	connection func() models.ConnectionStatus // nil — состояние соединения неизвестно
+	states     map[string]struct{}            // Встречавшиеся значения MachineState
+	modes      map[string]struct{}            // Встречавшиеся значения ProgramMode
+}
+
+var (
+	axisLabels    = []string{"machine", "axis"}
+	spindleLabels = []string{"machine", "spindle"}
+	machineLabels = []string{"machine"}
+
+	axisPositionDesc    = newDesc("axis_position", "Абсолютная позиция оси.", axisLabels...)
+	axisLoadDesc        = newDesc("axis_load_percent", "Нагрузка сервопривода оси, %.", axisLabels...)
+	axisServoTempDesc   = newDesc("axis_servo_temperature_celsius", "Температура сервомотора оси, °C.", axisLabels...)
+	axisCoderTempDesc   = newDesc("axis_coder_temperature_celsius", "Температура энкодера оси, °C.", axisLabels...)
+	spindleSpeedDesc    = newDesc("spindle_speed_rpm", "Частота вращения шпинделя, об/мин.", spindleLabels...)
+	spindleLoadDesc     = newDesc("spindle_load_percent", "Нагрузка шпинделя, %.", spindleLabels...)
+	feedOverrideDesc    = newDesc("feed_override_percent", "Коррекция подачи, %.", machineLabels...)
+	jogOverrideDesc     = newDesc("jog_override_percent", "Коррекция скорости JOG, %.", machineLabels...)
+	partsCountDesc      = newDesc("parts_count", "Счетчик обработанных деталей.", machineLabels...)
+	activeAlarmsDesc    = newDesc("active_alarms", "Количество активных тревог.", machineLabels...)
+	machineStateDesc    = newDesc("machine_state", "Состояние выполнения программы: 1 у текущего значения.", "machine", "state")
+	programModeDesc     = newDesc("program_mode", "Режим работы: 1 у текущего значения.", "machine", "mode")
+	connectedDesc       = newDesc("connected", "1, если соединение со станком установлено.", machineLabels...)
+	reconnectsDesc      = newDesc("reconnects_total", "Количество переподключений к станку.", machineLabels...)
+	lastUpdateDesc      = newDesc("last_update_timestamp_seconds", "Время последнего снимка данных станка.", machineLabels...)
+	machineDescriptions = []*prometheus.Desc{
+		axisPositionDesc, axisLoadDesc, axisServoTempDesc, axisCoderTempDesc, spindleSpeedDesc, spindleLoadDesc,
+		feedOverrideDesc, jogOverrideDesc, partsCountDesc, activeAlarmsDesc, machineStateDesc, programModeDesc,
+		connectedDesc, reconnectsDesc, lastUpdateDesc,
+	}
+)
+
+func newDesc(name, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(Namespace, "", name), help, labels, nil)
+}
+
+// NewExporter создает экспортер и реестр, в котором зарегистрированы экспортер,
+// а также метрики среды выполнения Go и процесса.
+func NewExporter() *Exporter {
+	e := &Exporter{
+		registry: prometheus.NewRegistry(),
+		calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "focas_calls_total",
+			Help:      "Количество вызовов функций FOCAS по коду возврата.",
+		}, []string{"machine", "function", "rc"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "focas_call_duration_seconds",
+			Help:      "Длительность вызовов функций FOCAS.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"machine", "function"}),
+		lockWait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "focas_lock_wait_seconds",
+			Help:      "Время ожидания блокировки вызовов FOCAS (libLock или блокировки бэкенда).",
+			Buckets:   []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1, 5, 10},
+		}, machineLabels),
+		machines: make(map[string]*machine),
+	}
+	e.registry.MustRegister(e, collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	return e
+}
+
+// Handler возвращает обработчик HTTP для эндпоинта /metrics.
+func (e *Exporter) Handler() http.Handler {
+	return promhttp.HandlerFor(e.registry, promhttp.HandlerOpts{})
+}
+
+// Registry возвращает реестр экспортера, например для регистрации собственных метрик.
+func (e *Exporter) Registry() *prometheus.Registry {
+	return e.registry
+}
+
+// Observer возвращает наблюдателя вызовов FOCAS станка machineID.
+func (e *Exporter) Observer(machineID string) model.CallObserver {
+	return &observer{
+		calls:    e.calls.MustCurryWith(prometheus.Labels{"machine": machineID}),
+		latency:  e.latency.MustCurryWith(prometheus.Labels{"machine": machineID}),
+		lockWait: e.lockWait.WithLabelValues(machineID),
+	}
+}
+
+// observer — наблюдатель вызовов FOCAS одного станка.
+type observer struct {
+	calls    *prometheus.CounterVec
+	latency  prometheus.ObserverVec
+	lockWait prometheus.Observer
+}
+
+func (o *observer) ObserveCall(function string, rc int16, duration time.Duration) {
+	o.calls.WithLabelValues(function, model.Code(rc).String()).Inc()
+	o.latency.WithLabelValues(function).Observe(duration.Seconds())
+}
+
+func (o *observer) ObserveLockWait(wait time.Duration) {
+	o.lockWait.Observe(wait.Seconds())
+}
+
+// Update сохраняет снимок данных станка machineID для метрик станка.
+func (e *Exporter) Update(machineID string, data *models.AggregatedData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.machine(machineID).data = data
+}
+
+// UpdateConnection сохраняет состояние соединения станка machineID, например
+// из fleet.Snapshot.Connection.
+func (e *Exporter) UpdateConnection(machineID string, status models.ConnectionStatus) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.machine(machineID).connection = func() models.ConnectionStatus { return status }
+}
+
+// Remove удаляет метрики станка machineID. Метрики вызовов FOCAS сохраняются.
+func (e *Exporter) Remove(machineID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.machines, machineID)
+}
+
+// Run обновляет метрики станка machineID по данным опроса client до отмены ctx
+// или закрытия клиента, после чего удаляет их. Метрики соединения берутся
+// из client.ConnectionStatus.
+func (e *Exporter) Run(ctx context.Context, machineID string, client *fanuc.Client, opts fanuc.SubscribeOptions) error {
+	e.mu.Lock()
+	e.machine(machineID).connection = client.ConnectionStatus
+	e.mu.Unlock()
+	defer e.Remove(machineID)
+
+	snapshots, err := client.Subscribe(ctx, opts)
+	if err != nil {
+		return err
+	}
+	for snapshot := range snapshots {
+		if snapshot.Data != nil {
+			e.Update(machineID, snapshot.Data)
+		}
+	}
+	return ctx.Err()
+}
+
+// machine возвращает запись станка, создавая ее при необходимости. Вызывается под e.mu.
+func (e *Exporter) machine(id string) *machine {
+	m, ok := e.machines[id]
+	if !ok {
+		m = &machine{states: make(map[string]struct{}), modes: make(map[string]struct{})}
+		e.machines[id] = m
+	}
+	return m
+}
+
+// Describe реализует prometheus.Collector.
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range machineDescriptions {
+		ch <- desc
+	}
+	e.calls.Describe(ch)
+	e.latency.Describe(ch)
+	e.lockWait.Describe(ch)
+}
+
+// Collect реализует prometheus.Collector.
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	e.calls.Collect(ch)
+	e.latency.Collect(ch)
+	e.lockWait.Collect(ch)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for id, m := range e.machines {
+		if m.connection != nil {
+			status := m.connection()
+			connected := 0.0
+			if status.State == models.ConnectionConnected {
+				connected = 1
+			}
+			ch <- prometheus.MustNewConstMetric(connectedDesc, prometheus.GaugeValue, connected, id)
+			ch <- prometheus.MustNewConstMetric(reconnectsDesc, prometheus.CounterValue, float64(status.ReconnectCount), id)
+		}
+		if m.data != nil {
+			m.collect(ch, id)
+		}
+	}
+}
+
+// collect передает метрики станка по последнему снимку. Вызывается под e.mu.
+func (m *machine) collect(ch chan<- prometheus.Metric, id string) {
+	data := m.data
+	gauge := func(desc *prometheus.Desc, value float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, append([]string{id}, labels...)...)
+	}
+	if !data.Timestamp.IsZero() {
+		gauge(lastUpdateDesc, float64(data.Timestamp.UnixNano())/1e9)
+	}
+
+	if hasValue(data, models.SectionAxes) {
+		for _, axis := range data.AxisInfos {
+			gauge(axisPositionDesc, axis.Position, axis.Name)
+			if axis.Quality.Good("load_percent") {
+				gauge(axisLoadDesc, axis.LoadPercent, axis.Name)
+			}
+			if axis.Quality.Good("servo_temperature") {
+				gauge(axisServoTempDesc, float64(axis.ServoTemperature), axis.Name)
+			}
+			if axis.Quality.Good("coder_temperature") {
+				gauge(axisCoderTempDesc, float64(axis.CoderTemperature), axis.Name)
+			}
+		}
+	}
+	if hasValue(data, models.SectionSpindles) {
+		for _, spindle := range data.SpindleInfos {
+			number := strconv.Itoa(int(spindle.Number))
+			if spindle.Quality.Good("speed_rpm") {
+				gauge(spindleSpeedDesc, float64(spindle.SpeedRPM), number)
+			}
+			if spindle.Quality.Good("load_percent") {
+				gauge(spindleLoadDesc, spindle.LoadPercent, number)
+			}
+		}
+	}
+	if hasValue(data, models.SectionFeed) {
+		gauge(feedOverrideDesc, float64(data.FeedOverride))
+	}
+	if hasValue(data, models.SectionJogOverride) {
+		gauge(jogOverrideDesc, float64(data.JogOverride))
+	}
+	if hasValue(data, models.SectionParameters) {
+		gauge(partsCountDesc, float64(data.PartsCount))
+	}
+	if hasValue(data, models.SectionAlarms) {
+		gauge(activeAlarmsDesc, float64(len(data.Alarms)))
+	}
+	if hasValue(data, models.SectionState) {
+		enum(ch, machineStateDesc, id, data.MachineState, m.states)
+		enum(ch, programModeDesc, id, data.ProgramMode, m.modes)
+	}
+}
+
+// enum передает метрику-перечисление: 1 у текущего значения current и 0 у значений,
+// встречавшихся раньше (seen).
+func enum(ch chan<- prometheus.Metric, desc *prometheus.Desc, id, current string, seen map[string]struct{}) {
+	if current != "" {
+		seen[current] = struct{}{}
+	}
+	for value := range seen {
+		v := 0.0
+		if value == current {
+			v = 1
+		}
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, id, value)
+	}
+}
+
+// hasValue сообщает, что раздел section снимка содержит значения.
+func hasValue(data *models.AggregatedData, section models.Section) bool {
+	if data.Sections == nil {
+		return true
+	}
+	status, ok := data.Sections[section]
+	return ok && status.Quality.HasValue()
+}
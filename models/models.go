@@ -23,6 +23,12 @@ type SystemInfo struct {
 	Version        string `json:"version"`
 	MaxAxes        int16  `json:"max_axes"`
 	ControlledAxes int16  `json:"controlled_axes"`
+	Paths          int16  `json:"paths"`          // Количество каналов (path); 1 для одноканального станка
+	CncType        string `json:"cnc_type"`       // Тип ЧПУ из cnc_sysinfo, например "0" или "30"
+	MachineType    string `json:"machine_type"`   // Тип станка: T (токарный), M (фрезерный) и т.д.
+	ModelSeries    string `json:"model_series"`   // Серия, по которой выбрана реализация, например "0i-TF"
+	ModelSource    string `json:"model_source"`   // Откуда взята серия: "config" или "detected"
+	Implementation string `json:"implementation"` // Выбранная реализация, например "Model0i"
 }
 
 // AxisInfo содержит информацию об оси
@@ -34,6 +40,9 @@ type AxisInfo struct {
 	CoderTemperature int32   `json:"coder_temperature"`
 	PowerConsumption int32   `json:"power_consumption"`
 	Diag301          float64 `json:"diag_301"`
+	// Quality — качество полей диагностики, которые не удалось прочитать (по JSON-именам).
+	// Такие поля в JSON выводятся как null.
+	Quality FieldQuality `json:"quality,omitempty"`
 }
 
 // AlarmDetail содержит детальную информацию об одной ошибке
@@ -64,6 +73,8 @@ type SpindleInfo struct {
 	OverridePercent  int16   `json:"override_percent"`
 	PowerConsumption int32   `json:"power_consumption"`
 	Diag411Value     int32   `json:"diag_411_value"`
+	// Quality — качество полей, которые не удалось прочитать (по JSON-именам).
+	Quality FieldQuality `json:"quality,omitempty"`
 }
 
 // CurrentProgramInfo содержит упрощенную информацию о текущей программе для AggregatedData.
@@ -116,4 +127,130 @@ type AggregatedData struct {
 	OperatingTime      string             `json:"operating_time"`
 	CycleTime          string             `json:"cycle_time"`
 	CuttingTime        string             `json:"cutting_time"`
+	// Paths содержит данные по каждому каналу многоканального станка.
+	// Поля верхнего уровня соответствуют каналу по умолчанию.
+	Paths []PathData `json:"paths,omitempty"`
+	// Sections содержит качество и время чтения каждого запрошенного раздела.
+	// Поля разделов без значения (см. Quality.HasValue) остаются нулевыми,
+	// а в JSON выводятся как null.
+	Sections map[Section]SectionStatus `json:"sections,omitempty"`
+}
+
+// Section — раздел сводных данных, читаемый отдельными вызовами FOCAS.
+type Section string
+
+// Разделы сводных данных.
+const (
+	SectionState       Section = "state"        // Состояние станка
+	SectionAlarms      Section = "alarms"       // Активные тревоги
+	SectionAxes        Section = "axes"         // Данные осей
+	SectionSpindles    Section = "spindles"     // Данные шпинделей
+	SectionProgram     Section = "program"      // Выполняемая программа
+	SectionFeed        Section = "feed"         // Фактическая подача и коррекция подачи
+	SectionContourFeed Section = "contour_feed" // Контурная подача (cnc_actf)
+	SectionJogOverride Section = "jog_override" // Коррекция JOG
+	SectionParameters  Section = "parameters"   // Счетчик деталей и наработка
+	SectionPaths       Section = "paths"        // Данные каналов многоканального станка
+)
+
+// AllSections возвращает все разделы сводных данных в порядке чтения.
+func AllSections() []Section {
+	return []Section{
+		SectionState, SectionAlarms, SectionAxes, SectionSpindles, SectionProgram, SectionFeed,
+		SectionContourFeed, SectionJogOverride, SectionParameters, SectionPaths,
+	}
+}
+
+// Результаты чтения раздела.
+const (
+	StatusOK          = "ok"          // Раздел прочитан
+	StatusError       = "error"       // Ошибка чтения
+	StatusUnsupported = "unsupported" // Функция или опция не поддерживается станком
+)
+
+// SectionStatus описывает результат чтения раздела сводных данных.
+type SectionStatus struct {
+	// Status — результат последнего чтения раздела.
+	Status string `json:"status"`
+	// Quality — качество значений раздела с учетом прежних чтений (см. MergeStale).
+	Quality Quality `json:"quality"`
+	// SourceTime — время чтения значения со станка. Для Stale — время
+	// последнего успешного чтения; для разделов без значения не заполняется.
+	SourceTime time.Time `json:"source_time,omitzero"`
+	Error      string    `json:"error,omitempty"`
+	Err        error     `json:"-"`
+}
+
+// PathData содержит состояние и программу одного канала (path) многоканального станка.
+type PathData struct {
+	Path               int16              `json:"path"`
+	MachineState       string             `json:"machine_state"`
+	ProgramMode        string             `json:"program_mode"`
+	TmMode             string             `json:"tm_mode"`
+	AxisMovementStatus string             `json:"axis_movement_status"`
+	MstbStatus         string             `json:"mstb_status"`
+	EmergencyStatus    string             `json:"emergency_status"`
+	AlarmStatus        string             `json:"alarm_status"`
+	EditStatus         string             `json:"edit_status"`
+	HasAlarms          bool               `json:"has_alarms"`
+	Alarms             []AlarmDetail      `json:"alarms"`
+	AxisInfos          []AxisInfo         `json:"axis_infos"`
+	SpindleInfos       []SpindleInfo      `json:"spindle_infos"`
+	CurrentProgram     CurrentProgramInfo `json:"current_program"`
+}
+
+// ConnectionState — состояние соединения клиента со станком.
+type ConnectionState string
+
+// Возможные состояния соединения.
+const (
+	ConnectionConnecting   ConnectionState = "Connecting"   // Первичное подключение
+	ConnectionConnected    ConnectionState = "Connected"    // Соединение установлено
+	ConnectionReconnecting ConnectionState = "Reconnecting" // Связь потеряна, идет переподключение
+	ConnectionDisconnected ConnectionState = "Disconnected" // Станок недоступен, попытки исчерпаны
+	ConnectionClosed       ConnectionState = "Closed"       // Клиент закрыт
+)
+
+// ConnectionStatus содержит текущее состояние соединения и его историю.
+type ConnectionStatus struct {
+	State          ConnectionState `json:"state"`
+	Since          time.Time       `json:"since"`
+	LastError      string          `json:"last_error,omitempty"`
+	LastErrorTime  time.Time       `json:"last_error_time,omitzero"`
+	LastSuccess    time.Time       `json:"last_success,omitzero"`
+	ReconnectCount int             `json:"reconnect_count"`
+}
+
+// ConnectionEvent описывает смену состояния соединения.
+type ConnectionEvent struct {
+	MachineID string           `json:"machine_id"`
+	From      ConnectionState  `json:"from"`
+	To        ConnectionState  `json:"to"`
+	Status    ConnectionStatus `json:"status"`
+	Timestamp time.Time        `json:"timestamp"`
+}
+
+// EventType — тип события изменения данных станка.
+type EventType string
+
+// Типы событий изменения данных станка.
+const (
+	EventMachineStateChanged   EventType = "MachineStateChanged"   // Before/After — MachineState
+	EventModeChanged           EventType = "ModeChanged"           // Before/After — ProgramMode
+	EventProgramChanged        EventType = "ProgramChanged"        // Before/After — CurrentProgramInfo
+	EventAlarmRaised           EventType = "AlarmRaised"           // After — AlarmDetail, Key — ErrorCode
+	EventAlarmCleared          EventType = "AlarmCleared"          // Before — AlarmDetail, Key — ErrorCode
+	EventEmergencyEntered      EventType = "EmergencyEntered"      // Before/After — EmergencyStatus
+	EventEmergencyCleared      EventType = "EmergencyCleared"      // Before/After — EmergencyStatus
+	EventPartsCountIncremented EventType = "PartsCountIncremented" // Before/After — PartsCount
+)
+
+// MachineEvent описывает изменение данных станка между двумя последовательными опросами.
+type MachineEvent struct {
+	Type      EventType `json:"type"`
+	MachineID string    `json:"machine_id"`
+	Key       string    `json:"key,omitempty"` // Код тревоги для AlarmRaised/AlarmCleared
+	Before    any       `json:"before"`
+	After     any       `json:"after"`
+	Timestamp time.Time `json:"timestamp"`
 }
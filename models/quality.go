@@ -0,0 +1,216 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+)
+
+// Quality — качество значения: удалось ли его прочитать и насколько оно актуально.
+type Quality string
+
+// Качество значений.
+const (
+	QualityGood         Quality = "Good"         // Значение прочитано в последнем опросе
+	QualityNotSupported Quality = "NotSupported" // Функция или опция не поддерживается станком (EW_FUNC, EW_NOOPT)
+	QualityCommError    Quality = "CommError"    // Значение не прочитано: нет связи или вызов FOCAS завершился ошибкой
+	QualityStale        Quality = "Stale"        // Последнее чтение не удалось; сохранено предыдущее значение
+)
+
+// HasValue сообщает, содержит ли поле значение, а не нулевую заглушку.
+func (q Quality) HasValue() bool {
+	return q == QualityGood || q == QualityStale
+}
+
+// FieldQuality содержит качество отдельных полей структуры по их JSON-именам.
+// Поля, которых нет в карте, прочитаны успешно.
+type FieldQuality map[string]Quality
+
+// Good сообщает, прочитано ли поле field (JSON-имя) в последнем опросе.
+func (q FieldQuality) Good(field string) bool {
+	quality, ok := q[field]
+	return !ok || quality == QualityGood
+}
+
+// sectionFields — JSON-поля AggregatedData, заполняемые каждым разделом.
+var sectionFields = map[Section][]string{
+	SectionState: {
+		"is_emergency", "machine_state", "program_mode", "tm_mode", "axis_movement_status",
+		"mstb_status", "emergency_status", "alarm_status", "edit_status",
+	},
+	SectionAlarms:      {"has_alarms", "alarms"},
+	SectionAxes:        {"axis_infos"},
+	SectionSpindles:    {"spindle_infos"},
+	SectionProgram:     {"current_program"},
+	SectionFeed:        {"actual_feed_rate", "feed_override"},
+	SectionContourFeed: {"contour_feed_rate"},
+	SectionJogOverride: {"jog_override"},
+	SectionParameters:  {"parts_count", "power_on_time", "operating_time", "cycle_time", "cutting_time"},
+	SectionPaths:       {"paths"},
+}
+
+// CopySection переносит поля раздела section из src в d.
+func (d *AggregatedData) CopySection(src *AggregatedData, section Section) {
+	switch section {
+	case SectionState:
+		d.IsEmergency = src.IsEmergency
+		d.MachineState = src.MachineState
+		d.ProgramMode = src.ProgramMode
+		d.TmMode = src.TmMode
+		d.AxisMovementStatus = src.AxisMovementStatus
+		d.MstbStatus = src.MstbStatus
+		d.EmergencyStatus = src.EmergencyStatus
+		d.AlarmStatus = src.AlarmStatus
+		d.EditStatus = src.EditStatus
+	case SectionAlarms:
+		d.HasAlarms = src.HasAlarms
+		d.Alarms = src.Alarms
+	case SectionAxes:
+		d.AxisInfos = src.AxisInfos
+	case SectionSpindles:
+		d.SpindleInfos = src.SpindleInfos
+	case SectionProgram:
+		d.CurrentProgram = src.CurrentProgram
+	case SectionFeed:
+		d.ActualFeedRate = src.ActualFeedRate
+		d.FeedOverride = src.FeedOverride
+	case SectionContourFeed:
+		d.ContourFeedRate = src.ContourFeedRate
+	case SectionJogOverride:
+		d.JogOverride = src.JogOverride
+	case SectionParameters:
+		d.PartsCount = src.PartsCount
+		d.PowerOnTime = src.PowerOnTime
+		d.OperatingTime = src.OperatingTime
+		d.CycleTime = src.CycleTime
+		d.CuttingTime = src.CuttingTime
+	case SectionPaths:
+		d.Paths = src.Paths
+	}
+}
+
+// MergeStale переносит из prev значения разделов, которые не удалось прочитать в d.
+// Такие разделы сохраняют статус и ошибку последнего чтения, а получают качество Stale
+// и время исходного чтения. Разделы, которые d не запрашивал, и разделы NotSupported не переносятся.
+// Карта d.Sections заменяется копией, поэтому ранее отданные данные не изменяются.
+func (d *AggregatedData) MergeStale(prev *AggregatedData) {
+	if prev == nil || d.Sections == nil {
+		return
+	}
+	var sections map[Section]SectionStatus
+	for section, status := range d.Sections {
+		if status.Status != StatusError {
+			continue
+		}
+		old, ok := prev.Sections[section]
+		if !ok || !old.Quality.HasValue() {
+			continue
+		}
+		if sections == nil {
+			sections = maps.Clone(d.Sections)
+		}
+		d.CopySection(prev, section)
+		sections[section] = SectionStatus{
+			Status:     status.Status,
+			Quality:    QualityStale,
+			SourceTime: old.SourceTime,
+			Error:      status.Error,
+			Err:        status.Err,
+		}
+	}
+	if sections != nil {
+		d.Sections = sections
+	}
+}
+
+// MarshalJSON выводит поля разделов без значения (не запрошенных или прочитанных
+// с ошибкой) как null, чтобы их нельзя было спутать с настоящими нулями.
+// Если Sections не заполнен, все поля выводятся как есть.
+func (d AggregatedData) MarshalJSON() ([]byte, error) {
+	type plain AggregatedData
+	data, err := json.Marshal(plain(d))
+	if err != nil || d.Sections == nil {
+		return data, err
+	}
+	missing := make(map[string]bool)
+	for section, fields := range sectionFields {
+		if !d.Sections[section].Quality.HasValue() {
+			for _, field := range fields {
+				missing[field] = true
+			}
+		}
+	}
+	return nullFields(data, missing)
+}
+
+// MarshalJSON выводит поля диагностики, которые не удалось прочитать, как null.
+func (a AxisInfo) MarshalJSON() ([]byte, error) {
+	type plain AxisInfo
+	data, err := json.Marshal(plain(a))
+	if err != nil {
+		return nil, err
+	}
+	return nullFields(data, a.Quality.missing())
+}
+
+// MarshalJSON выводит поля, которые не удалось прочитать, как null.
+func (s SpindleInfo) MarshalJSON() ([]byte, error) {
+	type plain SpindleInfo
+	data, err := json.Marshal(plain(s))
+	if err != nil {
+		return nil, err
+	}
+	return nullFields(data, s.Quality.missing())
+}
+
+// missing возвращает поля без значения.
+func (q FieldQuality) missing() map[string]bool {
+	var fields map[string]bool
+	for field, quality := range q {
+		if !quality.HasValue() {
+			if fields == nil {
+				fields = make(map[string]bool)
+			}
+			fields[field] = true
+		}
+	}
+	return fields
+}
+
+// nullFields заменяет значения полей fields JSON-объекта data на null с сохранением порядка полей.
+func nullFields(data []byte, fields map[string]bool) ([]byte, error) {
+	if len(fields) == 0 {
+		return data, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil { // {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.Grow(len(data))
+	buf.WriteByte('{')
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		name, _ := key.(string)
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		encoded, _ := json.Marshal(name)
+		buf.Write(encoded)
+		buf.WriteByte(':')
+		if fields[name] {
+			buf.WriteString("null")
+		} else {
+			buf.Write(value)
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
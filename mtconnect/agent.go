@@ -0,0 +1,261 @@
+// Package mtconnect реализует агент MTConnect поверх fanuc.Client: описание устройства
+// (/probe), текущие значения (/current) и историю наблюдений (/sample) из кольцевого буфера
+// с последовательными номерами.
+package mtconnect
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	"github.com/iwtcode/fanucAdapter/models"
+)
+
+// Options задает параметры агента.
+type Options struct {
+	// DeviceName — имя устройства. По умолчанию "fanuc".
+	DeviceName string
+	// UUID — идентификатор устройства. По умолчанию совпадает с DeviceName.
+	UUID string
+	// Sender — имя агента в заголовке ответов. По умолчанию "fanucAdapter".
+	Sender string
+	// BufferSize — количество наблюдений в буфере /sample. По умолчанию 4096.
+	BufferSize int
+	// Subscribe — параметры опроса станка (см. fanuc.Client.Subscribe).
+	Subscribe fanuc.SubscribeOptions
+}
+
+// Agent — агент MTConnect для одного станка.
+type Agent struct {
+	client     *fanuc.Client
+	device     *Device
+	opts       Options
+	instanceID int64
+
+	mu     sync.RWMutex
+	buffer *buffer
+}
+
+// New создает агент для станка client. Описание устройства строится по системной
+// информации, именам осей и количеству шпинделей, поэтому станок должен быть доступен.
+// Опрос запускается методом Run.
+func New(ctx context.Context, client *fanuc.Client, opts Options) (*Agent, error) {
+	device, err := ReadDevice(ctx, client, opts.DeviceName, opts.UUID)
+	if err != nil {
+		return nil, err
+	}
+	return NewAgent(device, client, opts), nil
+}
+
+// ReadDevice строит описание устройства по данным станка client (см. NewDevice).
+// Пустое имя заменяется на "fanuc", пустой UUID — на имя устройства.
+func ReadDevice(ctx context.Context, client *fanuc.Client, name, uuid string) (*Device, error) {
+	info := client.GetSystemInfo()
+	if info == nil {
+		return nil, errors.New("mtconnect: system info is not available")
+	}
+	axes, err := client.GetAxisDataCtx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("mtconnect: failed to read axes: %w", err)
+	}
+	spindles, err := client.GetSpindleDataCtx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("mtconnect: failed to read spindles: %w", err)
+	}
+	names := make([]string, len(axes))
+	for i, axis := range axes {
+		names[i] = axis.Name
+	}
+
+	if name == "" {
+		name = "fanuc"
+	}
+	if uuid == "" {
+		uuid = name
+	}
+	return NewDevice(name, uuid, info, names, len(spindles)), nil
+}
+
+// NewAgent создает агент для готового описания устройства. client может быть nil,
+// если данные передаются через Update.
+func NewAgent(device *Device, client *fanuc.Client, opts Options) *Agent {
+	if opts.Sender == "" {
+		opts.Sender = "fanucAdapter"
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 4096
+	}
+	a := &Agent{
+		client:     client,
+		device:     device,
+		opts:       opts,
+		instanceID: time.Now().Unix(),
+		buffer:     newBuffer(opts.BufferSize),
+	}
+	// До первого опроса все значения недоступны
+	a.Update(nil, time.Now())
+	return a
+}
+
+// Device возвращает описание устройства агента.
+func (a *Agent) Device() *Device {
+	return a.device
+}
+
+// Run опрашивает станок до отмены ctx или закрытия клиента и добавляет изменения
+// значений в буфер. После остановки все значения становятся недоступными.
+func (a *Agent) Run(ctx context.Context) error {
+	if a.client == nil {
+		return errors.New("mtconnect: agent has no client")
+	}
+	snapshots, err := a.client.Subscribe(ctx, a.opts.Subscribe)
+	if err != nil {
+		return err
+	}
+	for s := range snapshots {
+		a.Update(s.Data, s.Timestamp)
+	}
+	a.Update(nil, time.Now())
+	return ctx.Err()
+}
+
+// Update добавляет в буфер изменения значений из снимка data (nil — станок недоступен).
+func (a *Agent) Update(data *models.AggregatedData, ts time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, item := range a.device.items {
+		if item.Category == CategoryCondition {
+			a.buffer.setConditions(item, a.device.Conditions(data), ts)
+			continue
+		}
+		a.buffer.addValue(item, a.device.Value(item, data), ts)
+	}
+}
+
+// ServeHTTP обрабатывает запросы /probe, /current и /sample (также с префиксом имени устройства).
+func (a *Agent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	if rest, ok := strings.CutPrefix(path, a.device.Name); ok && (rest == "" || rest[0] == '/') {
+		path = strings.TrimPrefix(rest, "/")
+	} else if strings.Contains(path, "/") {
+		a.writeError(w, http.StatusNotFound, errorNoDevice, fmt.Sprintf("Could not find the device %q", strings.SplitN(path, "/", 2)[0]))
+		return
+	}
+
+	switch path {
+	case "", "probe":
+		a.probe(w)
+	case "current":
+		a.current(w, r)
+	case "sample":
+		a.sample(w, r)
+	default:
+		a.writeError(w, http.StatusNotFound, errorInvalidRequest, fmt.Sprintf("Unknown request %q", path))
+	}
+}
+
+func (a *Agent) probe(w http.ResponseWriter) {
+	a.mu.RLock()
+	h := a.header()
+	a.mu.RUnlock()
+	writeXML(w, http.StatusOK, devicesDocument{XMLNS: devicesNamespace, Header: h, Devices: []*Device{a.device}})
+}
+
+func (a *Agent) current(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Has("at") {
+		a.writeError(w, http.StatusBadRequest, errorUnsupported, "The 'at' parameter is not supported")
+		return
+	}
+	a.mu.RLock()
+	h := a.header()
+	observations := a.buffer.current(a.device.items)
+	a.mu.RUnlock()
+	h.NextSequence = h.LastSequence + 1
+	a.writeStreams(w, h, observations)
+}
+
+func (a *Agent) sample(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	count := 100
+	if s := query.Get("count"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			a.writeError(w, http.StatusBadRequest, errorInvalidRequest, "'count' must be a positive integer")
+			return
+		}
+		count = n
+	}
+
+	a.mu.RLock()
+	h := a.header()
+	from := h.FirstSequence
+	var parseErr error
+	if s := query.Get("from"); s != "" {
+		from, parseErr = strconv.ParseUint(s, 10, 64)
+	}
+	var observations []observation
+	var next uint64
+	outOfRange := parseErr == nil && (from < h.FirstSequence || from > h.LastSequence+1)
+	if parseErr == nil && !outOfRange {
+		observations, next = a.buffer.sample(from, count)
+	}
+	a.mu.RUnlock()
+
+	switch {
+	case parseErr != nil:
+		a.writeError(w, http.StatusBadRequest, errorInvalidRequest, "'from' must be a non-negative integer")
+	case outOfRange:
+		a.writeError(w, http.StatusNotFound, errorOutOfRange,
+			fmt.Sprintf("'from' must be between %d and %d", h.FirstSequence, h.LastSequence+1))
+	default:
+		h.NextSequence = next
+		a.writeStreams(w, h, observations)
+	}
+}
+
+// header возвращает заголовок ответа. Вызывается под a.mu.
+func (a *Agent) header() header {
+	return header{
+		CreationTime:  formatTime(time.Now()),
+		Sender:        a.opts.Sender,
+		InstanceID:    a.instanceID,
+		Version:       schemaVersion,
+		BufferSize:    len(a.buffer.ring),
+		FirstSequence: a.buffer.first(),
+		LastSequence:  a.buffer.last(),
+	}
+}
+
+func (a *Agent) writeStreams(w http.ResponseWriter, h header, observations []observation) {
+	doc := streamsDocument{XMLNS: streamsNamespace, Header: h}
+	if len(observations) > 0 {
+		doc.Streams = []deviceStream{newStream(a.device, observations)}
+	}
+	writeXML(w, http.StatusOK, doc)
+}
+
+func (a *Agent) writeError(w http.ResponseWriter, status int, code, text string) {
+	a.mu.RLock()
+	h := a.header()
+	a.mu.RUnlock()
+	writeXML(w, status, errorDocument{XMLNS: errorNamespace, Header: h, Errors: []xmlError{{Code: code, Text: text}}})
+}
+
+func writeXML(w http.ResponseWriter, status int, doc any) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write([]byte(xml.Header))
+	w.Write(body)
+}
@@ -0,0 +1,128 @@
+package mtconnect
+
+import "time"
+
+// observation — одно наблюдение элемента данных в буфере агента.
+type observation struct {
+	Sequence  uint64
+	Timestamp time.Time
+	Item      *DataItem
+	Value     string // Значение события или сэмпла
+	// Condition — состояние условия; заполняется только для элементов CategoryCondition.
+	Condition Condition
+}
+
+// buffer — кольцевой буфер наблюдений с последовательными номерами, начиная с 1.
+// Кроме истории буфер хранит текущее значение каждого элемента данных и активные
+// состояния условий, чтобы /current отвечал и после вытеснения старых наблюдений.
+type buffer struct {
+	ring []observation
+	next uint64 // Номер следующего наблюдения
+
+	latest     map[*DataItem]observation   // Последние значения событий и сэмплов
+	conditions map[*DataItem][]observation // Активные состояния условий
+}
+
+func newBuffer(size int) *buffer {
+	return &buffer{
+		ring:       make([]observation, size),
+		next:       1,
+		latest:     make(map[*DataItem]observation),
+		conditions: make(map[*DataItem][]observation),
+	}
+}
+
+// first возвращает номер самого старого наблюдения в буфере.
+func (b *buffer) first() uint64 {
+	if b.next > uint64(len(b.ring)) {
+		return b.next - uint64(len(b.ring))
+	}
+	return 1
+}
+
+// last возвращает номер последнего наблюдения (0, если буфер пуст).
+func (b *buffer) last() uint64 {
+	return b.next - 1
+}
+
+func (b *buffer) append(o observation) {
+	o.Sequence = b.next
+	b.ring[(o.Sequence-1)%uint64(len(b.ring))] = o
+	b.next++
+}
+
+// addValue добавляет значение события или сэмпла, если оно изменилось.
+func (b *buffer) addValue(item *DataItem, value string, ts time.Time) {
+	if prev, ok := b.latest[item]; ok && prev.Value == value {
+		return
+	}
+	b.append(observation{Timestamp: ts, Item: item, Value: value})
+	b.latest[item] = b.at(b.last())
+}
+
+// setConditions заменяет активные состояния условия item. В буфер добавляются только
+// изменения: новые тревоги, сброс отдельных тревог (Normal с кодом) или общий переход
+// в Normal или Unavailable.
+func (b *buffer) setConditions(item *DataItem, values []Condition, ts time.Time) {
+	active := b.conditions[item]
+	byCode := make(map[string]observation, len(active))
+	for _, o := range active {
+		byCode[o.Condition.NativeCode] = o
+	}
+
+	// Без тревог условие описывается одним состоянием Normal или Unavailable
+	if len(values) == 1 && values[0].NativeCode == "" {
+		if len(active) == 1 && active[0].Condition == values[0] {
+			return
+		}
+		b.append(observation{Timestamp: ts, Item: item, Condition: values[0]})
+		b.conditions[item] = []observation{b.at(b.last())}
+		return
+	}
+
+	current := make(map[string]bool, len(values))
+	var result []observation
+	for _, v := range values {
+		current[v.NativeCode] = true
+		if prev, ok := byCode[v.NativeCode]; ok && prev.Condition == v {
+			result = append(result, prev)
+			continue
+		}
+		b.append(observation{Timestamp: ts, Item: item, Condition: v})
+		result = append(result, b.at(b.last()))
+	}
+	for _, o := range active {
+		if code := o.Condition.NativeCode; code != "" && !current[code] {
+			b.append(observation{Timestamp: ts, Item: item, Condition: Condition{Level: LevelNormal, NativeCode: code}})
+		}
+	}
+	b.conditions[item] = result
+}
+
+// at возвращает наблюдение с номером seq, который должен быть в пределах [first, last].
+func (b *buffer) at(seq uint64) observation {
+	return b.ring[(seq-1)%uint64(len(b.ring))]
+}
+
+// sample возвращает до count наблюдений, начиная с номера from, и номер следующего наблюдения.
+func (b *buffer) sample(from uint64, count int) ([]observation, uint64) {
+	var result []observation
+	seq := from
+	for ; seq < b.next && len(result) < count; seq++ {
+		result = append(result, b.at(seq))
+	}
+	return result, seq
+}
+
+// current возвращает текущие значения всех элементов данных items.
+func (b *buffer) current(items []*DataItem) []observation {
+	var result []observation
+	for _, item := range items {
+		if item.Category == CategoryCondition {
+			result = append(result, b.conditions[item]...)
+		} else if o, ok := b.latest[item]; ok {
+			result = append(result, o)
+		}
+	}
+	return result
+}
@@ -0,0 +1,294 @@
+package mtconnect
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/iwtcode/fanucAdapter/models"
+)
+
+// Категории элементов данных MTConnect.
+const (
+	CategorySample    = "SAMPLE"
+	CategoryEvent     = "EVENT"
+	CategoryCondition = "CONDITION"
+)
+
+// DataItem — элемент данных устройства MTConnect.
+type DataItem struct {
+	ID          string `xml:"id,attr"`
+	Name        string `xml:"name,attr,omitempty"`
+	Category    string `xml:"category,attr"`
+	Type        string `xml:"type,attr"`
+	SubType     string `xml:"subType,attr,omitempty"`
+	Units       string `xml:"units,attr,omitempty"`
+	NativeUnits string `xml:"nativeUnits,attr,omitempty"`
+
+	component *Component
+	// read возвращает значение элемента из сводных данных; ok=false — значение недоступно.
+	// Для условий не используется.
+	read func(data *models.AggregatedData) (value string, ok bool)
+}
+
+// Component — компонент устройства (Controller, Path, Axes, Linear, Rotary).
+type Component struct {
+	XMLName    xml.Name
+	ID         string       `xml:"id,attr"`
+	Name       string       `xml:"name,attr,omitempty"`
+	DataItems  []*DataItem  `xml:"DataItems>DataItem"`
+	Components []*Component `xml:"Components>Component"`
+}
+
+// Type возвращает тип компонента, например "Controller".
+func (c *Component) Type() string {
+	return c.XMLName.Local
+}
+
+// MarshalXML выводит компонент без пустых элементов DataItems и Components.
+func (c *Component) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	type dataItems struct {
+		Items []*DataItem `xml:"DataItem"`
+	}
+	type components struct {
+		Items []*Component // Имя элемента задает XMLName компонента
+	}
+	out := struct {
+		XMLName    xml.Name
+		ID         string      `xml:"id,attr"`
+		Name       string      `xml:"name,attr,omitempty"`
+		DataItems  *dataItems  `xml:"DataItems"`
+		Components *components `xml:"Components"`
+	}{XMLName: c.XMLName, ID: c.ID, Name: c.Name}
+	if len(c.DataItems) > 0 {
+		out.DataItems = &dataItems{c.DataItems}
+	}
+	if len(c.Components) > 0 {
+		out.Components = &components{c.Components}
+	}
+	return e.Encode(out)
+}
+
+// Description — описание устройства.
+type Description struct {
+	Manufacturer string `xml:"manufacturer,attr,omitempty"`
+	Model        string `xml:"model,attr,omitempty"`
+	Text         string `xml:",chardata"`
+}
+
+// Device — устройство MTConnect, описывающее один станок.
+type Device struct {
+	XMLName     xml.Name     `xml:"Device"`
+	ID          string       `xml:"id,attr"`
+	Name        string       `xml:"name,attr"`
+	UUID        string       `xml:"uuid,attr"`
+	Description Description  `xml:"Description"`
+	DataItems   []*DataItem  `xml:"DataItems>DataItem"`
+	Components  []*Component `xml:"Components>Component"`
+
+	items []*DataItem          // Все элементы данных в порядке документа
+	byID  map[string]*DataItem // Элементы по ID
+}
+
+// NewDevice строит модель устройства по системной информации станка, именам осей
+// (ReadAxisData) и количеству шпинделей. Оси A, B и C описываются как Rotary с углом
+// поворота, остальные — как Linear с позицией в миллиметрах.
+func NewDevice(name, uuid string, info *models.SystemInfo, axes []string, spindles int) *Device {
+	if info == nil {
+		info = &models.SystemInfo{}
+	}
+	d := &Device{
+		ID:   "dev",
+		Name: name,
+		UUID: uuid,
+		Description: Description{
+			Manufacturer: "FANUC",
+			Model:        info.Model,
+			Text:         strings.TrimSpace(fmt.Sprintf("Series %s %s %s", info.ModelSeries, info.Series, info.Version)),
+		},
+		byID: make(map[string]*DataItem),
+	}
+	d.DataItems = []*DataItem{
+		d.item(nil, &DataItem{ID: "avail", Category: CategoryEvent, Type: "AVAILABILITY"}, nil),
+	}
+
+	controller := newComponent("Controller", "cont", "controller")
+	controller.DataItems = []*DataItem{
+		d.item(controller, &DataItem{ID: "estop", Name: "estop", Category: CategoryEvent, Type: "EMERGENCY_STOP"},
+			stateValue(func(data *models.AggregatedData) (string, bool) {
+				if data.IsEmergency {
+					return "TRIGGERED", true
+				}
+				return "ARMED", true
+			})),
+		d.item(controller, &DataItem{ID: "mode", Name: "mode", Category: CategoryEvent, Type: "CONTROLLER_MODE"},
+			stateValue(func(data *models.AggregatedData) (string, bool) {
+				return controllerMode(data.ProgramMode)
+			})),
+		d.item(controller, &DataItem{ID: "system", Category: CategoryCondition, Type: "SYSTEM"}, nil),
+	}
+
+	path := newComponent("Path", "path1", "path")
+	path.DataItems = []*DataItem{
+		d.item(path, &DataItem{ID: "execution", Name: "execution", Category: CategoryEvent, Type: "EXECUTION"},
+			stateValue(func(data *models.AggregatedData) (string, bool) {
+				return execution(data.MachineState)
+			})),
+		d.item(path, &DataItem{ID: "program", Name: "program", Category: CategoryEvent, Type: "PROGRAM"},
+			sectionValue(models.SectionProgram, func(data *models.AggregatedData) (string, bool) {
+				return data.CurrentProgram.ProgramName, true
+			})),
+		d.item(path, &DataItem{ID: "block", Name: "block", Category: CategoryEvent, Type: "BLOCK"},
+			sectionValue(models.SectionProgram, func(data *models.AggregatedData) (string, bool) {
+				return data.CurrentProgram.GCodeLine, true
+			})),
+		d.item(path, &DataItem{ID: "part_count", Name: "part_count", Category: CategoryEvent, Type: "PART_COUNT", SubType: "ALL"},
+			sectionValue(models.SectionParameters, func(data *models.AggregatedData) (string, bool) {
+				return fmt.Sprint(data.PartsCount), true
+			})),
+		d.item(path, &DataItem{ID: "path_feedrate", Name: "path_feedrate", Category: CategorySample, Type: "PATH_FEEDRATE",
+			SubType: "ACTUAL", Units: "MILLIMETER/SECOND", NativeUnits: "MILLIMETER/MINUTE"},
+			sectionValue(models.SectionFeed, func(data *models.AggregatedData) (string, bool) {
+				return formatFloat(float64(data.ActualFeedRate) / 60), true
+			})),
+		d.item(path, &DataItem{ID: "feed_ovr", Name: "feed_ovr", Category: CategoryEvent, Type: "PATH_FEEDRATE_OVERRIDE",
+			SubType: "PROGRAMMED", Units: "PERCENT"},
+			sectionValue(models.SectionFeed, func(data *models.AggregatedData) (string, bool) {
+				return fmt.Sprint(data.FeedOverride), true
+			})),
+		d.item(path, &DataItem{ID: "jog_ovr", Name: "jog_ovr", Category: CategoryEvent, Type: "PATH_FEEDRATE_OVERRIDE",
+			SubType: "JOG", Units: "PERCENT"},
+			sectionValue(models.SectionJogOverride, func(data *models.AggregatedData) (string, bool) {
+				return fmt.Sprint(data.JogOverride), true
+			})),
+	}
+	controller.Components = []*Component{path}
+
+	axesComponent := newComponent("Axes", "axes", "axes")
+	ids := make(map[string]bool)
+	for _, axisName := range axes {
+		axesComponent.Components = append(axesComponent.Components, d.axis(axisName, ids))
+	}
+	for number := 1; number <= spindles; number++ {
+		axesComponent.Components = append(axesComponent.Components, d.spindle(int16(number)))
+	}
+
+	d.Components = []*Component{controller}
+	if len(axesComponent.Components) > 0 {
+		d.Components = append(d.Components, axesComponent)
+	}
+	return d
+}
+
+func newComponent(kind, id, name string) *Component {
+	return &Component{XMLName: xml.Name{Local: kind}, ID: id, Name: name}
+}
+
+// item регистрирует элемент данных компонента c (nil — уровень устройства).
+func (d *Device) item(c *Component, item *DataItem, read func(*models.AggregatedData) (string, bool)) *DataItem {
+	item.component = c
+	item.read = read
+	d.items = append(d.items, item)
+	d.byID[item.ID] = item
+	return item
+}
+
+// axis описывает ось станка. ids исключает совпадение ID осей с одинаковыми именами.
+func (d *Device) axis(axisName string, ids map[string]bool) *Component {
+	id := strings.ToLower(axisName)
+	for base, n := id, 2; ids[id]; n++ {
+		id = fmt.Sprintf("%s%d", base, n)
+	}
+	ids[id] = true
+
+	kind, position, units := "Linear", "POSITION", "MILLIMETER"
+	if rotary := strings.TrimRight(axisName, "0123456789"); rotary == "A" || rotary == "B" || rotary == "C" {
+		kind, position, units = "Rotary", "ANGLE", "DEGREE"
+	}
+
+	axis := func(data *models.AggregatedData) *models.AxisInfo {
+		for i := range data.AxisInfos {
+			if data.AxisInfos[i].Name == axisName {
+				return &data.AxisInfos[i]
+			}
+		}
+		return nil
+	}
+	c := newComponent(kind, id, axisName)
+	c.DataItems = []*DataItem{
+		d.item(c, &DataItem{ID: id + "_pos", Name: axisName + "act", Category: CategorySample, Type: position,
+			SubType: "ACTUAL", Units: units},
+			sectionValue(models.SectionAxes, func(data *models.AggregatedData) (string, bool) {
+				if a := axis(data); a != nil {
+					return formatFloat(a.Position), true
+				}
+				return "", false
+			})),
+		d.item(c, &DataItem{ID: id + "_load", Name: axisName + "load", Category: CategorySample, Type: "LOAD", Units: "PERCENT"},
+			sectionValue(models.SectionAxes, func(data *models.AggregatedData) (string, bool) {
+				if a := axis(data); a != nil && a.Quality.Good("diag_301") {
+					return formatFloat(a.Diag301), true
+				}
+				return "", false
+			})),
+		d.item(c, &DataItem{ID: id + "_temp", Name: axisName + "temp", Category: CategorySample, Type: "TEMPERATURE", Units: "CELSIUS"},
+			sectionValue(models.SectionAxes, func(data *models.AggregatedData) (string, bool) {
+				if a := axis(data); a != nil && a.Quality.Good("servo_temperature") {
+					return fmt.Sprint(a.ServoTemperature), true
+				}
+				return "", false
+			})),
+	}
+	return c
+}
+
+// spindle описывает шпиндель с номером number.
+func (d *Device) spindle(number int16) *Component {
+	id := fmt.Sprintf("s%d", number)
+	name := fmt.Sprintf("S%d", number)
+	spindle := func(data *models.AggregatedData) *models.SpindleInfo {
+		for i := range data.SpindleInfos {
+			if data.SpindleInfos[i].Number == number {
+				return &data.SpindleInfos[i]
+			}
+		}
+		return nil
+	}
+	c := newComponent("Rotary", id, name)
+	c.DataItems = []*DataItem{
+		d.item(c, &DataItem{ID: id + "_speed", Name: name + "speed", Category: CategorySample, Type: "ROTARY_VELOCITY",
+			SubType: "ACTUAL", Units: "REVOLUTION/MINUTE"},
+			sectionValue(models.SectionSpindles, func(data *models.AggregatedData) (string, bool) {
+				if s := spindle(data); s != nil {
+					return fmt.Sprint(s.SpeedRPM), true
+				}
+				return "", false
+			})),
+		d.item(c, &DataItem{ID: id + "_load", Name: name + "load", Category: CategorySample, Type: "LOAD", Units: "PERCENT"},
+			sectionValue(models.SectionSpindles, func(data *models.AggregatedData) (string, bool) {
+				if s := spindle(data); s != nil {
+					return formatFloat(s.LoadPercent), true
+				}
+				return "", false
+			})),
+		d.item(c, &DataItem{ID: id + "_ovr", Name: name + "ovr", Category: CategoryEvent, Type: "ROTARY_VELOCITY_OVERRIDE",
+			Units: "PERCENT"},
+			sectionValue(models.SectionSpindles, func(data *models.AggregatedData) (string, bool) {
+				if s := spindle(data); s != nil && s.Quality.Good("override_percent") {
+					return fmt.Sprint(s.OverridePercent), true
+				}
+				return "", false
+			})),
+	}
+	return c
+}
+
+// Items возвращает все элементы данных устройства в порядке документа.
+func (d *Device) Items() []*DataItem {
+	return d.items
+}
+
+// Item возвращает элемент данных по ID или nil.
+func (d *Device) Item(id string) *DataItem {
+	return d.byID[id]
+}
@@ -0,0 +1,354 @@
+// Package shdr реализует адаптер MTConnect: передает данные станка стандартному агенту
+// MTConnect по протоколу SHDR (строки с полями через "|") поверх TCP.
+//
+// Агент подключается к адаптеру (по умолчанию порт 7878) и получает сначала все текущие
+// значения, а затем только изменения между последовательными снимками AggregatedData.
+// Ключи SHDR совпадают с ID элементов данных описания mtconnect.Device.
+package shdr
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	"github.com/iwtcode/fanucAdapter/models"
+	"github.com/iwtcode/fanucAdapter/mtconnect"
+)
+
+// DefaultAddr — адрес, который слушает адаптер по умолчанию (стандартный порт SHDR).
+const DefaultAddr = ":7878"
+
+// Options задает параметры адаптера.
+type Options struct {
+	// DeviceName и UUID — имя и идентификатор устройства (см. mtconnect.ReadDevice).
+	DeviceName string
+	UUID       string
+	// Heartbeat — интервал проверки связи, сообщаемый агенту в ответе на "* PING".
+	// По умолчанию 10 секунд.
+	Heartbeat time.Duration
+	// Subscribe — параметры опроса станка (см. fanuc.Client.Subscribe).
+	Subscribe fanuc.SubscribeOptions
+}
+
+// Adapter — TCP-сервер SHDR для одного станка.
+type Adapter struct {
+	client *fanuc.Client
+	device *mtconnect.Device
+	opts   Options
+
+	mu         sync.Mutex
+	values     map[*mtconnect.DataItem]string
+	conditions map[*mtconnect.DataItem][]mtconnect.Condition
+	timestamp  time.Time // Время последнего снимка
+	conns      map[*conn]struct{}
+	ln         net.Listener
+	done       chan struct{}
+}
+
+// New создает адаптер для станка client. Описание устройства строится по данным станка,
+// поэтому станок должен быть доступен. Опрос запускается методом Run, прием подключений — Start.
+func New(ctx context.Context, client *fanuc.Client, opts Options) (*Adapter, error) {
+	device, err := mtconnect.ReadDevice(ctx, client, opts.DeviceName, opts.UUID)
+	if err != nil {
+		return nil, err
+	}
+	return NewAdapter(device, client, opts), nil
+}
+
+// NewAdapter создает адаптер для готового описания устройства. client может быть nil,
+// если данные передаются через Update.
+func NewAdapter(device *mtconnect.Device, client *fanuc.Client, opts Options) *Adapter {
+	if opts.Heartbeat <= 0 {
+		opts.Heartbeat = 10 * time.Second
+	}
+	a := &Adapter{
+		client:     client,
+		device:     device,
+		opts:       opts,
+		values:     make(map[*mtconnect.DataItem]string),
+		conditions: make(map[*mtconnect.DataItem][]mtconnect.Condition),
+		conns:      make(map[*conn]struct{}),
+	}
+	// До первого опроса все значения недоступны
+	a.Update(nil, time.Now())
+	return a
+}
+
+// Device возвращает описание устройства адаптера.
+func (a *Adapter) Device() *mtconnect.Device {
+	return a.device
+}
+
+// Run опрашивает станок до отмены ctx или закрытия клиента и передает изменения
+// подключенным агентам. После остановки все значения становятся недоступными.
+func (a *Adapter) Run(ctx context.Context) error {
+	if a.client == nil {
+		return errors.New("shdr: adapter has no client")
+	}
+	snapshots, err := a.client.Subscribe(ctx, a.opts.Subscribe)
+	if err != nil {
+		return err
+	}
+	for s := range snapshots {
+		a.Update(s.Data, s.Timestamp)
+	}
+	a.Update(nil, time.Now())
+	return ctx.Err()
+}
+
+// Update передает подключенным агентам значения из снимка data (nil — станок недоступен),
+// изменившиеся с предыдущего снимка.
+func (a *Adapter) Update(data *models.AggregatedData, ts time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.timestamp = ts
+
+	var values []string
+	var conditions []string
+	for _, item := range a.device.Items() {
+		if item.Category == mtconnect.CategoryCondition {
+			conditions = append(conditions, a.setConditions(item, a.device.Conditions(data), ts)...)
+			continue
+		}
+		value := a.device.Value(item, data)
+		if prev, ok := a.values[item]; ok && prev == value {
+			continue
+		}
+		a.values[item] = value
+		values = append(values, item.ID, clean(value))
+	}
+
+	var b strings.Builder
+	if len(values) > 0 {
+		writeLine(&b, ts, values...)
+	}
+	for _, line := range conditions {
+		b.WriteString(line)
+	}
+	if b.Len() > 0 {
+		a.broadcast([]byte(b.String()))
+	}
+}
+
+// setConditions заменяет активные состояния условия item и возвращает строки изменений:
+// новые тревоги, сброс отдельных тревог (NORMAL с кодом) или общий переход
+// в NORMAL или UNAVAILABLE без кода.
+func (a *Adapter) setConditions(item *mtconnect.DataItem, values []mtconnect.Condition, ts time.Time) []string {
+	active := a.conditions[item]
+	a.conditions[item] = values
+
+	if len(values) == 1 && values[0].NativeCode == "" {
+		if len(active) == 1 && active[0] == values[0] {
+			return nil
+		}
+		return []string{conditionLine(ts, item, values[0])}
+	}
+
+	previous := make(map[string]mtconnect.Condition, len(active))
+	for _, c := range active {
+		previous[c.NativeCode] = c
+	}
+	current := make(map[string]bool, len(values))
+	var lines []string
+	for _, v := range values {
+		current[v.NativeCode] = true
+		if prev, ok := previous[v.NativeCode]; ok && prev == v {
+			continue
+		}
+		lines = append(lines, conditionLine(ts, item, v))
+	}
+	for _, c := range active {
+		if c.NativeCode != "" && !current[c.NativeCode] {
+			lines = append(lines, conditionLine(ts, item, mtconnect.Condition{Level: mtconnect.LevelNormal, NativeCode: c.NativeCode}))
+		}
+	}
+	return lines
+}
+
+// snapshot возвращает все текущие значения для нового подключения. Вызывается под a.mu.
+func (a *Adapter) snapshot() []byte {
+	var b strings.Builder
+	var values []string
+	for _, item := range a.device.Items() {
+		if item.Category == mtconnect.CategoryCondition {
+			continue
+		}
+		values = append(values, item.ID, clean(a.values[item]))
+	}
+	if len(values) > 0 {
+		writeLine(&b, a.timestamp, values...)
+	}
+	for _, item := range a.device.Items() {
+		for _, c := range a.conditions[item] {
+			b.WriteString(conditionLine(a.timestamp, item, c))
+		}
+	}
+	return []byte(b.String())
+}
+
+// Start начинает прием подключений агентов на addr (например, DefaultAddr) в фоне.
+func (a *Adapter) Start(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen %s: %w", addr, err)
+	}
+
+	a.mu.Lock()
+	if a.ln != nil {
+		a.mu.Unlock()
+		ln.Close()
+		return errors.New("shdr adapter already started")
+	}
+	a.ln = ln
+	a.done = make(chan struct{})
+	a.mu.Unlock()
+
+	go a.serve(ln)
+	return nil
+}
+
+// Close останавливает прием подключений и разрывает все соединения.
+func (a *Adapter) Close() error {
+	a.mu.Lock()
+	ln, done := a.ln, a.done
+	for c := range a.conns {
+		c.close()
+	}
+	a.mu.Unlock()
+
+	if ln == nil {
+		return nil
+	}
+	err := ln.Close()
+	<-done
+	return err
+}
+
+// Addr возвращает адрес, на котором адаптер принимает подключения.
+func (a *Adapter) Addr() net.Addr {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.ln == nil {
+		return nil
+	}
+	return a.ln.Addr()
+}
+
+func (a *Adapter) serve(ln net.Listener) {
+	defer close(a.done)
+	for {
+		nc, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		c := newConn(nc)
+
+		// Новое подключение получает все текущие значения раньше последующих изменений
+		a.mu.Lock()
+		c.send(a.snapshot())
+		a.conns[c] = struct{}{}
+		a.mu.Unlock()
+
+		go c.writeLoop()
+		go func() {
+			a.readLoop(c)
+			a.mu.Lock()
+			delete(a.conns, c)
+			a.mu.Unlock()
+			c.close()
+		}()
+	}
+}
+
+// readLoop отвечает на проверки связи агента до закрытия соединения.
+func (a *Adapter) readLoop(c *conn) {
+	scanner := bufio.NewScanner(c.nc)
+	for scanner.Scan() {
+		if strings.HasPrefix(strings.TrimSpace(scanner.Text()), "* PING") {
+			c.send([]byte(fmt.Sprintf("* PONG %d\n", a.opts.Heartbeat.Milliseconds())))
+		}
+	}
+}
+
+// broadcast отправляет данные всем подключениям. Вызывается под a.mu.
+func (a *Adapter) broadcast(data []byte) {
+	for c := range a.conns {
+		c.send(data)
+	}
+}
+
+// conn — подключение агента. Запись выполняется отдельной горутиной, чтобы медленный
+// агент не задерживал опрос; при переполнении очереди соединение разрывается,
+// и после переподключения агент получает все значения заново.
+type conn struct {
+	nc   net.Conn
+	out  chan []byte
+	once sync.Once
+	done chan struct{}
+}
+
+func newConn(nc net.Conn) *conn {
+	return &conn{nc: nc, out: make(chan []byte, 256), done: make(chan struct{})}
+}
+
+func (c *conn) send(data []byte) {
+	select {
+	case c.out <- data:
+	case <-c.done:
+	default:
+		c.close()
+	}
+}
+
+func (c *conn) writeLoop() {
+	for {
+		select {
+		case data := <-c.out:
+			if _, err := c.nc.Write(data); err != nil {
+				c.close()
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *conn) close() {
+	c.once.Do(func() {
+		close(c.done)
+		c.nc.Close()
+	})
+}
+
+// writeLine записывает строку SHDR "время|ключ|значение|...".
+func writeLine(b *strings.Builder, ts time.Time, fields ...string) {
+	b.WriteString(formatTime(ts))
+	for _, f := range fields {
+		b.WriteByte('|')
+		b.WriteString(f)
+	}
+	b.WriteByte('\n')
+}
+
+// conditionLine возвращает строку условия "время|ключ|уровень|код|важность|квалификатор|сообщение".
+func conditionLine(ts time.Time, item *mtconnect.DataItem, c mtconnect.Condition) string {
+	var b strings.Builder
+	writeLine(&b, ts, item.ID, strings.ToUpper(c.Level), clean(c.NativeCode), "", "", clean(c.Message))
+	return b.String()
+}
+
+// clean убирает из значения разделители полей и строк SHDR.
+func clean(value string) string {
+	return strings.NewReplacer("|", " ", "\n", " ", "\r", " ").Replace(value)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000000Z")
+}
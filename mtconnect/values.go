@@ -0,0 +1,160 @@
+package mtconnect
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/iwtcode/fanucAdapter/focas/interpreter"
+	"github.com/iwtcode/fanucAdapter/models"
+)
+
+// Unavailable — значение элемента данных, которое не удалось получить.
+const Unavailable = "UNAVAILABLE"
+
+// Уровни условий (Condition).
+const (
+	LevelNormal      = "Normal"
+	LevelWarning     = "Warning"
+	LevelFault       = "Fault"
+	LevelUnavailable = "Unavailable"
+)
+
+// Condition — состояние условия (CONDITION) в одном снимке.
+type Condition struct {
+	Level      string // LevelNormal, LevelFault и т.д.
+	NativeCode string // Код тревоги станка (см. AlarmNativeCode); пусто для состояния без тревог
+	Message    string
+}
+
+// sectionGood сообщает, прочитан ли раздел section в data. Значения Stale считаются
+// недоступными: MTConnect не передает устаревшие значения как текущие.
+func sectionGood(data *models.AggregatedData, section models.Section) bool {
+	if data.Sections == nil {
+		return true
+	}
+	return data.Sections[section].Quality == models.QualityGood
+}
+
+// sectionValue возвращает функцию чтения, которая недоступна, если раздел не прочитан.
+func sectionValue(section models.Section, read func(*models.AggregatedData) (string, bool)) func(*models.AggregatedData) (string, bool) {
+	return func(data *models.AggregatedData) (string, bool) {
+		if !sectionGood(data, section) {
+			return "", false
+		}
+		return read(data)
+	}
+}
+
+// stateValue — sectionValue для раздела состояния станка.
+func stateValue(read func(*models.AggregatedData) (string, bool)) func(*models.AggregatedData) (string, bool) {
+	return sectionValue(models.SectionState, read)
+}
+
+// Value возвращает значение события или сэмпла item в снимке data (nil — станок недоступен)
+// или Unavailable.
+func (d *Device) Value(item *DataItem, data *models.AggregatedData) string {
+	if item.ID == "avail" {
+		return availability(data)
+	}
+	if item.read == nil || data == nil {
+		return Unavailable
+	}
+	v, ok := item.read(data)
+	if !ok {
+		return Unavailable
+	}
+	return v
+}
+
+// availability — AVAILABLE, если в снимке прочитан хотя бы один раздел.
+func availability(data *models.AggregatedData) string {
+	if data == nil {
+		return Unavailable
+	}
+	if data.Sections == nil {
+		return "AVAILABLE"
+	}
+	for _, status := range data.Sections {
+		if status.Quality == models.QualityGood {
+			return "AVAILABLE"
+		}
+	}
+	return Unavailable
+}
+
+// Conditions возвращает активные состояния условия SYSTEM: тревоги станка
+// или одно состояние Normal (Unavailable) без кода.
+func (d *Device) Conditions(data *models.AggregatedData) []Condition {
+	if data == nil || !sectionGood(data, models.SectionAlarms) {
+		return []Condition{{Level: LevelUnavailable}}
+	}
+	if len(data.Alarms) == 0 {
+		return []Condition{{Level: LevelNormal}}
+	}
+	values := make([]Condition, 0, len(data.Alarms))
+	seen := make(map[string]bool, len(data.Alarms))
+	for _, alarm := range data.Alarms {
+		code := AlarmNativeCode(alarm)
+		// Повторяющиеся коды тревог — одно состояние
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		values = append(values, Condition{
+			Level:      LevelFault,
+			NativeCode: code,
+			Message:    alarm.ErrorMessage,
+		})
+	}
+	return values
+}
+
+// AlarmNativeCode возвращает код тревоги в виде, принятом на станках FANUC: тип тревоги
+// FOCAS и номер, например "SV0401". Если тип неизвестен, возвращается только номер.
+func AlarmNativeCode(alarm models.AlarmDetail) string {
+	number := alarm.ErrorCode
+	if len(number) < 4 {
+		number = strings.Repeat("0", 4-len(number)) + number
+	}
+	// Описание типа начинается с его обозначения: "SV – Servo alarm"
+	if prefix, _, ok := strings.Cut(alarm.ErrorTypeDescription, " "); ok && len(prefix) == 2 &&
+		strings.ToUpper(prefix) == prefix && strings.Trim(prefix, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") == "" {
+		return prefix + number
+	}
+	return alarm.ErrorCode
+}
+
+// execution преобразует состояние станка (MachineState) в EXECUTION.
+func execution(state string) (string, bool) {
+	switch state {
+	case interpreter.MachineStateReset:
+		return "READY", true
+	case interpreter.MachineStateStop:
+		return "STOPPED", true
+	case interpreter.MachineStateHold:
+		return "FEED_HOLD", true
+	case interpreter.MachineStateStart, interpreter.MachineStateMSTR:
+		return "ACTIVE", true
+	}
+	return "", false
+}
+
+// controllerMode преобразует режим работы (ProgramMode) в CONTROLLER_MODE.
+func controllerMode(mode string) (string, bool) {
+	switch mode {
+	case interpreter.ProgramModeMemory, interpreter.ProgramModeRemote:
+		return "AUTOMATIC", true
+	case interpreter.ProgramModeMDI:
+		return "MANUAL_DATA_INPUT", true
+	case interpreter.ProgramModeEdit:
+		return "EDIT", true
+	case interpreter.ProgramModeHandle, interpreter.ProgramModeJOG, interpreter.ProgramModeIncFeed,
+		interpreter.ProgramModeReference, interpreter.ProgramModeTeachInJOG, interpreter.ProgramModeTeachInHandle:
+		return "MANUAL", true
+	}
+	return "", false
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,162 @@
+package mtconnect
+
+import (
+	"encoding/xml"
+	"strings"
+	"time"
+)
+
+// Версия схемы MTConnect, которой соответствуют ответы агента.
+const schemaVersion = "1.3"
+
+const (
+	devicesNamespace = "urn:mtconnect.org:MTConnectDevices:" + schemaVersion
+	streamsNamespace = "urn:mtconnect.org:MTConnectStreams:" + schemaVersion
+	errorNamespace   = "urn:mtconnect.org:MTConnectError:" + schemaVersion
+)
+
+// Коды ошибок MTConnect.
+const (
+	errorInvalidRequest = "INVALID_REQUEST"
+	errorOutOfRange     = "OUT_OF_RANGE"
+	errorUnsupported    = "UNSUPPORTED"
+	errorNoDevice       = "NO_DEVICE"
+)
+
+type header struct {
+	CreationTime  string `xml:"creationTime,attr"`
+	Sender        string `xml:"sender,attr"`
+	InstanceID    int64  `xml:"instanceId,attr"`
+	Version       string `xml:"version,attr"`
+	BufferSize    int    `xml:"bufferSize,attr"`
+	NextSequence  uint64 `xml:"nextSequence,attr,omitempty"`
+	FirstSequence uint64 `xml:"firstSequence,attr,omitempty"`
+	LastSequence  uint64 `xml:"lastSequence,attr,omitempty"`
+}
+
+type devicesDocument struct {
+	XMLName xml.Name  `xml:"MTConnectDevices"`
+	XMLNS   string    `xml:"xmlns,attr"`
+	Header  header    `xml:"Header"`
+	Devices []*Device `xml:"Devices>Device"`
+}
+
+type streamsDocument struct {
+	XMLName xml.Name       `xml:"MTConnectStreams"`
+	XMLNS   string         `xml:"xmlns,attr"`
+	Header  header         `xml:"Header"`
+	Streams []deviceStream `xml:"Streams>DeviceStream"`
+}
+
+type deviceStream struct {
+	Name       string            `xml:"name,attr"`
+	UUID       string            `xml:"uuid,attr"`
+	Components []componentStream `xml:"ComponentStream"`
+}
+
+type componentStream struct {
+	Component   string           `xml:"component,attr"`
+	Name        string           `xml:"name,attr,omitempty"`
+	ComponentID string           `xml:"componentId,attr"`
+	Samples     *xmlObservations `xml:"Samples"`
+	Events      *xmlObservations `xml:"Events"`
+	Condition   *xmlObservations `xml:"Condition"`
+}
+
+// xmlObservations — список наблюдений; nil не выводится.
+type xmlObservations struct {
+	Items []xmlObservation
+}
+
+func add(o **xmlObservations, x xmlObservation) {
+	if *o == nil {
+		*o = &xmlObservations{}
+	}
+	(*o).Items = append((*o).Items, x)
+}
+
+// xmlObservation — наблюдение в потоке. Имя элемента задается типом элемента данных
+// (Position, Execution) или уровнем условия (Normal, Fault).
+type xmlObservation struct {
+	XMLName    xml.Name
+	DataItemID string `xml:"dataItemId,attr"`
+	Timestamp  string `xml:"timestamp,attr"`
+	Sequence   uint64 `xml:"sequence,attr"`
+	Name       string `xml:"name,attr,omitempty"`
+	SubType    string `xml:"subType,attr,omitempty"`
+	Type       string `xml:"type,attr,omitempty"`
+	NativeCode string `xml:"nativeCode,attr,omitempty"`
+	Value      string `xml:",chardata"`
+}
+
+type errorDocument struct {
+	XMLName xml.Name   `xml:"MTConnectError"`
+	XMLNS   string     `xml:"xmlns,attr"`
+	Header  header     `xml:"Header"`
+	Errors  []xmlError `xml:"Errors>Error"`
+}
+
+type xmlError struct {
+	Code string `xml:"errorCode,attr"`
+	Text string `xml:",chardata"`
+}
+
+// newStream группирует наблюдения по компонентам в порядке документа устройства.
+func newStream(d *Device, observations []observation) deviceStream {
+	stream := deviceStream{Name: d.Name, UUID: d.UUID}
+	index := make(map[*Component]int)
+	for _, o := range observations {
+		c := o.Item.component
+		i, ok := index[c]
+		if !ok {
+			cs := componentStream{Component: "Device", Name: d.Name, ComponentID: d.ID}
+			if c != nil {
+				cs = componentStream{Component: c.Type(), Name: c.Name, ComponentID: c.ID}
+			}
+			stream.Components = append(stream.Components, cs)
+			i = len(stream.Components) - 1
+			index[c] = i
+		}
+
+		cs := &stream.Components[i]
+		x := xmlObservation{
+			DataItemID: o.Item.ID,
+			Timestamp:  formatTime(o.Timestamp),
+			Sequence:   o.Sequence,
+			Name:       o.Item.Name,
+			SubType:    o.Item.SubType,
+		}
+		switch o.Item.Category {
+		case CategorySample:
+			x.XMLName.Local = elementName(o.Item.Type)
+			x.Value = o.Value
+			add(&cs.Samples, x)
+		case CategoryEvent:
+			x.XMLName.Local = elementName(o.Item.Type)
+			x.Value = o.Value
+			add(&cs.Events, x)
+		case CategoryCondition:
+			x.XMLName.Local = o.Condition.Level
+			x.Type = o.Item.Type
+			x.NativeCode = o.Condition.NativeCode
+			x.Value = o.Condition.Message
+			add(&cs.Condition, x)
+		}
+	}
+	return stream
+}
+
+// elementName преобразует тип элемента данных в имя элемента потока: PATH_FEEDRATE -> PathFeedrate.
+func elementName(dataItemType string) string {
+	parts := strings.Split(strings.ToLower(dataItemType), "_")
+	for i, p := range parts {
+		if p != "" {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000000Z")
+}
@@ -0,0 +1,100 @@
+package opcua
+
+import (
+	"strings"
+	"time"
+
+	"github.com/iwtcode/fanucAdapter/models"
+	"github.com/iwtcode/fanucAdapter/opcua/ua"
+)
+
+// alarmSeverity — важность событий тревог (диапазон 1–1000). Тревоги ЧПУ
+// останавливают обработку, поэтому все они считаются высокими.
+const alarmSeverity uint16 = 800
+
+// alarmEvent — событие AlarmConditionType о появлении или сбросе тревоги станка.
+// Тревоги ЧПУ не требуют подтверждения в OPC UA и считаются подтвержденными.
+type alarmEvent struct {
+	id          []byte
+	source      ua.NodeID
+	sourceName  string
+	conditionID ua.NodeID
+	time        time.Time
+	receiveTime time.Time
+	alarm       models.AlarmDetail
+	active      bool
+}
+
+func newAlarmEvent(m *machine, alarm models.AlarmDetail, active bool, ts time.Time) *alarmEvent {
+	return &alarmEvent{
+		id:          randomBytes(16),
+		source:      m.object.id,
+		sourceName:  m.id,
+		conditionID: MachineNodeID(m.id, "Alarms/"+alarm.ErrorCode),
+		time:        ts,
+		receiveTime: time.Now(),
+		alarm:       alarm,
+		active:      active,
+	}
+}
+
+// fields возвращает значения полей события, выбранных фильтром. Неизвестные поля
+// возвращаются пустыми.
+func (e *alarmEvent) fields(selects []ua.SimpleAttributeOperand) []ua.Variant {
+	fields := make([]ua.Variant, len(selects))
+	for i, op := range selects {
+		fields[i] = e.field(op)
+	}
+	return fields
+}
+
+func (e *alarmEvent) field(op ua.SimpleAttributeOperand) ua.Variant {
+	// ConditionId выбирается атрибутом NodeId самого условия
+	if op.AttributeID == ua.AttributeNodeID && len(op.BrowsePath) == 0 {
+		return ua.NewVariant(e.conditionID)
+	}
+	if op.AttributeID != ua.AttributeValue {
+		return ua.Variant{}
+	}
+
+	names := make([]string, len(op.BrowsePath))
+	for i, name := range op.BrowsePath {
+		names[i] = name.Name
+	}
+	switch strings.Join(names, "/") {
+	case "EventId":
+		return ua.NewVariant(e.id)
+	case "EventType":
+		return ua.NewVariant(ns0(ua.IDAlarmConditionType))
+	case "SourceNode":
+		return ua.NewVariant(e.source)
+	case "SourceName":
+		return ua.NewVariant(e.sourceName)
+	case "Time":
+		return ua.NewVariant(e.time)
+	case "ReceiveTime":
+		return ua.NewVariant(e.receiveTime)
+	case "Message":
+		return ua.NewVariant(ua.NewText(alarmText(e.alarm)))
+	case "Severity":
+		return ua.NewVariant(alarmSeverity)
+	case "ConditionName":
+		return ua.NewVariant(e.alarm.ErrorCode)
+	case "Retain":
+		return ua.NewVariant(e.active)
+	case "ActiveState":
+		if e.active {
+			return ua.NewVariant(ua.NewText("Active"))
+		}
+		return ua.NewVariant(ua.NewText("Inactive"))
+	case "ActiveState/Id":
+		return ua.NewVariant(e.active)
+	case "AckedState":
+		return ua.NewVariant(ua.NewText("Acknowledged"))
+	case "AckedState/Id", "EnabledState/Id":
+		return ua.NewVariant(true)
+	case "EnabledState":
+		return ua.NewVariant(ua.NewText("Enabled"))
+	}
+	return ua.Variant{}
+}
@@ -0,0 +1,448 @@
+package opcua
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	"github.com/iwtcode/fanucAdapter/models"
+	"github.com/iwtcode/fanucAdapter/opcua/ua"
+)
+
+// machine — объект станка в адресном пространстве.
+type machine struct {
+	id     string
+	object *node
+	props  map[string]*node // Свойства системной информации по имени
+	vars   map[string]*node // Переменные по пути относительно объекта станка
+	axes   []string         // Имена осей в порядке появления
+	// spindles — номера шпинделей в порядке появления
+	spindles []int16
+	// alarms — активные тревоги по коду; nil до первого чтения состояния
+	alarms map[string]models.AlarmDetail
+}
+
+// variable описывает переменную раздела сводных данных.
+type variable struct {
+	name     string
+	dataType uint32
+	rank     int32
+	value    func(data *models.AggregatedData) any
+}
+
+// stateVariables повторяют поля models.UnifiedMachineData.
+var stateVariables = []variable{
+	{"TmMode", ua.IDString, ua.ValueRankScalar, func(d *models.AggregatedData) any { return d.TmMode }},
+	{"ProgramMode", ua.IDString, ua.ValueRankScalar, func(d *models.AggregatedData) any { return d.ProgramMode }},
+	{"MachineState", ua.IDString, ua.ValueRankScalar, func(d *models.AggregatedData) any { return d.MachineState }},
+	{"AxisMovementStatus", ua.IDString, ua.ValueRankScalar, func(d *models.AggregatedData) any { return d.AxisMovementStatus }},
+	{"MstbStatus", ua.IDString, ua.ValueRankScalar, func(d *models.AggregatedData) any { return d.MstbStatus }},
+	{"EmergencyStatus", ua.IDString, ua.ValueRankScalar, func(d *models.AggregatedData) any { return d.EmergencyStatus }},
+	{"AlarmStatus", ua.IDString, ua.ValueRankScalar, func(d *models.AggregatedData) any { return d.AlarmStatus }},
+	{"EditStatus", ua.IDString, ua.ValueRankScalar, func(d *models.AggregatedData) any { return d.EditStatus }},
+}
+
+// alarmVariables находятся в папке State, но их качество определяется разделом тревог.
+var alarmVariables = []variable{
+	{"Alarms", ua.IDString, ua.ValueRankArray, func(d *models.AggregatedData) any {
+		alarms := make([]string, len(d.Alarms))
+		for i, alarm := range d.Alarms {
+			alarms[i] = alarmText(alarm)
+		}
+		return alarms
+	}},
+}
+
+var programVariables = []variable{
+	{"Name", ua.IDString, ua.ValueRankScalar, func(d *models.AggregatedData) any { return d.CurrentProgram.ProgramName }},
+	{"Number", ua.IDInt64, ua.ValueRankScalar, func(d *models.AggregatedData) any { return d.CurrentProgram.ProgramNumber }},
+	{"GCodeLine", ua.IDString, ua.ValueRankScalar, func(d *models.AggregatedData) any { return d.CurrentProgram.GCodeLine }},
+}
+
+var counterVariables = []variable{
+	{"PartsCount", ua.IDInt64, ua.ValueRankScalar, func(d *models.AggregatedData) any { return d.PartsCount }},
+	{"PowerOnTime", ua.IDString, ua.ValueRankScalar, func(d *models.AggregatedData) any { return d.PowerOnTime }},
+	{"OperatingTime", ua.IDString, ua.ValueRankScalar, func(d *models.AggregatedData) any { return d.OperatingTime }},
+	{"CycleTime", ua.IDString, ua.ValueRankScalar, func(d *models.AggregatedData) any { return d.CycleTime }},
+	{"CuttingTime", ua.IDString, ua.ValueRankScalar, func(d *models.AggregatedData) any { return d.CuttingTime }},
+}
+
+// itemVariable описывает переменную оси или шпинделя. field — JSON-имя поля
+// в карте качества (пусто — поле без собственного качества).
+type itemVariable[T any] struct {
+	name     string
+	dataType uint32
+	field    string
+	value    func(T) any
+}
+
+var axisVariables = []itemVariable[models.AxisInfo]{
+	{"Position", ua.IDDouble, "", func(a models.AxisInfo) any { return a.Position }},
+	{"Load", ua.IDDouble, "load_percent", func(a models.AxisInfo) any { return a.LoadPercent }},
+	{"ServoTemperature", ua.IDInt32, "servo_temperature", func(a models.AxisInfo) any { return a.ServoTemperature }},
+	{"CoderTemperature", ua.IDInt32, "coder_temperature", func(a models.AxisInfo) any { return a.CoderTemperature }},
+	{"PowerConsumption", ua.IDInt32, "power_consumption", func(a models.AxisInfo) any { return a.PowerConsumption }},
+}
+
+var spindleVariables = []itemVariable[models.SpindleInfo]{
+	{"Speed", ua.IDInt32, "speed_rpm", func(s models.SpindleInfo) any { return s.SpeedRPM }},
+	{"Load", ua.IDDouble, "load_percent", func(s models.SpindleInfo) any { return s.LoadPercent }},
+	{"Override", ua.IDInt16, "override_percent", func(s models.SpindleInfo) any { return s.OverridePercent }},
+	{"PowerConsumption", ua.IDInt32, "power_consumption", func(s models.SpindleInfo) any { return s.PowerConsumption }},
+}
+
+// systemProperties — свойства объекта станка из models.SystemInfo.
+var systemProperties = []struct {
+	name     string
+	dataType uint32
+	value    func(*models.SystemInfo) any
+}{
+	{"Manufacturer", ua.IDString, func(i *models.SystemInfo) any { return i.Manufacturer }},
+	{"Model", ua.IDString, func(i *models.SystemInfo) any { return i.Model }},
+	{"Series", ua.IDString, func(i *models.SystemInfo) any { return i.Series }},
+	{"Version", ua.IDString, func(i *models.SystemInfo) any { return i.Version }},
+	{"MaxAxes", ua.IDInt16, func(i *models.SystemInfo) any { return i.MaxAxes }},
+	{"ControlledAxes", ua.IDInt16, func(i *models.SystemInfo) any { return i.ControlledAxes }},
+	{"Paths", ua.IDInt16, func(i *models.SystemInfo) any { return i.Paths }},
+	{"CncType", ua.IDString, func(i *models.SystemInfo) any { return i.CncType }},
+	{"MachineType", ua.IDString, func(i *models.SystemInfo) any { return i.MachineType }},
+	{"ModelSeries", ua.IDString, func(i *models.SystemInfo) any { return i.ModelSeries }},
+	{"Implementation", ua.IDString, func(i *models.SystemInfo) any { return i.Implementation }},
+}
+
+// MachineNodeID возвращает идентификатор узла станка id или его переменной по пути path,
+// например MachineNodeID("lathe", "Axes/X/Position").
+func MachineNodeID(id, path string) ua.NodeID {
+	if path == "" {
+		return ua.NewStringNodeID(1, id)
+	}
+	return ua.NewStringNodeID(1, id+"/"+path)
+}
+
+// AddMachine добавляет объект станка id с системной информацией info (nil — еще не прочитана).
+// Папки осей и шпинделей заполняются по мере появления данных (см. Update).
+func (s *Server) AddMachine(id string, info *models.SystemInfo) error {
+	if id == "" {
+		return errors.New("opcua: machine id is empty")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.machines[id]; ok {
+		return fmt.Errorf("opcua: machine %q already exists", id)
+	}
+
+	m := &machine{
+		id:    id,
+		props: make(map[string]*node),
+		vars:  make(map[string]*node),
+	}
+	m.object = s.addNode(&node{id: MachineNodeID(id, ""), class: ua.NodeClassObject,
+		browseName: ua.QualifiedName{NamespaceIndex: 1, Name: id}, eventNotifier: ua.EventNotifierSubscribe},
+		ns0(ua.IDObjectsFolder), ua.IDOrganizes, ns0(ua.IDBaseObjectType))
+	s.addReference(ns0(ua.IDServer), ns0(ua.IDHasNotifier), m.object.id)
+	s.addReference(m.object.id, ns0(ua.IDGeneratesEvent), ns0(ua.IDAlarmConditionType))
+
+	for _, p := range systemProperties {
+		m.props[p.name] = s.addNode(&node{id: MachineNodeID(id, p.name), class: ua.NodeClassVariable,
+			browseName: ua.QualifiedName{NamespaceIndex: 1, Name: p.name}, dataType: ns0(p.dataType), valueRank: ua.ValueRankScalar},
+			m.object.id, ua.IDHasProperty, ns0(ua.IDPropertyType))
+	}
+	for _, folder := range []string{"Axes", "Spindles", "State", "Program", "Counters"} {
+		s.addFolder(m, folder, "")
+	}
+	for _, v := range stateVariables {
+		s.addVariable(m, "State", v.name, v.dataType, v.rank)
+	}
+	for _, v := range alarmVariables {
+		s.addVariable(m, "State", v.name, v.dataType, v.rank)
+	}
+	for _, v := range programVariables {
+		s.addVariable(m, "Program", v.name, v.dataType, v.rank)
+	}
+	for _, v := range counterVariables {
+		s.addVariable(m, "Counters", v.name, v.dataType, v.rank)
+	}
+	s.machines[id] = m
+
+	s.setSystemInfo(m, info)
+	s.updateMachine(m, nil, time.Now())
+	return nil
+}
+
+// addFolder добавляет папку name в папку parent станка (пустой parent — в объект станка).
+func (s *Server) addFolder(m *machine, name, parent string) *node {
+	parentID := m.object.id
+	path := name
+	if parent != "" {
+		parentID = MachineNodeID(m.id, parent)
+		path = parent + "/" + name
+	}
+	return s.addNode(&node{id: MachineNodeID(m.id, path), class: ua.NodeClassObject,
+		browseName: ua.QualifiedName{NamespaceIndex: 1, Name: name}}, parentID, ua.IDOrganizes, ns0(ua.IDFolderType))
+}
+
+// addVariable добавляет переменную name в папку parent станка. До первого обновления
+// значение отсутствует с кодом BadWaitingForInitialData.
+func (s *Server) addVariable(m *machine, parent, name string, dataType uint32, rank int32) *node {
+	path := parent + "/" + name
+	n := s.addNode(&node{id: MachineNodeID(m.id, path), class: ua.NodeClassVariable,
+		browseName: ua.QualifiedName{NamespaceIndex: 1, Name: name}, dataType: ns0(dataType), valueRank: rank,
+		value: ua.DataValue{Status: ua.BadWaitingForInitialData, ServerTimestamp: time.Now()}},
+		MachineNodeID(m.id, parent), ua.IDHasComponent, ns0(ua.IDBaseDataVariableType))
+	m.vars[path] = n
+	return n
+}
+
+// SetSystemInfo обновляет свойства станка id.
+func (s *Server) SetSystemInfo(id string, info *models.SystemInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.machines[id]
+	if !ok {
+		return fmt.Errorf("opcua: unknown machine %q", id)
+	}
+	s.setSystemInfo(m, info)
+	return nil
+}
+
+// setSystemInfo записывает свойства станка. Неизменившиеся значения сохраняют
+// прежние метки времени. Вызывается под s.mu.
+func (s *Server) setSystemInfo(m *machine, info *models.SystemInfo) {
+	now := time.Now()
+	for _, p := range systemProperties {
+		n := m.props[p.name]
+		value := ua.DataValue{Status: ua.BadWaitingForInitialData, ServerTimestamp: now}
+		if info != nil {
+			value = ua.DataValue{Value: ua.NewVariant(p.value(info)), SourceTimestamp: now, ServerTimestamp: now}
+		}
+		if n.value.ServerTimestamp.IsZero() || n.value.Status != value.Status || !reflect.DeepEqual(n.value.Value, value.Value) {
+			n.value = value
+		}
+	}
+}
+
+// Update обновляет переменные станка id по снимку data (nil — станок недоступен)
+// и публикует события появления и сброса тревог.
+func (s *Server) Update(id string, data *models.AggregatedData, ts time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.machines[id]
+	if !ok {
+		return fmt.Errorf("opcua: unknown machine %q", id)
+	}
+	s.updateMachine(m, data, ts)
+	return nil
+}
+
+// Run добавляет станок id, если его еще нет, и обновляет его по данным опроса client
+// до отмены ctx или закрытия клиента. После остановки значения станка получают
+// код BadNoCommunication.
+func (s *Server) Run(ctx context.Context, id string, client *fanuc.Client, opts fanuc.SubscribeOptions) error {
+	s.mu.Lock()
+	_, ok := s.machines[id]
+	s.mu.Unlock()
+	if !ok {
+		if err := s.AddMachine(id, client.GetSystemInfo()); err != nil {
+			return err
+		}
+	}
+
+	snapshots, err := client.Subscribe(ctx, opts)
+	if err != nil {
+		return err
+	}
+	for snapshot := range snapshots {
+		if snapshot.SystemInfo != nil {
+			_ = s.SetSystemInfo(id, snapshot.SystemInfo)
+		}
+		if snapshot.Data != nil {
+			_ = s.Update(id, snapshot.Data, snapshot.Timestamp)
+		}
+	}
+	_ = s.Update(id, nil, time.Now())
+	return ctx.Err()
+}
+
+// updateMachine записывает значения переменных станка. Вызывается под s.mu.
+func (s *Server) updateMachine(m *machine, data *models.AggregatedData, ts time.Time) {
+	now := time.Now()
+	setSection := func(folder string, vars []variable, section models.Section) {
+		status, source := sectionStatus(data, section, ts)
+		for _, v := range vars {
+			setValue(m.vars[folder+"/"+v.name], status, source, now, func() any { return v.value(data) })
+		}
+	}
+	setSection("State", stateVariables, models.SectionState)
+	setSection("State", alarmVariables, models.SectionAlarms)
+	setSection("Program", programVariables, models.SectionProgram)
+	setSection("Counters", counterVariables, models.SectionParameters)
+
+	// Оси и шпиндели
+	status, source := sectionStatus(data, models.SectionAxes, ts)
+	axes := make(map[string]models.AxisInfo)
+	if data != nil && !status.IsBad() {
+		for _, axis := range data.AxisInfos {
+			if _, ok := axes[axis.Name]; ok {
+				continue
+			}
+			axes[axis.Name] = axis
+			s.ensureAxis(m, axis.Name)
+		}
+	}
+	for _, name := range m.axes {
+		axis, ok := axes[name]
+		for _, v := range axisVariables {
+			n := m.vars["Axes/"+name+"/"+v.name]
+			if !status.IsBad() && !ok {
+				setValue(n, ua.BadNoData, time.Time{}, now, nil)
+				continue
+			}
+			setValue(n, fieldStatus(status, axis.Quality, v.field), source, now, func() any { return v.value(axis) })
+		}
+	}
+
+	status, source = sectionStatus(data, models.SectionSpindles, ts)
+	spindles := make(map[int16]models.SpindleInfo)
+	if data != nil && !status.IsBad() {
+		for _, spindle := range data.SpindleInfos {
+			if _, ok := spindles[spindle.Number]; ok {
+				continue
+			}
+			spindles[spindle.Number] = spindle
+			s.ensureSpindle(m, spindle.Number)
+		}
+	}
+	for _, number := range m.spindles {
+		spindle, ok := spindles[number]
+		for _, v := range spindleVariables {
+			n := m.vars["Spindles/"+strconv.Itoa(int(number))+"/"+v.name]
+			if !status.IsBad() && !ok {
+				setValue(n, ua.BadNoData, time.Time{}, now, nil)
+				continue
+			}
+			setValue(n, fieldStatus(status, spindle.Quality, v.field), source, now, func() any { return v.value(spindle) })
+		}
+	}
+
+	if data != nil {
+		s.updateAlarms(m, data, ts)
+	}
+}
+
+// ensureAxis добавляет папку оси name, если ее еще нет.
+func (s *Server) ensureAxis(m *machine, name string) {
+	path := "Axes/" + name
+	if _, ok := s.nodes[MachineNodeID(m.id, path)]; ok {
+		return
+	}
+	s.addFolder(m, name, "Axes")
+	for _, v := range axisVariables {
+		s.addVariable(m, path, v.name, v.dataType, ua.ValueRankScalar)
+	}
+	m.axes = append(m.axes, name)
+}
+
+// ensureSpindle добавляет папку шпинделя number, если ее еще нет.
+func (s *Server) ensureSpindle(m *machine, number int16) {
+	name := strconv.Itoa(int(number))
+	path := "Spindles/" + name
+	if _, ok := s.nodes[MachineNodeID(m.id, path)]; ok {
+		return
+	}
+	s.addFolder(m, name, "Spindles")
+	for _, v := range spindleVariables {
+		s.addVariable(m, path, v.name, v.dataType, ua.ValueRankScalar)
+	}
+	m.spindles = append(m.spindles, number)
+}
+
+// updateAlarms сравнивает тревоги снимка с активными и публикует события. Тревоги
+// сравниваются только по успешно прочитанным тревогам; первое чтение публикует
+// события для всех активных тревог.
+func (s *Server) updateAlarms(m *machine, data *models.AggregatedData, ts time.Time) {
+	if data.Sections != nil && data.Sections[models.SectionAlarms].Quality != models.QualityGood {
+		return
+	}
+	current := make(map[string]models.AlarmDetail, len(data.Alarms))
+	for _, alarm := range data.Alarms {
+		if _, ok := current[alarm.ErrorCode]; ok {
+			continue
+		}
+		current[alarm.ErrorCode] = alarm
+		if _, ok := m.alarms[alarm.ErrorCode]; !ok {
+			s.emitEvent(m, newAlarmEvent(m, alarm, true, ts))
+		}
+	}
+	for code, alarm := range m.alarms {
+		if _, ok := current[code]; !ok {
+			s.emitEvent(m, newAlarmEvent(m, alarm, false, ts))
+		}
+	}
+	m.alarms = current
+}
+
+// setValue записывает значение переменной. Для кодов Bad значение не передается.
+func setValue(n *node, status ua.StatusCode, source, now time.Time, value func() any) {
+	dv := ua.DataValue{Status: status, SourceTimestamp: source, ServerTimestamp: now}
+	if !status.IsBad() && value != nil {
+		dv.Value = ua.NewVariant(value())
+	}
+	n.value = dv
+}
+
+// sectionStatus возвращает код состояния и время чтения раздела section снимка data.
+func sectionStatus(data *models.AggregatedData, section models.Section, ts time.Time) (ua.StatusCode, time.Time) {
+	if data == nil {
+		return ua.BadNoCommunication, time.Time{}
+	}
+	if data.Sections == nil {
+		return ua.Good, ts
+	}
+	status, ok := data.Sections[section]
+	if !ok {
+		return ua.BadWaitingForInitialData, time.Time{}
+	}
+	source := status.SourceTime
+	if source.IsZero() {
+		source = ts
+	}
+	return qualityStatus(status.Quality), source
+}
+
+// fieldStatus уточняет код состояния раздела качеством поля field.
+func fieldStatus(status ua.StatusCode, quality models.FieldQuality, field string) ua.StatusCode {
+	if status.IsBad() || field == "" {
+		return status
+	}
+	if q, ok := quality[field]; ok {
+		return qualityStatus(q)
+	}
+	return status
+}
+
+// qualityStatus возвращает код состояния OPC UA для качества значения.
+func qualityStatus(q models.Quality) ua.StatusCode {
+	switch q {
+	case models.QualityGood:
+		return ua.Good
+	case models.QualityStale:
+		return ua.UncertainLastUsableValue
+	case models.QualityNotSupported:
+		return ua.BadNotSupported
+	case models.QualityCommError:
+		return ua.BadNoCommunication
+	}
+	return ua.BadDeviceFailure
+}
+
+// alarmText возвращает описание тревоги: код и сообщение.
+func alarmText(alarm models.AlarmDetail) string {
+	if alarm.ErrorMessage == "" {
+		return alarm.ErrorCode
+	}
+	return alarm.ErrorCode + " " + alarm.ErrorMessage
+}
@@ -0,0 +1,275 @@
+package opcua
+
+import (
+	"time"
+
+	"github.com/iwtcode/fanucAdapter/opcua/ua"
+)
+
+const (
+	productURI      = "urn:iwtcode:fanucAdapter"
+	anonymousPolicy = "anonymous"
+)
+
+// node — узел адресного пространства.
+type node struct {
+	id          ua.NodeID
+	class       ua.NodeClass
+	browseName  ua.QualifiedName
+	displayName ua.LocalizedText
+	refs        []reference
+
+	// Переменные и типы переменных
+	value     ua.DataValue
+	read      func() ua.DataValue // Значение, вычисляемое при чтении (текущее время сервера)
+	dataType  ua.NodeID
+	valueRank int32
+
+	eventNotifier byte // Объекты
+
+	// Типы
+	isAbstract  bool
+	symmetric   bool             // Типы ссылок
+	inverseName ua.LocalizedText // Типы ссылок
+}
+
+// reference — ссылка узла на target.
+type reference struct {
+	typeID  ua.NodeID
+	forward bool
+	target  ua.NodeID
+}
+
+func ns0(id uint32) ua.NodeID {
+	return ua.NewNumericNodeID(0, id)
+}
+
+// currentValue возвращает текущее значение переменной.
+func (n *node) currentValue() ua.DataValue {
+	if n.read != nil {
+		return n.read()
+	}
+	return n.value
+}
+
+// typeDefinition возвращает тип объекта или переменной.
+func (n *node) typeDefinition() ua.NodeID {
+	for _, ref := range n.refs {
+		if ref.forward && ref.typeID == ns0(ua.IDHasTypeDefinition) {
+			return ref.target
+		}
+	}
+	return ua.NodeID{}
+}
+
+// addNode добавляет узел n со ссылкой refType от parent (пустой parent — без родителя)
+// и определением типа typeDef (пустое — без него). Вызывается под s.mu.
+func (s *Server) addNode(n *node, parent ua.NodeID, refType uint32, typeDef ua.NodeID) *node {
+	if n.displayName.Text == "" {
+		n.displayName = ua.NewText(n.browseName.Name)
+	}
+	s.nodes[n.id] = n
+	if !parent.IsNull() {
+		s.addReference(parent, ns0(refType), n.id)
+	}
+	if !typeDef.IsNull() {
+		n.refs = append(n.refs, reference{typeID: ns0(ua.IDHasTypeDefinition), forward: true, target: typeDef})
+	}
+	return n
+}
+
+// addReference добавляет ссылку source → target и обратную ей. Вызывается под s.mu.
+func (s *Server) addReference(source, refType, target ua.NodeID) {
+	if n, ok := s.nodes[source]; ok {
+		n.refs = append(n.refs, reference{typeID: refType, forward: true, target: target})
+	}
+	if n, ok := s.nodes[target]; ok {
+		n.refs = append(n.refs, reference{typeID: refType, forward: false, target: source})
+	}
+}
+
+// isSubtype сообщает, что тип t совпадает с base или является его подтипом. Вызывается под s.mu.
+func (s *Server) isSubtype(t, base ua.NodeID) bool {
+	for depth := 0; depth < 32; depth++ {
+		if t == base {
+			return true
+		}
+		n, ok := s.nodes[t]
+		if !ok {
+			return false
+		}
+		var parent ua.NodeID
+		for _, ref := range n.refs {
+			if !ref.forward && ref.typeID == ns0(ua.IDHasSubtype) {
+				parent = ref.target
+				break
+			}
+		}
+		if parent.IsNull() {
+			return false
+		}
+		t = parent
+	}
+	return false
+}
+
+// addStandardNodes создает минимальную часть стандартного адресного пространства:
+// корневые папки, иерархию используемых типов и объект Server.
+func (s *Server) addStandardNodes() {
+	folder := func(id uint32, name string, parent uint32) {
+		s.addNode(&node{id: ns0(id), class: ua.NodeClassObject, browseName: ua.QualifiedName{Name: name}},
+			ns0(parent), ua.IDOrganizes, ns0(ua.IDFolderType))
+	}
+	s.addNode(&node{id: ns0(ua.IDRoot), class: ua.NodeClassObject, browseName: ua.QualifiedName{Name: "Root"}},
+		ua.NodeID{}, 0, ua.NodeID{})
+
+	// Типы ссылок
+	refType := func(id uint32, name, inverse string, parent uint32, abstract, symmetric bool) {
+		n := &node{id: ns0(id), class: ua.NodeClassReferenceType, browseName: ua.QualifiedName{Name: name},
+			isAbstract: abstract, symmetric: symmetric}
+		if inverse != "" {
+			n.inverseName = ua.NewText(inverse)
+		}
+		s.addNode(n, ns0(parent), ua.IDHasSubtype, ua.NodeID{})
+	}
+	s.addNode(&node{id: ns0(ua.IDReferences), class: ua.NodeClassReferenceType, browseName: ua.QualifiedName{Name: "References"},
+		isAbstract: true, symmetric: true}, ua.NodeID{}, 0, ua.NodeID{})
+	refType(ua.IDHierarchicalReferences, "HierarchicalReferences", "", ua.IDReferences, true, false)
+	refType(ua.IDNonHierarchicalReferences, "NonHierarchicalReferences", "", ua.IDReferences, true, false)
+	refType(ua.IDHasChild, "HasChild", "", ua.IDHierarchicalReferences, true, false)
+	refType(ua.IDOrganizes, "Organizes", "OrganizedBy", ua.IDHierarchicalReferences, false, false)
+	refType(ua.IDHasEventSource, "HasEventSource", "EventSourceOf", ua.IDHierarchicalReferences, false, false)
+	refType(ua.IDHasNotifier, "HasNotifier", "NotifierOf", ua.IDHasEventSource, false, false)
+	refType(ua.IDAggregates, "Aggregates", "", ua.IDHasChild, true, false)
+	refType(ua.IDHasSubtype, "HasSubtype", "HasSupertype", ua.IDHasChild, false, false)
+	refType(ua.IDHasComponent, "HasComponent", "ComponentOf", ua.IDAggregates, false, false)
+	refType(ua.IDHasProperty, "HasProperty", "PropertyOf", ua.IDAggregates, false, false)
+	refType(ua.IDHasTypeDefinition, "HasTypeDefinition", "TypeDefinitionOf", ua.IDNonHierarchicalReferences, false, false)
+	refType(ua.IDGeneratesEvent, "GeneratesEvent", "GeneratedBy", ua.IDNonHierarchicalReferences, false, false)
+
+	// Типы объектов и событий
+	objectType := func(id uint32, name string, parent uint32, abstract bool) {
+		s.addNode(&node{id: ns0(id), class: ua.NodeClassObjectType, browseName: ua.QualifiedName{Name: name}, isAbstract: abstract},
+			ns0(parent), ua.IDHasSubtype, ua.NodeID{})
+	}
+	s.addNode(&node{id: ns0(ua.IDBaseObjectType), class: ua.NodeClassObjectType, browseName: ua.QualifiedName{Name: "BaseObjectType"}},
+		ua.NodeID{}, 0, ua.NodeID{})
+	objectType(ua.IDFolderType, "FolderType", ua.IDBaseObjectType, false)
+	objectType(ua.IDServerType, "ServerType", ua.IDBaseObjectType, false)
+	objectType(ua.IDBaseEventType, "BaseEventType", ua.IDBaseObjectType, true)
+	objectType(ua.IDConditionType, "ConditionType", ua.IDBaseEventType, true)
+	objectType(ua.IDAcknowledgeableCondition, "AcknowledgeableConditionType", ua.IDConditionType, false)
+	objectType(ua.IDAlarmConditionType, "AlarmConditionType", ua.IDAcknowledgeableCondition, false)
+
+	// Типы переменных
+	variableType := func(id uint32, name string, parent uint32, dataType uint32, abstract bool) {
+		s.addNode(&node{id: ns0(id), class: ua.NodeClassVariableType, browseName: ua.QualifiedName{Name: name},
+			isAbstract: abstract, dataType: ns0(dataType), valueRank: ua.ValueRankScalar}, ns0(parent), ua.IDHasSubtype, ua.NodeID{})
+	}
+	s.addNode(&node{id: ns0(ua.IDBaseVariableType), class: ua.NodeClassVariableType, browseName: ua.QualifiedName{Name: "BaseVariableType"},
+		isAbstract: true, dataType: ns0(ua.IDBaseDataType), valueRank: ua.ValueRankScalar}, ua.NodeID{}, 0, ua.NodeID{})
+	variableType(ua.IDBaseDataVariableType, "BaseDataVariableType", ua.IDBaseVariableType, ua.IDBaseDataType, false)
+	variableType(ua.IDPropertyType, "PropertyType", ua.IDBaseVariableType, ua.IDBaseDataType, false)
+	variableType(ua.IDServerStatusVariableType, "ServerStatusType", ua.IDBaseDataVariableType, ua.IDServerStatusType, false)
+	variableType(ua.IDBuildInfoVariableType, "BuildInfoType", ua.IDBaseDataVariableType, ua.IDBuildInfoType, false)
+
+	// Типы данных
+	dataType := func(id uint32, name string, parent uint32, abstract bool) {
+		s.addNode(&node{id: ns0(id), class: ua.NodeClassDataType, browseName: ua.QualifiedName{Name: name}, isAbstract: abstract},
+			ns0(parent), ua.IDHasSubtype, ua.NodeID{})
+	}
+	s.addNode(&node{id: ns0(ua.IDBaseDataType), class: ua.NodeClassDataType, browseName: ua.QualifiedName{Name: "BaseDataType"}, isAbstract: true},
+		ua.NodeID{}, 0, ua.NodeID{})
+	dataType(ua.IDBoolean, "Boolean", ua.IDBaseDataType, false)
+	dataType(ua.IDNumber, "Number", ua.IDBaseDataType, true)
+	dataType(ua.IDInteger, "Integer", ua.IDNumber, true)
+	dataType(ua.IDUInteger, "UInteger", ua.IDNumber, true)
+	dataType(ua.IDSByte, "SByte", ua.IDInteger, false)
+	dataType(ua.IDInt16, "Int16", ua.IDInteger, false)
+	dataType(ua.IDInt32, "Int32", ua.IDInteger, false)
+	dataType(ua.IDInt64, "Int64", ua.IDInteger, false)
+	dataType(ua.IDByte, "Byte", ua.IDUInteger, false)
+	dataType(ua.IDUInt16, "UInt16", ua.IDUInteger, false)
+	dataType(ua.IDUInt32, "UInt32", ua.IDUInteger, false)
+	dataType(ua.IDUInt64, "UInt64", ua.IDUInteger, false)
+	dataType(ua.IDFloat, "Float", ua.IDNumber, false)
+	dataType(ua.IDDouble, "Double", ua.IDNumber, false)
+	dataType(ua.IDDuration, "Duration", ua.IDDouble, false)
+	dataType(ua.IDString, "String", ua.IDBaseDataType, false)
+	dataType(ua.IDLocaleID, "LocaleId", ua.IDString, false)
+	dataType(ua.IDDateTime, "DateTime", ua.IDBaseDataType, false)
+	dataType(ua.IDUtcTime, "UtcTime", ua.IDDateTime, false)
+	dataType(ua.IDByteString, "ByteString", ua.IDBaseDataType, false)
+	dataType(ua.IDNodeIDType, "NodeId", ua.IDBaseDataType, false)
+	dataType(ua.IDStatusCodeType, "StatusCode", ua.IDBaseDataType, false)
+	dataType(ua.IDQualifiedNameType, "QualifiedName", ua.IDBaseDataType, false)
+	dataType(ua.IDLocalizedTextType, "LocalizedText", ua.IDBaseDataType, false)
+	dataType(ua.IDStructure, "Structure", ua.IDBaseDataType, true)
+	dataType(ua.IDServerStatusType, "ServerStatusDataType", ua.IDStructure, false)
+	dataType(ua.IDBuildInfoType, "BuildInfo", ua.IDStructure, false)
+	dataType(ua.IDEnumeration, "Enumeration", ua.IDBaseDataType, true)
+	dataType(ua.IDServerStateType, "ServerState", ua.IDEnumeration, false)
+
+	// Папки
+	folder(ua.IDObjectsFolder, "Objects", ua.IDRoot)
+	folder(ua.IDTypesFolder, "Types", ua.IDRoot)
+	folder(ua.IDViewsFolder, "Views", ua.IDRoot)
+	folder(ua.IDObjectTypesFolder, "ObjectTypes", ua.IDTypesFolder)
+	folder(ua.IDVariableTypesFolder, "VariableTypes", ua.IDTypesFolder)
+	folder(ua.IDDataTypesFolder, "DataTypes", ua.IDTypesFolder)
+	folder(ua.IDReferenceTypes, "ReferenceTypes", ua.IDTypesFolder)
+	s.addReference(ns0(ua.IDObjectTypesFolder), ns0(ua.IDOrganizes), ns0(ua.IDBaseObjectType))
+	s.addReference(ns0(ua.IDVariableTypesFolder), ns0(ua.IDOrganizes), ns0(ua.IDBaseVariableType))
+	s.addReference(ns0(ua.IDDataTypesFolder), ns0(ua.IDOrganizes), ns0(ua.IDBaseDataType))
+	s.addReference(ns0(ua.IDReferenceTypes), ns0(ua.IDOrganizes), ns0(ua.IDReferences))
+
+	// Объект Server
+	s.addNode(&node{id: ns0(ua.IDServer), class: ua.NodeClassObject, browseName: ua.QualifiedName{Name: "Server"},
+		eventNotifier: ua.EventNotifierSubscribe}, ns0(ua.IDObjectsFolder), ua.IDOrganizes, ns0(ua.IDServerType))
+	property := func(id uint32, name string, dataType uint32, rank int32, value any) {
+		s.addNode(&node{id: ns0(id), class: ua.NodeClassVariable, browseName: ua.QualifiedName{Name: name},
+			dataType: ns0(dataType), valueRank: rank, value: ua.DataValue{Value: ua.NewVariant(value), ServerTimestamp: s.start}},
+			ns0(ua.IDServer), ua.IDHasProperty, ns0(ua.IDPropertyType))
+	}
+	property(ua.IDServerNamespaceArray, "NamespaceArray", ua.IDString, ua.ValueRankArray,
+		[]string{"http://opcfoundation.org/UA/", NamespaceURI})
+	property(ua.IDServerServerArray, "ServerArray", ua.IDString, ua.ValueRankArray, []string{s.opts.ApplicationURI})
+
+	dynamic := func(id uint32, name string, parent, refType, dataType, typeDef uint32, read func() any) {
+		s.addNode(&node{id: ns0(id), class: ua.NodeClassVariable, browseName: ua.QualifiedName{Name: name},
+			dataType: ns0(dataType), valueRank: ua.ValueRankScalar, read: func() ua.DataValue {
+				now := time.Now()
+				return ua.DataValue{Value: ua.NewVariant(read()), SourceTimestamp: now, ServerTimestamp: now}
+			}}, ns0(parent), refType, ns0(typeDef))
+	}
+	dynamic(ua.IDServerServiceLevel, "ServiceLevel", ua.IDServer, ua.IDHasProperty, ua.IDByte, ua.IDPropertyType,
+		func() any { return uint8(255) })
+	dynamic(ua.IDServerStatus, "ServerStatus", ua.IDServer, ua.IDHasComponent, ua.IDServerStatusType, ua.IDServerStatusVariableType,
+		func() any { return ua.NewExtensionObject(s.serverStatus()) })
+	dynamic(ua.IDServerStatusStartTime, "StartTime", ua.IDServerStatus, ua.IDHasComponent, ua.IDUtcTime, ua.IDBaseDataVariableType,
+		func() any { return s.start })
+	dynamic(ua.IDServerStatusCurrentTime, "CurrentTime", ua.IDServerStatus, ua.IDHasComponent, ua.IDUtcTime, ua.IDBaseDataVariableType,
+		func() any { return time.Now() })
+	dynamic(ua.IDServerStatusState, "State", ua.IDServerStatus, ua.IDHasComponent, ua.IDServerStateType, ua.IDBaseDataVariableType,
+		func() any { return int32(ua.ServerStateRunning) })
+	dynamic(ua.IDServerStatusBuildInfo, "BuildInfo", ua.IDServerStatus, ua.IDHasComponent, ua.IDBuildInfoType, ua.IDBuildInfoVariableType,
+		func() any { return ua.NewExtensionObject(s.buildInfo()) })
+}
+
+func (s *Server) buildInfo() ua.BuildInfo {
+	return ua.BuildInfo{
+		ProductURI:       productURI,
+		ManufacturerName: "iwtcode",
+		ProductName:      s.opts.ApplicationName,
+		BuildDate:        s.start,
+	}
+}
+
+func (s *Server) serverStatus() ua.ServerStatusDataType {
+	return ua.ServerStatusDataType{
+		StartTime:   s.start,
+		CurrentTime: time.Now(),
+		State:       ua.ServerStateRunning,
+		BuildInfo:   s.buildInfo(),
+	}
+}
@@ -0,0 +1,226 @@
+// Package opcuatest содержит минимальный клиент OPC UA (политика None, анонимный вход)
+// для проверки встроенного сервера в тестах и при отладке.
+package opcuatest
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/iwtcode/fanucAdapter/opcua/ua"
+)
+
+// Client — сеанс OPC UA поверх одного безопасного канала. Методы безопасны
+// для вызова из нескольких горутин.
+type Client struct {
+	ch      *ua.Channel
+	timeout time.Duration
+
+	mu            sync.Mutex
+	token         ua.NodeID
+	lastRequestID uint32
+	pending       map[uint32]chan response
+	err           error
+	done          chan struct{}
+}
+
+type response struct {
+	msg any
+	err error
+}
+
+// Dial подключается к серверу по адресу host:port, открывает безопасный канал
+// и активирует анонимный сеанс. timeout ограничивает ожидание каждого ответа.
+func Dial(addr string, timeout time.Duration) (*Client, error) {
+	nc, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	url := "opc.tcp://" + addr
+	_ = nc.SetDeadline(time.Now().Add(timeout))
+	limits, err := ua.SendHello(nc, url, ua.DefaultLimits)
+	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+	_ = nc.SetDeadline(time.Time{})
+
+	c := &Client{
+		ch:      ua.NewChannel(nc, limits),
+		timeout: timeout,
+		pending: make(map[uint32]chan response),
+		done:    make(chan struct{}),
+	}
+	go c.readLoop()
+
+	resp, err := c.send(ua.MessageOpenChannel, ua.OpenSecureChannelRequest{
+		RequestType:       ua.SecurityTokenIssue,
+		SecurityMode:      ua.SecurityModeNone,
+		RequestedLifetime: 3_600_000,
+	})
+	if err != nil {
+		c.ch.Close()
+		return nil, fmt.Errorf("open secure channel: %w", err)
+	}
+	token := resp.(ua.OpenSecureChannelResponse).SecurityToken
+	c.ch.SetToken(token.ChannelID, token.TokenID)
+
+	resp, err = c.Send(ua.CreateSessionRequest{
+		ClientDescription:       ua.ApplicationDescription{ApplicationURI: "urn:iwtcode:fanucAdapter:opcuatest", ApplicationType: ua.ApplicationClient},
+		EndpointURL:             url,
+		SessionName:             "opcuatest",
+		RequestedSessionTimeout: 60_000,
+	})
+	if err != nil {
+		c.ch.Close()
+		return nil, fmt.Errorf("create session: %w", err)
+	}
+	c.mu.Lock()
+	c.token = resp.(ua.CreateSessionResponse).AuthenticationToken
+	c.mu.Unlock()
+
+	if _, err := c.Send(ua.ActivateSessionRequest{
+		UserIdentityToken: ua.NewExtensionObject(ua.AnonymousIdentityToken{PolicyID: "anonymous"}),
+	}); err != nil {
+		c.ch.Close()
+		return nil, fmt.Errorf("activate session: %w", err)
+	}
+	return c, nil
+}
+
+// Send отправляет запрос службы req (например, ua.ReadRequest) с заголовком сеанса
+// и возвращает ответ. ServiceFault и ответы с кодом Bad возвращаются ошибкой ua.StatusCode.
+func (c *Client) Send(req any) (any, error) {
+	return c.send(ua.MessageService, req)
+}
+
+func (c *Client) send(typ string, req any) (any, error) {
+	c.mu.Lock()
+	if c.err != nil {
+		err := c.err
+		c.mu.Unlock()
+		return nil, err
+	}
+	c.lastRequestID++
+	id := c.lastRequestID
+	wait := make(chan response, 1)
+	c.pending[id] = wait
+	h := ua.RequestHeader{
+		AuthenticationToken: c.token,
+		Timestamp:           time.Now(),
+		RequestHandle:       id,
+		TimeoutHint:         uint32(c.timeout / time.Millisecond),
+	}
+	c.mu.Unlock()
+
+	body, err := ua.EncodeMessage(ua.WithRequestHeader(req, h))
+	if err == nil {
+		err = c.ch.WriteMessage(typ, id, body)
+	}
+	if err != nil {
+		c.forget(id)
+		return nil, err
+	}
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+	select {
+	case resp := <-wait:
+		if resp.err != nil {
+			return nil, resp.err
+		}
+		if h, ok := ua.ResponseHeaderOf(resp.msg); ok && h.ServiceResult.IsBad() {
+			return resp.msg, h.ServiceResult
+		}
+		return resp.msg, nil
+	case <-timer.C:
+		c.forget(id)
+		return nil, fmt.Errorf("%T: %w", req, ua.BadTimeout)
+	}
+}
+
+func (c *Client) forget(id uint32) {
+	c.mu.Lock()
+	delete(c.pending, id)
+	c.mu.Unlock()
+}
+
+func (c *Client) readLoop() {
+	defer close(c.done)
+	for {
+		msg, err := c.ch.ReadMessage()
+		if err != nil {
+			c.fail(err)
+			return
+		}
+		resp, err := ua.DecodeMessage(msg.Body)
+		c.mu.Lock()
+		wait, ok := c.pending[msg.RequestID]
+		delete(c.pending, msg.RequestID)
+		c.mu.Unlock()
+		if ok {
+			wait <- response{msg: resp, err: err}
+		}
+	}
+}
+
+// fail завершает ожидающие запросы ошибкой соединения.
+func (c *Client) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+	for id, wait := range c.pending {
+		wait <- response{err: err}
+		delete(c.pending, id)
+	}
+}
+
+// Read читает атрибут Value узлов ids.
+func (c *Client) Read(ids ...ua.NodeID) ([]ua.DataValue, error) {
+	nodes := make([]ua.ReadValueID, len(ids))
+	for i, id := range ids {
+		nodes[i] = ua.ReadValueID{NodeID: id, AttributeID: ua.AttributeValue}
+	}
+	resp, err := c.Send(ua.ReadRequest{TimestampsToReturn: ua.TimestampsBoth, NodesToRead: nodes})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(ua.ReadResponse).Results, nil
+}
+
+// Browse возвращает иерархические ссылки узла id в прямом направлении.
+func (c *Client) Browse(id ua.NodeID) ([]ua.ReferenceDescription, error) {
+	resp, err := c.Send(ua.BrowseRequest{NodesToBrowse: []ua.BrowseDescription{{
+		NodeID:          id,
+		BrowseDirection: ua.BrowseForward,
+		ReferenceTypeID: ua.NewNumericNodeID(0, ua.IDHierarchicalReferences),
+		IncludeSubtypes: true,
+		ResultMask:      ua.BrowseResultAll,
+	}}})
+	if err != nil {
+		return nil, err
+	}
+	result := resp.(ua.BrowseResponse).Results[0]
+	if result.StatusCode.IsBad() {
+		return nil, result.StatusCode
+	}
+	return result.References, nil
+}
+
+// Close закрывает сеанс и безопасный канал.
+func (c *Client) Close() error {
+	_, err := c.Send(ua.CloseSessionRequest{DeleteSubscriptions: true})
+	if body, encErr := ua.EncodeMessage(ua.CloseSecureChannelRequest{}); encErr == nil {
+		_ = c.ch.WriteMessage(ua.MessageCloseChannel, 0, body)
+	}
+	c.ch.Close()
+	<-c.done
+	if errors.Is(err, ua.BadSessionIDInvalid) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,470 @@
+// Package opcua реализует встроенный сервер OPC UA с моделью данных станков FANUC.
+//
+// Для каждого станка (AddMachine) в папке Objects создается объект с системной
+// информацией в свойствах и переменными, которые обновляются по данным опроса (Update, Run):
+//
+//	<станок>/Axes/<ось>/Position, Load, ServoTemperature, CoderTemperature, PowerConsumption
+//	<станок>/Spindles/<номер>/Speed, Load, Override, PowerConsumption
+//	<станок>/State/<поле UnifiedMachineData>
+//	<станок>/Program/Name, Number, GCodeLine
+//	<станок>/Counters/PartsCount, PowerOnTime, OperatingTime, CycleTime, CuttingTime
+//
+// Идентификаторы узлов станков — строки в пространстве имен NamespaceURI (ns=1),
+// совпадающие с путем узла: "ns=1;s=<станок>/Axes/X/Position". Качество разделов данных
+// передается кодом состояния значения: Stale — UncertainLastUsableValue, CommError —
+// BadNoCommunication, NotSupported — BadNotSupported.
+//
+// Появление и сброс тревог станка публикуются событиями AlarmConditionType, источник
+// которых — объект станка; подписаться на них можно у объекта станка или объекта Server.
+//
+// Сервер поддерживает двоичный протокол UA TCP с политикой безопасности None
+// и анонимным доступом. Адресное пространство доступно только для чтения: запись
+// отклоняется с BadNotWritable.
+package opcua
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/iwtcode/fanucAdapter/opcua/ua"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultAddr — адрес, который слушает сервер по умолчанию (стандартный порт OPC UA).
+const DefaultAddr = ":4840"
+
+// NamespaceURI — пространство имен узлов станков (индекс 1).
+const NamespaceURI = "urn:iwtcode:fanucAdapter"
+
+// Options задает параметры сервера.
+type Options struct {
+	// EndpointURL — адрес точки подключения, который сервер сообщает клиентам.
+	// По умолчанию "opc.tcp://<имя хоста>:<порт>" по адресу Start.
+	EndpointURL string
+	// ApplicationURI — URI приложения сервера. По умолчанию "urn:iwtcode:fanucAdapter:server".
+	ApplicationURI string
+	// ApplicationName — имя сервера. По умолчанию "fanucAdapter".
+	ApplicationName string
+	// MaxSessions — максимальное число одновременных сеансов. По умолчанию 100.
+	MaxSessions int
+	// MinPublishingInterval — минимальный интервал публикации подписок. По умолчанию 100 мс.
+	MinPublishingInterval time.Duration
+	// Logger — журнал ошибок соединений. По умолчанию журнал не ведется.
+	Logger *logrus.Logger
+}
+
+// Server — сервер OPC UA для одного или нескольких станков.
+type Server struct {
+	opts  Options
+	start time.Time
+
+	mu            sync.Mutex
+	nodes         map[ua.NodeID]*node
+	machines      map[string]*machine
+	sessions      map[ua.NodeID]*session // По токену аутентификации
+	conns         map[*conn]struct{}
+	lastID        uint32 // Последний выданный ID канала, сеанса, подписки или элемента
+	endpointURL   string
+	ln            net.Listener
+	done          chan struct{}
+	closed        bool
+	housekeepDone chan struct{}
+}
+
+// NewServer создает сервер со стандартными узлами адресного пространства.
+// Станки добавляются методом AddMachine, прием подключений запускается методом Start.
+func NewServer(opts Options) *Server {
+	if opts.ApplicationURI == "" {
+		opts.ApplicationURI = "urn:iwtcode:fanucAdapter:server"
+	}
+	if opts.ApplicationName == "" {
+		opts.ApplicationName = "fanucAdapter"
+	}
+	if opts.MaxSessions <= 0 {
+		opts.MaxSessions = 100
+	}
+	if opts.MinPublishingInterval <= 0 {
+		opts.MinPublishingInterval = 100 * time.Millisecond
+	}
+	if opts.Logger == nil {
+		opts.Logger = logrus.New()
+		opts.Logger.SetOutput(io.Discard)
+	}
+	s := &Server{
+		opts:     opts,
+		start:    time.Now(),
+		nodes:    make(map[ua.NodeID]*node),
+		machines: make(map[string]*machine),
+		sessions: make(map[ua.NodeID]*session),
+		conns:    make(map[*conn]struct{}),
+	}
+	s.addStandardNodes()
+	return s
+}
+
+// Start начинает прием подключений на addr (например, DefaultAddr) в фоне.
+func (s *Server) Start(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen %s: %w", addr, err)
+	}
+
+	s.mu.Lock()
+	if s.ln != nil || s.closed {
+		s.mu.Unlock()
+		ln.Close()
+		return errors.New("opcua server already started")
+	}
+	s.ln = ln
+	s.done = make(chan struct{})
+	s.housekeepDone = make(chan struct{})
+	s.endpointURL = s.opts.EndpointURL
+	if s.endpointURL == "" {
+		s.endpointURL = defaultEndpointURL(ln.Addr())
+	}
+	s.mu.Unlock()
+
+	go s.serve(ln)
+	go s.housekeep()
+	return nil
+}
+
+// defaultEndpointURL строит адрес точки подключения по имени хоста и порту.
+func defaultEndpointURL(addr net.Addr) string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "localhost"
+	}
+	port := "4840"
+	if tcp, ok := addr.(*net.TCPAddr); ok {
+		port = strconv.Itoa(tcp.Port)
+	}
+	return "opc.tcp://" + net.JoinHostPort(host, port)
+}
+
+// Close закрывает сеансы и подписки, останавливает прием подключений и разрывает
+// все соединения.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	ln, done, housekeepDone := s.ln, s.done, s.housekeepDone
+	for _, sess := range s.sessions {
+		s.closeSession(sess, ua.BadShutdown)
+	}
+	for c := range s.conns {
+		c.close()
+	}
+	s.mu.Unlock()
+
+	if ln == nil {
+		return nil
+	}
+	err := ln.Close()
+	<-done
+	<-housekeepDone
+	return err
+}
+
+// Addr возвращает адрес, на котором сервер принимает подключения.
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ln == nil {
+		return nil
+	}
+	return s.ln.Addr()
+}
+
+// EndpointURL возвращает адрес точки подключения, который сервер сообщает клиентам.
+func (s *Server) EndpointURL() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.endpointURL
+}
+
+// nextID возвращает новый идентификатор. Вызывается под s.mu.
+func (s *Server) nextID() uint32 {
+	s.lastID++
+	return s.lastID
+}
+
+func (s *Server) serve(ln net.Listener) {
+	defer close(s.done)
+	for {
+		nc, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.serveConn(nc)
+	}
+}
+
+// housekeep закрывает сеансы без активности дольше их таймаута и отвечает на запросы
+// Publish, время ожидания которых истекло.
+func (s *Server) housekeep() {
+	defer close(s.housekeepDone)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for _, sess := range s.sessions {
+				if now.Sub(sess.lastSeen) > sess.timeout {
+					s.opts.Logger.Infof("OPC UA: session %q timed out", sess.name)
+					s.closeSession(sess, ua.BadTimeout)
+					continue
+				}
+				sess.expirePublishRequests(now)
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// serveConn выполняет обмен Hello/Acknowledge и обрабатывает сообщения соединения
+// до его закрытия.
+func (s *Server) serveConn(nc net.Conn) {
+	_ = nc.SetReadDeadline(time.Now().Add(10 * time.Second))
+	limits, _, err := ua.AcceptHello(nc, ua.DefaultLimits)
+	if err != nil {
+		s.opts.Logger.Debugf("OPC UA: hello from %s failed: %v", nc.RemoteAddr(), err)
+		nc.Close()
+		return
+	}
+	_ = nc.SetReadDeadline(time.Time{})
+
+	c := newConn(ua.NewChannel(nc, limits), s.opts.Logger)
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		nc.Close()
+		return
+	}
+	s.conns[c] = struct{}{}
+	s.mu.Unlock()
+
+	go c.writeLoop()
+	err = s.readLoop(c)
+	var transportErr *ua.TransportError
+	if errors.As(err, &transportErr) {
+		_ = c.ch.WriteError(transportErr.Status, transportErr.Reason)
+	}
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+		s.opts.Logger.Debugf("OPC UA: connection %s closed: %v", nc.RemoteAddr(), err)
+	}
+
+	s.mu.Lock()
+	delete(s.conns, c)
+	for _, sess := range s.sessions {
+		sess.detach(c)
+	}
+	s.mu.Unlock()
+	c.close()
+}
+
+// readLoop обрабатывает сообщения безопасного канала до ошибки или CloseSecureChannel.
+func (s *Server) readLoop(c *conn) error {
+	for {
+		msg, err := c.ch.ReadMessage()
+		if err != nil {
+			return err
+		}
+		if msg.Type != ua.MessageOpenChannel && msg.ChannelID != c.channelID {
+			return &ua.TransportError{Status: ua.BadTCPSecureChannelUnknown, Reason: "unknown secure channel"}
+		}
+
+		req, err := ua.DecodeMessage(msg.Body)
+		var unknown *ua.UnknownMessageError
+		switch {
+		case errors.As(err, &unknown):
+			c.send(ua.MessageService, msg.RequestID, ua.ServiceFault{ResponseHeader: responseHeader(ua.RequestHeader{}, ua.BadServiceUnsupported)})
+			continue
+		case err != nil:
+			return &ua.TransportError{Status: ua.BadDecodingError, Reason: err.Error()}
+		}
+
+		switch msg.Type {
+		case ua.MessageOpenChannel:
+			if err := s.openChannel(c, msg.RequestID, req); err != nil {
+				return err
+			}
+		case ua.MessageCloseChannel:
+			return nil
+		default:
+			s.handle(c, msg.RequestID, req)
+		}
+	}
+}
+
+// openChannel открывает безопасный канал или продлевает его токен.
+func (s *Server) openChannel(c *conn, requestID uint32, msg any) error {
+	req, ok := msg.(ua.OpenSecureChannelRequest)
+	if !ok {
+		return &ua.TransportError{Status: ua.BadTCPMessageTypeInvalid, Reason: fmt.Sprintf("unexpected %T in OPN", msg)}
+	}
+	if req.SecurityMode != ua.SecurityModeNone {
+		c.send(ua.MessageOpenChannel, requestID, ua.ServiceFault{ResponseHeader: responseHeader(req.RequestHeader, ua.BadSecurityModeRejected)})
+		return nil
+	}
+	if req.RequestType == ua.SecurityTokenRenew && c.channelID == 0 {
+		return &ua.TransportError{Status: ua.BadSecureChannelIDInvalid, Reason: "renew before issue"}
+	}
+
+	if c.channelID == 0 {
+		s.mu.Lock()
+		c.channelID = s.nextID()
+		s.mu.Unlock()
+	}
+	c.tokenID++
+
+	// Время жизни токена не проверяется: канал с политикой None не шифруется
+	lifetime := req.RequestedLifetime
+	if lifetime == 0 || lifetime > 3_600_000 {
+		lifetime = 3_600_000
+	}
+	c.ch.SetToken(c.channelID, c.tokenID)
+	c.send(ua.MessageOpenChannel, requestID, ua.OpenSecureChannelResponse{
+		ResponseHeader: responseHeader(req.RequestHeader, ua.Good),
+		SecurityToken: ua.ChannelSecurityToken{
+			ChannelID:       c.channelID,
+			TokenID:         c.tokenID,
+			CreatedAt:       time.Now(),
+			RevisedLifetime: lifetime,
+		},
+	})
+	return nil
+}
+
+// responseHeader возвращает заголовок ответа на запрос с заголовком h.
+func responseHeader(h ua.RequestHeader, status ua.StatusCode) ua.ResponseHeader {
+	return ua.ResponseHeader{
+		Timestamp:     time.Now(),
+		RequestHandle: h.RequestHandle,
+		ServiceResult: status,
+	}
+}
+
+// fault возвращает ответ ServiceFault с кодом status.
+func fault(h ua.RequestHeader, status ua.StatusCode) ua.ServiceFault {
+	return ua.ServiceFault{ResponseHeader: responseHeader(h, status)}
+}
+
+// endpoints возвращает описание единственной точки подключения: политика None,
+// анонимный доступ. Вызывается под s.mu.
+func (s *Server) endpoints(url string) []ua.EndpointDescription {
+	if url == "" {
+		url = s.endpointURL
+	}
+	return []ua.EndpointDescription{{
+		EndpointURL:       url,
+		Server:            s.application(),
+		SecurityMode:      ua.SecurityModeNone,
+		SecurityPolicyURI: ua.SecurityPolicyNone,
+		UserIdentityTokens: []ua.UserTokenPolicy{{
+			PolicyID:          anonymousPolicy,
+			TokenType:         ua.UserTokenAnonymous,
+			SecurityPolicyURI: ua.SecurityPolicyNone,
+		}},
+		TransportProfileURI: ua.TransportProfileTCP,
+	}}
+}
+
+// application возвращает описание приложения сервера. Вызывается под s.mu.
+func (s *Server) application() ua.ApplicationDescription {
+	return ua.ApplicationDescription{
+		ApplicationURI:  s.opts.ApplicationURI,
+		ProductURI:      productURI,
+		ApplicationName: ua.NewText(s.opts.ApplicationName),
+		ApplicationType: ua.ApplicationServer,
+		DiscoveryURLs:   []string{s.endpointURL},
+	}
+}
+
+// randomBytes возвращает n случайных байт для нонсов, токенов и точек продолжения.
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return b
+}
+
+// conn — подключение клиента. Запись выполняется отдельной горутиной, чтобы медленный
+// клиент не задерживал публикацию подписок; при переполнении очереди соединение
+// разрывается, и клиент восстанавливает сеанс заново.
+type conn struct {
+	ch     *ua.Channel
+	logger *logrus.Logger
+	out    chan outMessage
+	once   sync.Once
+	done   chan struct{}
+
+	// Используются только горутиной чтения
+	channelID uint32
+	tokenID   uint32
+}
+
+type outMessage struct {
+	typ       string
+	requestID uint32
+	body      []byte
+}
+
+func newConn(ch *ua.Channel, logger *logrus.Logger) *conn {
+	return &conn{ch: ch, logger: logger, out: make(chan outMessage, 256), done: make(chan struct{})}
+}
+
+// send кодирует и ставит в очередь ответ msg на запрос requestID.
+func (c *conn) send(typ string, requestID uint32, msg any) {
+	body, err := ua.EncodeMessage(msg)
+	if err != nil {
+		c.logger.Errorf("OPC UA: encode %T: %v", msg, err)
+		h, _ := ua.ResponseHeaderOf(msg)
+		if body, err = ua.EncodeMessage(ua.ServiceFault{ResponseHeader: ua.ResponseHeader{
+			Timestamp:     time.Now(),
+			RequestHandle: h.RequestHandle,
+			ServiceResult: ua.BadEncodingLimitsExceeded,
+		}}); err != nil {
+			return
+		}
+	}
+	select {
+	case c.out <- outMessage{typ: typ, requestID: requestID, body: body}:
+	case <-c.done:
+	default:
+		c.close()
+	}
+}
+
+func (c *conn) writeLoop() {
+	for {
+		select {
+		case msg := <-c.out:
+			if err := c.ch.WriteMessage(msg.typ, msg.requestID, msg.body); err != nil {
+				c.close()
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *conn) close() {
+	c.once.Do(func() {
+		close(c.done)
+		c.ch.Close()
+	})
+}
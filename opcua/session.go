@@ -0,0 +1,521 @@
+package opcua
+
+import (
+	"encoding/hex"
+	"slices"
+	"time"
+
+	"github.com/iwtcode/fanucAdapter/opcua/ua"
+)
+
+const (
+	minSessionTimeout     = 10 * time.Second
+	maxSessionTimeout     = time.Hour
+	defaultSessionTimeout = time.Minute
+	maxContinuationPoints = 10
+	maxPublishRequests    = 10
+	maxSubscriptions      = 100
+)
+
+// session — сеанс клиента. Все поля защищены Server.mu.
+type session struct {
+	id        ua.NodeID
+	token     ua.NodeID
+	name      string
+	timeout   time.Duration
+	lastSeen  time.Time
+	activated bool
+	conn      *conn // Канал, к которому привязан сеанс; nil после разрыва соединения
+
+	continuations map[string]continuation // Точки продолжения Browse
+	subscriptions map[uint32]*subscription
+	publish       []publishRequest // Ожидающие запросы Publish в порядке поступления
+}
+
+// continuation — оставшиеся ссылки Browse и размер страницы.
+type continuation struct {
+	refs []ua.ReferenceDescription
+	max  uint32
+}
+
+// publishRequest — запрос Publish, ожидающий уведомлений.
+type publishRequest struct {
+	conn      *conn
+	requestID uint32
+	header    ua.RequestHeader
+	results   []ua.StatusCode // Результаты подтверждений
+	deadline  time.Time       // Нулевое — без ограничения
+}
+
+// detach отвязывает сеанс от закрытого соединения c. Сеанс сохраняется до истечения
+// таймаута, и клиент может активировать его в новом канале.
+func (sess *session) detach(c *conn) {
+	if sess.conn == c {
+		sess.conn = nil
+	}
+	sess.publish = slices.DeleteFunc(sess.publish, func(req publishRequest) bool { return req.conn == c })
+}
+
+// expirePublishRequests отвечает BadTimeout на запросы Publish с истекшим временем ожидания.
+func (sess *session) expirePublishRequests(now time.Time) {
+	sess.publish = slices.DeleteFunc(sess.publish, func(req publishRequest) bool {
+		if req.deadline.IsZero() || now.Before(req.deadline) {
+			return false
+		}
+		req.conn.send(ua.MessageService, req.requestID, fault(req.header, ua.BadTimeout))
+		return true
+	})
+}
+
+// closeSession удаляет сеанс и его подписки и отвечает status на ожидающие
+// запросы Publish. Вызывается под s.mu.
+func (s *Server) closeSession(sess *session, status ua.StatusCode) {
+	delete(s.sessions, sess.token)
+	for _, sub := range sess.subscriptions {
+		sub.stop()
+	}
+	sess.subscriptions = nil
+	for _, req := range sess.publish {
+		req.conn.send(ua.MessageService, req.requestID, fault(req.header, status))
+	}
+	sess.publish = nil
+}
+
+// handle обрабатывает запрос службы req, полученный по соединению c.
+func (s *Server) handle(c *conn, requestID uint32, req any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var resp any
+	switch req := req.(type) {
+	case ua.GetEndpointsRequest:
+		resp = ua.GetEndpointsResponse{ResponseHeader: responseHeader(req.RequestHeader, ua.Good), Endpoints: s.endpoints(req.EndpointURL)}
+	case ua.FindServersRequest:
+		resp = ua.FindServersResponse{ResponseHeader: responseHeader(req.RequestHeader, ua.Good), Servers: []ua.ApplicationDescription{s.application()}}
+	case ua.CreateSessionRequest:
+		resp = s.createSession(c, req)
+	case ua.ActivateSessionRequest:
+		resp = s.activateSession(c, req)
+	case ua.CloseSessionRequest:
+		sess, status := s.session(c, req.RequestHeader, false)
+		if status != ua.Good {
+			resp = fault(req.RequestHeader, status)
+			break
+		}
+		s.closeSession(sess, ua.BadSessionClosed)
+		resp = ua.CloseSessionResponse{ResponseHeader: responseHeader(req.RequestHeader, ua.Good)}
+	default:
+		h, _ := ua.RequestHeaderOf(req)
+		sess, status := s.session(c, h, true)
+		if status != ua.Good {
+			resp = fault(h, status)
+			break
+		}
+		resp = s.handleSession(c, sess, requestID, req)
+	}
+	if resp != nil {
+		c.send(ua.MessageService, requestID, resp)
+	}
+}
+
+// handleSession обрабатывает запрос службы, требующей активного сеанса. Пустой ответ
+// означает, что ответ будет отправлен позже (Publish).
+func (s *Server) handleSession(c *conn, sess *session, requestID uint32, req any) any {
+	switch req := req.(type) {
+	case ua.BrowseRequest:
+		return s.browse(sess, req)
+	case ua.BrowseNextRequest:
+		return s.browseNext(sess, req)
+	case ua.TranslateBrowsePathsToNodeIDsRequest:
+		return s.translateBrowsePaths(req)
+	case ua.ReadRequest:
+		return s.read(req)
+	case ua.WriteRequest:
+		return s.write(req)
+	case ua.RegisterNodesRequest:
+		if len(req.NodesToRegister) == 0 {
+			return fault(req.RequestHeader, ua.BadNothingToDo)
+		}
+		return ua.RegisterNodesResponse{ResponseHeader: responseHeader(req.RequestHeader, ua.Good), RegisteredNodeIDs: req.NodesToRegister}
+	case ua.UnregisterNodesRequest:
+		if len(req.NodesToUnregister) == 0 {
+			return fault(req.RequestHeader, ua.BadNothingToDo)
+		}
+		return ua.UnregisterNodesResponse{ResponseHeader: responseHeader(req.RequestHeader, ua.Good)}
+	case ua.CreateSubscriptionRequest:
+		return s.createSubscription(sess, req)
+	case ua.ModifySubscriptionRequest:
+		return s.modifySubscription(sess, req)
+	case ua.SetPublishingModeRequest:
+		return s.setPublishingMode(sess, req)
+	case ua.DeleteSubscriptionsRequest:
+		return s.deleteSubscriptions(sess, req)
+	case ua.CreateMonitoredItemsRequest:
+		return s.createMonitoredItems(sess, req)
+	case ua.ModifyMonitoredItemsRequest:
+		return s.modifyMonitoredItems(sess, req)
+	case ua.SetMonitoringModeRequest:
+		return s.setMonitoringMode(sess, req)
+	case ua.DeleteMonitoredItemsRequest:
+		return s.deleteMonitoredItems(sess, req)
+	case ua.PublishRequest:
+		return s.publish(c, sess, requestID, req)
+	case ua.RepublishRequest:
+		return s.republish(sess, req)
+	}
+	h, _ := ua.RequestHeaderOf(req)
+	return fault(h, ua.BadServiceUnsupported)
+}
+
+// session возвращает сеанс по токену аутентификации запроса и отмечает его активность.
+// Вызывается под s.mu.
+func (s *Server) session(c *conn, h ua.RequestHeader, activated bool) (*session, ua.StatusCode) {
+	sess, ok := s.sessions[h.AuthenticationToken]
+	if !ok {
+		return nil, ua.BadSessionIDInvalid
+	}
+	if activated && !sess.activated {
+		return nil, ua.BadSessionNotActivated
+	}
+	if sess.activated && sess.conn != c {
+		return nil, ua.BadSecureChannelIDInvalid
+	}
+	sess.lastSeen = time.Now()
+	return sess, ua.Good
+}
+
+func (s *Server) createSession(c *conn, req ua.CreateSessionRequest) any {
+	if len(s.sessions) >= s.opts.MaxSessions {
+		return fault(req.RequestHeader, ua.BadTooManySessions)
+	}
+	timeout := time.Duration(req.RequestedSessionTimeout * float64(time.Millisecond))
+	switch {
+	case timeout <= 0:
+		timeout = defaultSessionTimeout
+	case timeout < minSessionTimeout:
+		timeout = minSessionTimeout
+	case timeout > maxSessionTimeout:
+		timeout = maxSessionTimeout
+	}
+
+	sess := &session{
+		id:            ua.NewNumericNodeID(1, s.nextID()),
+		token:         ua.NodeID{Namespace: 1, Type: ua.NodeIDOpaque, Name: string(randomBytes(32))},
+		name:          req.SessionName,
+		timeout:       timeout,
+		lastSeen:      time.Now(),
+		conn:          c,
+		continuations: make(map[string]continuation),
+		subscriptions: make(map[uint32]*subscription),
+	}
+	s.sessions[sess.token] = sess
+	return ua.CreateSessionResponse{
+		ResponseHeader:        responseHeader(req.RequestHeader, ua.Good),
+		SessionID:             sess.id,
+		AuthenticationToken:   sess.token,
+		RevisedSessionTimeout: float64(timeout / time.Millisecond),
+		ServerNonce:           randomBytes(32),
+		ServerEndpoints:       s.endpoints(req.EndpointURL),
+	}
+}
+
+// activateSession активирует сеанс и привязывает его к каналу c. Принимается только
+// анонимный вход.
+func (s *Server) activateSession(c *conn, req ua.ActivateSessionRequest) any {
+	sess, ok := s.sessions[req.RequestHeader.AuthenticationToken]
+	if !ok {
+		return fault(req.RequestHeader, ua.BadSessionIDInvalid)
+	}
+	switch token := req.UserIdentityToken.Value.(type) {
+	case nil:
+		if req.UserIdentityToken.Body != nil {
+			return fault(req.RequestHeader, ua.BadIdentityTokenInvalid)
+		}
+	case ua.AnonymousIdentityToken:
+		if token.PolicyID != "" && token.PolicyID != anonymousPolicy {
+			return fault(req.RequestHeader, ua.BadIdentityTokenInvalid)
+		}
+	default:
+		return fault(req.RequestHeader, ua.BadIdentityTokenRejected)
+	}
+
+	sess.activated = true
+	sess.lastSeen = time.Now()
+	if sess.conn != c {
+		// Запросы Publish старого канала остаются без ответа: клиент их уже не ждет
+		if sess.conn != nil {
+			sess.detach(sess.conn)
+		}
+		sess.conn = c
+	}
+	return ua.ActivateSessionResponse{ResponseHeader: responseHeader(req.RequestHeader, ua.Good), ServerNonce: randomBytes(32)}
+}
+
+func (s *Server) browse(sess *session, req ua.BrowseRequest) any {
+	if len(req.NodesToBrowse) == 0 {
+		return fault(req.RequestHeader, ua.BadNothingToDo)
+	}
+	results := make([]ua.BrowseResult, len(req.NodesToBrowse))
+	for i, desc := range req.NodesToBrowse {
+		refs, status := s.references(desc)
+		if status != ua.Good {
+			results[i] = ua.BrowseResult{StatusCode: status}
+			continue
+		}
+		results[i] = s.browseResult(sess, refs, req.RequestedMaxReferencesPerNode)
+	}
+	return ua.BrowseResponse{ResponseHeader: responseHeader(req.RequestHeader, ua.Good), Results: results}
+}
+
+func (s *Server) browseNext(sess *session, req ua.BrowseNextRequest) any {
+	if len(req.ContinuationPoints) == 0 {
+		return fault(req.RequestHeader, ua.BadNothingToDo)
+	}
+	results := make([]ua.BrowseResult, len(req.ContinuationPoints))
+	for i, cp := range req.ContinuationPoints {
+		key := hex.EncodeToString(cp)
+		cont, ok := sess.continuations[key]
+		if !ok {
+			results[i] = ua.BrowseResult{StatusCode: ua.BadContinuationPointInvalid}
+			continue
+		}
+		delete(sess.continuations, key)
+		if !req.ReleaseContinuationPoints {
+			results[i] = s.browseResult(sess, cont.refs, cont.max)
+		}
+	}
+	return ua.BrowseNextResponse{ResponseHeader: responseHeader(req.RequestHeader, ua.Good), Results: results}
+}
+
+// browseResult возвращает первые max ссылок (0 — все) и сохраняет остальные
+// в точке продолжения.
+func (s *Server) browseResult(sess *session, refs []ua.ReferenceDescription, max uint32) ua.BrowseResult {
+	if max == 0 || int(max) >= len(refs) {
+		return ua.BrowseResult{References: refs}
+	}
+	if len(sess.continuations) >= maxContinuationPoints {
+		return ua.BrowseResult{StatusCode: ua.BadNoContinuationPoints}
+	}
+	cp := randomBytes(16)
+	sess.continuations[hex.EncodeToString(cp)] = continuation{refs: refs[max:], max: max}
+	return ua.BrowseResult{ContinuationPoint: cp, References: refs[:max]}
+}
+
+// references возвращает ссылки узла, отобранные по описанию desc. Вызывается под s.mu.
+func (s *Server) references(desc ua.BrowseDescription) ([]ua.ReferenceDescription, ua.StatusCode) {
+	n, ok := s.nodes[desc.NodeID]
+	if !ok {
+		return nil, ua.BadNodeIDUnknown
+	}
+	if desc.BrowseDirection < ua.BrowseForward || desc.BrowseDirection > ua.BrowseBoth {
+		return nil, ua.BadBrowseDirectionInvalid
+	}
+	if !desc.ReferenceTypeID.IsNull() {
+		if t, ok := s.nodes[desc.ReferenceTypeID]; !ok || t.class != ua.NodeClassReferenceType {
+			return nil, ua.BadReferenceTypeIDInvalid
+		}
+	}
+
+	var refs []ua.ReferenceDescription
+	for _, ref := range n.refs {
+		if desc.BrowseDirection == ua.BrowseForward && !ref.forward || desc.BrowseDirection == ua.BrowseInverse && ref.forward {
+			continue
+		}
+		if !desc.ReferenceTypeID.IsNull() && ref.typeID != desc.ReferenceTypeID &&
+			!(desc.IncludeSubtypes && s.isSubtype(ref.typeID, desc.ReferenceTypeID)) {
+			continue
+		}
+		target, ok := s.nodes[ref.target]
+		if !ok || desc.NodeClassMask != 0 && uint32(target.class)&desc.NodeClassMask == 0 {
+			continue
+		}
+
+		var rd ua.ReferenceDescription
+		rd.NodeID = ua.ExpandedNodeID{NodeID: target.id}
+		if desc.ResultMask&ua.BrowseResultReferenceType != 0 {
+			rd.ReferenceTypeID = ref.typeID
+		}
+		if desc.ResultMask&ua.BrowseResultIsForward != 0 {
+			rd.IsForward = ref.forward
+		}
+		if desc.ResultMask&ua.BrowseResultNodeClass != 0 {
+			rd.NodeClass = target.class
+		}
+		if desc.ResultMask&ua.BrowseResultBrowseName != 0 {
+			rd.BrowseName = target.browseName
+		}
+		if desc.ResultMask&ua.BrowseResultDisplayName != 0 {
+			rd.DisplayName = target.displayName
+		}
+		if desc.ResultMask&ua.BrowseResultTypeDefinition != 0 &&
+			(target.class == ua.NodeClassObject || target.class == ua.NodeClassVariable) {
+			rd.TypeDefinition = ua.ExpandedNodeID{NodeID: target.typeDefinition()}
+		}
+		refs = append(refs, rd)
+	}
+	return refs, ua.Good
+}
+
+func (s *Server) translateBrowsePaths(req ua.TranslateBrowsePathsToNodeIDsRequest) any {
+	if len(req.BrowsePaths) == 0 {
+		return fault(req.RequestHeader, ua.BadNothingToDo)
+	}
+	results := make([]ua.BrowsePathResult, len(req.BrowsePaths))
+	for i, path := range req.BrowsePaths {
+		results[i] = s.translateBrowsePath(path)
+	}
+	return ua.TranslateBrowsePathsToNodeIDsResponse{ResponseHeader: responseHeader(req.RequestHeader, ua.Good), Results: results}
+}
+
+// translateBrowsePath находит узлы по относительному пути от начального узла.
+// Вызывается под s.mu.
+func (s *Server) translateBrowsePath(path ua.BrowsePath) ua.BrowsePathResult {
+	if _, ok := s.nodes[path.StartingNode]; !ok {
+		return ua.BrowsePathResult{StatusCode: ua.BadNodeIDUnknown}
+	}
+	if len(path.RelativePath.Elements) == 0 {
+		return ua.BrowsePathResult{StatusCode: ua.BadNothingToDo}
+	}
+
+	current := []ua.NodeID{path.StartingNode}
+	for _, elem := range path.RelativePath.Elements {
+		if elem.TargetName.Name == "" {
+			return ua.BrowsePathResult{StatusCode: ua.BadBrowseNameInvalid}
+		}
+		var next []ua.NodeID
+		for _, id := range current {
+			for _, ref := range s.nodes[id].refs {
+				if ref.forward == elem.IsInverse {
+					continue
+				}
+				if !elem.ReferenceTypeID.IsNull() && ref.typeID != elem.ReferenceTypeID &&
+					!(elem.IncludeSubtypes && s.isSubtype(ref.typeID, elem.ReferenceTypeID)) {
+					continue
+				}
+				target, ok := s.nodes[ref.target]
+				if ok && target.browseName == elem.TargetName && !slices.Contains(next, target.id) {
+					next = append(next, target.id)
+				}
+			}
+		}
+		if len(next) == 0 {
+			return ua.BrowsePathResult{StatusCode: ua.BadNoMatch}
+		}
+		current = next
+	}
+
+	targets := make([]ua.BrowsePathTarget, len(current))
+	for i, id := range current {
+		targets[i] = ua.BrowsePathTarget{TargetID: ua.ExpandedNodeID{NodeID: id}, RemainingPathIndex: 0xFFFFFFFF}
+	}
+	return ua.BrowsePathResult{Targets: targets}
+}
+
+func (s *Server) read(req ua.ReadRequest) any {
+	if req.TimestampsToReturn < ua.TimestampsSource || req.TimestampsToReturn > ua.TimestampsNeither {
+		return fault(req.RequestHeader, ua.BadTimestampsToReturnInvalid)
+	}
+	if len(req.NodesToRead) == 0 {
+		return fault(req.RequestHeader, ua.BadNothingToDo)
+	}
+	results := make([]ua.DataValue, len(req.NodesToRead))
+	for i, id := range req.NodesToRead {
+		results[i] = s.readValue(id, req.TimestampsToReturn)
+	}
+	return ua.ReadResponse{ResponseHeader: responseHeader(req.RequestHeader, ua.Good), Results: results}
+}
+
+// readValue читает атрибут узла. Вызывается под s.mu.
+func (s *Server) readValue(id ua.ReadValueID, timestamps ua.TimestampsToReturn) ua.DataValue {
+	n, ok := s.nodes[id.NodeID]
+	switch {
+	case !ok:
+		return ua.DataValue{Status: ua.BadNodeIDUnknown}
+	case id.IndexRange != "":
+		return ua.DataValue{Status: ua.BadIndexRangeInvalid}
+	case id.DataEncoding.Name != "":
+		return ua.DataValue{Status: ua.BadDataEncodingInvalid}
+	}
+	if id.AttributeID != ua.AttributeValue {
+		return s.readAttribute(n, id.AttributeID)
+	}
+	if n.class != ua.NodeClassVariable && n.class != ua.NodeClassVariableType {
+		return ua.DataValue{Status: ua.BadAttributeIDInvalid}
+	}
+
+	value := n.currentValue()
+	switch timestamps {
+	case ua.TimestampsSource:
+		value.ServerTimestamp = time.Time{}
+	case ua.TimestampsServer:
+		value.SourceTimestamp = time.Time{}
+	case ua.TimestampsNeither:
+		value.SourceTimestamp, value.ServerTimestamp = time.Time{}, time.Time{}
+	}
+	return value
+}
+
+// readAttribute читает атрибут узла, кроме Value.
+func (s *Server) readAttribute(n *node, attr uint32) ua.DataValue {
+	isType := n.class == ua.NodeClassObjectType || n.class == ua.NodeClassVariableType ||
+		n.class == ua.NodeClassReferenceType || n.class == ua.NodeClassDataType
+	isVariable := n.class == ua.NodeClassVariable || n.class == ua.NodeClassVariableType
+
+	var value any
+	switch {
+	case attr == ua.AttributeNodeID:
+		value = n.id
+	case attr == ua.AttributeNodeClass:
+		value = int32(n.class)
+	case attr == ua.AttributeBrowseName:
+		value = n.browseName
+	case attr == ua.AttributeDisplayName:
+		value = n.displayName
+	case attr == ua.AttributeDescription:
+		value = ua.LocalizedText{}
+	case attr == ua.AttributeWriteMask, attr == ua.AttributeUserWriteMask:
+		value = uint32(0)
+	case attr == ua.AttributeIsAbstract && isType:
+		value = n.isAbstract
+	case attr == ua.AttributeSymmetric && n.class == ua.NodeClassReferenceType:
+		value = n.symmetric
+	case attr == ua.AttributeInverseName && n.class == ua.NodeClassReferenceType:
+		value = n.inverseName
+	case attr == ua.AttributeEventNotifier && n.class == ua.NodeClassObject:
+		value = n.eventNotifier
+	case attr == ua.AttributeDataType && isVariable:
+		value = n.dataType
+	case attr == ua.AttributeValueRank && isVariable:
+		value = n.valueRank
+	case attr == ua.AttributeArrayDimensions && isVariable:
+		if n.valueRank == ua.ValueRankArray {
+			value = []uint32{0}
+		} else {
+			value = []uint32(nil)
+		}
+	case (attr == ua.AttributeAccessLevel || attr == ua.AttributeUserAccessLevel) && n.class == ua.NodeClassVariable:
+		value = ua.AccessLevelCurrentRead
+	case attr == ua.AttributeMinimumSamplingInterval && n.class == ua.NodeClassVariable:
+		value = float64(s.opts.MinPublishingInterval / time.Millisecond)
+	case attr == ua.AttributeHistorizing && n.class == ua.NodeClassVariable:
+		value = false
+	default:
+		return ua.DataValue{Status: ua.BadAttributeIDInvalid}
+	}
+	return ua.DataValue{Value: ua.NewVariant(value)}
+}
+
+func (s *Server) write(req ua.WriteRequest) any {
+	if len(req.NodesToWrite) == 0 {
+		return fault(req.RequestHeader, ua.BadNothingToDo)
+	}
+	results := make([]ua.StatusCode, len(req.NodesToWrite))
+	for i, w := range req.NodesToWrite {
+		if _, ok := s.nodes[w.NodeID]; !ok {
+			results[i] = ua.BadNodeIDUnknown
+			continue
+		}
+		results[i] = ua.BadNotWritable
+	}
+	return ua.WriteResponse{ResponseHeader: responseHeader(req.RequestHeader, ua.Good), Results: results}
+}
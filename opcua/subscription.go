@@ -0,0 +1,652 @@
+package opcua
+
+import (
+	"math"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/iwtcode/fanucAdapter/opcua/ua"
+)
+
+const (
+	defaultKeepAliveCount = 10
+	maxRetransmitQueue    = 100
+	maxEventQueue         = 1000
+)
+
+// subscription — подписка сеанса. Каждая подписка опрашивает свои элементы
+// в отдельной горутине с интервалом публикации. Поля защищены Server.mu.
+type subscription struct {
+	id               uint32
+	sess             *session
+	interval         time.Duration
+	lifetimeCount    uint32
+	keepAliveCount   uint32
+	maxNotifications uint32
+	enabled          bool
+	items            map[uint32]*monitoredItem
+
+	seq              uint32 // Последний выданный номер сообщения
+	keepAliveCounter uint32
+	lifetimeCounter  uint32
+	late             bool // Есть что отправить, но нет запроса Publish
+	sentFirst        bool
+	retransmit       []ua.NotificationMessage
+
+	ticker *time.Ticker
+	once   sync.Once
+	done   chan struct{}
+}
+
+// monitoredItem — элемент подписки: изменение атрибута узла или события объекта.
+type monitoredItem struct {
+	id           uint32
+	clientHandle uint32
+	nodeID       ua.NodeID
+	attributeID  uint32
+	mode         ua.MonitoringMode
+	timestamps   ua.TimestampsToReturn
+
+	// Элементы данных: последнее значение и значение для отправки (очередь из одного)
+	trigger  ua.DataChangeTrigger
+	deadband float64 // Абсолютная зона нечувствительности; 0 — нет
+	last     *ua.DataValue
+	pending  *ua.DataValue
+
+	// Элементы событий: поля для отправки и очередь событий
+	event   bool
+	selects []ua.SimpleAttributeOperand
+	events  [][]ua.Variant
+}
+
+func (sub *subscription) stop() {
+	sub.once.Do(func() {
+		sub.ticker.Stop()
+		close(sub.done)
+	})
+}
+
+// stopped сообщает, что подписка удалена.
+func (sub *subscription) stopped() bool {
+	select {
+	case <-sub.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// run выполняет циклы публикации подписки до ее удаления.
+func (s *Server) run(sub *subscription) {
+	for {
+		select {
+		case <-sub.done:
+			return
+		case <-sub.ticker.C:
+			s.mu.Lock()
+			if !sub.stopped() {
+				s.tick(sub)
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// tick выполняет цикл публикации: опрашивает элементы данных и отправляет уведомления
+// или сообщение keep-alive, если есть ожидающий запрос Publish. Вызывается под s.mu.
+func (s *Server) tick(sub *subscription) {
+	for _, item := range sub.items {
+		if !item.event {
+			s.sample(item)
+		}
+	}
+
+	switch {
+	case sub.enabled && sub.hasNotifications():
+		if s.sendNotifications(sub) {
+			return
+		}
+		sub.late = true
+	default:
+		sub.keepAliveCounter++
+		if sub.sentFirst && sub.keepAliveCounter < sub.keepAliveCount {
+			return
+		}
+		if s.sendKeepAlive(sub) {
+			return
+		}
+		sub.late = true
+	}
+
+	sub.lifetimeCounter++
+	if sub.lifetimeCounter >= sub.lifetimeCount {
+		s.opts.Logger.Infof("OPC UA: subscription %d of session %q expired", sub.id, sub.sess.name)
+		s.deleteSubscription(sub)
+	}
+}
+
+// hasNotifications сообщает, есть ли у подписки неотправленные уведомления.
+func (sub *subscription) hasNotifications() bool {
+	for _, item := range sub.items {
+		if item.pending != nil || len(item.events) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// takePublishRequest извлекает самый старый ожидающий запрос Publish сеанса.
+func (sess *session) takePublishRequest() (publishRequest, bool) {
+	if len(sess.publish) == 0 {
+		return publishRequest{}, false
+	}
+	req := sess.publish[0]
+	sess.publish = slices.Delete(sess.publish, 0, 1)
+	return req, true
+}
+
+// sendNotifications отправляет уведомления подписки в ответ на ожидающий запрос Publish.
+// Уведомления сверх MaxNotificationsPerPublish остаются для следующего запроса.
+func (s *Server) sendNotifications(sub *subscription) bool {
+	req, ok := sub.sess.takePublishRequest()
+	if !ok {
+		return false
+	}
+
+	limit := int(sub.maxNotifications)
+	if limit == 0 {
+		limit = math.MaxInt
+	}
+	var changes []ua.MonitoredItemNotification
+	var events []ua.EventFieldList
+	for _, item := range sub.sortedItems() {
+		if item.pending != nil && len(changes)+len(events) < limit {
+			changes = append(changes, ua.MonitoredItemNotification{ClientHandle: item.clientHandle, Value: *item.pending})
+			item.pending = nil
+		}
+		for len(item.events) > 0 && len(changes)+len(events) < limit {
+			events = append(events, ua.EventFieldList{ClientHandle: item.clientHandle, EventFields: item.events[0]})
+			item.events = slices.Delete(item.events, 0, 1)
+		}
+	}
+
+	sub.seq++
+	msg := ua.NotificationMessage{SequenceNumber: sub.seq, PublishTime: time.Now()}
+	if len(changes) > 0 {
+		msg.NotificationData = append(msg.NotificationData, ua.NewExtensionObject(ua.DataChangeNotification{MonitoredItems: changes}))
+	}
+	if len(events) > 0 {
+		msg.NotificationData = append(msg.NotificationData, ua.NewExtensionObject(ua.EventNotificationList{Events: events}))
+	}
+	sub.retransmit = append(sub.retransmit, msg)
+	if len(sub.retransmit) > maxRetransmitQueue {
+		sub.retransmit = slices.Delete(sub.retransmit, 0, len(sub.retransmit)-maxRetransmitQueue)
+	}
+
+	more := sub.hasNotifications()
+	s.sendPublishResponse(sub, req, msg, more)
+	sub.late = more
+	return true
+}
+
+// sendKeepAlive отправляет сообщение keep-alive без уведомлений. Номер сообщения
+// не расходуется: он совпадает с номером следующего сообщения с уведомлениями.
+func (s *Server) sendKeepAlive(sub *subscription) bool {
+	req, ok := sub.sess.takePublishRequest()
+	if !ok {
+		return false
+	}
+	s.sendPublishResponse(sub, req, ua.NotificationMessage{SequenceNumber: sub.seq + 1, PublishTime: time.Now()}, false)
+	sub.late = false
+	return true
+}
+
+func (s *Server) sendPublishResponse(sub *subscription, req publishRequest, msg ua.NotificationMessage, more bool) {
+	available := make([]uint32, len(sub.retransmit))
+	for i, m := range sub.retransmit {
+		available[i] = m.SequenceNumber
+	}
+	req.conn.send(ua.MessageService, req.requestID, ua.PublishResponse{
+		ResponseHeader:           responseHeader(req.header, ua.Good),
+		SubscriptionID:           sub.id,
+		AvailableSequenceNumbers: available,
+		MoreNotifications:        more,
+		NotificationMessage:      msg,
+		Results:                  req.results,
+	})
+	sub.sentFirst = true
+	sub.keepAliveCounter = 0
+	sub.lifetimeCounter = 0
+}
+
+// sortedItems возвращает элементы подписки в порядке создания.
+func (sub *subscription) sortedItems() []*monitoredItem {
+	items := make([]*monitoredItem, 0, len(sub.items))
+	for _, item := range sub.items {
+		items = append(items, item)
+	}
+	slices.SortFunc(items, func(a, b *monitoredItem) int { return int(a.id) - int(b.id) })
+	return items
+}
+
+// sample читает значение элемента данных и ставит его в очередь, если оно изменилось
+// с учетом условия и зоны нечувствительности фильтра. Вызывается под s.mu.
+func (s *Server) sample(item *monitoredItem) {
+	if item.mode == ua.MonitoringDisabled {
+		return
+	}
+	value := s.readValue(ua.ReadValueID{NodeID: item.nodeID, AttributeID: item.attributeID}, item.timestamps)
+	if item.last != nil && !changed(*item.last, value, item.trigger, item.deadband) {
+		return
+	}
+	item.last = &value
+	if item.mode == ua.MonitoringReporting {
+		item.pending = &value
+	}
+}
+
+// changed сообщает, что значение изменилось по условию trigger фильтра DataChangeFilter.
+func changed(old, value ua.DataValue, trigger ua.DataChangeTrigger, deadband float64) bool {
+	if old.Status != value.Status {
+		return true
+	}
+	if trigger == ua.TriggerStatus {
+		return false
+	}
+	if trigger == ua.TriggerStatusValueTimestamp && !old.SourceTimestamp.Equal(value.SourceTimestamp) {
+		return true
+	}
+	if a, ok := number(old.Value.Value); ok && deadband > 0 {
+		if b, ok := number(value.Value.Value); ok {
+			return math.Abs(a-b) > deadband
+		}
+	}
+	return !reflect.DeepEqual(old.Value, value.Value)
+}
+
+// number возвращает числовое значение скаляра для зоны нечувствительности.
+func number(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// emitEvent ставит событие станка m в очередь элементов событий, подписанных на объект
+// станка или объект Server. Вызывается под s.mu.
+func (s *Server) emitEvent(m *machine, e *alarmEvent) {
+	for _, sess := range s.sessions {
+		for _, sub := range sess.subscriptions {
+			for _, item := range sub.items {
+				if !item.event || item.mode != ua.MonitoringReporting ||
+					item.nodeID != ns0(ua.IDServer) && item.nodeID != m.object.id {
+					continue
+				}
+				item.events = append(item.events, e.fields(item.selects))
+				if len(item.events) > maxEventQueue {
+					item.events = slices.Delete(item.events, 0, 1)
+				}
+			}
+		}
+	}
+}
+
+func (s *Server) createSubscription(sess *session, req ua.CreateSubscriptionRequest) any {
+	if len(sess.subscriptions) >= maxSubscriptions {
+		return fault(req.RequestHeader, ua.BadTooManySubscriptions)
+	}
+	sub := &subscription{
+		id:      s.nextID(),
+		sess:    sess,
+		enabled: req.PublishingEnabled,
+		items:   make(map[uint32]*monitoredItem),
+		done:    make(chan struct{}),
+	}
+	s.reviseSubscription(sub, req.RequestedPublishingInterval, req.RequestedLifetimeCount, req.RequestedMaxKeepAliveCount)
+	sub.maxNotifications = req.MaxNotificationsPerPublish
+	sub.ticker = time.NewTicker(sub.interval)
+	sess.subscriptions[sub.id] = sub
+	go s.run(sub)
+
+	return ua.CreateSubscriptionResponse{
+		ResponseHeader:            responseHeader(req.RequestHeader, ua.Good),
+		SubscriptionID:            sub.id,
+		RevisedPublishingInterval: float64(sub.interval) / float64(time.Millisecond),
+		RevisedLifetimeCount:      sub.lifetimeCount,
+		RevisedMaxKeepAliveCount:  sub.keepAliveCount,
+	}
+}
+
+// reviseSubscription устанавливает параметры подписки с учетом ограничений сервера.
+func (s *Server) reviseSubscription(sub *subscription, interval float64, lifetime, keepAlive uint32) {
+	sub.interval = time.Duration(interval * float64(time.Millisecond))
+	if sub.interval < s.opts.MinPublishingInterval {
+		sub.interval = s.opts.MinPublishingInterval
+	}
+	sub.keepAliveCount = keepAlive
+	if sub.keepAliveCount == 0 {
+		sub.keepAliveCount = defaultKeepAliveCount
+	}
+	sub.lifetimeCount = max(lifetime, 3*sub.keepAliveCount)
+}
+
+func (s *Server) modifySubscription(sess *session, req ua.ModifySubscriptionRequest) any {
+	sub, ok := sess.subscriptions[req.SubscriptionID]
+	if !ok {
+		return fault(req.RequestHeader, ua.BadSubscriptionIDInvalid)
+	}
+	s.reviseSubscription(sub, req.RequestedPublishingInterval, req.RequestedLifetimeCount, req.RequestedMaxKeepAliveCount)
+	sub.maxNotifications = req.MaxNotificationsPerPublish
+	sub.ticker.Reset(sub.interval)
+	return ua.ModifySubscriptionResponse{
+		ResponseHeader:            responseHeader(req.RequestHeader, ua.Good),
+		RevisedPublishingInterval: float64(sub.interval) / float64(time.Millisecond),
+		RevisedLifetimeCount:      sub.lifetimeCount,
+		RevisedMaxKeepAliveCount:  sub.keepAliveCount,
+	}
+}
+
+func (s *Server) setPublishingMode(sess *session, req ua.SetPublishingModeRequest) any {
+	if len(req.SubscriptionIDs) == 0 {
+		return fault(req.RequestHeader, ua.BadNothingToDo)
+	}
+	results := make([]ua.StatusCode, len(req.SubscriptionIDs))
+	for i, id := range req.SubscriptionIDs {
+		sub, ok := sess.subscriptions[id]
+		if !ok {
+			results[i] = ua.BadSubscriptionIDInvalid
+			continue
+		}
+		sub.enabled = req.PublishingEnabled
+	}
+	return ua.SetPublishingModeResponse{ResponseHeader: responseHeader(req.RequestHeader, ua.Good), Results: results}
+}
+
+func (s *Server) deleteSubscriptions(sess *session, req ua.DeleteSubscriptionsRequest) any {
+	if len(req.SubscriptionIDs) == 0 {
+		return fault(req.RequestHeader, ua.BadNothingToDo)
+	}
+	results := make([]ua.StatusCode, len(req.SubscriptionIDs))
+	for i, id := range req.SubscriptionIDs {
+		sub, ok := sess.subscriptions[id]
+		if !ok {
+			results[i] = ua.BadSubscriptionIDInvalid
+			continue
+		}
+		s.deleteSubscription(sub)
+	}
+	return ua.DeleteSubscriptionsResponse{ResponseHeader: responseHeader(req.RequestHeader, ua.Good), Results: results}
+}
+
+// deleteSubscription останавливает и удаляет подписку. Если у сеанса не осталось
+// подписок, ожидающие запросы Publish получают BadNoSubscription. Вызывается под s.mu.
+func (s *Server) deleteSubscription(sub *subscription) {
+	sub.stop()
+	sess := sub.sess
+	delete(sess.subscriptions, sub.id)
+	if len(sess.subscriptions) > 0 {
+		return
+	}
+	for _, req := range sess.publish {
+		req.conn.send(ua.MessageService, req.requestID, fault(req.header, ua.BadNoSubscription))
+	}
+	sess.publish = nil
+}
+
+func (s *Server) createMonitoredItems(sess *session, req ua.CreateMonitoredItemsRequest) any {
+	sub, ok := sess.subscriptions[req.SubscriptionID]
+	if !ok {
+		return fault(req.RequestHeader, ua.BadSubscriptionIDInvalid)
+	}
+	if req.TimestampsToReturn < ua.TimestampsSource || req.TimestampsToReturn > ua.TimestampsNeither {
+		return fault(req.RequestHeader, ua.BadTimestampsToReturnInvalid)
+	}
+	if len(req.ItemsToCreate) == 0 {
+		return fault(req.RequestHeader, ua.BadNothingToDo)
+	}
+
+	results := make([]ua.MonitoredItemCreateResult, len(req.ItemsToCreate))
+	for i, create := range req.ItemsToCreate {
+		item := &monitoredItem{
+			nodeID:      create.ItemToMonitor.NodeID,
+			attributeID: create.ItemToMonitor.AttributeID,
+			mode:        create.MonitoringMode,
+			timestamps:  req.TimestampsToReturn,
+		}
+		if status := s.readValue(create.ItemToMonitor, req.TimestampsToReturn).Status; status.IsBad() && !isValueStatus(status) {
+			results[i] = ua.MonitoredItemCreateResult{StatusCode: status}
+			continue
+		}
+		filterResult, status := s.setFilter(item, create.RequestedParameters.Filter)
+		if status != ua.Good {
+			results[i] = ua.MonitoredItemCreateResult{StatusCode: status}
+			continue
+		}
+		item.id = s.nextID()
+		item.clientHandle = create.RequestedParameters.ClientHandle
+		sub.items[item.id] = item
+		results[i] = ua.MonitoredItemCreateResult{
+			MonitoredItemID:         item.id,
+			RevisedSamplingInterval: float64(sub.interval) / float64(time.Millisecond),
+			RevisedQueueSize:        item.queueSize(),
+			FilterResult:            filterResult,
+		}
+	}
+	return ua.CreateMonitoredItemsResponse{ResponseHeader: responseHeader(req.RequestHeader, ua.Good), Results: results}
+}
+
+// isValueStatus сообщает, что код состояния относится к значению переменной, а не
+// к ошибке в описании элемента: на такие переменные можно подписаться.
+func isValueStatus(status ua.StatusCode) bool {
+	switch status {
+	case ua.BadWaitingForInitialData, ua.BadNoCommunication, ua.BadNotSupported, ua.BadDeviceFailure, ua.BadNoData:
+		return true
+	}
+	return false
+}
+
+// setFilter проверяет фильтр элемента и применяет его. Для атрибута EventNotifier
+// требуется EventFilter, для остальных атрибутов допускается DataChangeFilter
+// с абсолютной зоной нечувствительности.
+func (s *Server) setFilter(item *monitoredItem, filter ua.ExtensionObject) (ua.ExtensionObject, ua.StatusCode) {
+	if item.attributeID == ua.AttributeEventNotifier {
+		n := s.nodes[item.nodeID]
+		if n.class != ua.NodeClassObject || n.eventNotifier&ua.EventNotifierSubscribe == 0 {
+			return ua.ExtensionObject{}, ua.BadNotSupported
+		}
+		f, ok := filter.Value.(ua.EventFilter)
+		if !ok || len(f.SelectClauses) == 0 {
+			return ua.ExtensionObject{}, ua.BadMonitoredItemFilterInvalid
+		}
+		// Условие WhereClause не проверяется: сервер публикует только события тревог
+		item.event = true
+		item.selects = f.SelectClauses
+		return ua.NewExtensionObject(ua.EventFilterResult{SelectClauseResults: make([]ua.StatusCode, len(f.SelectClauses))}), ua.Good
+	}
+
+	item.trigger, item.deadband = ua.TriggerStatusValue, 0
+	switch f := filter.Value.(type) {
+	case nil:
+		if filter.Body != nil {
+			return ua.ExtensionObject{}, ua.BadMonitoredItemFilterUnsupported
+		}
+	case ua.DataChangeFilter:
+		switch f.DeadbandType {
+		case 0:
+		case 1: // Absolute
+			if f.DeadbandValue < 0 {
+				return ua.ExtensionObject{}, ua.BadMonitoredItemFilterInvalid
+			}
+			item.deadband = f.DeadbandValue
+		default:
+			return ua.ExtensionObject{}, ua.BadMonitoredItemFilterUnsupported
+		}
+		item.trigger = f.Trigger
+	case ua.EventFilter:
+		return ua.ExtensionObject{}, ua.BadFilterNotAllowed
+	default:
+		return ua.ExtensionObject{}, ua.BadMonitoredItemFilterUnsupported
+	}
+	return ua.ExtensionObject{}, ua.Good
+}
+
+func (item *monitoredItem) queueSize() uint32 {
+	if item.event {
+		return maxEventQueue
+	}
+	return 1
+}
+
+func (s *Server) modifyMonitoredItems(sess *session, req ua.ModifyMonitoredItemsRequest) any {
+	sub, ok := sess.subscriptions[req.SubscriptionID]
+	if !ok {
+		return fault(req.RequestHeader, ua.BadSubscriptionIDInvalid)
+	}
+	if req.TimestampsToReturn < ua.TimestampsSource || req.TimestampsToReturn > ua.TimestampsNeither {
+		return fault(req.RequestHeader, ua.BadTimestampsToReturnInvalid)
+	}
+	if len(req.ItemsToModify) == 0 {
+		return fault(req.RequestHeader, ua.BadNothingToDo)
+	}
+
+	results := make([]ua.MonitoredItemModifyResult, len(req.ItemsToModify))
+	for i, modify := range req.ItemsToModify {
+		item, ok := sub.items[modify.MonitoredItemID]
+		if !ok {
+			results[i] = ua.MonitoredItemModifyResult{StatusCode: ua.BadMonitoredItemIDInvalid}
+			continue
+		}
+		updated := *item
+		filterResult, status := s.setFilter(&updated, modify.RequestedParameters.Filter)
+		if status != ua.Good {
+			results[i] = ua.MonitoredItemModifyResult{StatusCode: status}
+			continue
+		}
+		updated.clientHandle = modify.RequestedParameters.ClientHandle
+		updated.timestamps = req.TimestampsToReturn
+		*item = updated
+		results[i] = ua.MonitoredItemModifyResult{
+			RevisedSamplingInterval: float64(sub.interval) / float64(time.Millisecond),
+			RevisedQueueSize:        item.queueSize(),
+			FilterResult:            filterResult,
+		}
+	}
+	return ua.ModifyMonitoredItemsResponse{ResponseHeader: responseHeader(req.RequestHeader, ua.Good), Results: results}
+}
+
+func (s *Server) setMonitoringMode(sess *session, req ua.SetMonitoringModeRequest) any {
+	sub, ok := sess.subscriptions[req.SubscriptionID]
+	if !ok {
+		return fault(req.RequestHeader, ua.BadSubscriptionIDInvalid)
+	}
+	if len(req.MonitoredItemIDs) == 0 {
+		return fault(req.RequestHeader, ua.BadNothingToDo)
+	}
+	results := make([]ua.StatusCode, len(req.MonitoredItemIDs))
+	for i, id := range req.MonitoredItemIDs {
+		item, ok := sub.items[id]
+		if !ok {
+			results[i] = ua.BadMonitoredItemIDInvalid
+			continue
+		}
+		item.mode = req.MonitoringMode
+		if item.mode != ua.MonitoringReporting {
+			item.pending, item.events = nil, nil
+		}
+		if item.mode == ua.MonitoringDisabled {
+			item.last = nil
+		}
+	}
+	return ua.SetMonitoringModeResponse{ResponseHeader: responseHeader(req.RequestHeader, ua.Good), Results: results}
+}
+
+func (s *Server) deleteMonitoredItems(sess *session, req ua.DeleteMonitoredItemsRequest) any {
+	sub, ok := sess.subscriptions[req.SubscriptionID]
+	if !ok {
+		return fault(req.RequestHeader, ua.BadSubscriptionIDInvalid)
+	}
+	if len(req.MonitoredItemIDs) == 0 {
+		return fault(req.RequestHeader, ua.BadNothingToDo)
+	}
+	results := make([]ua.StatusCode, len(req.MonitoredItemIDs))
+	for i, id := range req.MonitoredItemIDs {
+		if _, ok := sub.items[id]; !ok {
+			results[i] = ua.BadMonitoredItemIDInvalid
+			continue
+		}
+		delete(sub.items, id)
+	}
+	return ua.DeleteMonitoredItemsResponse{ResponseHeader: responseHeader(req.RequestHeader, ua.Good), Results: results}
+}
+
+// publish принимает подтверждения и ставит запрос в очередь. Опоздавшие подписки
+// отвечают на него сразу, остальные — в следующем цикле публикации.
+func (s *Server) publish(c *conn, sess *session, requestID uint32, req ua.PublishRequest) any {
+	results := make([]ua.StatusCode, len(req.SubscriptionAcknowledgements))
+	for i, ack := range req.SubscriptionAcknowledgements {
+		sub, ok := sess.subscriptions[ack.SubscriptionID]
+		if !ok {
+			results[i] = ua.BadSubscriptionIDInvalid
+			continue
+		}
+		j := slices.IndexFunc(sub.retransmit, func(m ua.NotificationMessage) bool { return m.SequenceNumber == ack.SequenceNumber })
+		if j < 0 {
+			results[i] = ua.BadSequenceNumberUnknown
+			continue
+		}
+		sub.retransmit = slices.Delete(sub.retransmit, j, j+1)
+	}
+
+	if len(sess.subscriptions) == 0 {
+		return ua.PublishResponse{ResponseHeader: responseHeader(req.RequestHeader, ua.BadNoSubscription), Results: results}
+	}
+	if len(sess.publish) >= maxPublishRequests {
+		return ua.PublishResponse{ResponseHeader: responseHeader(req.RequestHeader, ua.BadTooManyPublishRequests), Results: results}
+	}
+
+	pr := publishRequest{conn: c, requestID: requestID, header: req.RequestHeader, results: results}
+	if req.RequestHeader.TimeoutHint > 0 {
+		pr.deadline = time.Now().Add(time.Duration(req.RequestHeader.TimeoutHint) * time.Millisecond)
+	}
+	sess.publish = append(sess.publish, pr)
+
+	ids := make([]uint32, 0, len(sess.subscriptions))
+	for id := range sess.subscriptions {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		sub := sess.subscriptions[id]
+		if !sub.late {
+			continue
+		}
+		if sub.enabled && sub.hasNotifications() {
+			s.sendNotifications(sub)
+		} else {
+			s.sendKeepAlive(sub)
+		}
+		break
+	}
+	return nil
+}
+
+func (s *Server) republish(sess *session, req ua.RepublishRequest) any {
+	sub, ok := sess.subscriptions[req.SubscriptionID]
+	if !ok {
+		return fault(req.RequestHeader, ua.BadSubscriptionIDInvalid)
+	}
+	for _, msg := range sub.retransmit {
+		if msg.SequenceNumber == req.RetransmitSequenceNumber {
+			return ua.RepublishResponse{ResponseHeader: responseHeader(req.RequestHeader, ua.Good), NotificationMessage: msg}
+		}
+	}
+	return fault(req.RequestHeader, ua.BadMessageNotAvailable)
+}
@@ -0,0 +1,400 @@
+// Package ua реализует двоичную кодировку OPC UA (OPC 10000-6), типы данных и сообщения
+// служб, а также транспорт UA TCP с политикой безопасности None — все, что нужно
+// встроенному серверу пакета opcua и тестовому клиенту opcuatest.
+//
+// Структуры сообщений кодируются по порядку экспортируемых полей: bool — Boolean,
+// целые и вещественные типы — соответствующие типы OPC UA, string — String,
+// []byte — ByteString, time.Time — DateTime, срезы — массивы, структуры — поле за полем.
+// Встроенные типы со своей кодировкой (NodeID, Variant, DataValue и т.д.) реализуют
+// Marshaler и Unmarshaler.
+package ua
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"time"
+)
+
+// Marshaler кодирует значение в двоичном виде.
+type Marshaler interface {
+	EncodeUA(e *Encoder)
+}
+
+// Unmarshaler декодирует значение из двоичного вида.
+type Unmarshaler interface {
+	DecodeUA(d *Decoder)
+}
+
+var (
+	marshalerType   = reflect.TypeFor[Marshaler]()
+	unmarshalerType = reflect.TypeFor[Unmarshaler]()
+	timeType        = reflect.TypeFor[time.Time]()
+)
+
+// Encode кодирует v.
+func Encode(v any) ([]byte, error) {
+	e := &Encoder{}
+	e.Value(v)
+	return e.Bytes(), e.Err()
+}
+
+// Decode декодирует b в значение, на которое указывает v.
+func Decode(b []byte, v any) error {
+	d := NewDecoder(b)
+	d.Value(v)
+	return d.Err()
+}
+
+// Encoder накапливает закодированные значения. Первая ошибка сохраняется,
+// последующие записи игнорируются.
+type Encoder struct {
+	buf []byte
+	err error
+}
+
+// Bytes возвращает закодированные данные.
+func (e *Encoder) Bytes() []byte { return e.buf }
+
+// Err возвращает первую ошибку кодирования.
+func (e *Encoder) Err() error { return e.err }
+
+func (e *Encoder) fail(err error) {
+	if e.err == nil {
+		e.err = err
+	}
+}
+
+func (e *Encoder) Uint8(v uint8)     { e.buf = append(e.buf, v) }
+func (e *Encoder) Uint16(v uint16)   { e.buf = binary.LittleEndian.AppendUint16(e.buf, v) }
+func (e *Encoder) Uint32(v uint32)   { e.buf = binary.LittleEndian.AppendUint32(e.buf, v) }
+func (e *Encoder) Uint64(v uint64)   { e.buf = binary.LittleEndian.AppendUint64(e.buf, v) }
+func (e *Encoder) Int32(v int32)     { e.Uint32(uint32(v)) }
+func (e *Encoder) Int64(v int64)     { e.Uint64(uint64(v)) }
+func (e *Encoder) Float64(v float64) { e.Uint64(math.Float64bits(v)) }
+
+// Bool кодирует Boolean.
+func (e *Encoder) Bool(v bool) {
+	if v {
+		e.Uint8(1)
+	} else {
+		e.Uint8(0)
+	}
+}
+
+// String кодирует String. Пустая строка кодируется как null.
+func (e *Encoder) String(s string) {
+	if s == "" {
+		e.Int32(-1)
+		return
+	}
+	e.Int32(int32(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+// ByteString кодирует ByteString; nil кодируется как null.
+func (e *Encoder) ByteString(b []byte) {
+	if b == nil {
+		e.Int32(-1)
+		return
+	}
+	e.Int32(int32(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// Количество интервалов по 100 нс между 1601-01-01 и 1970-01-01.
+const epochDelta = 116444736000000000
+
+// Time кодирует DateTime: интервалы по 100 нс с 1601-01-01 UTC. Нулевое время кодируется как 0.
+func (e *Encoder) Time(t time.Time) {
+	if t.IsZero() {
+		e.Int64(0)
+		return
+	}
+	e.Int64(t.Unix()*10_000_000 + int64(t.Nanosecond()/100) + epochDelta)
+}
+
+// Value кодирует v по правилам пакета.
+func (e *Encoder) Value(v any) {
+	e.value(reflect.ValueOf(v))
+}
+
+func (e *Encoder) value(v reflect.Value) {
+	if e.err != nil {
+		return
+	}
+	if !v.IsValid() {
+		e.fail(errors.New("ua: cannot encode nil"))
+		return
+	}
+	t := v.Type()
+	if t == timeType {
+		e.Time(v.Interface().(time.Time))
+		return
+	}
+	if t.Implements(marshalerType) && t.Kind() != reflect.Pointer {
+		v.Interface().(Marshaler).EncodeUA(e)
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		e.Bool(v.Bool())
+	case reflect.Int8:
+		e.Uint8(uint8(v.Int()))
+	case reflect.Uint8:
+		e.Uint8(uint8(v.Uint()))
+	case reflect.Int16:
+		e.Uint16(uint16(v.Int()))
+	case reflect.Uint16:
+		e.Uint16(uint16(v.Uint()))
+	case reflect.Int32:
+		e.Int32(int32(v.Int()))
+	case reflect.Uint32:
+		e.Uint32(uint32(v.Uint()))
+	case reflect.Int64:
+		e.Int64(v.Int())
+	case reflect.Uint64:
+		e.Uint64(v.Uint())
+	case reflect.Float32:
+		e.Uint32(math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		e.Float64(v.Float())
+	case reflect.String:
+		e.String(v.String())
+	case reflect.Array:
+		for i := range v.Len() {
+			e.value(v.Index(i))
+		}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			e.ByteString(v.Bytes())
+			return
+		}
+		if v.IsNil() {
+			e.Int32(-1)
+			return
+		}
+		e.Int32(int32(v.Len()))
+		for i := range v.Len() {
+			e.value(v.Index(i))
+		}
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if t.Field(i).IsExported() {
+				e.value(v.Field(i))
+			}
+		}
+	case reflect.Pointer:
+		if v.IsNil() {
+			e.value(reflect.Zero(t.Elem()))
+		} else {
+			e.value(v.Elem())
+		}
+	default:
+		e.fail(fmt.Errorf("ua: cannot encode %s", t))
+	}
+}
+
+// Decoder читает закодированные значения. После первой ошибки все чтения возвращают
+// нулевые значения.
+type Decoder struct {
+	buf []byte
+	pos int
+	err error
+}
+
+// NewDecoder создает декодер для b.
+func NewDecoder(b []byte) *Decoder {
+	return &Decoder{buf: b}
+}
+
+// Err возвращает первую ошибку декодирования.
+func (d *Decoder) Err() error { return d.err }
+
+// Len возвращает количество непрочитанных байт.
+func (d *Decoder) Len() int { return len(d.buf) - d.pos }
+
+// Rest возвращает непрочитанные байты.
+func (d *Decoder) Rest() []byte { return d.buf[d.pos:] }
+
+func (d *Decoder) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+// Bytes читает n байт без копирования.
+func (d *Decoder) Bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || d.Len() < n {
+		d.fail(io.ErrUnexpectedEOF)
+		return nil
+	}
+	b := d.buf[d.pos : d.pos+n]
+	d.pos += n
+	return b
+}
+
+func (d *Decoder) Uint8() uint8 {
+	if b := d.Bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (d *Decoder) Uint16() uint16 {
+	if b := d.Bytes(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (d *Decoder) Uint32() uint32 {
+	if b := d.Bytes(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (d *Decoder) Uint64() uint64 {
+	if b := d.Bytes(8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (d *Decoder) Int32() int32     { return int32(d.Uint32()) }
+func (d *Decoder) Int64() int64     { return int64(d.Uint64()) }
+func (d *Decoder) Float64() float64 { return math.Float64frombits(d.Uint64()) }
+func (d *Decoder) Bool() bool       { return d.Uint8() != 0 }
+
+// String читает String; null возвращается как пустая строка.
+func (d *Decoder) String() string {
+	return string(d.ByteString())
+}
+
+// ByteString читает ByteString; null возвращается как nil.
+func (d *Decoder) ByteString() []byte {
+	n := d.Int32()
+	if n < 0 {
+		return nil
+	}
+	b := d.Bytes(int(n))
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
+
+// Time читает DateTime; 0 и значения до 1970 года возвращаются как нулевое время.
+func (d *Decoder) Time() time.Time {
+	ticks := d.Int64()
+	if ticks <= epochDelta || ticks == math.MaxInt64 {
+		return time.Time{}
+	}
+	ticks -= epochDelta
+	return time.Unix(ticks/10_000_000, (ticks%10_000_000)*100).UTC()
+}
+
+// arrayLen читает длину массива; -1 — null.
+func (d *Decoder) arrayLen() int {
+	n := d.Int32()
+	if n < 0 {
+		return -1
+	}
+	// Каждый элемент занимает хотя бы один байт
+	if int(n) > d.Len() {
+		d.fail(fmt.Errorf("ua: array length %d exceeds message", n))
+		return -1
+	}
+	return int(n)
+}
+
+// Value декодирует значение, на которое указывает v.
+func (d *Decoder) Value(v any) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		d.fail(fmt.Errorf("ua: cannot decode into %T", v))
+		return
+	}
+	d.value(rv.Elem())
+}
+
+func (d *Decoder) value(v reflect.Value) {
+	if d.err != nil {
+		return
+	}
+	t := v.Type()
+	if t == timeType {
+		v.Set(reflect.ValueOf(d.Time()))
+		return
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		v.Addr().Interface().(Unmarshaler).DecodeUA(d)
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		v.SetBool(d.Bool())
+	case reflect.Int8:
+		v.SetInt(int64(int8(d.Uint8())))
+	case reflect.Uint8:
+		v.SetUint(uint64(d.Uint8()))
+	case reflect.Int16:
+		v.SetInt(int64(int16(d.Uint16())))
+	case reflect.Uint16:
+		v.SetUint(uint64(d.Uint16()))
+	case reflect.Int32:
+		v.SetInt(int64(d.Int32()))
+	case reflect.Uint32:
+		v.SetUint(uint64(d.Uint32()))
+	case reflect.Int64:
+		v.SetInt(d.Int64())
+	case reflect.Uint64:
+		v.SetUint(d.Uint64())
+	case reflect.Float32:
+		v.SetFloat(float64(math.Float32frombits(d.Uint32())))
+	case reflect.Float64:
+		v.SetFloat(d.Float64())
+	case reflect.String:
+		v.SetString(d.String())
+	case reflect.Array:
+		for i := range v.Len() {
+			d.value(v.Index(i))
+		}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			v.SetBytes(d.ByteString())
+			return
+		}
+		n := d.arrayLen()
+		if n < 0 {
+			v.SetZero()
+			return
+		}
+		s := reflect.MakeSlice(t, n, n)
+		for i := range n {
+			d.value(s.Index(i))
+		}
+		v.Set(s)
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if t.Field(i).IsExported() {
+				d.value(v.Field(i))
+			}
+		}
+	case reflect.Pointer:
+		p := reflect.New(t.Elem())
+		d.value(p.Elem())
+		v.Set(p)
+	default:
+		d.fail(fmt.Errorf("ua: cannot decode %s", t))
+	}
+}
@@ -0,0 +1,421 @@
+package ua
+
+import "fmt"
+
+// Встроенные типы данных (идентификаторы в маске Variant).
+const (
+	TypeBoolean         byte = 1
+	TypeSByte           byte = 2
+	TypeByte            byte = 3
+	TypeInt16           byte = 4
+	TypeUInt16          byte = 5
+	TypeInt32           byte = 6
+	TypeUInt32          byte = 7
+	TypeInt64           byte = 8
+	TypeUInt64          byte = 9
+	TypeFloat           byte = 10
+	TypeDouble          byte = 11
+	TypeString          byte = 12
+	TypeDateTime        byte = 13
+	TypeGUID            byte = 14
+	TypeByteString      byte = 15
+	TypeNodeID          byte = 17
+	TypeExpandedNodeID  byte = 18
+	TypeStatusCode      byte = 19
+	TypeQualifiedName   byte = 20
+	TypeLocalizedText   byte = 21
+	TypeExtensionObject byte = 22
+	TypeDataValue       byte = 23
+	TypeVariant         byte = 24
+)
+
+// Идентификаторы атрибутов узлов.
+const (
+	AttributeNodeID                  uint32 = 1
+	AttributeNodeClass               uint32 = 2
+	AttributeBrowseName              uint32 = 3
+	AttributeDisplayName             uint32 = 4
+	AttributeDescription             uint32 = 5
+	AttributeWriteMask               uint32 = 6
+	AttributeUserWriteMask           uint32 = 7
+	AttributeIsAbstract              uint32 = 8
+	AttributeSymmetric               uint32 = 9
+	AttributeInverseName             uint32 = 10
+	AttributeContainsNoLoops         uint32 = 11
+	AttributeEventNotifier           uint32 = 12
+	AttributeValue                   uint32 = 13
+	AttributeDataType                uint32 = 14
+	AttributeValueRank               uint32 = 15
+	AttributeArrayDimensions         uint32 = 16
+	AttributeAccessLevel             uint32 = 17
+	AttributeUserAccessLevel         uint32 = 18
+	AttributeMinimumSamplingInterval uint32 = 19
+	AttributeHistorizing             uint32 = 20
+	AttributeExecutable              uint32 = 21
+	AttributeUserExecutable          uint32 = 22
+)
+
+// NodeClass — класс узла.
+type NodeClass int32
+
+// Классы узлов (значения — биты маски NodeClassMask).
+const (
+	NodeClassUnspecified   NodeClass = 0
+	NodeClassObject        NodeClass = 1
+	NodeClassVariable      NodeClass = 2
+	NodeClassMethod        NodeClass = 4
+	NodeClassObjectType    NodeClass = 8
+	NodeClassVariableType  NodeClass = 16
+	NodeClassReferenceType NodeClass = 32
+	NodeClassDataType      NodeClass = 64
+	NodeClassView          NodeClass = 128
+)
+
+// BrowseDirection — направление обхода ссылок.
+type BrowseDirection int32
+
+// Направления обхода ссылок.
+const (
+	BrowseForward BrowseDirection = 0
+	BrowseInverse BrowseDirection = 1
+	BrowseBoth    BrowseDirection = 2
+)
+
+// Биты маски ResultMask запроса Browse.
+const (
+	BrowseResultReferenceType  uint32 = 0x01
+	BrowseResultIsForward      uint32 = 0x02
+	BrowseResultNodeClass      uint32 = 0x04
+	BrowseResultBrowseName     uint32 = 0x08
+	BrowseResultDisplayName    uint32 = 0x10
+	BrowseResultTypeDefinition uint32 = 0x20
+	BrowseResultAll            uint32 = 0x3F
+)
+
+// TimestampsToReturn — какие метки времени возвращать со значениями.
+type TimestampsToReturn int32
+
+// Варианты меток времени.
+const (
+	TimestampsSource  TimestampsToReturn = 0
+	TimestampsServer  TimestampsToReturn = 1
+	TimestampsBoth    TimestampsToReturn = 2
+	TimestampsNeither TimestampsToReturn = 3
+)
+
+// MonitoringMode — режим отслеживаемого элемента.
+type MonitoringMode int32
+
+// Режимы отслеживаемых элементов.
+const (
+	MonitoringDisabled  MonitoringMode = 0
+	MonitoringSampling  MonitoringMode = 1
+	MonitoringReporting MonitoringMode = 2
+)
+
+// DataChangeTrigger — условие уведомления об изменении значения.
+type DataChangeTrigger int32
+
+// Условия уведомления об изменении значения.
+const (
+	TriggerStatus               DataChangeTrigger = 0
+	TriggerStatusValue          DataChangeTrigger = 1
+	TriggerStatusValueTimestamp DataChangeTrigger = 2
+)
+
+// MessageSecurityMode — режим защиты сообщений.
+type MessageSecurityMode int32
+
+// Режимы защиты сообщений.
+const (
+	SecurityModeInvalid        MessageSecurityMode = 0
+	SecurityModeNone           MessageSecurityMode = 1
+	SecurityModeSign           MessageSecurityMode = 2
+	SecurityModeSignAndEncrypt MessageSecurityMode = 3
+)
+
+// UserTokenType — вид удостоверения пользователя.
+type UserTokenType int32
+
+// Виды удостоверений пользователя.
+const (
+	UserTokenAnonymous   UserTokenType = 0
+	UserTokenUserName    UserTokenType = 1
+	UserTokenCertificate UserTokenType = 2
+	UserTokenIssued      UserTokenType = 3
+)
+
+// ApplicationType — вид приложения OPC UA.
+type ApplicationType int32
+
+// Виды приложений.
+const (
+	ApplicationServer          ApplicationType = 0
+	ApplicationClient          ApplicationType = 1
+	ApplicationClientAndServer ApplicationType = 2
+	ApplicationDiscoveryServer ApplicationType = 3
+)
+
+// SecurityTokenRequestType — выдача нового или продление токена канала.
+type SecurityTokenRequestType int32
+
+// Виды запросов токена канала.
+const (
+	SecurityTokenIssue SecurityTokenRequestType = 0
+	SecurityTokenRenew SecurityTokenRequestType = 1
+)
+
+// ServerState — состояние сервера.
+type ServerState int32
+
+// Состояния сервера.
+const (
+	ServerStateRunning  ServerState = 0
+	ServerStateFailed   ServerState = 1
+	ServerStateShutdown ServerState = 4
+)
+
+// Биты AccessLevel и EventNotifier.
+const (
+	AccessLevelCurrentRead  byte = 0x01
+	AccessLevelCurrentWrite byte = 0x02
+	EventNotifierSubscribe  byte = 0x01
+)
+
+// ValueRank: скаляр или одномерный массив.
+const (
+	ValueRankScalar int32 = -1
+	ValueRankArray  int32 = 1
+)
+
+// URI политики безопасности None — единственной, которую поддерживает пакет.
+const SecurityPolicyNone = "http://opcfoundation.org/UA/SecurityPolicy#None"
+
+// URI транспортного профиля UA TCP с двоичной кодировкой.
+const TransportProfileTCP = "http://opcfoundation.org/UA-Profile/Transport/uatcp-uasc-uabinary"
+
+// Идентификаторы узлов пространства имен 0, которые использует сервер.
+const (
+	// Типы данных
+	IDBoolean           uint32 = 1
+	IDSByte             uint32 = 2
+	IDByte              uint32 = 3
+	IDInt16             uint32 = 4
+	IDUInt16            uint32 = 5
+	IDInt32             uint32 = 6
+	IDUInt32            uint32 = 7
+	IDInt64             uint32 = 8
+	IDUInt64            uint32 = 9
+	IDFloat             uint32 = 10
+	IDDouble            uint32 = 11
+	IDString            uint32 = 12
+	IDDateTime          uint32 = 13
+	IDByteString        uint32 = 15
+	IDNodeIDType        uint32 = 17
+	IDStatusCodeType    uint32 = 19
+	IDQualifiedNameType uint32 = 20
+	IDLocalizedTextType uint32 = 21
+	IDStructure         uint32 = 22
+	IDBaseDataType      uint32 = 24
+	IDNumber            uint32 = 26
+	IDInteger           uint32 = 27
+	IDUInteger          uint32 = 28
+	IDEnumeration       uint32 = 29
+	IDDuration          uint32 = 290
+	IDUtcTime           uint32 = 294
+	IDLocaleID          uint32 = 295
+	IDBuildInfoType     uint32 = 338
+	IDServerStateType   uint32 = 852
+	IDServerStatusType  uint32 = 862
+
+	// Типы ссылок
+	IDReferences                uint32 = 31
+	IDNonHierarchicalReferences uint32 = 32
+	IDHierarchicalReferences    uint32 = 33
+	IDHasChild                  uint32 = 34
+	IDOrganizes                 uint32 = 35
+	IDHasEventSource            uint32 = 36
+	IDHasTypeDefinition         uint32 = 40
+	IDGeneratesEvent            uint32 = 41
+	IDAggregates                uint32 = 44
+	IDHasSubtype                uint32 = 45
+	IDHasProperty               uint32 = 46
+	IDHasComponent              uint32 = 47
+	IDHasNotifier               uint32 = 48
+
+	// Типы объектов, переменных и событий
+	IDBaseObjectType           uint32 = 58
+	IDFolderType               uint32 = 61
+	IDBaseVariableType         uint32 = 62
+	IDBaseDataVariableType     uint32 = 63
+	IDPropertyType             uint32 = 68
+	IDServerType               uint32 = 2004
+	IDBaseEventType            uint32 = 2041
+	IDServerStatusVariableType uint32 = 2138
+	IDConditionType            uint32 = 2782
+	IDAcknowledgeableCondition uint32 = 2881
+	IDAlarmConditionType       uint32 = 2915
+	IDBuildInfoVariableType    uint32 = 3051
+
+	// Папки
+	IDRoot                uint32 = 84
+	IDObjectsFolder       uint32 = 85
+	IDTypesFolder         uint32 = 86
+	IDViewsFolder         uint32 = 87
+	IDObjectTypesFolder   uint32 = 88
+	IDVariableTypesFolder uint32 = 89
+	IDDataTypesFolder     uint32 = 90
+	IDReferenceTypes      uint32 = 91
+
+	// Объект Server
+	IDServer                  uint32 = 2253
+	IDServerServerArray       uint32 = 2254
+	IDServerNamespaceArray    uint32 = 2255
+	IDServerStatus            uint32 = 2256
+	IDServerStatusStartTime   uint32 = 2257
+	IDServerStatusCurrentTime uint32 = 2258
+	IDServerStatusState       uint32 = 2259
+	IDServerStatusBuildInfo   uint32 = 2260
+	IDServerServiceLevel      uint32 = 2267
+)
+
+// StatusCode — код результата OPC UA. Старшие биты задают серьезность: Good, Uncertain, Bad.
+type StatusCode uint32
+
+// Коды результата, которые использует пакет.
+const (
+	Good                              StatusCode = 0
+	BadUnexpectedError                StatusCode = 0x80010000
+	BadInternalError                  StatusCode = 0x80020000
+	BadEncodingLimitsExceeded         StatusCode = 0x80080000
+	BadDecodingError                  StatusCode = 0x80070000
+	BadTimeout                        StatusCode = 0x800A0000
+	BadServiceUnsupported             StatusCode = 0x800B0000
+	BadShutdown                       StatusCode = 0x800C0000
+	BadNothingToDo                    StatusCode = 0x800F0000
+	BadTooManyOperations              StatusCode = 0x80100000
+	BadUserAccessDenied               StatusCode = 0x801F0000
+	BadIdentityTokenInvalid           StatusCode = 0x80200000
+	BadIdentityTokenRejected          StatusCode = 0x80210000
+	BadSecureChannelIDInvalid         StatusCode = 0x80220000
+	BadSessionIDInvalid               StatusCode = 0x80250000
+	BadSessionClosed                  StatusCode = 0x80260000
+	BadSessionNotActivated            StatusCode = 0x80270000
+	BadSubscriptionIDInvalid          StatusCode = 0x80280000
+	BadTimestampsToReturnInvalid      StatusCode = 0x802B0000
+	BadNoCommunication                StatusCode = 0x80310000
+	BadWaitingForInitialData          StatusCode = 0x80320000
+	BadNodeIDInvalid                  StatusCode = 0x80330000
+	BadNodeIDUnknown                  StatusCode = 0x80340000
+	BadAttributeIDInvalid             StatusCode = 0x80350000
+	BadIndexRangeInvalid              StatusCode = 0x80360000
+	BadDataEncodingInvalid            StatusCode = 0x80380000
+	BadNotReadable                    StatusCode = 0x803A0000
+	BadNotWritable                    StatusCode = 0x803B0000
+	BadNotSupported                   StatusCode = 0x803D0000
+	BadMonitoredItemIDInvalid         StatusCode = 0x80420000
+	BadMonitoredItemFilterInvalid     StatusCode = 0x80430000
+	BadMonitoredItemFilterUnsupported StatusCode = 0x80440000
+	BadFilterNotAllowed               StatusCode = 0x80450000
+	BadContinuationPointInvalid       StatusCode = 0x804A0000
+	BadNoContinuationPoints           StatusCode = 0x804B0000
+	BadReferenceTypeIDInvalid         StatusCode = 0x804C0000
+	BadBrowseDirectionInvalid         StatusCode = 0x804D0000
+	BadSecurityModeRejected           StatusCode = 0x80540000
+	BadSecurityPolicyRejected         StatusCode = 0x80550000
+	BadTooManySessions                StatusCode = 0x80560000
+	BadBrowseNameInvalid              StatusCode = 0x80600000
+	BadNoMatch                        StatusCode = 0x806F0000
+	BadTooManyPublishRequests         StatusCode = 0x80780000
+	BadNoSubscription                 StatusCode = 0x80790000
+	BadSequenceNumberUnknown          StatusCode = 0x807A0000
+	BadMessageNotAvailable            StatusCode = 0x807B0000
+	BadTCPMessageTypeInvalid          StatusCode = 0x807E0000
+	BadTCPSecureChannelUnknown        StatusCode = 0x807F0000
+	BadTCPMessageTooLarge             StatusCode = 0x80800000
+	BadTCPEndpointURLInvalid          StatusCode = 0x80830000
+	BadDeviceFailure                  StatusCode = 0x808B0000
+	BadNoData                         StatusCode = 0x809B0000
+	BadTooManySubscriptions           StatusCode = 0x80770000
+	UncertainLastUsableValue          StatusCode = 0x40900000
+)
+
+var statusNames = map[StatusCode]string{
+	Good:                              "Good",
+	BadUnexpectedError:                "BadUnexpectedError",
+	BadInternalError:                  "BadInternalError",
+	BadEncodingLimitsExceeded:         "BadEncodingLimitsExceeded",
+	BadDecodingError:                  "BadDecodingError",
+	BadTimeout:                        "BadTimeout",
+	BadServiceUnsupported:             "BadServiceUnsupported",
+	BadShutdown:                       "BadShutdown",
+	BadNothingToDo:                    "BadNothingToDo",
+	BadTooManyOperations:              "BadTooManyOperations",
+	BadUserAccessDenied:               "BadUserAccessDenied",
+	BadIdentityTokenInvalid:           "BadIdentityTokenInvalid",
+	BadIdentityTokenRejected:          "BadIdentityTokenRejected",
+	BadSecureChannelIDInvalid:         "BadSecureChannelIdInvalid",
+	BadSessionIDInvalid:               "BadSessionIdInvalid",
+	BadSessionClosed:                  "BadSessionClosed",
+	BadSessionNotActivated:            "BadSessionNotActivated",
+	BadSubscriptionIDInvalid:          "BadSubscriptionIdInvalid",
+	BadTimestampsToReturnInvalid:      "BadTimestampsToReturnInvalid",
+	BadNoCommunication:                "BadNoCommunication",
+	BadWaitingForInitialData:          "BadWaitingForInitialData",
+	BadNodeIDInvalid:                  "BadNodeIdInvalid",
+	BadNodeIDUnknown:                  "BadNodeIdUnknown",
+	BadAttributeIDInvalid:             "BadAttributeIdInvalid",
+	BadIndexRangeInvalid:              "BadIndexRangeInvalid",
+	BadDataEncodingInvalid:            "BadDataEncodingInvalid",
+	BadNotReadable:                    "BadNotReadable",
+	BadNotWritable:                    "BadNotWritable",
+	BadNotSupported:                   "BadNotSupported",
+	BadMonitoredItemIDInvalid:         "BadMonitoredItemIdInvalid",
+	BadMonitoredItemFilterInvalid:     "BadMonitoredItemFilterInvalid",
+	BadMonitoredItemFilterUnsupported: "BadMonitoredItemFilterUnsupported",
+	BadFilterNotAllowed:               "BadFilterNotAllowed",
+	BadContinuationPointInvalid:       "BadContinuationPointInvalid",
+	BadNoContinuationPoints:           "BadNoContinuationPoints",
+	BadReferenceTypeIDInvalid:         "BadReferenceTypeIdInvalid",
+	BadBrowseDirectionInvalid:         "BadBrowseDirectionInvalid",
+	BadSecurityModeRejected:           "BadSecurityModeRejected",
+	BadSecurityPolicyRejected:         "BadSecurityPolicyRejected",
+	BadTooManySessions:                "BadTooManySessions",
+	BadBrowseNameInvalid:              "BadBrowseNameInvalid",
+	BadNoMatch:                        "BadNoMatch",
+	BadTooManyPublishRequests:         "BadTooManyPublishRequests",
+	BadNoSubscription:                 "BadNoSubscription",
+	BadSequenceNumberUnknown:          "BadSequenceNumberUnknown",
+	BadMessageNotAvailable:            "BadMessageNotAvailable",
+	BadTCPMessageTypeInvalid:          "BadTcpMessageTypeInvalid",
+	BadTCPSecureChannelUnknown:        "BadTcpSecureChannelUnknown",
+	BadTCPMessageTooLarge:             "BadTcpMessageTooLarge",
+	BadTCPEndpointURLInvalid:          "BadTcpEndpointUrlInvalid",
+	BadDeviceFailure:                  "BadDeviceFailure",
+	BadNoData:                         "BadNoData",
+	BadTooManySubscriptions:           "BadTooManySubscriptions",
+	UncertainLastUsableValue:          "UncertainLastUsableValue",
+}
+
+// IsBad сообщает, что код означает ошибку.
+func (s StatusCode) IsBad() bool {
+	return s&0x80000000 != 0
+}
+
+// IsUncertain сообщает, что значение недостоверно.
+func (s StatusCode) IsUncertain() bool {
+	return s&0xC0000000 == 0x40000000
+}
+
+// String возвращает имя кода, например "BadNodeIdUnknown".
+func (s StatusCode) String() string {
+	if name, ok := statusNames[s&0xFFFF0000]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%08X", uint32(s))
+}
+
+// Error позволяет возвращать код результата как ошибку.
+func (s StatusCode) Error() string {
+	return "ua: " + s.String()
+}
@@ -0,0 +1,707 @@
+package ua
+
+import (
+	"reflect"
+	"time"
+)
+
+// RequestHeader — заголовок запроса службы.
+type RequestHeader struct {
+	AuthenticationToken NodeID
+	Timestamp           time.Time
+	RequestHandle       uint32
+	ReturnDiagnostics   uint32
+	AuditEntryID        string
+	TimeoutHint         uint32
+	AdditionalHeader    ExtensionObject
+}
+
+// ResponseHeader — заголовок ответа службы.
+type ResponseHeader struct {
+	Timestamp          time.Time
+	RequestHandle      uint32
+	ServiceResult      StatusCode
+	ServiceDiagnostics DiagnosticInfo
+	StringTable        []string
+	AdditionalHeader   ExtensionObject
+}
+
+// RequestHeaderOf возвращает заголовок запроса службы msg (первое поле структуры).
+func RequestHeaderOf(msg any) (RequestHeader, bool) {
+	v := reflect.ValueOf(msg)
+	if v.Kind() != reflect.Struct || v.NumField() == 0 {
+		return RequestHeader{}, false
+	}
+	h, ok := v.Field(0).Interface().(RequestHeader)
+	return h, ok
+}
+
+// WithRequestHeader возвращает копию запроса msg с заголовком h.
+func WithRequestHeader(msg any, h RequestHeader) any {
+	v := reflect.New(reflect.TypeOf(msg)).Elem()
+	v.Set(reflect.ValueOf(msg))
+	if v.Kind() == reflect.Struct && v.NumField() > 0 && v.Field(0).Type() == reflect.TypeFor[RequestHeader]() {
+		v.Field(0).Set(reflect.ValueOf(h))
+	}
+	return v.Interface()
+}
+
+// ResponseHeaderOf возвращает заголовок ответа службы msg (первое поле структуры).
+func ResponseHeaderOf(msg any) (ResponseHeader, bool) {
+	v := reflect.ValueOf(msg)
+	if v.Kind() != reflect.Struct || v.NumField() == 0 {
+		return ResponseHeader{}, false
+	}
+	h, ok := v.Field(0).Interface().(ResponseHeader)
+	return h, ok
+}
+
+// ServiceFault — ответ на запрос, который не удалось выполнить целиком.
+type ServiceFault struct {
+	ResponseHeader ResponseHeader
+}
+
+// Обнаружение серверов
+
+type ApplicationDescription struct {
+	ApplicationURI      string
+	ProductURI          string
+	ApplicationName     LocalizedText
+	ApplicationType     ApplicationType
+	GatewayServerURI    string
+	DiscoveryProfileURI string
+	DiscoveryURLs       []string
+}
+
+type UserTokenPolicy struct {
+	PolicyID          string
+	TokenType         UserTokenType
+	IssuedTokenType   string
+	IssuerEndpointURL string
+	SecurityPolicyURI string
+}
+
+type EndpointDescription struct {
+	EndpointURL         string
+	Server              ApplicationDescription
+	ServerCertificate   []byte
+	SecurityMode        MessageSecurityMode
+	SecurityPolicyURI   string
+	UserIdentityTokens  []UserTokenPolicy
+	TransportProfileURI string
+	SecurityLevel       uint8
+}
+
+type FindServersRequest struct {
+	RequestHeader RequestHeader
+	EndpointURL   string
+	LocaleIDs     []string
+	ServerURIs    []string
+}
+
+type FindServersResponse struct {
+	ResponseHeader ResponseHeader
+	Servers        []ApplicationDescription
+}
+
+type GetEndpointsRequest struct {
+	RequestHeader RequestHeader
+	EndpointURL   string
+	LocaleIDs     []string
+	ProfileURIs   []string
+}
+
+type GetEndpointsResponse struct {
+	ResponseHeader ResponseHeader
+	Endpoints      []EndpointDescription
+}
+
+// Защищенный канал
+
+type ChannelSecurityToken struct {
+	ChannelID       uint32
+	TokenID         uint32
+	CreatedAt       time.Time
+	RevisedLifetime uint32
+}
+
+type OpenSecureChannelRequest struct {
+	RequestHeader         RequestHeader
+	ClientProtocolVersion uint32
+	RequestType           SecurityTokenRequestType
+	SecurityMode          MessageSecurityMode
+	ClientNonce           []byte
+	RequestedLifetime     uint32
+}
+
+type OpenSecureChannelResponse struct {
+	ResponseHeader        ResponseHeader
+	ServerProtocolVersion uint32
+	SecurityToken         ChannelSecurityToken
+	ServerNonce           []byte
+}
+
+type CloseSecureChannelRequest struct {
+	RequestHeader RequestHeader
+}
+
+// Сеансы
+
+type SignatureData struct {
+	Algorithm string
+	Signature []byte
+}
+
+type SignedSoftwareCertificate struct {
+	CertificateData []byte
+	Signature       []byte
+}
+
+type CreateSessionRequest struct {
+	RequestHeader           RequestHeader
+	ClientDescription       ApplicationDescription
+	ServerURI               string
+	EndpointURL             string
+	SessionName             string
+	ClientNonce             []byte
+	ClientCertificate       []byte
+	RequestedSessionTimeout float64
+	MaxResponseMessageSize  uint32
+}
+
+type CreateSessionResponse struct {
+	ResponseHeader             ResponseHeader
+	SessionID                  NodeID
+	AuthenticationToken        NodeID
+	RevisedSessionTimeout      float64
+	ServerNonce                []byte
+	ServerCertificate          []byte
+	ServerEndpoints            []EndpointDescription
+	ServerSoftwareCertificates []SignedSoftwareCertificate
+	ServerSignature            SignatureData
+	MaxRequestMessageSize      uint32
+}
+
+type AnonymousIdentityToken struct {
+	PolicyID string
+}
+
+type UserNameIdentityToken struct {
+	PolicyID            string
+	UserName            string
+	Password            []byte
+	EncryptionAlgorithm string
+}
+
+type ActivateSessionRequest struct {
+	RequestHeader              RequestHeader
+	ClientSignature            SignatureData
+	ClientSoftwareCertificates []SignedSoftwareCertificate
+	LocaleIDs                  []string
+	UserIdentityToken          ExtensionObject
+	UserTokenSignature         SignatureData
+}
+
+type ActivateSessionResponse struct {
+	ResponseHeader  ResponseHeader
+	ServerNonce     []byte
+	Results         []StatusCode
+	DiagnosticInfos []DiagnosticInfo
+}
+
+type CloseSessionRequest struct {
+	RequestHeader       RequestHeader
+	DeleteSubscriptions bool
+}
+
+type CloseSessionResponse struct {
+	ResponseHeader ResponseHeader
+}
+
+// Просмотр адресного пространства
+
+type ViewDescription struct {
+	ViewID      NodeID
+	Timestamp   time.Time
+	ViewVersion uint32
+}
+
+type BrowseDescription struct {
+	NodeID          NodeID
+	BrowseDirection BrowseDirection
+	ReferenceTypeID NodeID
+	IncludeSubtypes bool
+	NodeClassMask   uint32
+	ResultMask      uint32
+}
+
+type ReferenceDescription struct {
+	ReferenceTypeID NodeID
+	IsForward       bool
+	NodeID          ExpandedNodeID
+	BrowseName      QualifiedName
+	DisplayName     LocalizedText
+	NodeClass       NodeClass
+	TypeDefinition  ExpandedNodeID
+}
+
+type BrowseResult struct {
+	StatusCode        StatusCode
+	ContinuationPoint []byte
+	References        []ReferenceDescription
+}
+
+type BrowseRequest struct {
+	RequestHeader                 RequestHeader
+	View                          ViewDescription
+	RequestedMaxReferencesPerNode uint32
+	NodesToBrowse                 []BrowseDescription
+}
+
+type BrowseResponse struct {
+	ResponseHeader  ResponseHeader
+	Results         []BrowseResult
+	DiagnosticInfos []DiagnosticInfo
+}
+
+type BrowseNextRequest struct {
+	RequestHeader             RequestHeader
+	ReleaseContinuationPoints bool
+	ContinuationPoints        [][]byte
+}
+
+type BrowseNextResponse struct {
+	ResponseHeader  ResponseHeader
+	Results         []BrowseResult
+	DiagnosticInfos []DiagnosticInfo
+}
+
+type RelativePathElement struct {
+	ReferenceTypeID NodeID
+	IsInverse       bool
+	IncludeSubtypes bool
+	TargetName      QualifiedName
+}
+
+type RelativePath struct {
+	Elements []RelativePathElement
+}
+
+type BrowsePath struct {
+	StartingNode NodeID
+	RelativePath RelativePath
+}
+
+type BrowsePathTarget struct {
+	TargetID           ExpandedNodeID
+	RemainingPathIndex uint32
+}
+
+type BrowsePathResult struct {
+	StatusCode StatusCode
+	Targets    []BrowsePathTarget
+}
+
+type TranslateBrowsePathsToNodeIDsRequest struct {
+	RequestHeader RequestHeader
+	BrowsePaths   []BrowsePath
+}
+
+type TranslateBrowsePathsToNodeIDsResponse struct {
+	ResponseHeader  ResponseHeader
+	Results         []BrowsePathResult
+	DiagnosticInfos []DiagnosticInfo
+}
+
+type RegisterNodesRequest struct {
+	RequestHeader   RequestHeader
+	NodesToRegister []NodeID
+}
+
+type RegisterNodesResponse struct {
+	ResponseHeader    ResponseHeader
+	RegisteredNodeIDs []NodeID
+}
+
+type UnregisterNodesRequest struct {
+	RequestHeader     RequestHeader
+	NodesToUnregister []NodeID
+}
+
+type UnregisterNodesResponse struct {
+	ResponseHeader ResponseHeader
+}
+
+// Чтение и запись атрибутов
+
+type ReadValueID struct {
+	NodeID       NodeID
+	AttributeID  uint32
+	IndexRange   string
+	DataEncoding QualifiedName
+}
+
+type ReadRequest struct {
+	RequestHeader      RequestHeader
+	MaxAge             float64
+	TimestampsToReturn TimestampsToReturn
+	NodesToRead        []ReadValueID
+}
+
+type ReadResponse struct {
+	ResponseHeader  ResponseHeader
+	Results         []DataValue
+	DiagnosticInfos []DiagnosticInfo
+}
+
+type WriteValue struct {
+	NodeID      NodeID
+	AttributeID uint32
+	IndexRange  string
+	Value       DataValue
+}
+
+type WriteRequest struct {
+	RequestHeader RequestHeader
+	NodesToWrite  []WriteValue
+}
+
+type WriteResponse struct {
+	ResponseHeader  ResponseHeader
+	Results         []StatusCode
+	DiagnosticInfos []DiagnosticInfo
+}
+
+// Отслеживаемые элементы
+
+type DataChangeFilter struct {
+	Trigger       DataChangeTrigger
+	DeadbandType  uint32
+	DeadbandValue float64
+}
+
+type SimpleAttributeOperand struct {
+	TypeDefinitionID NodeID
+	BrowsePath       []QualifiedName
+	AttributeID      uint32
+	IndexRange       string
+}
+
+type ContentFilterElement struct {
+	FilterOperator int32
+	FilterOperands []ExtensionObject
+}
+
+type ContentFilter struct {
+	Elements []ContentFilterElement
+}
+
+type EventFilter struct {
+	SelectClauses []SimpleAttributeOperand
+	WhereClause   ContentFilter
+}
+
+type ContentFilterElementResult struct {
+	StatusCode             StatusCode
+	OperandStatusCodes     []StatusCode
+	OperandDiagnosticInfos []DiagnosticInfo
+}
+
+type ContentFilterResult struct {
+	ElementResults         []ContentFilterElementResult
+	ElementDiagnosticInfos []DiagnosticInfo
+}
+
+type EventFilterResult struct {
+	SelectClauseResults         []StatusCode
+	SelectClauseDiagnosticInfos []DiagnosticInfo
+	WhereClauseResult           ContentFilterResult
+}
+
+type MonitoringParameters struct {
+	ClientHandle     uint32
+	SamplingInterval float64
+	Filter           ExtensionObject
+	QueueSize        uint32
+	DiscardOldest    bool
+}
+
+type MonitoredItemCreateRequest struct {
+	ItemToMonitor       ReadValueID
+	MonitoringMode      MonitoringMode
+	RequestedParameters MonitoringParameters
+}
+
+type MonitoredItemCreateResult struct {
+	StatusCode              StatusCode
+	MonitoredItemID         uint32
+	RevisedSamplingInterval float64
+	RevisedQueueSize        uint32
+	FilterResult            ExtensionObject
+}
+
+type CreateMonitoredItemsRequest struct {
+	RequestHeader      RequestHeader
+	SubscriptionID     uint32
+	TimestampsToReturn TimestampsToReturn
+	ItemsToCreate      []MonitoredItemCreateRequest
+}
+
+type CreateMonitoredItemsResponse struct {
+	ResponseHeader  ResponseHeader
+	Results         []MonitoredItemCreateResult
+	DiagnosticInfos []DiagnosticInfo
+}
+
+type MonitoredItemModifyRequest struct {
+	MonitoredItemID     uint32
+	RequestedParameters MonitoringParameters
+}
+
+type MonitoredItemModifyResult struct {
+	StatusCode              StatusCode
+	RevisedSamplingInterval float64
+	RevisedQueueSize        uint32
+	FilterResult            ExtensionObject
+}
+
+type ModifyMonitoredItemsRequest struct {
+	RequestHeader      RequestHeader
+	SubscriptionID     uint32
+	TimestampsToReturn TimestampsToReturn
+	ItemsToModify      []MonitoredItemModifyRequest
+}
+
+type ModifyMonitoredItemsResponse struct {
+	ResponseHeader  ResponseHeader
+	Results         []MonitoredItemModifyResult
+	DiagnosticInfos []DiagnosticInfo
+}
+
+type SetMonitoringModeRequest struct {
+	RequestHeader    RequestHeader
+	SubscriptionID   uint32
+	MonitoringMode   MonitoringMode
+	MonitoredItemIDs []uint32
+}
+
+type SetMonitoringModeResponse struct {
+	ResponseHeader  ResponseHeader
+	Results         []StatusCode
+	DiagnosticInfos []DiagnosticInfo
+}
+
+type DeleteMonitoredItemsRequest struct {
+	RequestHeader    RequestHeader
+	SubscriptionID   uint32
+	MonitoredItemIDs []uint32
+}
+
+type DeleteMonitoredItemsResponse struct {
+	ResponseHeader  ResponseHeader
+	Results         []StatusCode
+	DiagnosticInfos []DiagnosticInfo
+}
+
+// Подписки
+
+type CreateSubscriptionRequest struct {
+	RequestHeader               RequestHeader
+	RequestedPublishingInterval float64
+	RequestedLifetimeCount      uint32
+	RequestedMaxKeepAliveCount  uint32
+	MaxNotificationsPerPublish  uint32
+	PublishingEnabled           bool
+	Priority                    uint8
+}
+
+type CreateSubscriptionResponse struct {
+	ResponseHeader            ResponseHeader
+	SubscriptionID            uint32
+	RevisedPublishingInterval float64
+	RevisedLifetimeCount      uint32
+	RevisedMaxKeepAliveCount  uint32
+}
+
+type ModifySubscriptionRequest struct {
+	RequestHeader               RequestHeader
+	SubscriptionID              uint32
+	RequestedPublishingInterval float64
+	RequestedLifetimeCount      uint32
+	RequestedMaxKeepAliveCount  uint32
+	MaxNotificationsPerPublish  uint32
+	Priority                    uint8
+}
+
+type ModifySubscriptionResponse struct {
+	ResponseHeader            ResponseHeader
+	RevisedPublishingInterval float64
+	RevisedLifetimeCount      uint32
+	RevisedMaxKeepAliveCount  uint32
+}
+
+type SetPublishingModeRequest struct {
+	RequestHeader     RequestHeader
+	PublishingEnabled bool
+	SubscriptionIDs   []uint32
+}
+
+type SetPublishingModeResponse struct {
+	ResponseHeader  ResponseHeader
+	Results         []StatusCode
+	DiagnosticInfos []DiagnosticInfo
+}
+
+type SubscriptionAcknowledgement struct {
+	SubscriptionID uint32
+	SequenceNumber uint32
+}
+
+type NotificationMessage struct {
+	SequenceNumber   uint32
+	PublishTime      time.Time
+	NotificationData []ExtensionObject
+}
+
+type MonitoredItemNotification struct {
+	ClientHandle uint32
+	Value        DataValue
+}
+
+type DataChangeNotification struct {
+	MonitoredItems  []MonitoredItemNotification
+	DiagnosticInfos []DiagnosticInfo
+}
+
+type EventFieldList struct {
+	ClientHandle uint32
+	EventFields  []Variant
+}
+
+type EventNotificationList struct {
+	Events []EventFieldList
+}
+
+type StatusChangeNotification struct {
+	Status         StatusCode
+	DiagnosticInfo DiagnosticInfo
+}
+
+type PublishRequest struct {
+	RequestHeader                RequestHeader
+	SubscriptionAcknowledgements []SubscriptionAcknowledgement
+}
+
+type PublishResponse struct {
+	ResponseHeader           ResponseHeader
+	SubscriptionID           uint32
+	AvailableSequenceNumbers []uint32
+	MoreNotifications        bool
+	NotificationMessage      NotificationMessage
+	Results                  []StatusCode
+	DiagnosticInfos          []DiagnosticInfo
+}
+
+type RepublishRequest struct {
+	RequestHeader            RequestHeader
+	SubscriptionID           uint32
+	RetransmitSequenceNumber uint32
+}
+
+type RepublishResponse struct {
+	ResponseHeader      ResponseHeader
+	NotificationMessage NotificationMessage
+}
+
+type DeleteSubscriptionsRequest struct {
+	RequestHeader   RequestHeader
+	SubscriptionIDs []uint32
+}
+
+type DeleteSubscriptionsResponse struct {
+	ResponseHeader  ResponseHeader
+	Results         []StatusCode
+	DiagnosticInfos []DiagnosticInfo
+}
+
+// Структуры объекта Server
+
+type BuildInfo struct {
+	ProductURI       string
+	ManufacturerName string
+	ProductName      string
+	SoftwareVersion  string
+	BuildNumber      string
+	BuildDate        time.Time
+}
+
+type ServerStatusDataType struct {
+	StartTime           time.Time
+	CurrentTime         time.Time
+	State               ServerState
+	BuildInfo           BuildInfo
+	SecondsTillShutdown uint32
+	ShutdownReason      LocalizedText
+}
+
+// Идентификаторы двоичных кодировок структур (OPC 10000-6, NodeIds.csv).
+func init() {
+	Register(397, ServiceFault{})
+	Register(422, FindServersRequest{})
+	Register(425, FindServersResponse{})
+	Register(428, GetEndpointsRequest{})
+	Register(431, GetEndpointsResponse{})
+	Register(446, OpenSecureChannelRequest{})
+	Register(449, OpenSecureChannelResponse{})
+	Register(452, CloseSecureChannelRequest{})
+	Register(461, CreateSessionRequest{})
+	Register(464, CreateSessionResponse{})
+	Register(467, ActivateSessionRequest{})
+	Register(470, ActivateSessionResponse{})
+	Register(473, CloseSessionRequest{})
+	Register(476, CloseSessionResponse{})
+	Register(321, AnonymousIdentityToken{})
+	Register(324, UserNameIdentityToken{})
+	Register(527, BrowseRequest{})
+	Register(530, BrowseResponse{})
+	Register(533, BrowseNextRequest{})
+	Register(536, BrowseNextResponse{})
+	Register(554, TranslateBrowsePathsToNodeIDsRequest{})
+	Register(557, TranslateBrowsePathsToNodeIDsResponse{})
+	Register(560, RegisterNodesRequest{})
+	Register(563, RegisterNodesResponse{})
+	Register(566, UnregisterNodesRequest{})
+	Register(569, UnregisterNodesResponse{})
+	Register(603, SimpleAttributeOperand{})
+	Register(631, ReadRequest{})
+	Register(634, ReadResponse{})
+	Register(673, WriteRequest{})
+	Register(676, WriteResponse{})
+	Register(724, DataChangeFilter{})
+	Register(727, EventFilter{})
+	Register(736, EventFilterResult{})
+	Register(751, CreateMonitoredItemsRequest{})
+	Register(754, CreateMonitoredItemsResponse{})
+	Register(763, ModifyMonitoredItemsRequest{})
+	Register(766, ModifyMonitoredItemsResponse{})
+	Register(769, SetMonitoringModeRequest{})
+	Register(772, SetMonitoringModeResponse{})
+	Register(781, DeleteMonitoredItemsRequest{})
+	Register(784, DeleteMonitoredItemsResponse{})
+	Register(787, CreateSubscriptionRequest{})
+	Register(790, CreateSubscriptionResponse{})
+	Register(793, ModifySubscriptionRequest{})
+	Register(796, ModifySubscriptionResponse{})
+	Register(799, SetPublishingModeRequest{})
+	Register(802, SetPublishingModeResponse{})
+	Register(811, DataChangeNotification{})
+	Register(820, StatusChangeNotification{})
+	Register(826, PublishRequest{})
+	Register(829, PublishResponse{})
+	Register(832, RepublishRequest{})
+	Register(835, RepublishResponse{})
+	Register(847, DeleteSubscriptionsRequest{})
+	Register(850, DeleteSubscriptionsResponse{})
+	Register(864, ServerStatusDataType{})
+	Register(916, EventNotificationList{})
+}
@@ -0,0 +1,386 @@
+package ua
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+// Типы сообщений UA TCP и безопасного канала.
+const (
+	MessageHello        = "HEL"
+	MessageAcknowledge  = "ACK"
+	MessageError        = "ERR"
+	MessageOpenChannel  = "OPN"
+	MessageCloseChannel = "CLO"
+	MessageService      = "MSG"
+)
+
+// Типы фрагментов (chunk) сообщения.
+const (
+	chunkFinal        = 'F'
+	chunkIntermediate = 'C'
+	chunkAbort        = 'A'
+)
+
+const (
+	headerSize = 8
+	// Минимальный размер буфера по OPC 10000-6
+	minBufferSize = 8192
+)
+
+// Hello — первое сообщение клиента UA TCP.
+type Hello struct {
+	ProtocolVersion   uint32
+	ReceiveBufferSize uint32
+	SendBufferSize    uint32
+	MaxMessageSize    uint32
+	MaxChunkCount     uint32
+	EndpointURL       string
+}
+
+// Acknowledge — ответ сервера на Hello с согласованными ограничениями.
+type Acknowledge struct {
+	ProtocolVersion   uint32
+	ReceiveBufferSize uint32
+	SendBufferSize    uint32
+	MaxMessageSize    uint32
+	MaxChunkCount     uint32
+}
+
+// TransportError — сообщение ERR перед закрытием соединения.
+type TransportError struct {
+	Status StatusCode
+	Reason string
+}
+
+func (e *TransportError) Error() string {
+	if e.Reason == "" {
+		return "ua: transport error " + e.Status.String()
+	}
+	return fmt.Sprintf("ua: transport error %s: %s", e.Status, e.Reason)
+}
+
+// Limits — ограничения размеров сообщений соединения.
+type Limits struct {
+	ReceiveBufferSize uint32 // Максимальный размер принимаемого фрагмента
+	SendBufferSize    uint32 // Максимальный размер отправляемого фрагмента
+	MaxMessageSize    uint32 // Максимальный размер принимаемого сообщения и всех незавершенных сообщений канала вместе; 0 — без ограничения
+	MaxChunkCount     uint32 // Максимальное число фрагментов принимаемого сообщения; 0 — без ограничения
+}
+
+// DefaultLimits — ограничения по умолчанию.
+var DefaultLimits = Limits{
+	ReceiveBufferSize: 65535,
+	SendBufferSize:    65535,
+	MaxMessageSize:    4 << 20,
+	MaxChunkCount:     0,
+}
+
+// Message — собранное из фрагментов сообщение безопасного канала.
+type Message struct {
+	Type      string // MessageOpenChannel, MessageCloseChannel или MessageService
+	ChannelID uint32
+	TokenID   uint32 // Для OPN не заполняется
+	RequestID uint32
+	Body      []byte // Закодированное сообщение службы (см. DecodeMessage)
+}
+
+// Channel — безопасный канал поверх соединения UA TCP с политикой безопасности None:
+// собирает сообщения из фрагментов и разбивает на фрагменты отправляемые сообщения.
+// Чтение выполняется из одной горутины, запись безопасна из нескольких.
+type Channel struct {
+	conn   net.Conn
+	limits Limits
+
+	wmu       sync.Mutex
+	channelID uint32
+	tokenID   uint32
+	seq       uint32
+	partial   map[uint32][]byte // Незавершенные сообщения по ID запроса
+	chunks    map[uint32]uint32
+	buffered  int // Суммарный размер незавершенных сообщений
+}
+
+// NewChannel создает канал поверх соединения, для которого уже выполнен обмен Hello/Acknowledge.
+func NewChannel(conn net.Conn, limits Limits) *Channel {
+	return &Channel{
+		conn:    conn,
+		limits:  limits,
+		partial: make(map[uint32][]byte),
+		chunks:  make(map[uint32]uint32),
+	}
+}
+
+// SetToken задает ID канала и токена для отправляемых фрагментов. Сервер задает их
+// при открытии канала, клиент — по ответу OpenSecureChannel.
+func (c *Channel) SetToken(channelID, tokenID uint32) {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	c.channelID = channelID
+	c.tokenID = tokenID
+}
+
+// Close закрывает соединение.
+func (c *Channel) Close() error {
+	return c.conn.Close()
+}
+
+// RemoteAddr возвращает адрес другой стороны.
+func (c *Channel) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// ReadMessage читает фрагменты до завершения очередного сообщения. ID запроса выбирает
+// другая сторона, поэтому размер незавершенных сообщений ограничивается для канала
+// в целом: иначе фрагменты под разными ID запроса заняли бы неограниченную память.
+func (c *Channel) ReadMessage() (Message, error) {
+	for {
+		typ, chunkType, body, err := readChunk(c.conn, c.limits.ReceiveBufferSize)
+		if err != nil {
+			return Message{}, err
+		}
+		if typ == MessageError {
+			return Message{}, decodeTransportError(body)
+		}
+		msg, data, err := c.parseChunk(typ, body)
+		if err != nil {
+			return Message{}, err
+		}
+
+		switch chunkType {
+		case chunkAbort:
+			c.discard(msg.RequestID)
+			continue
+		case chunkIntermediate, chunkFinal:
+		default:
+			return Message{}, &TransportError{Status: BadTCPMessageTypeInvalid, Reason: fmt.Sprintf("invalid chunk type %q", chunkType)}
+		}
+
+		c.partial[msg.RequestID] = append(c.partial[msg.RequestID], data...)
+		c.chunks[msg.RequestID]++
+		c.buffered += len(data)
+		if max := c.limits.MaxMessageSize; max > 0 && c.buffered > int(max) {
+			return Message{}, &TransportError{Status: BadTCPMessageTooLarge, Reason: "message too large"}
+		}
+		if max := c.limits.MaxChunkCount; max > 0 && c.chunks[msg.RequestID] > max {
+			return Message{}, &TransportError{Status: BadTCPMessageTooLarge, Reason: "too many chunks"}
+		}
+		if chunkType == chunkIntermediate {
+			continue
+		}
+		msg.Body = c.partial[msg.RequestID]
+		c.discard(msg.RequestID)
+		return msg, nil
+	}
+}
+
+// discard удаляет незавершенное сообщение запроса requestID.
+func (c *Channel) discard(requestID uint32) {
+	c.buffered -= len(c.partial[requestID])
+	delete(c.partial, requestID)
+	delete(c.chunks, requestID)
+}
+
+// parseChunk разбирает заголовки безопасности и последовательности фрагмента.
+func (c *Channel) parseChunk(typ string, body []byte) (Message, []byte, error) {
+	d := NewDecoder(body)
+	msg := Message{Type: typ, ChannelID: d.Uint32()}
+	switch typ {
+	case MessageOpenChannel:
+		policy := d.String()
+		d.ByteString() // SenderCertificate
+		d.ByteString() // ReceiverCertificateThumbprint
+		if d.Err() == nil && policy != SecurityPolicyNone {
+			return Message{}, nil, &TransportError{Status: BadSecurityPolicyRejected, Reason: policy}
+		}
+	case MessageService, MessageCloseChannel:
+		msg.TokenID = d.Uint32()
+	default:
+		return Message{}, nil, &TransportError{Status: BadTCPMessageTypeInvalid, Reason: fmt.Sprintf("unexpected message %q", typ)}
+	}
+	d.Uint32() // SequenceNumber
+	msg.RequestID = d.Uint32()
+	if d.Err() != nil {
+		return Message{}, nil, &TransportError{Status: BadDecodingError, Reason: "truncated chunk header"}
+	}
+	return msg, d.Rest(), nil
+}
+
+// WriteMessage отправляет сообщение службы body (см. EncodeMessage), при необходимости
+// разбивая его на фрагменты.
+func (c *Channel) WriteMessage(typ string, requestID uint32, body []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	var security []byte
+	if typ == MessageOpenChannel {
+		e := &Encoder{}
+		e.String(SecurityPolicyNone)
+		e.ByteString(nil)
+		e.ByteString(nil)
+		security = e.Bytes()
+	} else {
+		security = binary.LittleEndian.AppendUint32(nil, c.tokenID)
+	}
+
+	maxBody := int(c.limits.SendBufferSize) - headerSize - 4 - len(security) - 8
+	if maxBody <= 0 {
+		return errors.New("ua: send buffer too small")
+	}
+	for {
+		n := min(len(body), maxBody)
+		chunkType := byte(chunkFinal)
+		if n < len(body) {
+			chunkType = chunkIntermediate
+		}
+		c.seq++
+		if c.seq > 4294966271 { // Переход через максимум по OPC 10000-6
+			c.seq = 1
+		}
+
+		size := headerSize + 4 + len(security) + 8 + n
+		chunk := make([]byte, 0, size)
+		chunk = append(chunk, typ...)
+		chunk = append(chunk, chunkType)
+		chunk = binary.LittleEndian.AppendUint32(chunk, uint32(size))
+		chunk = binary.LittleEndian.AppendUint32(chunk, c.channelID)
+		chunk = append(chunk, security...)
+		chunk = binary.LittleEndian.AppendUint32(chunk, c.seq)
+		chunk = binary.LittleEndian.AppendUint32(chunk, requestID)
+		chunk = append(chunk, body[:n]...)
+		if _, err := c.conn.Write(chunk); err != nil {
+			return err
+		}
+		body = body[n:]
+		if len(body) == 0 {
+			return nil
+		}
+	}
+}
+
+// WriteError отправляет сообщение ERR. После него соединение следует закрыть.
+func (c *Channel) WriteError(status StatusCode, reason string) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	return writeTransportError(c.conn, status, reason)
+}
+
+// AcceptHello выполняет серверную часть обмена Hello/Acknowledge и возвращает
+// согласованные ограничения и адрес точки подключения, указанный клиентом.
+func AcceptHello(conn net.Conn, limits Limits) (Limits, string, error) {
+	typ, _, body, err := readChunk(conn, minBufferSize)
+	if err != nil {
+		return Limits{}, "", err
+	}
+	if typ != MessageHello {
+		_ = writeTransportError(conn, BadTCPMessageTypeInvalid, "expected HEL")
+		return Limits{}, "", &TransportError{Status: BadTCPMessageTypeInvalid, Reason: "expected HEL, got " + typ}
+	}
+	var hello Hello
+	if err := Decode(body, &hello); err != nil {
+		return Limits{}, "", err
+	}
+	if hello.ReceiveBufferSize < minBufferSize || hello.SendBufferSize < minBufferSize {
+		_ = writeTransportError(conn, BadTCPMessageTooLarge, "buffer size too small")
+		return Limits{}, "", &TransportError{Status: BadTCPMessageTooLarge, Reason: "buffer size too small"}
+	}
+
+	// Отправляемый фрагмент не больше приемного буфера клиента, и наоборот
+	negotiated := limits
+	negotiated.SendBufferSize = min(limits.SendBufferSize, hello.ReceiveBufferSize)
+	negotiated.ReceiveBufferSize = min(limits.ReceiveBufferSize, hello.SendBufferSize)
+	ack := Acknowledge{
+		ReceiveBufferSize: negotiated.ReceiveBufferSize,
+		SendBufferSize:    negotiated.SendBufferSize,
+		MaxMessageSize:    limits.MaxMessageSize,
+		MaxChunkCount:     limits.MaxChunkCount,
+	}
+	if err := writeChunk(conn, MessageAcknowledge, ack); err != nil {
+		return Limits{}, "", err
+	}
+	return negotiated, hello.EndpointURL, nil
+}
+
+// SendHello выполняет клиентскую часть обмена Hello/Acknowledge.
+func SendHello(conn net.Conn, endpointURL string, limits Limits) (Limits, error) {
+	hello := Hello{
+		ReceiveBufferSize: limits.ReceiveBufferSize,
+		SendBufferSize:    limits.SendBufferSize,
+		MaxMessageSize:    limits.MaxMessageSize,
+		MaxChunkCount:     limits.MaxChunkCount,
+		EndpointURL:       endpointURL,
+	}
+	if err := writeChunk(conn, MessageHello, hello); err != nil {
+		return Limits{}, err
+	}
+	typ, _, body, err := readChunk(conn, limits.ReceiveBufferSize)
+	if err != nil {
+		return Limits{}, err
+	}
+	switch typ {
+	case MessageError:
+		return Limits{}, decodeTransportError(body)
+	case MessageAcknowledge:
+	default:
+		return Limits{}, &TransportError{Status: BadTCPMessageTypeInvalid, Reason: "expected ACK, got " + typ}
+	}
+	var ack Acknowledge
+	if err := Decode(body, &ack); err != nil {
+		return Limits{}, err
+	}
+	negotiated := limits
+	negotiated.SendBufferSize = min(limits.SendBufferSize, ack.ReceiveBufferSize)
+	negotiated.ReceiveBufferSize = min(limits.ReceiveBufferSize, ack.SendBufferSize)
+	return negotiated, nil
+}
+
+// readChunk читает один фрагмент: тип сообщения, тип фрагмента и тело после заголовка.
+func readChunk(r io.Reader, maxSize uint32) (string, byte, []byte, error) {
+	var header [headerSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return "", 0, nil, err
+	}
+	size := binary.LittleEndian.Uint32(header[4:])
+	if size < headerSize || size > maxSize {
+		return "", 0, nil, &TransportError{Status: BadTCPMessageTooLarge, Reason: fmt.Sprintf("chunk size %d", size)}
+	}
+	body := make([]byte, size-headerSize)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return "", 0, nil, err
+	}
+	return string(header[:3]), header[3], body, nil
+}
+
+// writeChunk отправляет однофрагментное сообщение HEL, ACK или ERR.
+func writeChunk(w io.Writer, typ string, v any) error {
+	body, err := Encode(v)
+	if err != nil {
+		return err
+	}
+	chunk := make([]byte, 0, headerSize+len(body))
+	chunk = append(chunk, typ...)
+	chunk = append(chunk, chunkFinal)
+	chunk = binary.LittleEndian.AppendUint32(chunk, uint32(headerSize+len(body)))
+	chunk = append(chunk, body...)
+	_, err = w.Write(chunk)
+	return err
+}
+
+func writeTransportError(w io.Writer, status StatusCode, reason string) error {
+	return writeChunk(w, MessageError, struct {
+		Status StatusCode
+		Reason string
+	}{status, reason})
+}
+
+func decodeTransportError(body []byte) error {
+	var e TransportError
+	if err := Decode(body, &e); err != nil {
+		return err
+	}
+	return &e
+}
@@ -0,0 +1,583 @@
+package ua
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IDType — вид идентификатора узла.
+type IDType byte
+
+// Виды идентификаторов узлов.
+const (
+	NodeIDNumeric IDType = iota
+	NodeIDString
+	NodeIDGUID
+	NodeIDOpaque
+)
+
+// NodeID — идентификатор узла. Значение сравнимо и может быть ключом map.
+type NodeID struct {
+	Namespace uint16
+	Type      IDType
+	Numeric   uint32 // Для NodeIDNumeric
+	Name      string // Для NodeIDString; байты для NodeIDGUID (16 байт) и NodeIDOpaque
+}
+
+// NewNumericNodeID возвращает числовой идентификатор.
+func NewNumericNodeID(ns uint16, id uint32) NodeID {
+	return NodeID{Namespace: ns, Numeric: id}
+}
+
+// NewStringNodeID возвращает строковый идентификатор.
+func NewStringNodeID(ns uint16, id string) NodeID {
+	return NodeID{Namespace: ns, Type: NodeIDString, Name: id}
+}
+
+// IsNull сообщает, что идентификатор пустой (ns=0;i=0).
+func (n NodeID) IsNull() bool {
+	return n == NodeID{}
+}
+
+// String возвращает идентификатор в текстовом виде, например "i=85" или "ns=1;s=lathe.State".
+func (n NodeID) String() string {
+	var prefix string
+	if n.Namespace != 0 {
+		prefix = "ns=" + strconv.Itoa(int(n.Namespace)) + ";"
+	}
+	switch n.Type {
+	case NodeIDString:
+		return prefix + "s=" + n.Name
+	case NodeIDGUID:
+		return prefix + "g=" + hex.EncodeToString([]byte(n.Name))
+	case NodeIDOpaque:
+		return prefix + "b=" + hex.EncodeToString([]byte(n.Name))
+	}
+	return prefix + "i=" + strconv.FormatUint(uint64(n.Numeric), 10)
+}
+
+// ParseNodeID разбирает числовой или строковый идентификатор в текстовом виде.
+func ParseNodeID(s string) (NodeID, error) {
+	var ns uint64
+	rest := s
+	if strings.HasPrefix(rest, "ns=") {
+		nsPart, id, ok := strings.Cut(rest[3:], ";")
+		if !ok {
+			return NodeID{}, fmt.Errorf("ua: invalid node id %q", s)
+		}
+		var err error
+		if ns, err = strconv.ParseUint(nsPart, 10, 16); err != nil {
+			return NodeID{}, fmt.Errorf("ua: invalid node id %q", s)
+		}
+		rest = id
+	}
+	switch {
+	case strings.HasPrefix(rest, "i="):
+		id, err := strconv.ParseUint(rest[2:], 10, 32)
+		if err != nil {
+			return NodeID{}, fmt.Errorf("ua: invalid node id %q", s)
+		}
+		return NewNumericNodeID(uint16(ns), uint32(id)), nil
+	case strings.HasPrefix(rest, "s="):
+		return NewStringNodeID(uint16(ns), rest[2:]), nil
+	}
+	return NodeID{}, fmt.Errorf("ua: unsupported node id %q", s)
+}
+
+func (n NodeID) EncodeUA(e *Encoder) {
+	n.encode(e, 0)
+}
+
+func (n *NodeID) DecodeUA(d *Decoder) {
+	if flags := n.decode(d); flags != 0 {
+		d.fail(errors.New("ua: expanded node id flags in node id"))
+	}
+}
+
+// encode кодирует идентификатор с флагами ExpandedNodeId в старших битах маски.
+func (n NodeID) encode(e *Encoder, flags byte) {
+	switch n.Type {
+	case NodeIDNumeric:
+		switch {
+		case n.Namespace == 0 && n.Numeric <= 0xFF:
+			e.Uint8(0x00 | flags)
+			e.Uint8(uint8(n.Numeric))
+		case n.Namespace <= 0xFF && n.Numeric <= 0xFFFF:
+			e.Uint8(0x01 | flags)
+			e.Uint8(uint8(n.Namespace))
+			e.Uint16(uint16(n.Numeric))
+		default:
+			e.Uint8(0x02 | flags)
+			e.Uint16(n.Namespace)
+			e.Uint32(n.Numeric)
+		}
+	case NodeIDString:
+		e.Uint8(0x03 | flags)
+		e.Uint16(n.Namespace)
+		e.String(n.Name)
+	case NodeIDGUID:
+		e.Uint8(0x04 | flags)
+		e.Uint16(n.Namespace)
+		var guid [16]byte
+		copy(guid[:], n.Name)
+		e.buf = append(e.buf, guid[:]...)
+	case NodeIDOpaque:
+		e.Uint8(0x05 | flags)
+		e.Uint16(n.Namespace)
+		e.ByteString([]byte(n.Name))
+	default:
+		e.fail(fmt.Errorf("ua: invalid node id type %d", n.Type))
+	}
+}
+
+func (n *NodeID) decode(d *Decoder) (flags byte) {
+	mask := d.Uint8()
+	*n = NodeID{}
+	switch mask & 0x3F {
+	case 0x00:
+		n.Numeric = uint32(d.Uint8())
+	case 0x01:
+		n.Namespace = uint16(d.Uint8())
+		n.Numeric = uint32(d.Uint16())
+	case 0x02:
+		n.Namespace = d.Uint16()
+		n.Numeric = d.Uint32()
+	case 0x03:
+		n.Namespace = d.Uint16()
+		n.Type = NodeIDString
+		n.Name = d.String()
+	case 0x04:
+		n.Namespace = d.Uint16()
+		n.Type = NodeIDGUID
+		n.Name = string(d.Bytes(16))
+	case 0x05:
+		n.Namespace = d.Uint16()
+		n.Type = NodeIDOpaque
+		n.Name = string(d.ByteString())
+	default:
+		d.fail(fmt.Errorf("ua: invalid node id encoding 0x%02X", mask))
+	}
+	return mask & 0xC0
+}
+
+// ExpandedNodeID — идентификатор узла, возможно, на другом сервере.
+type ExpandedNodeID struct {
+	NodeID       NodeID
+	NamespaceURI string
+	ServerIndex  uint32
+}
+
+func (n ExpandedNodeID) EncodeUA(e *Encoder) {
+	var flags byte
+	if n.NamespaceURI != "" {
+		flags |= 0x80
+	}
+	if n.ServerIndex != 0 {
+		flags |= 0x40
+	}
+	n.NodeID.encode(e, flags)
+	if n.NamespaceURI != "" {
+		e.String(n.NamespaceURI)
+	}
+	if n.ServerIndex != 0 {
+		e.Uint32(n.ServerIndex)
+	}
+}
+
+func (n *ExpandedNodeID) DecodeUA(d *Decoder) {
+	flags := n.NodeID.decode(d)
+	n.NamespaceURI = ""
+	n.ServerIndex = 0
+	if flags&0x80 != 0 {
+		n.NamespaceURI = d.String()
+	}
+	if flags&0x40 != 0 {
+		n.ServerIndex = d.Uint32()
+	}
+}
+
+// QualifiedName — имя, уточненное индексом пространства имен.
+type QualifiedName struct {
+	NamespaceIndex uint16
+	Name           string
+}
+
+// LocalizedText — текст с необязательной локалью.
+type LocalizedText struct {
+	Locale string
+	Text   string
+}
+
+// NewText возвращает LocalizedText без локали.
+func NewText(text string) LocalizedText {
+	return LocalizedText{Text: text}
+}
+
+func (t LocalizedText) EncodeUA(e *Encoder) {
+	var mask uint8
+	if t.Locale != "" {
+		mask |= 0x01
+	}
+	if t.Text != "" {
+		mask |= 0x02
+	}
+	e.Uint8(mask)
+	if t.Locale != "" {
+		e.String(t.Locale)
+	}
+	if t.Text != "" {
+		e.String(t.Text)
+	}
+}
+
+func (t *LocalizedText) DecodeUA(d *Decoder) {
+	mask := d.Uint8()
+	*t = LocalizedText{}
+	if mask&0x01 != 0 {
+		t.Locale = d.String()
+	}
+	if mask&0x02 != 0 {
+		t.Text = d.String()
+	}
+}
+
+// GUID — значение Guid в двоичном виде.
+type GUID [16]byte
+
+// Variant — значение любого встроенного типа или массив таких значений.
+// Поддерживаются bool, int8, uint8, int16, uint16, int32, uint32, int64, uint64, float32,
+// float64, string, time.Time, GUID, []byte, NodeID, ExpandedNodeID, StatusCode,
+// QualifiedName, LocalizedText, ExtensionObject, DataValue, а также срезы этих типов
+// и []Variant. Многомерные массивы не поддерживаются.
+type Variant struct {
+	Value any
+}
+
+// NewVariant возвращает Variant со значением v.
+func NewVariant(v any) Variant {
+	return Variant{Value: v}
+}
+
+var variantTypes = map[byte]reflect.Type{
+	TypeBoolean:         reflect.TypeFor[bool](),
+	TypeSByte:           reflect.TypeFor[int8](),
+	TypeByte:            reflect.TypeFor[uint8](),
+	TypeInt16:           reflect.TypeFor[int16](),
+	TypeUInt16:          reflect.TypeFor[uint16](),
+	TypeInt32:           reflect.TypeFor[int32](),
+	TypeUInt32:          reflect.TypeFor[uint32](),
+	TypeInt64:           reflect.TypeFor[int64](),
+	TypeUInt64:          reflect.TypeFor[uint64](),
+	TypeFloat:           reflect.TypeFor[float32](),
+	TypeDouble:          reflect.TypeFor[float64](),
+	TypeString:          reflect.TypeFor[string](),
+	TypeDateTime:        timeType,
+	TypeGUID:            reflect.TypeFor[GUID](),
+	TypeByteString:      reflect.TypeFor[[]byte](),
+	TypeNodeID:          reflect.TypeFor[NodeID](),
+	TypeExpandedNodeID:  reflect.TypeFor[ExpandedNodeID](),
+	TypeStatusCode:      reflect.TypeFor[StatusCode](),
+	TypeQualifiedName:   reflect.TypeFor[QualifiedName](),
+	TypeLocalizedText:   reflect.TypeFor[LocalizedText](),
+	TypeExtensionObject: reflect.TypeFor[ExtensionObject](),
+	TypeDataValue:       reflect.TypeFor[DataValue](),
+	TypeVariant:         reflect.TypeFor[Variant](),
+}
+
+var variantIDs = func() map[reflect.Type]byte {
+	ids := make(map[reflect.Type]byte, len(variantTypes))
+	for id, t := range variantTypes {
+		ids[t] = id
+	}
+	return ids
+}()
+
+// TypeOf возвращает встроенный тип значения и признак массива.
+func (v Variant) TypeOf() (id byte, array bool) {
+	if v.Value == nil {
+		return 0, false
+	}
+	t := reflect.TypeOf(v.Value)
+	if id, ok := variantIDs[t]; ok && t != variantTypes[TypeVariant] {
+		return id, false
+	}
+	if t.Kind() == reflect.Slice {
+		if id, ok := variantIDs[t.Elem()]; ok {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+func (v Variant) EncodeUA(e *Encoder) {
+	if v.Value == nil {
+		e.Uint8(0)
+		return
+	}
+	id, array := v.TypeOf()
+	if id == 0 {
+		e.fail(fmt.Errorf("ua: unsupported variant type %T", v.Value))
+		return
+	}
+	rv := reflect.ValueOf(v.Value)
+	if !array {
+		e.Uint8(id)
+		e.value(rv)
+		return
+	}
+	e.Uint8(id | 0x80)
+	e.Int32(int32(rv.Len()))
+	for i := range rv.Len() {
+		e.value(rv.Index(i))
+	}
+}
+
+func (v *Variant) DecodeUA(d *Decoder) {
+	mask := d.Uint8()
+	v.Value = nil
+	id := mask & 0x3F
+	if id == 0 {
+		return
+	}
+	t, ok := variantTypes[id]
+	if !ok {
+		d.fail(fmt.Errorf("ua: unsupported variant type %d", id))
+		return
+	}
+	if mask&0x80 == 0 {
+		if id == TypeVariant {
+			d.fail(errors.New("ua: scalar variant in variant"))
+			return
+		}
+		value := reflect.New(t).Elem()
+		d.value(value)
+		v.Value = value.Interface()
+		return
+	}
+
+	n := d.arrayLen()
+	if n < 0 {
+		n = 0
+	}
+	s := reflect.MakeSlice(reflect.SliceOf(t), n, n)
+	for i := range n {
+		d.value(s.Index(i))
+	}
+	v.Value = s.Interface()
+	if mask&0x40 != 0 {
+		// Размерности многомерного массива: значения передаются плоским массивом
+		var dims []int32
+		d.value(reflect.ValueOf(&dims).Elem())
+	}
+}
+
+// DataValue — значение атрибута с качеством и метками времени.
+type DataValue struct {
+	Value           Variant // Value.Value == nil — значения нет
+	Status          StatusCode
+	SourceTimestamp time.Time
+	ServerTimestamp time.Time
+}
+
+func (v DataValue) EncodeUA(e *Encoder) {
+	var mask uint8
+	if v.Value.Value != nil {
+		mask |= 0x01
+	}
+	if v.Status != Good {
+		mask |= 0x02
+	}
+	if !v.SourceTimestamp.IsZero() {
+		mask |= 0x04
+	}
+	if !v.ServerTimestamp.IsZero() {
+		mask |= 0x08
+	}
+	e.Uint8(mask)
+	if mask&0x01 != 0 {
+		v.Value.EncodeUA(e)
+	}
+	if mask&0x02 != 0 {
+		e.Uint32(uint32(v.Status))
+	}
+	if mask&0x04 != 0 {
+		e.Time(v.SourceTimestamp)
+	}
+	if mask&0x08 != 0 {
+		e.Time(v.ServerTimestamp)
+	}
+}
+
+func (v *DataValue) DecodeUA(d *Decoder) {
+	mask := d.Uint8()
+	*v = DataValue{}
+	if mask&0x01 != 0 {
+		v.Value.DecodeUA(d)
+	}
+	if mask&0x02 != 0 {
+		v.Status = StatusCode(d.Uint32())
+	}
+	if mask&0x04 != 0 {
+		v.SourceTimestamp = d.Time()
+	}
+	if mask&0x10 != 0 {
+		d.Uint16() // SourcePicoseconds
+	}
+	if mask&0x08 != 0 {
+		v.ServerTimestamp = d.Time()
+	}
+	if mask&0x20 != 0 {
+		d.Uint16() // ServerPicoseconds
+	}
+}
+
+// ExtensionObject — структура, закодированная вместе с идентификатором своей кодировки.
+// Зарегистрированные типы (см. Register) декодируются в Value, остальные сохраняются в Body.
+type ExtensionObject struct {
+	TypeID NodeID // Заполняется по типу Value при кодировании
+	Value  any
+	Body   []byte // Тело незарегистрированного типа
+}
+
+// NewExtensionObject возвращает ExtensionObject со значением зарегистрированного типа.
+func NewExtensionObject(v any) ExtensionObject {
+	return ExtensionObject{Value: v}
+}
+
+func (o ExtensionObject) EncodeUA(e *Encoder) {
+	if o.Value == nil {
+		o.TypeID.EncodeUA(e)
+		if o.Body == nil {
+			e.Uint8(0)
+			return
+		}
+		e.Uint8(1)
+		e.ByteString(o.Body)
+		return
+	}
+	id, ok := typeIDs[reflect.TypeOf(o.Value)]
+	if !ok {
+		e.fail(fmt.Errorf("ua: unregistered extension object type %T", o.Value))
+		return
+	}
+	id.EncodeUA(e)
+	e.Uint8(1)
+	// Длина тела записывается после его кодирования
+	start := len(e.buf)
+	e.Int32(0)
+	e.Value(o.Value)
+	binary.LittleEndian.PutUint32(e.buf[start:], uint32(len(e.buf)-start-4))
+}
+
+func (o *ExtensionObject) DecodeUA(d *Decoder) {
+	*o = ExtensionObject{}
+	o.TypeID.DecodeUA(d)
+	switch encoding := d.Uint8(); encoding {
+	case 0:
+	case 1, 2:
+		body := d.ByteString()
+		t, ok := registeredTypes[o.TypeID]
+		if !ok || encoding != 1 {
+			o.Body = body
+			return
+		}
+		value := reflect.New(t).Elem()
+		sub := NewDecoder(body)
+		sub.value(value)
+		if sub.err != nil {
+			d.fail(fmt.Errorf("ua: decode %s: %w", t.Name(), sub.err))
+			return
+		}
+		o.Value = value.Interface()
+	default:
+		d.fail(fmt.Errorf("ua: invalid extension object encoding %d", encoding))
+	}
+}
+
+// DiagnosticInfo — диагностическая информация. Сервер ее не формирует,
+// а при декодировании пропускает.
+type DiagnosticInfo struct{}
+
+func (DiagnosticInfo) EncodeUA(e *Encoder) {
+	e.Uint8(0)
+}
+
+func (*DiagnosticInfo) DecodeUA(d *Decoder) {
+	mask := d.Uint8()
+	for _, bit := range []uint8{0x01, 0x02, 0x08, 0x04} { // SymbolicId, NamespaceUri, Locale, LocalizedText
+		if mask&bit != 0 {
+			d.Int32()
+		}
+	}
+	if mask&0x10 != 0 {
+		_ = d.String() // AdditionalInfo
+	}
+	if mask&0x20 != 0 {
+		d.Uint32() // InnerStatusCode
+	}
+	if mask&0x40 != 0 {
+		var inner DiagnosticInfo
+		inner.DecodeUA(d)
+	}
+}
+
+var (
+	registeredTypes = make(map[NodeID]reflect.Type)
+	typeIDs         = make(map[reflect.Type]NodeID)
+)
+
+// Register связывает структуру v с идентификатором ее двоичной кодировки в пространстве
+// имен 0 (например, 631 для ReadRequest). Используется для ExtensionObject и сообщений служб.
+func Register(id uint32, v any) {
+	t := reflect.TypeOf(v)
+	registeredTypes[NewNumericNodeID(0, id)] = t
+	typeIDs[t] = NewNumericNodeID(0, id)
+}
+
+// EncodeMessage кодирует сообщение службы: идентификатор кодировки и тело.
+func EncodeMessage(v any) ([]byte, error) {
+	id, ok := typeIDs[reflect.TypeOf(v)]
+	if !ok {
+		return nil, fmt.Errorf("ua: unregistered message type %T", v)
+	}
+	e := &Encoder{}
+	id.EncodeUA(e)
+	e.Value(v)
+	return e.Bytes(), e.Err()
+}
+
+// DecodeMessage декодирует сообщение службы. Для незарегистрированного типа возвращается
+// ошибка UnknownMessageError с его идентификатором.
+func DecodeMessage(b []byte) (any, error) {
+	d := NewDecoder(b)
+	var id NodeID
+	id.DecodeUA(d)
+	if d.err != nil {
+		return nil, d.err
+	}
+	t, ok := registeredTypes[id]
+	if !ok {
+		return nil, &UnknownMessageError{TypeID: id}
+	}
+	value := reflect.New(t).Elem()
+	d.value(value)
+	if d.err != nil {
+		return nil, fmt.Errorf("ua: decode %s: %w", t.Name(), d.err)
+	}
+	return value.Interface(), nil
+}
+
+// UnknownMessageError — сообщение службы незарегистрированного типа.
+type UnknownMessageError struct {
+	TypeID NodeID
+}
+
+func (e *UnknownMessageError) Error() string {
+	return "ua: unknown message type " + e.TypeID.String()
+}
@@ -0,0 +1,98 @@
+package fanuc
+
+import (
+	"context"
+
+	"github.com/iwtcode/fanucAdapter/focas"
+	"github.com/iwtcode/fanucAdapter/models"
+)
+
+// PathClient выполняет чтения для одного канала (path) многоканального станка,
+// например одной из револьверных головок токарного станка.
+type PathClient struct {
+	client *Client
+	path   int16
+}
+
+// Path возвращает клиент для чтения данных канала path (1..Paths()).
+// Номер канала проверяет станок: для несуществующего канала вызовы завершаются ошибкой focas.ErrData.
+func (c *Client) Path(path int16) *PathClient {
+	return &PathClient{client: c, path: path}
+}
+
+// Paths возвращает количество каналов станка. До первого подключения возвращает 1.
+func (c *Client) Paths() int16 {
+	if info := c.GetSystemInfo(); info != nil && info.Paths > 0 {
+		return info.Paths
+	}
+	return 1
+}
+
+// Number возвращает номер канала.
+func (p *PathClient) Number() int16 {
+	return p.path
+}
+
+func (p *PathClient) ctx(ctx context.Context) context.Context {
+	return focas.WithPath(ctx, p.path)
+}
+
+// GetMachineState возвращает состояние канала.
+func (p *PathClient) GetMachineState() (*models.UnifiedMachineData, error) {
+	return p.GetMachineStateCtx(context.Background())
+}
+
+// GetMachineStateCtx — вариант GetMachineState с поддержкой отмены и дедлайна через ctx.
+func (p *PathClient) GetMachineStateCtx(ctx context.Context) (*models.UnifiedMachineData, error) {
+	return p.client.GetMachineStateCtx(p.ctx(ctx))
+}
+
+// GetAxisData возвращает информацию об осях канала.
+func (p *PathClient) GetAxisData() ([]models.AxisInfo, error) {
+	return p.GetAxisDataCtx(context.Background())
+}
+
+// GetAxisDataCtx — вариант GetAxisData с поддержкой отмены и дедлайна через ctx.
+func (p *PathClient) GetAxisDataCtx(ctx context.Context) ([]models.AxisInfo, error) {
+	return p.client.GetAxisDataCtx(p.ctx(ctx))
+}
+
+// GetSpindleData возвращает информацию о шпинделях канала.
+func (p *PathClient) GetSpindleData() ([]models.SpindleInfo, error) {
+	return p.GetSpindleDataCtx(context.Background())
+}
+
+// GetSpindleDataCtx — вариант GetSpindleData с поддержкой отмены и дедлайна через ctx.
+func (p *PathClient) GetSpindleDataCtx(ctx context.Context) ([]models.SpindleInfo, error) {
+	return p.client.GetSpindleDataCtx(p.ctx(ctx))
+}
+
+// GetProgramInfo возвращает информацию о программе, выполняемой в канале.
+func (p *PathClient) GetProgramInfo() (*models.ProgramInfo, error) {
+	return p.GetProgramInfoCtx(context.Background())
+}
+
+// GetProgramInfoCtx — вариант GetProgramInfo с поддержкой отмены и дедлайна через ctx.
+func (p *PathClient) GetProgramInfoCtx(ctx context.Context) (*models.ProgramInfo, error) {
+	return p.client.GetProgramInfoCtx(p.ctx(ctx))
+}
+
+// GetControlProgram возвращает полный G-код программы, выполняемой в канале.
+func (p *PathClient) GetControlProgram() (string, error) {
+	return p.GetControlProgramCtx(context.Background())
+}
+
+// GetControlProgramCtx — вариант GetControlProgram с поддержкой отмены и дедлайна через ctx.
+func (p *PathClient) GetControlProgramCtx(ctx context.Context) (string, error) {
+	return p.client.GetControlProgramCtx(p.ctx(ctx))
+}
+
+// GetAlarms возвращает список активных ошибок канала.
+func (p *PathClient) GetAlarms() ([]models.AlarmDetail, error) {
+	return p.GetAlarmsCtx(context.Background())
+}
+
+// GetAlarmsCtx — вариант GetAlarms с поддержкой отмены и дедлайна через ctx.
+func (p *PathClient) GetAlarmsCtx(ctx context.Context) ([]models.AlarmDetail, error) {
+	return p.client.GetAlarmsCtx(p.ctx(ctx))
+}
@@ -0,0 +1,89 @@
+// Package kafkatest содержит брокер Kafka в памяти для проверки публикации без Kafka.
+package kafkatest
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/iwtcode/fanucAdapter/publish/kafka"
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+// ErrUnavailable возвращается WriteMessages, пока брокер остановлен (см. SetDown).
+var ErrUnavailable = errors.New("kafkatest: broker unavailable")
+
+// Broker — брокер в памяти, реализующий kafka.Writer. Как и Kafka из docker-compose.yml,
+// он принимает сообщения только в существующие топики.
+type Broker struct {
+	mu     sync.Mutex
+	topics map[string][]kafka.Message
+	down   bool
+	writes int
+}
+
+// NewBroker создает брокер с топиками topics.
+func NewBroker(topics ...string) *Broker {
+	b := &Broker{topics: make(map[string][]kafka.Message)}
+	for _, topic := range topics {
+		b.topics[topic] = nil
+	}
+	return b
+}
+
+// SetDown останавливает (true) или запускает (false) брокер. Остановленный брокер
+// отклоняет все отправки с ErrUnavailable, сохраненные сообщения не теряются.
+func (b *Broker) SetDown(down bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.down = down
+}
+
+// WriteMessages сохраняет сообщения. Сообщения в несуществующие топики отклоняются
+// ошибкой kafkago.UnknownTopicOrPartition в составе kafkago.WriteErrors.
+func (b *Broker) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.writes++
+	if b.down {
+		return ErrUnavailable
+	}
+
+	errs := make(kafkago.WriteErrors, len(msgs))
+	failed := false
+	for i, msg := range msgs {
+		if _, ok := b.topics[msg.Topic]; !ok {
+			errs[i] = kafkago.UnknownTopicOrPartition
+			failed = true
+			continue
+		}
+		msg.Offset = int64(len(b.topics[msg.Topic]))
+		b.topics[msg.Topic] = append(b.topics[msg.Topic], msg)
+	}
+	if failed {
+		return errs
+	}
+	return nil
+}
+
+// Close ничего не делает: сообщения остаются доступными через Messages.
+func (b *Broker) Close() error {
+	return nil
+}
+
+// Messages возвращает сообщения топика в порядке записи.
+func (b *Broker) Messages(topic string) []kafka.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]kafka.Message(nil), b.topics[topic]...)
+}
+
+// Writes возвращает количество вызовов WriteMessages, включая неудачные.
+func (b *Broker) Writes() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.writes
+}
@@ -0,0 +1,483 @@
+// Package kafka публикует снимки данных станка (AggregatedData) и события изменений
+// (MachineEvent) в Kafka. Ключ сообщения — MachineID, поэтому все сообщения одного станка
+// попадают в одну партицию и читаются в порядке публикации.
+//
+// Сообщения сначала помещаются в ограниченную очередь в памяти, а фоновая горутина
+// отправляет их пачками с подтверждением от всех реплик. Если брокер недоступен
+// (например, перезапускается), отправка повторяется с экспоненциальной паузой,
+// пока сообщения не будут подтверждены: доставка выполняется как минимум один раз,
+// повторы возможны. При переполнении очереди вытесняются самые старые сообщения.
+// Неотправленные сообщения хранятся только в памяти и теряются при остановке процесса.
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	fanucv1 "github.com/iwtcode/fanucAdapter/api/fanuc/v1"
+	"github.com/iwtcode/fanucAdapter/models"
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+)
+
+// Message — сообщение Kafka.
+type Message = kafkago.Message
+
+// Writer отправляет сообщения в Kafka. WriteMessages возвращает управление после
+// подтверждения брокером; при частичной ошибке возвращается kafkago.WriteErrors
+// с ошибкой для каждого сообщения. Ему удовлетворяет *kafkago.Writer,
+// а для тестов — kafkatest.Broker.
+type Writer interface {
+	WriteMessages(ctx context.Context, msgs ...Message) error
+	Close() error
+}
+
+// Encoding — формат значений сообщений.
+type Encoding string
+
+// Форматы значений сообщений.
+const (
+	EncodingJSON     Encoding = "json"     // JSON, как в HTTP API
+	EncodingProtobuf Encoding = "protobuf" // fanuc.v1.MachineData и fanuc.v1.MachineEvent (api/fanuc/v1)
+)
+
+// Заголовки сообщений.
+const (
+	HeaderContentType = "content-type" // application/json или application/x-protobuf
+	HeaderMessageType = "message-type" // Полное имя сообщения схемы, например fanuc.v1.MachineData
+	HeaderEventType   = "event-type"   // Тип события (models.EventType) для событий
+)
+
+// ErrClosed возвращается при публикации после Close.
+var ErrClosed = errors.New("kafka publisher closed")
+
+// Config задает параметры публикации. Нулевые значения заменяются значениями по умолчанию.
+type Config struct {
+	// Brokers — адреса брокеров, например "localhost:9092". Не используется в NewWithWriter.
+	Brokers []string
+	// SnapshotTopic — топик снимков данных. По умолчанию "mtconnect_data".
+	SnapshotTopic string
+	// EventTopic — топик событий изменений. По умолчанию "machine_events".
+	EventTopic string
+	// Encoding — формат значений. По умолчанию EncodingJSON.
+	Encoding Encoding
+	// Compression — сжатие пачек: "none", "gzip", "snappy", "lz4" или "zstd".
+	// По умолчанию "snappy". Не используется в NewWithWriter.
+	Compression string
+	// BatchSize — наибольшее количество сообщений в одной отправке. По умолчанию 100.
+	BatchSize int
+	// BatchTimeout — наибольшее время накопления пачки. По умолчанию 100 мс.
+	BatchTimeout time.Duration
+	// BufferSize — емкость очереди неотправленных сообщений. По умолчанию 10000.
+	BufferSize int
+	// RetryBackoff и MaxRetryBackoff — начальная и наибольшая пауза между повторами
+	// отправки. По умолчанию 500 мс и 30 секунд.
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	// CloseTimeout — время на отправку оставшихся сообщений при Close. По умолчанию 10 секунд.
+	CloseTimeout time.Duration
+	// Logger — журнал ошибок отправки. По умолчанию журнал не ведется.
+	Logger *logrus.Logger
+}
+
+func (c *Config) setDefaults() {
+	if c.SnapshotTopic == "" {
+		c.SnapshotTopic = "mtconnect_data"
+	}
+	if c.EventTopic == "" {
+		c.EventTopic = "machine_events"
+	}
+	if c.Encoding == "" {
+		c.Encoding = EncodingJSON
+	}
+	if c.Compression == "" {
+		c.Compression = "snappy"
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+	}
+	if c.BatchTimeout <= 0 {
+		c.BatchTimeout = 100 * time.Millisecond
+	}
+	if c.BufferSize <= 0 {
+		c.BufferSize = 10000
+	}
+	if c.RetryBackoff <= 0 {
+		c.RetryBackoff = 500 * time.Millisecond
+	}
+	if c.MaxRetryBackoff < c.RetryBackoff {
+		c.MaxRetryBackoff = max(30*time.Second, c.RetryBackoff)
+	}
+	if c.CloseTimeout <= 0 {
+		c.CloseTimeout = 10 * time.Second
+	}
+	if c.Logger == nil {
+		c.Logger = logrus.New()
+		c.Logger.SetOutput(io.Discard)
+	}
+}
+
+func (c *Config) validate() error {
+	switch c.Encoding {
+	case EncodingJSON, EncodingProtobuf:
+	default:
+		return fmt.Errorf("unknown encoding %q", c.Encoding)
+	}
+	return nil
+}
+
+// Stats — счетчики публикации.
+type Stats struct {
+	Pending   int    // Сообщения в очереди и в текущей отправке
+	Delivered uint64 // Подтвержденные брокером сообщения
+	Dropped   uint64 // Вытесненные из очереди или отклоненные брокером сообщения
+	Retries   uint64 // Неудачные попытки отправки
+	LastError string // Последняя ошибка отправки
+}
+
+// Publisher публикует сообщения в Kafka. Методы безопасны для одновременного вызова.
+type Publisher struct {
+	cfg    Config
+	writer Writer
+
+	mu       sync.Mutex
+	queue    []Message
+	inflight int
+	stats    Stats
+	closing  bool
+
+	notify chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New создает публикатор с подключением к cfg.Brokers. Топики должны существовать:
+// автоматическое создание топиков отключено.
+func New(cfg Config) (*Publisher, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, errors.New("kafka: no brokers")
+	}
+	cfg.setDefaults()
+	compression, err := parseCompression(cfg.Compression)
+	if err != nil {
+		return nil, err
+	}
+	w := &kafkago.Writer{
+		Addr:         kafkago.TCP(cfg.Brokers...),
+		Balancer:     &kafkago.Hash{},
+		RequiredAcks: kafkago.RequireAll,
+		Compression:  compression,
+		BatchSize:    cfg.BatchSize,
+		BatchTimeout: cfg.BatchTimeout,
+		// Повторы выполняет Publisher без ограничения количества попыток
+		MaxAttempts: 1,
+		ErrorLogger: kafkago.LoggerFunc(cfg.Logger.Errorf),
+	}
+	return NewWithWriter(cfg, w)
+}
+
+// NewWithWriter создает публикатор, отправляющий сообщения через w.
+// Publisher закрывает w в Close.
+func NewWithWriter(cfg Config, w Writer) (*Publisher, error) {
+	cfg.setDefaults()
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("kafka: %w", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Publisher{
+		cfg:    cfg,
+		writer: w,
+		notify: make(chan struct{}, 1),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go p.loop()
+	return p, nil
+}
+
+// PublishSnapshot ставит снимок data в очередь на отправку в SnapshotTopic.
+func (p *Publisher) PublishSnapshot(data *models.AggregatedData) error {
+	if data == nil {
+		return errors.New("kafka: nil snapshot")
+	}
+	var msg proto.Message
+	if p.cfg.Encoding == EncodingProtobuf {
+		msg = fanucv1.FromMachineData(data)
+	}
+	value, err := p.encode(data, msg)
+	if err != nil {
+		return fmt.Errorf("kafka: encode snapshot: %w", err)
+	}
+	return p.enqueue(Message{
+		Topic: p.cfg.SnapshotTopic,
+		Key:   []byte(data.MachineID),
+		Value: value,
+		Headers: []kafkago.Header{
+			{Key: HeaderContentType, Value: []byte(p.contentType())},
+			{Key: HeaderMessageType, Value: []byte("fanuc.v1.MachineData")},
+		},
+		Time: data.Timestamp,
+	})
+}
+
+// PublishEvent ставит событие e в очередь на отправку в EventTopic.
+func (p *Publisher) PublishEvent(e models.MachineEvent) error {
+	var msg proto.Message
+	if p.cfg.Encoding == EncodingProtobuf {
+		event, err := fanucv1.FromMachineEvent(e)
+		if err != nil {
+			return fmt.Errorf("kafka: encode event: %w", err)
+		}
+		msg = event
+	}
+	value, err := p.encode(e, msg)
+	if err != nil {
+		return fmt.Errorf("kafka: encode event: %w", err)
+	}
+	return p.enqueue(Message{
+		Topic: p.cfg.EventTopic,
+		Key:   []byte(e.MachineID),
+		Value: value,
+		Headers: []kafkago.Header{
+			{Key: HeaderContentType, Value: []byte(p.contentType())},
+			{Key: HeaderMessageType, Value: []byte("fanuc.v1.MachineEvent")},
+			{Key: HeaderEventType, Value: []byte(e.Type)},
+		},
+		Time: e.Timestamp,
+	})
+}
+
+// Run опрашивает станок client (см. fanuc.Client.Subscribe) до отмены ctx или закрытия
+// клиента и публикует каждый снимок и события изменений между снимками
+// (см. fanuc.EventDetector).
+func (p *Publisher) Run(ctx context.Context, client *fanuc.Client, opts fanuc.SubscribeOptions) error {
+	snapshots, err := client.Subscribe(ctx, opts)
+	if err != nil {
+		return err
+	}
+	detector := fanuc.NewEventDetector()
+	for s := range snapshots {
+		if s.Data != nil {
+			if err := p.PublishSnapshot(s.Data); err != nil {
+				return err
+			}
+		}
+		for _, e := range detector.Detect(s) {
+			if err := p.PublishEvent(e); err != nil {
+				return err
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// Stats возвращает счетчики публикации.
+func (p *Publisher) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := p.stats
+	stats.Pending = len(p.queue) + p.inflight
+	return stats
+}
+
+// Close прекращает прием сообщений, отправляет оставшиеся в течение CloseTimeout
+// и закрывает соединение с брокером. Если отправить все сообщения не удалось,
+// возвращается ошибка с их количеством.
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	if p.closing {
+		p.mu.Unlock()
+		<-p.done
+		return nil
+	}
+	p.closing = true
+	p.mu.Unlock()
+	p.wake()
+
+	timer := time.NewTimer(p.cfg.CloseTimeout)
+	defer timer.Stop()
+	select {
+	case <-p.done:
+	case <-timer.C:
+		p.cancel()
+		<-p.done
+	}
+	p.cancel()
+
+	err := p.writer.Close()
+	if pending := p.Stats().Pending; pending > 0 {
+		return fmt.Errorf("kafka: %d messages not delivered", pending)
+	}
+	return err
+}
+
+func (p *Publisher) encode(v any, msg proto.Message) ([]byte, error) {
+	if msg != nil {
+		return proto.Marshal(msg)
+	}
+	return json.Marshal(v)
+}
+
+func (p *Publisher) contentType() string {
+	if p.cfg.Encoding == EncodingProtobuf {
+		return "application/x-protobuf"
+	}
+	return "application/json"
+}
+
+// enqueue добавляет сообщение в очередь, вытесняя самое старое при переполнении.
+func (p *Publisher) enqueue(msg Message) error {
+	p.mu.Lock()
+	if p.closing {
+		p.mu.Unlock()
+		return ErrClosed
+	}
+	p.queue = append(p.queue, msg)
+	p.trim()
+	p.mu.Unlock()
+	p.wake()
+	return nil
+}
+
+// trim вытесняет самые старые сообщения сверх BufferSize. Вызывается под p.mu.
+func (p *Publisher) trim() {
+	if over := len(p.queue) + p.inflight - p.cfg.BufferSize; over > 0 {
+		over = min(over, len(p.queue))
+		clear(p.queue[:over])
+		p.queue = p.queue[over:]
+		p.stats.Dropped += uint64(over)
+	}
+}
+
+func (p *Publisher) wake() {
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+}
+
+// loop отправляет сообщения из очереди, пока публикатор не закрыт и очередь не пуста.
+func (p *Publisher) loop() {
+	defer close(p.done)
+	var backoff time.Duration
+	for {
+		batch := p.next()
+		if batch == nil {
+			return
+		}
+		err := p.writer.WriteMessages(p.ctx, batch...)
+		p.complete(batch, err)
+		if err == nil {
+			backoff = 0
+			continue
+		}
+		if p.ctx.Err() != nil {
+			return
+		}
+
+		backoff = min(max(2*backoff, p.cfg.RetryBackoff), p.cfg.MaxRetryBackoff)
+		p.cfg.Logger.Warnf("Kafka: write failed (%v), retry in %s", err, backoff)
+		select {
+		case <-time.After(backoff):
+		case <-p.ctx.Done():
+			return
+		}
+	}
+}
+
+// next ожидает сообщения и забирает из очереди очередную пачку. Возвращает nil,
+// когда публикатор закрыт и очередь пуста или отправка прервана.
+func (p *Publisher) next() []Message {
+	for {
+		p.mu.Lock()
+		if n := min(len(p.queue), p.cfg.BatchSize); n > 0 {
+			batch := make([]Message, n)
+			copy(batch, p.queue)
+			clear(p.queue[:n])
+			p.queue = p.queue[n:]
+			p.inflight = n
+			p.mu.Unlock()
+			return batch
+		}
+		closing := p.closing
+		p.mu.Unlock()
+		if closing {
+			return nil
+		}
+		select {
+		case <-p.notify:
+		case <-p.ctx.Done():
+			return nil
+		}
+	}
+}
+
+// complete учитывает результат отправки пачки: неподтвержденные сообщения возвращаются
+// в начало очереди, отклоненные брокером без возможности повтора — отбрасываются.
+func (p *Publisher) complete(batch []Message, err error) {
+	var retry []Message
+	var rejected uint64
+	var writeErrs kafkago.WriteErrors
+	switch {
+	case err == nil:
+	case errors.As(err, &writeErrs) && len(writeErrs) == len(batch):
+		for i, e := range writeErrs {
+			switch {
+			case e == nil:
+			case permanent(e):
+				rejected++
+				p.cfg.Logger.Errorf("Kafka: message for %s rejected: %v", batch[i].Topic, e)
+			default:
+				retry = append(retry, batch[i])
+			}
+		}
+	default:
+		retry = batch
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.inflight = 0
+	p.stats.Delivered += uint64(len(batch)-len(retry)) - rejected
+	p.stats.Dropped += rejected
+	if err != nil {
+		p.stats.Retries++
+		p.stats.LastError = err.Error()
+	}
+	if len(retry) > 0 {
+		p.queue = append(retry, p.queue...)
+		p.trim()
+	}
+}
+
+// permanent сообщает, что брокер отклонил сообщение и повтор не поможет
+// (например, сообщение слишком велико).
+func permanent(err error) bool {
+	var kerr kafkago.Error
+	return errors.As(err, &kerr) && !kerr.Temporary()
+}
+
+func parseCompression(name string) (kafkago.Compression, error) {
+	switch name {
+	case "none":
+		return 0, nil
+	case "gzip":
+		return kafkago.Gzip, nil
+	case "snappy":
+		return kafkago.Snappy, nil
+	case "lz4":
+		return kafkago.Lz4, nil
+	case "zstd":
+		return kafkago.Zstd, nil
+	}
+	return 0, fmt.Errorf("kafka: unknown compression %q", name)
+}
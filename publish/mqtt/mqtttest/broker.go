@@ -0,0 +1,155 @@
+// Package mqtttest содержит брокер MQTT в памяти для проверки публикации без брокера.
+package mqtttest
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/iwtcode/fanucAdapter/publish/mqtt"
+)
+
+// ErrDisconnected возвращается при публикации через разорванное соединение (см. Drop).
+var ErrDisconnected = errors.New("mqtttest: not connected")
+
+// Broker — брокер в памяти. Хранит все сообщения и последние сообщения с флагом retained,
+// публикует завещания разорванных соединений. Подписки поддерживают только точное
+// совпадение топика, без "+" и "#".
+type Broker struct {
+	mu       sync.Mutex
+	messages []mqtt.Message
+	retained map[string]mqtt.Message
+	conns    []*conn
+}
+
+// NewBroker создает пустой брокер.
+func NewBroker() *Broker {
+	return &Broker{retained: make(map[string]mqtt.Message)}
+}
+
+// Dial подключается к брокеру; подходит для mqtt.Config.Dial.
+func (b *Broker) Dial(will func() mqtt.Message, onReconnect func()) (mqtt.Conn, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := &conn{broker: b, will: will, session: will(), onReconnect: onReconnect, subs: make(map[string]func(mqtt.Message))}
+	b.conns = append(b.conns, c)
+	return c, nil
+}
+
+// Publish публикует сообщение от имени другого клиента, например команду NCMD.
+func (b *Broker) Publish(msg mqtt.Message) {
+	b.deliver(msg)
+}
+
+// Drop разрывает все соединения без штатного отключения: брокер публикует их завещания.
+func (b *Broker) Drop() {
+	b.mu.Lock()
+	var wills []mqtt.Message
+	for _, c := range b.conns {
+		if !c.down && !c.closed {
+			c.down = true
+			wills = append(wills, c.session)
+		}
+	}
+	b.mu.Unlock()
+	for _, will := range wills {
+		b.deliver(will)
+	}
+}
+
+// Reconnect восстанавливает разорванные соединения с завещанием новой сессии
+// и вызывает их onReconnect.
+func (b *Broker) Reconnect() {
+	b.mu.Lock()
+	var restored []*conn
+	for _, c := range b.conns {
+		if c.down && !c.closed {
+			c.down = false
+			c.session = c.will()
+			restored = append(restored, c)
+		}
+	}
+	b.mu.Unlock()
+	for _, c := range restored {
+		c.onReconnect()
+	}
+}
+
+// Retained возвращает последнее сообщение топика с флагом retained.
+func (b *Broker) Retained(topic string) (mqtt.Message, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	msg, ok := b.retained[topic]
+	return msg, ok
+}
+
+// Messages возвращает все сообщения топика в порядке публикации.
+func (b *Broker) Messages(topic string) []mqtt.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var result []mqtt.Message
+	for _, msg := range b.messages {
+		if msg.Topic == topic {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
+// deliver сохраняет сообщение и передает его подписчикам вне блокировки.
+func (b *Broker) deliver(msg mqtt.Message) {
+	b.mu.Lock()
+	b.messages = append(b.messages, msg)
+	if msg.Retained {
+		if len(msg.Payload) == 0 {
+			delete(b.retained, msg.Topic)
+		} else {
+			b.retained[msg.Topic] = msg
+		}
+	}
+	var handlers []func(mqtt.Message)
+	for _, c := range b.conns {
+		if handler, ok := c.subs[msg.Topic]; ok && !c.down && !c.closed {
+			handlers = append(handlers, handler)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(msg)
+	}
+}
+
+type conn struct {
+	broker      *Broker
+	will        func() mqtt.Message
+	session     mqtt.Message // Завещание текущей сессии
+	onReconnect func()
+	subs        map[string]func(mqtt.Message)
+	down        bool
+	closed      bool
+}
+
+func (c *conn) Publish(msg mqtt.Message) error {
+	c.broker.mu.Lock()
+	ok := !c.down && !c.closed
+	c.broker.mu.Unlock()
+	if !ok {
+		return ErrDisconnected
+	}
+	c.broker.deliver(msg)
+	return nil
+}
+
+func (c *conn) Subscribe(topic string, _ byte, handler func(mqtt.Message)) error {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+	c.subs[topic] = handler
+	return nil
+}
+
+func (c *conn) Disconnect() error {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+	c.closed = true
+	return nil
+}
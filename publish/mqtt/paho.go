@@ -0,0 +1,171 @@
+package mqtt
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+)
+
+// Интервал переподключения к брокеру растет от reconnectMinInterval до reconnectMaxInterval.
+const (
+	reconnectMinInterval = time.Second
+	reconnectMaxInterval = time.Minute
+)
+
+// pahoConn — соединение Conn на основе Eclipse Paho. Автоматическое переподключение Paho
+// не используется: завещание задается при подключении, а каждая новая сессия должна
+// получить свое (в Sparkplug — NDEATH со следующим bdSeq). Поэтому после потери связи
+// создается новый клиент с новым завещанием.
+type pahoConn struct {
+	cfg         Config
+	will        func() Message
+	onReconnect func()
+
+	mu     sync.Mutex
+	client paho.Client
+	subs   map[string]subscription // Подписки, восстанавливаемые после переподключения
+	closed bool
+	done   chan struct{} // Закрывается при Disconnect
+}
+
+type subscription struct {
+	qos     byte
+	handler func(Message)
+}
+
+// pahoDialer возвращает Dialer, подключающийся к cfg.Broker.
+func pahoDialer(cfg Config) Dialer {
+	return func(will func() Message, onReconnect func()) (Conn, error) {
+		c := &pahoConn{
+			cfg:         cfg,
+			will:        will,
+			onReconnect: onReconnect,
+			subs:        make(map[string]subscription),
+			done:        make(chan struct{}),
+		}
+		client, err := c.connect()
+		if err != nil {
+			return nil, err
+		}
+		c.client = client
+		return c, nil
+	}
+}
+
+// connect подключает нового клиента Paho с завещанием новой сессии.
+func (c *pahoConn) connect() (paho.Client, error) {
+	will := c.will()
+	opts := paho.NewClientOptions().
+		AddBroker(c.cfg.Broker).
+		SetClientID(c.cfg.ClientID).
+		SetUsername(c.cfg.Username).
+		SetPassword(c.cfg.Password).
+		SetBinaryWill(will.Topic, will.Payload, will.QoS, will.Retained).
+		SetConnectTimeout(c.cfg.ConnectTimeout).
+		SetAutoReconnect(false).
+		SetConnectionLostHandler(func(_ paho.Client, err error) {
+			c.cfg.Logger.Warnf("MQTT: connection to %s lost: %v", c.cfg.Broker, err)
+			go c.reconnect()
+		})
+
+	client := paho.NewClient(opts)
+	if err := c.wait(client.Connect()); err != nil {
+		client.Disconnect(0)
+		return nil, err
+	}
+	return client, nil
+}
+
+// reconnect подключается заново, пока это не удастся или соединение не будет закрыто,
+// затем восстанавливает подписки и вызывает onReconnect.
+func (c *pahoConn) reconnect() {
+	interval := reconnectMinInterval
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-time.After(interval):
+		}
+
+		client, err := c.connect()
+		if err != nil {
+			c.cfg.Logger.Warnf("MQTT: reconnect to %s failed: %v", c.cfg.Broker, err)
+			interval = min(interval*2, reconnectMaxInterval)
+			continue
+		}
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			client.Disconnect(250)
+			return
+		}
+		c.client = client
+		c.mu.Unlock()
+
+		if err := c.resubscribe(); err != nil {
+			c.cfg.Logger.Warnf("MQTT: resubscribe failed: %v", err)
+		}
+		c.onReconnect()
+		return
+	}
+}
+
+func (c *pahoConn) Publish(msg Message) error {
+	return c.wait(c.current().Publish(msg.Topic, msg.QoS, msg.Retained, msg.Payload))
+}
+
+func (c *pahoConn) Subscribe(topic string, qos byte, handler func(Message)) error {
+	c.mu.Lock()
+	c.subs[topic] = subscription{qos: qos, handler: handler}
+	c.mu.Unlock()
+	return c.subscribe(topic, qos, handler)
+}
+
+func (c *pahoConn) Disconnect() error {
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.done)
+	}
+	client := c.client
+	c.mu.Unlock()
+	client.Disconnect(250)
+	return nil
+}
+
+// current возвращает клиента текущей сессии.
+func (c *pahoConn) current() paho.Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.client
+}
+
+func (c *pahoConn) subscribe(topic string, qos byte, handler func(Message)) error {
+	return c.wait(c.current().Subscribe(topic, qos, func(_ paho.Client, m paho.Message) {
+		handler(Message{Topic: m.Topic(), Payload: m.Payload(), QoS: m.Qos(), Retained: m.Retained()})
+	}))
+}
+
+// resubscribe восстанавливает подписки: сессия после переподключения начинается заново.
+func (c *pahoConn) resubscribe() error {
+	c.mu.Lock()
+	subs := maps.Clone(c.subs)
+	c.mu.Unlock()
+
+	var errs []error
+	for topic, s := range subs {
+		errs = append(errs, c.subscribe(topic, s.qos, s.handler))
+	}
+	return errors.Join(errs...)
+}
+
+func (c *pahoConn) wait(token paho.Token) error {
+	if !token.WaitTimeout(c.cfg.ConnectTimeout) {
+		return fmt.Errorf("timeout after %s", c.cfg.ConnectTimeout)
+	}
+	return token.Error()
+}
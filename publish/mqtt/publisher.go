@@ -0,0 +1,416 @@
+// Package mqtt публикует данные станка в брокер MQTT.
+//
+// В обычном режиме данные публикуются в отдельные топики станка с флагом retained,
+// поэтому новый подписчик сразу получает последние значения:
+//
+//	<prefix>/<machine>/status    online или offline
+//	<prefix>/<machine>/state     состояние и режим станка
+//	<prefix>/<machine>/axes      данные осей
+//	<prefix>/<machine>/spindles  данные шпинделей
+//	<prefix>/<machine>/alarms    активные тревоги
+//	<prefix>/<machine>/program   выполняемая программа
+//
+// Топик status задан завещанием (LWT) соединения: если публикатор пропадет без отключения,
+// брокер сам опубликует offline. Значения публикуются только при изменении.
+//
+// В режиме Sparkplug B (Config.Sparkplug) публикатор — узел (edge node) Sparkplug
+// с одним устройством-станком: NBIRTH/NDEATH описывают соединение с брокером,
+// DBIRTH/DDATA/DDEATH — станок и его соединение по FOCAS (см. sparkplug.go).
+package mqtt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	"github.com/iwtcode/fanucAdapter/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Message — сообщение MQTT.
+type Message struct {
+	Topic    string
+	Payload  []byte
+	QoS      byte
+	Retained bool
+}
+
+// Conn — соединение с брокером MQTT. Соединение восстанавливается само: новая сессия
+// получает новое завещание и прежние подписки.
+type Conn interface {
+	Publish(msg Message) error
+	Subscribe(topic string, qos byte, handler func(Message)) error
+	// Disconnect закрывает соединение штатно: завещание брокером не публикуется.
+	Disconnect() error
+}
+
+// Dialer подключается к брокеру. will вызывается перед каждым подключением, включая
+// переподключения, и возвращает завещание новой сессии. onReconnect вызывается после
+// каждого восстановления соединения (но не после первого подключения).
+type Dialer func(will func() Message, onReconnect func()) (Conn, error)
+
+// Статусы станка в топике status.
+const (
+	StatusOnline  = "online"
+	StatusOffline = "offline"
+)
+
+// ErrClosed возвращается при публикации после Close.
+var ErrClosed = errors.New("mqtt publisher closed")
+
+// Config задает параметры публикации.
+type Config struct {
+	// Broker — адрес брокера, например "tcp://localhost:1883". Не используется с Dial.
+	Broker   string
+	ClientID string // По умолчанию "fanucAdapter-<MachineID>"
+	Username string
+	Password string
+	// ConnectTimeout — время ожидания подключения к брокеру. По умолчанию 10 секунд.
+	ConnectTimeout time.Duration
+
+	// MachineID — идентификатор станка в топиках. Символы "/", "+" и "#" заменяются на "_".
+	MachineID string
+	// TopicPrefix — начало топиков обычного режима. По умолчанию "fanuc".
+	TopicPrefix string
+	// QoS — качество обслуживания публикаций обычного режима (0, 1 или 2).
+	QoS byte
+
+	// Sparkplug включает режим Sparkplug B вместо обычных топиков.
+	Sparkplug *SparkplugConfig
+
+	// Dial подключается к брокеру. По умолчанию используется Eclipse Paho с Broker,
+	// ClientID, Username и Password.
+	Dial Dialer
+	// Logger — журнал ошибок публикации. По умолчанию журнал не ведется.
+	Logger *logrus.Logger
+}
+
+func (c *Config) setDefaults() {
+	if c.TopicPrefix == "" {
+		c.TopicPrefix = "fanuc"
+	}
+	if c.ClientID == "" {
+		c.ClientID = "fanucAdapter-" + topicID(c.MachineID)
+	}
+	if c.ConnectTimeout <= 0 {
+		c.ConnectTimeout = 10 * time.Second
+	}
+	if c.Sparkplug != nil {
+		sp := *c.Sparkplug
+		sp.setDefaults(c.MachineID)
+		c.Sparkplug = &sp
+	}
+	if c.Logger == nil {
+		c.Logger = logrus.New()
+		c.Logger.SetOutput(io.Discard)
+	}
+}
+
+// Publisher публикует данные одного станка. Методы безопасны для одновременного вызова.
+type Publisher struct {
+	cfg  Config
+	conn Conn
+
+	mu     sync.Mutex
+	online bool
+	closed bool
+	info   *models.SystemInfo
+	data   *models.AggregatedData // Последний снимок
+	keys   map[string]string      // Содержимое последних публикаций по топикам без времени
+	retain map[string][]byte      // Последние публикации по топикам
+	sp     *sparkplug             // nil в обычном режиме
+}
+
+// New подключается к брокеру и публикует статус offline (в режиме Sparkplug — NBIRTH).
+// Станок считается недоступным, пока не вызван SetOnline(true).
+func New(cfg Config) (*Publisher, error) {
+	if cfg.MachineID == "" {
+		return nil, errors.New("mqtt: empty machine ID")
+	}
+	if cfg.QoS > 2 {
+		return nil, fmt.Errorf("mqtt: invalid QoS %d", cfg.QoS)
+	}
+	cfg.setDefaults()
+	if cfg.Dial == nil {
+		if cfg.Broker == "" {
+			return nil, errors.New("mqtt: no broker")
+		}
+		cfg.Dial = pahoDialer(cfg)
+	}
+
+	p := &Publisher{
+		cfg:    cfg,
+		keys:   make(map[string]string),
+		retain: make(map[string][]byte),
+	}
+	var will func() Message
+	if cfg.Sparkplug != nil {
+		p.sp = newSparkplug(*cfg.Sparkplug)
+		will = p.sp.session
+	} else {
+		offline := p.message(p.topic("status"), []byte(StatusOffline))
+		will = func() Message { return offline }
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	conn, err := cfg.Dial(will, p.reconnected)
+	if err != nil {
+		return nil, fmt.Errorf("mqtt: connect: %w", err)
+	}
+	p.conn = conn
+
+	if p.sp != nil {
+		err = p.sp.start(p)
+	} else {
+		err = p.publish(p.topic("status"), []byte(StatusOffline))
+	}
+	if err != nil {
+		conn.Disconnect()
+		return nil, err
+	}
+	return p, nil
+}
+
+// SetOnline сообщает, доступен ли станок. В обычном режиме публикуется статус,
+// в режиме Sparkplug при потере станка публикуется DDEATH, а после восстановления
+// следующий снимок публикуется как DBIRTH.
+func (p *Publisher) SetOnline(online bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrClosed
+	}
+	if p.online == online {
+		return nil
+	}
+	p.online = online
+	if p.sp != nil {
+		if !online {
+			return p.sp.deviceDeath(p)
+		}
+		return nil
+	}
+	status := StatusOffline
+	if online {
+		status = StatusOnline
+	}
+	return p.publish(p.topic("status"), []byte(status))
+}
+
+// SetSystemInfo задает системную информацию станка, которая в режиме Sparkplug
+// передается в DBIRTH. При изменении устройство рождается заново.
+func (p *Publisher) SetSystemInfo(info *models.SystemInfo) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if info == nil || (p.info != nil && *p.info == *info) {
+		return
+	}
+	p.info = info
+	if p.sp != nil {
+		p.sp.born = false
+	}
+}
+
+// PublishSnapshot публикует изменившиеся с предыдущего снимка значения. Пока станок
+// недоступен (см. SetOnline), снимок только запоминается.
+func (p *Publisher) PublishSnapshot(data *models.AggregatedData) error {
+	if data == nil {
+		return errors.New("mqtt: nil snapshot")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrClosed
+	}
+	p.data = data
+	if !p.online {
+		return nil
+	}
+	if p.sp != nil {
+		return p.sp.publishData(p)
+	}
+	return p.publishTopics(data)
+}
+
+// Run опрашивает станок client (см. fanuc.Client.Subscribe) до отмены ctx или закрытия
+// клиента и публикует снимки. Доступность станка (SetOnline) следует за состоянием
+// соединения клиента: станок доступен в состоянии Connected. Ошибки публикации
+// записываются в журнал и не прерывают опрос. После остановки станок помечается
+// недоступным.
+func (p *Publisher) Run(ctx context.Context, client *fanuc.Client, opts fanuc.SubscribeOptions) error {
+	events, cancel := client.ConnectionEvents(16)
+	defer cancel()
+	snapshots, err := client.Subscribe(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer p.logError(func() error { return p.SetOnline(false) })
+
+	p.SetSystemInfo(client.GetSystemInfo())
+	p.logError(func() error { return p.SetOnline(client.ConnectionState() == models.ConnectionConnected) })
+	for {
+		select {
+		case e := <-events:
+			p.logError(func() error { return p.SetOnline(e.To == models.ConnectionConnected) })
+		case s, ok := <-snapshots:
+			if !ok {
+				return ctx.Err()
+			}
+			p.SetSystemInfo(s.SystemInfo)
+			if s.Data != nil {
+				p.logError(func() error { return p.PublishSnapshot(s.Data) })
+			}
+		}
+	}
+}
+
+func (p *Publisher) logError(fn func() error) {
+	if err := fn(); err != nil && !errors.Is(err, ErrClosed) {
+		p.cfg.Logger.Warnf("MQTT: %v", err)
+	}
+}
+
+// Close штатно отключается от брокера, предварительно опубликовав статус offline
+// (в режиме Sparkplug — DDEATH и NDEATH).
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+
+	var err error
+	if p.sp != nil {
+		err = p.sp.stop(p)
+	} else {
+		err = p.publish(p.topic("status"), []byte(StatusOffline))
+	}
+	return errors.Join(err, p.conn.Disconnect())
+}
+
+// reconnected повторно публикует состояние после восстановления соединения с брокером:
+// брокер уже опубликовал завещание.
+func (p *Publisher) reconnected() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed || p.conn == nil {
+		return
+	}
+
+	var err error
+	if p.sp != nil {
+		err = p.sp.start(p)
+	} else {
+		status := StatusOffline
+		if p.online {
+			status = StatusOnline
+		}
+		err = p.publish(p.topic("status"), []byte(status))
+		for topic, payload := range p.retain {
+			err = errors.Join(err, p.publish(topic, payload))
+		}
+	}
+	if err != nil {
+		p.cfg.Logger.Warnf("MQTT: republish after reconnect failed: %v", err)
+	}
+}
+
+// topicSection описывает топик обычного режима: раздел сводных данных, качество которого
+// передается в топике, и JSON-поля AggregatedData.
+type topicSection struct {
+	name    string
+	section models.Section
+	fields  []string
+}
+
+var topicSections = []topicSection{
+	{"state", models.SectionState, []string{
+		"is_emergency", "machine_state", "program_mode", "tm_mode", "axis_movement_status",
+		"mstb_status", "emergency_status", "alarm_status", "edit_status",
+	}},
+	{"axes", models.SectionAxes, []string{"axis_infos"}},
+	{"spindles", models.SectionSpindles, []string{"spindle_infos"}},
+	{"alarms", models.SectionAlarms, []string{"has_alarms", "alarms"}},
+	{"program", models.SectionProgram, []string{"current_program"}},
+}
+
+// publishTopics публикует топики разделов, содержимое которых изменилось. Топик содержит
+// время снимка, качество раздела и поля в том же виде, что и JSON AggregatedData.
+// Разделы, которые еще не читались, не публикуются. Вызывается под p.mu.
+func (p *Publisher) publishTopics(data *models.AggregatedData) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("mqtt: encode snapshot: %w", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return fmt.Errorf("mqtt: encode snapshot: %w", err)
+	}
+	timestamp, err := json.Marshal(data.Timestamp)
+	if err != nil {
+		return fmt.Errorf("mqtt: encode snapshot: %w", err)
+	}
+
+	var errs []error
+	for _, ts := range topicSections {
+		quality := models.QualityGood
+		if data.Sections != nil {
+			status, ok := data.Sections[ts.section]
+			if !ok {
+				continue
+			}
+			quality = status.Quality
+		}
+
+		var body bytes.Buffer
+		fmt.Fprintf(&body, `"quality":%q`, quality)
+		for _, field := range ts.fields {
+			fmt.Fprintf(&body, `,%q:%s`, field, fields[field])
+		}
+		topic := p.topic(ts.name)
+		if p.keys[topic] == body.String() {
+			continue
+		}
+
+		payload := []byte(`{"timestamp":` + string(timestamp) + "," + body.String() + "}")
+		if err := p.publish(topic, payload); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		p.keys[topic] = body.String()
+		p.retain[topic] = payload
+	}
+	return errors.Join(errs...)
+}
+
+// topic возвращает топик обычного режима.
+func (p *Publisher) topic(name string) string {
+	return p.cfg.TopicPrefix + "/" + topicID(p.cfg.MachineID) + "/" + name
+}
+
+// message возвращает сообщение обычного режима с флагом retained.
+func (p *Publisher) message(topic string, payload []byte) Message {
+	return Message{Topic: topic, Payload: payload, QoS: p.cfg.QoS, Retained: true}
+}
+
+// publish публикует сообщение обычного режима. Вызывается под p.mu.
+func (p *Publisher) publish(topic string, payload []byte) error {
+	if err := p.conn.Publish(p.message(topic, payload)); err != nil {
+		return fmt.Errorf("mqtt: publish %s: %w", topic, err)
+	}
+	return nil
+}
+
+// topicID заменяет в идентификаторе символы, недопустимые в уровне топика.
+func topicID(id string) string {
+	return strings.NewReplacer("/", "_", "+", "_", "#", "_").Replace(id)
+}
@@ -0,0 +1,387 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	sparkplugb "github.com/iwtcode/fanucAdapter/api/sparkplug/b"
+	"github.com/iwtcode/fanucAdapter/models"
+	"google.golang.org/protobuf/proto"
+)
+
+// Режим Sparkplug B.
+//
+// Топики: spBv1.0/<group>/<тип>/<edge node>[/<device>]. Публикатор — узел с одним
+// устройством-станком:
+//
+//   - NBIRTH публикуется после каждого подключения к брокеру и содержит bdSeq
+//     и "Node Control/Rebirth"; NDEATH с тем же bdSeq задан завещанием соединения
+//     и публикуется явно при Close. bdSeq увеличивается (0–255) при каждом подключении,
+//     чтобы приложение-хост не приняло запоздавший NDEATH старой сессии за новую.
+//   - DBIRTH публикуется с первым снимком после того, как станок стал доступен,
+//     и содержит все метрики устройства, включая "Properties/..." из SystemInfo.
+//   - DDATA содержит только метрики, изменившиеся с предыдущей публикации.
+//     Если набор метрик изменился (например, появилась ось), публикуется новый DBIRTH.
+//   - DDEATH публикуется при потере соединения со станком (SetOnline(false)).
+//
+// Значения разделов и полей с качеством, отличным от Good, передаются как is_null.
+// NCMD с "Node Control/Rebirth" = true заново публикует NBIRTH и DBIRTH.
+
+// SparkplugNamespace — пространство имен топиков Sparkplug B.
+const SparkplugNamespace = "spBv1.0"
+
+// SparkplugConfig задает идентификаторы Sparkplug B. Символы "/", "+" и "#"
+// заменяются на "_".
+type SparkplugConfig struct {
+	GroupID    string // По умолчанию "fanuc"
+	EdgeNodeID string // По умолчанию Config.MachineID
+	DeviceID   string // По умолчанию "cnc"
+}
+
+func (c *SparkplugConfig) setDefaults(machineID string) {
+	if c.GroupID == "" {
+		c.GroupID = "fanuc"
+	}
+	if c.EdgeNodeID == "" {
+		c.EdgeNodeID = machineID
+	}
+	if c.DeviceID == "" {
+		c.DeviceID = "cnc"
+	}
+	c.GroupID = topicID(c.GroupID)
+	c.EdgeNodeID = topicID(c.EdgeNodeID)
+	c.DeviceID = topicID(c.DeviceID)
+}
+
+// Метрики узла.
+const (
+	metricBdSeq   = "bdSeq"
+	metricRebirth = "Node Control/Rebirth"
+)
+
+// sparkplug хранит состояние узла Sparkplug. Все методы, кроме session, вызываются
+// под Publisher.mu.
+type sparkplug struct {
+	cfg        SparkplugConfig
+	sessions   atomic.Uint64 // Количество подключений к брокеру
+	bdSeq      atomic.Uint64 // bdSeq текущей сессии
+	seq        uint64
+	born       bool              // DBIRTH опубликован и DDEATH еще нет
+	metrics    map[string]metric // Последние опубликованные метрики устройства
+	subscribed bool
+}
+
+// metric — значение метрики: string, bool, int64 или float64; nil для is_null.
+type metric struct {
+	name  string
+	typ   sparkplugb.DataType
+	value any
+}
+
+func newSparkplug(cfg SparkplugConfig) *sparkplug {
+	return &sparkplug{cfg: cfg}
+}
+
+func (s *sparkplug) nodeTopic(kind string) string {
+	return SparkplugNamespace + "/" + s.cfg.GroupID + "/" + kind + "/" + s.cfg.EdgeNodeID
+}
+
+func (s *sparkplug) deviceTopic(kind string) string {
+	return s.nodeTopic(kind) + "/" + s.cfg.DeviceID
+}
+
+// nextSeq возвращает порядковый номер сообщения (0–255).
+func (s *sparkplug) nextSeq() uint64 {
+	seq := s.seq
+	s.seq = (s.seq + 1) % 256
+	return seq
+}
+
+// session начинает новую сессию MQTT: выбирает следующий bdSeq и возвращает NDEATH
+// с ним для завещания. Вызывается Dialer перед каждым подключением.
+func (s *sparkplug) session() Message {
+	s.bdSeq.Store((s.sessions.Add(1) - 1) % 256)
+	return s.death()
+}
+
+// death возвращает NDEATH текущей сессии.
+func (s *sparkplug) death() Message {
+	payload := &sparkplugb.Payload{
+		Timestamp: proto.Uint64(millis(time.Now())),
+		Metrics:   []*sparkplugb.Payload_Metric{s.bdSeqMetric()},
+	}
+	data, _ := proto.Marshal(payload)
+	return Message{Topic: s.nodeTopic("NDEATH"), Payload: data, QoS: 1}
+}
+
+func (s *sparkplug) bdSeqMetric() *sparkplugb.Payload_Metric {
+	return toProto(metric{name: metricBdSeq, typ: sparkplugb.DataType_UInt64, value: int64(s.bdSeq.Load())}, 0)
+}
+
+// start подписывается на команды узла и публикует NBIRTH, а если станок доступен —
+// DBIRTH. Вызывается после подключения к брокеру и по команде Rebirth.
+func (s *sparkplug) start(p *Publisher) error {
+	if !s.subscribed {
+		if err := p.conn.Subscribe(s.nodeTopic("NCMD"), 0, p.command); err != nil {
+			return fmt.Errorf("mqtt: subscribe NCMD: %w", err)
+		}
+		s.subscribed = true
+	}
+
+	s.seq = 0
+	s.born = false
+	now := millis(time.Now())
+	rebirth := toProto(metric{name: metricRebirth, typ: sparkplugb.DataType_Boolean, value: false}, now)
+	if err := s.publish(p, s.nodeTopic("NBIRTH"), now, s.bdSeqMetric(), rebirth); err != nil {
+		return err
+	}
+	if p.online && p.data != nil {
+		return s.publishData(p)
+	}
+	return nil
+}
+
+// stop публикует DDEATH и NDEATH перед штатным отключением.
+func (s *sparkplug) stop(p *Publisher) error {
+	if err := s.deviceDeath(p); err != nil {
+		return err
+	}
+	death := s.death()
+	if err := p.conn.Publish(death); err != nil {
+		return fmt.Errorf("mqtt: publish %s: %w", death.Topic, err)
+	}
+	return nil
+}
+
+// publishData публикует DBIRTH или DDATA для снимка p.data.
+func (s *sparkplug) publishData(p *Publisher) error {
+	ts := millis(p.data.Timestamp)
+	current := deviceMetrics(p.data)
+	values := make(map[string]metric, len(current))
+	for _, m := range current {
+		values[m.name] = m
+	}
+
+	if !s.born || !sameNames(s.metrics, values) {
+		all := append(propertyMetrics(p.info), current...)
+		if err := s.publish(p, s.deviceTopic("DBIRTH"), ts, toProtos(all, ts)...); err != nil {
+			return err
+		}
+		s.born = true
+		s.metrics = values
+		return nil
+	}
+
+	var changed []metric
+	for _, m := range current {
+		if s.metrics[m.name] != m {
+			changed = append(changed, m)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	if err := s.publish(p, s.deviceTopic("DDATA"), ts, toProtos(changed, ts)...); err != nil {
+		return err
+	}
+	s.metrics = values
+	return nil
+}
+
+// deviceDeath публикует DDEATH, если устройство было рождено.
+func (s *sparkplug) deviceDeath(p *Publisher) error {
+	if !s.born {
+		return nil
+	}
+	s.born = false
+	return s.publish(p, s.deviceTopic("DDEATH"), millis(time.Now()))
+}
+
+func (s *sparkplug) publish(p *Publisher, topic string, ts uint64, metrics ...*sparkplugb.Payload_Metric) error {
+	payload := &sparkplugb.Payload{
+		Timestamp: proto.Uint64(ts),
+		Metrics:   metrics,
+		Seq:       proto.Uint64(s.nextSeq()),
+	}
+	data, err := proto.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("mqtt: encode %s: %w", topic, err)
+	}
+	if err := p.conn.Publish(Message{Topic: topic, Payload: data}); err != nil {
+		return fmt.Errorf("mqtt: publish %s: %w", topic, err)
+	}
+	return nil
+}
+
+// command обрабатывает NCMD: "Node Control/Rebirth" = true заново публикует NBIRTH и DBIRTH.
+func (p *Publisher) command(msg Message) {
+	var payload sparkplugb.Payload
+	if err := proto.Unmarshal(msg.Payload, &payload); err != nil {
+		p.cfg.Logger.Warnf("MQTT: invalid NCMD: %v", err)
+		return
+	}
+	for _, m := range payload.GetMetrics() {
+		if m.GetName() != metricRebirth || !m.GetBooleanValue() {
+			continue
+		}
+		p.mu.Lock()
+		if !p.closed {
+			if err := p.sp.start(p); err != nil {
+				p.cfg.Logger.Warnf("MQTT: rebirth failed: %v", err)
+			}
+		}
+		p.mu.Unlock()
+		return
+	}
+}
+
+// propertyMetrics возвращает метрики системной информации станка для DBIRTH.
+func propertyMetrics(info *models.SystemInfo) []metric {
+	if info == nil {
+		return nil
+	}
+	var ms metrics
+	ms.add("Properties/Manufacturer", sparkplugb.DataType_String, info.Manufacturer, true)
+	ms.add("Properties/Model", sparkplugb.DataType_String, info.Model, true)
+	ms.add("Properties/Series", sparkplugb.DataType_String, info.Series, true)
+	ms.add("Properties/Version", sparkplugb.DataType_String, info.Version, true)
+	ms.add("Properties/MaxAxes", sparkplugb.DataType_Int16, int64(info.MaxAxes), true)
+	ms.add("Properties/ControlledAxes", sparkplugb.DataType_Int16, int64(info.ControlledAxes), true)
+	ms.add("Properties/Paths", sparkplugb.DataType_Int16, int64(info.Paths), true)
+	ms.add("Properties/CncType", sparkplugb.DataType_String, info.CncType, true)
+	ms.add("Properties/MachineType", sparkplugb.DataType_String, info.MachineType, true)
+	ms.add("Properties/ModelSeries", sparkplugb.DataType_String, info.ModelSeries, true)
+	return ms
+}
+
+// deviceMetrics возвращает метрики устройства по снимку data.
+func deviceMetrics(data *models.AggregatedData) []metric {
+	good := func(section models.Section) bool {
+		return data.Sections == nil || data.Sections[section].Quality == models.QualityGood
+	}
+	var ms metrics
+
+	state := good(models.SectionState)
+	ms.add("State/MachineState", sparkplugb.DataType_String, data.MachineState, state)
+	ms.add("State/ProgramMode", sparkplugb.DataType_String, data.ProgramMode, state)
+	ms.add("State/TmMode", sparkplugb.DataType_String, data.TmMode, state)
+	ms.add("State/AxisMovement", sparkplugb.DataType_String, data.AxisMovementStatus, state)
+	ms.add("State/Mstb", sparkplugb.DataType_String, data.MstbStatus, state)
+	ms.add("State/Emergency", sparkplugb.DataType_String, data.EmergencyStatus, state)
+	ms.add("State/Alarm", sparkplugb.DataType_String, data.AlarmStatus, state)
+	ms.add("State/Edit", sparkplugb.DataType_String, data.EditStatus, state)
+	ms.add("State/IsEmergency", sparkplugb.DataType_Boolean, data.IsEmergency, state)
+
+	alarms := data.Alarms
+	if alarms == nil {
+		alarms = []models.AlarmDetail{}
+	}
+	active, _ := json.Marshal(alarms)
+	alarmsGood := good(models.SectionAlarms)
+	ms.add("Alarms/HasAlarms", sparkplugb.DataType_Boolean, data.HasAlarms, alarmsGood)
+	ms.add("Alarms/Count", sparkplugb.DataType_Int32, int64(len(data.Alarms)), alarmsGood)
+	ms.add("Alarms/Active", sparkplugb.DataType_String, string(active), alarmsGood)
+
+	program := good(models.SectionProgram)
+	ms.add("Program/Name", sparkplugb.DataType_String, data.CurrentProgram.ProgramName, program)
+	ms.add("Program/Number", sparkplugb.DataType_Int64, data.CurrentProgram.ProgramNumber, program)
+	ms.add("Program/Line", sparkplugb.DataType_String, data.CurrentProgram.GCodeLine, program)
+
+	axes := good(models.SectionAxes)
+	for _, a := range data.AxisInfos {
+		prefix := "Axes/" + a.Name + "/"
+		field := func(name string) bool { return axes && a.Quality.Good(name) }
+		ms.add(prefix+"Position", sparkplugb.DataType_Double, a.Position, axes)
+		ms.add(prefix+"Load", sparkplugb.DataType_Double, a.LoadPercent, field("load_percent"))
+		ms.add(prefix+"ServoTemperature", sparkplugb.DataType_Int32, int64(a.ServoTemperature), field("servo_temperature"))
+		ms.add(prefix+"CoderTemperature", sparkplugb.DataType_Int32, int64(a.CoderTemperature), field("coder_temperature"))
+		ms.add(prefix+"PowerConsumption", sparkplugb.DataType_Int32, int64(a.PowerConsumption), field("power_consumption"))
+	}
+
+	spindles := good(models.SectionSpindles)
+	for _, s := range data.SpindleInfos {
+		prefix := "Spindles/S" + strconv.Itoa(int(s.Number)) + "/"
+		field := func(name string) bool { return spindles && s.Quality.Good(name) }
+		ms.add(prefix+"Speed", sparkplugb.DataType_Int32, int64(s.SpeedRPM), field("speed_rpm"))
+		ms.add(prefix+"Load", sparkplugb.DataType_Double, s.LoadPercent, field("load_percent"))
+		ms.add(prefix+"Override", sparkplugb.DataType_Int16, int64(s.OverridePercent), field("override_percent"))
+		ms.add(prefix+"PowerConsumption", sparkplugb.DataType_Int32, int64(s.PowerConsumption), field("power_consumption"))
+	}
+
+	feed := good(models.SectionFeed)
+	ms.add("Feed/Actual", sparkplugb.DataType_Int32, int64(data.ActualFeedRate), feed)
+	ms.add("Feed/Override", sparkplugb.DataType_Int16, int64(data.FeedOverride), feed)
+	ms.add("Feed/Contour", sparkplugb.DataType_Int32, int64(data.ContourFeedRate), good(models.SectionContourFeed))
+	ms.add("Feed/JogOverride", sparkplugb.DataType_Int32, int64(data.JogOverride), good(models.SectionJogOverride))
+
+	parameters := good(models.SectionParameters)
+	ms.add("Counters/PartsCount", sparkplugb.DataType_Int64, data.PartsCount, parameters)
+	ms.add("Counters/PowerOnTime", sparkplugb.DataType_String, data.PowerOnTime, parameters)
+	ms.add("Counters/OperatingTime", sparkplugb.DataType_String, data.OperatingTime, parameters)
+	ms.add("Counters/CycleTime", sparkplugb.DataType_String, data.CycleTime, parameters)
+	ms.add("Counters/CuttingTime", sparkplugb.DataType_String, data.CuttingTime, parameters)
+	return ms
+}
+
+type metrics []metric
+
+// add добавляет метрику; если ok ложно, значение передается как is_null.
+func (ms *metrics) add(name string, typ sparkplugb.DataType, value any, ok bool) {
+	if !ok {
+		value = nil
+	}
+	*ms = append(*ms, metric{name: name, typ: typ, value: value})
+}
+
+func sameNames(a, b map[string]metric) bool {
+	return maps.EqualFunc(a, b, func(x, y metric) bool { return x.typ == y.typ })
+}
+
+func toProtos(ms []metric, ts uint64) []*sparkplugb.Payload_Metric {
+	result := make([]*sparkplugb.Payload_Metric, 0, len(ms))
+	for _, m := range ms {
+		result = append(result, toProto(m, ts))
+	}
+	return result
+}
+
+// toProto преобразует метрику в сообщение Sparkplug. Целые до 32 бит передаются
+// в int_value (отрицательные — в дополнительном коде), 64-битные — в long_value.
+func toProto(m metric, ts uint64) *sparkplugb.Payload_Metric {
+	pm := &sparkplugb.Payload_Metric{
+		Name:     proto.String(m.name),
+		Datatype: proto.Uint32(uint32(m.typ)),
+	}
+	if ts != 0 {
+		pm.Timestamp = proto.Uint64(ts)
+	}
+	switch v := m.value.(type) {
+	case nil:
+		pm.IsNull = proto.Bool(true)
+	case string:
+		pm.Value = &sparkplugb.Payload_Metric_StringValue{StringValue: v}
+	case bool:
+		pm.Value = &sparkplugb.Payload_Metric_BooleanValue{BooleanValue: v}
+	case float64:
+		pm.Value = &sparkplugb.Payload_Metric_DoubleValue{DoubleValue: v}
+	case int64:
+		if m.typ == sparkplugb.DataType_Int64 || m.typ == sparkplugb.DataType_UInt64 {
+			pm.Value = &sparkplugb.Payload_Metric_LongValue{LongValue: uint64(v)}
+		} else {
+			pm.Value = &sparkplugb.Payload_Metric_IntValue{IntValue: uint32(int32(v))}
+		}
+	}
+	return pm
+}
+
+// millis возвращает время в миллисекундах Unix, как принято в Sparkplug.
+func millis(t time.Time) uint64 {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return uint64(t.UnixMilli())
+}
@@ -0,0 +1,212 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/iwtcode/fanucAdapter/focas/fake"
+)
+
+// Machine — описание виртуального станка в формате JSON для cmd/fanuc-sim.
+// Незаполненные поля берутся из fake.NewCNC (токарный станок 0i-TF).
+type Machine struct {
+	AddInfo *int16 `json:"add_info,omitempty"` // ODBSYS.addinfo, бит 0x02 — i-серия
+	CncType string `json:"cnc_type,omitempty"` // ODBSYS.cnc_type, например "0" или "30"
+	MtType  string `json:"mt_type,omitempty"`  // ODBSYS.mt_type, например " T" или " M"
+	Series  string `json:"series,omitempty"`
+	Version string `json:"version,omitempty"`
+	MaxAxis int16  `json:"max_axis,omitempty"`
+
+	Axes     []MachineAxis    `json:"axes,omitempty"`
+	Spindles []MachineSpindle `json:"spindles,omitempty"`
+	Alarms   []MachineAlarm   `json:"alarms,omitempty"`
+
+	// Params — значения параметров по номерам (счетчики деталей и времена 6711–6757 и др.).
+	// Указанные значения дополняют и перезаписывают параметры по умолчанию.
+	Params map[int16]int32 `json:"params,omitempty"`
+
+	// Programs — тексты программ по имени ("O0001") или полному пути.
+	Programs map[string]string `json:"programs,omitempty"`
+	// Running — имя программы из Programs, выполняемой при старте симулятора.
+	Running string `json:"running,omitempty"`
+
+	// Paths описывает каналы 2..N многоканального станка; поля выше относятся к каналу 1.
+	Paths []MachinePath `json:"paths,omitempty"`
+}
+
+// MachinePath описывает дополнительный канал (path) многоканального станка.
+type MachinePath struct {
+	Axes     []MachineAxis    `json:"axes,omitempty"`
+	Spindles []MachineSpindle `json:"spindles,omitempty"`
+	Alarms   []MachineAlarm   `json:"alarms,omitempty"`
+	// Running — имя программы из Programs, выполняемой в канале.
+	Running string `json:"running,omitempty"`
+}
+
+// MachineAxis описывает ось. Position задается в единицах 10^-Decimals.
+type MachineAxis struct {
+	Name             string `json:"name"`
+	Position         int32  `json:"position"`
+	Decimals         int16  `json:"decimals"`
+	Load             int32  `json:"load,omitempty"`
+	ServoTemperature int32  `json:"servo_temperature,omitempty"`
+	CoderTemperature int32  `json:"coder_temperature,omitempty"`
+	PowerConsumption int32  `json:"power_consumption,omitempty"`
+}
+
+// MachineSpindle описывает шпиндель. OverridePercent задается в процентах (0..100).
+type MachineSpindle struct {
+	Speed           int32 `json:"speed"`
+	Load            int32 `json:"load,omitempty"`
+	OverridePercent int32 `json:"override_percent,omitempty"`
+}
+
+// MachineAlarm описывает активную ошибку.
+type MachineAlarm struct {
+	Number  int32  `json:"number"`
+	Type    int16  `json:"type"`
+	Axis    int16  `json:"axis,omitempty"`
+	Message string `json:"message"`
+}
+
+// LoadMachine читает описание станка из JSON-файла.
+func LoadMachine(path string) (*Machine, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m Machine
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// CNC строит состояние виртуального станка по описанию.
+func (m *Machine) CNC() (*fake.CNC, error) {
+	cnc := fake.NewCNC()
+
+	if m.AddInfo != nil {
+		cnc.SysInfo.AddInfo = *m.AddInfo
+	}
+	setString(&cnc.SysInfo.CncType, m.CncType)
+	setString(&cnc.SysInfo.MtType, m.MtType)
+	setString(&cnc.SysInfo.Series, m.Series)
+	setString(&cnc.SysInfo.Version, m.Version)
+	if m.MaxAxis > 0 {
+		cnc.SysInfo.MaxAxis = m.MaxAxis
+	}
+
+	if m.Axes != nil {
+		axes, err := buildAxes(m.Axes)
+		if err != nil {
+			return nil, err
+		}
+		cnc.Axes = axes
+		cnc.SysInfo.Axes = fmt.Sprintf("%02d", len(cnc.Axes))
+	}
+
+	if m.Spindles != nil {
+		cnc.Spindles = buildSpindles(m.Spindles)
+	}
+
+	cnc.Alarms = append(cnc.Alarms, buildAlarms(m.Alarms)...)
+	if len(cnc.Alarms) > 0 {
+		cnc.Stat.Alarm = 1
+	}
+
+	for no, value := range m.Params {
+		cnc.Params[no] = value
+	}
+
+	if m.Programs != nil {
+		cnc.Programs = m.Programs
+	}
+	if m.Running != "" {
+		if _, ok := cnc.Programs[m.Running]; !ok {
+			return nil, fmt.Errorf("running program %q not found in programs", m.Running)
+		}
+	}
+
+	for i, mp := range m.Paths {
+		axes, err := buildAxes(mp.Axes)
+		if err != nil {
+			return nil, fmt.Errorf("path %d: %w", i+2, err)
+		}
+		ps := fake.PathState{
+			Axes:     axes,
+			Spindles: buildSpindles(mp.Spindles),
+			Alarms:   buildAlarms(mp.Alarms),
+		}
+		if len(ps.Alarms) > 0 {
+			ps.Stat.Alarm = 1
+		}
+		if mp.Running != "" {
+			source, ok := cnc.Programs[mp.Running]
+			if !ok {
+				return nil, fmt.Errorf("path %d: running program %q not found in programs", i+2, mp.Running)
+			}
+			ps.ExecName = mp.Running
+			fmt.Sscanf(mp.Running, "O%d", &ps.ExecNumber)
+			ps.ExecBlock = firstBlock(source)
+			ps.Stat.Aut = 1 // MEM
+			ps.Stat.Run = 3 // START
+			ps.Stat.Motion = 1
+		}
+		cnc.ExtraPaths = append(cnc.ExtraPaths, ps)
+	}
+	if len(cnc.ExtraPaths) > 0 {
+		cnc.MaxPath = int16(1 + len(cnc.ExtraPaths))
+	}
+	return cnc, nil
+}
+
+func buildAxes(src []MachineAxis) ([]fake.Axis, error) {
+	axes := make([]fake.Axis, 0, len(src))
+	for _, a := range src {
+		if len(a.Name) == 0 || len(a.Name) > 2 {
+			return nil, fmt.Errorf("invalid axis name %q", a.Name)
+		}
+		axis := fake.Axis{
+			Name:             a.Name[0],
+			Position:         a.Position,
+			PosDec:           a.Decimals,
+			Load:             a.Load,
+			ServoTemperature: a.ServoTemperature,
+			CoderTemperature: a.CoderTemperature,
+			PowerConsumption: a.PowerConsumption,
+		}
+		if len(a.Name) == 2 {
+			axis.Suffix = a.Name[1]
+		}
+		axes = append(axes, axis)
+	}
+	return axes, nil
+}
+
+func buildSpindles(src []MachineSpindle) []fake.Spindle {
+	spindles := make([]fake.Spindle, 0, len(src))
+	for _, sp := range src {
+		spindles = append(spindles, fake.Spindle{
+			Speed:    sp.Speed,
+			Load:     sp.Load,
+			Override: int16(sp.OverridePercent * 16383 / 100),
+		})
+	}
+	return spindles
+}
+
+func buildAlarms(src []MachineAlarm) []fake.Alarm {
+	var alarms []fake.Alarm
+	for _, a := range src {
+		alarms = append(alarms, fake.Alarm{Number: a.Number, Type: a.Type, Axis: a.Axis, Message: a.Message})
+	}
+	return alarms
+}
+
+func setString(dst *string, value string) {
+	if value != "" {
+		*dst = value
+	}
+}
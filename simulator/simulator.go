@@ -0,0 +1,260 @@
+// Package simulator запускает виртуальный станок FANUC, доступный по протоколу
+// FOCAS/Ethernet. К нему можно подключиться обычным fanuc.Client
+// (Transport: fanuc.TransportEthernet) и прогнать клиент, логику
+// переподключения и все методы Get* без реального станка.
+//
+// Состояние станка задается структурой fake.CNC, а сбои — методами Fail,
+// DropConnections и SetChaos.
+package simulator
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+
+	. "github.com/iwtcode/fanucAdapter/focas/errcode"
+	"github.com/iwtcode/fanucAdapter/focas/ethernet"
+	"github.com/iwtcode/fanucAdapter/focas/fake"
+)
+
+// DefaultAddr — адрес, который слушает симулятор по умолчанию (стандартный порт FOCAS).
+const DefaultAddr = ":8193"
+
+// Chaos задает вероятности случайных сбоев для каждого вызова FOCAS (0..1).
+type Chaos struct {
+	Busy   float64 // вызов завершается с EW_BUSY
+	Handle float64 // вызов завершается с EW_HANDLE
+	Drop   float64 // соединение разрывается (клиент получает EW_SOCKET)
+}
+
+// Simulator — виртуальный станок с TCP-сервером FOCAS/Ethernet.
+type Simulator struct {
+	backend *fake.Backend
+	server  *ethernet.Server
+
+	mu    sync.Mutex
+	ln    net.Listener
+	done  chan struct{}
+	chaos Chaos
+	rand  *rand.Rand
+}
+
+// New создает симулятор для указанного станка. Если cnc равен nil, используется fake.NewCNC.
+func New(cnc *fake.CNC) *Simulator {
+	backend := fake.New(cnc)
+	return &Simulator{
+		backend: backend,
+		server:  ethernet.NewServer(backend),
+	}
+}
+
+// Start начинает прием подключений на addr (например, "127.0.0.1:0") в фоне.
+func (s *Simulator) Start(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen %s: %w", addr, err)
+	}
+
+	s.mu.Lock()
+	if s.ln != nil {
+		s.mu.Unlock()
+		ln.Close()
+		return errors.New("simulator already started")
+	}
+	s.ln = ln
+	s.done = make(chan struct{})
+	s.mu.Unlock()
+
+	go func() {
+		defer close(s.done)
+		_ = s.server.Serve(ln)
+	}()
+	return nil
+}
+
+// Close останавливает сервер и разрывает все соединения.
+func (s *Simulator) Close() error {
+	err := s.server.Close()
+
+	s.mu.Lock()
+	done := s.done
+	s.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+	return err
+}
+
+// Addr возвращает адрес, на котором симулятор принимает подключения.
+func (s *Simulator) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ln == nil {
+		return nil
+	}
+	return s.ln.Addr()
+}
+
+// Host возвращает IP-адрес симулятора для fanuc.Config.IP.
+func (s *Simulator) Host() string {
+	if addr, ok := s.Addr().(*net.TCPAddr); ok {
+		if addr.IP.IsUnspecified() {
+			return "127.0.0.1"
+		}
+		return addr.IP.String()
+	}
+	return ""
+}
+
+// Port возвращает TCP-порт симулятора для fanuc.Config.Port.
+func (s *Simulator) Port() uint16 {
+	if addr, ok := s.Addr().(*net.TCPAddr); ok {
+		return uint16(addr.Port)
+	}
+	return 0
+}
+
+// Backend возвращает бэкенд, обслуживающий запросы клиентов.
+func (s *Simulator) Backend() *fake.Backend {
+	return s.backend
+}
+
+// Update изменяет состояние станка.
+func (s *Simulator) Update(f func(cnc *fake.CNC)) {
+	s.backend.Update(f)
+}
+
+// State возвращает копию текущего состояния станка.
+func (s *Simulator) State() *fake.CNC {
+	return s.backend.State()
+}
+
+// AddProgram добавляет программу в память станка.
+func (s *Simulator) AddProgram(name, source string) {
+	s.backend.Update(func(cnc *fake.CNC) {
+		if cnc.Programs == nil {
+			cnc.Programs = make(map[string]string)
+		}
+		cnc.Programs[name] = source
+	})
+}
+
+// StartProgram запускает программу из памяти станка в автоматическом режиме (MEM, START).
+func (s *Simulator) StartProgram(name string) error {
+	var err error
+	s.backend.Update(func(cnc *fake.CNC) {
+		source, ok := cnc.Programs[name]
+		if !ok {
+			err = fmt.Errorf("program %q not found", name)
+			return
+		}
+		var number int64
+		fmt.Sscanf(name, "O%d", &number)
+
+		cnc.ExecName = name
+		cnc.ExecNumber = number
+		cnc.ExecBlock = firstBlock(source)
+		cnc.Stat.Aut = 1 // MEM
+		cnc.Stat.Run = 3 // START
+		cnc.Stat.Motion = 1
+	})
+	return err
+}
+
+// StopProgram останавливает выполнение программы (RESET).
+func (s *Simulator) StopProgram() {
+	s.backend.Update(func(cnc *fake.CNC) {
+		cnc.Stat.Run = 0
+		cnc.Stat.Motion = 0
+	})
+}
+
+// RaiseAlarm добавляет активную ошибку и выставляет признак ALARM.
+func (s *Simulator) RaiseAlarm(alarm fake.Alarm) {
+	s.backend.Update(func(cnc *fake.CNC) {
+		cnc.Alarms = append(cnc.Alarms, alarm)
+		cnc.Stat.Alarm = 1
+	})
+}
+
+// ClearAlarms сбрасывает все активные ошибки.
+func (s *Simulator) ClearAlarms() {
+	s.backend.Update(func(cnc *fake.CNC) {
+		cnc.Alarms = nil
+		cnc.Stat.Alarm = 0
+	})
+}
+
+// Fail ставит в очередь коды возврата для функции fn (например, "cnc_statinfo").
+// Код EW_SOCKET разрывает соединение клиента при соответствующем вызове.
+func (s *Simulator) Fail(fn string, rcs ...int16) {
+	s.backend.Fail(fn, rcs...)
+}
+
+// DropConnections разрывает все клиентские соединения и делает выданные хендлы недействительными.
+func (s *Simulator) DropConnections() {
+	s.backend.DropConnections()
+	s.server.DropConnections()
+}
+
+// SetReachable управляет доступностью станка: недоступный станок разрывает новые подключения.
+func (s *Simulator) SetReachable(reachable bool) {
+	s.backend.SetReachable(reachable)
+}
+
+// SetChaos включает случайные сбои с заданными вероятностями.
+// Нулевое значение Chaos отключает их.
+func (s *Simulator) SetChaos(chaos Chaos) {
+	s.mu.Lock()
+	s.chaos = chaos
+	if s.rand == nil {
+		s.rand = rand.New(rand.NewSource(rand.Int63()))
+	}
+	s.mu.Unlock()
+
+	if chaos == (Chaos{}) {
+		s.backend.SetHook(nil)
+		return
+	}
+	s.backend.SetHook(s.chaosHook)
+}
+
+// Calls возвращает имена всех выполненных вызовов FOCAS по порядку.
+func (s *Simulator) Calls() []string {
+	return s.backend.Calls()
+}
+
+func (s *Simulator) chaosHook(fn string, handle uint16) int16 {
+	// Открытие и закрытие соединения не ломаем, иначе клиент не сможет переподключиться
+	if fn == "cnc_allclibhndl3" || fn == "cnc_freelibhndl" || fn == "cnc_startupprocess" {
+		return EW_OK
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.rand.Float64()
+	switch {
+	case p < s.chaos.Drop:
+		return EW_SOCKET
+	case p < s.chaos.Drop+s.chaos.Handle:
+		return EW_HANDLE
+	case p < s.chaos.Drop+s.chaos.Handle+s.chaos.Busy:
+		return EW_BUSY
+	}
+	return EW_OK
+}
+
+// firstBlock возвращает первый кадр программы после ее номера.
+func firstBlock(source string) string {
+	for _, line := range strings.Split(source, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line == "%" || line[0] == 'O' || line[0] == '<' {
+			continue
+		}
+		return line
+	}
+	return ""
+}
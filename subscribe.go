@@ -0,0 +1,324 @@
+package fanuc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/iwtcode/fanucAdapter/models"
+)
+
+// DataGroup — группа данных, опрашиваемая подпиской с собственным интервалом.
+type DataGroup string
+
+// Группы данных подписки
+const (
+	GroupState      DataGroup = "state"      // Состояние станка, тревоги и выполняемая программа
+	GroupPositions  DataGroup = "positions"  // Позиции и нагрузка осей
+	GroupSpindles   DataGroup = "spindles"   // Скорость и нагрузка шпинделей
+	GroupFeed       DataGroup = "feed"       // Подача и коррекции
+	GroupParameters DataGroup = "parameters" // Счетчик деталей и наработка
+	GroupPaths      DataGroup = "paths"      // Данные каналов многоканального станка
+	GroupSystem     DataGroup = "system"     // Системная информация (cnc_sysinfo)
+)
+
+// groupOrder — порядок опроса групп в пределах одного снимка.
+var groupOrder = []DataGroup{GroupState, GroupPositions, GroupSpindles, GroupFeed, GroupParameters, GroupPaths, GroupSystem}
+
+// groupSections — разделы сводных данных, читаемые каждой группой.
+var groupSections = map[DataGroup][]models.Section{
+	GroupState:      {models.SectionState, models.SectionAlarms, models.SectionProgram},
+	GroupPositions:  {models.SectionAxes},
+	GroupSpindles:   {models.SectionSpindles},
+	GroupFeed:       {models.SectionFeed, models.SectionContourFeed, models.SectionJogOverride},
+	GroupParameters: {models.SectionParameters},
+	GroupPaths:      {models.SectionPaths},
+}
+
+// DefaultSubscribeIntervals возвращает интервалы по умолчанию: быстрые для состояния
+// и позиций, медленные для параметров и системной информации. У одноканального
+// станка группа GroupPaths не обращается к станку.
+func DefaultSubscribeIntervals() map[DataGroup]time.Duration {
+	return map[DataGroup]time.Duration{
+		GroupState:      500 * time.Millisecond,
+		GroupPositions:  500 * time.Millisecond,
+		GroupSpindles:   time.Second,
+		GroupFeed:       time.Second,
+		GroupParameters: 10 * time.Second,
+		GroupPaths:      time.Second,
+		GroupSystem:     time.Minute,
+	}
+}
+
+// Backpressure определяет поведение подписки, когда получатель не успевает читать снимки.
+type Backpressure int
+
+const (
+	// DropOldest вытесняет из буфера самый старый снимок; опрос не замедляется.
+	DropOldest Backpressure = iota
+	// Block приостанавливает опрос, пока получатель не прочитает снимок.
+	Block
+)
+
+// SubscribeOptions задает параметры подписки.
+type SubscribeOptions struct {
+	// Intervals задает период опроса каждой группы. Группы, которых нет в карте,
+	// не опрашиваются. nil означает DefaultSubscribeIntervals.
+	Intervals map[DataGroup]time.Duration
+	// Buffer — размер буфера канала. По умолчанию 1.
+	Buffer int
+	// Backpressure — поведение при заполненном буфере. По умолчанию DropOldest.
+	Backpressure Backpressure
+}
+
+// Snapshot — снимок данных станка, отправляемый подпиской.
+type Snapshot struct {
+	Seq       uint64    // Порядковый номер снимка, начиная с 1
+	Timestamp time.Time // Время завершения опроса
+	// Groups — группы, прочитанные в этом опросе. Поля остальных групп в Data
+	// сохраняют значения последнего успешного чтения. Data.Sections содержит
+	// качество и время чтения разделов: разделы, которые не удалось прочитать
+	// после успешного чтения, помечаются как Stale и сохраняют прежние значения.
+	Groups []DataGroup
+	Data   *models.AggregatedData
+	// SystemInfo — последняя прочитанная системная информация (GroupSystem).
+	SystemInfo *models.SystemInfo
+	// Err содержит ошибки чтения групп этого опроса. Ошибка не завершает подписку:
+	// следующий опрос выполняется по расписанию.
+	Err error
+	// Errors — ошибки чтения по группам; nil, если все группы прочитаны.
+	Errors map[DataGroup]error
+	// Dropped — количество снимков, вытесненных с начала подписки (DropOldest).
+	Dropped uint64
+}
+
+// Subscribe запускает периодический опрос станка и возвращает канал снимков.
+// Каждая группа данных опрашивается со своим интервалом; группы, срок которых наступил
+// одновременно, читаются в одном снимке. Канал закрывается при отмене ctx или закрытии клиента.
+func (c *Client) Subscribe(ctx context.Context, opts SubscribeOptions) (<-chan Snapshot, error) {
+	intervals := opts.Intervals
+	if intervals == nil {
+		intervals = DefaultSubscribeIntervals()
+	}
+	var groups []DataGroup
+	for _, g := range groupOrder {
+		if interval, ok := intervals[g]; ok {
+			if interval <= 0 {
+				return nil, fmt.Errorf("subscribe: invalid interval %s for group %q", interval, g)
+			}
+			groups = append(groups, g)
+		}
+	}
+	for g := range intervals {
+		if !knownGroup(g) {
+			return nil, fmt.Errorf("subscribe: unknown data group %q", g)
+		}
+	}
+	if len(groups) == 0 {
+		return nil, errors.New("subscribe: no data groups")
+	}
+	buffer := opts.Buffer
+	if buffer < 1 {
+		buffer = 1
+	}
+
+	s := &subscription{
+		client:       c,
+		intervals:    intervals,
+		groups:       groups,
+		backpressure: opts.Backpressure,
+		ch:           make(chan Snapshot, buffer),
+		data: &models.AggregatedData{
+			MachineID: fmt.Sprintf("%s:%d", c.config.IP, c.config.Port),
+			IsEnabled: true,
+			Sections:  make(map[models.Section]models.SectionStatus),
+		},
+		systemInfo: c.GetSystemInfo(),
+	}
+	go s.run(ctx)
+	return s.ch, nil
+}
+
+func knownGroup(g DataGroup) bool {
+	for _, known := range groupOrder {
+		if g == known {
+			return true
+		}
+	}
+	return false
+}
+
+// subscription — состояние одной подписки. Используется только горутиной run.
+type subscription struct {
+	client       *Client
+	intervals    map[DataGroup]time.Duration
+	groups       []DataGroup
+	backpressure Backpressure
+	ch           chan Snapshot
+
+	data       *models.AggregatedData // Накопленные данные всех групп
+	systemInfo *models.SystemInfo
+	seq        uint64
+	dropped    uint64
+}
+
+func (s *subscription) run(ctx context.Context) {
+	defer close(s.ch)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		// Закрытие клиента прерывает и текущий опрос
+		select {
+		case <-s.client.adapter.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	next := make(map[DataGroup]time.Time, len(s.groups))
+	now := time.Now()
+	for _, g := range s.groups {
+		next[g] = now
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		now := time.Now()
+		var due []DataGroup
+		for _, g := range s.groups {
+			if !next[g].After(now) {
+				due = append(due, g)
+				// Пропущенные из-за долгого опроса сроки не накапливаются
+				next[g] = now.Add(s.intervals[g])
+			}
+		}
+
+		snapshot := s.poll(ctx, due)
+		if ctx.Err() != nil {
+			return
+		}
+		if !s.send(ctx, snapshot) {
+			return
+		}
+
+		earliest := next[s.groups[0]]
+		for _, g := range s.groups[1:] {
+			if next[g].Before(earliest) {
+				earliest = next[g]
+			}
+		}
+		timer.Reset(time.Until(earliest))
+	}
+}
+
+// poll читает группы due и возвращает снимок с накопленными данными.
+func (s *subscription) poll(ctx context.Context, due []DataGroup) Snapshot {
+	var errs []error
+	var groupErrs map[DataGroup]error
+	for _, g := range due {
+		if err := s.readGroup(ctx, g); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", g, err))
+			if groupErrs == nil {
+				groupErrs = make(map[DataGroup]error)
+			}
+			groupErrs[g] = err
+		}
+	}
+
+	s.seq++
+	now := time.Now().UTC()
+	data := *s.data
+	data.Timestamp = now
+	data.Sections = maps.Clone(s.data.Sections)
+	return Snapshot{
+		Seq:        s.seq,
+		Timestamp:  now,
+		Groups:     due,
+		Data:       &data,
+		SystemInfo: s.systemInfo,
+		Err:        errors.Join(errs...),
+		Errors:     groupErrs,
+	}
+}
+
+// readGroup читает одну группу и обновляет накопленные данные. Срезы в данных
+// заменяются, а не изменяются, поэтому отправленные снимки остаются неизменными.
+// Возвращает первую ошибку чтения раздела; неподдерживаемые разделы ошибкой не считаются.
+func (s *subscription) readGroup(ctx context.Context, g DataGroup) error {
+	a := s.client.adapter
+	if g == GroupSystem {
+		info, err := a.ReadSystemInfo(ctx)
+		if err != nil {
+			return err
+		}
+		s.systemInfo = info
+		return nil
+	}
+
+	sections := groupSections[g]
+	data, _ := a.AggregateSections(ctx, sections...)
+	data.MergeStale(s.data)
+
+	var err error
+	for _, section := range sections {
+		status := data.Sections[section]
+		s.data.CopySection(data, section)
+		s.data.Sections[section] = status
+		if err == nil && status.Err != nil && status.Status != models.StatusUnsupported {
+			err = status.Err
+		}
+	}
+	return err
+}
+
+// setMachineState переносит состояние станка в сводные данные так же, как AggregateAllData.
+func setMachineState(d *models.AggregatedData, state *models.UnifiedMachineData) {
+	d.MachineState = state.MachineState
+	d.ProgramMode = state.ProgramMode
+	d.TmMode = state.TmMode
+	d.AxisMovementStatus = state.AxisMovementStatus
+	d.MstbStatus = state.MstbStatus
+	d.EmergencyStatus = state.EmergencyStatus
+	d.AlarmStatus = state.AlarmStatus
+	d.EditStatus = state.EditStatus
+	d.IsEmergency = state.EmergencyStatus != "Not Emergency"
+	d.HasAlarms = len(state.Alarms) > 0
+	d.Alarms = state.Alarms
+}
+
+// send отправляет снимок согласно политике Backpressure. Возвращает false при отмене ctx.
+func (s *subscription) send(ctx context.Context, snapshot Snapshot) bool {
+	if s.backpressure == Block {
+		select {
+		case s.ch <- snapshot:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for {
+		snapshot.Dropped = s.dropped
+		select {
+		case s.ch <- snapshot:
+			return true
+		default:
+			// Буфер заполнен: вытесняем самый старый снимок
+			select {
+			case <-s.ch:
+				s.dropped++
+			default:
+			}
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	fanuc "github.com/iwtcode/fanucAdapter"
+	"github.com/iwtcode/fanucAdapter/focas/fake"
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
@@ -21,7 +22,16 @@ func init() {
 	logrus.SetLevel(logrus.DebugLevel)
 }
 
+// setupTest подключается к станку из ../.env, если задан FANUC_HARDWARE=1.
+// Без него тесты идут против симулятора по FOCAS/Ethernet, поэтому набор
+// запускается без станка; транспорт libfwlib32 при этом не проверяется.
 func setupTest(t *testing.T) *fanuc.Client {
+	if os.Getenv("FANUC_HARDWARE") != "1" {
+		c, sim := setupSimulatorTest(t, fake.NewCNC())
+		require.NoError(t, sim.StartProgram("O0001"))
+		return c
+	}
+
 	err := godotenv.Load("../.env")
 	if err != nil {
 		logrus.Warnf("Warning: Could not load .env file from ../.env. Using default values or environment variables: %v", err)
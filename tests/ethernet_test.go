@@ -0,0 +1,293 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	"github.com/iwtcode/fanucAdapter/focas/errcode"
+	"github.com/iwtcode/fanucAdapter/focas/ethernet"
+	"github.com/stretchr/testify/require"
+)
+
+// exchange — записанная пара кадров "запрос → ответ" в hex.
+type exchange struct {
+	request  string
+	response string
+}
+
+// Синтетический обмен (2 оси, одна активная ошибка), составленный кодеком этого
+// пакета, а не записанный со станка: он проверяет согласованность Backend
+// с ethernet.EncodeResponses, но не совместимость с настоящим ЧПУ.
+// Сверка с независимой реализацией протокола — vendorFrames ниже.
+var syntheticSession = []exchange{
+	// open
+	{"a0a0a0a00001010100020001", "a0a0a0a0000101020000"},
+	// cnc_sysinfo
+	{
+		"a0a0a0a000012101001e0001001c0001000100180000000000000000000000000000000000000000",
+		"a0a0a0a000012102002400010022000100010018000000000000001200000020300020544434463133302e303032",
+	},
+	// cnc_statinfo
+	{
+		"a0a0a0a000012101001e0001001c0001000100190000000000000000000000000000000000000000",
+		"a0a0a0a0000121020024000100220001000100190000000000000012000000010001000300010000000000000000",
+	},
+	// cnc_rdposition(-1, 32)
+	{
+		"a0a0a0a000012101001e0001001c000100010026ffffffff00000020000000000000000000000000",
+		"a0a0a0a00001210200720001007000010001002600000000000000600001e84800030000000058000001e84800030000000058000001e84800030000000058000001e8480003000000005800ffff61cc0003000000005a00ffff61cc0003000000005a00ffff61cc0003000000005a00ffff61cc0003000000005a00",
+	},
+	// cnc_rdalmmsg(-1, 10)
+	{
+		"a0a0a0a000012101001e0001001c000100010035ffffffff0000000a000000000000000000000000",
+		"a0a0a0a000012102005e0001005c000100010035000000000000004c000003e9000600000000000b534552564f20414c41524d0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	},
+	// cnc_rdparar(6711..6757)
+	{
+		"a0a0a0a000012101001e0001001c00010001000f00001a3700001a65000000000000100000000000",
+		"a0a0a0a00001210200220001002000010001000f00000000000000101a3700000000002a1a6500000000005f",
+	},
+	// cnc_exeprgname
+	{
+		"a0a0a0a000012101001e0001001c0001000100cf0000000000000000000000000000000000000000",
+		"a0a0a0a000012102003a000100380001000100cf00000000000000284f303030310000000000000000000000000000000000000000000000000000000000000000000001",
+	},
+	// cnc_diagnoss(301, -1, 20)
+	{
+		"a0a0a0a000012101001e0001001c0001000100300000012dffffffff000000140000000000000000",
+		"a0a0a0a00001210200280001002600010001003000000000000000160008012dffff0000007d000000010000000700000000",
+	},
+	// cnc_actf → EW_NOOPT
+	{
+		"a0a0a0a000012101001e0001001c0001000100240000000000000000000000000000000000000000",
+		"a0a0a0a0000121020012000100100001000100240006000000000000",
+	},
+	// close
+	{"a0a0a0a0000102010000", "a0a0a0a0000102020000"},
+}
+
+// Кадры, которые отправляет libfwlib32 1.0.5 из каталога focas (записаны
+// с заглушкой сервера по журналу cnc_startupprocess). Это независимая от кодека
+// пакета реализация протокола, поэтому совпадение с ней проверяет формат кадров.
+var vendorFrames = struct {
+	open, close, openResponse, closeResponse string
+	statInfo                                 string
+}{
+	open:  "a0a0a0a00001010100020001",
+	close: "a0a0a0a0000102010000",
+	// libfwlib32 отклоняет ответ на открытие, если тело не равно 16 байтам
+	openResponse:  "a0a0a0a00001010200100000000000000000000000000000000000",
+	closeResponse: "a0a0a0a0000102020000",
+	// cnc_statinfo отправляется пакетом из трех блоков: 0x19, 0xE1, 0x98
+	statInfo: "a0a0a0a0000121010056" + "0003" +
+		"001c0001000100190000000000000000000000000000000000000000" +
+		"001c0001000100e10000000000000000000000000000000000000000" +
+		"001c0001000100980000000000000000000000000000000000000000",
+}
+
+// startReplayServer запускает TCP-заглушку, которая проверяет входящие кадры
+// и отвечает записанными кадрами. Если exchanges заканчиваются раньше
+// сессии, соединение закрывается.
+func startReplayServer(t *testing.T, exchanges []exchange) (string, uint16) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for _, ex := range exchanges {
+			frame, err := ethernet.ReadFrame(conn)
+			if err != nil {
+				return
+			}
+			raw := make([]byte, 10+len(frame.Body))
+			binary.BigEndian.PutUint32(raw[0:4], ethernet.Magic)
+			binary.BigEndian.PutUint16(raw[4:6], frame.Version)
+			binary.BigEndian.PutUint16(raw[6:8], frame.Type)
+			binary.BigEndian.PutUint16(raw[8:10], uint16(len(frame.Body)))
+			copy(raw[10:], frame.Body)
+			if hex.EncodeToString(raw) != ex.request {
+				t.Errorf("unexpected request frame:\n got  %x\n want %s", raw, ex.request)
+				return
+			}
+
+			resp, _ := hex.DecodeString(ex.response)
+			if _, err := conn.Write(resp); err != nil {
+				return
+			}
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), uint16(addr.Port)
+}
+
+func TestEthernetSyntheticSession(t *testing.T) {
+	ip, port := startReplayServer(t, syntheticSession)
+	b := ethernet.New()
+
+	h, rc := b.AllcLibHndl3(ip, port, 1000)
+	require.Equal(t, int16(errcode.EW_OK), rc)
+
+	sys, rc := b.SysInfo(h)
+	require.Equal(t, int16(errcode.EW_OK), rc)
+	require.Equal(t, int16(32), sys.MaxAxis)
+	require.Equal(t, " T", sys.MtType)
+	require.Equal(t, "D4F1", sys.Series)
+	require.Equal(t, "02", sys.Axes)
+
+	stat, rc := b.StatInfo(h)
+	require.Equal(t, int16(errcode.EW_OK), rc)
+	require.Equal(t, int16(1), stat.TmMode)
+	require.Equal(t, int16(3), stat.Run)
+
+	posBuf := make([]byte, 32*48)
+	num := int16(32)
+	rc = b.RdPosition(h, -1, &num, posBuf)
+	require.Equal(t, int16(errcode.EW_OK), rc)
+	require.Equal(t, int16(2), num)
+	require.Equal(t, int32(125000), int32(binary.LittleEndian.Uint32(posBuf[0:4])))
+	require.Equal(t, int16(3), int16(binary.LittleEndian.Uint16(posBuf[4:6])))
+	require.Equal(t, byte('X'), posBuf[10])
+	require.Equal(t, int32(-40500), int32(binary.LittleEndian.Uint32(posBuf[48:52])))
+	require.Equal(t, byte('Z'), posBuf[58])
+
+	almBuf := make([]byte, 10*76)
+	numAlarms := int16(10)
+	rc = b.RdAlmMsg(h, -1, &numAlarms, almBuf)
+	require.Equal(t, int16(errcode.EW_OK), rc)
+	require.Equal(t, int16(1), numAlarms)
+	require.Equal(t, int32(1001), int32(binary.LittleEndian.Uint32(almBuf[0:4])))
+	require.Equal(t, "SERVO ALARM", string(almBuf[12:12+binary.LittleEndian.Uint16(almBuf[10:12])]))
+
+	parBuf := make([]byte, 4096)
+	start, end, length := int16(6711), int16(6757), int16(4096)
+	rc = b.RdParar(h, &start, 0, &end, &length, parBuf)
+	require.Equal(t, int16(errcode.EW_OK), rc)
+	require.Equal(t, int16(16), length)
+	require.Equal(t, int16(6757), end)
+	require.Equal(t, int32(42), int32(binary.LittleEndian.Uint32(parBuf[4:8])))
+
+	name, onum, rc := b.ExePrgName(h)
+	require.Equal(t, int16(errcode.EW_OK), rc)
+	require.Equal(t, "O0001", name)
+	require.Equal(t, int64(1), onum)
+
+	diagBuf := make([]byte, 20)
+	rc = b.Diagnoss(h, 301, -1, 20, diagBuf)
+	require.Equal(t, int16(errcode.EW_OK), rc)
+	require.Equal(t, int16(301), int16(binary.LittleEndian.Uint16(diagBuf[0:2])))
+	require.Equal(t, int32(125), int32(binary.LittleEndian.Uint32(diagBuf[4:8])))
+	require.Equal(t, int32(1), int32(binary.LittleEndian.Uint32(diagBuf[8:12])))
+	require.Equal(t, int32(7), int32(binary.LittleEndian.Uint32(diagBuf[12:16])))
+
+	rc = b.Actf(h, make([]byte, 8))
+	require.Equal(t, int16(errcode.EW_NOOPT), rc)
+
+	require.Equal(t, int16(errcode.EW_OK), b.FreeLibHndl(h))
+}
+
+func TestEthernetVendorFrames(t *testing.T) {
+	// Открытие и закрытие побайтно совпадают с libfwlib32, а ответ
+	// на открытие в ее формате (16 байт тела) принимается
+	ip, port := startReplayServer(t, []exchange{
+		{vendorFrames.open, vendorFrames.openResponse},
+		{vendorFrames.close, vendorFrames.closeResponse},
+	})
+	b := ethernet.New()
+
+	h, rc := b.AllcLibHndl3(ip, port, 1000)
+	require.Equal(t, int16(errcode.EW_OK), rc)
+	require.Equal(t, int16(errcode.EW_OK), b.FreeLibHndl(h))
+
+	// Пакет блоков из libfwlib32 разбирается, а первый блок совпадает
+	// с блоком, который кодек формирует для cnc_statinfo
+	raw, err := hex.DecodeString(vendorFrames.statInfo)
+	require.NoError(t, err)
+	frame, err := ethernet.ReadFrame(bytes.NewReader(raw))
+	require.NoError(t, err)
+	require.Equal(t, ethernet.FrameRequest, frame.Type)
+
+	blocks, err := ethernet.DecodeRequests(frame.Body)
+	require.NoError(t, err)
+	require.Len(t, blocks, 3)
+	require.Equal(t, ethernet.CmdStatInfo, blocks[0].Command)
+
+	own := ethernet.EncodeRequests(ethernet.RequestBlock{Command: ethernet.CmdStatInfo})
+	require.Equal(t, own[2:], frame.Body[2:2+len(own)-2])
+}
+
+func TestEthernetConnectionDrop(t *testing.T) {
+	// Заглушка отвечает только на открытие соединения и затем разрывает его
+	ip, port := startReplayServer(t, syntheticSession[:1])
+	b := ethernet.New()
+
+	h, rc := b.AllcLibHndl3(ip, port, 1000)
+	require.Equal(t, int16(errcode.EW_OK), rc)
+
+	_, rc = b.StatInfo(h)
+	require.Equal(t, int16(errcode.EW_SOCKET), rc)
+
+	_, rc = b.StatInfo(h)
+	require.Equal(t, int16(errcode.EW_HANDLE), rc, "после разрыва хендл должен стать недействительным")
+}
+
+func TestEthernetUnreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().(*net.TCPAddr)
+	ln.Close()
+
+	_, rc := ethernet.New().AllcLibHndl3(addr.IP.String(), uint16(addr.Port), 500)
+	require.Equal(t, int16(errcode.EW_SOCKET), rc)
+}
+
+func TestEthernetOfflineMachineDoesNotBlockOthers(t *testing.T) {
+	online, _ := setupSimulatorTest(t, nil)
+
+	// Подключение к недоступному станку зависает до release
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	offline := ethernet.New()
+	offline.Dial = func(network, address string, timeout time.Duration) (net.Conn, error) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		return nil, errors.New("unreachable")
+	}
+	c, err := fanuc.New(&fanuc.Config{
+		IP: "192.0.2.1", Port: 8193, ModelSeries: "0i", LogLevel: "off",
+		Backend: offline, LazyConnect: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(c.Close)
+	t.Cleanup(func() { close(release) })
+	<-started
+
+	// Вызовы к доступному станку выполняются, пока подключение к недоступному ожидает
+	read := make(chan error, 1)
+	go func() {
+		_, err := online.GetMachineState()
+		read <- err
+	}()
+	select {
+	case err := <-read:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Недоступный станок задерживает вызовы к другим станкам")
+	}
+}
@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	"github.com/iwtcode/fanucAdapter/focas/errcode"
+	"github.com/iwtcode/fanucAdapter/focas/fake"
+	"github.com/iwtcode/fanucAdapter/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeEvents(t *testing.T) {
+	c, backend := setupFakeTest(t, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := c.Events(ctx, fanuc.SubscribeOptions{
+		Intervals: map[fanuc.DataGroup]time.Duration{
+			fanuc.GroupState:      10 * time.Millisecond,
+			fanuc.GroupParameters: 10 * time.Millisecond,
+		},
+		Buffer:       16,
+		Backpressure: fanuc.Block,
+	})
+	require.NoError(t, err)
+
+	// Первые опросы задают исходные значения и событий не порождают
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case e := <-events:
+		t.Fatalf("unexpected event %+v", e)
+	default:
+	}
+
+	backend.Update(func(cnc *fake.CNC) {
+		cnc.Stat.Run = 3
+		cnc.Stat.Emergency = 1
+		cnc.Alarms = []fake.Alarm{{Number: 1001, Type: 6, Message: "SERVO ALARM"}}
+		cnc.ExecName = "O0002"
+		cnc.ExecNumber = 2
+		cnc.Params[6711] = 43
+	})
+
+	got := collectEvents(t, events, 5)
+	require.Equal(t, "START", got[models.EventMachineStateChanged].After)
+	require.Equal(t, "EMerGency", got[models.EventEmergencyEntered].After)
+	require.Equal(t, "1001", got[models.EventAlarmRaised].Key)
+	require.Nil(t, got[models.EventAlarmRaised].Before)
+	program := got[models.EventProgramChanged].After.(models.CurrentProgramInfo)
+	require.Equal(t, "O0002", program.ProgramName)
+	require.Equal(t, int64(42), got[models.EventPartsCountIncremented].Before)
+	require.Equal(t, int64(43), got[models.EventPartsCountIncremented].After)
+	require.Equal(t, "127.0.0.1:8193", got[models.EventAlarmRaised].MachineID)
+	require.False(t, got[models.EventAlarmRaised].Timestamp.IsZero())
+
+	// Неудачное чтение тревог не означает их сброс
+	var failAlarms atomic.Bool
+	failAlarms.Store(true)
+	backend.SetHook(func(fn string, handle uint16) int16 {
+		if fn == "cnc_rdalmmsg" && failAlarms.Load() {
+			return errcode.EW_DATA
+		}
+		return errcode.EW_OK
+	})
+	time.Sleep(50 * time.Millisecond)
+	failAlarms.Store(false)
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case e := <-events:
+		t.Fatalf("unexpected event %+v", e)
+	default:
+	}
+
+	backend.Update(func(cnc *fake.CNC) {
+		cnc.Alarms = nil
+	})
+	got = collectEvents(t, events, 1)
+	require.Equal(t, "1001", got[models.EventAlarmCleared].Key)
+	require.Nil(t, got[models.EventAlarmCleared].After)
+
+	// Сравнение результатов GetMachineState без подписки
+	diff := fanuc.DiffMachineState("m1",
+		&models.UnifiedMachineData{ProgramMode: "MEMory", EmergencyStatus: "Not Emergency"},
+		&models.UnifiedMachineData{ProgramMode: "MDI", EmergencyStatus: "Not Emergency"},
+		time.Now())
+	require.Len(t, diff, 1)
+	require.Equal(t, models.EventModeChanged, diff[0].Type)
+	require.Equal(t, "MEMory", diff[0].Before)
+
+	// Тревоги, прочитанные с ошибкой, не сравниваются
+	alarm := models.AlarmDetail{ErrorCode: "SV1001"}
+	before := &models.AggregatedData{Alarms: []models.AlarmDetail{alarm}}
+	after := &models.AggregatedData{Sections: map[models.Section]models.SectionStatus{
+		models.SectionAlarms: {Status: models.StatusError, Quality: models.QualityCommError},
+	}}
+	require.Empty(t, fanuc.DiffData(before, after))
+}
+
+// collectEvents читает n событий и возвращает их по типу.
+func collectEvents(t *testing.T, events <-chan models.MachineEvent, n int) map[models.EventType]models.MachineEvent {
+	t.Helper()
+	got := make(map[models.EventType]models.MachineEvent)
+	for len(got) < n {
+		select {
+		case e := <-events:
+			got[e.Type] = e
+		case <-time.After(2 * time.Second):
+			t.Fatalf("got %d of %d events: %+v", len(got), n, got)
+		}
+	}
+	return got
+}
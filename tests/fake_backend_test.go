@@ -0,0 +1,461 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	apperrors "github.com/iwtcode/fanucAdapter/errors"
+	"github.com/iwtcode/fanucAdapter/focas"
+	"github.com/iwtcode/fanucAdapter/focas/errcode"
+	"github.com/iwtcode/fanucAdapter/focas/fake"
+	"github.com/iwtcode/fanucAdapter/focas/interpreter"
+	"github.com/iwtcode/fanucAdapter/focas/model"
+	"github.com/iwtcode/fanucAdapter/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func setupFakeTest(t *testing.T, cnc *fake.CNC) (*fanuc.Client, *fake.Backend) {
+	t.Helper()
+	backend := fake.New(cnc)
+
+	c, err := fanuc.New(&fanuc.Config{
+		IP:          "127.0.0.1",
+		Port:        8193,
+		TimeoutMs:   1000,
+		ModelSeries: "0i",
+		LogLevel:    "off",
+		Backend:     backend,
+	})
+	require.NoError(t, err, "Не удалось создать клиент с fake-бэкендом")
+	t.Cleanup(c.Close)
+
+	return c, backend
+}
+
+func TestFakeSystemInfo(t *testing.T) {
+	c, _ := setupFakeTest(t, nil)
+
+	sysInfo := c.GetSystemInfo()
+	require.Equal(t, "FANUC", sysInfo.Manufacturer)
+	require.Equal(t, "D4F1", sysInfo.Series)
+	require.Equal(t, int16(32), sysInfo.MaxAxes)
+	require.Equal(t, int16(2), sysInfo.ControlledAxes)
+}
+
+func TestFakeMachineState(t *testing.T) {
+	cnc := fake.NewCNC()
+	cnc.Stat.Run = 3
+	cnc.Stat.Emergency = 1
+	cnc.Alarms = []fake.Alarm{{Number: 1001, Type: 6, Message: "SERVO ALARM"}}
+	c, _ := setupFakeTest(t, cnc)
+
+	state, err := c.GetMachineState()
+	require.NoError(t, err)
+	require.Equal(t, "T", state.TmMode)
+	require.Equal(t, "MEMory", state.ProgramMode)
+	require.Equal(t, "START", state.MachineState)
+	require.Equal(t, "EMerGency", state.EmergencyStatus)
+	require.Len(t, state.Alarms, 1)
+	require.Equal(t, "1001", state.Alarms[0].ErrorCode)
+	require.Equal(t, "SERVO ALARM", state.Alarms[0].ErrorMessage)
+}
+
+func TestFakeAxisAndSpindleData(t *testing.T) {
+	c, _ := setupFakeTest(t, nil)
+
+	axes, err := c.GetAxisData()
+	require.NoError(t, err)
+	require.Len(t, axes, 2)
+	require.Equal(t, "X", axes[0].Name)
+	require.InDelta(t, 125.0, axes[0].Position, 1e-9)
+	require.InDelta(t, 12.0, axes[0].Diag301, 1e-9)
+	require.Equal(t, int32(31), axes[0].ServoTemperature)
+	require.Equal(t, "Z", axes[1].Name)
+	require.InDelta(t, -40.5, axes[1].Position, 1e-9)
+
+	spindles, err := c.GetSpindleData()
+	require.NoError(t, err)
+	require.Len(t, spindles, 1)
+	require.Equal(t, int32(1200), spindles[0].SpeedRPM)
+	require.Equal(t, int16(100), spindles[0].OverridePercent)
+	require.Equal(t, int32(12), spindles[0].Diag411Value)
+}
+
+func TestFakeCurrentData(t *testing.T) {
+	c, _ := setupFakeTest(t, nil)
+
+	data, err := c.GetCurrentData()
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1:8193", data.MachineID)
+	require.Equal(t, "O0001", data.CurrentProgram.ProgramName)
+	require.Equal(t, "G00 X100. Z0.;", data.CurrentProgram.GCodeLine)
+	require.Equal(t, int32(150), data.ContourFeedRate)
+	require.Equal(t, int16(100), data.FeedOverride)
+	require.Equal(t, int32(50), data.JogOverride)
+	require.Equal(t, int64(42), data.PartsCount)
+	require.Equal(t, "1200:00:00", data.PowerOnTime)
+	require.Equal(t, "00:01:35", data.CycleTime)
+}
+
+func TestFakeControlProgram(t *testing.T) {
+	c, _ := setupFakeTest(t, nil)
+
+	gcode, err := c.GetControlProgram()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(gcode, "%\nO0001\n"))
+	require.True(t, strings.HasSuffix(gcode, "M30;\n%"))
+}
+
+func TestFakeReconnectAfterConnectionDrop(t *testing.T) {
+	c, backend := setupFakeTest(t, nil)
+
+	backend.DropConnections()
+	backend.Fail("cnc_statinfo", errcode.EW_SOCKET)
+
+	state, err := c.GetMachineState()
+	require.NoError(t, err, "Адаптер должен переподключиться после разрыва")
+	require.Equal(t, "Reset", state.MachineState)
+	require.Contains(t, backend.Calls(), "cnc_allclibhndl3")
+}
+
+func TestFakePartialCurrentData(t *testing.T) {
+	c, backend := setupFakeTest(t, nil)
+
+	// Ошибка одного раздела не теряет остальные
+	backend.Fail("cnc_actf", errcode.EW_NOOPT)
+	backend.Fail("cnc_rdspeed", errcode.EW_DATA)
+	backend.Fail("cnc_diagnoss", errcode.EW_NUMBER) // Диагностика 301 осей
+	backend.Fail("cnc_rdspload", errcode.EW_NOOPT)
+	backend.Fail("cnc_rdalmmsg", errcode.EW_DATA)
+	data, err := c.GetCurrentData()
+	require.NoError(t, err)
+	require.Equal(t, models.StatusUnsupported, data.Sections[models.SectionContourFeed].Status)
+	require.Equal(t, models.StatusError, data.Sections[models.SectionFeed].Status)
+	require.ErrorIs(t, data.Sections[models.SectionFeed].Err, focas.ErrData)
+	require.NotEmpty(t, data.Sections[models.SectionFeed].Error)
+	require.Equal(t, models.StatusOK, data.Sections[models.SectionState].Status)
+	require.Equal(t, models.QualityNotSupported, data.Sections[models.SectionContourFeed].Quality)
+	require.Equal(t, models.QualityCommError, data.Sections[models.SectionFeed].Quality)
+	require.Zero(t, data.Sections[models.SectionFeed].SourceTime)
+	require.Equal(t, models.QualityGood, data.Sections[models.SectionState].Quality)
+	require.Equal(t, models.QualityCommError, data.Sections[models.SectionAlarms].Quality)
+	require.False(t, data.Sections[models.SectionState].SourceTime.IsZero())
+	require.Equal(t, "O0001", data.CurrentProgram.ProgramName)
+	require.Equal(t, int64(42), data.PartsCount)
+	require.Zero(t, data.ContourFeedRate)
+	require.Equal(t, models.QualityCommError, data.AxisInfos[0].Quality["diag_301"])
+	require.Equal(t, models.QualityNotSupported, data.SpindleInfos[0].Quality["override_percent"])
+
+	// Непрочитанные значения выводятся в JSON как null, а не как ноль
+	raw, err := json.Marshal(data)
+	require.NoError(t, err)
+	var decoded struct {
+		ContourFeedRate *int32 `json:"contour_feed_rate"`
+		ActualFeedRate  *int32 `json:"actual_feed_rate"`
+		PartsCount      *int64 `json:"parts_count"`
+		HasAlarms       *bool  `json:"has_alarms"`
+		AxisInfos       []struct {
+			Diag301          *float64 `json:"diag_301"`
+			ServoTemperature *int32   `json:"servo_temperature"`
+		} `json:"axis_infos"`
+		Sections map[models.Section]struct {
+			Quality models.Quality `json:"quality"`
+		} `json:"sections"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	require.Nil(t, decoded.ContourFeedRate)
+	require.Nil(t, decoded.ActualFeedRate)
+	require.Equal(t, int64(42), *decoded.PartsCount)
+	require.Nil(t, decoded.HasAlarms)
+	require.Nil(t, decoded.AxisInfos[0].Diag301)
+	require.NotNil(t, decoded.AxisInfos[0].ServoTemperature)
+	require.Equal(t, models.QualityNotSupported, decoded.Sections[models.SectionContourFeed].Quality)
+
+	// Невыбранные разделы не читаются
+	calls := len(backend.Calls())
+	data, err = c.GetCurrentDataSections(context.Background(), models.SectionState, models.SectionProgram)
+	require.NoError(t, err)
+	require.Len(t, data.Sections, 2)
+	require.Equal(t, "O0001", data.CurrentProgram.ProgramName)
+	require.Nil(t, data.AxisInfos)
+	for _, fn := range backend.Calls()[calls:] {
+		require.NotContains(t, []string{"cnc_rdposition", "cnc_rdparar", "cnc_actf", "cnc_rdspmeter"}, fn)
+	}
+}
+
+func TestFakeUnsupportedFunction(t *testing.T) {
+	c, backend := setupFakeTest(t, nil)
+
+	backend.Fail("cnc_actf", errcode.EW_NOOPT)
+	backend.SetErrorDetail(4, 2)
+
+	_, err := c.GetContourFeedRate()
+	require.Error(t, err)
+	require.ErrorIs(t, err, focas.ErrNoOption)
+	require.NotErrorIs(t, err, focas.ErrFunction)
+
+	var focasErr *focas.FocasError
+	require.True(t, errors.As(err, &focasErr))
+	require.Equal(t, "cnc_actf", focasErr.Func)
+	require.Equal(t, int16(errcode.EW_NOOPT), focasErr.RC)
+	require.NotZero(t, focasErr.Handle)
+	require.Equal(t, &focas.ErrorDetail{ErrNo: 4, ErrDtNo: 2}, focasErr.Detail)
+
+	appErr := focas.ToAppError(err)
+	require.Equal(t, apperrors.NotImplementedCode, appErr.Code)
+	require.ErrorIs(t, appErr, focas.ErrNoOption)
+}
+
+func TestFakeModelSeriesDetection(t *testing.T) {
+	newClient := func(series string, cnc *fake.CNC) *fanuc.Client {
+		c, err := fanuc.New(&fanuc.Config{
+			IP:          "127.0.0.1",
+			Port:        8193,
+			ModelSeries: series,
+			LogLevel:    "off",
+			Backend:     fake.New(cnc),
+		})
+		require.NoError(t, err)
+		t.Cleanup(c.Close)
+		return c
+	}
+
+	info := newClient("", nil).GetSystemInfo()
+	require.Equal(t, "0i-TF", info.ModelSeries)
+	require.Equal(t, "T", info.MachineType)
+	require.Equal(t, focas.ModelSourceDetected, info.ModelSource)
+	require.Equal(t, "Model0i", info.Implementation)
+
+	cnc := fake.NewCNC()
+	cnc.SysInfo.AddInfo = 0
+	cnc.SysInfo.CncType = "31"
+	cnc.SysInfo.MtType = "MM"
+	info = newClient("", cnc).GetSystemInfo()
+	require.Equal(t, "31i-M", info.ModelSeries)
+	require.Equal(t, "Model31", info.Implementation)
+
+	// Серия из конфигурации имеет приоритет
+	info = newClient("16i", nil).GetSystemInfo()
+	require.Equal(t, "16i", info.ModelSeries)
+	require.Equal(t, focas.ModelSourceConfig, info.ModelSource)
+	require.Equal(t, "Model16i", info.Implementation)
+
+	// Поколение серии 0i определяется по коду ПО ЧПУ
+	for _, tc := range []struct {
+		sysInfo     model.SysInfo
+		series      string
+		machineType string
+	}{
+		{model.SysInfo{AddInfo: 2, CncType: " 0", MtType: " T", Series: "D6B1"}, "0i-TD", "T"},
+		{model.SysInfo{AddInfo: 2, CncType: " 0", MtType: " M", Series: "D4B1"}, "0i-MD", "M"},
+		{model.SysInfo{AddInfo: 2, CncType: " 0", MtType: " T", Series: "D6F1"}, "0i-TF", "T"},
+		{model.SysInfo{AddInfo: 2, CncType: " 0", MtType: " M", Series: "D4F1"}, "0i-MF", "M"},
+		{model.SysInfo{AddInfo: 2, CncType: " 0", MtType: "TT", Series: "D6F1"}, "0i-TF", "T"},
+		{model.SysInfo{AddInfo: 2, CncType: " 0", MtType: " M", Series: "XXXX"}, "0i-M", "M"},
+		{model.SysInfo{AddInfo: 2, CncType: " 0", Series: "D4F1"}, "0i-F", ""},
+		{model.SysInfo{AddInfo: 2, CncType: "16", MtType: " M", Series: "B0F1"}, "16i-M", "M"},
+		{model.SysInfo{CncType: "31", MtType: " M", Series: "G4F1"}, "31i-M", "M"},
+	} {
+		series, machineType := focas.DetectModelSeries(tc.sysInfo)
+		require.Equal(t, tc.series, series, "series %q", tc.sysInfo.Series)
+		require.Equal(t, tc.machineType, machineType)
+	}
+	cnc = fake.NewCNC()
+	cnc.SysInfo.Series = "D6B1"
+	info = newClient("", cnc).GetSystemInfo()
+	require.Equal(t, "0i-TD", info.ModelSeries)
+	require.Equal(t, "Model0i", info.Implementation)
+}
+
+// customEditInterpreter — интерпретатор станкостроителя с собственными состояниями редактирования.
+type customEditInterpreter struct {
+	interpreter.Model0iInterpreter
+}
+
+func (i *customEditInterpreter) InterpretMachineState(stat *model.StatInfo) *models.UnifiedMachineData {
+	data := i.Model0iInterpreter.InterpretMachineState(stat)
+	if stat.Edit == 40 {
+		data.EditStatus = "PALLET EDIT"
+	}
+	return data
+}
+
+type stubProgramReader struct{}
+
+func (stubProgramReader) GetControlProgram(ctx context.Context, a model.FocasCaller) (string, error) {
+	return "%\nSTUB\n%", nil
+}
+
+func TestFakeCustomImplementationRegistry(t *testing.T) {
+	require.NoError(t, focas.Register(focas.Implementation{
+		Name:           "Custom0iTF",
+		Match:          focas.MatchPrefix("0i"),
+		Priority:       10,
+		NewInterpreter: func() model.Interpreter { return &customEditInterpreter{} },
+	}))
+	t.Cleanup(func() { focas.Unregister("Custom0iTF") })
+	require.ErrorIs(t, focas.Register(focas.Implementation{
+		Name:           "Custom0iTF",
+		Match:          focas.MatchAny,
+		NewInterpreter: func() model.Interpreter { return &customEditInterpreter{} },
+	}), focas.ErrDuplicateImplementation)
+
+	cnc := fake.NewCNC()
+	cnc.Stat.Edit = 40
+	c, _ := setupFakeTest(t, cnc)
+	require.Equal(t, "Custom0iTF+Model0i", c.GetSystemInfo().Implementation)
+
+	state, err := c.GetMachineState()
+	require.NoError(t, err)
+	require.Equal(t, "PALLET EDIT", state.EditStatus)
+
+	// Ридер с тем же приоритетом, что у встроенных, заменяет их, так как зарегистрирован позже
+	require.NoError(t, focas.Register(focas.Implementation{
+		Name:             "StubReader",
+		Match:            focas.MatchAny,
+		Priority:         focas.PriorityBuiltin,
+		NewProgramReader: func() model.ProgramReader { return stubProgramReader{} },
+	}))
+	t.Cleanup(func() { focas.Unregister("StubReader") })
+
+	c, _ = setupFakeTest(t, nil)
+	require.Equal(t, "Custom0iTF+StubReader", c.GetSystemInfo().Implementation)
+	gcode, err := c.GetControlProgram()
+	require.NoError(t, err)
+	require.Equal(t, "%\nSTUB\n%", gcode)
+}
+
+// probingBackend — fake-бэкенд недоступного станка, проверка доступности которого
+// ждет сигнала release.
+type probingBackend struct {
+	*fake.Backend
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *probingBackend) Probe(ip string, port uint16) int16 {
+	close(b.started)
+	<-b.release
+	return errcode.EW_SOCKET
+}
+
+func TestFakeConnectProbeWithoutLock(t *testing.T) {
+	c, _ := setupFakeTest(t, nil)
+
+	offline := &probingBackend{Backend: fake.New(nil), started: make(chan struct{}), release: make(chan struct{})}
+	done := make(chan error, 1)
+	go func() {
+		_, err := focas.Connect(offline, "127.0.0.2", 8193, 1000)
+		done <- err
+	}()
+	<-offline.started
+
+	// Пока проверка доступности недоступного станка не завершена, вызовы FOCAS
+	// к другому станку через общую блокировку выполняются без ожидания
+	read := make(chan error, 1)
+	go func() {
+		_, err := c.GetMachineState()
+		read <- err
+	}()
+	select {
+	case err := <-read:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		close(offline.release)
+		t.Fatal("Проверка доступности станка удерживает блокировку вызовов")
+	}
+
+	close(offline.release)
+	err := <-done
+	var focasErr *model.FocasError
+	require.ErrorAs(t, err, &focasErr)
+	require.Equal(t, int16(errcode.EW_SOCKET), focasErr.RC)
+	require.NotContains(t, offline.Calls(), "cnc_allclibhndl3", "После неудачной проверки подключение не выполняется")
+}
+
+func TestFakeInitialSystemInfoFailure(t *testing.T) {
+	backend := fake.New(nil)
+	backend.Fail("cnc_sysinfo", errcode.EW_FUNC)
+
+	_, err := fanuc.New(&fanuc.Config{IP: "127.0.0.1", Port: 8193, ModelSeries: "0i", LogLevel: "off", Backend: backend})
+	require.Error(t, err)
+	require.Contains(t, backend.Calls(), "cnc_freelibhndl", "Хендл неудачного подключения должен освобождаться")
+}
+
+func TestFakeRetryPolicyOnInitialConnect(t *testing.T) {
+	// Политика повторов из конфигурации действует уже при чтении cnc_sysinfo в New
+	backend := fake.New(nil)
+	backend.Fail("cnc_sysinfo", errcode.EW_SOCKET)
+
+	_, err := fanuc.New(&fanuc.Config{
+		IP: "127.0.0.1", Port: 8193, ModelSeries: "0i", LogLevel: "off", Backend: backend,
+		Retry: fanuc.RetryPolicy{MaxAttempts: 1},
+	})
+	require.Error(t, err)
+	connects := 0
+	for _, fn := range backend.Calls() {
+		if fn == "cnc_allclibhndl3" {
+			connects++
+		}
+	}
+	require.Equal(t, 1, connects, "При MaxAttempts: 1 переподключение не выполняется")
+}
+
+// NewFocasAdapter сохраняет исходную сигнатуру; новые параметры задаются через AdapterOptions.
+var _ func(string, uint16, int32, string, logrus.FieldLogger) (*focas.FocasAdapter, error) = focas.NewFocasAdapter
+
+func TestFakeAdapterOptions(t *testing.T) {
+	backend := fake.New(nil)
+	adapter, err := focas.NewFocasAdapterWithOptions(focas.AdapterOptions{
+		Backend: backend, IP: "127.0.0.1", Port: 8193, TimeoutMs: 1000, ModelSeries: "0i",
+	})
+	require.NoError(t, err)
+	defer adapter.Close()
+	require.Equal(t, "FANUC", adapter.GetSystemInfo().Manufacturer)
+}
+
+func TestFakeLazyConnectReadyWithSystemInfo(t *testing.T) {
+	backend := fake.New(nil)
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	backend.SetHook(func(fn string, handle uint16) int16 {
+		if fn == "cnc_sysinfo" {
+			once.Do(func() {
+				close(entered)
+				<-release
+			})
+		}
+		return errcode.EW_OK
+	})
+
+	c, err := fanuc.New(&fanuc.Config{
+		IP:          "127.0.0.1",
+		Port:        8193,
+		LogLevel:    "off",
+		Backend:     backend,
+		LazyConnect: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(c.Close)
+
+	// Пока cnc_sysinfo не завершен, адаптер не должен считаться подключенным
+	<-entered
+	require.NotEqual(t, models.ConnectionConnected, c.ConnectionState())
+	require.Nil(t, c.GetSystemInfo())
+	_, err = c.GetMachineState()
+	require.ErrorIs(t, err, focas.ErrNotConnected)
+
+	close(release)
+	require.Eventually(t, func() bool {
+		return c.ConnectionState() == models.ConnectionConnected
+	}, 2*time.Second, 5*time.Millisecond)
+	require.NotNil(t, c.GetSystemInfo())
+}
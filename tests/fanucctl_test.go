@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	"github.com/iwtcode/fanucAdapter/focas/fake"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulatorFanucctl(t *testing.T) {
+	cnc := fake.NewCNC()
+	cnc.Diag = map[int16][]int32{510: {1234}}
+	c, sim := setupSimulatorTest(t, cnc)
+
+	// Чтение отдельных параметров и диагностики через клиент
+	parts, err := c.GetParameter(6711, 0)
+	require.NoError(t, err)
+	require.Equal(t, int32(42), parts)
+	diag, err := c.GetDiagnosis(510, 0, fanuc.DiagDoubleWord)
+	require.NoError(t, err)
+	require.Equal(t, float64(1234), diag)
+	_, err = c.GetDiagnosis(510, 0, "qword")
+	require.Error(t, err)
+
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go не найден в PATH, сборка fanucctl пропущена")
+	}
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "fanucctl")
+	out, err := exec.Command(gobin, "build", "-o", bin, "../cmd/fanucctl").CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	fanucctl := func(args ...string) ([]byte, error) {
+		args = append([]string{
+			"-ip", sim.Host(), "-port", strconv.Itoa(int(sim.Port())),
+			"-transport", fanuc.TransportEthernet, "-series", "0i", "-format", "json",
+		}, args...)
+		cmd := exec.Command(bin, args...)
+		cmd.Env = append(os.Environ(), "LOG_LEVEL=")
+		return cmd.Output()
+	}
+
+	out, err = fanucctl("params", "get", "6711")
+	require.NoError(t, err)
+	var values []struct {
+		Number int16   `json:"number"`
+		Value  float64 `json:"value"`
+	}
+	require.NoError(t, json.Unmarshal(out, &values))
+	require.Equal(t, int16(6711), values[0].Number)
+	require.Equal(t, float64(42), values[0].Value)
+
+	out, err = fanucctl("axes")
+	require.NoError(t, err)
+	var axes []map[string]any
+	require.NoError(t, json.Unmarshal(out, &axes))
+	require.Len(t, axes, 2)
+	require.Equal(t, "X", axes[0]["name"])
+
+	require.NoError(t, sim.StartProgram("O0001"))
+	program := filepath.Join(dir, "O0001.nc")
+	_, err = fanucctl("program", "upload", "-o", program)
+	require.NoError(t, err)
+	source, err := os.ReadFile(program)
+	require.NoError(t, err)
+	require.Contains(t, string(source), "O0001")
+
+	// Ошибки аргументов завершаются кодом 2
+	_, err = fanucctl("params", "get")
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, 2, exitErr.ExitCode())
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	"github.com/iwtcode/fanucAdapter/fleet"
+	"github.com/iwtcode/fanucAdapter/focas/errcode"
+	"github.com/iwtcode/fanucAdapter/focas/fake"
+	"github.com/iwtcode/fanucAdapter/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeFleetPolling(t *testing.T) {
+	_, err := fleet.New(&fleet.Config{
+		Machines: []fleet.MachineConfig{{ID: "dup", Backend: fake.New(nil)}, {ID: "dup", Backend: fake.New(nil)}},
+		LogLevel: "off",
+	})
+	require.Error(t, err, "Повторяющиеся ID станков должны отклоняться")
+
+	// Недоступный станок не отвечает на подключение, пока тест не отпустит его.
+	// У fake-бэкенда нет собственной блокировки (model.CallLocker), поэтому
+	// cnc_allclibhndl3 выполняется под общей блокировкой вызовов FOCAS и на время
+	// ожидания занимает ее для всех станков.
+	dialing := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	offline := fake.New(nil)
+	offline.SetHook(func(fn string, handle uint16) int16 {
+		if fn == "cnc_allclibhndl3" {
+			select {
+			case dialing <- struct{}{}:
+			case <-done:
+				return errcode.EW_SOCKET
+			}
+			select {
+			case <-release:
+			case <-done:
+			}
+			return errcode.EW_SOCKET
+		}
+		return errcode.EW_OK
+	})
+
+	// Опросы доступных станков считаются по вызовам cnc_statinfo
+	var polls [2]atomic.Int32
+	online := make([]*fake.Backend, len(polls))
+	for i := range online {
+		online[i] = fake.New(nil)
+		online[i].SetHook(func(fn string, handle uint16) int16 {
+			if fn == "cnc_statinfo" {
+				polls[i].Add(1)
+			}
+			return errcode.EW_OK
+		})
+	}
+
+	f, err := fleet.New(&fleet.Config{
+		Machines: []fleet.MachineConfig{
+			{ID: "lathe-1", ModelSeries: "0i", Backend: online[0], Tags: map[string]string{"line": "A"}},
+			{ID: "lathe-2", ModelSeries: "0i", Backend: online[1], Tags: map[string]string{"line": "B"}},
+			{ID: "offline", ModelSeries: "0i", Backend: offline, Tags: map[string]string{"line": "A"}},
+		},
+		PollInterval: fleet.Duration(10 * time.Millisecond),
+		PollTimeout:  fleet.Duration(time.Second),
+		Retry:        fanuc.RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 100 * time.Millisecond},
+		LogLevel:     "off",
+	})
+	require.NoError(t, err)
+	t.Cleanup(f.Close)
+	stop := sync.OnceFunc(func() { close(done) })
+	t.Cleanup(stop)
+
+	require.Equal(t, []string{"lathe-1", "offline"}, f.Select(map[string]string{"line": "A"}))
+
+	f.Start()
+
+	// Недоступный станок повторяет подключения, каждый раз занимая блокировку.
+	// Между попытками доступные станки должны успевать опрашиваться
+	<-dialing
+	for round := 0; round < 3; round++ {
+		before := [2]int32{polls[0].Load(), polls[1].Load()}
+		release <- struct{}{}
+		<-dialing
+		for i := range polls {
+			require.Greater(t, polls[i].Load(), before[i], "Станок lathe-%d не опрошен между попытками подключения недоступного", i+1)
+		}
+	}
+	stop()
+
+	require.Eventually(t, func() bool {
+		for _, id := range []string{"lathe-1", "lathe-2"} {
+			s, ok := f.Snapshot(id)
+			if !ok || s.Data == nil {
+				return false
+			}
+		}
+		return true
+	}, 3*time.Second, 20*time.Millisecond)
+
+	s, _ := f.Snapshot("lathe-1")
+	require.Equal(t, "lathe-1", s.Data.MachineID)
+	require.Equal(t, "O0001", s.Data.CurrentProgram.ProgramName)
+	require.Empty(t, s.Error)
+
+	s, ok := f.Snapshot("offline")
+	require.True(t, ok)
+	require.Nil(t, s.Data)
+	require.NotEqual(t, models.ConnectionConnected, s.Connection.State)
+
+	require.NoError(t, f.Remove("lathe-2"))
+	require.Len(t, f.Snapshots(), 2)
+	_, ok = f.Client("lathe-2")
+	require.False(t, ok)
+}
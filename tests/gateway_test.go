@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apperrors "github.com/iwtcode/fanucAdapter/errors"
+	"github.com/iwtcode/fanucAdapter/focas/errcode"
+	"github.com/iwtcode/fanucAdapter/gateway"
+	"github.com/iwtcode/fanucAdapter/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeGateway(t *testing.T) {
+	c, backend := setupFakeTest(t, nil)
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	gw := gateway.New(gateway.Options{Logger: logger})
+	require.NoError(t, gw.Add("lathe", c, map[string]string{"line": "A"}))
+	server := httptest.NewServer(gw)
+	defer server.Close()
+
+	get := func(path string, status int, v any) {
+		t.Helper()
+		resp, err := server.Client().Get(server.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, status, resp.StatusCode, path)
+		require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	}
+
+	var machines []gateway.Machine
+	get("/machines", http.StatusOK, &machines)
+	require.Len(t, machines, 1)
+	require.Equal(t, "lathe", machines[0].ID)
+	require.Equal(t, models.ConnectionConnected, machines[0].Connection.State)
+	require.Equal(t, "FANUC", machines[0].SystemInfo.Manufacturer)
+
+	var axes []models.AxisInfo
+	get("/machines/lathe/axes", http.StatusOK, &axes)
+	require.Len(t, axes, 2)
+	require.Equal(t, "X", axes[0].Name)
+
+	var params models.ParameterInfo
+	get("/machines/lathe/parameters", http.StatusOK, &params)
+	require.Equal(t, int64(42), params.PartsCount)
+
+	var program models.ControlProgram
+	get("/machines/lathe/program/source", http.StatusOK, &program)
+	require.NotEmpty(t, program.GCode)
+
+	var data models.AggregatedData
+	get("/machines/lathe/current?sections=state,axes", http.StatusOK, &data)
+	require.Len(t, data.Sections, 2)
+	require.Equal(t, models.QualityGood, data.Sections[models.SectionAxes].Quality)
+
+	var ready map[string]any
+	get("/readyz", http.StatusOK, &ready)
+	require.Equal(t, "ready", ready["status"])
+
+	var doc map[string]any
+	get("/openapi.json", http.StatusOK, &doc)
+	require.Contains(t, doc["paths"], "/machines/{id}/current")
+
+	// Ошибки возвращаются телом AppError
+	var appErr struct {
+		apperrors.AppError
+		Details string `json:"details"`
+	}
+	get("/machines/mill/state", http.StatusNotFound, &appErr)
+	require.Equal(t, apperrors.NotFound, appErr.Message)
+	require.Contains(t, appErr.Details, "mill")
+
+	get("/machines/lathe/current?sections=bogus", http.StatusBadRequest, &appErr)
+	require.Equal(t, apperrors.BadRequestCode, appErr.Code)
+
+	backend.Fail("cnc_rdalmmsg", errcode.EW_NOOPT)
+	get("/machines/lathe/alarms", http.StatusNotImplemented, &appErr)
+	require.Equal(t, apperrors.NotImplemented, appErr.Message)
+
+	resp, err := server.Client().Post(server.URL+"/machines", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	fanucv1 "github.com/iwtcode/fanucAdapter/api/fanuc/v1"
+	"github.com/iwtcode/fanucAdapter/focas/errcode"
+	"github.com/iwtcode/fanucAdapter/focas/fake"
+	"github.com/iwtcode/fanucAdapter/grpcapi"
+	"github.com/iwtcode/fanucAdapter/models"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestFakeGRPCService(t *testing.T) {
+	c, backend := setupFakeTest(t, nil)
+	service := grpcapi.NewServer(grpcapi.Options{})
+	require.NoError(t, service.Add("lathe", c, map[string]string{"line": "A"}))
+
+	client := startGRPC(t, service)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	list, err := client.ListMachines(ctx, &fanucv1.ListMachinesRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.Machines, 1)
+	require.Equal(t, "lathe", list.Machines[0].Id)
+	require.Equal(t, string(models.ConnectionConnected), list.Machines[0].Connection.State)
+	require.Equal(t, "FANUC", list.Machines[0].SystemInfo.Manufacturer)
+
+	params, err := client.GetParameters(ctx, &fanucv1.MachineRequest{MachineId: "lathe"})
+	require.NoError(t, err)
+	require.Equal(t, int64(42), params.PartsCount)
+
+	axes, err := client.GetAxes(ctx, &fanucv1.MachineRequest{MachineId: "lathe"})
+	require.NoError(t, err)
+	require.Len(t, axes.Axes, 2)
+	require.Equal(t, "X", axes.Axes[0].Name)
+
+	data, err := client.GetCurrentData(ctx, &fanucv1.GetCurrentDataRequest{MachineId: "lathe", Sections: []string{"state"}})
+	require.NoError(t, err)
+	require.Equal(t, fanucv1.Quality_QUALITY_GOOD, data.Sections["state"].Quality)
+	require.NotContains(t, data.Sections, "axes")
+
+	// Ошибки передаются кодами gRPC
+	_, err = client.GetAlarms(ctx, &fanucv1.MachineRequest{MachineId: "mill"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.GetCurrentData(ctx, &fanucv1.GetCurrentDataRequest{MachineId: "lathe", Sections: []string{"bogus"}})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	backend.Fail("cnc_rdalmmsg", errcode.EW_NOOPT)
+	_, err = client.GetAlarms(ctx, &fanucv1.MachineRequest{MachineId: "lathe"})
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+
+	// Watch передает снимки и события изменений
+	stream, err := client.Watch(ctx, &fanucv1.WatchRequest{
+		Intervals: map[string]*durationpb.Duration{
+			"state":      durationpb.New(10 * time.Millisecond),
+			"parameters": durationpb.New(10 * time.Millisecond),
+		},
+	})
+	require.NoError(t, err)
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, "lathe", resp.MachineId)
+	require.NotNil(t, resp.GetSnapshot())
+
+	backend.Update(func(cnc *fake.CNC) {
+		cnc.Params[6711] = 43
+	})
+	for {
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		if event := resp.GetEvent(); event != nil {
+			require.Equal(t, string(models.EventPartsCountIncremented), event.Type)
+			require.Equal(t, float64(43), event.After.GetNumberValue())
+			break
+		}
+	}
+
+	// Ошибка потокового вызова возвращается при чтении первого сообщения
+	stream, err = client.Watch(ctx, &fanucv1.WatchRequest{Intervals: map[string]*durationpb.Duration{"bogus": durationpb.New(time.Second)}})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestFakeGRPCWatchShared(t *testing.T) {
+	c, backend := setupFakeTest(t, nil)
+	service := grpcapi.NewServer(grpcapi.Options{MaxSubscriptions: 2})
+	require.NoError(t, service.Add("lathe", c, nil))
+	client := startGRPC(t, service)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	statInfoCalls := func() int {
+		n := 0
+		for _, fn := range backend.Calls() {
+			if fn == "cnc_statinfo" {
+				n++
+			}
+		}
+		return n
+	}
+	watch := func(interval time.Duration) (grpc.ServerStreamingClient[fanucv1.WatchResponse], error) {
+		stream, err := client.Watch(ctx, &fanucv1.WatchRequest{
+			Intervals: map[string]*durationpb.Duration{"state": durationpb.New(interval)},
+		})
+		require.NoError(t, err)
+		resp, err := stream.Recv()
+		if err == nil {
+			require.NotNil(t, resp.GetSnapshot())
+		}
+		return stream, err
+	}
+
+	// Второй наблюдатель с теми же интервалами получает снимки общей подписки
+	_, err := watch(time.Hour)
+	require.NoError(t, err)
+	calls := statInfoCalls()
+	_, err = watch(time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, calls, statInfoCalls())
+
+	// Подписки с другими интервалами ограничены MaxSubscriptions
+	_, err = watch(2 * time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, calls+1, statInfoCalls())
+	_, err = watch(3 * time.Hour)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+// startGRPC запускает сервис на bufconn и возвращает подключенного клиента.
+func startGRPC(t *testing.T, service *grpcapi.Server) fanucv1.MachineServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	service.Register(server)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return fanucv1.NewMachineServiceClient(conn)
+}
@@ -0,0 +1,19 @@
+module github.com/iwtcode/fanucAdapter/tests/interop
+
+go 1.24.4
+
+require (
+	github.com/gopcua/opcua v0.8.0
+	github.com/iwtcode/fanucAdapter v0.0.0
+	github.com/sirupsen/logrus v1.9.3
+	github.com/stretchr/testify v1.11.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/iwtcode/fanucAdapter => ../..
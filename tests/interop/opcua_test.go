@@ -0,0 +1,172 @@
+// Package interop проверяет совместимость сервера OPC UA с независимым клиентом
+// gopcua. Пакет вынесен в отдельный модуль, чтобы gopcua не попал в зависимости
+// основного модуля: go test ./... в каталоге tests/interop.
+package interop
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/id"
+	"github.com/gopcua/opcua/ua"
+	fanuc "github.com/iwtcode/fanucAdapter"
+	"github.com/iwtcode/fanucAdapter/focas/fake"
+	server "github.com/iwtcode/fanucAdapter/opcua"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeGopcuaInterop(t *testing.T) {
+	backend := fake.New(nil)
+	c, err := fanuc.New(&fanuc.Config{
+		IP:          "127.0.0.1",
+		Port:        8193,
+		TimeoutMs:   1000,
+		ModelSeries: "0i",
+		LogLevel:    "off",
+		Backend:     backend,
+	})
+	require.NoError(t, err)
+	t.Cleanup(c.Close)
+
+	srv := server.NewServer(server.Options{})
+	require.NoError(t, srv.Start("127.0.0.1:0"))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	go srv.Run(ctx, "lathe", c, fanuc.SubscribeOptions{
+		Intervals: map[fanuc.DataGroup]time.Duration{
+			fanuc.GroupState:      10 * time.Millisecond,
+			fanuc.GroupPositions:  10 * time.Millisecond,
+			fanuc.GroupParameters: 10 * time.Millisecond,
+		},
+	})
+
+	// Обнаружение точки подключения и сессия с анонимным пользователем
+	endpoint := "opc.tcp://" + srv.Addr().String()
+	endpoints, err := opcua.GetEndpoints(ctx, endpoint)
+	require.NoError(t, err)
+	ep, err := opcua.SelectEndpoint(endpoints, ua.SecurityPolicyURINone, ua.MessageSecurityModeNone)
+	require.NoError(t, err)
+	ep.EndpointURL = endpoint
+	client, err := opcua.NewClient(endpoint,
+		opcua.SecurityMode(ua.MessageSecurityModeNone),
+		opcua.AuthAnonymous(),
+		opcua.AutoReconnect(false),
+		opcua.SecurityFromEndpoint(ep, ua.UserTokenTypeAnonymous),
+	)
+	require.NoError(t, err)
+	require.NoError(t, client.Connect(ctx))
+	defer client.Close(context.Background())
+
+	// Пространство имен станка и чтение значений
+	namespaces, err := client.NamespaceArray(ctx)
+	require.NoError(t, err)
+	require.Greater(t, len(namespaces), 1)
+
+	state := ua.MustParseNodeID("ns=1;s=lathe/State/MachineState")
+	read := func(nodes ...*ua.NodeID) []*ua.DataValue {
+		req := &ua.ReadRequest{TimestampsToReturn: ua.TimestampsToReturnBoth}
+		for _, node := range nodes {
+			req.NodesToRead = append(req.NodesToRead, &ua.ReadValueID{NodeID: node, AttributeID: ua.AttributeIDValue})
+		}
+		resp, err := client.Read(ctx, req)
+		require.NoError(t, err)
+		require.Len(t, resp.Results, len(nodes))
+		return resp.Results
+	}
+	require.Eventually(t, func() bool {
+		return read(state)[0].Status == ua.StatusOK
+	}, 2*time.Second, 10*time.Millisecond)
+	values := read(state, ua.MustParseNodeID("ns=1;s=lathe/Counters/PartsCount"), ua.MustParseNodeID("ns=1;s=lathe/Manufacturer"))
+	require.Equal(t, "Reset", values[0].Value.Value())
+	require.Equal(t, int64(42), values[1].Value.Value())
+	require.Equal(t, "FANUC", values[2].Value.Value())
+	require.False(t, values[0].SourceTimestamp.IsZero())
+
+	// Обзор дерева осей
+	browse, err := client.Browse(ctx, &ua.BrowseRequest{
+		NodesToBrowse: []*ua.BrowseDescription{{
+			NodeID:          ua.MustParseNodeID("ns=1;s=lathe/Axes"),
+			BrowseDirection: ua.BrowseDirectionForward,
+			ReferenceTypeID: ua.NewNumericNodeID(0, id.HierarchicalReferences),
+			IncludeSubtypes: true,
+			ResultMask:      uint32(ua.BrowseResultMaskAll),
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, ua.StatusOK, browse.Results[0].StatusCode)
+	var axes []string
+	for _, ref := range browse.Results[0].References {
+		axes = append(axes, ref.BrowseName.Name)
+	}
+	require.Equal(t, []string{"X", "Z"}, axes)
+
+	// Подписка на изменение счетчика деталей и события тревог объекта Server
+	notify := make(chan *opcua.PublishNotificationData, 16)
+	sub, err := client.Subscribe(ctx, &opcua.SubscriptionParameters{Interval: 50 * time.Millisecond}, notify)
+	require.NoError(t, err)
+	fields := []string{"SourceName", "Message", "Severity"}
+	var selects []*ua.SimpleAttributeOperand
+	for _, name := range fields {
+		selects = append(selects, &ua.SimpleAttributeOperand{
+			TypeDefinitionID: ua.NewNumericNodeID(0, id.BaseEventType),
+			BrowsePath:       []*ua.QualifiedName{{Name: name}},
+			AttributeID:      ua.AttributeIDValue,
+		})
+	}
+	events := opcua.NewMonitoredItemCreateRequestWithDefaults(ua.NewNumericNodeID(0, id.Server), ua.AttributeIDEventNotifier, 2)
+	events.RequestedParameters.Filter = &ua.ExtensionObject{
+		EncodingMask: ua.ExtensionObjectBinary,
+		TypeID:       &ua.ExpandedNodeID{NodeID: ua.NewNumericNodeID(0, id.EventFilter_Encoding_DefaultBinary)},
+		// gopcua кодирует nil-указатель как отсутствие поля, поэтому пустой
+		// WhereClause задается явно, как в примерах gopcua
+		Value: ua.EventFilter{SelectClauses: selects, WhereClause: &ua.ContentFilter{}},
+	}
+	monitored, err := sub.Monitor(ctx, ua.TimestampsToReturnBoth,
+		opcua.NewMonitoredItemCreateRequestWithDefaults(ua.MustParseNodeID("ns=1;s=lathe/Counters/PartsCount"), ua.AttributeIDValue, 1),
+		events,
+	)
+	require.NoError(t, err)
+	for _, result := range monitored.Results {
+		require.Equal(t, ua.StatusOK, result.StatusCode)
+	}
+
+	var counts []int64
+	var alarms []*ua.EventFieldList
+	receive := func() {
+		select {
+		case n := <-notify:
+			require.NoError(t, n.Error)
+			switch v := n.Value.(type) {
+			case *ua.DataChangeNotification:
+				for _, item := range v.MonitoredItems {
+					counts = append(counts, item.Value.Value.Value().(int64))
+				}
+			case *ua.EventNotificationList:
+				alarms = append(alarms, v.Events...)
+			}
+		case <-ctx.Done():
+			t.Fatal("нет уведомлений подписки")
+		}
+	}
+	for len(counts) == 0 {
+		receive()
+	}
+	require.Equal(t, int64(42), counts[0])
+
+	backend.Update(func(cnc *fake.CNC) {
+		cnc.Params[6711] = 43
+		cnc.Alarms = []fake.Alarm{{Number: 1001, Type: 6, Message: "SERVO ALARM"}}
+	})
+	for counts[len(counts)-1] != 43 || len(alarms) == 0 {
+		receive()
+	}
+	require.Equal(t, uint32(2), alarms[0].ClientHandle)
+	require.Len(t, alarms[0].EventFields, len(fields))
+	require.Equal(t, "lathe", alarms[0].EventFields[0].Value())
+	require.Contains(t, alarms[0].EventFields[1].Value().(*ua.LocalizedText).Text, "SERVO ALARM")
+	require.NoError(t, sub.Cancel(ctx))
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	fanucv1 "github.com/iwtcode/fanucAdapter/api/fanuc/v1"
+	"github.com/iwtcode/fanucAdapter/focas/fake"
+	"github.com/iwtcode/fanucAdapter/models"
+	"github.com/iwtcode/fanucAdapter/publish/kafka"
+	"github.com/iwtcode/fanucAdapter/publish/kafka/kafkatest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestFakeKafkaPublisher(t *testing.T) {
+	c, backend := setupFakeTest(t, nil)
+
+	broker := kafkatest.NewBroker("mtconnect_data", "machine_events")
+	broker.SetDown(true)
+	publisher, err := kafka.NewWithWriter(kafka.Config{
+		Encoding:     kafka.EncodingProtobuf,
+		RetryBackoff: 10 * time.Millisecond,
+	}, broker)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- publisher.Run(ctx, c, fanuc.SubscribeOptions{
+			Intervals: map[fanuc.DataGroup]time.Duration{fanuc.GroupState: 10 * time.Millisecond},
+		})
+	}()
+
+	// Пока брокер недоступен, сообщения копятся в очереди и отправляются повторно
+	require.Eventually(t, func() bool { return broker.Writes() > 2 }, 2*time.Second, 5*time.Millisecond)
+	require.Empty(t, broker.Messages("mtconnect_data"))
+	require.Positive(t, publisher.Stats().Pending)
+
+	backend.Update(func(cnc *fake.CNC) {
+		cnc.Alarms = []fake.Alarm{{Number: 1001, Type: 6, Message: "SERVO ALARM"}}
+	})
+	time.Sleep(50 * time.Millisecond)
+	broker.SetDown(false)
+
+	// После восстановления брокера доставляются и накопленные снимки, и события
+	require.Eventually(t, func() bool { return len(broker.Messages("machine_events")) > 0 }, 2*time.Second, 5*time.Millisecond)
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	require.NoError(t, publisher.Close())
+	require.Zero(t, publisher.Stats().Pending)
+
+	snapshots := broker.Messages("mtconnect_data")
+	require.NotEmpty(t, snapshots)
+	require.Equal(t, "127.0.0.1:8193", string(snapshots[0].Key))
+	var data fanucv1.MachineData
+	require.NoError(t, proto.Unmarshal(snapshots[0].Value, &data))
+	require.Equal(t, "127.0.0.1:8193", data.MachineId)
+	require.Equal(t, fanucv1.Quality_QUALITY_GOOD, data.Sections[string(models.SectionState)].Quality)
+
+	event := broker.Messages("machine_events")[0]
+	require.Equal(t, "127.0.0.1:8193", string(event.Key))
+	var e fanucv1.MachineEvent
+	require.NoError(t, proto.Unmarshal(event.Value, &e))
+	require.Equal(t, string(models.EventAlarmRaised), e.Type)
+	require.Equal(t, "1001", e.Key)
+	require.Equal(t, "SERVO ALARM", e.After.GetStructValue().Fields["error_message"].GetStringValue())
+
+	require.ErrorIs(t, publisher.PublishSnapshot(&models.AggregatedData{}), kafka.ErrClosed)
+}
+
+func TestFakeKafkaPublisherUnknownTopic(t *testing.T) {
+	broker := kafkatest.NewBroker("mtconnect_data")
+	publisher, err := kafka.NewWithWriter(kafka.Config{
+		RetryBackoff: 10 * time.Millisecond,
+		CloseTimeout: 100 * time.Millisecond,
+	}, broker)
+	require.NoError(t, err)
+
+	require.NoError(t, publisher.PublishSnapshot(&models.AggregatedData{MachineID: "m1", PartsCount: 7}))
+	require.NoError(t, publisher.PublishEvent(models.MachineEvent{Type: models.EventModeChanged, MachineID: "m1"}))
+
+	// Снимок доставлен, событие ожидает создания топика событий
+	require.Eventually(t, func() bool { return len(broker.Messages("mtconnect_data")) == 1 }, time.Second, 5*time.Millisecond)
+	var data map[string]any
+	require.NoError(t, json.Unmarshal(broker.Messages("mtconnect_data")[0].Value, &data))
+	require.EqualValues(t, 7, data["parts_count"])
+	require.ErrorContains(t, publisher.Close(), "1 messages not delivered")
+}
@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	"github.com/iwtcode/fanucAdapter/focas/fake"
+	"github.com/iwtcode/fanucAdapter/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeMetricsExporter(t *testing.T) {
+	exporter := metrics.NewExporter()
+	backend := fake.New(nil)
+	c, err := fanuc.New(&fanuc.Config{
+		IP:          "127.0.0.1",
+		Port:        8193,
+		TimeoutMs:   1000,
+		ModelSeries: "0i",
+		LogLevel:    "off",
+		Backend:     backend,
+		Observer:    exporter.Observer("lathe"),
+	})
+	require.NoError(t, err)
+	defer c.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- exporter.Run(ctx, "lathe", c, fanuc.SubscribeOptions{
+			Intervals: map[fanuc.DataGroup]time.Duration{
+				fanuc.GroupState:      10 * time.Millisecond,
+				fanuc.GroupPositions:  10 * time.Millisecond,
+				fanuc.GroupParameters: 10 * time.Millisecond,
+			},
+		})
+	}()
+
+	server := httptest.NewServer(exporter.Handler())
+	defer server.Close()
+	scrape := func() string {
+		resp, err := server.Client().Get(server.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	var body string
+	require.Eventually(t, func() bool {
+		body = scrape()
+		return containsAll(body, `fanuc_parts_count{machine="lathe"} 42`, `fanuc_axis_position{axis="X",machine="lathe"}`)
+	}, 2*time.Second, 10*time.Millisecond)
+	require.Contains(t, body, `fanuc_machine_state{machine="lathe",state="Reset"} 1`)
+	require.Contains(t, body, `fanuc_active_alarms{machine="lathe"} 0`)
+	require.Contains(t, body, `fanuc_connected{machine="lathe"} 1`)
+	require.Contains(t, body, `fanuc_reconnects_total{machine="lathe"} 0`)
+	require.Contains(t, body, `fanuc_focas_calls_total{function="cnc_statinfo",machine="lathe",rc="EW_OK"}`)
+	require.Contains(t, body, `fanuc_focas_call_duration_seconds_count{function="cnc_rdposition",machine="lathe"}`)
+	require.Contains(t, body, `fanuc_focas_lock_wait_seconds_count{machine="lathe"}`)
+	require.Contains(t, body, "go_goroutines")
+
+	// Новое состояние становится текущим, прежнее остается со значением 0
+	backend.Update(func(cnc *fake.CNC) {
+		cnc.Stat.Run = 3
+		cnc.Alarms = []fake.Alarm{{Number: 1001, Type: 2, Message: "SERVO ALARM"}}
+	})
+	require.Eventually(t, func() bool {
+		return containsAll(scrape(),
+			`fanuc_machine_state{machine="lathe",state="Reset"} 0`,
+			`fanuc_machine_state{machine="lathe",state="START"} 1`,
+			`fanuc_active_alarms{machine="lathe"} 1`)
+	}, 2*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	require.NotContains(t, scrape(), `fanuc_parts_count{machine="lathe"}`)
+}
+
+func containsAll(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if !strings.Contains(s, sub) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	sparkplugb "github.com/iwtcode/fanucAdapter/api/sparkplug/b"
+	"github.com/iwtcode/fanucAdapter/focas/fake"
+	"github.com/iwtcode/fanucAdapter/publish/mqtt"
+	"github.com/iwtcode/fanucAdapter/publish/mqtt/mqtttest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+var mqttSubscribe = fanuc.SubscribeOptions{
+	Intervals: map[fanuc.DataGroup]time.Duration{
+		fanuc.GroupState:     10 * time.Millisecond,
+		fanuc.GroupPositions: 10 * time.Millisecond,
+	},
+}
+
+func TestFakeMQTTPublisher(t *testing.T) {
+	c, backend := setupFakeTest(t, nil)
+	broker := mqtttest.NewBroker()
+	publisher, err := mqtt.New(mqtt.Config{MachineID: "lathe/1", QoS: 1, Dial: broker.Dial})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- publisher.Run(ctx, c, mqttSubscribe) }()
+
+	retained := func(topic string) map[string]any {
+		msg, ok := broker.Retained(topic)
+		if !ok {
+			return nil
+		}
+		var v map[string]any
+		require.NoError(t, json.Unmarshal(msg.Payload, &v))
+		return v
+	}
+	status := func() string {
+		msg, _ := broker.Retained("fanuc/lathe_1/status")
+		return string(msg.Payload)
+	}
+
+	require.Eventually(t, func() bool { return retained("fanuc/lathe_1/axes") != nil }, 2*time.Second, 5*time.Millisecond)
+	require.Equal(t, mqtt.StatusOnline, status())
+	state := retained("fanuc/lathe_1/state")
+	require.Equal(t, "Good", state["quality"])
+	require.Equal(t, "Reset", state["machine_state"])
+	require.NotEmpty(t, state["timestamp"])
+	require.Equal(t, "O0001", retained("fanuc/lathe_1/program")["current_program"].(map[string]any)["program_name"])
+
+	// Неизменившиеся значения повторно не публикуются
+	time.Sleep(50 * time.Millisecond)
+	require.Len(t, broker.Messages("fanuc/lathe_1/state"), 1)
+
+	backend.Update(func(cnc *fake.CNC) {
+		cnc.Alarms = []fake.Alarm{{Number: 1001, Type: 6, Message: "SERVO ALARM"}}
+	})
+	require.Eventually(t, func() bool { return retained("fanuc/lathe_1/alarms")["has_alarms"] == true }, 2*time.Second, 5*time.Millisecond)
+
+	// Потеря соединения с брокером: завещание, затем восстановление статуса
+	broker.Drop()
+	require.Equal(t, mqtt.StatusOffline, status())
+	broker.Reconnect()
+	require.Equal(t, mqtt.StatusOnline, status())
+
+	// Потеря соединения со станком
+	c.Close()
+	require.Eventually(t, func() bool { return status() == mqtt.StatusOffline }, 2*time.Second, 5*time.Millisecond)
+	require.NoError(t, <-done)
+	require.NoError(t, publisher.Close())
+	require.Equal(t, mqtt.StatusOffline, status())
+}
+
+func TestFakeMQTTSparkplug(t *testing.T) {
+	c, _ := setupFakeTest(t, nil)
+	broker := mqtttest.NewBroker()
+	publisher, err := mqtt.New(mqtt.Config{
+		MachineID: "lathe-1",
+		Sparkplug: &mqtt.SparkplugConfig{GroupID: "plant"},
+		Dial:      broker.Dial,
+	})
+	require.NoError(t, err)
+
+	payloads := func(topic string) []*sparkplugb.Payload {
+		var result []*sparkplugb.Payload
+		for _, msg := range broker.Messages(topic) {
+			var p sparkplugb.Payload
+			require.NoError(t, proto.Unmarshal(msg.Payload, &p))
+			result = append(result, &p)
+		}
+		return result
+	}
+	metrics := func(p *sparkplugb.Payload) map[string]*sparkplugb.Payload_Metric {
+		result := make(map[string]*sparkplugb.Payload_Metric)
+		for _, m := range p.GetMetrics() {
+			result[m.GetName()] = m
+		}
+		return result
+	}
+
+	nbirth := payloads("spBv1.0/plant/NBIRTH/lathe-1")
+	require.Len(t, nbirth, 1)
+	require.Zero(t, nbirth[0].GetSeq())
+	require.Contains(t, metrics(nbirth[0]), "bdSeq")
+	require.Contains(t, metrics(nbirth[0]), "Node Control/Rebirth")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- publisher.Run(ctx, c, mqttSubscribe) }()
+
+	// DBIRTH содержит системную информацию и все метрики станка
+	require.Eventually(t, func() bool {
+		births := payloads("spBv1.0/plant/DBIRTH/lathe-1/cnc")
+		return len(births) > 0 && metrics(births[len(births)-1])["Axes/X/Position"] != nil
+	}, 2*time.Second, 5*time.Millisecond)
+	births := payloads("spBv1.0/plant/DBIRTH/lathe-1/cnc")
+	birth := metrics(births[len(births)-1])
+	require.Equal(t, "FANUC", birth["Properties/Manufacturer"].GetStringValue())
+	require.Equal(t, "Reset", birth["State/MachineState"].GetStringValue())
+	require.Equal(t, uint32(sparkplugb.DataType_String), birth["State/MachineState"].GetDatatype())
+	require.True(t, birth["Counters/PartsCount"].GetIsNull(), "Группа parameters не опрашивается")
+	require.NotZero(t, births[len(births)-1].GetSeq())
+
+	// Команда Rebirth заново публикует NBIRTH и DBIRTH
+	rebirth, err := proto.Marshal(&sparkplugb.Payload{Metrics: []*sparkplugb.Payload_Metric{{
+		Name:  proto.String("Node Control/Rebirth"),
+		Value: &sparkplugb.Payload_Metric_BooleanValue{BooleanValue: true},
+	}}})
+	require.NoError(t, err)
+	count := len(births)
+	broker.Publish(mqtt.Message{Topic: "spBv1.0/plant/NCMD/lathe-1", Payload: rebirth})
+	require.Len(t, payloads("spBv1.0/plant/NBIRTH/lathe-1"), 2)
+	require.Len(t, payloads("spBv1.0/plant/DBIRTH/lathe-1/cnc"), count+1)
+
+	// Потеря соединения со станком — DDEATH, штатное отключение — NDEATH
+	c.Close()
+	require.Eventually(t, func() bool {
+		return len(payloads("spBv1.0/plant/DDEATH/lathe-1/cnc")) == 1
+	}, 2*time.Second, 5*time.Millisecond)
+	require.NoError(t, <-done)
+	require.NoError(t, publisher.Close())
+	ndeath := payloads("spBv1.0/plant/NDEATH/lathe-1")
+	require.Len(t, ndeath, 1)
+	require.Equal(t, metrics(nbirth[0])["bdSeq"].GetLongValue(), metrics(ndeath[0])["bdSeq"].GetLongValue())
+}
+
+func TestFakeMQTTSparkplugReconnect(t *testing.T) {
+	broker := mqtttest.NewBroker()
+	publisher, err := mqtt.New(mqtt.Config{
+		MachineID: "lathe-1",
+		Sparkplug: &mqtt.SparkplugConfig{GroupID: "plant"},
+		Dial:      broker.Dial,
+	})
+	require.NoError(t, err)
+
+	bdSeqs := func(topic string) []int64 {
+		var result []int64
+		for _, msg := range broker.Messages(topic) {
+			var p sparkplugb.Payload
+			require.NoError(t, proto.Unmarshal(msg.Payload, &p))
+			for _, m := range p.GetMetrics() {
+				if m.GetName() == "bdSeq" {
+					result = append(result, int64(m.GetLongValue()))
+				}
+			}
+		}
+		return result
+	}
+
+	// Обрыв связи: брокер публикует завещание с bdSeq первой сессии
+	broker.Drop()
+	broker.Reconnect()
+	require.NoError(t, publisher.Close())
+
+	nbirth := bdSeqs("spBv1.0/plant/NBIRTH/lathe-1")
+	ndeath := bdSeqs("spBv1.0/plant/NDEATH/lathe-1")
+	require.Len(t, nbirth, 2)
+	require.Equal(t, nbirth, ndeath, "NDEATH каждой сессии несет bdSeq ее NBIRTH")
+	require.Equal(t, nbirth[0]+1, nbirth[1], "bdSeq увеличивается с каждой сессией")
+}
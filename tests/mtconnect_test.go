@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	"github.com/iwtcode/fanucAdapter/focas/fake"
+	"github.com/iwtcode/fanucAdapter/mtconnect"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeMTConnectAgent(t *testing.T) {
+	c, backend := setupFakeTest(t, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	agent, err := mtconnect.New(ctx, c, mtconnect.Options{
+		DeviceName: "lathe",
+		Subscribe: fanuc.SubscribeOptions{
+			Intervals: map[fanuc.DataGroup]time.Duration{
+				fanuc.GroupState:      10 * time.Millisecond,
+				fanuc.GroupPositions:  10 * time.Millisecond,
+				fanuc.GroupParameters: 10 * time.Millisecond,
+			},
+		},
+	})
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- agent.Run(ctx) }()
+
+	server := httptest.NewServer(agent)
+	defer server.Close()
+
+	probe := get(t, server.URL+"/probe", http.StatusOK)
+	require.Contains(t, probe, `<Device id="dev" name="lathe" uuid="lathe">`)
+	require.Contains(t, probe, `<Linear id="x" name="X">`)
+	require.Contains(t, probe, `type="ROTARY_VELOCITY"`)
+	require.NotNil(t, agent.Device().Item("s1_speed"))
+
+	// Значения появляются после первого опроса; непрочитанные группы недоступны
+	require.Eventually(t, func() bool {
+		return strings.Contains(get(t, server.URL+"/lathe/current", http.StatusOK), ">READY</Execution>")
+	}, 2*time.Second, 10*time.Millisecond)
+	current := get(t, server.URL+"/current", http.StatusOK)
+	require.Contains(t, current, ">42</PartCount>")
+	require.Contains(t, current, ">ARMED</EmergencyStop>")
+	require.Contains(t, current, `<Normal dataItemId="system"`)
+	require.Contains(t, current, ">UNAVAILABLE</RotaryVelocity>")
+
+	var streams struct {
+		Header struct {
+			NextSequence uint64 `xml:"nextSequence,attr"`
+		} `xml:"Header"`
+	}
+	require.NoError(t, xml.Unmarshal([]byte(current), &streams))
+
+	backend.Update(func(cnc *fake.CNC) {
+		cnc.Stat.Run = 3
+		cnc.Alarms = []fake.Alarm{{Number: 1001, Type: 6, Message: "SERVO ALARM"}}
+	})
+	var sample string
+	require.Eventually(t, func() bool {
+		sample = get(t, server.URL+"/sample?from="+strconv.FormatUint(streams.Header.NextSequence, 10), http.StatusOK)
+		return strings.Contains(sample, "<Fault ")
+	}, 2*time.Second, 10*time.Millisecond)
+	require.Contains(t, sample, ">ACTIVE</Execution>")
+	require.Contains(t, sample, `nativeCode="SV1001"`)
+	require.NotContains(t, sample, "PartCount", "Неизменные значения не повторяются")
+
+	require.Contains(t, get(t, server.URL+"/sample?from=0", http.StatusNotFound), "OUT_OF_RANGE")
+	require.Contains(t, get(t, server.URL+"/sample?count=x", http.StatusBadRequest), "INVALID_REQUEST")
+	require.Contains(t, get(t, server.URL+"/mill/current", http.StatusNotFound), "NO_DEVICE")
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	require.Contains(t, get(t, server.URL+"/current", http.StatusOK), ">UNAVAILABLE</Execution>")
+}
+
+func get(t *testing.T, url string, status int) string {
+	t.Helper()
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, status, resp.StatusCode, string(body))
+	return string(body)
+}
@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	"github.com/iwtcode/fanucAdapter/focas/fake"
+	"github.com/iwtcode/fanucAdapter/opcua"
+	"github.com/iwtcode/fanucAdapter/opcua/opcuatest"
+	"github.com/iwtcode/fanucAdapter/opcua/ua"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeOPCUAServer(t *testing.T) {
+	c, backend := setupFakeTest(t, nil)
+	server := opcua.NewServer(opcua.Options{})
+	require.NoError(t, server.Start("127.0.0.1:0"))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Run(ctx, "lathe", c, fanuc.SubscribeOptions{
+			Intervals: map[fanuc.DataGroup]time.Duration{
+				fanuc.GroupState:      10 * time.Millisecond,
+				fanuc.GroupPositions:  10 * time.Millisecond,
+				fanuc.GroupParameters: 10 * time.Millisecond,
+			},
+		})
+	}()
+
+	client, err := opcuatest.Dial(server.Addr().String(), 2*time.Second)
+	require.NoError(t, err)
+	defer client.Close()
+
+	// Значения разделов появляются после первого опроса
+	state := opcua.MachineNodeID("lathe", "State/MachineState")
+	require.Eventually(t, func() bool {
+		values, err := client.Read(state)
+		return err == nil && values[0].Status == ua.Good
+	}, 2*time.Second, 10*time.Millisecond)
+	values, err := client.Read(state, opcua.MachineNodeID("lathe", "Counters/PartsCount"), opcua.MachineNodeID("lathe", "Manufacturer"))
+	require.NoError(t, err)
+	require.Equal(t, "Reset", values[0].Value.Value)
+	require.Equal(t, int64(42), values[1].Value.Value)
+	require.Equal(t, "FANUC", values[2].Value.Value)
+
+	refs, err := client.Browse(opcua.MachineNodeID("lathe", "Axes"))
+	require.NoError(t, err)
+	require.Len(t, refs, 2)
+	require.Equal(t, "X", refs[0].BrowseName.Name)
+	require.Equal(t, "Z", refs[1].BrowseName.Name)
+
+	// Подписка на изменение счетчика деталей и события тревог объекта Server
+	resp, err := client.Send(ua.CreateSubscriptionRequest{RequestedPublishingInterval: 50, PublishingEnabled: true})
+	require.NoError(t, err)
+	subID := resp.(ua.CreateSubscriptionResponse).SubscriptionID
+	resp, err = client.Send(ua.CreateMonitoredItemsRequest{
+		SubscriptionID:     subID,
+		TimestampsToReturn: ua.TimestampsBoth,
+		ItemsToCreate: []ua.MonitoredItemCreateRequest{{
+			ItemToMonitor:       ua.ReadValueID{NodeID: opcua.MachineNodeID("lathe", "Counters/PartsCount"), AttributeID: ua.AttributeValue},
+			MonitoringMode:      ua.MonitoringReporting,
+			RequestedParameters: ua.MonitoringParameters{ClientHandle: 1, QueueSize: 1},
+		}, {
+			ItemToMonitor:  ua.ReadValueID{NodeID: ua.NewNumericNodeID(0, ua.IDServer), AttributeID: ua.AttributeEventNotifier},
+			MonitoringMode: ua.MonitoringReporting,
+			RequestedParameters: ua.MonitoringParameters{ClientHandle: 2, Filter: ua.NewExtensionObject(ua.EventFilter{
+				SelectClauses: []ua.SimpleAttributeOperand{
+					{BrowsePath: []ua.QualifiedName{{Name: "SourceName"}}, AttributeID: ua.AttributeValue},
+					{BrowsePath: []ua.QualifiedName{{Name: "Message"}}, AttributeID: ua.AttributeValue},
+					{BrowsePath: []ua.QualifiedName{{Name: "ActiveState"}, {Name: "Id"}}, AttributeID: ua.AttributeValue},
+				},
+			})},
+		}},
+	})
+	require.NoError(t, err)
+	for _, result := range resp.(ua.CreateMonitoredItemsResponse).Results {
+		require.Equal(t, ua.Good, result.StatusCode)
+	}
+
+	var changes []ua.MonitoredItemNotification
+	var events []ua.EventFieldList
+	publish := func() {
+		resp, err := client.Send(ua.PublishRequest{})
+		require.NoError(t, err)
+		for _, data := range resp.(ua.PublishResponse).NotificationMessage.NotificationData {
+			switch n := data.Value.(type) {
+			case ua.DataChangeNotification:
+				changes = append(changes, n.MonitoredItems...)
+			case ua.EventNotificationList:
+				events = append(events, n.Events...)
+			}
+		}
+	}
+	publish()
+	require.Len(t, changes, 1)
+	require.Equal(t, int64(42), changes[0].Value.Value.Value)
+
+	backend.Update(func(cnc *fake.CNC) {
+		cnc.Params[6711] = 43
+		cnc.Alarms = []fake.Alarm{{Number: 1001, Type: 6, Message: "SERVO ALARM"}}
+	})
+	for deadline := time.Now().Add(2 * time.Second); (len(changes) < 2 || len(events) < 1) && time.Now().Before(deadline); {
+		publish()
+	}
+	require.Len(t, changes, 2)
+	require.Equal(t, int64(43), changes[1].Value.Value.Value)
+	require.Len(t, events, 1)
+	require.Equal(t, uint32(2), events[0].ClientHandle)
+	require.Equal(t, "lathe", events[0].EventFields[0].Value)
+	require.Contains(t, events[0].EventFields[1].Value.(ua.LocalizedText).Text, "SERVO ALARM")
+	require.Equal(t, true, events[0].EventFields[2].Value)
+
+	// После остановки опроса значения теряют связь со станком
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	values, err = client.Read(state)
+	require.NoError(t, err)
+	require.Equal(t, ua.BadNoCommunication, values[0].Status)
+}
+
+func TestFakeOPCUAPartialChunksLimit(t *testing.T) {
+	client, server := net.Pipe()
+	ch := ua.NewChannel(server, ua.Limits{ReceiveBufferSize: 8192, SendBufferSize: 8192, MaxMessageSize: 4096})
+	defer ch.Close()
+
+	// Каждое сообщение меньше MaxMessageSize, но вместе незавершенные сообщения больше
+	go func() {
+		defer client.Close()
+		body := make([]byte, 1024)
+		for id := uint32(1); id <= 8; id++ {
+			if _, err := client.Write(intermediateChunk(id, body)); err != nil {
+				return
+			}
+		}
+	}()
+	_, err := ch.ReadMessage()
+	var transportErr *ua.TransportError
+	require.ErrorAs(t, err, &transportErr)
+	require.Equal(t, ua.BadTCPMessageTooLarge, transportErr.Status)
+}
+
+// intermediateChunk кодирует промежуточный фрагмент MSG запроса requestID.
+func intermediateChunk(requestID uint32, body []byte) []byte {
+	chunk := append([]byte("MSG"), 'C')
+	chunk = binary.LittleEndian.AppendUint32(chunk, uint32(8+16+len(body)))
+	chunk = binary.LittleEndian.AppendUint32(chunk, 1)         // ChannelID
+	chunk = binary.LittleEndian.AppendUint32(chunk, 1)         // TokenID
+	chunk = binary.LittleEndian.AppendUint32(chunk, requestID) // SequenceNumber
+	chunk = binary.LittleEndian.AppendUint32(chunk, requestID)
+	return append(chunk, body...)
+}
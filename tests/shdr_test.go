@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iwtcode/fanucAdapter/models"
+	"github.com/iwtcode/fanucAdapter/mtconnect/shdr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeSHDRAdapter(t *testing.T) {
+	c, _ := setupFakeTest(t, nil)
+
+	adapter, err := shdr.New(t.Context(), c, shdr.Options{Heartbeat: 5 * time.Second})
+	require.NoError(t, err)
+	require.NoError(t, adapter.Start("127.0.0.1:0"))
+	defer adapter.Close()
+
+	data, err := c.GetCurrentData()
+	require.NoError(t, err)
+	adapter.Update(data, time.Now())
+
+	// Подключение получает все текущие значения
+	conn, lines := dialSHDR(t, adapter)
+	first := readLine(t, lines)
+	require.Contains(t, first, "|execution|READY|")
+	require.Contains(t, first, "|part_count|42|")
+	require.Contains(t, readLine(t, lines), "|system|NORMAL||||")
+
+	fmt.Fprintln(conn, "* PING")
+	require.Equal(t, "* PONG 5000", readLine(t, lines))
+
+	// Передаются только изменения, тревоги — строками CONDITION
+	changed := *data
+	changed.MachineState = "START"
+	changed.Alarms = []models.AlarmDetail{{ErrorCode: "401", ErrorTypeDescription: "SV – Servo alarm", ErrorMessage: "SERVO | ALARM"}}
+	adapter.Update(&changed, time.Now())
+	update := readLine(t, lines)
+	require.True(t, strings.HasSuffix(update, "|execution|ACTIVE"), update)
+	require.NotContains(t, update, "part_count")
+	require.True(t, strings.HasSuffix(readLine(t, lines), "|system|FAULT|SV0401|||SERVO   ALARM"))
+
+	changed.Alarms = nil
+	adapter.Update(&changed, time.Now())
+	require.True(t, strings.HasSuffix(readLine(t, lines), "|system|NORMAL||||"))
+
+	// После переподключения значения передаются заново
+	conn.Close()
+	_, lines = dialSHDR(t, adapter)
+	require.Contains(t, readLine(t, lines), "|execution|ACTIVE|")
+}
+
+func dialSHDR(t *testing.T, adapter *shdr.Adapter) (net.Conn, <-chan string) {
+	t.Helper()
+	conn, err := net.Dial("tcp", adapter.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	lines := make(chan string, 16)
+	go func() {
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	return conn, lines
+}
+
+func readLine(t *testing.T, lines <-chan string) string {
+	t.Helper()
+	select {
+	case line := <-lines:
+		return line
+	case <-time.After(2 * time.Second):
+		t.Fatal("no SHDR line")
+		return ""
+	}
+}
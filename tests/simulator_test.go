@@ -0,0 +1,370 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	apperrors "github.com/iwtcode/fanucAdapter/errors"
+	"github.com/iwtcode/fanucAdapter/focas"
+	"github.com/iwtcode/fanucAdapter/focas/errcode"
+	"github.com/iwtcode/fanucAdapter/focas/fake"
+	"github.com/iwtcode/fanucAdapter/focas/model"
+	"github.com/iwtcode/fanucAdapter/models"
+	"github.com/iwtcode/fanucAdapter/simulator"
+	"github.com/stretchr/testify/require"
+)
+
+// setupSimulatorTest запускает симулятор на свободном порту и подключает к нему
+// клиент через транспорт FOCAS/Ethernet.
+func setupSimulatorTest(t *testing.T, cnc *fake.CNC) (*fanuc.Client, *simulator.Simulator) {
+	t.Helper()
+	sim := simulator.New(cnc)
+	require.NoError(t, sim.Start("127.0.0.1:0"))
+	t.Cleanup(func() { sim.Close() })
+
+	c, err := fanuc.New(&fanuc.Config{
+		IP:          sim.Host(),
+		Port:        sim.Port(),
+		TimeoutMs:   1000,
+		ModelSeries: "0i",
+		LogLevel:    "off",
+		Transport:   fanuc.TransportEthernet,
+	})
+	require.NoError(t, err, "Не удалось подключиться к симулятору")
+	t.Cleanup(c.Close)
+
+	return c, sim
+}
+
+func TestSimulatorAllGetters(t *testing.T) {
+	cnc := fake.NewCNC()
+	cnc.Alarms = []fake.Alarm{{Number: 1001, Type: 6, Message: "SERVO ALARM"}}
+	c, sim := setupSimulatorTest(t, cnc)
+	require.NoError(t, sim.StartProgram("O0001"))
+
+	sysInfo := c.GetSystemInfo()
+	require.Equal(t, "D4F1", sysInfo.Series)
+	require.Equal(t, int16(2), sysInfo.ControlledAxes)
+
+	state, err := c.GetMachineState()
+	require.NoError(t, err)
+	require.Equal(t, "START", state.MachineState)
+	require.Len(t, state.Alarms, 1)
+	require.Equal(t, "SERVO ALARM", state.Alarms[0].ErrorMessage)
+
+	alarms, err := c.GetAlarms()
+	require.NoError(t, err)
+	require.Len(t, alarms, 1)
+
+	axes, err := c.GetAxisData()
+	require.NoError(t, err)
+	require.Len(t, axes, 2)
+	require.InDelta(t, 125.0, axes[0].Position, 1e-9)
+	require.InDelta(t, 12.0, axes[0].Diag301, 1e-9)
+	require.Equal(t, int32(29), axes[0].CoderTemperature)
+
+	spindles, err := c.GetSpindleData()
+	require.NoError(t, err)
+	require.Len(t, spindles, 1)
+	require.Equal(t, int32(1200), spindles[0].SpeedRPM)
+	require.Equal(t, int16(100), spindles[0].OverridePercent)
+
+	prog, err := c.GetProgramInfo()
+	require.NoError(t, err)
+	require.Equal(t, "O0001", prog.Name)
+	require.Equal(t, "G00 X100. Z0.;", prog.CurrentGCode)
+
+	feed, err := c.GetFeedData()
+	require.NoError(t, err)
+	require.NotNil(t, feed)
+
+	contour, err := c.GetContourFeedRate()
+	require.NoError(t, err)
+	require.Equal(t, int32(150), contour)
+
+	feedOverride, err := c.GetFeedOverride()
+	require.NoError(t, err)
+	require.Equal(t, int32(100), feedOverride)
+
+	jogOverride, err := c.GetJogOverride()
+	require.NoError(t, err)
+	require.Equal(t, int32(50), jogOverride)
+
+	params, err := c.GetParameterInfo()
+	require.NoError(t, err)
+	require.Equal(t, int64(42), params.PartsCount)
+	require.Equal(t, "1200:00:00", params.PowerOnTime)
+
+	gcode, err := c.GetControlProgram()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(gcode, "%\nO0001\n"))
+
+	data, err := c.GetCurrentData()
+	require.NoError(t, err)
+	require.Equal(t, "O0001", data.CurrentProgram.ProgramName)
+	require.Equal(t, "00:01:35", data.CycleTime)
+}
+
+func TestSimulatorFaults(t *testing.T) {
+	c, sim := setupSimulatorTest(t, nil)
+
+	// EW_BUSY возвращается вызывающему как ошибка, следующий вызов проходит
+	sim.Fail("cnc_actf", errcode.EW_BUSY)
+	_, err := c.GetContourFeedRate()
+	require.Error(t, err)
+	_, err = c.GetContourFeedRate()
+	require.NoError(t, err)
+
+	// EW_HANDLE и разрыв сокета приводят к прозрачному переподключению
+	sim.Fail("cnc_statinfo", errcode.EW_HANDLE, errcode.EW_SOCKET)
+	_, err = c.GetMachineState()
+	require.NoError(t, err)
+
+	sim.DropConnections()
+	axes, err := c.GetAxisData()
+	require.NoError(t, err, "Клиент должен переподключиться после разрыва всех соединений")
+	require.Len(t, axes, 2)
+}
+
+func TestSimulatorTypedErrors(t *testing.T) {
+	c, sim := setupSimulatorTest(t, nil)
+
+	// Подробности cnc_getdtailerr передаются по FOCAS/Ethernet
+	sim.Fail("cnc_rdparar", errcode.EW_NUMBER)
+	sim.Backend().SetErrorDetail(3, 6711)
+	_, err := c.GetParameterInfo()
+	require.ErrorIs(t, err, focas.ErrNumber)
+	var focasErr *focas.FocasError
+	require.ErrorAs(t, err, &focasErr)
+	require.Equal(t, "cnc_rdparar", focasErr.Func)
+	require.NotNil(t, focasErr.Detail)
+	require.Equal(t, int16(6711), focasErr.Detail.ErrDtNo)
+	require.Equal(t, apperrors.NotFoundErrorCode, focas.ToAppError(err).Code)
+
+	// Для ошибок библиотеки (rc < 0) подробности не запрашиваются
+	sim.Fail("cnc_statinfo", errcode.EW_BUSY)
+	_, err = c.GetMachineState()
+	require.ErrorIs(t, err, focas.ErrBusy)
+	require.ErrorAs(t, err, &focasErr)
+	require.Nil(t, focasErr.Detail)
+	require.Equal(t, apperrors.ServiceUnavailableCode, focas.ToAppError(err).Code)
+}
+
+func TestSimulatorMultiPath(t *testing.T) {
+	cnc := fake.NewCNC()
+	cnc.MaxPath = 2
+	cnc.Programs["O0002"] = "%\nO0002\nG00 X50. Z10.;\nM30;\n%"
+	cnc.ExtraPaths = []fake.PathState{{
+		Stat:       model.StatInfo{Aut: 1, Run: 3, Motion: 1},
+		Axes:       []fake.Axis{{Name: 'X', Position: 50000, PosDec: 3}, {Name: 'Z', Position: 10000, PosDec: 3}, {Name: 'B', Position: 90000, PosDec: 3}},
+		Alarms:     []fake.Alarm{{Number: 2001, Type: 6, Message: "TURRET 2 ALARM"}},
+		ExecName:   "O0002",
+		ExecNumber: 2,
+		ExecBlock:  "G00 X50. Z10.;",
+	}}
+	c, _ := setupSimulatorTest(t, cnc)
+	require.Equal(t, int16(2), c.Paths())
+
+	axes, err := c.Path(2).GetAxisData()
+	require.NoError(t, err)
+	require.Len(t, axes, 3)
+	require.InDelta(t, 50.0, axes[0].Position, 1e-9)
+
+	prog, err := c.Path(2).GetProgramInfo()
+	require.NoError(t, err)
+	require.Equal(t, "O0002", prog.Name)
+
+	alarms, err := c.Path(2).GetAlarms()
+	require.NoError(t, err)
+	require.Len(t, alarms, 1)
+
+	// Чтение без канала возвращается к каналу по умолчанию
+	prog, err = c.GetProgramInfo()
+	require.NoError(t, err)
+	require.Equal(t, "O0001", prog.Name)
+	alarms, err = c.GetAlarms()
+	require.NoError(t, err)
+	require.Empty(t, alarms)
+
+	data, err := c.GetCurrentData()
+	require.NoError(t, err)
+	require.Len(t, data.Paths, 2)
+	require.Equal(t, "O0001", data.Paths[0].CurrentProgram.ProgramName)
+	require.Equal(t, "O0002", data.Paths[1].CurrentProgram.ProgramName)
+	require.Equal(t, "START", data.Paths[1].MachineState)
+	require.True(t, data.Paths[1].HasAlarms)
+	require.Len(t, data.Paths[1].AxisInfos, 3)
+	require.Equal(t, "O0001", data.CurrentProgram.ProgramName)
+
+	_, err = c.Path(3).GetAxisData()
+	require.ErrorIs(t, err, focas.ErrData)
+}
+
+func TestSimulatorRetryPolicyAndCircuitBreaker(t *testing.T) {
+	sim := simulator.New(nil)
+	require.NoError(t, sim.Start("127.0.0.1:0"))
+	t.Cleanup(func() { sim.Close() })
+
+	c, err := fanuc.New(&fanuc.Config{
+		IP:        sim.Host(),
+		Port:      sim.Port(),
+		TimeoutMs: 1000,
+		LogLevel:  "off",
+		Transport: fanuc.TransportEthernet,
+		Retry: fanuc.RetryPolicy{
+			MaxAttempts:      3,
+			InitialBackoff:   10 * time.Millisecond,
+			BreakerThreshold: 1,
+			BreakerCooldown:  time.Minute,
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(c.Close)
+
+	// Станок "выключили": соединения рвутся, новые не принимаются
+	sim.SetReachable(false)
+	sim.DropConnections()
+
+	_, err = c.GetMachineState()
+	require.ErrorIs(t, err, focas.ErrRetriesExhausted)
+
+	// Предохранитель разомкнут: вызов завершается сразу, без обращения к станку
+	calls := len(sim.Calls())
+	_, err = c.GetAxisData()
+	require.ErrorIs(t, err, focas.ErrCircuitOpen)
+	require.Equal(t, calls, len(sim.Calls()))
+}
+
+func TestSimulatorContextDeadline(t *testing.T) {
+	sim := simulator.New(nil)
+	require.NoError(t, sim.Start("127.0.0.1:0"))
+	t.Cleanup(func() { sim.Close() })
+
+	c, err := fanuc.New(&fanuc.Config{
+		IP:        sim.Host(),
+		Port:      sim.Port(),
+		TimeoutMs: 1000,
+		LogLevel:  "off",
+		Transport: fanuc.TransportEthernet,
+		Retry:     fanuc.RetryPolicy{MaxAttempts: -1, InitialBackoff: 50 * time.Millisecond},
+	})
+	require.NoError(t, err)
+	t.Cleanup(c.Close)
+
+	sim.SetReachable(false)
+	sim.DropConnections()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = c.GetMachineStateCtx(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), 2*time.Second, "Вызов должен завершиться по дедлайну")
+	require.Equal(t, apperrors.GatewayTimeoutCode, focas.ToAppError(err).Code)
+
+	// Отмененный клиентом запрос не считается внутренней ошибкой
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = c.GetMachineStateCtx(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, apperrors.ClientClosedRequestCode, focas.ToAppError(err).Code)
+}
+
+func TestSimulatorConnectionStateEvents(t *testing.T) {
+	sim := simulator.New(nil)
+	require.NoError(t, sim.Start("127.0.0.1:0"))
+	t.Cleanup(func() { sim.Close() })
+
+	c, err := fanuc.New(&fanuc.Config{
+		IP:        sim.Host(),
+		Port:      sim.Port(),
+		TimeoutMs: 1000,
+		LogLevel:  "off",
+		Transport: fanuc.TransportEthernet,
+		Retry:     fanuc.RetryPolicy{MaxAttempts: 2, InitialBackoff: 10 * time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	status := c.ConnectionStatus()
+	require.Equal(t, models.ConnectionConnected, status.State)
+	require.False(t, status.LastSuccess.IsZero())
+	data, err := json.Marshal(status)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "last_error_time", "Нулевое время ошибки не сериализуется")
+
+	events, cancel := c.ConnectionEvents(16)
+	defer cancel()
+
+	// Потеря связи: Reconnecting, затем Disconnected после исчерпания попыток
+	sim.SetReachable(false)
+	sim.DropConnections()
+	_, err = c.GetMachineState()
+	require.Error(t, err)
+	require.Equal(t, models.ConnectionDisconnected, c.ConnectionState())
+	require.NotEmpty(t, c.ConnectionStatus().LastError)
+
+	// Станок снова доступен: переподключение и Connected
+	sim.SetReachable(true)
+	_, err = c.GetMachineState()
+	require.NoError(t, err)
+	require.Equal(t, 1, c.ConnectionStatus().ReconnectCount)
+
+	c.Close()
+	require.Equal(t, models.ConnectionClosed, c.ConnectionState())
+
+	var transitions []models.ConnectionState
+	for len(events) > 0 {
+		transitions = append(transitions, (<-events).To)
+	}
+	require.Equal(t, []models.ConnectionState{
+		models.ConnectionReconnecting,
+		models.ConnectionDisconnected,
+		models.ConnectionReconnecting,
+		models.ConnectionConnected,
+		models.ConnectionClosed,
+	}, transitions)
+}
+
+func TestSimulatorLazyConnect(t *testing.T) {
+	sim := simulator.New(nil)
+	require.NoError(t, sim.Start("127.0.0.1:0"))
+	t.Cleanup(func() { sim.Close() })
+	sim.SetReachable(false)
+
+	c, err := fanuc.New(&fanuc.Config{
+		IP:          sim.Host(),
+		Port:        sim.Port(),
+		TimeoutMs:   1000,
+		LogLevel:    "off",
+		Transport:   fanuc.TransportEthernet,
+		LazyConnect: true,
+		Retry:       fanuc.RetryPolicy{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond},
+	})
+	require.NoError(t, err, "В ленивом режиме New не должен зависеть от доступности станка")
+	t.Cleanup(c.Close)
+
+	require.Equal(t, models.ConnectionDisconnected, c.ConnectionState())
+	require.Nil(t, c.GetSystemInfo())
+
+	_, err = c.GetMachineState()
+	require.ErrorIs(t, err, focas.ErrNotConnected)
+	_, err = c.GetAxisData()
+	require.ErrorIs(t, err, focas.ErrNotConnected)
+
+	sim.SetReachable(true)
+	require.Eventually(t, func() bool {
+		return c.ConnectionState() == models.ConnectionConnected
+	}, 2*time.Second, 10*time.Millisecond)
+
+	require.NotNil(t, c.GetSystemInfo())
+	require.Equal(t, "D4F1", c.GetSystemInfo().Series)
+
+	state, err := c.GetMachineState()
+	require.NoError(t, err)
+	require.NotNil(t, state)
+}
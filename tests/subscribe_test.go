@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	"github.com/iwtcode/fanucAdapter/focas"
+	"github.com/iwtcode/fanucAdapter/focas/errcode"
+	"github.com/iwtcode/fanucAdapter/focas/fake"
+	"github.com/iwtcode/fanucAdapter/focas/model"
+	"github.com/iwtcode/fanucAdapter/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeSubscribe(t *testing.T) {
+	c, backend := setupFakeTest(t, nil)
+	backend.Update(func(cnc *fake.CNC) {
+		cnc.Alarms = []fake.Alarm{{Number: 1001, Type: 6, Message: "SERVO ALARM"}}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	snapshots, err := c.Subscribe(ctx, fanuc.SubscribeOptions{
+		Intervals: map[fanuc.DataGroup]time.Duration{
+			fanuc.GroupState:      20 * time.Millisecond,
+			fanuc.GroupParameters: time.Hour,
+		},
+		Backpressure: fanuc.Block,
+	})
+	require.NoError(t, err)
+
+	// Первый снимок содержит все группы
+	first := <-snapshots
+	require.NoError(t, first.Err)
+	require.Equal(t, uint64(1), first.Seq)
+	require.Equal(t, []fanuc.DataGroup{fanuc.GroupState, fanuc.GroupParameters}, first.Groups)
+	require.Equal(t, "O0001", first.Data.CurrentProgram.ProgramName)
+	require.Equal(t, int64(42), first.Data.PartsCount)
+
+	// Ошибка чтения приходит в потоке, а подписка продолжается
+	backend.Fail("cnc_statinfo", errcode.EW_NUMBER)
+	var failed bool
+	for i := 0; i < 5; i++ {
+		s := <-snapshots
+		require.Equal(t, []fanuc.DataGroup{fanuc.GroupState}, s.Groups)
+		require.Equal(t, int64(42), s.Data.PartsCount, "Значения медленной группы сохраняются")
+		if s.Err != nil {
+			require.ErrorIs(t, s.Err, focas.ErrNumber)
+			// Прежние значения сохраняются с качеством Stale и временем последнего чтения
+			state := s.Data.Sections[models.SectionState]
+			require.Equal(t, models.QualityStale, state.Quality)
+			require.Equal(t, models.StatusError, state.Status)
+			require.Equal(t, first.Data.Sections[models.SectionState].SourceTime, state.SourceTime)
+			require.Equal(t, first.Data.MachineState, s.Data.MachineState)
+			require.Equal(t, models.QualityGood, first.Data.Sections[models.SectionState].Quality)
+			failed = true
+		}
+	}
+	require.True(t, failed)
+
+	// Тревоги, которые не удалось прочитать, сохраняются с качеством Stale
+	backend.Fail("cnc_rdalmmsg", errcode.EW_DATA)
+	for failed = false; !failed; {
+		s := <-snapshots
+		if s.Err != nil {
+			alarms := s.Data.Sections[models.SectionAlarms]
+			require.Equal(t, models.QualityStale, alarms.Quality)
+			require.Equal(t, models.QualityGood, s.Data.Sections[models.SectionState].Quality)
+			require.Equal(t, first.Data.Alarms, s.Data.Alarms)
+			require.Len(t, s.Data.Alarms, 1)
+			require.True(t, s.Data.HasAlarms)
+			failed = true
+		}
+	}
+
+	cancel()
+	for range snapshots {
+	}
+
+	_, err = c.Subscribe(context.Background(), fanuc.SubscribeOptions{
+		Intervals: map[fanuc.DataGroup]time.Duration{"unknown": time.Second},
+	})
+	require.Error(t, err)
+}
+
+func TestFakeSubscribeDropOldest(t *testing.T) {
+	c, _ := setupFakeTest(t, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	snapshots, err := c.Subscribe(ctx, fanuc.SubscribeOptions{
+		Intervals: map[fanuc.DataGroup]time.Duration{fanuc.GroupState: 5 * time.Millisecond},
+		Buffer:    2,
+	})
+	require.NoError(t, err)
+
+	// Медленный получатель не задерживает опрос: старые снимки вытесняются
+	time.Sleep(100 * time.Millisecond)
+	<-snapshots
+	latest := <-snapshots
+	require.Greater(t, latest.Dropped, uint64(0))
+	require.Greater(t, latest.Seq, uint64(2))
+
+	// Закрытие клиента закрывает канал
+	c.Close()
+	require.Eventually(t, func() bool {
+		select {
+		case _, ok := <-snapshots:
+			return !ok
+		default:
+			return false
+		}
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestFakeSubscribePaths(t *testing.T) {
+	cnc := fake.NewCNC()
+	cnc.MaxPath = 2
+	cnc.ExtraPaths = []fake.PathState{{
+		Stat:     model.StatInfo{Aut: 1, Run: 3},
+		Axes:     []fake.Axis{{Name: 'X', Position: 50000, PosDec: 3}},
+		ExecName: "O0002",
+	}}
+	c, _ := setupFakeTest(t, cnc)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	snapshots, err := c.Subscribe(ctx, fanuc.SubscribeOptions{
+		Intervals: map[fanuc.DataGroup]time.Duration{
+			fanuc.GroupState: time.Hour,
+			fanuc.GroupPaths: time.Hour,
+		},
+	})
+	require.NoError(t, err)
+
+	s := <-snapshots
+	require.NoError(t, s.Err)
+	require.Equal(t, []fanuc.DataGroup{fanuc.GroupState, fanuc.GroupPaths}, s.Groups)
+	require.Len(t, s.Data.Paths, 2)
+	require.Equal(t, "O0002", s.Data.Paths[1].CurrentProgram.ProgramName)
+	require.Equal(t, models.QualityGood, s.Data.Sections[models.SectionPaths].Quality)
+	require.Contains(t, fanuc.DefaultSubscribeIntervals(), fanuc.GroupPaths)
+}
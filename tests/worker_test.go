@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	fanuc "github.com/iwtcode/fanucAdapter"
+	"github.com/iwtcode/fanucAdapter/fleet"
+	"github.com/iwtcode/fanucAdapter/focas/errcode"
+	"github.com/iwtcode/fanucAdapter/focas/fake"
+	"github.com/iwtcode/fanucAdapter/focas/worker"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+// envCrashMarker — файл-маркер: пока его нет, обработчик падает на первом cnc_statinfo.
+const envCrashMarker = "FANUC_TEST_CRASH_MARKER"
+
+// envStdoutNoise заставляет обработчик писать в fd 1 при каждом вызове,
+// как это делают libfwlib32 и cgo-код.
+const envStdoutNoise = "FANUC_TEST_STDOUT_NOISE"
+
+// TestMain позволяет тестовому бинарнику работать процессом-обработчиком с fake-бэкендом.
+func TestMain(m *testing.M) {
+	if worker.IsWorker() {
+		serveFakeWorker()
+	}
+	os.Exit(m.Run())
+}
+
+func serveFakeWorker() {
+	backend := fake.New(nil)
+	if os.Getenv(envStdoutNoise) == "1" {
+		backend.SetHook(func(fn string, handle uint16) int16 {
+			fmt.Fprintf(os.Stdout, "fwlib: %s\n", fn)
+			return errcode.EW_OK
+		})
+	}
+	if marker := os.Getenv(envCrashMarker); marker != "" {
+		backend.SetHook(func(fn string, handle uint16) int16 {
+			if fn == "cnc_statinfo" {
+				if _, err := os.Stat(marker); os.IsNotExist(err) {
+					os.WriteFile(marker, nil, 0o644)
+					os.Exit(2) // Имитация сбоя внутри libfwlib32
+				}
+			}
+			return errcode.EW_OK
+		})
+	}
+	if err := worker.ServeParent(backend); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestFakeWorkerRestart(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(os.Stderr)
+	logger.SetLevel(logrus.ErrorLevel)
+
+	marker := filepath.Join(t.TempDir(), "crashed")
+	w, err := worker.New(worker.Options{
+		Command:        os.Args[0],
+		Env:            []string{envCrashMarker + "=" + marker},
+		RestartBackoff: 50 * time.Millisecond,
+		Logger:         logger,
+	})
+	require.NoError(t, err)
+	t.Cleanup(w.Close)
+
+	c, err := fanuc.New(&fanuc.Config{
+		IP:          "127.0.0.1",
+		Port:        8193,
+		TimeoutMs:   1000,
+		ModelSeries: "0i",
+		LogLevel:    "off",
+		Backend:     w,
+		Retry:       fanuc.RetryPolicy{InitialBackoff: 50 * time.Millisecond, BreakerThreshold: -1},
+	})
+	require.NoError(t, err)
+	t.Cleanup(c.Close)
+	require.Equal(t, "D4F1", c.GetSystemInfo().Series)
+
+	// Первый cnc_statinfo роняет обработчик; после перезапуска клиент переподключается
+	require.Eventually(t, func() bool {
+		state, err := c.GetMachineState()
+		return err == nil && state.MachineState != ""
+	}, 5*time.Second, 50*time.Millisecond)
+	require.FileExists(t, marker)
+	require.Equal(t, 1, w.Restarts())
+
+	axes, err := c.GetAxisData()
+	require.NoError(t, err)
+	require.Len(t, axes, 2)
+	require.InDelta(t, 125.0, axes[0].Position, 1e-9)
+
+	program, err := c.GetControlProgram()
+	require.NoError(t, err)
+	require.NotEmpty(t, program)
+}
+
+func TestFakeWorkerStdoutNoise(t *testing.T) {
+	// Вывод обработчика в stdout не должен попадать в поток протокола
+	w, err := worker.New(worker.Options{
+		Command: os.Args[0],
+		Env:     []string{envStdoutNoise + "=1"},
+		Stderr:  io.Discard,
+		Logger:  logrus.New(),
+	})
+	require.NoError(t, err)
+	t.Cleanup(w.Close)
+
+	c, err := fanuc.New(&fanuc.Config{
+		IP:          "127.0.0.1",
+		Port:        8193,
+		ModelSeries: "0i",
+		LogLevel:    "off",
+		Backend:     w,
+	})
+	require.NoError(t, err)
+	t.Cleanup(c.Close)
+
+	for i := 0; i < 3; i++ {
+		_, err := c.GetMachineState()
+		require.NoError(t, err)
+	}
+	require.Zero(t, w.Restarts())
+}
+
+func TestFakeFleetProcessIsolation(t *testing.T) {
+	f, err := fleet.New(&fleet.Config{
+		Machines: []fleet.MachineConfig{
+			{ID: "lathe-1", IP: "127.0.0.1", ModelSeries: "0i", WorkerGroup: "line-a"},
+			{ID: "lathe-2", IP: "127.0.0.2", ModelSeries: "0i", WorkerGroup: "line-a"},
+			{ID: "mill-1", IP: "127.0.0.3", ModelSeries: "0i"},
+		},
+		PollInterval: fleet.Duration(50 * time.Millisecond),
+		Isolation:    fleet.IsolationProcess,
+		Worker:       worker.Options{Command: os.Args[0]},
+		LogLevel:     "off",
+	})
+	require.NoError(t, err)
+	t.Cleanup(f.Close)
+
+	f.Start()
+	require.Eventually(t, func() bool {
+		for _, s := range f.Snapshots() {
+			if s.Data == nil {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 20*time.Millisecond, "Станки должны опрашиваться через процессы-обработчики")
+}
+
+func TestFakeWorkerRequiresCommand(t *testing.T) {
+	// Без явного исполняемого файла обработчик не должен запускать копию текущей программы
+	_, err := worker.New(worker.Options{})
+	require.ErrorIs(t, err, worker.ErrNoCommand)
+
+	_, err = fleet.New(&fleet.Config{
+		Machines:  []fleet.MachineConfig{{ID: "lathe-1", IP: "127.0.0.1", ModelSeries: "0i"}},
+		Isolation: fleet.IsolationProcess,
+		LogLevel:  "off",
+	})
+	require.ErrorIs(t, err, worker.ErrNoCommand)
+}